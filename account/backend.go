@@ -23,20 +23,24 @@ import (
 
 type Backend struct {
 	*pkgservice.BaseService
+
+	db *accountDB
 }
 
 func NewBackend(ctx *pkgservice.RouterContext, config *Config, router pkgservice.Router) (*Backend, error) {
 	// init account
-	err := InitAccount(config.DataDir)
+	db, err := newAccountDB(config.DataDir, config.DBEngine)
 	if err != nil {
 		return nil, err
 	}
 
 	// backend
-	backend := &Backend{}
+	backend := &Backend{
+		db: db,
+	}
 
 	// spm
-	spm, err := NewServiceProtocolManager(router, backend)
+	spm, err := NewServiceProtocolManager(router, backend, db)
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +62,7 @@ func (b *Backend) Start() error {
 
 func (b *Backend) Stop() error {
 	b.SPM().(*ServiceProtocolManager).Stop()
-	TeardownAccount()
+	b.db.Close()
 
 	return nil
 }
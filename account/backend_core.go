@@ -185,6 +185,12 @@ func (b *Backend) GetRawUserNameByID(id *types.PttID) (*UserName, error) {
 	return spm.GetUserNameByID(id)
 }
 
+func (b *Backend) SearchUserIDs(query string, limit int) ([]*types.PttID, error) {
+
+	spm := b.SPM().(*ServiceProtocolManager)
+	return spm.SearchUserIDs(query, limit)
+}
+
 func (b *Backend) GetUserName(idBytes []byte) (*BackendUserName, error) {
 
 	u, err := b.GetRawUserName(idBytes)
@@ -16,8 +16,13 @@
 
 package account
 
+import "github.com/ailabstw/go-pttai-core/pttdb"
+
 type Config struct {
 	DataDir string
+
+	// DBEngine is the storage-engine of the dbs, pttdb.DefaultEngine if empty.
+	DBEngine pttdb.Engine
 }
 
 func NewConfig() (*Config, error) {
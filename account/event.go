@@ -0,0 +1,65 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package account
+
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+type ProfileEventType int
+
+const (
+	_ ProfileEventType = iota
+	ProfileEventTypeUserName
+	ProfileEventTypeUserImg
+	ProfileEventTypeNameCard
+)
+
+/*
+ProfileEvent is posted when the user-name / user-img / name-card of the user is created or updated.
+*/
+type ProfileEvent struct {
+	UserID   *types.PttID     `json:"ID"`
+	Type     ProfileEventType `json:"T"`
+	UpdateTS types.Timestamp  `json:"UT"`
+}
+
+/*
+postupdateUserObj is the postcreate / postupdate of the user-name / user-img / name-card.
+*/
+func (pm *ProtocolManager) postupdateUserObj(obj pkgservice.Object, oplog *pkgservice.BaseOplog) error {
+	var evType ProfileEventType
+	switch obj.(type) {
+	case *UserName:
+		evType = ProfileEventTypeUserName
+	case *UserImg:
+		evType = ProfileEventTypeUserImg
+	case *NameCard:
+		evType = ProfileEventTypeNameCard
+	default:
+		return pkgservice.ErrInvalidData
+	}
+
+	pm.PostEvent(&ProfileEvent{
+		UserID:   obj.GetID(),
+		Type:     evType,
+		UpdateTS: oplog.UpdateTS,
+	})
+
+	return nil
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/ailabstw/go-pttai-core/node"
 	"github.com/ailabstw/go-pttai-core/pttdb"
+	"github.com/ailabstw/go-pttai-core/search"
 	pkgservice "github.com/ailabstw/go-pttai-core/service"
 )
 
@@ -109,11 +110,6 @@ const (
 
 // db
 var (
-	dbAccount     *pttdb.LDBBatch    = nil
-	dbAccountCore *pttdb.LDBDatabase = nil
-
-	dbMeta *pttdb.LDBDatabase = nil
-
 	DBProfilePrefix = []byte(".pfdb")
 
 	DBUserNamePrefix    = []byte(".umdb")
@@ -126,6 +122,9 @@ var (
 	DBUserNodePrefix     = []byte(".undb")
 	DBUserNodeIdxPrefix  = []byte(".unix")
 	DBUserNodeInfoPrefix = []byte(".uidb")
+
+	DBUserNameSearchPrefix = []byte(".umsx")
+	DBNameCardSearchPrefix = []byte(".ncsx")
 )
 
 // max-masters
@@ -152,39 +151,68 @@ var (
 	DBUserMerkleOplogPrefix = []byte(".urmk")
 )
 
-func InitAccount(dataDir string) error {
+/*
+accountDB is the dbs of the account-service of a node.
+*/
+type accountDB struct {
+	account     pttdb.IndexBatch
+	accountCore pttdb.Storage
+
+	meta pttdb.Storage
+
+	// search
+	userNameIndex *search.Index
+	nameCardIndex *search.Index
+}
+
+func newAccountDB(dataDir string, engine pttdb.Engine) (*accountDB, error) {
 	var err error
 
-	dbAccountCore, err = pttdb.NewLDBDatabase("account", dataDir, 0, 0)
+	db := &accountDB{}
+
+	db.accountCore, err = pttdb.NewStorageWithEngine(engine, "account", dataDir)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	dbAccount, err = pttdb.NewLDBBatch(dbAccountCore)
+	db.account, err = pttdb.NewStorageBatch(db.accountCore)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	dbMeta, err = pttdb.NewLDBDatabase("accountmeta", dataDir, 0, 0)
+	db.userNameIndex, err = search.NewIndex(db.accountCore, DBUserNameSearchPrefix)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	db.nameCardIndex, err = search.NewIndex(db.accountCore, DBNameCardSearchPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	db.meta, err = pttdb.NewStorageWithEngine(engine, "accountmeta", dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
 }
 
-func TeardownAccount() {
-	if dbAccountCore != nil {
-		dbAccountCore.Close()
-		dbAccountCore = nil
+func (db *accountDB) Close() {
+	if db.accountCore != nil {
+		db.accountCore.Close()
+		db.accountCore = nil
 	}
 
-	if dbAccount != nil {
-		dbAccount = nil
+	if db.account != nil {
+		db.account = nil
 	}
 
-	if dbMeta != nil {
-		dbMeta.Close()
-		dbMeta = nil
+	db.userNameIndex = nil
+	db.nameCardIndex = nil
+
+	if db.meta != nil {
+		db.meta.Close()
+		db.meta = nil
 	}
 }
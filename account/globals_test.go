@@ -29,6 +29,7 @@ import (
 const ()
 
 var (
+	tDB       *accountDB     = nil
 	tEntityID *types.PttID   = nil
 	tLockMap  *types.LockMap = nil
 
@@ -71,7 +72,7 @@ func setupTest(t *testing.T) {
 	origHandler = log.Root().GetHandler()
 	log.Root().SetHandler(log.Must.FileHandler("log.tmp.txt", log.TerminalFormat(true)))
 
-	InitAccount("./test.out")
+	tDB, _ = newAccountDB("./test.out", "")
 
 	tEntityID, _ = types.NewPttID()
 	tLockMap, _ = types.NewLockMap(1)
@@ -82,25 +83,25 @@ func setupTest(t *testing.T) {
 	tUserNameMarshal = []byte(`{"b":{"V":2,"ID":"f8FnBNeGR37bqtFqZ4zZjXGYdKpoyDbWLRrv8qRSevKjQzeWpdeX46","CT":{"T":1,"NT":5},"CID":"f8FnBNeGR37bqtFqZ4zZjXGYdKpoyDbWLRrv8qRSevKjQzeWpdeX46","UID":"f8FnBNeGR37bqtFqZ4zZjXGYdKpoyDbWLRrv8qRSevKjQzeWpdeX46","e":"1fAhYWqs6ctsWHNZtpYJNB9BxxQPq4Pa5LkbLC3wpAHLipXE7tXVY","S":7,"g":0,"a":0},"UT":{"T":1,"NT":5}}`)
 
 	tUserNameA, _ = NewUserName(tTsA, tUserIDA, tEntityID, nil, types.StatusAlive, nil)
-	tUserNameA.SetDB(dbAccount, tLockMap, tEntityID, DBUserNamePrefix, DBUserNameIdxPrefix, nil, nil)
+	tUserNameA.SetDB(tDB.account, tLockMap, tEntityID, DBUserNamePrefix, DBUserNameIdxPrefix, nil, nil)
 
 	tKeyB, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
 	tUserIDB, _ = types.NewPttIDFromKey(tKeyB)
 	tTsB = types.Timestamp{Ts: 2, NanoTs: 6}
 	tUserNameB, _ = NewUserName(tTsB, tUserIDB, tEntityID, nil, types.StatusAlive, nil)
-	tUserNameB.SetDB(dbAccount, tLockMap, tEntityID, DBUserNamePrefix, DBUserNameIdxPrefix, nil, nil)
+	tUserNameB.SetDB(tDB.account, tLockMap, tEntityID, DBUserNamePrefix, DBUserNameIdxPrefix, nil, nil)
 
 	tKeyC, _ = crypto.HexToECDSA("869d6ecf5211f1cc60418a13b9d870b22959d0c16f02bec714c960dd2298a32d")
 	tUserIDC, _ = types.NewPttIDFromKey(tKeyC)
 	tTsC = types.Timestamp{Ts: 3, NanoTs: 7}
 	tUserNameC, _ = NewUserName(tTsC, tUserIDC, tEntityID, nil, types.StatusAlive, nil)
-	tUserNameC.SetDB(dbAccount, tLockMap, tEntityID, DBUserNamePrefix, DBUserNameIdxPrefix, nil, nil)
+	tUserNameC.SetDB(tDB.account, tLockMap, tEntityID, DBUserNamePrefix, DBUserNameIdxPrefix, nil, nil)
 
 	tKeyD, _ = crypto.HexToECDSA("e238eb8e04fee6511ab04c6dd3c89ce097b11f25d584863ac2b6d5b35b1847e4")
 	tUserIDD, _ = types.NewPttIDFromKey(tKeyD)
 	tTsD = types.Timestamp{Ts: 4, NanoTs: 8}
 	tUserNameD, _ = NewUserName(tTsD, tUserIDD, tEntityID, nil, types.StatusAlive, nil)
-	tUserNameD.SetDB(dbAccount, tLockMap, tEntityID, DBUserNamePrefix, DBUserNameIdxPrefix, nil, nil)
+	tUserNameD.SetDB(tDB.account, tLockMap, tEntityID, DBUserNamePrefix, DBUserNameIdxPrefix, nil, nil)
 
 }
 
@@ -109,7 +110,7 @@ func teardownTest(t *testing.T) {
 
 	types.RandRead = origRandRead
 
-	TeardownAccount()
+	tDB.Close()
 
 	os.RemoveAll("./test.out")
 }
@@ -22,6 +22,7 @@ import (
 	"github.com/ailabstw/go-pttai-core/common"
 	"github.com/ailabstw/go-pttai-core/common/types"
 	"github.com/ailabstw/go-pttai-core/pttdb"
+	"github.com/ailabstw/go-pttai-core/search"
 	pkgservice "github.com/ailabstw/go-pttai-core/service"
 )
 
@@ -29,6 +30,8 @@ type SyncNameCardInfo struct {
 	*pkgservice.BaseSyncInfo `json:"b"`
 
 	Card []byte `json:"C,omitempty"`
+
+	index *search.Index
 }
 
 func NewEmptySyncNameCardInfo() *SyncNameCardInfo {
@@ -54,6 +57,8 @@ type NameCard struct {
 	SyncInfo               *SyncNameCardInfo `json:"s,omitempty"`
 
 	Card []byte `json:"C,omitempty"`
+
+	index *search.Index
 }
 
 func NewNameCard(
@@ -114,11 +119,13 @@ func AliveNameCards(typedObjs []*NameCard) []*NameCard {
 func (pm *ProtocolManager) SetNameCardDB(u *NameCard) {
 	spm := pm.Entity().Service().SPM()
 
-	u.SetDB(dbAccount, spm.DBObjLock(), pm.Entity().GetID(), pm.dbNameCardPrefix, pm.dbNameCardIdxPrefix, nil, nil)
+	u.index = pm.db.nameCardIndex
+	u.SetDB(pm.db.account, spm.DBObjLock(), pm.Entity().GetID(), pm.dbNameCardPrefix, pm.dbNameCardIdxPrefix, nil, nil)
 }
 
 func (spm *ServiceProtocolManager) SetNameCardDB(u *NameCard) {
-	u.SetDB(dbAccount, spm.DBObjLock(), nil, DBNameCardPrefix, DBNameCardIdxPrefix, nil, nil)
+	u.index = spm.db.nameCardIndex
+	u.SetDB(spm.db.account, spm.DBObjLock(), nil, DBNameCardPrefix, DBNameCardIdxPrefix, nil, nil)
 }
 
 func (u *NameCard) Save(isLocked bool) error {
@@ -157,12 +164,13 @@ func (u *NameCard) Save(isLocked bool) error {
 		return err
 	}
 
-	return nil
+	return indexUserObj(u.index, u.BaseObject, u.Card)
 }
 
 func (u *NameCard) NewEmptyObj() pkgservice.Object {
 	newU := NewEmptyNameCard()
 	newU.CloneDB(u.BaseObject)
+	newU.index = u.index
 	return newU
 }
 
@@ -29,10 +29,12 @@ type Profile struct {
 	UpdateTS               types.Timestamp `json:"UT"`
 
 	MyID *types.PttID `json:"m"`
+
+	db *accountDB
 }
 
-func NewEmptyProfile() *Profile {
-	return &Profile{BaseEntity: &pkgservice.BaseEntity{SyncInfo: &pkgservice.BaseSyncInfo{}}}
+func NewEmptyProfile(db *accountDB) *Profile {
+	return &Profile{BaseEntity: &pkgservice.BaseEntity{SyncInfo: &pkgservice.BaseSyncInfo{}}, db: db}
 }
 
 func NewProfile(myID *types.PttID, ts types.Timestamp, router pkgservice.Router, service pkgservice.Service, spm pkgservice.ServiceProtocolManager, dbLock *types.LockMap) (*Profile, error) {
@@ -42,7 +44,9 @@ func NewProfile(myID *types.PttID, ts types.Timestamp, router pkgservice.Router,
 		return nil, err
 	}
 
-	e := pkgservice.NewBaseEntity(id, ts, myID, types.StatusInit, dbAccount, dbLock)
+	db := spm.(*ServiceProtocolManager).db
+
+	e := pkgservice.NewBaseEntity(id, ts, myID, types.StatusInit, db.account, dbLock)
 	e.EntityType = pkgservice.EntityTypePersonal
 
 	p := &Profile{
@@ -50,6 +54,8 @@ func NewProfile(myID *types.PttID, ts types.Timestamp, router pkgservice.Router,
 		UpdateTS:   ts,
 
 		MyID: myID,
+
+		db: db,
 	}
 
 	log.Debug("NewProfile", "id", id)
@@ -72,7 +78,8 @@ func (p *Profile) SetUpdateTS(ts types.Timestamp) {
 
 func (p *Profile) Init(router pkgservice.Router, service pkgservice.Service, spm pkgservice.ServiceProtocolManager) error {
 
-	p.SetDB(dbAccount, spm.GetDBLock())
+	p.db = spm.(*ServiceProtocolManager).db
+	p.SetDB(p.db.account, spm.GetDBLock())
 
 	err := p.InitPM(router, service)
 	if err != nil {
@@ -134,7 +141,7 @@ func (p *Profile) Save(isLocked bool) error {
 		return err
 	}
 
-	err = dbAccountCore.Put(key, marshaled)
+	err = p.db.accountCore.Put(key, marshaled)
 	if err != nil {
 		return err
 	}
@@ -23,7 +23,7 @@ import (
 func NewEmptyApproveJoinProfile() *ApproveJoinEntity {
 	return &ApproveJoinEntity{
 		ApproveJoinEntity: &pkgservice.ApproveJoinEntity{
-			Entity: NewEmptyProfile(),
+			Entity: NewEmptyProfile(nil),
 		},
 		UserName: NewEmptyUserName(),
 		UserImg:  NewEmptyUserImg(),
@@ -30,6 +30,7 @@ func (pm *ProtocolManager) CleanObject() error {
 	err := userName.Get(false)
 	if err == nil {
 		userName.Delete(false)
+		pm.db.userNameIndex.Delete(nil, userName.ID)
 	}
 
 	// user-img
@@ -48,6 +49,7 @@ func (pm *ProtocolManager) CleanObject() error {
 	err = nameCard.Get(false)
 	if err == nil {
 		nameCard.Delete(false)
+		pm.db.nameCardIndex.Delete(nil, nameCard.ID)
 	}
 
 	return nil
@@ -34,6 +34,13 @@ func (spm *ServiceProtocolManager) CreateJoinEntity(
 	isResetOwnerID bool,
 ) (pkgservice.Entity, error) {
 
+	// the profile may be created by the other services (ex: friend / me)
+	profile, ok := approveJoin.Entity.(*Profile)
+	if !ok {
+		return nil, pkgservice.ErrInvalidData
+	}
+	profile.db = spm.db
+
 	entity, err := spm.BaseServiceProtocolManager.CreateJoinEntity(
 		approveJoin.ApproveJoinEntity,
 		peer,
@@ -43,7 +43,7 @@ func (pm *ProtocolManager) CreateNameCard(nameCard []byte) error {
 		pm.broadcastUserOplogsCore,
 		pm.broadcastUserOplogCore,
 
-		nil,
+		pm.postupdateUserObj,
 	)
 	if err != nil {
 		return err
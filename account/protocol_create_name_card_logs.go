@@ -37,7 +37,7 @@ func (pm *ProtocolManager) handleCreateNameCardLogs(oplog *pkgservice.BaseOplog,
 
 		pm.existsInInfoCreateNameCard,
 		pm.newNameCardWithOplog,
-		nil,
+		pm.postupdateUserObj,
 		pm.updateCreateNameCardInfo,
 	)
 }
@@ -56,7 +56,7 @@ func (pm *ProtocolManager) handlePendingCreateNameCardLogs(oplog *pkgservice.Bas
 
 		pm.existsInInfoCreateNameCard,
 		pm.newNameCardWithOplog,
-		nil,
+		pm.postupdateUserObj,
 		pm.updateCreateNameCardInfo,
 	)
 }
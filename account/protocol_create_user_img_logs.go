@@ -30,7 +30,7 @@ func (pm *ProtocolManager) handleCreateUserImgLogs(oplog *pkgservice.BaseOplog,
 
 	return pm.HandleCreateObjectLog(
 		oplog, obj, opData, info,
-		pm.existsInInfoCreateUserImg, pm.newUserImgWithOplog, nil, pm.updateCreateUserImgInfo)
+		pm.existsInInfoCreateUserImg, pm.newUserImgWithOplog, pm.postupdateUserObj, pm.updateCreateUserImgInfo)
 }
 
 func (pm *ProtocolManager) handlePendingCreateUserImgLogs(oplog *pkgservice.BaseOplog, info *ProcessUserInfo) (types.Bool, []*pkgservice.BaseOplog, error) {
@@ -41,7 +41,7 @@ func (pm *ProtocolManager) handlePendingCreateUserImgLogs(oplog *pkgservice.Base
 
 	return pm.HandlePendingCreateObjectLog(
 		oplog, obj, opData, info,
-		pm.existsInInfoCreateUserImg, pm.newUserImgWithOplog, nil, pm.updateCreateUserImgInfo)
+		pm.existsInInfoCreateUserImg, pm.newUserImgWithOplog, pm.postupdateUserObj, pm.updateCreateUserImgInfo)
 }
 
 func (pm *ProtocolManager) setNewestCreateUserImgLog(oplog *pkgservice.BaseOplog) (types.Bool, error) {
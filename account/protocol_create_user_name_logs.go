@@ -37,7 +37,7 @@ func (pm *ProtocolManager) handleCreateUserNameLogs(oplog *pkgservice.BaseOplog,
 
 		pm.existsInInfoCreateUserName,
 		pm.newUserNameWithOplog,
-		nil,
+		pm.postupdateUserObj,
 		pm.updateCreateUserNameInfo,
 	)
 }
@@ -56,7 +56,7 @@ func (pm *ProtocolManager) handlePendingCreateUserNameLogs(oplog *pkgservice.Bas
 
 		pm.existsInInfoCreateUserName,
 		pm.newUserNameWithOplog,
-		nil,
+		pm.postupdateUserObj,
 		pm.updateCreateUserNameInfo,
 	)
 }
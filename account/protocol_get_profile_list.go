@@ -23,7 +23,7 @@ import (
 )
 
 func (spm *ServiceProtocolManager) GetProfileList(startingID *types.PttID, limit int) ([]*Profile, error) {
-	iter, err := getProfileIter(startingID)
+	iter, err := spm.getProfileIter(startingID)
 	if err != nil {
 		return nil, err
 	}
@@ -39,7 +39,7 @@ func (spm *ServiceProtocolManager) GetProfileList(startingID *types.PttID, limit
 
 		v := iter.Value()
 
-		eachProfile := &Profile{}
+		eachProfile := &Profile{db: spm.db}
 		err = eachProfile.Unmarshal(v)
 		if err != nil {
 			continue
@@ -53,13 +53,13 @@ func (spm *ServiceProtocolManager) GetProfileList(startingID *types.PttID, limit
 	return profileList, nil
 }
 
-func getProfileIter(startingID *types.PttID) (iterator.Iterator, error) {
+func (spm *ServiceProtocolManager) getProfileIter(startingID *types.PttID) (iterator.Iterator, error) {
 	if startingID == nil {
-		return dbAccount.DB().NewIteratorWithPrefix(nil, DBProfilePrefix, pttdb.ListOrderNext)
+		return spm.db.account.DB().NewIteratorWithPrefix(nil, DBProfilePrefix, pttdb.ListOrderNext)
 	}
 
 	// key
-	profile := NewEmptyProfile()
+	profile := NewEmptyProfile(spm.db)
 	profile.SetID(startingID)
 
 	key, err := profile.MarshalKey()
@@ -68,7 +68,7 @@ func getProfileIter(startingID *types.PttID) (iterator.Iterator, error) {
 	}
 
 	// iter
-	iter, err := dbAccount.DB().NewIteratorWithPrefix(key, DBProfilePrefix, pttdb.ListOrderNext)
+	iter, err := spm.db.account.DB().NewIteratorWithPrefix(key, DBProfilePrefix, pttdb.ListOrderNext)
 	if err != nil {
 		return nil, err
 	}
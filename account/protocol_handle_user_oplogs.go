@@ -170,6 +170,8 @@ func (pm *ProtocolManager) HandleSyncNewUserOplogAck(dataBytes []byte, peer *pkg
 		pm.SetUserDB,
 		pm.HandleUserOplogs,
 		pm.postsyncUserOplogs,
+
+		pm.userOplogMerkle,
 	)
 }
 
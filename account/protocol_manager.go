@@ -30,6 +30,7 @@ type ProtocolManager struct {
 	*pkgservice.BaseProtocolManager
 
 	// db
+	db              *accountDB
 	dbUserLock      *types.LockMap
 	userOplogMerkle *pkgservice.Merkle
 
@@ -95,12 +96,14 @@ func newBaseProtocolManager(pm *ProtocolManager, router pkgservice.Router, entit
 		entity, // entity
 		svc,
 
-		dbAccount, //db
+		pm.db.account, //db
 	)
 	if err != nil {
 		return nil
 	}
 
+	b.SetOpRateLimit(ForceSyncUserOplogByMerkleMsg, pkgservice.ForceSyncOplogByMerkleRateLimit)
+
 	return b
 }
 
@@ -114,12 +117,13 @@ func NewProtocolManager(profile *Profile, router pkgservice.Router, svc pkgservi
 	entityIDBytes, _ := entityID.MarshalText()
 	entityIDStr := string(entityIDBytes)
 
-	userOplogMerkle, err := pkgservice.NewMerkle(DBUserOplogPrefix, DBUserMerkleOplogPrefix, profile.ID, dbAccount, "("+entityIDStr+"/"+svc.Name()+":user)")
+	userOplogMerkle, err := pkgservice.NewMerkle(DBUserOplogPrefix, DBUserMerkleOplogPrefix, profile.ID, profile.db.account, "("+entityIDStr+"/"+svc.Name()+":user)")
 	if err != nil {
 		return nil, err
 	}
 
 	pm := &ProtocolManager{
+		db:              profile.db,
 		dbUserLock:      dbUserLock,
 		userOplogMerkle: userOplogMerkle,
 	}
@@ -49,10 +49,10 @@ func (pm *ProtocolManager) CleanUserNode() error {
 }
 
 func (pm *ProtocolManager) InitUserNode(entityID *types.PttID) {
-	userNodeInfo := &UserNodeInfo{}
+	userNodeInfo := &UserNodeInfo{db: pm.db.accountCore}
 	err := userNodeInfo.Get(entityID)
 	if err != nil {
-		userNodeInfo = &UserNodeInfo{ID: entityID}
+		userNodeInfo = &UserNodeInfo{ID: entityID, db: pm.db.accountCore}
 	}
 	pm.userNodeInfo = userNodeInfo
 }
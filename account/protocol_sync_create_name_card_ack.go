@@ -53,7 +53,7 @@ func (pm *ProtocolManager) HandleSyncCreateNameCardAck(dataBytes []byte, peer *p
 
 			pm.SetUserDB,
 			nil,
-			nil,
+			pm.postupdateUserObj,
 			pm.broadcastUserOplogCore,
 		)
 	}
@@ -49,7 +49,7 @@ func (pm *ProtocolManager) HandleSyncCreateUserImgAck(dataBytes []byte, peer *pk
 
 			pm.SetUserDB,
 			nil,
-			nil,
+			pm.postupdateUserObj,
 			pm.broadcastUserOplogCore,
 		)
 	}
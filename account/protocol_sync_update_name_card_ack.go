@@ -50,7 +50,7 @@ func (pm *ProtocolManager) HandleSyncUpdateNameCardAck(dataBytes []byte, peer *p
 
 			pm.SetUserDB,
 			pm.updateSyncNameCard,
-			nil,
+			pm.postupdateUserObj,
 			pm.broadcastUserOplogCore,
 		)
 	}
@@ -51,7 +51,7 @@ func (pm *ProtocolManager) HandleSyncUpdateUserImgAck(dataBytes []byte, peer *pk
 
 			pm.SetUserDB,
 			pm.updateSyncUserImg,
-			nil,
+			pm.postupdateUserObj,
 			pm.broadcastUserOplogCore,
 		)
 
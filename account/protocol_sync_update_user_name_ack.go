@@ -51,7 +51,7 @@ func (pm *ProtocolManager) HandleSyncUpdateUserNameAck(dataBytes []byte, peer *p
 			pm.SetUserDB,
 			pm.updateSyncUserName,
 
-			nil,
+			pm.postupdateUserObj,
 			pm.broadcastUserOplogCore,
 		)
 	}
@@ -60,7 +60,7 @@ func (pm *ProtocolManager) UpdateNameCard(card []byte) (*NameCard, error) {
 		nil,
 
 		pm.broadcastUserOplogCore,
-		nil,
+		pm.postupdateUserObj,
 	)
 	if err != nil {
 		return nil, err
@@ -42,7 +42,7 @@ func (pm *ProtocolManager) handleUpdateNameCardLogs(oplog *pkgservice.BaseOplog,
 		pm.SetUserDB,
 		nil,
 
-		nil,
+		pm.postupdateUserObj,
 
 		pm.updateUpdateNameCardInfo,
 	)
@@ -69,7 +69,7 @@ func (pm *ProtocolManager) handlePendingUpdateNameCardLogs(oplog *pkgservice.Bas
 		pm.SetUserDB,
 		nil,
 
-		nil,
+		pm.postupdateUserObj,
 
 		pm.updateUpdateNameCardInfo,
 	)
@@ -75,7 +75,7 @@ func (pm *ProtocolManager) UpdateUserImg(imgStr string) (*UserImg, error) {
 		nil,
 
 		pm.broadcastUserOplogCore,
-		nil,
+		pm.postupdateUserObj,
 	)
 	if err != nil {
 		return nil, err
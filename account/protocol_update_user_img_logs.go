@@ -45,7 +45,7 @@ func (pm *ProtocolManager) handleUpdateUserImgLogs(oplog *pkgservice.BaseOplog,
 		pm.SetUserDB,
 		nil,
 
-		nil,
+		pm.postupdateUserObj,
 		pm.updateUpdateUserImgInfo,
 	)
 }
@@ -73,7 +73,7 @@ func (pm *ProtocolManager) handlePendingUpdateUserImgLogs(oplog *pkgservice.Base
 		pm.SetUserDB,
 		nil,
 
-		nil,
+		pm.postupdateUserObj,
 
 		pm.updateUpdateUserImgInfo,
 	)
@@ -60,7 +60,7 @@ func (pm *ProtocolManager) UpdateUserName(name []byte) (*UserName, error) {
 		nil,
 
 		pm.broadcastUserOplogCore,
-		nil,
+		pm.postupdateUserObj,
 	)
 	if err != nil {
 		return nil, err
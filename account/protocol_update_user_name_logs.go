@@ -42,7 +42,7 @@ func (pm *ProtocolManager) handleUpdateUserNameLogs(oplog *pkgservice.BaseOplog,
 		pm.SetUserDB,
 		nil,
 
-		nil,
+		pm.postupdateUserObj,
 
 		pm.updateUpdateUserNameInfo,
 	)
@@ -69,7 +69,7 @@ func (pm *ProtocolManager) handlePendingUpdateUserNameLogs(oplog *pkgservice.Bas
 		pm.SetUserDB,
 		nil,
 
-		nil,
+		pm.postupdateUserObj,
 
 		pm.updateUpdateUserNameInfo,
 	)
@@ -25,7 +25,7 @@ func (pm *ProtocolManager) CreateUserOplog(objID *types.PttID, ts types.Timestam
 
 	myID := pm.Entity().GetID()
 
-	oplog, err := NewUserOplog(objID, ts, myID, op, data, myID, pm.dbUserLock)
+	oplog, err := NewUserOplog(objID, ts, myID, op, data, pm.db.account, myID, pm.dbUserLock)
 	if err != nil {
 		return nil, err
 	}
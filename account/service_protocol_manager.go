@@ -20,9 +20,11 @@ import pkgservice "github.com/ailabstw/go-pttai-core/service"
 
 type ServiceProtocolManager struct {
 	*pkgservice.BaseServiceProtocolManager
+
+	db *accountDB
 }
 
-func NewServiceProtocolManager(router pkgservice.Router, service pkgservice.Service) (*ServiceProtocolManager, error) {
+func NewServiceProtocolManager(router pkgservice.Router, service pkgservice.Service, db *accountDB) (*ServiceProtocolManager, error) {
 
 	b, err := pkgservice.NewBaseServiceProtocolManager(router, service)
 	if err != nil {
@@ -31,6 +33,8 @@ func NewServiceProtocolManager(router pkgservice.Router, service pkgservice.Serv
 
 	spm := &ServiceProtocolManager{
 		BaseServiceProtocolManager: b,
+
+		db: db,
 	}
 
 	// load profiles
@@ -56,5 +60,5 @@ func NewServiceProtocolManager(router pkgservice.Router, service pkgservice.Serv
 }
 
 func (spm *ServiceProtocolManager) NewEmptyEntity() pkgservice.Entity {
-	return NewEmptyProfile()
+	return NewEmptyProfile(spm.db)
 }
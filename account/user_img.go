@@ -119,11 +119,11 @@ func AliveUserImgs(typedObjs []*UserImg) []*UserImg {
 func (pm *ProtocolManager) SetUserImgDB(u *UserImg) {
 	spm := pm.Entity().Service().SPM()
 
-	u.SetDB(dbAccount, spm.DBObjLock(), pm.Entity().GetID(), pm.dbUserImgPrefix, pm.dbUserImgIdxPrefix, nil, nil)
+	u.SetDB(pm.db.account, spm.DBObjLock(), pm.Entity().GetID(), pm.dbUserImgPrefix, pm.dbUserImgIdxPrefix, nil, nil)
 }
 
 func (spm *ServiceProtocolManager) SetUserImgDB(u *UserImg) {
-	u.SetDB(dbAccount, spm.DBObjLock(), nil, DBUserImgPrefix, DBUserImgIdxPrefix, nil, nil)
+	u.SetDB(spm.db.account, spm.DBObjLock(), nil, DBUserImgPrefix, DBUserImgIdxPrefix, nil, nil)
 }
 
 func (u *UserImg) Save(isLocked bool) error {
@@ -22,6 +22,7 @@ import (
 	"github.com/ailabstw/go-pttai-core/common"
 	"github.com/ailabstw/go-pttai-core/common/types"
 	"github.com/ailabstw/go-pttai-core/pttdb"
+	"github.com/ailabstw/go-pttai-core/search"
 	pkgservice "github.com/ailabstw/go-pttai-core/service"
 )
 
@@ -29,6 +30,8 @@ type SyncUserNameInfo struct {
 	*pkgservice.BaseSyncInfo `json:"b"`
 
 	Name []byte `json:"N,omitempty"`
+
+	index *search.Index
 }
 
 func NewEmptySyncUserNameInfo() *SyncUserNameInfo {
@@ -54,6 +57,8 @@ type UserName struct {
 	SyncInfo               *SyncUserNameInfo `json:"s,omitempty"`
 
 	Name []byte `json:"N,omitempty"`
+
+	index *search.Index
 }
 
 func NewUserName(
@@ -114,11 +119,13 @@ func AliveUserNames(typedObjs []*UserName) []*UserName {
 func (pm *ProtocolManager) SetUserNameDB(u *UserName) {
 	spm := pm.Entity().Service().SPM()
 
-	u.SetDB(dbAccount, spm.DBObjLock(), pm.Entity().GetID(), pm.dbUserNamePrefix, pm.dbUserNameIdxPrefix, nil, nil)
+	u.index = pm.db.userNameIndex
+	u.SetDB(pm.db.account, spm.DBObjLock(), pm.Entity().GetID(), pm.dbUserNamePrefix, pm.dbUserNameIdxPrefix, nil, nil)
 }
 
 func (spm *ServiceProtocolManager) SetUserNameDB(u *UserName) {
-	u.SetDB(dbAccount, spm.DBObjLock(), nil, DBUserNamePrefix, DBUserNameIdxPrefix, nil, nil)
+	u.index = spm.db.userNameIndex
+	u.SetDB(spm.db.account, spm.DBObjLock(), nil, DBUserNamePrefix, DBUserNameIdxPrefix, nil, nil)
 }
 
 func (u *UserName) Save(isLocked bool) error {
@@ -157,12 +164,13 @@ func (u *UserName) Save(isLocked bool) error {
 		return err
 	}
 
-	return nil
+	return indexUserObj(u.index, u.BaseObject, u.Name)
 }
 
 func (u *UserName) NewEmptyObj() pkgservice.Object {
 	newU := NewEmptyUserName()
 	newU.CloneDB(u.BaseObject)
+	newU.index = u.index
 	return newU
 }
 
@@ -133,13 +133,13 @@ func TestUserName_Save(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			u := tt.u
-			u.SetDB(dbAccount, tLockMap, tEntityID, DBUserNamePrefix, DBUserNameIdxPrefix, nil, nil)
+			u.SetDB(tDB.account, tLockMap, tEntityID, DBUserNamePrefix, DBUserNameIdxPrefix, nil, nil)
 			if err := u.Save(true); (err != nil) != tt.wantErr {
 				t.Errorf("UserName.Save() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
 			key, _ := u.MarshalKey()
-			if isHas, _ := dbAccountCore.Has(key); !isHas {
+			if isHas, _ := tDB.accountCore.Has(key); !isHas {
 				t.Errorf("UserName.Save() id not exists: u: %v", u)
 			}
 		})
@@ -198,12 +198,12 @@ func TestUserName_Get(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			u := tt.u
-			u.SetDB(dbAccount, tLockMap, tEntityID, DBUserNamePrefix, DBUserNameIdxPrefix, nil, nil)
+			u.SetDB(tDB.account, tLockMap, tEntityID, DBUserNamePrefix, DBUserNameIdxPrefix, nil, nil)
 			if err := u.Get(true); (err != nil) != tt.wantErr {
 				t.Errorf("UserName.Get() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
-			tt.want.SetDB(dbAccount, tLockMap, tEntityID, DBUserNamePrefix, DBUserNameIdxPrefix, nil, nil)
+			tt.want.SetDB(tDB.account, tLockMap, tEntityID, DBUserNamePrefix, DBUserNameIdxPrefix, nil, nil)
 			if !reflect.DeepEqual(u, tt.want) {
 				t.Errorf("UserName.Get() u = %v, want %v", u, tt.want)
 			}
@@ -247,13 +247,13 @@ func TestUserName_Delete(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			u := tt.u
-			u.SetDB(dbAccount, tLockMap, tEntityID, DBUserNamePrefix, DBUserNameIdxPrefix, nil, nil)
+			u.SetDB(tDB.account, tLockMap, tEntityID, DBUserNamePrefix, DBUserNameIdxPrefix, nil, nil)
 			if err := u.Delete(true); (err != nil) != tt.wantErr {
 				t.Errorf("UserName.Delete() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
 			newU := &UserName{BaseObject: &pkgservice.BaseObject{ID: tt.args.id}}
-			newU.SetDB(dbAccount, tLockMap, tEntityID, DBUserNamePrefix, DBUserNameIdxPrefix, nil, nil)
+			newU.SetDB(tDB.account, tLockMap, tEntityID, DBUserNamePrefix, DBUserNameIdxPrefix, nil, nil)
 			err := newU.Get(true)
 			if err != leveldb.ErrNotFound {
 				t.Errorf("UserName.Delete() unable to delete: id: %v newU: %v e: %v", tt.args.id, newU, err)
@@ -20,23 +20,29 @@ import (
 	"encoding/json"
 
 	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/pttdb"
 )
 
 type UserNodeInfo struct {
 	ID         *types.PttID
 	UserNodeID *types.PttID `json:"nid"`
 	NUserNode  int          `json:"n"`
+
+	db pttdb.Storage
 }
 
 func NewUserNodeInfo(
 	id *types.PttID,
 	userNodeID *types.PttID,
 	nUserNode int,
+	db pttdb.Storage,
 ) (*UserNodeInfo, error) {
 	return &UserNodeInfo{
 		ID:         id,
 		UserNodeID: userNodeID,
 		NUserNode:  nUserNode,
+
+		db: db,
 	}, nil
 }
 
@@ -51,7 +57,7 @@ func (u *UserNodeInfo) Save() error {
 		return err
 	}
 
-	err = dbAccount.DB().Put(key, marshaled)
+	err = u.db.Put(key, marshaled)
 	if err != nil {
 		return err
 	}
@@ -65,7 +71,7 @@ func (u *UserNodeInfo) Get(id *types.PttID) error {
 	if err != nil {
 		return err
 	}
-	val, err := dbAccount.DB().Get(key)
+	val, err := u.db.Get(key)
 	if err != nil {
 		return err
 	}
@@ -77,7 +83,7 @@ func (u *UserNodeInfo) Delete() error {
 	if err != nil {
 		return err
 	}
-	err = dbAccount.DB().Delete(key)
+	err = u.db.Delete(key)
 	if err != nil {
 		return err
 	}
@@ -19,6 +19,7 @@ package account
 import (
 	"github.com/ailabstw/go-pttai-core/common/types"
 	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/pttdb"
 	pkgservice "github.com/ailabstw/go-pttai-core/service"
 )
 
@@ -30,9 +31,9 @@ func (o *UserOplog) GetBaseOplog() *pkgservice.BaseOplog {
 	return o.BaseOplog
 }
 
-func NewUserOplog(objID *types.PttID, ts types.Timestamp, doerID *types.PttID, op pkgservice.OpType, opData pkgservice.OpData, userID *types.PttID, dbLock *types.LockMap) (*UserOplog, error) {
+func NewUserOplog(objID *types.PttID, ts types.Timestamp, doerID *types.PttID, op pkgservice.OpType, opData pkgservice.OpData, db pttdb.IndexBatch, userID *types.PttID, dbLock *types.LockMap) (*UserOplog, error) {
 
-	oplog, err := pkgservice.NewOplog(objID, ts, doerID, op, opData, dbAccount, userID, DBUserOplogPrefix, DBUserIdxOplogPrefix, DBUserMerkleOplogPrefix, dbLock)
+	oplog, err := pkgservice.NewOplog(objID, ts, doerID, op, opData, db, userID, DBUserOplogPrefix, DBUserIdxOplogPrefix, DBUserMerkleOplogPrefix, dbLock)
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +62,7 @@ func (pm *ProtocolManager) NewUserOplogWithTS(objID *types.PttID, ts types.Times
 	myID := pm.Router().GetMyEntity().GetID()
 	entityID := pm.Entity().GetID()
 
-	oplog, err := NewUserOplog(objID, ts, myID, op, opData, entityID, pm.dbUserLock)
+	oplog, err := NewUserOplog(objID, ts, myID, op, opData, pm.db.account, entityID, pm.dbUserLock)
 	if err != nil {
 		return nil, err
 	}
@@ -73,12 +74,12 @@ func (spm *ServiceProtocolManager) NewUserOplogWithTS(entityID *types.PttID, ts
 
 	myID := spm.Router().GetMyEntity().GetID()
 
-	return NewUserOplog(entityID, ts, myID, op, opData, entityID, spm.GetDBLogLock())
+	return NewUserOplog(entityID, ts, myID, op, opData, spm.db.account, entityID, spm.GetDBLogLock())
 }
 
 func (pm *ProtocolManager) SetUserDB(oplog *pkgservice.BaseOplog) {
 	userID := pm.Entity().GetID()
-	oplog.SetDB(dbAccount, userID, DBUserOplogPrefix, DBUserIdxOplogPrefix, DBUserMerkleOplogPrefix, pm.dbUserLock)
+	oplog.SetDB(pm.db.account, userID, DBUserOplogPrefix, DBUserIdxOplogPrefix, DBUserMerkleOplogPrefix, pm.dbUserLock)
 }
 
 func OplogsToUserOplogs(logs []*pkgservice.BaseOplog) []*UserOplog {
@@ -81,3 +81,69 @@ type UserOpCreateNameCard struct {
 type UserOpUpdateNameCard struct {
 	Hash []byte `json:"H"`
 }
+
+func (o *UserOpCreateProfile) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+}
+
+func (o *UserOpDeleteProfile) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+}
+
+func (o *UserOpTransferProfile) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.PttID(o.ToID)
+}
+
+func (o *UserOpAddUserNode) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.NodeID(o.NodeID)
+}
+
+func (o *UserOpRemoveUserNode) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.NodeID(o.NodeID)
+}
+
+func (o *UserOpCreateUserName) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+}
+
+func (o *UserOpUpdateUserName) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.Bytes(o.Hash)
+}
+
+func (o *UserOpCreateUserImg) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+}
+
+func (o *UserOpUpdateUserImg) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.Bytes(o.Hash)
+}
+
+func (o *UserOpCreateNameCard) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+}
+
+func (o *UserOpUpdateNameCard) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.Bytes(o.Hash)
+}
+
+func init() {
+	pkgservice.RegisterOpData(DBUserOplogPrefix, map[pkgservice.OpType]func() pkgservice.CanonicalOpData{
+		UserOpTypeCreateProfile:   func() pkgservice.CanonicalOpData { return &UserOpCreateProfile{} },
+		UserOpTypeDeleteProfile:   func() pkgservice.CanonicalOpData { return &UserOpDeleteProfile{} },
+		UserOpTypeTransferProfile: func() pkgservice.CanonicalOpData { return &UserOpTransferProfile{} },
+		UserOpTypeAddUserNode:     func() pkgservice.CanonicalOpData { return &UserOpAddUserNode{} },
+		UserOpTypeRemoveUserNode:  func() pkgservice.CanonicalOpData { return &UserOpRemoveUserNode{} },
+		UserOpTypeCreateUserName:  func() pkgservice.CanonicalOpData { return &UserOpCreateUserName{} },
+		UserOpTypeUpdateUserName:  func() pkgservice.CanonicalOpData { return &UserOpUpdateUserName{} },
+		UserOpTypeCreateUserImg:   func() pkgservice.CanonicalOpData { return &UserOpCreateUserImg{} },
+		UserOpTypeUpdateUserImg:   func() pkgservice.CanonicalOpData { return &UserOpUpdateUserImg{} },
+		UserOpTypeCreateNameCard:  func() pkgservice.CanonicalOpData { return &UserOpCreateNameCard{} },
+		UserOpTypeUpdateNameCard:  func() pkgservice.CanonicalOpData { return &UserOpUpdateNameCard{} },
+	})
+}
@@ -0,0 +1,84 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package account
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserOpData_EncodeCanonical(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	id := &types.PttID{1, 2}
+	ts := types.Timestamp{Ts: 1, NanoTs: 2}
+	nodeID := &discover.NodeID{3}
+
+	// prepare test-cases
+	tests := []struct {
+		name string
+		op   pkgservice.OpType
+		data pkgservice.CanonicalOpData
+		want string
+	}{
+		{name: "CreateProfile", op: UserOpTypeCreateProfile, data: &UserOpCreateProfile{}, want: "0101"},
+		{name: "DeleteProfile", op: UserOpTypeDeleteProfile, data: &UserOpDeleteProfile{}, want: "0101"},
+		{name: "TransferProfile", op: UserOpTypeTransferProfile, data: &UserOpTransferProfile{ToID: id}, want: "01012801020000000000000000000000000000000000000000000000000000000000000000000000000000"},
+		{name: "AddUserNode", op: UserOpTypeAddUserNode, data: &UserOpAddUserNode{NodeID: nodeID}, want: "01014003000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"},
+		{name: "RemoveUserNode", op: UserOpTypeRemoveUserNode, data: &UserOpRemoveUserNode{NodeID: nodeID}, want: "01014003000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"},
+		{name: "CreateUserName", op: UserOpTypeCreateUserName, data: &UserOpCreateUserName{}, want: "0101"},
+		{name: "UpdateUserName", op: UserOpTypeUpdateUserName, data: &UserOpUpdateUserName{Hash: []byte{7}}, want: "01010107"},
+		{name: "CreateUserImg", op: UserOpTypeCreateUserImg, data: &UserOpCreateUserImg{}, want: "0101"},
+		{name: "UpdateUserImg", op: UserOpTypeUpdateUserImg, data: &UserOpUpdateUserImg{Hash: []byte{7}}, want: "01010107"},
+		{name: "CreateNameCard", op: UserOpTypeCreateNameCard, data: &UserOpCreateNameCard{}, want: "0101"},
+		{name: "UpdateNameCard", op: UserOpTypeUpdateNameCard, data: &UserOpUpdateNameCard{Hash: []byte{7}}, want: "01010107"},
+	}
+
+	// run test
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pkgservice.MarshalCanonicalData(tt.data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, hex.EncodeToString(got))
+
+			// the same for the op-data unmarshaled from the peers.
+			o, err := pkgservice.NewOplog(id, ts, id, tt.op, tt.data, nil, id, DBUserOplogPrefix, nil, nil, nil)
+			assert.NoError(t, err)
+			o.SignV = pkgservice.SignVersionCanonical
+			want, err := o.MarshalCanonical()
+			assert.NoError(t, err)
+
+			marshaled, err := o.Marshal()
+			assert.NoError(t, err)
+			o2 := &pkgservice.BaseOplog{}
+			err = o2.Unmarshal(marshaled)
+			assert.NoError(t, err)
+			o2.SetDB(nil, id, DBUserOplogPrefix, nil, nil, nil)
+
+			got2, err := o2.MarshalCanonical()
+			assert.NoError(t, err)
+			assert.Equal(t, want, got2)
+		})
+	}
+}
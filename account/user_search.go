@@ -0,0 +1,69 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package account
+
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/search"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+/*
+indexUserObj keeps the search-index of the user-name / name-card in sync with the saved object.
+The doc-id is the user-id (the id of the user-name / name-card).
+*/
+func indexUserObj(idx *search.Index, obj *pkgservice.BaseObject, text []byte) error {
+	if idx == nil {
+		return nil
+	}
+
+	statusClass := types.StatusToStatusClass(obj.Status)
+	if statusClass == types.StatusClassDeleted || len(text) == 0 {
+		return idx.Delete(nil, obj.ID)
+	}
+
+	return idx.Put(nil, obj.ID, text)
+}
+
+/*
+SearchUserIDs returns the ids of the users whose user-name or name-card match the query.
+*/
+func (spm *ServiceProtocolManager) SearchUserIDs(query string, limit int) ([]*types.PttID, error) {
+	userIDs := make([]*types.PttID, 0)
+	exists := make(map[types.PttID]bool)
+
+	for _, idx := range []*search.Index{spm.db.userNameIndex, spm.db.nameCardIndex} {
+		docs, err := idx.Search(query, nil, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, doc := range docs {
+			if exists[*doc.ID] {
+				continue
+			}
+			exists[*doc.ID] = true
+			userIDs = append(userIDs, doc.ID)
+
+			if limit > 0 && len(userIDs) >= limit {
+				return userIDs, nil
+			}
+		}
+	}
+
+	return userIDs, nil
+}
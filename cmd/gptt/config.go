@@ -0,0 +1,631 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of go-pttai.
+//
+// go-pttai is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-pttai is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-pttai. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/ailabstw/go-pttai-core/account"
+	"github.com/ailabstw/go-pttai-core/content"
+	"github.com/ailabstw/go-pttai-core/friend"
+	"github.com/ailabstw/go-pttai-core/group"
+	"github.com/ailabstw/go-pttai-core/me"
+	"github.com/ailabstw/go-pttai-core/node"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	"github.com/ailabstw/go-pttai-core/service"
+	"github.com/naoina/toml"
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+/*
+gpttConfig is the layered config of gptt. The defaults are overridden by the
+TOML config file, and then by the environment variables and the flags.
+
+The DataDir of the services defaults to the sub-directories of Node.DataDir.
+*/
+type gpttConfig struct {
+	Node    nodeConfig
+	P2P     p2pConfig
+	Router  routerConfig
+	Me      meConfig
+	Account accountConfig
+	Friend  friendConfig
+}
+
+type nodeConfig struct {
+	DataDir   string
+	UserIdent string `toml:",omitempty"`
+	NetworkID uint32
+
+	// IPCPath is placed inside the datadir if it is a simple file name.
+	// An empty path disables IPC.
+	IPCPath string
+
+	// The HTTP, websocket, signal and metrics servers are not started if the
+	// hosts are empty.
+	HTTPHost         string
+	HTTPPort         int
+	HTTPCors         []string
+	HTTPVirtualHosts []string
+	HTTPModules      []string
+	ExternHTTPAddr   string `toml:",omitempty"`
+
+	WSHost    string
+	WSPort    int
+	WSOrigins []string
+	WSModules []string
+
+	RPCAuth bool
+
+	SignalHost string
+	SignalPort int
+
+	MetricsHost string
+	MetricsPort int
+}
+
+type p2pConfig struct {
+	MaxPeers        int
+	MaxPendingPeers int `toml:",omitempty"`
+	DialRatio       int `toml:",omitempty"`
+	NoDiscovery     bool
+	NoDial          bool `toml:",omitempty"`
+	ListenAddr      string
+
+	// BootstrapNodes are the enode URLs.
+	BootstrapNodes []string
+
+	// P2PBootnodes are the p2pnode URLs.
+	P2PListenAddr string   `toml:",omitempty"`
+	P2PBootnodes  []string `toml:",omitempty"`
+
+	SignalServerURL string
+}
+
+type routerConfig struct {
+	DataDir string `toml:",omitempty"`
+
+	MaxPeers          int
+	MaxHubPeers       int
+	MaxImportantPeers int
+	MaxMemberPeers    int
+	MaxPendingPeers   int
+	MaxRandomPeers    int
+
+	ExpireOplogSeconds int
+
+	IsPrivateAsPublic bool
+
+	// HubNodes are the hex node-ids of the hubs.
+	IsHub    bool
+	HubNodes []string
+
+	// HubQuotaBytes, HubNodeQuotaBytes and HubTotalQuotaBytes are the quotas of the relayed data
+	// for each user, from each sending node, and in total.
+	HubQuotaBytes      int
+	HubNodeQuotaBytes  int
+	HubTotalQuotaBytes int
+	HubExpireSeconds   int
+
+	// IsRelay is whether to forward the relayed data (currently the pending friend-oplogs and the objects / blocks) toward the target nodes.
+	// RelayQuotaBytes is the quota of the data relayed for each peer in each relay-window.
+	IsRelay         bool
+	RelayQuotaBytes int
+
+	// IsSignCanonical is whether to sign the oplogs in the canonical encoding instead of json.
+	// Enable only when all the nodes are upgraded, the legacy nodes are not able to verify the canonical oplogs.
+	IsSignCanonical bool
+}
+
+type meConfig struct {
+	DataDir string `toml:",omitempty"`
+}
+
+type accountConfig struct {
+	DataDir string `toml:",omitempty"`
+}
+
+type friendConfig struct {
+	DataDir string `toml:",omitempty"`
+
+	MaxSyncRandomSeconds int
+	MinSyncRandomSeconds int
+}
+
+/*
+pttConfig is the configs of the node and the services, converted from gpttConfig.
+*/
+type pttConfig struct {
+	Node    *node.Config
+	Me      *me.Config
+	Account *account.Config
+	Friend  *friend.Config
+	Group   *group.Config
+	Content *content.Config
+	Router  *service.Config
+}
+
+// These settings ensure that TOML keys use the same names as Go struct fields.
+// The keys are matched case-insensitively with '_' ignored, so both HTTPPort
+// and http_port are accepted.
+var tomlSettings = toml.Config{
+	NormFieldName: func(rt reflect.Type, key string) string {
+		return strings.ToLower(strings.Replace(key, "_", "", -1))
+	},
+	FieldToKey: func(rt reflect.Type, field string) string {
+		return field
+	},
+	MissingField: func(rt reflect.Type, field string) error {
+		section, ok := tomlSections[rt]
+		if !ok {
+			return fmt.Errorf("section '%s' is not defined", field)
+		}
+		return fmt.Errorf("field '%s' is not defined in [%s]", field, section)
+	},
+}
+
+var tomlSections = map[reflect.Type]string{
+	reflect.TypeOf(nodeConfig{}):    "Node",
+	reflect.TypeOf(p2pConfig{}):     "P2P",
+	reflect.TypeOf(routerConfig{}):  "Router",
+	reflect.TypeOf(meConfig{}):      "Me",
+	reflect.TypeOf(accountConfig{}): "Account",
+	reflect.TypeOf(friendConfig{}):  "Friend",
+}
+
+func defaultGpttConfig() *gpttConfig {
+	return &gpttConfig{
+		Node: nodeConfig{
+			DataDir:          node.DefaultDataDir(),
+			NetworkID:        node.DefaultConfig.NetworkID,
+			IPCPath:          node.DefaultConfig.IPCPath,
+			HTTPHost:         node.DefaultConfig.HTTPHost,
+			HTTPPort:         node.DefaultConfig.HTTPPort,
+			HTTPCors:         node.DefaultConfig.HTTPCors,
+			HTTPVirtualHosts: node.DefaultConfig.HTTPVirtualHosts,
+			HTTPModules:      node.DefaultConfig.HTTPModules,
+			WSHost:           node.DefaultConfig.WSHost,
+			WSPort:           node.DefaultConfig.WSPort,
+			WSOrigins:        []string{},
+			WSModules:        []string{},
+			SignalHost:       node.DefaultConfig.SignalHost,
+			SignalPort:       node.DefaultConfig.SignalPort,
+			MetricsHost:      node.DefaultConfig.MetricsHost,
+			MetricsPort:      node.DefaultConfig.MetricsPort,
+		},
+		P2P: p2pConfig{
+			MaxPeers:       DefaultMaxPeers,
+			BootstrapNodes: []string{},
+		},
+		Router: routerConfig{
+			MaxPeers:           service.DefaultConfig.MaxPeers,
+			MaxHubPeers:        service.DefaultConfig.MaxHubPeers,
+			MaxImportantPeers:  service.DefaultConfig.MaxImportantPeers,
+			MaxMemberPeers:     service.DefaultConfig.MaxMemberPeers,
+			MaxPendingPeers:    service.DefaultConfig.MaxPendingPeers,
+			MaxRandomPeers:     service.DefaultConfig.MaxRandomPeers,
+			ExpireOplogSeconds: service.DefaultConfig.ExpireOplogSeconds,
+			IsPrivateAsPublic:  service.DefaultConfig.IsPrivateAsPublic,
+			IsHub:              service.DefaultConfig.IsHub,
+			HubNodes:           []string{},
+			HubQuotaBytes:      service.DefaultConfig.HubQuotaBytes,
+			HubNodeQuotaBytes:  service.DefaultConfig.HubNodeQuotaBytes,
+			HubTotalQuotaBytes: service.DefaultConfig.HubTotalQuotaBytes,
+			HubExpireSeconds:   service.DefaultConfig.HubExpireSeconds,
+			IsRelay:            service.DefaultConfig.IsRelay,
+			RelayQuotaBytes:    service.DefaultConfig.RelayQuotaBytes,
+			IsSignCanonical:    service.DefaultConfig.IsSignCanonical,
+		},
+		Friend: friendConfig{
+			MaxSyncRandomSeconds: friend.DefaultConfig.MaxSyncRandomSeconds,
+			MinSyncRandomSeconds: friend.DefaultConfig.MinSyncRandomSeconds,
+		},
+	}
+}
+
+/*
+loadConfig overrides cfg with the TOML config file.
+*/
+func loadConfig(file string, cfg *gpttConfig) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = tomlSettings.NewDecoder(bufio.NewReader(f)).Decode(cfg)
+	// Add file name to errors that have a line number.
+	if _, ok := err.(*toml.LineError); ok {
+		err = errors.New(file + ", " + err.Error())
+	}
+	return err
+}
+
+func writeConfig(w io.Writer, cfg *gpttConfig) error {
+	return tomlSettings.NewEncoder(w).Encode(cfg)
+}
+
+/*
+makeConfig loads the layered config: the defaults, the config file, and then
+the environment variables and the flags. The config file is --config, or
+gptt.toml in the datadir if exists.
+
+It returns the config and the path of the loaded config file (empty if no
+config file is loaded).
+*/
+func makeConfig(ctx *cli.Context) (*gpttConfig, string, error) {
+	cfg := defaultGpttConfig()
+	if ctx.GlobalIsSet(DataDirFlag.Name) {
+		cfg.Node.DataDir = expandPath(ctx.GlobalString(DataDirFlag.Name))
+	}
+
+	file := configFile(ctx, cfg.Node.DataDir)
+	if file != "" {
+		if err := loadConfig(file, cfg); err != nil {
+			return nil, "", err
+		}
+	}
+
+	applyFlags(ctx, cfg)
+
+	return cfg, file, nil
+}
+
+/*
+configFile returns the --config file, or gptt.toml in the datadir if exists.
+*/
+func configFile(ctx *cli.Context, dataDir string) string {
+	if file := ctx.GlobalString(ConfigFileFlag.Name); file != "" {
+		return expandPath(file)
+	}
+
+	file := filepath.Join(dataDir, DefaultConfigFile)
+	if _, err := os.Stat(file); err != nil {
+		return ""
+	}
+
+	return file
+}
+
+/*
+validate checks the config, and reports all the invalid fields at once.
+*/
+func (c *gpttConfig) validate() error {
+	var errs []string
+	addErr := func(field string, format string, args ...interface{}) {
+		errs = append(errs, field+": "+fmt.Sprintf(format, args...))
+	}
+
+	// node
+	if c.Node.DataDir == "" {
+		addErr("Node.DataDir", "must be set")
+	}
+	if strings.ContainsAny(c.Node.UserIdent, "/") {
+		addErr("Node.UserIdent", "must not contain '/'")
+	}
+	switch c.Node.NetworkID {
+	case node.Mainnet, node.Testnet, node.Devnet:
+	default:
+		addErr("Node.NetworkID", "unknown network %v (1=mainnet, 2=testnet, 3=devnet)", c.Node.NetworkID)
+	}
+
+	ports := make(map[string]string)
+	checkEndpoint := func(name string, host string, port int) {
+		if port < 0 || port > 65535 {
+			addErr("Node."+name+"Port", "%v is out of range [0, 65535]", port)
+			return
+		}
+		if host == "" {
+			return
+		}
+		if port == 0 {
+			return
+		}
+		endpoint := fmt.Sprintf("%v:%v", host, port)
+		if other, ok := ports[endpoint]; ok {
+			addErr("Node."+name+"Port", "%v is already used by Node.%vPort", endpoint, other)
+			return
+		}
+		ports[endpoint] = name
+	}
+	checkEndpoint("HTTP", c.Node.HTTPHost, c.Node.HTTPPort)
+	checkEndpoint("WS", c.Node.WSHost, c.Node.WSPort)
+	checkEndpoint("Signal", c.Node.SignalHost, c.Node.SignalPort)
+	checkEndpoint("Metrics", c.Node.MetricsHost, c.Node.MetricsPort)
+
+	if c.Node.RPCAuth && c.Node.HTTPHost == "" && c.Node.WSHost == "" {
+		addErr("Node.RPCAuth", "requires Node.HTTPHost or Node.WSHost")
+	}
+
+	// p2p
+	if c.P2P.MaxPeers < 0 {
+		addErr("P2P.MaxPeers", "must not be negative")
+	}
+	if c.P2P.MaxPendingPeers < 0 {
+		addErr("P2P.MaxPendingPeers", "must not be negative")
+	}
+	if c.P2P.DialRatio < 0 {
+		addErr("P2P.DialRatio", "must not be negative")
+	}
+	if c.P2P.ListenAddr != "" {
+		if _, _, err := net.SplitHostPort(c.P2P.ListenAddr); err != nil {
+			addErr("P2P.ListenAddr", "invalid address %q (expected host:port, e.g. ':9487')", c.P2P.ListenAddr)
+		}
+	}
+	for i, rawurl := range c.P2P.BootstrapNodes {
+		if _, err := discover.ParseNode(rawurl); err != nil {
+			addErr(fmt.Sprintf("P2P.BootstrapNodes[%v]", i), "invalid enode %q: %v", rawurl, err)
+		}
+	}
+	for i, rawurl := range c.P2P.P2PBootnodes {
+		if _, err := discover.ParseP2PNode(rawurl); err != nil {
+			addErr(fmt.Sprintf("P2P.P2PBootnodes[%v]", i), "invalid p2pnode %q: %v", rawurl, err)
+		}
+	}
+	if c.P2P.SignalServerURL != "" {
+		u, err := url.Parse(c.P2P.SignalServerURL)
+		switch {
+		case err != nil:
+			addErr("P2P.SignalServerURL", "invalid url %q: %v", c.P2P.SignalServerURL, err)
+		case u.Scheme != "ws" && u.Scheme != "wss":
+			addErr("P2P.SignalServerURL", "scheme of %q must be ws or wss", c.P2P.SignalServerURL)
+		case u.Host == "":
+			addErr("P2P.SignalServerURL", "host of %q must be set", c.P2P.SignalServerURL)
+		}
+	}
+
+	// router
+	if c.Router.MaxPeers <= 0 {
+		addErr("Router.MaxPeers", "must be positive")
+	}
+	routerPeers := []struct {
+		name string
+		n    int
+	}{
+		{"MaxHubPeers", c.Router.MaxHubPeers},
+		{"MaxImportantPeers", c.Router.MaxImportantPeers},
+		{"MaxMemberPeers", c.Router.MaxMemberPeers},
+		{"MaxPendingPeers", c.Router.MaxPendingPeers},
+		{"MaxRandomPeers", c.Router.MaxRandomPeers},
+	}
+	for _, each := range routerPeers {
+		switch {
+		case each.n < 0:
+			addErr("Router."+each.name, "must not be negative")
+		case each.n > c.Router.MaxPeers:
+			addErr("Router."+each.name, "%v exceeds Router.MaxPeers (%v)", each.n, c.Router.MaxPeers)
+		}
+	}
+	if c.Router.ExpireOplogSeconds <= 0 {
+		addErr("Router.ExpireOplogSeconds", "must be positive")
+	}
+	for i, hubNode := range c.Router.HubNodes {
+		if _, err := discover.HexID(hubNode); err != nil {
+			addErr(fmt.Sprintf("Router.HubNodes[%v]", i), "invalid node-id %q: %v", hubNode, err)
+		}
+	}
+	if c.Router.IsHub {
+		if c.Router.HubQuotaBytes <= 0 {
+			addErr("Router.HubQuotaBytes", "must be positive for the hub")
+		}
+		if c.Router.HubNodeQuotaBytes <= 0 {
+			addErr("Router.HubNodeQuotaBytes", "must be positive for the hub")
+		}
+		if c.Router.HubTotalQuotaBytes <= 0 {
+			addErr("Router.HubTotalQuotaBytes", "must be positive for the hub")
+		}
+		if c.Router.HubExpireSeconds <= 0 {
+			addErr("Router.HubExpireSeconds", "must be positive for the hub")
+		}
+	}
+	if c.Router.IsRelay && c.Router.RelayQuotaBytes <= 0 {
+		addErr("Router.RelayQuotaBytes", "must be positive for the relay")
+	}
+
+	// friend
+	if c.Friend.MinSyncRandomSeconds <= 0 {
+		addErr("Friend.MinSyncRandomSeconds", "must be positive")
+	}
+	if c.Friend.MaxSyncRandomSeconds < c.Friend.MinSyncRandomSeconds {
+		addErr("Friend.MaxSyncRandomSeconds", "%v is less than Friend.MinSyncRandomSeconds (%v)", c.Friend.MaxSyncRandomSeconds, c.Friend.MinSyncRandomSeconds)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%v:\n  %v", ErrInvalidConfig, strings.Join(errs, "\n  "))
+}
+
+/*
+resolveDataDir resolves the DataDir of the service as the sub-directory of
+Node.DataDir if not set.
+*/
+func (c *gpttConfig) resolveDataDir(dataDir string, name string) string {
+	if dataDir != "" {
+		return expandPath(dataDir)
+	}
+
+	return filepath.Join(c.Node.DataDir, name)
+}
+
+/*
+pttConfig converts to the configs of the node and the services.
+The passphrase is to encrypt / decrypt the node key and my key-files.
+*/
+func (c *gpttConfig) pttConfig(passphrase string) (*pttConfig, error) {
+	// node
+	nodeCfg := node.DefaultConfig
+	nodeCfg.Name = clientIdentifier
+	nodeCfg.DataDir = expandPath(c.Node.DataDir)
+	nodeCfg.UserIdent = c.Node.UserIdent
+	nodeCfg.NetworkID = c.Node.NetworkID
+	nodeCfg.KeyPassphrase = passphrase
+	nodeCfg.IPCPath = c.Node.IPCPath
+	nodeCfg.HTTPHost = c.Node.HTTPHost
+	nodeCfg.HTTPPort = c.Node.HTTPPort
+	nodeCfg.HTTPCors = c.Node.HTTPCors
+	nodeCfg.HTTPVirtualHosts = c.Node.HTTPVirtualHosts
+	nodeCfg.HTTPModules = c.Node.HTTPModules
+	nodeCfg.ExternHTTPAddr = c.Node.ExternHTTPAddr
+	if nodeCfg.ExternHTTPAddr == "" && nodeCfg.HTTPHost != "" {
+		nodeCfg.ExternHTTPAddr = fmt.Sprintf("http://%v:%v", nodeCfg.HTTPHost, nodeCfg.HTTPPort)
+	}
+	nodeCfg.WSHost = c.Node.WSHost
+	nodeCfg.WSPort = c.Node.WSPort
+	nodeCfg.WSOrigins = c.Node.WSOrigins
+	nodeCfg.WSModules = c.Node.WSModules
+	nodeCfg.RPCAuth = c.Node.RPCAuth
+	nodeCfg.SignalHost = c.Node.SignalHost
+	nodeCfg.SignalPort = c.Node.SignalPort
+	nodeCfg.MetricsHost = c.Node.MetricsHost
+	nodeCfg.MetricsPort = c.Node.MetricsPort
+
+	// p2p
+	nodeCfg.P2P.MaxPeers = c.P2P.MaxPeers
+	nodeCfg.P2P.MaxPendingPeers = c.P2P.MaxPendingPeers
+	nodeCfg.P2P.DialRatio = c.P2P.DialRatio
+	nodeCfg.P2P.NoDiscovery = c.P2P.NoDiscovery
+	nodeCfg.P2P.NoDial = c.P2P.NoDial
+	nodeCfg.P2P.ListenAddr = c.P2P.ListenAddr
+	nodeCfg.P2P.P2PListenAddr = c.P2P.P2PListenAddr
+	for _, rawurl := range c.P2P.BootstrapNodes {
+		n, err := discover.ParseNode(rawurl)
+		if err != nil {
+			return nil, err
+		}
+		nodeCfg.P2P.BootstrapNodes = append(nodeCfg.P2P.BootstrapNodes, n)
+	}
+	for _, rawurl := range c.P2P.P2PBootnodes {
+		n, err := discover.ParseP2PNode(rawurl)
+		if err != nil {
+			return nil, err
+		}
+		nodeCfg.P2P.P2PBootnodes = append(nodeCfg.P2P.P2PBootnodes, n)
+	}
+	if c.P2P.SignalServerURL != "" {
+		u, err := url.Parse(c.P2P.SignalServerURL)
+		if err != nil {
+			return nil, err
+		}
+		nodeCfg.P2P.SignalServerURL = *u
+	}
+
+	// router
+	routerCfg := service.DefaultConfig
+	routerCfg.DataDir = c.resolveDataDir(c.Router.DataDir, "service")
+	routerCfg.Version = clientVersion
+	routerCfg.GitCommit = gitCommit
+	routerCfg.MaxPeers = c.Router.MaxPeers
+	routerCfg.MaxHubPeers = c.Router.MaxHubPeers
+	routerCfg.MaxImportantPeers = c.Router.MaxImportantPeers
+	routerCfg.MaxMemberPeers = c.Router.MaxMemberPeers
+	routerCfg.MaxPendingPeers = c.Router.MaxPendingPeers
+	routerCfg.MaxRandomPeers = c.Router.MaxRandomPeers
+	routerCfg.ExpireOplogSeconds = c.Router.ExpireOplogSeconds
+	routerCfg.IsPrivateAsPublic = c.Router.IsPrivateAsPublic
+	routerCfg.IsHub = c.Router.IsHub
+	routerCfg.HubNodes = c.Router.HubNodes
+	routerCfg.HubQuotaBytes = c.Router.HubQuotaBytes
+	routerCfg.HubNodeQuotaBytes = c.Router.HubNodeQuotaBytes
+	routerCfg.HubTotalQuotaBytes = c.Router.HubTotalQuotaBytes
+	routerCfg.HubExpireSeconds = c.Router.HubExpireSeconds
+	routerCfg.IsRelay = c.Router.IsRelay
+	routerCfg.RelayQuotaBytes = c.Router.RelayQuotaBytes
+	routerCfg.IsSignCanonical = c.Router.IsSignCanonical
+
+	// services
+	meCfg := &me.Config{
+		DataDir:    c.resolveDataDir(c.Me.DataDir, "me"),
+		Passphrase: passphrase,
+	}
+
+	accountCfg := &account.Config{
+		DataDir: c.resolveDataDir(c.Account.DataDir, "account"),
+	}
+
+	friendCfg := &friend.Config{
+		DataDir:              c.resolveDataDir(c.Friend.DataDir, "friend"),
+		MaxSyncRandomSeconds: c.Friend.MaxSyncRandomSeconds,
+		MinSyncRandomSeconds: c.Friend.MinSyncRandomSeconds,
+	}
+
+	groupCfg := group.DefaultConfig
+	groupCfg.DataDir = filepath.Join(nodeCfg.DataDir, "group")
+
+	contentCfg := content.DefaultConfig
+	contentCfg.DataDir = filepath.Join(nodeCfg.DataDir, "content")
+
+	return &pttConfig{
+		Node:    &nodeCfg,
+		Me:      meCfg,
+		Account: accountCfg,
+		Friend:  friendCfg,
+		Group:   &groupCfg,
+		Content: &contentCfg,
+		Router:  &routerCfg,
+	}, nil
+}
+
+/*
+loadPttConfig loads and validates the layered config, and converts to the
+configs of the node and the services.
+*/
+func loadPttConfig(ctx *cli.Context) (*pttConfig, error) {
+	cfg, _, err := makeConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	pass, err := passphrase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.pttConfig(pass)
+}
+
+func dumpConfig(ctx *cli.Context) error {
+	cfg, _, err := makeConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if ctx.NArg() > 0 {
+		out, err = os.OpenFile(ctx.Args().Get(0), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+
+	return writeConfig(out, cfg)
+}
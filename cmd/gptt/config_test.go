@@ -0,0 +1,151 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of go-pttai.
+//
+// go-pttai is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-pttai is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-pttai. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+func testNewContext(t *testing.T, args ...string) *cli.Context {
+	app := cli.NewApp()
+	app.Flags = configFlags
+
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	for _, f := range configFlags {
+		f.Apply(set)
+	}
+	err := set.Parse(args)
+	assert.NoError(t, err)
+
+	return cli.NewContext(app, set, nil)
+}
+
+func testWriteConfig(t *testing.T, dir string, content string) string {
+	file := filepath.Join(dir, DefaultConfigFile)
+	err := ioutil.WriteFile(file, []byte(content), 0600)
+	assert.NoError(t, err)
+
+	return file
+}
+
+func TestDefaultConfig(t *testing.T) {
+	cfg := defaultGpttConfig()
+	assert.NoError(t, cfg.validate())
+
+	// dumped config is able to be loaded.
+	buf := &bytes.Buffer{}
+	err := writeConfig(buf, cfg)
+	assert.NoError(t, err)
+
+	cfg2 := &gpttConfig{}
+	err = tomlSettings.NewDecoder(buf).Decode(cfg2)
+	assert.NoError(t, err)
+	assert.Equal(t, cfg, cfg2)
+}
+
+func TestMakeConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-gptt-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	testWriteConfig(t, dir, `
+[Node]
+HTTPHost = "127.0.0.1"
+http_port = 9774
+
+[P2P]
+MaxPeers = 30
+
+[Me]
+DataDir = "/tmp/me"
+
+[Friend]
+MaxSyncRandomSeconds = 20
+`)
+
+	// config file in the datadir, overridden by the flags and the env.
+	os.Setenv("PTT_MAXPEERS", "40")
+	defer os.Unsetenv("PTT_MAXPEERS")
+
+	ctx := testNewContext(t, "--datadir", dir, "--rpcport", "9775", "--hub")
+	cfg, file, err := makeConfig(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, DefaultConfigFile), file)
+	assert.Equal(t, dir, cfg.Node.DataDir)
+	assert.Equal(t, "127.0.0.1", cfg.Node.HTTPHost)
+	assert.Equal(t, 9775, cfg.Node.HTTPPort)
+	assert.Equal(t, 40, cfg.P2P.MaxPeers)
+	assert.Equal(t, true, cfg.Router.IsHub)
+	assert.Equal(t, 20, cfg.Friend.MaxSyncRandomSeconds)
+	assert.Equal(t, 5, cfg.Friend.MinSyncRandomSeconds)
+	assert.NoError(t, cfg.validate())
+
+	pttCfg, err := cfg.pttConfig("")
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/me", pttCfg.Me.DataDir)
+	assert.Equal(t, filepath.Join(dir, "friend"), pttCfg.Friend.DataDir)
+	assert.Equal(t, filepath.Join(dir, "service"), pttCfg.Router.DataDir)
+	assert.Equal(t, 40, pttCfg.Node.P2P.MaxPeers)
+	assert.Equal(t, "http://127.0.0.1:9775", pttCfg.Node.ExternHTTPAddr)
+
+	// unknown field
+	file = testWriteConfig(t, dir, "[Node]\nHTTPPrt = 9774\n")
+	ctx = testNewContext(t, "--datadir", dir)
+	_, _, err = makeConfig(ctx)
+	assert.EqualError(t, err, file+", line 2: field 'HTTPPrt' is not defined in [Node]")
+
+	// missing --config
+	ctx = testNewContext(t, "--config", filepath.Join(dir, "not-exists.toml"))
+	_, _, err = makeConfig(ctx)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := defaultGpttConfig()
+	cfg.Node.DataDir = ""
+	cfg.Node.HTTPHost = "127.0.0.1"
+	cfg.Node.WSHost = "127.0.0.1"
+	cfg.Node.WSPort = cfg.Node.HTTPPort
+	cfg.P2P.ListenAddr = "9487"
+	cfg.P2P.SignalServerURL = "http://127.0.0.1:9489/signal"
+	cfg.Router.MaxHubPeers = cfg.Router.MaxPeers + 1
+	cfg.Router.HubNodes = []string{"invalid"}
+	cfg.Friend.MinSyncRandomSeconds = 0
+
+	err := cfg.validate()
+	assert.Error(t, err)
+
+	errs := strings.Split(err.Error(), "\n  ")
+	assert.Equal(t, ErrInvalidConfig.Error()+":", errs[0])
+	assert.Equal(t, 7, len(errs)-1)
+	assert.Contains(t, errs[1], "Node.DataDir")
+	assert.Contains(t, errs[2], "Node.WSPort")
+	assert.Contains(t, errs[3], "P2P.ListenAddr")
+	assert.Contains(t, errs[4], "P2P.SignalServerURL")
+	assert.Contains(t, errs[5], "Router.MaxHubPeers")
+	assert.Contains(t, errs[6], "Router.HubNodes[0]")
+	assert.Contains(t, errs[7], "Friend.MinSyncRandomSeconds")
+}
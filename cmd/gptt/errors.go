@@ -0,0 +1,28 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of go-pttai.
+//
+// go-pttai is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-pttai is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-pttai. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import "errors"
+
+var (
+	ErrInvalidConfig  = errors.New("invalid config")
+	ErrConfigExists   = errors.New("config file already exists")
+	ErrNoKey          = errors.New("no key-files in the datadir, run 'gptt init' first")
+	ErrKeyLocked      = errors.New("the key-files are encrypted, set PTT_PASSPHRASE or --passphrasefile")
+	ErrEmptyMnemonic  = errors.New("empty mnemonic")
+	ErrSamePassphrase = errors.New("the new passphrase is the same as the old one")
+)
@@ -0,0 +1,390 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of go-pttai.
+//
+// go-pttai is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-pttai is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-pttai. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/ailabstw/go-pttai-core/node"
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+// The flags override the config file. Each flag can also be set with the
+// environment variable PTT_<FLAG>.
+var (
+	ConfigFileFlag = cli.StringFlag{
+		Name:   "config",
+		Usage:  "TOML configuration file (default = <datadir>/" + DefaultConfigFile + " if exists)",
+		EnvVar: "PTT_CONFIG",
+	}
+	DataDirFlag = cli.StringFlag{
+		Name:   "datadir",
+		Usage:  "Data directory for the databases and keystore",
+		Value:  node.DefaultDataDir(),
+		EnvVar: "PTT_DATADIR",
+	}
+	VerbosityFlag = cli.IntFlag{
+		Name:   "verbosity",
+		Usage:  "Logging verbosity: 0=silent, 1=error, 2=warn, 3=info, 4=debug, 5=detail",
+		Value:  DefaultVerbosity,
+		EnvVar: "PTT_VERBOSITY",
+	}
+	PassphraseFileFlag = cli.StringFlag{
+		Name:   "passphrasefile",
+		Usage:  "File containing the passphrase of the key-files (or set PTT_PASSPHRASE)",
+		EnvVar: "PTT_PASSPHRASEFILE",
+	}
+	NewPassphraseFileFlag = cli.StringFlag{
+		Name:   "newpassphrasefile",
+		Usage:  "File containing the new passphrase for 'key passphrase' (or set PTT_NEW_PASSPHRASE)",
+		EnvVar: "PTT_NEWPASSPHRASEFILE",
+	}
+
+	// IPC settings
+	IPCDisabledFlag = cli.BoolFlag{
+		Name:   "ipcdisable",
+		Usage:  "Disable the IPC-RPC server",
+		EnvVar: "PTT_IPCDISABLE",
+	}
+	IPCPathFlag = cli.StringFlag{
+		Name:   "ipcpath",
+		Usage:  "Filename for IPC socket/pipe within the datadir (explicit paths escape it)",
+		EnvVar: "PTT_IPCPATH",
+	}
+
+	// RPC settings
+	RPCEnabledFlag = cli.BoolFlag{
+		Name:   "rpc",
+		Usage:  "Enable the HTTP-RPC server",
+		EnvVar: "PTT_RPC",
+	}
+	RPCListenAddrFlag = cli.StringFlag{
+		Name:   "rpcaddr",
+		Usage:  "HTTP-RPC server listening interface",
+		Value:  DefaultRPCHost,
+		EnvVar: "PTT_RPCADDR",
+	}
+	RPCPortFlag = cli.IntFlag{
+		Name:   "rpcport",
+		Usage:  "HTTP-RPC server listening port",
+		Value:  node.DefaultHTTPPort,
+		EnvVar: "PTT_RPCPORT",
+	}
+	RPCCORSDomainFlag = cli.StringFlag{
+		Name:   "rpccorsdomain",
+		Usage:  "Comma separated list of domains from which to accept cross origin requests (browser enforced)",
+		EnvVar: "PTT_RPCCORSDOMAIN",
+	}
+	RPCVirtualHostsFlag = cli.StringFlag{
+		Name:   "rpcvhosts",
+		Usage:  "Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard.",
+		Value:  strings.Join(node.DefaultConfig.HTTPVirtualHosts, ","),
+		EnvVar: "PTT_RPCVHOSTS",
+	}
+	RPCApiFlag = cli.StringFlag{
+		Name:   "rpcapi",
+		Usage:  "API's offered over the HTTP-RPC interface",
+		EnvVar: "PTT_RPCAPI",
+	}
+	RPCAuthFlag = cli.BoolFlag{
+		Name:   "rpcauth",
+		Usage:  "Require the api-tokens on the HTTP-RPC and WS-RPC servers",
+		EnvVar: "PTT_RPCAUTH",
+	}
+	WSEnabledFlag = cli.BoolFlag{
+		Name:   "ws",
+		Usage:  "Enable the WS-RPC server",
+		EnvVar: "PTT_WS",
+	}
+	WSListenAddrFlag = cli.StringFlag{
+		Name:   "wsaddr",
+		Usage:  "WS-RPC server listening interface",
+		Value:  DefaultRPCHost,
+		EnvVar: "PTT_WSADDR",
+	}
+	WSPortFlag = cli.IntFlag{
+		Name:   "wsport",
+		Usage:  "WS-RPC server listening port",
+		Value:  node.DefaultWSPort,
+		EnvVar: "PTT_WSPORT",
+	}
+	WSAllowedOriginsFlag = cli.StringFlag{
+		Name:   "wsorigins",
+		Usage:  "Origins from which to accept websockets requests",
+		EnvVar: "PTT_WSORIGINS",
+	}
+	WSApiFlag = cli.StringFlag{
+		Name:   "wsapi",
+		Usage:  "API's offered over the WS-RPC interface",
+		EnvVar: "PTT_WSAPI",
+	}
+
+	// Embedded servers
+	SignalEnabledFlag = cli.BoolFlag{
+		Name:   "signal",
+		Usage:  "Enable the embedded webrtc signal server",
+		EnvVar: "PTT_SIGNAL",
+	}
+	SignalPortFlag = cli.IntFlag{
+		Name:   "signalport",
+		Usage:  "Embedded signal server listening port",
+		Value:  node.DefaultSignalPort,
+		EnvVar: "PTT_SIGNALPORT",
+	}
+	MetricsEnabledFlag = cli.BoolFlag{
+		Name:   "metrics",
+		Usage:  "Enable the prometheus metrics endpoint",
+		EnvVar: "PTT_METRICS",
+	}
+	MetricsPortFlag = cli.IntFlag{
+		Name:   "metricsport",
+		Usage:  "Metrics endpoint listening port",
+		Value:  node.DefaultMetricsPort,
+		EnvVar: "PTT_METRICSPORT",
+	}
+
+	// Networking settings
+	MaxPeersFlag = cli.IntFlag{
+		Name:   "maxpeers",
+		Usage:  "Maximum number of network peers (network disabled if set to 0)",
+		Value:  DefaultMaxPeers,
+		EnvVar: "PTT_MAXPEERS",
+	}
+	ListenAddrFlag = cli.StringFlag{
+		Name:   "listenaddr",
+		Usage:  "Network listening address (e.g. :9487)",
+		EnvVar: "PTT_LISTENADDR",
+	}
+	NoDiscoverFlag = cli.BoolFlag{
+		Name:   "nodiscover",
+		Usage:  "Disables the peer discovery mechanism (manual peer addition)",
+		EnvVar: "PTT_NODISCOVER",
+	}
+	BootnodesFlag = cli.StringFlag{
+		Name:   "bootnodes",
+		Usage:  "Comma separated enode URLs for P2P discovery bootstrap",
+		EnvVar: "PTT_BOOTNODES",
+	}
+	SignalServerFlag = cli.StringFlag{
+		Name:   "signalserver",
+		Usage:  "URL of the webrtc signal server (e.g. ws://127.0.0.1:9489/signal)",
+		EnvVar: "PTT_SIGNALSERVER",
+	}
+
+	// Router settings
+	HubFlag = cli.BoolFlag{
+		Name:   "hub",
+		Usage:  "Run as a hub, storing and delivering the oplogs of the offline peers",
+		EnvVar: "PTT_HUB",
+	}
+)
+
+var configFlags = []cli.Flag{
+	ConfigFileFlag,
+	DataDirFlag,
+	VerbosityFlag,
+	PassphraseFileFlag,
+	NewPassphraseFileFlag,
+	IPCDisabledFlag,
+	IPCPathFlag,
+	RPCEnabledFlag,
+	RPCListenAddrFlag,
+	RPCPortFlag,
+	RPCCORSDomainFlag,
+	RPCVirtualHostsFlag,
+	RPCApiFlag,
+	RPCAuthFlag,
+	WSEnabledFlag,
+	WSListenAddrFlag,
+	WSPortFlag,
+	WSAllowedOriginsFlag,
+	WSApiFlag,
+	SignalEnabledFlag,
+	SignalPortFlag,
+	MetricsEnabledFlag,
+	MetricsPortFlag,
+	MaxPeersFlag,
+	ListenAddrFlag,
+	NoDiscoverFlag,
+	BootnodesFlag,
+	SignalServerFlag,
+	HubFlag,
+}
+
+// applyFlags applies the command line flags and the environment variables to the config.
+func applyFlags(ctx *cli.Context, cfg *gpttConfig) {
+	setNodeConfig(ctx, &cfg.Node)
+	setP2PConfig(ctx, &cfg.P2P)
+	setRouterConfig(ctx, &cfg.Router)
+}
+
+// setNodeConfig applies node-related command line flags to the config.
+func setNodeConfig(ctx *cli.Context, cfg *nodeConfig) {
+	if ctx.GlobalIsSet(DataDirFlag.Name) {
+		cfg.DataDir = expandPath(ctx.GlobalString(DataDirFlag.Name))
+	}
+
+	// ipc
+	switch {
+	case ctx.GlobalBool(IPCDisabledFlag.Name):
+		cfg.IPCPath = ""
+	case ctx.GlobalIsSet(IPCPathFlag.Name):
+		cfg.IPCPath = ctx.GlobalString(IPCPathFlag.Name)
+	}
+
+	// http
+	if ctx.GlobalBool(RPCEnabledFlag.Name) && cfg.HTTPHost == "" {
+		cfg.HTTPHost = ctx.GlobalString(RPCListenAddrFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCListenAddrFlag.Name) {
+		cfg.HTTPHost = ctx.GlobalString(RPCListenAddrFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCPortFlag.Name) {
+		cfg.HTTPPort = ctx.GlobalInt(RPCPortFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCCORSDomainFlag.Name) {
+		cfg.HTTPCors = splitAndTrim(ctx.GlobalString(RPCCORSDomainFlag.Name))
+	}
+	if ctx.GlobalIsSet(RPCApiFlag.Name) {
+		cfg.HTTPModules = splitAndTrim(ctx.GlobalString(RPCApiFlag.Name))
+	}
+	if ctx.GlobalIsSet(RPCVirtualHostsFlag.Name) {
+		cfg.HTTPVirtualHosts = splitAndTrim(ctx.GlobalString(RPCVirtualHostsFlag.Name))
+	}
+	if ctx.GlobalIsSet(RPCAuthFlag.Name) {
+		cfg.RPCAuth = ctx.GlobalBool(RPCAuthFlag.Name)
+	}
+
+	// ws
+	if ctx.GlobalBool(WSEnabledFlag.Name) && cfg.WSHost == "" {
+		cfg.WSHost = ctx.GlobalString(WSListenAddrFlag.Name)
+	}
+	if ctx.GlobalIsSet(WSListenAddrFlag.Name) {
+		cfg.WSHost = ctx.GlobalString(WSListenAddrFlag.Name)
+	}
+	if ctx.GlobalIsSet(WSPortFlag.Name) {
+		cfg.WSPort = ctx.GlobalInt(WSPortFlag.Name)
+	}
+	if ctx.GlobalIsSet(WSAllowedOriginsFlag.Name) {
+		cfg.WSOrigins = splitAndTrim(ctx.GlobalString(WSAllowedOriginsFlag.Name))
+	}
+	if ctx.GlobalIsSet(WSApiFlag.Name) {
+		cfg.WSModules = splitAndTrim(ctx.GlobalString(WSApiFlag.Name))
+	}
+
+	// signal / metrics
+	if ctx.GlobalBool(SignalEnabledFlag.Name) && cfg.SignalHost == "" {
+		cfg.SignalHost = DefaultRPCHost
+	}
+	if ctx.GlobalIsSet(SignalPortFlag.Name) {
+		cfg.SignalPort = ctx.GlobalInt(SignalPortFlag.Name)
+	}
+	if ctx.GlobalBool(MetricsEnabledFlag.Name) && cfg.MetricsHost == "" {
+		cfg.MetricsHost = DefaultRPCHost
+	}
+	if ctx.GlobalIsSet(MetricsPortFlag.Name) {
+		cfg.MetricsPort = ctx.GlobalInt(MetricsPortFlag.Name)
+	}
+}
+
+// setP2PConfig applies p2p-related command line flags to the config.
+func setP2PConfig(ctx *cli.Context, cfg *p2pConfig) {
+	if ctx.GlobalIsSet(MaxPeersFlag.Name) {
+		cfg.MaxPeers = ctx.GlobalInt(MaxPeersFlag.Name)
+	}
+	if ctx.GlobalIsSet(ListenAddrFlag.Name) {
+		cfg.ListenAddr = ctx.GlobalString(ListenAddrFlag.Name)
+	}
+	if ctx.GlobalIsSet(NoDiscoverFlag.Name) {
+		cfg.NoDiscovery = ctx.GlobalBool(NoDiscoverFlag.Name)
+	}
+	if ctx.GlobalIsSet(BootnodesFlag.Name) {
+		cfg.BootstrapNodes = splitAndTrim(ctx.GlobalString(BootnodesFlag.Name))
+	}
+	if ctx.GlobalIsSet(SignalServerFlag.Name) {
+		cfg.SignalServerURL = ctx.GlobalString(SignalServerFlag.Name)
+	}
+}
+
+// setRouterConfig applies router-related command line flags to the config.
+func setRouterConfig(ctx *cli.Context, cfg *routerConfig) {
+	if ctx.GlobalIsSet(HubFlag.Name) {
+		cfg.IsHub = ctx.GlobalBool(HubFlag.Name)
+	}
+}
+
+/*
+passphrase returns the passphrase of the key-files, from the passphrase-file
+or the PTT_PASSPHRASE environment variable.
+*/
+func passphrase(ctx *cli.Context) (string, error) {
+	return readPassphrase(ctx, PassphraseFileFlag.Name, EnvPassphrase)
+}
+
+func newPassphrase(ctx *cli.Context) (string, error) {
+	return readPassphrase(ctx, NewPassphraseFileFlag.Name, EnvNewPassphrase)
+}
+
+func readPassphrase(ctx *cli.Context, flagName string, envName string) (string, error) {
+	filename := ctx.GlobalString(flagName)
+	if filename == "" {
+		return os.Getenv(envName), nil
+	}
+
+	content, err := ioutil.ReadFile(expandPath(filename))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+func splitAndTrim(input string) []string {
+	result := strings.Split(input, ",")
+	for i, r := range result {
+		result[i] = strings.TrimSpace(r)
+	}
+	return result
+}
+
+// Expands a file path
+// 1. replace tilde with users home dir
+// 2. expands embedded environment variables
+// 3. cleans the path, e.g. /a/b/../c -> /a/c
+// Note, it has limitations, e.g. ~someuser/tmp will not be expanded
+func expandPath(p string) string {
+	if strings.HasPrefix(p, "~/") || strings.HasPrefix(p, "~\\") {
+		if home := homeDir(); home != "" {
+			p = home + p[1:]
+		}
+	}
+	return filepath.Clean(os.ExpandEnv(p))
+}
+
+func homeDir() string {
+	if home := os.Getenv("HOME"); home != "" {
+		return home
+	}
+	if usr, err := user.Current(); err == nil {
+		return usr.HomeDir
+	}
+	return ""
+}
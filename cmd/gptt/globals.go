@@ -0,0 +1,35 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of go-pttai.
+//
+// go-pttai is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-pttai is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-pttai. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+const (
+	clientIdentifier = "gptt" // Client identifier to advertise over the network
+	clientVersion    = "0.1.0"
+
+	DefaultConfigFile = "gptt.toml" // Path within the datadir to the config file
+	DefaultRPCHost    = "127.0.0.1" // Default host interface if the rpc is enabled by the flags
+	DefaultMaxPeers   = 100
+	DefaultVerbosity  = 3
+
+	EnvPassphrase    = "PTT_PASSPHRASE"
+	EnvNewPassphrase = "PTT_NEW_PASSPHRASE"
+)
+
+var (
+	// gitCommit is set by the linker with -ldflags "-X main.gitCommit=$(git rev-parse HEAD)"
+	gitCommit = ""
+)
@@ -0,0 +1,227 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of go-pttai.
+//
+// go-pttai is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-pttai is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-pttai. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ailabstw/go-pttai-core/key"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/me"
+	"github.com/ailabstw/go-pttai-core/node"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+var keyCommand = cli.Command{
+	Name:      "key",
+	Usage:     "Manage the node key and my key",
+	ArgsUsage: "",
+	Description: `
+The key-files are encrypted as the keystore if the passphrase is set
+(PTT_PASSPHRASE or --passphrasefile). The daemon should be stopped before
+restoring my key or changing the passphrase.`,
+	Subcommands: []cli.Command{
+		{
+			Action:    keyShow,
+			Name:      "show",
+			Usage:     "Show the node-id and my id",
+			ArgsUsage: " ",
+		},
+		{
+			Action:    keyMnemonic,
+			Name:      "mnemonic",
+			Usage:     "Show the mnemonic words of my key for the backup",
+			ArgsUsage: " ",
+		},
+		{
+			Action:    keyRestore,
+			Name:      "restore",
+			Usage:     "Restore my key from the mnemonic words (read from stdin)",
+			ArgsUsage: " ",
+		},
+		{
+			Action:    keyPassphrase,
+			Name:      "passphrase",
+			Usage:     "Change the passphrase of the key-files (PTT_NEW_PASSPHRASE or --newpassphrasefile)",
+			ArgsUsage: " ",
+		},
+	},
+}
+
+func nodeKeyFile(cfg *pttConfig) string {
+	return cfg.Node.ResolvePath(node.DataDirPrivateKey)
+}
+
+func myKeyFile(cfg *pttConfig) string {
+	return cfg.Me.ResolvePath(me.DataDirPrivateKey)
+}
+
+/*
+checkKeys checks that the key-files are able to be loaded with the passphrase,
+because the node exits if unable to decrypt the node key.
+
+The key-files are required to exist if isExist, otherwise they are generated
+when loaded.
+*/
+func checkKeys(cfg *pttConfig, isExist bool) error {
+	for _, filename := range []string{nodeKeyFile(cfg), myKeyFile(cfg)} {
+		_, _, err := key.LoadKeyFile(filename, cfg.Me.Passphrase)
+		switch {
+		case os.IsNotExist(err) && isExist:
+			return ErrNoKey
+		case os.IsNotExist(err):
+		case err == key.ErrKeyLocked:
+			return ErrKeyLocked
+		case err != nil:
+			return fmt.Errorf("unable to load %v: %v", filename, err)
+		}
+	}
+
+	return nil
+}
+
+/*
+loadKeys loads (or generates) the node key and my key.
+
+Return: node-id, my-id, error
+*/
+func loadKeys(cfg *pttConfig, isExist bool) (string, string, error) {
+	if err := checkKeys(cfg, isExist); err != nil {
+		return "", "", err
+	}
+
+	nodeKey := cfg.Node.NodeKey()
+	nodeID := discover.PubkeyID(&nodeKey.PublicKey)
+
+	if err := cfg.Me.SetMyKey("", "", "", false); err != nil {
+		return "", "", err
+	}
+
+	return nodeID.String(), cfg.Me.ID.String(), nil
+}
+
+func keyShow(ctx *cli.Context) error {
+	cfg, err := loadPttConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	nodeID, myID, err := loadKeys(cfg, true)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("node: %v\nme:   %v\n", nodeID, myID)
+
+	return nil
+}
+
+func keyMnemonic(ctx *cli.Context) error {
+	cfg, err := loadPttConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = loadKeys(cfg, true)
+	if err != nil {
+		return err
+	}
+
+	mnemonic, err := cfg.Me.Mnemonic()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(mnemonic)
+
+	return nil
+}
+
+func keyRestore(ctx *cli.Context) error {
+	cfg, err := loadPttConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := checkKeys(cfg, false); err != nil {
+		return err
+	}
+
+	fmt.Fprint(os.Stderr, "Mnemonic: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	mnemonic := strings.Join(strings.Fields(line), " ")
+	if mnemonic == "" {
+		if err != nil {
+			return err
+		}
+		return ErrEmptyMnemonic
+	}
+
+	if err := cfg.Me.RestoreMnemonic(mnemonic); err != nil {
+		return err
+	}
+
+	fmt.Printf("me:   %v\n", cfg.Me.ID)
+
+	return nil
+}
+
+func keyPassphrase(ctx *cli.Context) error {
+	cfg, err := loadPttConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	oldPass := cfg.Me.Passphrase
+	newPass, err := newPassphrase(ctx)
+	if err != nil {
+		return err
+	}
+	if oldPass == newPass {
+		return ErrSamePassphrase
+	}
+
+	if err := checkKeys(cfg, true); err != nil {
+		return err
+	}
+
+	// my key-files
+	if err := cfg.Me.ChangePassphrase(oldPass, newPass); err != nil {
+		return err
+	}
+
+	// node key
+	filename := nodeKeyFile(cfg)
+	nodeKey, _, err := key.LoadKeyFile(filename, oldPass)
+	if err != nil {
+		return err
+	}
+	if err := key.SaveKeyFile(filename, nodeKey, newPass); err != nil {
+		return err
+	}
+
+	if newPass == "" {
+		log.Warn("the key-files are saved in plaintext")
+	}
+	fmt.Println("passphrase is changed.")
+
+	return nil
+}
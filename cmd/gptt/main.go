@@ -0,0 +1,400 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of go-pttai.
+//
+// go-pttai is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-pttai is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-pttai. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"syscall"
+
+	"github.com/ailabstw/go-pttai-core/account"
+	"github.com/ailabstw/go-pttai-core/content"
+	"github.com/ailabstw/go-pttai-core/friend"
+	"github.com/ailabstw/go-pttai-core/group"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/me"
+	"github.com/ailabstw/go-pttai-core/node"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	"github.com/ailabstw/go-pttai-core/service"
+	colorable "github.com/mattn/go-colorable"
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+var (
+	app = newApp()
+
+	initCommand = cli.Command{
+		Action:    initGptt,
+		Name:      "init",
+		Usage:     "Initialize the datadir with the config file and the keys",
+		ArgsUsage: " ",
+		Description: `
+The init command creates the datadir, writes the layered config as the config
+file (--config, or gptt.toml in the datadir) if not exists, and generates the
+node key and my key.`,
+	}
+	runCommand = cli.Command{
+		Action:    runGptt,
+		Name:      "run",
+		Usage:     "Run the PTT.ai node (default)",
+		ArgsUsage: " ",
+	}
+	dumpConfigCommand = cli.Command{
+		Action:      dumpConfig,
+		Name:        "dumpconfig",
+		Usage:       "Show the layered config as TOML",
+		ArgsUsage:   "[dumpfile]",
+		Description: `The dumpconfig command shows the config with the defaults, the config file, the environment variables and the flags applied.`,
+	}
+	versionCommand = cli.Command{
+		Action:    version,
+		Name:      "version",
+		Usage:     "Print version numbers",
+		ArgsUsage: " ",
+	}
+)
+
+func newApp() *cli.App {
+	app := cli.NewApp()
+	app.Name = clientIdentifier
+	app.Usage = "the PTT.ai daemon"
+	app.Version = versionWithCommit()
+	app.Flags = configFlags
+	app.Action = runGptt
+	app.Before = func(ctx *cli.Context) error {
+		initLog(ctx.GlobalInt(VerbosityFlag.Name))
+		return nil
+	}
+	app.Commands = []cli.Command{
+		initCommand,
+		runCommand,
+		dumpConfigCommand,
+		versionCommand,
+		keyCommand,
+	}
+	sort.Sort(cli.CommandsByName(app.Commands))
+
+	return app
+}
+
+func main() {
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func initLog(verbosity int) {
+	output := colorable.NewColorableStderr()
+
+	ostream := log.StreamHandler(output, log.TerminalFormat(true))
+	glogger := log.NewGlogHandler(ostream)
+
+	glogger.Verbosity(log.Lvl(verbosity))
+	log.Root().SetHandler(glogger)
+}
+
+func initGptt(ctx *cli.Context) error {
+	cfg := defaultGpttConfig()
+	if ctx.GlobalIsSet(DataDirFlag.Name) {
+		cfg.Node.DataDir = expandPath(ctx.GlobalString(DataDirFlag.Name))
+	}
+
+	file := ctx.GlobalString(ConfigFileFlag.Name)
+	if file == "" {
+		file = filepath.Join(cfg.Node.DataDir, DefaultConfigFile)
+	}
+	file = expandPath(file)
+
+	_, err := os.Stat(file)
+	isExists := err == nil
+	if isExists {
+		if err := loadConfig(file, cfg); err != nil {
+			return err
+		}
+	}
+
+	applyFlags(ctx, cfg)
+
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+
+	cfg.Node.DataDir, err = filepath.Abs(cfg.Node.DataDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cfg.Node.DataDir, 0700); err != nil {
+		return err
+	}
+
+	// config file
+	if isExists {
+		log.Info("config file exists, keep it", "file", file)
+	} else {
+		if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return err
+		}
+		err = writeConfig(f, cfg)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		log.Info("config file is written", "file", file)
+	}
+
+	// keys
+	pass, err := passphrase(ctx)
+	if err != nil {
+		return err
+	}
+	pttCfg, err := cfg.pttConfig(pass)
+	if err != nil {
+		return err
+	}
+
+	nodeID, myID, err := loadKeys(pttCfg, false)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("node: %v\nme:   %v\n", nodeID, myID)
+	fmt.Println("run 'gptt key mnemonic' to back up my key.")
+
+	return nil
+}
+
+func runGptt(ctx *cli.Context) error {
+	if args := ctx.Args(); len(args) > 0 {
+		return fmt.Errorf("invalid command: %q", args[0])
+	}
+
+	cfg, err := loadPttConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Info("PTT.ai: Hello world!", "version", versionWithCommit(), "datadir", cfg.Node.DataDir)
+
+	_, _, err = loadKeys(cfg, false)
+	if err != nil {
+		return err
+	}
+
+	// sign-version of the oplogs
+	if cfg.Router.IsSignCanonical {
+		service.CurrentSignVersion = service.SignVersionCanonical
+	}
+
+	// new node
+	n, err := node.New(cfg.Node)
+	if err != nil {
+		return err
+	}
+
+	// register router
+	if err := registerRouter(n, cfg); err != nil {
+		return err
+	}
+
+	// node start
+	if err := n.Start(); err != nil {
+		return err
+	}
+
+	// set-signal
+	go setSignal(n)
+
+	// wait-node
+	if err := WaitNode(n); err != nil {
+		return err
+	}
+
+	log.Info("PTT.ai: see u later～")
+
+	return nil
+}
+
+func versionWithCommit() string {
+	if gitCommit == "" {
+		return clientVersion
+	}
+
+	if len(gitCommit) > 8 {
+		return clientVersion + "-" + gitCommit[:8]
+	}
+	return clientVersion + "-" + gitCommit
+}
+
+func version(ctx *cli.Context) error {
+	fmt.Println(clientIdentifier)
+	fmt.Println("Version:", clientVersion)
+	if gitCommit != "" {
+		fmt.Println("Git Commit:", gitCommit)
+	}
+	fmt.Println("Architecture:", runtime.GOARCH)
+	fmt.Println("Go Version:", runtime.Version())
+	fmt.Println("Operating System:", runtime.GOOS)
+
+	return nil
+}
+
+func registerRouter(n *node.Node, cfg *pttConfig) error {
+	return n.Register(func(ctx *service.RouterContext) (service.NodeRouter, error) {
+		myNodeKey := cfg.Node.NodeKey()
+		myNodeID := discover.PubkeyID(&myNodeKey.PublicKey)
+
+		router, err := service.NewRouter(ctx, cfg.Router, &myNodeID, myNodeKey)
+		if err != nil {
+			return nil, err
+		}
+
+		accountBackend, err := account.NewBackend(ctx, cfg.Account, router)
+		if err != nil {
+			return nil, err
+		}
+		err = router.RegisterService(accountBackend)
+		if err != nil {
+			return nil, err
+		}
+
+		// friend
+		friendBackend, err := friend.NewBackend(ctx, cfg.Friend, cfg.Me.ID, router, accountBackend)
+		if err != nil {
+			return nil, err
+		}
+		err = router.RegisterService(friendBackend)
+		if err != nil {
+			return nil, err
+		}
+
+		// group
+		groupBackend, err := group.NewBackend(ctx, cfg.Group, router)
+		if err != nil {
+			return nil, err
+		}
+		err = router.RegisterService(groupBackend)
+		if err != nil {
+			return nil, err
+		}
+
+		// content
+		contentBackend, err := content.NewBackend(ctx, cfg.Content, router)
+		if err != nil {
+			return nil, err
+		}
+		err = router.RegisterService(contentBackend)
+		if err != nil {
+			return nil, err
+		}
+
+		// me
+		meBackend, err := me.NewBackend(ctx, cfg.Me, router, accountBackend, friendBackend)
+		if err != nil {
+			return nil, err
+		}
+
+		err = router.RegisterService(meBackend)
+		if err != nil {
+			return nil, err
+		}
+
+		err = router.Prestart()
+		if err != nil {
+			log.Error("unable to do Prestart", "e", err)
+			return nil, err
+		}
+
+		return router, nil
+	})
+}
+
+func setSignal(n *node.Node) {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+
+	<-sigc
+	go func() {
+		n.Stop(false, false)
+	}()
+
+	log.Debug("setSignal: received break-signal")
+	for i := 3; i > 0; i-- {
+		<-sigc
+		if i > 1 {
+			log.Warn("Already shutting down, interrupt more to panic.", "times", i-1)
+		}
+	}
+	panic("boom")
+}
+
+func WaitNode(n *node.Node) error {
+	log.Info("start Waiting...")
+
+	router := n.Services()[reflect.TypeOf(&service.BaseRouter{})].(*service.BaseRouter)
+
+loop:
+	for {
+		select {
+		case _, ok := <-router.NotifyNodeRestart().GetChan():
+			log.Debug("WaitNode: NotifyNodeRestart: start")
+			if !ok {
+				break loop
+			}
+			err := n.Restart(false, true)
+			if err != nil {
+				return err
+			}
+			router = n.Services()[reflect.TypeOf(&service.BaseRouter{})].(*service.BaseRouter)
+			log.Debug("WaitNode: NotifyNodeRestart: done")
+		case _, ok := <-router.NotifyNodeStop().GetChan():
+			log.Debug("WaitNode: NotifyNodeStop: start")
+			if !ok {
+				break loop
+			}
+			n.Stop(false, false)
+			log.Debug("WaitNode: NotifyNodeStop: done")
+			break loop
+		case err, ok := <-router.ErrChan().GetChan():
+			if !ok {
+				break loop
+			}
+			log.Error("Received err from ptt", "e", err)
+			break loop
+		case err, ok := <-n.StopChan:
+			log.Debug("WaitNode: StopChan: start")
+			if ok && err != nil {
+				log.Error("Wait", "e", err)
+				return err
+			}
+			log.Debug("WaitNode: StopChan: done")
+			break loop
+		}
+	}
+
+	return nil
+}
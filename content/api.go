@@ -0,0 +1,315 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package content
+
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+type PrivateAPI struct {
+	b *Backend
+}
+
+func NewPrivateAPI(b *Backend) *PrivateAPI {
+	return &PrivateAPI{b}
+}
+
+/**********
+ * Op
+ **********/
+
+func (api *PrivateAPI) CreateBoard(title []byte) (*BackendGetBoard, error) {
+	return api.b.CreateBoard(title)
+}
+
+func (api *PrivateAPI) CreateArticle(entityID string, title []byte, article [][]byte, mediaIDs []string) (*BackendCreateArticle, error) {
+	return api.b.CreateArticle(
+		[]byte(entityID),
+		title,
+		article,
+		mediaIDs,
+	)
+}
+
+func (api *PrivateAPI) UpdateArticle(entityID string, articleID string, title []byte, article [][]byte, mediaIDs []string) (*BackendGetArticle, error) {
+	return api.b.UpdateArticle(
+		[]byte(entityID),
+		[]byte(articleID),
+		title,
+		article,
+		mediaIDs,
+	)
+}
+
+func (api *PrivateAPI) DeleteArticle(entityID string, articleID string) (bool, error) {
+	return api.b.DeleteArticle([]byte(entityID), []byte(articleID))
+}
+
+func (api *PrivateAPI) CreateComment(entityID string, articleID string, commentType pkgservice.CommentType, comment [][]byte, mediaIDs []string) (*BackendCreateComment, error) {
+	return api.b.CreateComment(
+		[]byte(entityID),
+		[]byte(articleID),
+		commentType,
+		comment,
+		mediaIDs,
+	)
+}
+
+func (api *PrivateAPI) DeleteComment(entityID string, commentID string) (bool, error) {
+	return api.b.DeleteComment([]byte(entityID), []byte(commentID))
+}
+
+func (api *PrivateAPI) DeleteBoard(entityID string) (bool, error) {
+	return api.b.DeleteBoard([]byte(entityID))
+}
+
+func (api *PrivateAPI) MarkBoardSeen(entityID string) (types.Timestamp, error) {
+	return api.b.MarkBoardSeen([]byte(entityID))
+}
+
+/**********
+ * Join
+ **********/
+
+func (api *PrivateAPI) ShowURL(entityID string) (*pkgservice.BackendJoinURL, error) {
+	return api.b.ShowURL([]byte(entityID))
+}
+
+func (api *PrivateAPI) JoinBoard(url []byte) (*pkgservice.BackendJoinRequest, error) {
+	return api.b.JoinBoard(url)
+}
+
+func (api *PrivateAPI) LeaveBoard(entityID string) (bool, error) {
+	return api.b.LeaveBoard([]byte(entityID))
+}
+
+func (api *PrivateAPI) AddMember(entityID string, userID string) (bool, error) {
+	return api.b.AddMember([]byte(entityID), []byte(userID))
+}
+
+func (api *PrivateAPI) DeleteMember(entityID string, userID string) (bool, error) {
+	return api.b.DeleteMember([]byte(entityID), []byte(userID))
+}
+
+/**********
+ * Get Board
+ **********/
+
+func (api *PrivateAPI) GetBoard(entityID string) (*BackendGetBoard, error) {
+	return api.b.GetBoard([]byte(entityID))
+}
+
+func (api *PrivateAPI) GetRawBoard(entityID string) (*Board, error) {
+	return api.b.GetRawBoard([]byte(entityID))
+}
+
+func (api *PrivateAPI) GetBoardList(startingBoardID string, limit int) ([]*BackendGetBoard, error) {
+	return api.b.GetBoardList(
+		[]byte(startingBoardID),
+		limit,
+		pttdb.ListOrderNext,
+	)
+}
+
+/**********
+ * Get Article
+ **********/
+
+func (api *PrivateAPI) GetArticleList(entityID string, startingArticleID string, limit int, listOrder pttdb.ListOrder) ([]*BackendGetArticle, error) {
+	return api.b.GetArticleList(
+		[]byte(entityID),
+		[]byte(startingArticleID),
+		limit,
+		listOrder,
+	)
+}
+
+func (api *PrivateAPI) GetArticleBlockList(entityID string, articleID string, limit uint32) ([]*pkgservice.ArticleBlock, error) {
+	return api.b.GetArticleBlockList([]byte(entityID), []byte(articleID), limit)
+}
+
+func (api *PrivateAPI) GetCommentList(entityID string, articleID string, startingCommentID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.ArticleBlock, error) {
+	return api.b.GetCommentList(
+		[]byte(entityID),
+		[]byte(articleID),
+		[]byte(startingCommentID),
+		limit,
+		listOrder,
+	)
+}
+
+/**********
+ * BoardOplog
+ **********/
+
+func (api *PrivateAPI) GetBoardOplogList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*BoardOplog, error) {
+	return api.b.GetBoardOplogList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetPendingBoardOplogMasterList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*BoardOplog, error) {
+	return api.b.GetPendingBoardOplogMasterList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetPendingBoardOplogInternalList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*BoardOplog, error) {
+	return api.b.GetPendingBoardOplogInternalList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetBoardOplogMerkleNodeList(entityID string, level uint8, startKey []byte, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.BackendMerkleNode, error) {
+	return api.b.GetBoardOplogMerkleNodeList([]byte(entityID), pkgservice.MerkleTreeLevel(level), startKey, limit, listOrder)
+}
+
+func (api *PrivateAPI) ForceSyncBoardMerkle(entityID string) (bool, error) {
+	return api.b.ForceSyncBoardMerkle([]byte(entityID))
+}
+
+/**********
+ * MasterOplog
+ **********/
+
+func (api *PrivateAPI) GetMasterOplogList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.MasterOplog, error) {
+	return api.b.GetMasterOplogList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetPendingMasterOplogMasterList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.MasterOplog, error) {
+	return api.b.GetPendingMasterOplogMasterList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetPendingMasterOplogInternalList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.MasterOplog, error) {
+	return api.b.GetPendingMasterOplogInternalList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetMasterOplogMerkleNodeList(entityID string, level uint8, startKey []byte, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.BackendMerkleNode, error) {
+	return api.b.GetMasterOplogMerkleNodeList([]byte(entityID), pkgservice.MerkleTreeLevel(level), startKey, limit, listOrder)
+}
+
+func (api *PrivateAPI) ForceSyncMasterMerkle(entityID string) (bool, error) {
+	return api.b.ForceSyncMasterMerkle([]byte(entityID))
+}
+
+/**********
+ * MemberOplog
+ **********/
+
+func (api *PrivateAPI) GetMemberOplogList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.MemberOplog, error) {
+	return api.b.GetMemberOplogList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetPendingMemberOplogMasterList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.MemberOplog, error) {
+	return api.b.GetPendingMemberOplogMasterList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetPendingMemberOplogInternalList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.MemberOplog, error) {
+	return api.b.GetPendingMemberOplogInternalList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetMemberOplogMerkleNodeList(entityID string, level uint8, startKey []byte, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.BackendMerkleNode, error) {
+	return api.b.GetMemberOplogMerkleNodeList([]byte(entityID), pkgservice.MerkleTreeLevel(level), startKey, limit, listOrder)
+}
+
+func (api *PrivateAPI) ForceSyncMemberMerkle(entityID string) (bool, error) {
+	return api.b.ForceSyncMemberMerkle([]byte(entityID))
+}
+
+/**********
+ * OpKeyOplog
+ **********/
+
+func (api *PrivateAPI) GetOpKeyOplogList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.OpKeyOplog, error) {
+	return api.b.GetOpKeyOplogList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetPendingOpKeyOplogMasterList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.OpKeyOplog, error) {
+	return api.b.GetPendingOpKeyOplogMasterList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetPendingOpKeyOplogInternalList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.OpKeyOplog, error) {
+	return api.b.GetPendingOpKeyOplogInternalList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+/**********
+ * Master
+ **********/
+
+func (api *PrivateAPI) GetMasterListFromCache(entityID string) ([]*pkgservice.Master, error) {
+	return api.b.GetMasterListFromCache([]byte(entityID))
+}
+
+func (api *PrivateAPI) GetMasterList(entityID string, startID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.Master, error) {
+	return api.b.GetMasterList([]byte(entityID), []byte(startID), limit, listOrder)
+}
+
+/**********
+ * Member
+ **********/
+
+func (api *PrivateAPI) GetMemberList(entityID string, startID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.Member, error) {
+	return api.b.GetMemberList([]byte(entityID), []byte(startID), limit, listOrder)
+}
+
+/**********
+ * MyMemberOplog
+ **********/
+
+func (api *PrivateAPI) GetMyMemberLog(entityID string) (*pkgservice.BaseOplog, error) {
+	return api.b.GetMyMemberLog([]byte(entityID))
+}
+
+/**********
+ * Op
+ **********/
+
+func (api *PrivateAPI) ShowValidateKey() (*types.PttID, error) {
+	return api.b.ShowValidateKey()
+}
+
+func (api *PrivateAPI) ValidateValidateKey(key string) (bool, error) {
+	return api.b.ValidateValidateKey([]byte(key))
+}
+
+func (api *PrivateAPI) GetOpKeyInfos(entityID string) ([]*pkgservice.KeyInfo, error) {
+	return api.b.GetOpKeys([]byte(entityID))
+}
+
+func (api *PrivateAPI) RevokeOpKey(entityID string, keyID string, myKey string) (bool, error) {
+	return api.b.RevokeOpKey([]byte(entityID), []byte(keyID), []byte(myKey))
+}
+
+func (api *PrivateAPI) GetOpKeyInfosFromDB(entityID string) ([]*pkgservice.KeyInfo, error) {
+	return api.b.GetOpKeysFromDB([]byte(entityID))
+}
+
+/**********
+ * Peer
+ **********/
+
+func (api *PrivateAPI) CountPeers(entityID string) (int, error) {
+	return api.b.CountPeers([]byte(entityID))
+}
+
+func (api *PrivateAPI) GetPeers(entityID string) ([]*pkgservice.BackendPeer, error) {
+	return api.b.GetPeers([]byte(entityID))
+}
+
+func (api *PrivateAPI) ForceSync(entityID string) (bool, error) {
+	return api.b.ForceSync([]byte(entityID))
+}
+
+func (api *PrivateAPI) ForceOpKey(entityID string) (bool, error) {
+	return api.b.ForceOpKey([]byte(entityID))
+}
@@ -0,0 +1,291 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package content
+
+import (
+	"encoding/json"
+
+	"github.com/ailabstw/go-pttai-core/common"
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+type SyncArticleInfo struct {
+	*pkgservice.BaseSyncInfo `json:"b"`
+
+	Title []byte `json:"T,omitempty"`
+}
+
+func NewEmptySyncArticleInfo() *SyncArticleInfo {
+	return &SyncArticleInfo{BaseSyncInfo: &pkgservice.BaseSyncInfo{}}
+}
+
+func (s *SyncArticleInfo) ToObject(theObj pkgservice.Object) error {
+	obj, ok := theObj.(*Article)
+	if !ok {
+		return pkgservice.ErrInvalidData
+	}
+
+	s.BaseSyncInfo.ToObject(obj)
+
+	obj.Title = s.Title
+
+	return nil
+}
+
+type Article struct {
+	*pkgservice.BaseObject `json:"b"`
+
+	UpdateTS types.Timestamp `json:"UT"`
+
+	SyncInfo *SyncArticleInfo `json:"s,omitempty"`
+
+	Title []byte `json:"T,omitempty"`
+}
+
+func NewArticle(
+	createTS types.Timestamp,
+	creatorID *types.PttID,
+	entityID *types.PttID,
+
+	logID *types.PttID,
+
+	status types.Status,
+
+	title []byte,
+
+) (*Article, error) {
+
+	id, err := types.NewPttID()
+	if err != nil {
+		return nil, err
+	}
+
+	o := pkgservice.NewObject(id, createTS, creatorID, entityID, logID, status)
+
+	return &Article{
+		BaseObject: o,
+		UpdateTS:   createTS,
+
+		Title: title,
+	}, nil
+}
+
+func NewEmptyArticle() *Article {
+	return &Article{BaseObject: &pkgservice.BaseObject{}}
+}
+
+func ArticlesToObjs(typedObjs []*Article) []pkgservice.Object {
+	objs := make([]pkgservice.Object, len(typedObjs))
+	for i, obj := range typedObjs {
+		objs[i] = obj
+	}
+	return objs
+}
+
+func ObjsToArticles(objs []pkgservice.Object) []*Article {
+	typedObjs := make([]*Article, len(objs))
+	for i, obj := range objs {
+		typedObjs[i] = obj.(*Article)
+	}
+	return typedObjs
+}
+
+func AliveArticles(typedObjs []*Article) []*Article {
+	objs := make([]*Article, 0, len(typedObjs))
+	for _, obj := range typedObjs {
+		if obj.Status == types.StatusAlive {
+			objs = append(objs, obj)
+		}
+	}
+	return objs
+}
+
+func (pm *ProtocolManager) SetArticleDB(a *Article) {
+	a.SetDB(pm.db.board, pm.DBObjLock(), pm.Entity().GetID(), pm.dbArticlePrefix, pm.dbArticleIdxPrefix, pm.SetBlockInfoDB, pm.SetMediaDB)
+}
+
+func (a *Article) Save(isLocked bool) error {
+	var err error
+
+	if !isLocked {
+		err = a.Lock()
+		if err != nil {
+			return err
+		}
+		defer a.Unlock()
+	}
+
+	key, err := a.MarshalKey()
+	if err != nil {
+		return err
+	}
+	marshaled, err := a.Marshal()
+	if err != nil {
+		return err
+	}
+
+	idxKey, err := a.IdxKey()
+	if err != nil {
+		return err
+	}
+
+	idx := &pttdb.Index{Keys: [][]byte{key}, UpdateTS: a.UpdateTS}
+
+	kvs := []*pttdb.KeyVal{
+		&pttdb.KeyVal{K: key, V: marshaled},
+	}
+
+	log.Debug("Article.Save: to ForcePutAll", "idxKey", idxKey, "key", kvs[0].K)
+
+	_, err = a.DB().ForcePutAll(idxKey, idx, kvs)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a *Article) NewEmptyObj() pkgservice.Object {
+	newObj := NewEmptyArticle()
+	newObj.CloneDB(a.BaseObject)
+	return newObj
+}
+
+func (a *Article) GetNewObjByID(id *types.PttID, isLocked bool) (pkgservice.Object, error) {
+	newObj := a.NewEmptyObj()
+	newObj.SetID(id)
+	err := newObj.GetByID(isLocked)
+	if err != nil {
+		return nil, err
+	}
+	return newObj, nil
+}
+
+func (a *Article) SetUpdateTS(ts types.Timestamp) {
+	a.UpdateTS = ts
+}
+
+func (a *Article) GetUpdateTS() types.Timestamp {
+	return a.UpdateTS
+}
+
+func (a *Article) Get(isLocked bool) error {
+	var err error
+
+	if !isLocked {
+		err = a.RLock()
+		if err != nil {
+			return err
+		}
+		defer a.RUnlock()
+	}
+
+	key, err := a.MarshalKey()
+	if err != nil {
+		return err
+	}
+
+	val, err := a.DB().DBGet(key)
+	if err != nil {
+		return err
+	}
+
+	return a.Unmarshal(val)
+}
+
+func (a *Article) GetByID(isLocked bool) error {
+	var err error
+
+	val, err := a.GetValueByID(isLocked)
+	if err != nil {
+		return err
+	}
+
+	return a.Unmarshal(val)
+}
+
+func (a *Article) MarshalKey() ([]byte, error) {
+	marshalTimestamp, err := a.CreateTS.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return common.Concat([][]byte{a.FullDBPrefix(), marshalTimestamp, a.ID[:]})
+}
+
+func (a *Article) Marshal() ([]byte, error) {
+	return json.Marshal(a)
+}
+
+func (a *Article) Unmarshal(theBytes []byte) error {
+	return json.Unmarshal(theBytes, a)
+}
+
+func (a *Article) GetSyncInfo() pkgservice.SyncInfo {
+	if a.SyncInfo == nil {
+		return nil
+	}
+	return a.SyncInfo
+}
+
+func (a *Article) SetSyncInfo(theSyncInfo pkgservice.SyncInfo) error {
+	if theSyncInfo == nil {
+		a.SyncInfo = nil
+		return nil
+	}
+
+	syncInfo, ok := theSyncInfo.(*SyncArticleInfo)
+	if !ok {
+		return pkgservice.ErrInvalidData
+	}
+	a.SyncInfo = syncInfo
+
+	return nil
+}
+
+func (a *Article) DeleteAll(isLocked bool) error {
+	var err error
+	if !isLocked {
+		err = a.Lock()
+		if err != nil {
+			return err
+		}
+		defer a.Unlock()
+	}
+
+	// block-info
+	setBlockInfoDB := a.SetBlockInfoDB()
+
+	blockInfo := a.GetBlockInfo()
+	if blockInfo != nil {
+		setBlockInfoDB(blockInfo, a.ID)
+		blockInfo.Remove(false)
+	}
+
+	if a.SyncInfo != nil && a.SyncInfo.BlockInfo != nil {
+		setBlockInfoDB(a.SyncInfo.BlockInfo, a.ID)
+		a.SyncInfo.BlockInfo.Remove(false)
+	}
+
+	// delete
+	a.Delete(true)
+
+	return nil
+}
@@ -0,0 +1,544 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package content
+
+import (
+	"reflect"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+/**********
+ * Board
+ **********/
+
+func (b *Backend) CreateBoard(title []byte) (*BackendGetBoard, error) {
+	bd, err := b.SPM().(*ServiceProtocolManager).CreateBoard(title)
+	if err != nil {
+		return nil, err
+	}
+
+	return boardToBackendGetBoard(bd), nil
+}
+
+func (b *Backend) GetBoard(entityIDBytes []byte) (*BackendGetBoard, error) {
+	bd, err := b.GetRawBoard(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	bd.LastSeen, _ = bd.LoadLastSeen()
+	bd.ArticleCreateTS, _ = bd.LoadArticleCreateTS()
+
+	return boardToBackendGetBoard(bd), nil
+}
+
+func (b *Backend) GetRawBoard(entityIDBytes []byte) (*Board, error) {
+
+	entity, err := b.EntityIDToEntity(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*Board), nil
+}
+
+func (b *Backend) GetBoardList(startIDBytes []byte, limit int, listOrder pttdb.ListOrder) ([]*BackendGetBoard, error) {
+
+	startID, err := types.UnmarshalTextPttID(startIDBytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	boardList, err := b.SPM().(*ServiceProtocolManager).GetBoardList(startID, limit, listOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	backendBoardList := make([]*BackendGetBoard, len(boardList))
+	for i, bd := range boardList {
+		backendBoardList[i] = boardToBackendGetBoard(bd)
+	}
+
+	return backendBoardList, nil
+}
+
+func (b *Backend) DeleteBoard(entityIDBytes []byte) (bool, error) {
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return false, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	err = pm.DeleteBoard()
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (b *Backend) MarkBoardSeen(entityIDBytes []byte) (types.Timestamp, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return types.ZeroTimestamp, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	return pm.SaveLastSeen(types.ZeroTimestamp)
+}
+
+/**********
+ * Join Board
+ **********/
+
+func (b *Backend) ShowURL(entityIDBytes []byte) (*pkgservice.BackendJoinURL, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	ptt := b.Router()
+	myID := ptt.GetMyEntity().GetID()
+	myNodeID := ptt.MyNodeID()
+
+	keyInfo, err := pm.GetJoinKey()
+	if err != nil {
+		return nil, err
+	}
+
+	bd := pm.Entity().(*Board)
+
+	return pkgservice.MarshalBackendJoinURL(myID, myNodeID, keyInfo, bd.Title, pkgservice.PathJoinBoard)
+}
+
+func (b *Backend) JoinBoard(boardURL []byte) (*pkgservice.BackendJoinRequest, error) {
+	joinRequest, err := pkgservice.ParseBackendJoinURL(boardURL, pkgservice.PathJoinBoard)
+	log.Debug("JoinBoard: after parse", "joinRequest", joinRequest, "e", err)
+	if err != nil {
+		return nil, err
+	}
+
+	ptt := b.Router()
+	myNodeID := ptt.MyNodeID()
+	if reflect.DeepEqual(myNodeID, joinRequest.NodeID) {
+		return nil, ErrInvalidNode
+	}
+
+	spm := b.SPM().(*ServiceProtocolManager)
+	err = ptt.GetMyEntity().JoinEntity(joinRequest, spm.HandleApproveJoinBoard)
+	if err != nil {
+		return nil, err
+	}
+
+	backendJoinRequest := pkgservice.JoinRequestToBackendJoinRequest(joinRequest)
+
+	return backendJoinRequest, nil
+}
+
+/**********
+ * Member
+ **********/
+
+func (b *Backend) AddMember(entityIDBytes []byte, userIDBytes []byte) (bool, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return false, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	userID, err := types.UnmarshalTextPttID(userIDBytes, false)
+	if err != nil {
+		return false, err
+	}
+
+	_, _, err = pm.AddMember(userID, false)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (b *Backend) DeleteMember(entityIDBytes []byte, userIDBytes []byte) (bool, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return false, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	userID, err := types.UnmarshalTextPttID(userIDBytes, false)
+	if err != nil {
+		return false, err
+	}
+
+	return pm.DeleteMember(userID)
+}
+
+func (b *Backend) LeaveBoard(entityIDBytes []byte) (bool, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return false, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	myID := b.Router().GetMyEntity().GetID()
+
+	return pm.DeleteMember(myID)
+}
+
+/**********
+ * BoardOplog
+ **********/
+
+func (b *Backend) GetBoardOplogList(entityIDBytes []byte, logIDBytes []byte, limit int, listOrder pttdb.ListOrder) ([]*BoardOplog, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	logID, err := types.UnmarshalTextPttID(logIDBytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return pm.GetBoardOplogList(logID, limit, listOrder, types.StatusAlive)
+}
+
+func (b *Backend) GetPendingBoardOplogMasterList(entityIDBytes []byte, logIDBytes []byte, limit int, listOrder pttdb.ListOrder) ([]*BoardOplog, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	logID, err := types.UnmarshalTextPttID(logIDBytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return pm.GetBoardOplogList(logID, limit, listOrder, types.StatusPending)
+}
+
+func (b *Backend) GetPendingBoardOplogInternalList(entityIDBytes []byte, logIDBytes []byte, limit int, listOrder pttdb.ListOrder) ([]*BoardOplog, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	logID, err := types.UnmarshalTextPttID(logIDBytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return pm.GetBoardOplogList(logID, limit, listOrder, types.StatusInternalPending)
+}
+
+func (b *Backend) GetBoardOplogMerkleNodeList(entityIDBytes []byte, level pkgservice.MerkleTreeLevel, startKey []byte, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.BackendMerkleNode, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	merkleNodeList, err := pm.GetBoardOplogMerkleNodeList(level, startKey, limit, listOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*pkgservice.BackendMerkleNode, len(merkleNodeList))
+	for i, eachMerkleNode := range merkleNodeList {
+		results[i] = pkgservice.MerkleNodeToBackendMerkleNode(eachMerkleNode)
+	}
+
+	return results, nil
+}
+
+func (b *Backend) ForceSyncBoardMerkle(entityIDBytes []byte) (bool, error) {
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return false, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	return pm.ForceSyncBoardMerkle()
+}
+
+/**********
+ * Article
+ **********/
+
+func (b *Backend) CreateArticle(entityIDBytes []byte, title []byte, article [][]byte, mediaIDStrs []string) (*BackendCreateArticle, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	mediaIDs, err := mediaIDStrsToMediaIDs(mediaIDStrs)
+	if err != nil {
+		return nil, err
+	}
+
+	theArticle, err := pm.CreateArticle(title, article, mediaIDs)
+	log.Debug("CreateArticle: after CreateArticle", "e", err)
+	if err != nil {
+		return nil, err
+	}
+
+	return articleToBackendCreateArticle(theArticle), nil
+}
+
+func (b *Backend) UpdateArticle(entityIDBytes []byte, articleIDBytes []byte, title []byte, article [][]byte, mediaIDStrs []string) (*BackendGetArticle, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	articleID, err := types.UnmarshalTextPttID(articleIDBytes, false)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaIDs, err := mediaIDStrsToMediaIDs(mediaIDStrs)
+	if err != nil {
+		return nil, err
+	}
+
+	theArticle, err := pm.UpdateArticle(articleID, title, article, mediaIDs)
+	log.Debug("UpdateArticle: after UpdateArticle", "e", err)
+	if err != nil {
+		return nil, err
+	}
+
+	return articleToBackendGetArticle(theArticle), nil
+}
+
+func (b *Backend) DeleteArticle(entityIDBytes []byte, articleIDBytes []byte) (bool, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return false, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	articleID, err := types.UnmarshalTextPttID(articleIDBytes, false)
+	if err != nil {
+		return false, err
+	}
+
+	err = pm.DeleteArticle(articleID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (b *Backend) GetArticleList(entityIDBytes []byte, startIDBytes []byte, limit int, listOrder pttdb.ListOrder) ([]*BackendGetArticle, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	startID, err := types.UnmarshalTextPttID(startIDBytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	articleList, err := pm.GetArticleList(startID, limit, listOrder, true)
+	if err != nil {
+		return nil, err
+	}
+
+	backendArticleList := make([]*BackendGetArticle, len(articleList))
+	for i, article := range articleList {
+		backendArticleList[i] = articleToBackendGetArticle(article)
+	}
+
+	return backendArticleList, nil
+}
+
+func (b *Backend) GetArticleBlockList(entityIDBytes []byte, articleIDBytes []byte, limit uint32) ([]*pkgservice.ArticleBlock, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	articleID, err := types.UnmarshalTextPttID(articleIDBytes, false)
+	if err != nil {
+		return nil, err
+	}
+	if articleID == nil {
+		return nil, types.ErrInvalidID
+	}
+
+	article, contentBlocks, err := pm.GetArticleBlockList(articleID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	blockInfo := article.GetBlockInfo()
+	if blockInfo == nil {
+		return nil, pkgservice.ErrInvalidBlock
+	}
+	blockInfoID := blockInfo.ID
+
+	articleBlocks := make([]*pkgservice.ArticleBlock, len(contentBlocks))
+	for i, contentBlock := range contentBlocks {
+		articleBlocks[i] = contentBlockToArticleBlock(article, blockInfoID, contentBlock)
+	}
+
+	return articleBlocks, nil
+}
+
+/**********
+ * Comment
+ **********/
+
+func (b *Backend) CreateComment(entityIDBytes []byte, articleIDBytes []byte, commentType pkgservice.CommentType, comment [][]byte, mediaIDStrs []string) (*BackendCreateComment, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	articleID, err := types.UnmarshalTextPttID(articleIDBytes, false)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaIDs, err := mediaIDStrsToMediaIDs(mediaIDStrs)
+	if err != nil {
+		return nil, err
+	}
+
+	theComment, err := pm.CreateComment(articleID, commentType, comment, mediaIDs)
+	log.Debug("CreateComment: after CreateComment", "e", err)
+	if err != nil {
+		return nil, err
+	}
+
+	return commentToBackendCreateComment(theComment), nil
+}
+
+func (b *Backend) DeleteComment(entityIDBytes []byte, commentIDBytes []byte) (bool, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return false, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	commentID, err := types.UnmarshalTextPttID(commentIDBytes, false)
+	if err != nil {
+		return false, err
+	}
+
+	err = pm.DeleteComment(commentID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+/*
+GetCommentList gets the comments of the article as article-blocks (with ContentTypeComment).
+*/
+func (b *Backend) GetCommentList(entityIDBytes []byte, articleIDBytes []byte, startIDBytes []byte, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.ArticleBlock, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	articleID, err := types.UnmarshalTextPttID(articleIDBytes, false)
+	if err != nil {
+		return nil, err
+	}
+	if articleID == nil {
+		return nil, types.ErrInvalidID
+	}
+
+	startID, err := types.UnmarshalTextPttID(startIDBytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := pm.GetCommentList(articleID, startID, limit, listOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	articleBlocks := make([]*pkgservice.ArticleBlock, 0, len(comments))
+	for _, comment := range comments {
+		if comment.Status != types.StatusAlive {
+			continue
+		}
+
+		contentBlock, err := pm.GetCommentBlock(comment)
+		if err != nil {
+			continue
+		}
+
+		articleBlocks = append(articleBlocks, commentToArticleBlock(comment, contentBlock))
+	}
+
+	return articleBlocks, nil
+}
+
+func mediaIDStrsToMediaIDs(mediaIDStrs []string) ([]*types.PttID, error) {
+	if len(mediaIDStrs) == 0 {
+		return nil, nil
+	}
+
+	mediaIDs := make([]*types.PttID, len(mediaIDStrs))
+	for i, mediaIDStr := range mediaIDStrs {
+		eachMediaID, err := types.UnmarshalTextPttID([]byte(mediaIDStr), false)
+		if err != nil {
+			return nil, err
+		}
+		mediaIDs[i] = eachMediaID
+	}
+
+	return mediaIDs, nil
+}
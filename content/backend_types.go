@@ -0,0 +1,161 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package content
+
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+type BackendGetBoard struct {
+	ID        *types.PttID
+	Title     []byte          `json:"T"`
+	CreatorID *types.PttID    `json:"CID"`
+	Status    types.Status    `json:"S"`
+	CreateTS  types.Timestamp `json:"CT"`
+
+	ArticleCreateTS types.Timestamp //`json:"ACT"`
+	LastSeen        types.Timestamp `json:"LT"`
+}
+
+func boardToBackendGetBoard(bd *Board) *BackendGetBoard {
+	articleCreateTS := bd.ArticleCreateTS
+	if articleCreateTS.IsLess(bd.CreateTS) {
+		articleCreateTS = bd.CreateTS
+	}
+
+	lastSeen := bd.LastSeen
+	if lastSeen.IsLess(bd.CreateTS) {
+		lastSeen = bd.CreateTS
+	}
+
+	return &BackendGetBoard{
+		ID:        bd.ID,
+		Title:     bd.Title,
+		CreatorID: bd.CreatorID,
+		Status:    bd.Status,
+		CreateTS:  bd.CreateTS,
+
+		ArticleCreateTS: articleCreateTS,
+		LastSeen:        lastSeen,
+	}
+}
+
+type BackendCreateArticle struct {
+	BoardID   *types.PttID `json:"BID"`
+	ArticleID *types.PttID `json:"AID"`
+	BlockID   *types.PttID `json:"cID"`
+	NBlock    int          `json:"NB"`
+}
+
+func articleToBackendCreateArticle(a *Article) *BackendCreateArticle {
+
+	return &BackendCreateArticle{
+		BoardID:   a.EntityID,
+		ArticleID: a.ID,
+		BlockID:   a.BlockInfo.ID,
+		NBlock:    a.BlockInfo.NBlock,
+	}
+}
+
+type BackendGetArticle struct {
+	ID        *types.PttID
+	CreateTS  types.Timestamp //`json:"CT"`
+	UpdateTS  types.Timestamp //`json:"UT"`
+	CreatorID *types.PttID    //`json:"CID"`
+	BoardID   *types.PttID    //`json:"BID"`
+	BlockID   *types.PttID    //`json:"cID"`
+	NBlock    int             //`json:"N"`
+	Title     []byte          `json:"T"`
+	Status    types.Status    `json:"S"`
+}
+
+func articleToBackendGetArticle(a *Article) *BackendGetArticle {
+
+	return &BackendGetArticle{
+		ID:        a.ID,
+		CreateTS:  a.CreateTS,
+		UpdateTS:  a.UpdateTS,
+		CreatorID: a.CreatorID,
+		BoardID:   a.EntityID,
+		BlockID:   a.BlockInfo.ID,
+		NBlock:    a.BlockInfo.NBlock,
+		Title:     a.Title,
+		Status:    a.Status,
+	}
+}
+
+type BackendCreateComment struct {
+	BoardID   *types.PttID `json:"BID"`
+	ArticleID *types.PttID `json:"AID"`
+	CommentID *types.PttID `json:"CID"`
+	BlockID   *types.PttID `json:"cID"`
+}
+
+func commentToBackendCreateComment(c *Comment) *BackendCreateComment {
+
+	return &BackendCreateComment{
+		BoardID:   c.EntityID,
+		ArticleID: c.ArticleID,
+		CommentID: c.ID,
+		BlockID:   c.BlockInfo.ID,
+	}
+}
+
+func contentBlockToArticleBlock(a *Article, blockInfoID *types.PttID, contentBlock *pkgservice.ContentBlock) *pkgservice.ArticleBlock {
+
+	return &pkgservice.ArticleBlock{
+		V:           types.CurrentVersion,
+		ID:          blockInfoID,
+		ArticleID:   a.ID,
+		RefID:       a.ID,
+		ContentType: pkgservice.ContentTypeArticle,
+		CommentType: pkgservice.CommentTypeNone,
+		BlockID:     contentBlock.BlockID,
+		Status:      a.Status,
+
+		CreateTS: a.CreateTS,
+		UpdateTS: a.UpdateTS,
+
+		CreatorID: a.CreatorID,
+		UpdaterID: a.UpdaterID,
+
+		Buf: contentBlock.Buf,
+	}
+}
+
+func commentToArticleBlock(c *Comment, contentBlock *pkgservice.ContentBlock) *pkgservice.ArticleBlock {
+
+	return &pkgservice.ArticleBlock{
+		V:           types.CurrentVersion,
+		ID:          c.BlockInfo.ID,
+		ArticleID:   c.ArticleID,
+		RefID:       c.ID,
+		ContentType: pkgservice.ContentTypeComment,
+		CommentType: c.CommentType,
+		BlockID:     contentBlock.BlockID,
+		Status:      c.Status,
+
+		CreateTS: c.CreateTS,
+		UpdateTS: c.UpdateTS,
+
+		CreatorID: c.CreatorID,
+		UpdaterID: c.UpdaterID,
+
+		Buf: contentBlock.Buf,
+	}
+}
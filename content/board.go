@@ -0,0 +1,269 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package content
+
+import (
+	"encoding/json"
+
+	"github.com/ailabstw/go-pttai-core/common"
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+type Board struct {
+	*pkgservice.BaseEntity `json:"e"`
+
+	UpdateTS types.Timestamp `json:"UT"`
+
+	Title []byte `json:"T,omitempty"`
+
+	// get from other dbs
+	LastSeen        types.Timestamp `json:"-"`
+	ArticleCreateTS types.Timestamp `json:"-"`
+
+	db *contentDB
+}
+
+func NewEmptyBoard(db *contentDB) *Board {
+	return &Board{BaseEntity: &pkgservice.BaseEntity{SyncInfo: &pkgservice.BaseSyncInfo{}}, db: db}
+}
+
+func NewBoard(title []byte, router pkgservice.Router, service pkgservice.Service, spm pkgservice.ServiceProtocolManager, dbLock *types.LockMap) (*Board, error) {
+
+	myID := router.GetMyEntity().GetID()
+	id, err := pkgservice.NewPttIDWithMyID(myID)
+	if err != nil {
+		return nil, err
+	}
+
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return nil, err
+	}
+
+	db := spm.(*ServiceProtocolManager).db
+
+	e := pkgservice.NewBaseEntity(id, ts, myID, types.StatusInit, db.board, dbLock)
+	e.EntityType = pkgservice.EntityTypePrivate
+
+	b := &Board{
+		BaseEntity: e,
+		UpdateTS:   ts,
+
+		Title: title,
+
+		db: db,
+	}
+
+	err = b.Init(router, service, spm)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("NewBoard: done", "createTS", b.CreateTS)
+
+	return b, nil
+}
+
+func (b *Board) GetUpdateTS() types.Timestamp {
+	return b.UpdateTS
+}
+
+func (b *Board) SetUpdateTS(ts types.Timestamp) {
+	b.UpdateTS = ts
+}
+
+func (b *Board) Init(router pkgservice.Router, service pkgservice.Service, spm pkgservice.ServiceProtocolManager) error {
+
+	b.db = spm.(*ServiceProtocolManager).db
+	b.SetDB(b.db.board, spm.GetDBLock())
+
+	b.SetName(string(b.Title))
+
+	err := b.InitPM(router, service)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (b *Board) InitPM(router pkgservice.Router, service pkgservice.Service) error {
+	pm, err := NewProtocolManager(b, router, service)
+	if err != nil {
+		return err
+	}
+
+	b.BaseEntity.Init(pm, router, service)
+
+	return nil
+}
+
+func (b *Board) MarshalKey() ([]byte, error) {
+	marshalTimestamp, err := b.JoinTS.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return common.Concat([][]byte{DBBoardPrefix, marshalTimestamp, b.ID[:]})
+}
+
+func (b *Board) IdxKey() ([]byte, error) {
+	return common.Concat([][]byte{DBBoardIdxPrefix, b.ID[:]})
+}
+
+func (b *Board) Marshal() ([]byte, error) {
+	return json.Marshal(b)
+}
+
+func (b *Board) Unmarshal(theBytes []byte) error {
+	err := json.Unmarshal(theBytes, b)
+	if err != nil {
+		return err
+	}
+
+	// postprocess
+
+	return nil
+}
+
+func (b *Board) Save(isLocked bool) error {
+	if !isLocked {
+		err := b.Lock()
+		if err != nil {
+			return err
+		}
+		defer b.Unlock()
+	}
+
+	key, err := b.MarshalKey()
+	if err != nil {
+		return err
+	}
+
+	marshaled, err := b.Marshal()
+	if err != nil {
+		return err
+	}
+
+	idxKey, err := b.IdxKey()
+	if err != nil {
+		return err
+	}
+
+	idx := &pttdb.Index{Keys: [][]byte{key}, UpdateTS: b.UpdateTS}
+
+	kvs := []*pttdb.KeyVal{
+		&pttdb.KeyVal{
+			K: key,
+			V: marshaled,
+		},
+	}
+
+	_, err = b.db.board.ForcePutAll(idxKey, idx, kvs)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (b *Board) SaveLastSeen(ts types.Timestamp) error {
+	b.LastSeen = ts
+
+	key, err := b.MarshalLastSeenKey()
+	if err != nil {
+		return err
+	}
+
+	return b.saveTS(key, ts)
+}
+
+func (b *Board) LoadLastSeen() (types.Timestamp, error) {
+	key, err := b.MarshalLastSeenKey()
+	if err != nil {
+		return types.ZeroTimestamp, err
+	}
+
+	return b.loadTS(key)
+}
+
+func (b *Board) MarshalLastSeenKey() ([]byte, error) {
+	return common.Concat([][]byte{DBLastSeenPrefix, b.ID[:]})
+}
+
+func (b *Board) SaveArticleCreateTS(ts types.Timestamp) error {
+	b.ArticleCreateTS = ts
+
+	key, err := b.MarshalArticleCreateTSKey()
+	if err != nil {
+		return err
+	}
+
+	return b.saveTS(key, ts)
+}
+
+func (b *Board) LoadArticleCreateTS() (types.Timestamp, error) {
+	key, err := b.MarshalArticleCreateTSKey()
+	if err != nil {
+		return types.ZeroTimestamp, err
+	}
+
+	return b.loadTS(key)
+}
+
+func (b *Board) MarshalArticleCreateTSKey() ([]byte, error) {
+	return common.Concat([][]byte{DBArticleCreateTSPrefix, b.ID[:]})
+}
+
+func (b *Board) saveTS(key []byte, ts types.Timestamp) error {
+	val := &pttdb.DBable{
+		UpdateTS: ts,
+	}
+	marshaled, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.db.boardCore.TryPut(key, marshaled, ts)
+	if err != nil && err != pttdb.ErrInvalidUpdateTS {
+		return err
+	}
+
+	return nil
+}
+
+func (b *Board) loadTS(key []byte) (types.Timestamp, error) {
+	data, err := b.db.boardCore.Get(key)
+	if err != nil {
+		if err == pttdb.ErrNotFound {
+			err = nil
+		}
+		return types.ZeroTimestamp, err
+	}
+
+	val := &pttdb.DBable{}
+	err = json.Unmarshal(data, val)
+	if err != nil {
+		return types.ZeroTimestamp, err
+	}
+
+	return val.UpdateTS, nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package content
+
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+// optype
+const (
+	BoardOpTypeInvalid pkgservice.OpType = iota
+
+	BoardOpTypeCreateBoard
+	BoardOpTypeDeleteBoard
+
+	BoardOpTypeCreateArticle
+	BoardOpTypeUpdateArticle
+	BoardOpTypeDeleteArticle
+
+	BoardOpTypeCreateComment
+	BoardOpTypeDeleteComment
+
+	BoardOpTypeCreateMedia
+
+	NBoardOpType
+)
+
+type BoardOpCreateBoard struct {
+	Title []byte `json:"T"`
+}
+
+type BoardOpDeleteBoard struct {
+}
+
+type BoardOpCreateArticle struct {
+	BlockInfoID *types.PttID `json:"BID"`
+	Hashs       [][][]byte   `json:"H"`
+	NBlock      int          `json:"NB"`
+
+	MediaIDs []*types.PttID `json:"ms,omitempty"`
+
+	TitleHash []byte `json:"t"`
+}
+
+type BoardOpUpdateArticle struct {
+	BlockInfoID *types.PttID `json:"BID"`
+	Hashs       [][][]byte   `json:"H"`
+	NBlock      int          `json:"NB"`
+
+	MediaIDs []*types.PttID `json:"ms,omitempty"`
+
+	TitleHash []byte `json:"t"`
+}
+
+type BoardOpDeleteArticle struct {
+}
+
+type BoardOpCreateComment struct {
+	ArticleID   *types.PttID           `json:"AID"`
+	CommentType pkgservice.CommentType `json:"mt"`
+
+	BlockInfoID *types.PttID `json:"BID"`
+	Hashs       [][][]byte   `json:"H"`
+	NBlock      int          `json:"NB"`
+
+	MediaIDs []*types.PttID `json:"ms,omitempty"`
+}
+
+type BoardOpDeleteComment struct {
+}
+
+type BoardOpCreateMedia struct {
+	BlockInfoID *types.PttID `json:"BID"` // resized content-block-id
+	Hashs       [][][]byte   `json:"H"`
+	NBlock      int          `json:"NB"`
+}
+
+func (o *BoardOpCreateBoard) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.Bytes(o.Title)
+}
+
+func (o *BoardOpDeleteBoard) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+}
+
+func (o *BoardOpCreateArticle) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.PttID(o.BlockInfoID)
+	enc.Hashs(o.Hashs)
+	enc.Int64(int64(o.NBlock))
+	enc.PttIDs(o.MediaIDs)
+	enc.Bytes(o.TitleHash)
+}
+
+func (o *BoardOpUpdateArticle) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.PttID(o.BlockInfoID)
+	enc.Hashs(o.Hashs)
+	enc.Int64(int64(o.NBlock))
+	enc.PttIDs(o.MediaIDs)
+	enc.Bytes(o.TitleHash)
+}
+
+func (o *BoardOpDeleteArticle) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+}
+
+func (o *BoardOpCreateComment) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.PttID(o.ArticleID)
+	enc.Int64(int64(o.CommentType))
+	enc.PttID(o.BlockInfoID)
+	enc.Hashs(o.Hashs)
+	enc.Int64(int64(o.NBlock))
+	enc.PttIDs(o.MediaIDs)
+}
+
+func (o *BoardOpDeleteComment) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+}
+
+func init() {
+	pkgservice.RegisterOpData(DBBoardOplogPrefix, map[pkgservice.OpType]func() pkgservice.CanonicalOpData{
+		BoardOpTypeCreateBoard:   func() pkgservice.CanonicalOpData { return &BoardOpCreateBoard{} },
+		BoardOpTypeDeleteBoard:   func() pkgservice.CanonicalOpData { return &BoardOpDeleteBoard{} },
+		BoardOpTypeCreateArticle: func() pkgservice.CanonicalOpData { return &BoardOpCreateArticle{} },
+		BoardOpTypeUpdateArticle: func() pkgservice.CanonicalOpData { return &BoardOpUpdateArticle{} },
+		BoardOpTypeDeleteArticle: func() pkgservice.CanonicalOpData { return &BoardOpDeleteArticle{} },
+		BoardOpTypeCreateComment: func() pkgservice.CanonicalOpData { return &BoardOpCreateComment{} },
+		BoardOpTypeDeleteComment: func() pkgservice.CanonicalOpData { return &BoardOpDeleteComment{} },
+		BoardOpTypeCreateMedia:   func() pkgservice.CanonicalOpData { return &pkgservice.OpCreateMedia{} },
+	})
+}
@@ -0,0 +1,265 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package content
+
+import (
+	"encoding/json"
+
+	"github.com/ailabstw/go-pttai-core/common"
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+/*
+Comment is the comment of the article. The comments are stored with
+the article-id as part of the key, so the comments of an article
+are iterated together.
+*/
+type Comment struct {
+	*pkgservice.BaseObject `json:"b"`
+
+	UpdateTS types.Timestamp `json:"UT"`
+
+	SyncInfo *pkgservice.BaseSyncInfo `json:"s,omitempty"`
+
+	ArticleID   *types.PttID           `json:"AID"`
+	CommentType pkgservice.CommentType `json:"mt"`
+}
+
+func NewComment(
+	createTS types.Timestamp,
+	creatorID *types.PttID,
+	entityID *types.PttID,
+
+	logID *types.PttID,
+
+	status types.Status,
+
+	articleID *types.PttID,
+	commentType pkgservice.CommentType,
+
+) (*Comment, error) {
+
+	id, err := types.NewPttID()
+	if err != nil {
+		return nil, err
+	}
+
+	o := pkgservice.NewObject(id, createTS, creatorID, entityID, logID, status)
+
+	return &Comment{
+		BaseObject: o,
+		UpdateTS:   createTS,
+
+		ArticleID:   articleID,
+		CommentType: commentType,
+	}, nil
+}
+
+func NewEmptyComment() *Comment {
+	return &Comment{BaseObject: &pkgservice.BaseObject{}}
+}
+
+func CommentsToObjs(typedObjs []*Comment) []pkgservice.Object {
+	objs := make([]pkgservice.Object, len(typedObjs))
+	for i, obj := range typedObjs {
+		objs[i] = obj
+	}
+	return objs
+}
+
+func ObjsToComments(objs []pkgservice.Object) []*Comment {
+	typedObjs := make([]*Comment, len(objs))
+	for i, obj := range objs {
+		typedObjs[i] = obj.(*Comment)
+	}
+	return typedObjs
+}
+
+func (pm *ProtocolManager) SetCommentDB(c *Comment) {
+	c.SetDB(pm.db.board, pm.DBObjLock(), pm.Entity().GetID(), pm.dbCommentPrefix, pm.dbCommentIdxPrefix, pm.SetBlockInfoDB, pm.SetMediaDB)
+}
+
+func (c *Comment) Save(isLocked bool) error {
+	var err error
+
+	if !isLocked {
+		err = c.Lock()
+		if err != nil {
+			return err
+		}
+		defer c.Unlock()
+	}
+
+	key, err := c.MarshalKey()
+	if err != nil {
+		return err
+	}
+	marshaled, err := c.Marshal()
+	if err != nil {
+		return err
+	}
+
+	idxKey, err := c.IdxKey()
+	if err != nil {
+		return err
+	}
+
+	idx := &pttdb.Index{Keys: [][]byte{key}, UpdateTS: c.UpdateTS}
+
+	kvs := []*pttdb.KeyVal{
+		&pttdb.KeyVal{K: key, V: marshaled},
+	}
+
+	log.Debug("Comment.Save: to ForcePutAll", "idxKey", idxKey, "key", kvs[0].K)
+
+	_, err = c.DB().ForcePutAll(idxKey, idx, kvs)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Comment) NewEmptyObj() pkgservice.Object {
+	newObj := NewEmptyComment()
+	newObj.CloneDB(c.BaseObject)
+	return newObj
+}
+
+func (c *Comment) GetNewObjByID(id *types.PttID, isLocked bool) (pkgservice.Object, error) {
+	newObj := c.NewEmptyObj()
+	newObj.SetID(id)
+	err := newObj.GetByID(isLocked)
+	if err != nil {
+		return nil, err
+	}
+	return newObj, nil
+}
+
+func (c *Comment) SetUpdateTS(ts types.Timestamp) {
+	c.UpdateTS = ts
+}
+
+func (c *Comment) GetUpdateTS() types.Timestamp {
+	return c.UpdateTS
+}
+
+func (c *Comment) Get(isLocked bool) error {
+	var err error
+
+	if !isLocked {
+		err = c.RLock()
+		if err != nil {
+			return err
+		}
+		defer c.RUnlock()
+	}
+
+	key, err := c.MarshalKey()
+	if err != nil {
+		return err
+	}
+
+	val, err := c.DB().DBGet(key)
+	if err != nil {
+		return err
+	}
+
+	return c.Unmarshal(val)
+}
+
+func (c *Comment) GetByID(isLocked bool) error {
+	var err error
+
+	val, err := c.GetValueByID(isLocked)
+	if err != nil {
+		return err
+	}
+
+	return c.Unmarshal(val)
+}
+
+func (c *Comment) MarshalKey() ([]byte, error) {
+	if c.ArticleID == nil {
+		return nil, ErrInvalidArticle
+	}
+
+	marshalTimestamp, err := c.CreateTS.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return common.Concat([][]byte{c.FullDBPrefix(), c.ArticleID[:], marshalTimestamp, c.ID[:]})
+}
+
+func (c *Comment) Marshal() ([]byte, error) {
+	return json.Marshal(c)
+}
+
+func (c *Comment) Unmarshal(theBytes []byte) error {
+	return json.Unmarshal(theBytes, c)
+}
+
+func (c *Comment) GetSyncInfo() pkgservice.SyncInfo {
+	if c.SyncInfo == nil {
+		return nil
+	}
+	return c.SyncInfo
+}
+
+func (c *Comment) SetSyncInfo(theSyncInfo pkgservice.SyncInfo) error {
+	if theSyncInfo == nil {
+		c.SyncInfo = nil
+		return nil
+	}
+
+	syncInfo, ok := theSyncInfo.(*pkgservice.BaseSyncInfo)
+	if !ok {
+		return pkgservice.ErrInvalidData
+	}
+	c.SyncInfo = syncInfo
+
+	return nil
+}
+
+func (c *Comment) DeleteAll(isLocked bool) error {
+	var err error
+	if !isLocked {
+		err = c.Lock()
+		if err != nil {
+			return err
+		}
+		defer c.Unlock()
+	}
+
+	// block-info
+	blockInfo := c.GetBlockInfo()
+	if blockInfo != nil {
+		setBlockInfoDB := c.SetBlockInfoDB()
+		setBlockInfoDB(blockInfo, c.ID)
+
+		blockInfo.Remove(false)
+	}
+
+	// delete
+	c.Delete(true)
+
+	return nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package content
+
+import (
+	"path/filepath"
+
+	"github.com/ailabstw/go-pttai-core/node"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+// config
+var (
+	DefaultConfig = Config{
+		DataDir: filepath.Join(node.DefaultDataDir(), "content"),
+
+		MaxSyncRandomSeconds: 7,
+		MinSyncRandomSeconds: 5,
+	}
+)
+
+// db
+var (
+	DBBoardIdxPrefix         = []byte(".bdix")
+	DBBoardPrefix            = []byte(".bddb")
+	DBBoardOplogPrefix       = []byte(".bdlg")
+	DBBoardIdxOplogPrefix    = []byte(".bdig")
+	DBBoardMerkleOplogPrefix = []byte(".bdmk")
+
+	DBArticlePrefix    = []byte(".ardb")
+	DBArticleIdxPrefix = []byte(".arix")
+
+	DBCommentPrefix    = []byte(".cmdb")
+	DBCommentIdxPrefix = []byte(".cmix")
+
+	DBLastSeenPrefix        = []byte(".bdls")
+	DBArticleCreateTSPrefix = []byte(".bdac")
+
+	DBBoardNodePrefix = []byte(".bdnd")
+)
+
+// protocol
+const (
+	_ pkgservice.OpType = iota + pkgservice.NMsg
+	// board-oplog
+	AddBoardOplogMsg //30
+	AddBoardOplogsMsg
+
+	AddPendingBoardOplogMsg
+	AddPendingBoardOplogsMsg
+
+	SyncBoardOplogMsg
+	SyncBoardOplogAckMsg
+	SyncBoardOplogNewOplogsMsg
+	SyncBoardOplogNewOplogsAckMsg
+
+	InvalidSyncBoardOplogMsg
+
+	ForceSyncBoardOplogMsg
+	ForceSyncBoardOplogAckMsg
+	ForceSyncBoardOplogByMerkleMsg
+	ForceSyncBoardOplogByMerkleAckMsg
+	ForceSyncBoardOplogByOplogAckMsg
+
+	SyncPendingBoardOplogMsg
+	SyncPendingBoardOplogAckMsg
+
+	// article
+	SyncCreateArticleMsg
+	SyncCreateArticleAckMsg
+
+	SyncCreateArticleBlockMsg
+	SyncCreateArticleBlockAckMsg
+
+	SyncUpdateArticleMsg
+	SyncUpdateArticleAckMsg
+
+	SyncUpdateArticleBlockMsg
+	SyncUpdateArticleBlockAckMsg
+
+	// comment
+	SyncCreateCommentMsg
+	SyncCreateCommentAckMsg
+
+	SyncCreateCommentBlockMsg
+	SyncCreateCommentBlockAckMsg
+)
+
+// max-masters
+const (
+	MaxMasters = 1
+)
+
+// sync
+var (
+	MaxSyncRandomSeconds = 20
+	MinSyncRandomSeconds = 10
+)
+
+// op-key
+var (
+	RenewOpKeySeconds  int64 = 86400
+	ExpireOpKeySeconds int64 = 259200
+)
+
+// board
+const (
+	MaxTitleLength = 128
+)
+
+// article
+const (
+	NFirstLineInBlock = 20
+
+	MaxCommentLines = 5
+)
+
+/*
+contentDB is the dbs of the content-service of a node.
+*/
+type contentDB struct {
+	boardCore pttdb.Storage
+	board     pttdb.IndexBatch
+
+	meta pttdb.Storage
+}
+
+func newContentDB(dataDir string, engine pttdb.Engine) (*contentDB, error) {
+	var err error
+
+	db := &contentDB{}
+
+	db.boardCore, err = pttdb.NewStorageWithEngine(engine, "content", dataDir)
+	if err != nil {
+		return nil, err
+	}
+	db.board, err = pttdb.NewStorageBatch(db.boardCore)
+	if err != nil {
+		return nil, err
+	}
+
+	db.meta, err = pttdb.NewStorageWithEngine(engine, "contentmeta", dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func (db *contentDB) Close() {
+	if db.boardCore != nil {
+		db.boardCore.Close()
+		db.boardCore = nil
+	}
+	if db.board != nil {
+		db.board = nil
+	}
+
+	if db.meta != nil {
+		db.meta.Close()
+		db.meta = nil
+	}
+}
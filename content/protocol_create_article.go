@@ -0,0 +1,163 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package content
+
+import (
+	"reflect"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+type CreateArticle struct {
+	Title    []byte
+	Article  [][]byte
+	MediaIDs []*types.PttID
+}
+
+func (pm *ProtocolManager) CreateArticle(title []byte, article [][]byte, mediaIDs []*types.PttID) (*Article, error) {
+
+	myID := pm.Router().GetMyEntity().GetID()
+
+	if !pm.IsMember(myID, false) {
+		return nil, types.ErrInvalidID
+	}
+
+	if len(title) == 0 || len(title) > MaxTitleLength {
+		return nil, ErrInvalidTitle
+	}
+
+	data := &CreateArticle{
+		Title:    title,
+		Article:  article,
+		MediaIDs: mediaIDs,
+	}
+
+	obj, err := pm.CreateObject(
+		data,
+		BoardOpTypeCreateArticle,
+
+		pm.boardOplogMerkle,
+
+		pm.NewArticle,
+		pm.NewBoardOplogWithTS,
+		pm.increateArticle,
+
+		pm.SetBoardDB,
+		pm.broadcastBoardOplogsCore,
+		pm.broadcastBoardOplogCore,
+
+		pm.postcreateArticle,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	theArticle, ok := obj.(*Article)
+	if !ok {
+		return nil, pkgservice.ErrInvalidData
+	}
+
+	return theArticle, nil
+}
+
+func (pm *ProtocolManager) NewArticle(theData pkgservice.CreateData) (pkgservice.Object, pkgservice.OpData, error) {
+
+	myID := pm.Router().GetMyEntity().GetID()
+	entityID := pm.Entity().GetID()
+
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opData := &BoardOpCreateArticle{}
+
+	data, ok := theData.(*CreateArticle)
+	if !ok {
+		return nil, nil, pkgservice.ErrInvalidData
+	}
+
+	theArticle, err := NewArticle(ts, myID, entityID, nil, types.StatusInit, data.Title)
+	if err != nil {
+		return nil, nil, err
+	}
+	pm.SetArticleDB(theArticle)
+
+	return theArticle, opData, nil
+}
+
+func (pm *ProtocolManager) increateArticle(theObj pkgservice.Object, theData pkgservice.CreateData, oplog *pkgservice.BaseOplog, theOpData pkgservice.OpData) error {
+
+	obj, ok := theObj.(*Article)
+	if !ok {
+		return pkgservice.ErrInvalidData
+	}
+
+	data, ok := theData.(*CreateArticle)
+	if !ok {
+		return pkgservice.ErrInvalidData
+	}
+
+	opData, ok := theOpData.(*BoardOpCreateArticle)
+	if !ok {
+		return pkgservice.ErrInvalidData
+	}
+
+	// block-info
+	blockID, blockHashs, err := pm.SplitContentBlocks(nil, obj.ID, data.Article, NFirstLineInBlock)
+	log.Debug("increateArticle: after SplitContentBlocks", "obj", obj.ID, "blockID", blockID, "e", err)
+	if err != nil {
+		log.Error("increateArticle: Unable to SplitContentBlocks", "e", err)
+		return err
+	}
+
+	blockInfo, err := pkgservice.NewBlockInfo(blockID, blockHashs, data.MediaIDs, obj.CreatorID)
+	if err != nil {
+		return err
+	}
+	blockInfo.SetIsAllGood()
+
+	theObj.SetBlockInfo(blockInfo)
+
+	// op-data
+	opData.BlockInfoID = blockID
+	opData.NBlock = blockInfo.NBlock
+	opData.Hashs = blockHashs
+	opData.MediaIDs = data.MediaIDs
+	opData.TitleHash = types.Hash(data.Title)
+
+	return nil
+}
+
+func (pm *ProtocolManager) postcreateArticle(theObj pkgservice.Object, oplog *pkgservice.BaseOplog) error {
+
+	log.Debug("postcreateArticle: start")
+
+	entity := pm.Entity().(*Board)
+	entity.SaveArticleCreateTS(oplog.UpdateTS)
+
+	myID := pm.Router().GetMyEntity().GetID()
+	creatorID := theObj.GetCreatorID()
+
+	if reflect.DeepEqual(myID, creatorID) {
+		pm.SaveLastSeen(oplog.UpdateTS)
+	}
+
+	return nil
+}
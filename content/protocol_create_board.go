@@ -0,0 +1,68 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package content
+
+import (
+	"github.com/ailabstw/go-pttai-core/log"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+func (spm *ServiceProtocolManager) CreateBoard(title []byte) (*Board, error) {
+
+	myID := spm.Router().GetMyEntity().GetID()
+
+	if len(title) == 0 || len(title) > MaxTitleLength {
+		return nil, ErrInvalidTitle
+	}
+
+	data := &BoardOpCreateBoard{
+		Title: title,
+	}
+	entity, err := spm.CreateEntity(data, BoardOpTypeCreateBoard, spm.NewBoard, spm.NewBoardOplogWithTS, nil, nil)
+	log.Debug("CreateBoard: after CreateEntity", "e", err)
+	if err != nil {
+		return nil, err
+	}
+
+	bd, ok := entity.(*Board)
+	if !ok {
+		return nil, pkgservice.ErrInvalidEntity
+	}
+
+	// save article-create-ts
+	bd.SaveArticleCreateTS(bd.GetCreateTS())
+	bd.SaveLastSeen(bd.GetCreateTS())
+
+	log.Debug("CreateBoard: done", "board", bd.ID, "myID", myID)
+
+	return bd, nil
+}
+
+func (spm *ServiceProtocolManager) NewBoard(theData pkgservice.CreateData, router pkgservice.Router, service pkgservice.Service) (pkgservice.Entity, pkgservice.OpData, error) {
+
+	data, ok := theData.(*BoardOpCreateBoard)
+	if !ok {
+		return nil, nil, pkgservice.ErrInvalidData
+	}
+
+	bd, err := NewBoard(data.Title, router, service, spm, spm.GetDBLock())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return bd, data, nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+package content
+
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+type CreateComment struct {
+	ArticleID   *types.PttID
+	CommentType pkgservice.CommentType
+	Comment     [][]byte
+	MediaIDs    []*types.PttID
+}
+
+func (pm *ProtocolManager) CreateComment(articleID *types.PttID, commentType pkgservice.CommentType, comment [][]byte, mediaIDs []*types.PttID) (*Comment, error) {
+
+	myID := pm.Router().GetMyEntity().GetID()
+
+	if !pm.IsMember(myID, false) {
+		return nil, types.ErrInvalidID
+	}
+
+	if commentType < pkgservice.CommentTypePush || commentType > pkgservice.CommentTypeNone {
+		return nil, ErrInvalidComment
+	}
+
+	if len(comment) == 0 || len(comment) > MaxCommentLines {
+		return nil, ErrInvalidComment
+	}
+
+	// article
+	article := NewEmptyArticle()
+	pm.SetArticleDB(article)
+	article.SetID(articleID)
+	err := article.GetByID(false)
+	if err != nil {
+		return nil, err
+	}
+	if article.Status != types.StatusAlive {
+		return nil, ErrInvalidArticle
+	}
+
+	data := &CreateComment{
+		ArticleID:   articleID,
+		CommentType: commentType,
+		Comment:     comment,
+		MediaIDs:    mediaIDs,
+	}
+
+	obj, err := pm.CreateObject(
+		data,
+		BoardOpTypeCreateComment,
+
+		pm.boardOplogMerkle,
+
+		pm.NewComment,
+		pm.NewBoardOplogWithTS,
+		pm.increateComment,
+
+		pm.SetBoardDB,
+		pm.broadcastBoardOplogsCore,
+		pm.broadcastBoardOplogCore,
+
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	theComment, ok := obj.(*Comment)
+	if !ok {
+		return nil, pkgservice.ErrInvalidData
+	}
+
+	return theComment, nil
+}
+
+func (pm *ProtocolManager) NewComment(theData pkgservice.CreateData) (pkgservice.Object, pkgservice.OpData, error) {
+
+	myID := pm.Router().GetMyEntity().GetID()
+	entityID := pm.Entity().GetID()
+
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, ok := theData.(*CreateComment)
+	if !ok {
+		return nil, nil, pkgservice.ErrInvalidData
+	}
+
+	opData := &BoardOpCreateComment{}
+
+	theComment, err := NewComment(ts, myID, entityID, nil, types.StatusInit, data.ArticleID, data.CommentType)
+	if err != nil {
+		return nil, nil, err
+	}
+	pm.SetCommentDB(theComment)
+
+	return theComment, opData, nil
+}
+
+func (pm *ProtocolManager) increateComment(theObj pkgservice.Object, theData pkgservice.CreateData, oplog *pkgservice.BaseOplog, theOpData pkgservice.OpData) error {
+
+	obj, ok := theObj.(*Comment)
+	if !ok {
+		return pkgservice.ErrInvalidData
+	}
+
+	data, ok := theData.(*CreateComment)
+	if !ok {
+		return pkgservice.ErrInvalidData
+	}
+
+	opData, ok := theOpData.(*BoardOpCreateComment)
+	if !ok {
+		return pkgservice.ErrInvalidData
+	}
+
+	// block-info
+	blockID, blockHashs, err := pm.SplitContentBlocks(nil, obj.ID, data.Comment, MaxCommentLines)
+	if err != nil {
+		log.Error("increateComment: Unable to SplitContentBlocks", "e", err)
+		return err
+	}
+
+	blockInfo, err := pkgservice.NewBlockInfo(blockID, blockHashs, data.MediaIDs, obj.CreatorID)
+	if err != nil {
+		return err
+	}
+	blockInfo.SetIsAllGood()
+
+	theObj.SetBlockInfo(blockInfo)
+
+	// op-data
+	opData.ArticleID = data.ArticleID
+	opData.CommentType = data.CommentType
+
+	opData.BlockInfoID = blockID
+	opData.NBlock = blockInfo.NBlock
+	opData.Hashs = blockHashs
+	opData.MediaIDs = data.MediaIDs
+
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+package content
+
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+func (pm *ProtocolManager) DeleteArticle(articleID *types.PttID) error {
+
+	myID := pm.Router().GetMyEntity().GetID()
+
+	if !pm.IsMember(myID, false) {
+		return types.ErrInvalidID
+	}
+
+	origObj := NewEmptyArticle()
+	pm.SetArticleDB(origObj)
+
+	opData := &BoardOpDeleteArticle{}
+
+	err := pm.DeleteObject(
+		articleID,
+		BoardOpTypeDeleteArticle,
+
+		origObj,
+		opData,
+
+		pm.boardOplogMerkle,
+
+		pm.SetBoardDB,
+
+		pm.NewBoardOplog,
+		nil,
+		pm.setPendingDeleteArticleSyncInfo,
+		pm.broadcastBoardOplogCore,
+		nil,
+	)
+	log.Debug("DeleteArticle: after DeleteObject", "articleID", articleID, "e", err)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (pm *ProtocolManager) setPendingDeleteArticleSyncInfo(theObj pkgservice.Object, status types.Status, oplog *pkgservice.BaseOplog) error {
+
+	obj, ok := theObj.(*Article)
+	if !ok {
+		return pkgservice.ErrInvalidData
+	}
+
+	syncInfo := NewEmptySyncArticleInfo()
+	syncInfo.InitWithOplog(status, oplog)
+
+	obj.SyncInfo = syncInfo
+
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+package content
+
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+/*
+GetCommentList gets the comments of the article, starting from startID.
+*/
+func (pm *ProtocolManager) GetCommentList(articleID *types.PttID, startID *types.PttID, limit int, listOrder pttdb.ListOrder) ([]*Comment, error) {
+
+	obj := NewEmptyComment()
+	pm.SetCommentDB(obj)
+
+	iter, err := obj.GetCrossObjIterWithObj(articleID[:], startID, listOrder, false)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Release()
+
+	funcIter := pttdb.GetFuncIter(iter, listOrder)
+
+	comments := make([]*Comment, 0)
+	var each *Comment
+	i := 0
+	for funcIter() {
+		if limit > 0 && i >= limit {
+			break
+		}
+
+		each = NewEmptyComment()
+		err = each.Unmarshal(iter.Value())
+		if err != nil {
+			log.Warn("GetCommentList: unable to unmarshal", "e", err)
+			continue
+		}
+		pm.SetCommentDB(each)
+
+		comments = append(comments, each)
+
+		i++
+	}
+
+	return comments, nil
+}
+
+func (pm *ProtocolManager) GetCommentBlock(comment *Comment) (*pkgservice.ContentBlock, error) {
+
+	blockInfo := comment.GetBlockInfo()
+	if blockInfo == nil {
+		return nil, pkgservice.ErrInvalidBlock
+	}
+	pm.SetBlockInfoDB(blockInfo, comment.ID)
+
+	contentBlocks, err := pkgservice.GetContentBlockList(blockInfo, 1, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(contentBlocks) == 0 {
+		return nil, pkgservice.ErrInvalidBlock
+	}
+
+	return contentBlocks[0], nil
+}
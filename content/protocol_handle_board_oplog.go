@@ -0,0 +1,257 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package content
+
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+type ProcessBoardInfo struct {
+	CreateArticleInfo map[types.PttID]*pkgservice.BaseOplog
+	UpdateArticleInfo map[types.PttID]*pkgservice.BaseOplog
+	DeleteArticleInfo map[types.PttID]*pkgservice.BaseOplog
+
+	CreateCommentInfo map[types.PttID]*pkgservice.BaseOplog
+	DeleteCommentInfo map[types.PttID]*pkgservice.BaseOplog
+
+	CreateMediaInfo map[types.PttID]*pkgservice.BaseOplog
+
+	BlockInfo map[types.PttID]*pkgservice.BaseOplog
+
+	BoardInfo map[types.PttID]*pkgservice.BaseOplog
+}
+
+func NewProcessBoardInfo() *ProcessBoardInfo {
+	return &ProcessBoardInfo{
+		CreateArticleInfo: make(map[types.PttID]*pkgservice.BaseOplog),
+		UpdateArticleInfo: make(map[types.PttID]*pkgservice.BaseOplog),
+		DeleteArticleInfo: make(map[types.PttID]*pkgservice.BaseOplog),
+
+		CreateCommentInfo: make(map[types.PttID]*pkgservice.BaseOplog),
+		DeleteCommentInfo: make(map[types.PttID]*pkgservice.BaseOplog),
+
+		CreateMediaInfo: make(map[types.PttID]*pkgservice.BaseOplog),
+
+		BlockInfo: make(map[types.PttID]*pkgservice.BaseOplog),
+
+		BoardInfo: make(map[types.PttID]*pkgservice.BaseOplog),
+	}
+}
+
+/**********
+ * Process Oplog
+ **********/
+
+func (pm *ProtocolManager) processBoardLog(oplog *pkgservice.BaseOplog, processInfo pkgservice.ProcessInfo) (origLogs []*pkgservice.BaseOplog, err error) {
+	info, ok := processInfo.(*ProcessBoardInfo)
+	if !ok {
+		return nil, pkgservice.ErrInvalidData
+	}
+
+	switch oplog.Op {
+	case BoardOpTypeDeleteBoard:
+		origLogs, err = pm.handleDeleteBoardLogs(oplog, info)
+	case BoardOpTypeCreateArticle:
+		origLogs, err = pm.handleCreateArticleLogs(oplog, info)
+	case BoardOpTypeUpdateArticle:
+		origLogs, err = pm.handleUpdateArticleLogs(oplog, info)
+	case BoardOpTypeDeleteArticle:
+		origLogs, err = pm.handleDeleteArticleLogs(oplog, info)
+
+	case BoardOpTypeCreateComment:
+		origLogs, err = pm.handleCreateCommentLogs(oplog, info)
+	case BoardOpTypeDeleteComment:
+		origLogs, err = pm.handleDeleteCommentLogs(oplog, info)
+
+	case BoardOpTypeCreateMedia:
+	}
+	return
+}
+
+/**********
+ * Process Pending Oplog
+ **********/
+
+func (pm *ProtocolManager) processPendingBoardLog(oplog *pkgservice.BaseOplog, processInfo pkgservice.ProcessInfo) (isToSign types.Bool, origLogs []*pkgservice.BaseOplog, err error) {
+	info, ok := processInfo.(*ProcessBoardInfo)
+	if !ok {
+		return false, nil, pkgservice.ErrInvalidData
+	}
+
+	switch oplog.Op {
+	case BoardOpTypeDeleteBoard:
+		isToSign, origLogs, err = pm.handlePendingDeleteBoardLogs(oplog, info)
+
+	case BoardOpTypeCreateArticle:
+		isToSign, origLogs, err = pm.handlePendingCreateArticleLogs(oplog, info)
+	case BoardOpTypeUpdateArticle:
+		isToSign, origLogs, err = pm.handlePendingUpdateArticleLogs(oplog, info)
+	case BoardOpTypeDeleteArticle:
+		isToSign, origLogs, err = pm.handlePendingDeleteArticleLogs(oplog, info)
+
+	case BoardOpTypeCreateComment:
+		isToSign, origLogs, err = pm.handlePendingCreateCommentLogs(oplog, info)
+	case BoardOpTypeDeleteComment:
+		isToSign, origLogs, err = pm.handlePendingDeleteCommentLogs(oplog, info)
+
+	case BoardOpTypeCreateMedia:
+	}
+
+	return
+}
+
+/**********
+ * Postprocess Oplog
+ **********/
+
+func (pm *ProtocolManager) postprocessBoardOplogs(processInfo pkgservice.ProcessInfo, toBroadcastLogs []*pkgservice.BaseOplog, peer *pkgservice.PttPeer, isPending bool) (err error) {
+	info, ok := processInfo.(*ProcessBoardInfo)
+	if !ok {
+		err = pkgservice.ErrInvalidData
+	}
+
+	// article
+	createArticleIDs := pkgservice.ProcessInfoToSyncIDList(info.CreateArticleInfo, BoardOpTypeCreateArticle)
+	updateArticleIDs := pkgservice.ProcessInfoToSyncIDList(info.UpdateArticleInfo, BoardOpTypeUpdateArticle)
+
+	log.Debug("postprocessBoardOplogs: to syncArticle", "createArticleIDs", createArticleIDs, "updateArticleIDs", updateArticleIDs)
+
+	pm.SyncArticle(SyncCreateArticleMsg, createArticleIDs, peer)
+	pm.SyncArticle(SyncUpdateArticleMsg, updateArticleIDs, peer)
+
+	// comment
+	createCommentIDs := pkgservice.ProcessInfoToSyncIDList(info.CreateCommentInfo, BoardOpTypeCreateComment)
+
+	pm.SyncComment(SyncCreateCommentMsg, createCommentIDs, peer)
+
+	// blocks
+	createArticleBlockIDs := pkgservice.ProcessInfoToSyncBlockIDList(info.BlockInfo, BoardOpTypeCreateArticle)
+	updateArticleBlockIDs := pkgservice.ProcessInfoToSyncBlockIDList(info.BlockInfo, BoardOpTypeUpdateArticle)
+	createCommentBlockIDs := pkgservice.ProcessInfoToSyncBlockIDList(info.BlockInfo, BoardOpTypeCreateComment)
+
+	log.Debug("postprocessBoardOplogs: to syncBlock", "createArticleBlockIDs", createArticleBlockIDs, "updateArticleBlockIDs", updateArticleBlockIDs, "createCommentBlockIDs", createCommentBlockIDs)
+
+	pm.SyncArticleBlock(SyncCreateArticleBlockMsg, createArticleBlockIDs, peer)
+	pm.SyncArticleBlock(SyncUpdateArticleBlockMsg, updateArticleBlockIDs, peer)
+	pm.SyncCommentBlock(SyncCreateCommentBlockMsg, createCommentBlockIDs, peer)
+
+	pm.broadcastBoardOplogsCore(toBroadcastLogs)
+
+	// post-delete-board
+	if !isPending && len(info.BoardInfo) > 0 {
+		pm.postdeleteBoard(nil, false)
+	}
+
+	return
+}
+
+/**********
+ * Set Newest Oplog
+ **********/
+
+func (pm *ProtocolManager) SetNewestBoardOplog(oplog *pkgservice.BaseOplog) (err error) {
+	var isNewer types.Bool
+
+	switch oplog.Op {
+	case BoardOpTypeDeleteBoard:
+	case BoardOpTypeCreateArticle:
+		isNewer, err = pm.setNewestCreateArticleLog(oplog)
+	case BoardOpTypeUpdateArticle:
+		isNewer, err = pm.setNewestUpdateArticleLog(oplog)
+	case BoardOpTypeDeleteArticle:
+		isNewer, err = pm.setNewestDeleteArticleLog(oplog)
+	case BoardOpTypeCreateComment:
+		isNewer, err = pm.setNewestCreateCommentLog(oplog)
+	case BoardOpTypeDeleteComment:
+		isNewer, err = pm.setNewestDeleteCommentLog(oplog)
+	case BoardOpTypeCreateMedia:
+	}
+
+	oplog.IsNewer = isNewer
+
+	return
+}
+
+/**********
+ * Handle Failed Oplog
+ **********/
+
+func (pm *ProtocolManager) HandleFailedBoardOplog(oplog *pkgservice.BaseOplog) (err error) {
+
+	switch oplog.Op {
+	case BoardOpTypeDeleteBoard:
+	case BoardOpTypeCreateArticle:
+		err = pm.handleFailedCreateArticleLog(oplog)
+	case BoardOpTypeUpdateArticle:
+		err = pm.handleFailedUpdateArticleLog(oplog)
+	case BoardOpTypeDeleteArticle:
+		err = pm.handleFailedDeleteArticleLog(oplog)
+	case BoardOpTypeCreateComment:
+		err = pm.handleFailedCreateCommentLog(oplog)
+	case BoardOpTypeDeleteComment:
+		err = pm.handleFailedDeleteCommentLog(oplog)
+	case BoardOpTypeCreateMedia:
+	}
+
+	return
+}
+
+/**********
+ * Handle Failed Oplog
+ **********/
+
+func (pm *ProtocolManager) HandleFailedValidBoardOplog(oplog *pkgservice.BaseOplog, processInfo pkgservice.ProcessInfo) (err error) {
+
+	info, ok := processInfo.(*ProcessBoardInfo)
+	if !ok {
+		return pkgservice.ErrInvalidData
+	}
+
+	switch oplog.Op {
+	case BoardOpTypeDeleteBoard:
+	case BoardOpTypeCreateArticle:
+		err = pm.handleFailedValidCreateArticleLog(oplog, info)
+	case BoardOpTypeUpdateArticle:
+		err = pm.handleFailedValidUpdateArticleLog(oplog, info)
+	case BoardOpTypeDeleteArticle:
+		err = pm.handleFailedValidDeleteArticleLog(oplog, info)
+	case BoardOpTypeCreateComment:
+		err = pm.handleFailedValidCreateCommentLog(oplog, info)
+	case BoardOpTypeDeleteComment:
+		err = pm.handleFailedValidDeleteCommentLog(oplog, info)
+	case BoardOpTypeCreateMedia:
+	}
+
+	return
+}
+
+func (pm *ProtocolManager) postprocessFailedValidBoardOplogs(processInfo pkgservice.ProcessInfo, peer *pkgservice.PttPeer) error {
+
+	return nil
+}
+
+/**********
+ * Postsync Oplog
+ **********/
+
+func (pm *ProtocolManager) postsyncBoardOplogs(peer *pkgservice.PttPeer) (err error) {
+	err = pm.SyncPendingBoardOplog(peer)
+
+	return
+}
@@ -0,0 +1,133 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+package content
+
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+type UpdateArticle struct {
+	Title    []byte
+	Article  [][]byte
+	MediaIDs []*types.PttID
+}
+
+func (pm *ProtocolManager) UpdateArticle(articleID *types.PttID, title []byte, article [][]byte, mediaIDs []*types.PttID) (*Article, error) {
+
+	myID := pm.Router().GetMyEntity().GetID()
+
+	if !pm.IsMember(myID, false) {
+		return nil, types.ErrInvalidID
+	}
+
+	if len(title) == 0 || len(title) > MaxTitleLength {
+		return nil, ErrInvalidTitle
+	}
+
+	data := &UpdateArticle{
+		Title:    title,
+		Article:  article,
+		MediaIDs: mediaIDs,
+	}
+
+	origObj := NewEmptyArticle()
+	pm.SetArticleDB(origObj)
+
+	opData := &BoardOpUpdateArticle{}
+
+	err := pm.UpdateObject(
+		articleID,
+
+		data,
+		BoardOpTypeUpdateArticle,
+
+		origObj,
+
+		opData,
+
+		pm.boardOplogMerkle,
+
+		pm.SetBoardDB,
+
+		pm.NewBoardOplog,
+
+		pm.inupdateArticle,
+
+		nil,
+
+		pm.broadcastBoardOplogCore,
+		pm.postupdateArticle,
+	)
+	log.Debug("UpdateArticle: after UpdateObject", "articleID", articleID, "e", err)
+	if err != nil {
+		return nil, err
+	}
+
+	return origObj, nil
+}
+
+func (pm *ProtocolManager) inupdateArticle(obj pkgservice.Object, theData pkgservice.UpdateData, oplog *pkgservice.BaseOplog, theOpData pkgservice.OpData) (pkgservice.SyncInfo, error) {
+
+	data, ok := theData.(*UpdateArticle)
+	if !ok {
+		return nil, pkgservice.ErrInvalidData
+	}
+
+	opData, ok := theOpData.(*BoardOpUpdateArticle)
+	if !ok {
+		return nil, pkgservice.ErrInvalidData
+	}
+
+	// block-info
+	blockID, blockHashs, err := pm.SplitContentBlocks(nil, obj.GetID(), data.Article, NFirstLineInBlock)
+	if err != nil {
+		log.Error("inupdateArticle: Unable to SplitContentBlocks", "e", err)
+		return nil, err
+	}
+
+	myID := pm.Router().GetMyEntity().GetID()
+	blockInfo, err := pkgservice.NewBlockInfo(blockID, blockHashs, data.MediaIDs, myID)
+	if err != nil {
+		return nil, err
+	}
+	blockInfo.SetIsAllGood()
+
+	// op-data
+	opData.BlockInfoID = blockID
+	opData.NBlock = blockInfo.NBlock
+	opData.Hashs = blockHashs
+	opData.MediaIDs = data.MediaIDs
+	opData.TitleHash = types.Hash(data.Title)
+
+	// sync-info
+	syncInfo := NewEmptySyncArticleInfo()
+	syncInfo.InitWithOplog(oplog.ToStatus(), oplog)
+	syncInfo.BlockInfo = blockInfo
+
+	syncInfo.Title = data.Title
+
+	return syncInfo, nil
+}
+
+func (pm *ProtocolManager) postupdateArticle(theObj pkgservice.Object, oplog *pkgservice.BaseOplog) error {
+
+	entity := pm.Entity().(*Board)
+	entity.SaveArticleCreateTS(oplog.UpdateTS)
+
+	return nil
+}
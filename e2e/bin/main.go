@@ -9,7 +9,9 @@ import (
 	"strconv"
 
 	"github.com/ailabstw/go-pttai-core/account"
+	"github.com/ailabstw/go-pttai-core/content"
 	"github.com/ailabstw/go-pttai-core/friend"
+	"github.com/ailabstw/go-pttai-core/group"
 	"github.com/ailabstw/go-pttai-core/log"
 	"github.com/ailabstw/go-pttai-core/me"
 	"github.com/ailabstw/go-pttai-core/node"
@@ -79,6 +81,16 @@ func prepareNode(id int, port int) (*node.Node, error) {
 	friendConfig.MinSyncRandomSeconds = 5
 	friendConfig.MaxSyncRandomSeconds = 7
 
+	groupConfig := &group.DefaultConfig
+	groupConfig.DataDir = filepath.Join(fmt.Sprintf("./tmp/test/%d/", id), "group")
+	groupConfig.MinSyncRandomSeconds = 5
+	groupConfig.MaxSyncRandomSeconds = 7
+
+	contentConfig := &content.DefaultConfig
+	contentConfig.DataDir = filepath.Join(fmt.Sprintf("./tmp/test/%d/", id), "content")
+	contentConfig.MinSyncRandomSeconds = 5
+	contentConfig.MaxSyncRandomSeconds = 7
+
 	n, err := node.New(nodeCfg)
 	if err != nil {
 		return nil, err
@@ -111,6 +123,26 @@ func prepareNode(id int, port int) (*node.Node, error) {
 			return nil, err
 		}
 
+		// group
+		groupBackend, err := group.NewBackend(ctx, groupConfig, ptt)
+		if err != nil {
+			return nil, err
+		}
+		err = ptt.RegisterService(groupBackend)
+		if err != nil {
+			return nil, err
+		}
+
+		// content
+		contentBackend, err := content.NewBackend(ctx, contentConfig, ptt)
+		if err != nil {
+			return nil, err
+		}
+		err = ptt.RegisterService(contentBackend)
+		if err != nil {
+			return nil, err
+		}
+
 		// me
 		meBackend, err := me.NewBackend(ctx, meConfig, ptt, accountBackend, friendBackend)
 		if err != nil {
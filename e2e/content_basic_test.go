@@ -0,0 +1,306 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package e2e
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/content"
+	"github.com/ailabstw/go-pttai-core/me"
+	"github.com/ailabstw/go-pttai-core/service"
+	"github.com/stretchr/testify/assert"
+	baloo "gopkg.in/h2non/baloo.v3"
+)
+
+func TestContentBasic(t *testing.T) {
+	startSignalServer()
+
+	// node 1
+	ctx1, cancel1 := context.WithTimeout(context.Background(), 240*time.Second)
+	err := runNode(ctx1, 4, 14783)
+	if err != nil {
+		panic(err)
+	}
+	defer cancel1()
+
+	time.Sleep(5 * time.Second)
+	// node 2
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 240*time.Second)
+	err = runNode(ctx2, 5, 14784)
+	if err != nil {
+		panic(err)
+	}
+	time.Sleep(5 * time.Second)
+	defer cancel2()
+	fmt.Println("node ready")
+
+	time.Sleep(5 * time.Second)
+
+	// start
+	TimeSleepDefault := 30 * time.Second
+	isDebug := true
+
+	var bodyString string
+	assert := assert.New(t)
+
+	t0 := baloo.New("http://127.0.0.1:14783")
+	t1 := baloo.New("http://127.0.0.1:14784")
+
+	// 1. get
+	bodyString = `{"id": "testID", "method": "me_get", "params": []}`
+
+	me0_1 := &me.BackendMyInfo{}
+	testCore(t0, bodyString, me0_1, t, isDebug)
+	assert.Equal(types.StatusAlive, me0_1.Status)
+
+	me1_1 := &me.BackendMyInfo{}
+	testCore(t1, bodyString, me1_1, t, isDebug)
+	assert.Equal(types.StatusAlive, me1_1.Status)
+
+	// 2. create-board
+	bodyString = `{"id": "testID", "method": "content_createBoard", "params": ["dGVzdC1ib2FyZA=="]}`
+
+	board0_2 := &content.BackendGetBoard{}
+	testCore(t0, bodyString, board0_2, t, isDebug)
+	assert.Equal(types.StatusAlive, board0_2.Status)
+	assert.Equal([]byte("test-board"), board0_2.Title)
+	assert.Equal(me0_1.ID, board0_2.CreatorID)
+
+	marshaledBoardID, _ := board0_2.ID.MarshalText()
+
+	// wait for the join-key
+	time.Sleep(5 * time.Second)
+
+	// 3. show-url
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "content_showURL", "params": ["%v"]}`, string(marshaledBoardID))
+
+	dataShowURL0_3 := &service.BackendJoinURL{}
+	testCore(t0, bodyString, dataShowURL0_3, t, isDebug)
+	url0_3 := dataShowURL0_3.URL
+
+	// 4. join-board
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "content_joinBoard", "params": ["%v"]}`, base64.StdEncoding.EncodeToString([]byte(url0_3)))
+
+	dataJoinBoard1_4 := &service.BackendJoinRequest{}
+	testCore(t1, bodyString, dataJoinBoard1_4, t, isDebug)
+	assert.Equal(me0_1.ID, dataJoinBoard1_4.CreatorID)
+	assert.Equal(me0_1.NodeID, dataJoinBoard1_4.NodeID)
+
+	// wait 30
+	t.Logf("wait 30 seconds for hand-shaking")
+	time.Sleep(TimeSleepDefault)
+
+	// 5. get-board-list
+	bodyString = `{"id": "testID", "method": "content_getBoardList", "params": ["", 0]}`
+
+	dataBoardList1_5 := &struct {
+		Result []*content.BackendGetBoard `json:"result"`
+	}{}
+	testListCore(t1, bodyString, dataBoardList1_5, t, isDebug)
+	assert.Equal(1, len(dataBoardList1_5.Result))
+	board1_5 := dataBoardList1_5.Result[0]
+	assert.Equal(board0_2.ID, board1_5.ID)
+	assert.Equal(types.StatusAlive, board1_5.Status)
+	assert.Equal([]byte("test-board"), board1_5.Title)
+
+	// 6. create-article
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "content_createArticle", "params": ["%v", "dGl0bGUx", ["dGVzdDE="], []]}`, string(marshaledBoardID))
+
+	article0_6 := &content.BackendCreateArticle{}
+	testCore(t0, bodyString, article0_6, t, isDebug)
+	assert.Equal(board0_2.ID, article0_6.BoardID)
+	assert.Equal(1, article0_6.NBlock)
+
+	marshaledArticleID, _ := article0_6.ArticleID.MarshalText()
+
+	time.Sleep(10 * time.Second)
+
+	// 6.1. get-article-list
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "content_getArticleList", "params": ["%v", "", 0, 2]}`, string(marshaledBoardID))
+
+	dataArticleList0_6_1 := &struct {
+		Result []*content.BackendGetArticle `json:"result"`
+	}{}
+	testListCore(t0, bodyString, dataArticleList0_6_1, t, isDebug)
+	assert.Equal(1, len(dataArticleList0_6_1.Result))
+
+	dataArticleList1_6_1 := &struct {
+		Result []*content.BackendGetArticle `json:"result"`
+	}{}
+	testListCore(t1, bodyString, dataArticleList1_6_1, t, isDebug)
+	assert.Equal(1, len(dataArticleList1_6_1.Result))
+	assert.Equal(dataArticleList0_6_1, dataArticleList1_6_1)
+	assert.Equal([]byte("title1"), dataArticleList1_6_1.Result[0].Title)
+
+	// 6.2. get-article-block-list
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "content_getArticleBlockList", "params": ["%v", "%v", 0]}`, string(marshaledBoardID), string(marshaledArticleID))
+
+	dataArticleBlockList1_6_2 := &struct {
+		Result []*service.ArticleBlock `json:"result"`
+	}{}
+	testListCore(t1, bodyString, dataArticleBlockList1_6_2, t, isDebug)
+	assert.Equal(1, len(dataArticleBlockList1_6_2.Result))
+	assert.Equal([][]byte{[]byte("test1")}, dataArticleBlockList1_6_2.Result[0].Buf)
+
+	// 7. update-article
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "content_updateArticle", "params": ["%v", "%v", "dGl0bGUy", ["dGVzdDI="], []]}`, string(marshaledBoardID), string(marshaledArticleID))
+
+	_, err1_7 := testCore(t1, bodyString, &content.BackendGetArticle{}, t, isDebug)
+	assert.NotEqual(0, err1_7.Code)
+
+	article0_7 := &content.BackendGetArticle{}
+	testCore(t0, bodyString, article0_7, t, isDebug)
+	assert.Equal(article0_6.ArticleID, article0_7.ID)
+
+	time.Sleep(10 * time.Second)
+
+	// 7.1. get-article-block-list
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "content_getArticleBlockList", "params": ["%v", "%v", 0]}`, string(marshaledBoardID), string(marshaledArticleID))
+
+	dataArticleBlockList1_7_1 := &struct {
+		Result []*service.ArticleBlock `json:"result"`
+	}{}
+	testListCore(t1, bodyString, dataArticleBlockList1_7_1, t, isDebug)
+	assert.Equal(1, len(dataArticleBlockList1_7_1.Result))
+	assert.Equal([][]byte{[]byte("test2")}, dataArticleBlockList1_7_1.Result[0].Buf)
+	assert.NotEqual(article0_6.BlockID, dataArticleBlockList1_7_1.Result[0].ID)
+
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "content_getArticleList", "params": ["%v", "", 0, 2]}`, string(marshaledBoardID))
+
+	dataArticleList1_7_1 := &struct {
+		Result []*content.BackendGetArticle `json:"result"`
+	}{}
+	testListCore(t1, bodyString, dataArticleList1_7_1, t, isDebug)
+	assert.Equal(1, len(dataArticleList1_7_1.Result))
+	assert.Equal([]byte("title2"), dataArticleList1_7_1.Result[0].Title)
+
+	// 8. create-comment
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "content_createComment", "params": ["%v", "%v", 0, ["Y29tbWVudDE="], []]}`, string(marshaledBoardID), string(marshaledArticleID))
+
+	comment1_8 := &content.BackendCreateComment{}
+	testCore(t1, bodyString, comment1_8, t, isDebug)
+	assert.Equal(board0_2.ID, comment1_8.BoardID)
+	assert.Equal(article0_6.ArticleID, comment1_8.ArticleID)
+
+	marshaledCommentID, _ := comment1_8.CommentID.MarshalText()
+
+	time.Sleep(10 * time.Second)
+
+	// 8.1. get-comment-list
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "content_getCommentList", "params": ["%v", "%v", "", 0, 2]}`, string(marshaledBoardID), string(marshaledArticleID))
+
+	dataCommentList0_8_1 := &struct {
+		Result []*service.ArticleBlock `json:"result"`
+	}{}
+	testListCore(t0, bodyString, dataCommentList0_8_1, t, isDebug)
+	assert.Equal(1, len(dataCommentList0_8_1.Result))
+	assert.Equal(comment1_8.CommentID, dataCommentList0_8_1.Result[0].RefID)
+	assert.Equal(me1_1.ID, dataCommentList0_8_1.Result[0].CreatorID)
+	assert.Equal([][]byte{[]byte("comment1")}, dataCommentList0_8_1.Result[0].Buf)
+
+	// 9. delete-comment
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "content_deleteComment", "params": ["%v", "%v"]}`, string(marshaledBoardID), string(marshaledCommentID))
+
+	dataDeleteComment1_9 := &struct {
+		Result bool `json:"result"`
+	}{}
+	testListCore(t1, bodyString, dataDeleteComment1_9, t, isDebug)
+	assert.Equal(true, dataDeleteComment1_9.Result)
+
+	time.Sleep(10 * time.Second)
+
+	// 9.1. get-comment-list
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "content_getCommentList", "params": ["%v", "%v", "", 0, 2]}`, string(marshaledBoardID), string(marshaledArticleID))
+
+	dataCommentList0_9_1 := &struct {
+		Result []*service.ArticleBlock `json:"result"`
+	}{}
+	testListCore(t0, bodyString, dataCommentList0_9_1, t, isDebug)
+	assert.Equal(0, len(dataCommentList0_9_1.Result))
+
+	// 10. delete-article
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "content_deleteArticle", "params": ["%v", "%v"]}`, string(marshaledBoardID), string(marshaledArticleID))
+
+	dataDeleteArticle0_10 := &struct {
+		Result bool `json:"result"`
+	}{}
+	testListCore(t0, bodyString, dataDeleteArticle0_10, t, isDebug)
+	assert.Equal(true, dataDeleteArticle0_10.Result)
+
+	time.Sleep(10 * time.Second)
+
+	// 10.1. get-article-list
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "content_getArticleList", "params": ["%v", "", 0, 2]}`, string(marshaledBoardID))
+
+	dataArticleList1_10_1 := &struct {
+		Result []*content.BackendGetArticle `json:"result"`
+	}{}
+	testListCore(t1, bodyString, dataArticleList1_10_1, t, isDebug)
+	assert.Equal(1, len(dataArticleList1_10_1.Result))
+	assert.Equal(types.StatusDeleted, dataArticleList1_10_1.Result[0].Status)
+
+	// 11. board-oplog
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "content_getBoardOplogList", "params": ["%v", "", 0, 2]}`, string(marshaledBoardID))
+
+	dataBoardOplogList0_11 := &struct {
+		Result []*content.BoardOplog `json:"result"`
+	}{}
+	testListCore(t0, bodyString, dataBoardOplogList0_11, t, isDebug)
+	assert.Equal(6, len(dataBoardOplogList0_11.Result))
+	for _, oplog := range dataBoardOplogList0_11.Result {
+		assert.Equal(types.StatusAlive, oplog.ToStatus())
+	}
+
+	dataBoardOplogList1_11 := &struct {
+		Result []*content.BoardOplog `json:"result"`
+	}{}
+	testListCore(t1, bodyString, dataBoardOplogList1_11, t, isDebug)
+	assert.Equal(dataBoardOplogList0_11, dataBoardOplogList1_11)
+
+	// 12. delete-board
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "content_deleteBoard", "params": ["%v"]}`, string(marshaledBoardID))
+
+	dataDeleteBoard1_12 := &struct {
+		Result bool `json:"result"`
+	}{}
+	_, err1_12 := testCore(t1, bodyString, dataDeleteBoard1_12, t, isDebug)
+	assert.NotEqual(0, err1_12.Code)
+
+	dataDeleteBoard0_12 := &struct {
+		Result bool `json:"result"`
+	}{}
+	testListCore(t0, bodyString, dataDeleteBoard0_12, t, isDebug)
+	assert.Equal(true, dataDeleteBoard0_12.Result)
+
+	time.Sleep(10 * time.Second)
+
+	// 12.1. get-board
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "content_getBoard", "params": ["%v"]}`, string(marshaledBoardID))
+
+	board0_12_1 := &content.BackendGetBoard{}
+	testCore(t0, bodyString, board0_12_1, t, isDebug)
+	assert.Equal(types.StatusTerminal, board0_12_1.Status)
+
+	board1_12_1 := &content.BackendGetBoard{}
+	testCore(t1, bodyString, board1_12_1, t, isDebug)
+	assert.Equal(types.StatusTerminal, board1_12_1.Status)
+}
@@ -13,7 +13,9 @@ import (
 	"testing"
 
 	"github.com/ailabstw/go-pttai-core/account"
+	"github.com/ailabstw/go-pttai-core/content"
 	"github.com/ailabstw/go-pttai-core/friend"
+	"github.com/ailabstw/go-pttai-core/group"
 	"github.com/ailabstw/go-pttai-core/log"
 	"github.com/ailabstw/go-pttai-core/me"
 	"github.com/ailabstw/go-pttai-core/node"
@@ -29,6 +31,8 @@ type Config struct {
 	Me      *me.Config
 	Account *account.Config
 	Friend  *friend.Config
+	Group   *group.Config
+	Content *content.Config
 	Router  *service.Config
 	Utils   *UtilsConfig
 }
@@ -49,6 +53,8 @@ func prepareNode(id int, port int) (*node.Node, error) {
 		Me:      &me.DefaultConfig,
 		Account: &account.DefaultConfig,
 		Friend:  &friend.DefaultConfig,
+		Group:   &group.DefaultConfig,
+		Content: &content.DefaultConfig,
 		Router:  &service.DefaultConfig,
 		Utils:   &UtilsConfig{},
 	}
@@ -60,6 +66,8 @@ func prepareNode(id int, port int) (*node.Node, error) {
 	cfg.Router.DataDir = filepath.Join(fmt.Sprintf("./tmp/test/%d/", id), "service")
 	cfg.Account.DataDir = filepath.Join(fmt.Sprintf("./tmp/test/%d/", id), "account")
 	cfg.Friend.DataDir = filepath.Join(fmt.Sprintf("./tmp/test/%d/", id), "friend")
+	cfg.Group.DataDir = filepath.Join(fmt.Sprintf("./tmp/test/%d/", id), "group")
+	cfg.Content.DataDir = filepath.Join(fmt.Sprintf("./tmp/test/%d/", id), "content")
 	fmt.Printf("me config: %v\n", cfg.Me)
 
 	err := cfg.Me.SetMyKey("", "", "", false)
@@ -99,6 +107,26 @@ func prepareNode(id int, port int) (*node.Node, error) {
 			return nil, err
 		}
 
+		// group
+		groupBackend, err := group.NewBackend(ctx, cfg.Group, ptt)
+		if err != nil {
+			return nil, err
+		}
+		err = ptt.RegisterService(groupBackend)
+		if err != nil {
+			return nil, err
+		}
+
+		// content
+		contentBackend, err := content.NewBackend(ctx, cfg.Content, ptt)
+		if err != nil {
+			return nil, err
+		}
+		err = ptt.RegisterService(contentBackend)
+		if err != nil {
+			return nil, err
+		}
+
 		// me
 		meBackend, err := me.NewBackend(ctx, cfg.Me, ptt, accountBackend, friendBackend)
 		if err != nil {
@@ -17,7 +17,9 @@
 package e2e
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"fmt"
 	"testing"
 	"time"
@@ -302,4 +304,267 @@ func TestFriendBasic(t *testing.T) {
 	assert.Equal(2, len(dataMemberList1_12_1.Result))
 	assert.Equal(dataMemberList0_12_1, dataMemberList1_12_1)
 
+	// 13. create-message
+	marshaledFriendID, _ := friend0_8.ID.MarshalText()
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "friend_createMessage", "params": ["%v", ["dGVzdDE="], []]}`, string(marshaledFriendID))
+
+	message0_13 := &friend.BackendCreateMessage{}
+	testCore(t0, bodyString, message0_13, t, isDebug)
+	assert.Equal(friend0_8.ID, message0_13.FriendID)
+
+	time.Sleep(10 * time.Second)
+
+	// 13.1 delivered
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "friend_getMessageList", "params": ["%v", "", 0, 2]}`, string(marshaledFriendID))
+
+	dataMessageList0_13_1 := &struct {
+		Result []*friend.BackendGetMessage `json:"result"`
+	}{}
+	testListCore(t0, bodyString, dataMessageList0_13_1, t, isDebug)
+	assert.Equal(1, len(dataMessageList0_13_1.Result))
+	assert.Equal(friend.MessageReceiptStatusDelivered, dataMessageList0_13_1.Result[0].ReceiptStatus)
+
+	// 13.2 read
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "friend_markFriendSeen", "params": ["%v"]}`, string(marshaledFriendID))
+
+	testCore(t1, bodyString, nil, t, isDebug)
+
+	time.Sleep(5 * time.Second)
+
+	marshaledMessageID, _ := message0_13.MessageID.MarshalText()
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "friend_getMessageReceipts", "params": ["%v", "%v"]}`, string(marshaledFriendID), string(marshaledMessageID))
+
+	dataMessageReceipts0_13_2 := &struct {
+		Result []*friend.MessageReceipt `json:"result"`
+	}{}
+	testListCore(t0, bodyString, dataMessageReceipts0_13_2, t, isDebug)
+	assert.Equal(1, len(dataMessageReceipts0_13_2.Result))
+	assert.Equal(me1_1.ID, dataMessageReceipts0_13_2.Result[0].UserID)
+	assert.Equal(friend.MessageReceiptStatusRead, dataMessageReceipts0_13_2.Result[0].Status)
+
+	dataMessageReceipts1_13_2 := &struct {
+		Result []*friend.MessageReceipt `json:"result"`
+	}{}
+	testListCore(t1, bodyString, dataMessageReceipts1_13_2, t, isDebug)
+	assert.Equal(dataMessageReceipts0_13_2, dataMessageReceipts1_13_2)
+
+	// 14. update-message
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "friend_updateMessage", "params": ["%v", "%v", ["dGVzdDI="], []]}`, string(marshaledFriendID), string(marshaledMessageID))
+
+	dataUpdateMessage1_14 := &friend.BackendGetMessage{}
+	_, err1_14 := testCore(t1, bodyString, dataUpdateMessage1_14, t, isDebug)
+	assert.NotEqual(0, err1_14.Code)
+
+	message0_14 := &friend.BackendGetMessage{}
+	testCore(t0, bodyString, message0_14, t, isDebug)
+	assert.Equal(message0_13.MessageID, message0_14.ID)
+
+	time.Sleep(10 * time.Second)
+
+	// 14.1 get-message-block-list
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "friend_getMessageBlockList", "params": ["%v", "%v", "", 0, 0, 0]}`, string(marshaledFriendID), string(marshaledMessageID))
+
+	dataMessageBlockList0_14_1 := &struct {
+		Result []*friend.BackendMessageBlock `json:"result"`
+	}{}
+	testListCore(t0, bodyString, dataMessageBlockList0_14_1, t, isDebug)
+	assert.Equal(1, len(dataMessageBlockList0_14_1.Result))
+	assert.Equal([][]byte{[]byte("test2")}, dataMessageBlockList0_14_1.Result[0].Buf)
+	assert.NotEqual(message0_13.BlockID, dataMessageBlockList0_14_1.Result[0].ID)
+
+	dataMessageBlockList1_14_1 := &struct {
+		Result []*friend.BackendMessageBlock `json:"result"`
+	}{}
+	testListCore(t1, bodyString, dataMessageBlockList1_14_1, t, isDebug)
+	assert.Equal(1, len(dataMessageBlockList1_14_1.Result))
+	assert.Equal([][]byte{[]byte("test2")}, dataMessageBlockList1_14_1.Result[0].Buf)
+	assert.Equal(dataMessageBlockList0_14_1.Result[0].ID, dataMessageBlockList1_14_1.Result[0].ID)
+
+	// 14.2 search-messages
+	bodyString = `{"id": "testID", "method": "friend_searchMessages", "params": ["test2", "", 0]}`
+
+	dataSearchMessages1_14_2 := &struct {
+		Result []*friend.BackendGetMessage `json:"result"`
+	}{}
+	testListCore(t1, bodyString, dataSearchMessages1_14_2, t, isDebug)
+	assert.Equal(1, len(dataSearchMessages1_14_2.Result))
+	assert.Equal(message0_13.MessageID, dataSearchMessages1_14_2.Result[0].ID)
+
+	bodyString = `{"id": "testID", "method": "friend_searchMessages", "params": ["test1", "", 0]}`
+
+	dataSearchMessages1_14_2_1 := &struct {
+		Result []*friend.BackendGetMessage `json:"result"`
+	}{}
+	testListCore(t1, bodyString, dataSearchMessages1_14_2_1, t, isDebug)
+	assert.Equal(0, len(dataSearchMessages1_14_2_1.Result))
+
+	// 15. delete-message
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "friend_deleteMessage", "params": ["%v", "%v"]}`, string(marshaledFriendID), string(marshaledMessageID))
+
+	dataDeleteMessage0_15 := &struct {
+		Result bool `json:"result"`
+	}{}
+	testListCore(t0, bodyString, dataDeleteMessage0_15, t, isDebug)
+	assert.Equal(true, dataDeleteMessage0_15.Result)
+
+	time.Sleep(10 * time.Second)
+
+	// 15.1 get-message-list
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "friend_getMessageList", "params": ["%v", "", 0, 2]}`, string(marshaledFriendID))
+
+	dataMessageList0_15_1 := &struct {
+		Result []*friend.BackendGetMessage `json:"result"`
+	}{}
+	testListCore(t0, bodyString, dataMessageList0_15_1, t, isDebug)
+	assert.Equal(1, len(dataMessageList0_15_1.Result))
+	assert.Equal(types.StatusDeleted, dataMessageList0_15_1.Result[0].Status)
+
+	dataMessageList1_15_1 := &struct {
+		Result []*friend.BackendGetMessage `json:"result"`
+	}{}
+	testListCore(t1, bodyString, dataMessageList1_15_1, t, isDebug)
+	assert.Equal(1, len(dataMessageList1_15_1.Result))
+	assert.Equal(types.StatusDeleted, dataMessageList1_15_1.Result[0].Status)
+
+	// 16. create-ephemeral-message
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "friend_createMessage", "params": ["%v", ["dGVzdDM="], [], 5]}`, string(marshaledFriendID))
+
+	message1_16 := &friend.BackendCreateMessage{}
+	testCore(t1, bodyString, message1_16, t, isDebug)
+	assert.Equal(friend0_8.ID, message1_16.FriendID)
+
+	time.Sleep(3 * time.Second)
+
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "friend_getMessageList", "params": ["%v", "", 0, 2]}`, string(marshaledFriendID))
+
+	dataMessageList0_16 := &struct {
+		Result []*friend.BackendGetMessage `json:"result"`
+	}{}
+	testListCore(t0, bodyString, dataMessageList0_16, t, isDebug)
+	assert.Equal(2, len(dataMessageList0_16.Result))
+	assert.Equal(message1_16.MessageID, dataMessageList0_16.Result[1].ID)
+	assert.Equal(types.StatusAlive, dataMessageList0_16.Result[1].Status)
+	assert.NotNil(dataMessageList0_16.Result[1].ExpireTS)
+
+	time.Sleep(15 * time.Second)
+
+	// 16.1 expired
+	dataMessageList0_16_1 := &struct {
+		Result []*friend.BackendGetMessage `json:"result"`
+	}{}
+	testListCore(t0, bodyString, dataMessageList0_16_1, t, isDebug)
+	assert.Equal(2, len(dataMessageList0_16_1.Result))
+	assert.Equal(message1_16.MessageID, dataMessageList0_16_1.Result[1].ID)
+	assert.Equal(types.StatusDeleted, dataMessageList0_16_1.Result[1].Status)
+
+	dataMessageList1_16_1 := &struct {
+		Result []*friend.BackendGetMessage `json:"result"`
+	}{}
+	testListCore(t1, bodyString, dataMessageList1_16_1, t, isDebug)
+	assert.Equal(2, len(dataMessageList1_16_1.Result))
+	assert.Equal(types.StatusDeleted, dataMessageList1_16_1.Result[1].Status)
+	assert.Equal(dataMessageList0_16.Result[1].ExpireTS, dataMessageList1_16_1.Result[1].ExpireTS)
+
+	marshaledMessageID1_16, _ := message1_16.MessageID.MarshalText()
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "friend_getMessageBlockList", "params": ["%v", "%v", "", 0, 0, 0]}`, string(marshaledFriendID), string(marshaledMessageID1_16))
+
+	dataMessageBlockList0_16_1 := &struct {
+		Result []*friend.BackendMessageBlock `json:"result"`
+	}{}
+	testListCore(t0, bodyString, dataMessageBlockList0_16_1, t, isDebug)
+	assert.Equal(0, len(dataMessageBlockList0_16_1.Result))
+
+	// 17. upload-file
+	fileBuf := make([]byte, service.NByteInChunk+3)
+	for i := range fileBuf {
+		fileBuf[i] = byte(i % 251)
+	}
+	fileChunks := [][]byte{fileBuf[:service.NByteInChunk], fileBuf[service.NByteInChunk:]}
+
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "friend_beginUploadFile", "params": ["%v", "%v", %v]}`, string(marshaledFriendID), base64.StdEncoding.EncodeToString([]byte("test.bin")), len(fileBuf))
+
+	uploadFile0_17 := &friend.BackendUploadFile{}
+	testCore(t0, bodyString, uploadFile0_17, t, isDebug)
+	assert.Equal(2, uploadFile0_17.NChunk)
+	assert.Equal([]int{0, 1}, uploadFile0_17.MissingChunks)
+
+	// 17.1. upload-file-chunk (resumable in any order)
+	marshaledUploadID, _ := uploadFile0_17.ID.MarshalText()
+	for _, idx := range []int{1, 0} {
+		bodyString = fmt.Sprintf(`{"id": "testID", "method": "friend_uploadFileChunk", "params": ["%v", "%v", %v, "%v"]}`, string(marshaledFriendID), string(marshaledUploadID), idx, base64.StdEncoding.EncodeToString(fileChunks[idx]))
+
+		uploadFile0_17_1 := &friend.BackendUploadFile{}
+		testCore(t0, bodyString, uploadFile0_17_1, t, isDebug)
+		if idx == 1 {
+			assert.Equal([]int{0}, uploadFile0_17_1.MissingChunks)
+		} else {
+			assert.Equal(0, len(uploadFile0_17_1.MissingChunks))
+		}
+	}
+
+	// 17.2. create-file-media
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "friend_createFileMedia", "params": ["%v", "%v"]}`, string(marshaledFriendID), string(marshaledUploadID))
+
+	media0_17_2 := &friend.BackendGetMedia{}
+	testCore(t0, bodyString, media0_17_2, t, isDebug)
+	assert.Equal(int64(len(fileBuf)), media0_17_2.Size)
+	assert.Equal(2, media0_17_2.NChunk)
+
+	time.Sleep(10 * time.Second)
+
+	// 17.3. download-file
+	marshaledMediaID, _ := media0_17_2.ID.MarshalText()
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "friend_getMedia", "params": ["%v", "%v"]}`, string(marshaledFriendID), string(marshaledMediaID))
+
+	media0_17_3 := &friend.BackendGetMedia{}
+	testCore(t0, bodyString, media0_17_3, t, isDebug)
+	assert.Equal(types.StatusAlive, media0_17_3.Status)
+
+	media1_17_3 := &friend.BackendGetMedia{}
+	testCore(t1, bodyString, media1_17_3, t, isDebug)
+	assert.Equal(types.StatusAlive, media1_17_3.Status)
+	assert.Equal([]byte("test.bin"), media1_17_3.Filename)
+	assert.Equal(media0_17_2.Size, media1_17_3.Size)
+
+	downloaded := make([][]byte, 0, 2)
+	for idx := 0; idx < media1_17_3.NChunk; idx++ {
+		bodyString = fmt.Sprintf(`{"id": "testID", "method": "friend_getMediaChunk", "params": ["%v", "%v", %v]}`, string(marshaledFriendID), string(marshaledMediaID), idx)
+
+		var chunk []byte
+		testCore(t1, bodyString, &chunk, t, isDebug)
+		downloaded = append(downloaded, chunk)
+	}
+	assert.Equal(true, bytes.Equal(fileBuf, bytes.Join(downloaded, nil)))
+
+	// 18. typing
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "friend_setTyping", "params": ["%v", true]}`, string(marshaledFriendID))
+
+	testCore(t0, bodyString, nil, t, isDebug)
+
+	time.Sleep(3 * time.Second)
+
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "friend_getPresence", "params": ["%v"]}`, string(marshaledFriendID))
+
+	presence1_18 := &friend.BackendGetPresence{}
+	testCore(t1, bodyString, presence1_18, t, isDebug)
+	assert.Equal(friend.PresenceStatusOnline, presence1_18.Status)
+	assert.Equal(true, presence1_18.IsTyping)
+	assert.Equal(me0_1.ID, presence1_18.UserID)
+
+	// 18.1 hide presence and typing
+	bodyString = `{"id": "testID", "method": "friend_setPresenceSetting", "params": [true, true]}`
+
+	setting0_18_1 := &friend.PresenceSetting{}
+	testCore(t0, bodyString, setting0_18_1, t, isDebug)
+	assert.Equal(true, setting0_18_1.IsHidePresence)
+	assert.Equal(true, setting0_18_1.IsHideTyping)
+
+	time.Sleep(3 * time.Second)
+
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "friend_getPresence", "params": ["%v"]}`, string(marshaledFriendID))
+
+	presence1_18_1 := &friend.BackendGetPresence{}
+	testCore(t1, bodyString, presence1_18_1, t, isDebug)
+	assert.Equal(friend.PresenceStatusOffline, presence1_18_1.Status)
+	assert.Equal(false, presence1_18_1.IsTyping)
 }
@@ -0,0 +1,264 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package e2e
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/group"
+	"github.com/ailabstw/go-pttai-core/me"
+	"github.com/ailabstw/go-pttai-core/service"
+	"github.com/stretchr/testify/assert"
+	baloo "gopkg.in/h2non/baloo.v3"
+)
+
+func TestGroupBasic(t *testing.T) {
+	startSignalServer()
+
+	// node 1
+	ctx1, cancel1 := context.WithTimeout(context.Background(), 240*time.Second)
+	err := runNode(ctx1, 2, 14781)
+	if err != nil {
+		panic(err)
+	}
+	defer cancel1()
+
+	time.Sleep(5 * time.Second)
+	// node 2
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 240*time.Second)
+	err = runNode(ctx2, 3, 14782)
+	if err != nil {
+		panic(err)
+	}
+	time.Sleep(5 * time.Second)
+	defer cancel2()
+	fmt.Println("node ready")
+
+	time.Sleep(5 * time.Second)
+
+	// start
+	TimeSleepDefault := 30 * time.Second
+	isDebug := true
+
+	var bodyString string
+	var marshaled []byte
+	assert := assert.New(t)
+
+	t0 := baloo.New("http://127.0.0.1:14781")
+	t1 := baloo.New("http://127.0.0.1:14782")
+
+	// 1. get
+	bodyString = `{"id": "testID", "method": "me_get", "params": []}`
+
+	me0_1 := &me.BackendMyInfo{}
+	testCore(t0, bodyString, me0_1, t, isDebug)
+	assert.Equal(types.StatusAlive, me0_1.Status)
+
+	me1_1 := &me.BackendMyInfo{}
+	testCore(t1, bodyString, me1_1, t, isDebug)
+	assert.Equal(types.StatusAlive, me1_1.Status)
+
+	// 2. create-group
+	bodyString = `{"id": "testID", "method": "group_createGroup", "params": ["dGVzdC1ncm91cA=="]}`
+
+	group0_2 := &group.BackendGetGroup{}
+	testCore(t0, bodyString, group0_2, t, isDebug)
+	assert.Equal(types.StatusAlive, group0_2.Status)
+	assert.Equal([]byte("test-group"), group0_2.Title)
+	assert.Equal(me0_1.ID, group0_2.CreatorID)
+
+	marshaledGroupID, _ := group0_2.ID.MarshalText()
+
+	// wait for the join-key
+	time.Sleep(5 * time.Second)
+
+	// 2.1. invalid title
+	bodyString = `{"id": "testID", "method": "group_createGroup", "params": [""]}`
+
+	_, err0_2_1 := testCore(t0, bodyString, &group.BackendGetGroup{}, t, isDebug)
+	assert.NotEqual(0, err0_2_1.Code)
+
+	// 3. show-url
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "group_showURL", "params": ["%v"]}`, string(marshaledGroupID))
+
+	dataShowURL0_3 := &service.BackendJoinURL{}
+	testCore(t0, bodyString, dataShowURL0_3, t, isDebug)
+	url0_3 := dataShowURL0_3.URL
+
+	// 4. join-group
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "group_joinGroup", "params": ["%v"]}`, base64.StdEncoding.EncodeToString([]byte(url0_3)))
+
+	dataJoinGroup1_4 := &service.BackendJoinRequest{}
+	testCore(t1, bodyString, dataJoinGroup1_4, t, isDebug)
+	assert.Equal(me0_1.ID, dataJoinGroup1_4.CreatorID)
+	assert.Equal(me0_1.NodeID, dataJoinGroup1_4.NodeID)
+
+	// wait 30
+	t.Logf("wait 30 seconds for hand-shaking")
+	time.Sleep(TimeSleepDefault)
+
+	// 5. get-group-list
+	bodyString = `{"id": "testID", "method": "group_getGroupList", "params": ["", 0]}`
+
+	dataGroupList1_5 := &struct {
+		Result []*group.BackendGetGroup `json:"result"`
+	}{}
+	testListCore(t1, bodyString, dataGroupList1_5, t, isDebug)
+	assert.Equal(1, len(dataGroupList1_5.Result))
+	group1_5 := dataGroupList1_5.Result[0]
+	assert.Equal(group0_2.ID, group1_5.ID)
+	assert.Equal(types.StatusAlive, group1_5.Status)
+	assert.Equal([]byte("test-group"), group1_5.Title)
+
+	// 6. member-list
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "group_getMemberList", "params": ["%v", "", 0, 2]}`, string(marshaledGroupID))
+
+	dataMemberList0_6 := &struct {
+		Result []*service.Member `json:"result"`
+	}{}
+	testListCore(t0, bodyString, dataMemberList0_6, t, isDebug)
+	assert.Equal(2, len(dataMemberList0_6.Result))
+
+	dataMemberList1_6 := &struct {
+		Result []*service.Member `json:"result"`
+	}{}
+	testListCore(t1, bodyString, dataMemberList1_6, t, isDebug)
+	assert.Equal(2, len(dataMemberList1_6.Result))
+	assert.Equal(dataMemberList0_6, dataMemberList1_6)
+
+	// 7. create-message
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "group_createMessage", "params": ["%v", ["dGVzdDE="], []]}`, string(marshaledGroupID))
+
+	message0_7 := &group.BackendCreateMessage{}
+	testCore(t0, bodyString, message0_7, t, isDebug)
+	assert.Equal(group0_2.ID, message0_7.GroupID)
+
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "group_createMessage", "params": ["%v", ["dGVzdDI="], []]}`, string(marshaledGroupID))
+
+	message1_7 := &group.BackendCreateMessage{}
+	testCore(t1, bodyString, message1_7, t, isDebug)
+	assert.Equal(group0_2.ID, message1_7.GroupID)
+
+	time.Sleep(10 * time.Second)
+
+	// 7.1. get-message-list
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "group_getMessageList", "params": ["%v", "", 0, 2]}`, string(marshaledGroupID))
+
+	dataMessageList0_7_1 := &struct {
+		Result []*group.BackendGetMessage `json:"result"`
+	}{}
+	testListCore(t0, bodyString, dataMessageList0_7_1, t, isDebug)
+	assert.Equal(2, len(dataMessageList0_7_1.Result))
+
+	dataMessageList1_7_1 := &struct {
+		Result []*group.BackendGetMessage `json:"result"`
+	}{}
+	testListCore(t1, bodyString, dataMessageList1_7_1, t, isDebug)
+	assert.Equal(2, len(dataMessageList1_7_1.Result))
+	assert.Equal(dataMessageList0_7_1, dataMessageList1_7_1)
+
+	// 7.2. get-message-block-list
+	marshaled, _ = message0_7.MessageID.MarshalText()
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "group_getMessageBlockList", "params": ["%v", "%v", "", 0, 0, 0]}`, string(marshaledGroupID), string(marshaled))
+
+	dataMessageBlockList1_7_2 := &struct {
+		Result []*group.BackendMessageBlock `json:"result"`
+	}{}
+	testListCore(t1, bodyString, dataMessageBlockList1_7_2, t, isDebug)
+	assert.Equal(1, len(dataMessageBlockList1_7_2.Result))
+	assert.Equal([][]byte{[]byte("test1")}, dataMessageBlockList1_7_2.Result[0].Buf)
+
+	marshaled, _ = message1_7.MessageID.MarshalText()
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "group_getMessageBlockList", "params": ["%v", "%v", "", 0, 0, 0]}`, string(marshaledGroupID), string(marshaled))
+
+	dataMessageBlockList0_7_2 := &struct {
+		Result []*group.BackendMessageBlock `json:"result"`
+	}{}
+	testListCore(t0, bodyString, dataMessageBlockList0_7_2, t, isDebug)
+	assert.Equal(1, len(dataMessageBlockList0_7_2.Result))
+	assert.Equal([][]byte{[]byte("test2")}, dataMessageBlockList0_7_2.Result[0].Buf)
+
+	// 8. group-oplog
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "group_getGroupOplogList", "params": ["%v", "", 0, 2]}`, string(marshaledGroupID))
+
+	dataGroupOplogList0_8 := &struct {
+		Result []*group.GroupOplog `json:"result"`
+	}{}
+	testListCore(t0, bodyString, dataGroupOplogList0_8, t, isDebug)
+	assert.Equal(3, len(dataGroupOplogList0_8.Result))
+
+	dataGroupOplogList1_8 := &struct {
+		Result []*group.GroupOplog `json:"result"`
+	}{}
+	testListCore(t1, bodyString, dataGroupOplogList1_8, t, isDebug)
+	assert.Equal(dataGroupOplogList0_8, dataGroupOplogList1_8)
+
+	// 9. delete-member
+	marshaled, _ = me0_1.ID.MarshalText()
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "group_deleteMember", "params": ["%v", "%v"]}`, string(marshaledGroupID), string(marshaled))
+
+	dataDeleteMember1_9 := &struct {
+		Result bool `json:"result"`
+	}{}
+	_, err1_9 := testCore(t1, bodyString, dataDeleteMember1_9, t, isDebug)
+	assert.NotEqual(0, err1_9.Code)
+
+	marshaled, _ = me1_1.ID.MarshalText()
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "group_deleteMember", "params": ["%v", "%v"]}`, string(marshaledGroupID), string(marshaled))
+
+	dataDeleteMember0_9 := &struct {
+		Result bool `json:"result"`
+	}{}
+	testListCore(t0, bodyString, dataDeleteMember0_9, t, isDebug)
+	assert.Equal(true, dataDeleteMember0_9.Result)
+
+	time.Sleep(10 * time.Second)
+
+	// 9.1. member-list
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "group_getMemberList", "params": ["%v", "", 0, 2]}`, string(marshaledGroupID))
+
+	dataMemberList0_9_1 := &struct {
+		Result []*service.Member `json:"result"`
+	}{}
+	testListCore(t0, bodyString, dataMemberList0_9_1, t, isDebug)
+	assert.Equal(2, len(dataMemberList0_9_1.Result))
+	for _, member := range dataMemberList0_9_1.Result {
+		if reflect.DeepEqual(member.ID, me1_1.ID) {
+			assert.Equal(types.StatusDeleted, member.Status)
+		} else {
+			assert.Equal(types.StatusAlive, member.Status)
+		}
+	}
+
+	// 9.2. the removed member is unable to create messages.
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "group_getGroup", "params": ["%v"]}`, string(marshaledGroupID))
+
+	group1_9_2 := &group.BackendGetGroup{}
+	testCore(t1, bodyString, group1_9_2, t, isDebug)
+	assert.NotEqual(types.StatusAlive, group1_9_2.Status)
+
+	bodyString = fmt.Sprintf(`{"id": "testID", "method": "group_createMessage", "params": ["%v", ["dGVzdDM="], []]}`, string(marshaledGroupID))
+
+	_, err1_9_2 := testCore(t1, bodyString, &group.BackendCreateMessage{}, t, isDebug)
+	assert.NotEqual(0, err1_9_2.Code)
+}
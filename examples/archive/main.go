@@ -0,0 +1,232 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of go-pttai.
+//
+// go-pttai is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-pttai is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-pttai. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/ailabstw/go-pttai-core/account"
+	"github.com/ailabstw/go-pttai-core/content"
+	"github.com/ailabstw/go-pttai-core/friend"
+	"github.com/ailabstw/go-pttai-core/group"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/me"
+	"github.com/ailabstw/go-pttai-core/node"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	"github.com/ailabstw/go-pttai-core/service"
+	colorable "github.com/mattn/go-colorable"
+)
+
+type Config struct {
+	Node    *node.Config
+	Me      *me.Config
+	Account *account.Config
+	Friend  *friend.Config
+	Group   *group.Config
+	Content *content.Config
+	Router  *service.Config
+}
+
+const usage = `usage:
+    archive export [datadir] [archive-file] (instance-name)
+    archive import [archive-file] [datadir] (instance-name)
+
+instance-name is the name of the node-binary (default: gptt)`
+
+func main() {
+	initLog()
+
+	if len(os.Args) != 4 && len(os.Args) != 5 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	name := "gptt"
+	if len(os.Args) == 5 {
+		name = os.Args[4]
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "export":
+		err = exportData(os.Args[2], os.Args[3], name)
+	case "import":
+		err = importData(os.Args[2], os.Args[3], name)
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Error("unable to do archive", "cmd", os.Args[1], "e", err)
+		os.Exit(1)
+	}
+}
+
+func initLog() {
+	output := colorable.NewColorableStderr()
+
+	ostream := log.StreamHandler(output, log.TerminalFormat(true))
+	glogger := log.NewGlogHandler(ostream)
+
+	glogger.Verbosity(log.Lvl(3))
+	log.Root().SetHandler(glogger)
+}
+
+func newConfig(dataDir string, name string) *Config {
+	cfg := &Config{
+		Node:    &node.DefaultConfig,
+		Me:      &me.DefaultConfig,
+		Account: &account.DefaultConfig,
+		Friend:  &friend.DefaultConfig,
+		Group:   &group.DefaultConfig,
+		Content: &content.DefaultConfig,
+		Router:  &service.DefaultConfig,
+	}
+	cfg.Node.Name = name
+	cfg.Node.DataDir = dataDir
+	cfg.Node.HTTPHost = ""
+	cfg.Node.IPCPath = ""
+	cfg.Node.P2P.NoDiscovery = true
+	cfg.Me.DataDir = filepath.Join(dataDir, "me")
+	cfg.Router.DataDir = filepath.Join(dataDir, "service")
+	cfg.Account.DataDir = filepath.Join(dataDir, "account")
+	cfg.Friend.DataDir = filepath.Join(dataDir, "friend")
+	cfg.Group.DataDir = filepath.Join(dataDir, "group")
+	cfg.Content.DataDir = filepath.Join(dataDir, "content")
+
+	return cfg
+}
+
+func exportData(dataDir string, filename string, name string) error {
+	cfg := newConfig(dataDir, name)
+
+	n, err := node.New(cfg.Node)
+	if err != nil {
+		return err
+	}
+
+	return n.ExportData(filename)
+}
+
+/*
+importData restores the archive to the fresh dataDir,
+and starts the node once to re-register the entities to the router.
+*/
+func importData(filename string, dataDir string, name string) error {
+	cfg := newConfig(dataDir, name)
+
+	n, err := node.New(cfg.Node)
+	if err != nil {
+		return err
+	}
+
+	_, err = n.ImportData(filename, nil)
+	if err != nil {
+		return err
+	}
+
+	err = cfg.Me.SetMyKey("", "", "", false)
+	if err != nil {
+		return err
+	}
+
+	if err := registerRouter(n, cfg); err != nil {
+		return err
+	}
+
+	if err := n.Start(); err != nil {
+		return err
+	}
+
+	router := n.Services()[reflect.TypeOf(&service.BaseRouter{})].(*service.BaseRouter)
+	log.Info("importData: entities registered", "entities", len(router.GetEntities()))
+
+	return n.Stop(false, false)
+}
+
+func registerRouter(n *node.Node, cfg *Config) error {
+	return n.Register(func(ctx *service.RouterContext) (service.NodeRouter, error) {
+		myNodeKey := cfg.Node.NodeKey()
+		myNodeID := discover.PubkeyID(&myNodeKey.PublicKey)
+
+		router, err := service.NewRouter(ctx, cfg.Router, &myNodeID, myNodeKey)
+		if err != nil {
+			return nil, err
+		}
+
+		accountBackend, err := account.NewBackend(ctx, cfg.Account, router)
+		if err != nil {
+			return nil, err
+		}
+		err = router.RegisterService(accountBackend)
+		if err != nil {
+			return nil, err
+		}
+
+		// friend
+		friendBackend, err := friend.NewBackend(ctx, cfg.Friend, cfg.Me.ID, router, accountBackend)
+		if err != nil {
+			return nil, err
+		}
+		err = router.RegisterService(friendBackend)
+		if err != nil {
+			return nil, err
+		}
+
+		// group
+		groupBackend, err := group.NewBackend(ctx, cfg.Group, router)
+		if err != nil {
+			return nil, err
+		}
+		err = router.RegisterService(groupBackend)
+		if err != nil {
+			return nil, err
+		}
+
+		// content
+		contentBackend, err := content.NewBackend(ctx, cfg.Content, router)
+		if err != nil {
+			return nil, err
+		}
+		err = router.RegisterService(contentBackend)
+		if err != nil {
+			return nil, err
+		}
+
+		// me
+		meBackend, err := me.NewBackend(ctx, cfg.Me, router, accountBackend, friendBackend)
+		if err != nil {
+			return nil, err
+		}
+
+		err = router.RegisterService(meBackend)
+		if err != nil {
+			return nil, err
+		}
+
+		err = router.Prestart()
+		if err != nil {
+			log.Error("unable to do Prestart", "e", err)
+			return nil, err
+		}
+
+		return router, nil
+	})
+}
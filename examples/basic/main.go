@@ -28,7 +28,9 @@ import (
 	"syscall"
 
 	"github.com/ailabstw/go-pttai-core/account"
+	"github.com/ailabstw/go-pttai-core/content"
 	"github.com/ailabstw/go-pttai-core/friend"
+	"github.com/ailabstw/go-pttai-core/group"
 	"github.com/ailabstw/go-pttai-core/log"
 	"github.com/ailabstw/go-pttai-core/me"
 	"github.com/ailabstw/go-pttai-core/node"
@@ -43,6 +45,8 @@ type Config struct {
 	Me      *me.Config
 	Account *account.Config
 	Friend  *friend.Config
+	Group   *group.Config
+	Content *content.Config
 	Router  *service.Config
 	Utils   *UtilsConfig
 }
@@ -88,6 +92,8 @@ func gptt(ctx *cli.Context) error {
 		Me:      &me.DefaultConfig,
 		Account: &account.DefaultConfig,
 		Friend:  &friend.DefaultConfig,
+		Group:   &group.DefaultConfig,
+		Content: &content.DefaultConfig,
 		Router:  &service.DefaultConfig,
 		Utils:   &UtilsConfig{},
 	}
@@ -101,9 +107,15 @@ func gptt(ctx *cli.Context) error {
 	cfg.Router.DataDir = filepath.Join(dataDir, "service")
 	cfg.Account.DataDir = filepath.Join(dataDir, "account")
 	cfg.Friend.DataDir = filepath.Join(dataDir, "friend")
+	cfg.Group.DataDir = filepath.Join(dataDir, "group")
+	cfg.Content.DataDir = filepath.Join(dataDir, "content")
 	cfg.Friend.MinSyncRandomSeconds = 5
 	cfg.Friend.MaxSyncRandomSeconds = 7
 
+	// encrypt the key-files if the passphrase is set.
+	cfg.Me.Passphrase = os.Getenv("PTT_PASSPHRASE")
+	cfg.Node.KeyPassphrase = cfg.Me.Passphrase
+
 	// uncomment the following line if you want to make two node communicate with eachother
 	/*
 		    signalServerURL, err := url.Parse("ws://127.0.0.1:9489/signal")
@@ -113,6 +125,17 @@ func gptt(ctx *cli.Context) error {
 			cfg.Node.P2P.SignalServerURL = *signalServerURL
 	*/
 
+	// or uncomment the following line to run the signal server in-process
+	// (the node connects to it if SignalServerURL is not set).
+	// cfg.Node.SignalHost = "127.0.0.1"
+
+	// uncomment the following line to expose the metrics at http://127.0.0.1:16779/metrics
+	// cfg.Node.MetricsHost = "127.0.0.1"
+
+	// uncomment the following line to require the api-tokens on the http rpc
+	// (issued with examples/rpc-token over the ipc).
+	// cfg.Node.RPCAuth = true
+
 	err = cfg.Me.SetMyKey("", "", "", false)
 	if err != nil {
 		panic(err)
@@ -176,6 +199,26 @@ func registerRouter(n *node.Node, cfg *Config) error {
 			return nil, err
 		}
 
+		// group
+		groupBackend, err := group.NewBackend(ctx, cfg.Group, router)
+		if err != nil {
+			return nil, err
+		}
+		err = router.RegisterService(groupBackend)
+		if err != nil {
+			return nil, err
+		}
+
+		// content
+		contentBackend, err := content.NewBackend(ctx, cfg.Content, router)
+		if err != nil {
+			return nil, err
+		}
+		err = router.RegisterService(contentBackend)
+		if err != nil {
+			return nil, err
+		}
+
 		// me
 		meBackend, err := me.NewBackend(ctx, cfg.Me, router, accountBackend, friendBackend)
 		if err != nil {
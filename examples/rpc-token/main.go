@@ -0,0 +1,83 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const usage = `usage: rpc-token [-ipc path] <command> [args]
+
+commands:
+	issue <name> <permission,...>   issue a token (ex: issue reader friend_get*,content_get*)
+	revoke <id>                     revoke the token
+	list                            list the tokens
+`
+
+func main() {
+	ipcPath := flag.String("ipc", "", "ipc path of the node (ex: ~/.pttai/gptt/gptt.ipc)")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if *ipcPath == "" || len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	client, err := rpc.Dial(*ipcPath)
+	if err != nil {
+		fatal(err)
+	}
+	defer client.Close()
+
+	var result interface{}
+	switch {
+	case args[0] == "issue" && len(args) == 3:
+		err = client.Call(&result, "admin_issueToken", args[1], strings.Split(args[2], ","))
+	case args[0] == "revoke" && len(args) == 2:
+		err = client.Call(&result, "admin_revokeToken", args[1])
+	case args[0] == "list" && len(args) == 1:
+		err = client.Call(&result, "admin_listTokens")
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fatal(err)
+	}
+
+	marshaled, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Println(string(marshaled))
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "rpc-token:", err)
+	os.Exit(1)
+}
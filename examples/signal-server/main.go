@@ -0,0 +1,62 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/p2p/signaling"
+	colorable "github.com/mattn/go-colorable"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:9489", "listening address of the signal server")
+	verbosity := flag.Int("verbosity", 3, "log verbosity (0-5)")
+	flag.Parse()
+
+	initLog(*verbosity)
+
+	server := signaling.NewServer()
+	err := server.Start(*addr)
+	if err != nil {
+		log.Error("unable to start signal server", "addr", *addr, "e", err)
+		os.Exit(1)
+	}
+
+	signalURL := server.URL()
+	log.Info("signal server is running", "url", signalURL.String())
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	<-sigc
+
+	server.Stop()
+}
+
+func initLog(verbosity int) {
+	output := colorable.NewColorableStderr()
+
+	ostream := log.StreamHandler(output, log.TerminalFormat(true))
+	glogger := log.NewGlogHandler(ostream)
+
+	glogger.Verbosity(log.Lvl(verbosity))
+	log.Root().SetHandler(glogger)
+}
@@ -18,9 +18,12 @@
 package friend
 
 import (
+	"context"
+
 	"github.com/ailabstw/go-pttai-core/common/types"
 	"github.com/ailabstw/go-pttai-core/pttdb"
 	pkgservice "github.com/ailabstw/go-pttai-core/service"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 type PrivateAPI struct {
@@ -35,14 +38,33 @@ func NewPrivateAPI(b *Backend) *PrivateAPI {
  * Op
  **********/
 
-func (api *PrivateAPI) CreateMessage(entityID string, message [][]byte, mediaIDs []string) (*BackendCreateMessage, error) {
+func (api *PrivateAPI) CreateMessage(entityID string, message [][]byte, mediaIDs []string, ttl *int64) (*BackendCreateMessage, error) {
+	var theTTL int64
+	if ttl != nil {
+		theTTL = *ttl
+	}
+
 	return api.b.CreateMessage(
 		[]byte(entityID),
 		message,
 		mediaIDs,
+		theTTL,
+	)
+}
+
+func (api *PrivateAPI) UpdateMessage(entityID string, messageID string, message [][]byte, mediaIDs []string) (*BackendGetMessage, error) {
+	return api.b.UpdateMessage(
+		[]byte(entityID),
+		[]byte(messageID),
+		message,
+		mediaIDs,
 	)
 }
 
+func (api *PrivateAPI) DeleteMessage(entityID string, messageID string) (bool, error) {
+	return api.b.DeleteMessage([]byte(entityID), []byte(messageID))
+}
+
 func (api *PrivateAPI) DeleteFriend(entityID string) (bool, error) {
 	return api.b.DeleteFriend([]byte(entityID))
 }
@@ -51,6 +73,58 @@ func (api *PrivateAPI) MarkFriendSeen(entityID string) (types.Timestamp, error)
 	return api.b.MarkFriendSeen([]byte(entityID))
 }
 
+/**********
+ * Presence
+ **********/
+
+func (api *PrivateAPI) SetTyping(entityID string, isTyping bool) (bool, error) {
+	return api.b.SetTyping([]byte(entityID), isTyping)
+}
+
+func (api *PrivateAPI) GetPresence(entityID string) (*BackendGetPresence, error) {
+	return api.b.GetPresence([]byte(entityID))
+}
+
+func (api *PrivateAPI) GetPresenceSetting() (*PresenceSetting, error) {
+	return api.b.GetPresenceSetting()
+}
+
+func (api *PrivateAPI) SetPresenceSetting(isHidePresence bool, isHideTyping bool) (*PresenceSetting, error) {
+	return api.b.SetPresenceSetting(isHidePresence, isHideTyping)
+}
+
+/**********
+ * File
+ **********/
+
+func (api *PrivateAPI) BeginUploadFile(entityID string, filename []byte, size int64) (*BackendUploadFile, error) {
+	return api.b.BeginUploadFile([]byte(entityID), filename, size)
+}
+
+func (api *PrivateAPI) UploadFileChunk(entityID string, uploadID string, idx int, buf []byte) (*BackendUploadFile, error) {
+	return api.b.UploadFileChunk([]byte(entityID), []byte(uploadID), idx, buf)
+}
+
+func (api *PrivateAPI) GetUploadFile(entityID string, uploadID string) (*BackendUploadFile, error) {
+	return api.b.GetUploadFile([]byte(entityID), []byte(uploadID))
+}
+
+func (api *PrivateAPI) CancelUploadFile(entityID string, uploadID string) (bool, error) {
+	return api.b.CancelUploadFile([]byte(entityID), []byte(uploadID))
+}
+
+func (api *PrivateAPI) CreateFileMedia(entityID string, uploadID string) (*BackendGetMedia, error) {
+	return api.b.CreateFileMedia([]byte(entityID), []byte(uploadID))
+}
+
+func (api *PrivateAPI) GetMedia(entityID string, mediaID string) (*BackendGetMedia, error) {
+	return api.b.GetMedia([]byte(entityID), []byte(mediaID))
+}
+
+func (api *PrivateAPI) GetMediaChunk(entityID string, mediaID string, idx int) ([]byte, error) {
+	return api.b.GetMediaChunk([]byte(entityID), []byte(mediaID), idx)
+}
+
 /**********
  * Get Friend
  **********/
@@ -83,6 +157,10 @@ func (api *PrivateAPI) GetFriendListSeen() (types.Timestamp, error) {
 	return api.b.GetFriendListSeen()
 }
 
+func (api *PrivateAPI) SearchFriends(query string) ([]*BackendGetFriend, error) {
+	return api.b.SearchFriends(query)
+}
+
 func (api *PrivateAPI) GetFriendListByMsgCreateTS(ts int64, nanoTS uint32, limit int, listOrder pttdb.ListOrder) ([]*BackendGetFriend, error) {
 	return api.b.GetFriendListByMsgCreateTS(
 		ts,
@@ -105,6 +183,14 @@ func (api *PrivateAPI) GetMessageList(entityID string, startingMessageID string,
 	)
 }
 
+func (api *PrivateAPI) SearchMessages(query string, entityID string, limit int) ([]*BackendGetMessage, error) {
+	return api.b.SearchMessages(query, []byte(entityID), limit)
+}
+
+func (api *PrivateAPI) GetMessageReceipts(entityID string, messageID string) ([]*MessageReceipt, error) {
+	return api.b.GetMessageReceipts([]byte(entityID), []byte(messageID))
+}
+
 func (api *PrivateAPI) GetMessageBlockList(entityID string, messageID string, dummy0 string, dummy1 pkgservice.ContentType, dummy2 uint32, limit uint32) ([]*BackendMessageBlock, error) {
 	return api.b.GetMessageBlockList([]byte(entityID), []byte(messageID), limit)
 }
@@ -268,3 +354,31 @@ func (api *PrivateAPI) ForceSync(entityID string) (bool, error) {
 func (api *PrivateAPI) ForceOpKey(entityID string) (bool, error) {
 	return api.b.ForceOpKey([]byte(entityID))
 }
+
+/**********
+ * Subscribe
+ **********/
+
+func (api *PrivateAPI) Messages(ctx context.Context, entityID string) (*rpc.Subscription, error) {
+	return api.b.SubscribeMessages(ctx, []byte(entityID))
+}
+
+func (api *PrivateAPI) Friends(ctx context.Context) (*rpc.Subscription, error) {
+	return api.b.SubscribeFriends(ctx)
+}
+
+func (api *PrivateAPI) EntityStatus(ctx context.Context, entityID string) (*rpc.Subscription, error) {
+	return api.b.SubscribeEntityStatus(ctx, []byte(entityID))
+}
+
+func (api *PrivateAPI) MerkleSync(ctx context.Context, entityID string) (*rpc.Subscription, error) {
+	return api.b.SubscribeMerkleSync(ctx, []byte(entityID))
+}
+
+func (api *PrivateAPI) Presence(ctx context.Context, entityID string) (*rpc.Subscription, error) {
+	return api.b.SubscribePresence(ctx, []byte(entityID))
+}
+
+func (api *PrivateAPI) Typing(ctx context.Context, entityID string) (*rpc.Subscription, error) {
+	return api.b.SubscribeTyping(ctx, []byte(entityID))
+}
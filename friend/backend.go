@@ -27,11 +27,13 @@ type Backend struct {
 	*pkgservice.BaseService
 
 	accountBackend *account.Backend
+
+	db *friendDB
 }
 
 func NewBackend(ctx *pkgservice.RouterContext, cfg *Config, id *types.PttID, router pkgservice.Router, accountBackend *account.Backend) (*Backend, error) {
 	// init friend
-	err := InitFriend(cfg.DataDir)
+	db, err := newFriendDB(cfg.DataDir, cfg.DBEngine)
 	if err != nil {
 		return nil, err
 	}
@@ -39,10 +41,12 @@ func NewBackend(ctx *pkgservice.RouterContext, cfg *Config, id *types.PttID, rou
 	// backend
 	backend := &Backend{
 		accountBackend: accountBackend,
+
+		db: db,
 	}
 
 	// spm
-	spm, err := NewServiceProtocolManager(router, backend)
+	spm, err := NewServiceProtocolManager(router, backend, db)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +70,7 @@ func (b *Backend) Start() error {
 func (b *Backend) Stop() error {
 	b.SPM().(*ServiceProtocolManager).Stop()
 
-	TeardownFriend()
+	b.db.Close()
 	return nil
 }
 
@@ -17,11 +17,15 @@
 package friend
 
 import (
+	"context"
+	"reflect"
+
 	"github.com/ailabstw/go-pttai-core/account"
 	"github.com/ailabstw/go-pttai-core/common/types"
 	"github.com/ailabstw/go-pttai-core/log"
 	"github.com/ailabstw/go-pttai-core/pttdb"
 	pkgservice "github.com/ailabstw/go-pttai-core/service"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 func (b *Backend) GetFriend(entityIDBytes []byte) (*BackendGetFriend, error) {
@@ -111,6 +115,33 @@ func (b *Backend) GetFriendList(startIDBytes []byte, limit int, listOrder pttdb.
 
 }
 
+func (b *Backend) SearchFriends(query string) ([]*BackendGetFriend, error) {
+
+	accountBackend := b.accountBackend
+	userIDs, err := accountBackend.SearchUserIDs(query, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	spm := b.SPM().(*ServiceProtocolManager)
+	backendFriendList := make([]*BackendGetFriend, 0, len(userIDs))
+	var userName *account.UserName
+	for _, userID := range userIDs {
+		f, err := spm.GetFriendByFriendID(userID)
+		if err != nil || f.Status != types.StatusAlive {
+			continue
+		}
+
+		userName, err = accountBackend.GetRawUserNameByID(userID)
+		if err != nil {
+			userName = account.NewEmptyUserName()
+		}
+		backendFriendList = append(backendFriendList, friendToBackendGetFriend(f, userName))
+	}
+
+	return backendFriendList, nil
+}
+
 func (b *Backend) GetFriendListByMsgCreateTS(theTS int64, nanoTS uint32, limit int, listOrder pttdb.ListOrder) ([]*BackendGetFriend, error) {
 
 	ts := types.Timestamp{Ts: theTS, NanoTs: nanoTS}
@@ -215,7 +246,7 @@ func (b *Backend) ForceSyncFriendMerkle(entityIDBytes []byte) (bool, error) {
 	return pm.ForceSyncFriendMerkle()
 }
 
-func (b *Backend) CreateMessage(entityIDBytes []byte, message [][]byte, mediaIDStrs []string) (*BackendCreateMessage, error) {
+func (b *Backend) CreateMessage(entityIDBytes []byte, message [][]byte, mediaIDStrs []string, ttl int64) (*BackendCreateMessage, error) {
 
 	thePM, err := b.EntityIDToPM(entityIDBytes)
 	if err != nil {
@@ -237,7 +268,7 @@ func (b *Backend) CreateMessage(entityIDBytes []byte, message [][]byte, mediaIDS
 		}
 	}
 
-	theMessage, err := pm.CreateMessage(message, mediaIDs)
+	theMessage, err := pm.CreateMessage(message, mediaIDs, ttl)
 	log.Debug("CreateMessage: after CreateMessage", "e", err)
 	if err != nil {
 		return nil, err
@@ -246,6 +277,54 @@ func (b *Backend) CreateMessage(entityIDBytes []byte, message [][]byte, mediaIDS
 	return messageToBackendCreateMessage(theMessage), nil
 }
 
+func (b *Backend) UpdateMessage(entityIDBytes []byte, messageIDBytes []byte, message [][]byte, mediaIDStrs []string) (*BackendGetMessage, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	messageID, err := types.UnmarshalTextPttID(messageIDBytes, false)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaIDs, err := mediaIDStrsToMediaIDs(mediaIDStrs)
+	if err != nil {
+		return nil, err
+	}
+
+	theMessage, err := pm.UpdateMessage(messageID, message, mediaIDs)
+	log.Debug("UpdateMessage: after UpdateMessage", "e", err)
+	if err != nil {
+		return nil, err
+	}
+
+	return messageToBackendGetMessage(theMessage, pm.GetMessageReceiptStatus(theMessage)), nil
+}
+
+func (b *Backend) DeleteMessage(entityIDBytes []byte, messageIDBytes []byte) (bool, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return false, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	messageID, err := types.UnmarshalTextPttID(messageIDBytes, false)
+	if err != nil {
+		return false, err
+	}
+
+	err = pm.DeleteMessage(messageID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 func (b *Backend) GetMessageList(entityIDBytes []byte, startIDBytes []byte, limit int, listOrder pttdb.ListOrder) ([]*BackendGetMessage, error) {
 
 	thePM, err := b.EntityIDToPM(entityIDBytes)
@@ -260,10 +339,40 @@ func (b *Backend) GetMessageList(entityIDBytes []byte, startIDBytes []byte, limi
 	}
 
 	messageList, err := pm.GetMessageList(startID, limit, listOrder, true)
+	if err != nil {
+		return nil, err
+	}
 
 	backendMessageList := make([]*BackendGetMessage, len(messageList))
 	for i, message := range messageList {
-		backendMessageList[i] = messageToBackendGetMessage(message)
+		backendMessageList[i] = messageToBackendGetMessage(message, pm.GetMessageReceiptStatus(message))
+	}
+
+	return backendMessageList, nil
+}
+
+func (b *Backend) SearchMessages(query string, entityIDBytes []byte, limit int) ([]*BackendGetMessage, error) {
+
+	entityID, err := types.UnmarshalTextPttID(entityIDBytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	spm := b.SPM().(*ServiceProtocolManager)
+	messageList, err := spm.SearchMessages(query, entityID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	backendMessageList := make([]*BackendGetMessage, 0, len(messageList))
+	for _, message := range messageList {
+		entity := spm.Entity(message.EntityID)
+		if entity == nil {
+			continue
+		}
+		pm := entity.PM().(*ProtocolManager)
+
+		backendMessageList = append(backendMessageList, messageToBackendGetMessage(message, pm.GetMessageReceiptStatus(message)))
 	}
 
 	return backendMessageList, nil
@@ -304,6 +413,25 @@ func (b *Backend) GetMessageBlockList(entityIDBytes []byte, msgIDBytes []byte, l
 	return backendMsgBlocks, nil
 }
 
+func (b *Backend) GetMessageReceipts(entityIDBytes []byte, msgIDBytes []byte) ([]*MessageReceipt, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	msgID, err := types.UnmarshalTextPttID(msgIDBytes, false)
+	if err != nil {
+		return nil, err
+	}
+	if msgID == nil {
+		return nil, types.ErrInvalidID
+	}
+
+	return pm.GetMessageReceipts(msgID)
+}
+
 func (b *Backend) MarkFriendSeen(entityIDBytes []byte) (types.Timestamp, error) {
 
 	thePM, err := b.EntityIDToPM(entityIDBytes)
@@ -312,7 +440,17 @@ func (b *Backend) MarkFriendSeen(entityIDBytes []byte) (types.Timestamp, error)
 	}
 	pm := thePM.(*ProtocolManager)
 
-	return pm.SaveLastSeen(types.ZeroTimestamp)
+	ts, err := pm.SaveLastSeen(types.ZeroTimestamp)
+	if err != nil {
+		return types.ZeroTimestamp, err
+	}
+
+	err = pm.MarkMessagesRead(ts)
+	if err != nil {
+		log.Warn("MarkFriendSeen: unable to mark messages read", "e", err)
+	}
+
+	return ts, nil
 }
 
 func (b *Backend) MarkFriendListSeen() (types.Timestamp, error) {
@@ -326,7 +464,7 @@ func (b *Backend) MarkFriendListSeen() (types.Timestamp, error) {
 		return types.ZeroTimestamp, err
 	}
 
-	err = dbMeta.Put(DBFriendListSeenPrefix, tsBytes)
+	err = b.db.meta.Put(DBFriendListSeenPrefix, tsBytes)
 	if err != nil {
 		return types.ZeroTimestamp, err
 	}
@@ -335,7 +473,7 @@ func (b *Backend) MarkFriendListSeen() (types.Timestamp, error) {
 }
 
 func (b *Backend) GetFriendListSeen() (types.Timestamp, error) {
-	tsBytes, err := dbMeta.Get(DBFriendListSeenPrefix)
+	tsBytes, err := b.db.meta.Get(DBFriendListSeenPrefix)
 	if err != nil {
 		return types.ZeroTimestamp, nil
 	}
@@ -347,3 +485,271 @@ func (b *Backend) GetFriendListSeen() (types.Timestamp, error) {
 
 	return ts, nil
 }
+
+/**********
+ * Presence
+ **********/
+
+func (b *Backend) SetTyping(entityIDBytes []byte, isTyping bool) (bool, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return false, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	err = pm.SendTyping(isTyping)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (b *Backend) GetPresence(entityIDBytes []byte) (*BackendGetPresence, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+	f := pm.Entity().(*Friend)
+
+	status, isTyping := pm.GetPresence()
+
+	return &BackendGetPresence{
+		FriendID: f.ID,
+		UserID:   f.FriendID,
+		Status:   status,
+		IsTyping: isTyping,
+	}, nil
+}
+
+func (b *Backend) GetPresenceSetting() (*PresenceSetting, error) {
+	return b.SPM().(*ServiceProtocolManager).GetPresenceSetting(), nil
+}
+
+func (b *Backend) SetPresenceSetting(isHidePresence bool, isHideTyping bool) (*PresenceSetting, error) {
+	setting := &PresenceSetting{
+		IsHidePresence: isHidePresence,
+		IsHideTyping:   isHideTyping,
+	}
+
+	err := b.SPM().(*ServiceProtocolManager).SetPresenceSetting(setting)
+	if err != nil {
+		return nil, err
+	}
+
+	return setting, nil
+}
+
+func mediaIDStrsToMediaIDs(mediaIDStrs []string) ([]*types.PttID, error) {
+	if len(mediaIDStrs) == 0 {
+		return nil, nil
+	}
+
+	mediaIDs := make([]*types.PttID, len(mediaIDStrs))
+	for i, mediaIDStr := range mediaIDStrs {
+		eachMediaID, err := types.UnmarshalTextPttID([]byte(mediaIDStr), false)
+		if err != nil {
+			return nil, err
+		}
+		mediaIDs[i] = eachMediaID
+	}
+
+	return mediaIDs, nil
+}
+
+/**********
+ * File
+ **********/
+
+func (b *Backend) BeginUploadFile(entityIDBytes []byte, filename []byte, size int64) (*BackendUploadFile, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	uploadFile, err := pm.BeginUploadFile(filename, size)
+	if err != nil {
+		return nil, err
+	}
+
+	return uploadFileToBackendUploadFile(uploadFile), nil
+}
+
+func (b *Backend) UploadFileChunk(entityIDBytes []byte, uploadIDBytes []byte, idx int, buf []byte) (*BackendUploadFile, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	uploadID, err := types.UnmarshalTextPttID(uploadIDBytes, false)
+	if err != nil {
+		return nil, err
+	}
+
+	uploadFile, err := pm.UploadFileChunk(uploadID, idx, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return uploadFileToBackendUploadFile(uploadFile), nil
+}
+
+func (b *Backend) GetUploadFile(entityIDBytes []byte, uploadIDBytes []byte) (*BackendUploadFile, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	uploadID, err := types.UnmarshalTextPttID(uploadIDBytes, false)
+	if err != nil {
+		return nil, err
+	}
+
+	uploadFile, err := pm.GetUploadFile(uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	return uploadFileToBackendUploadFile(uploadFile), nil
+}
+
+func (b *Backend) CancelUploadFile(entityIDBytes []byte, uploadIDBytes []byte) (bool, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return false, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	uploadID, err := types.UnmarshalTextPttID(uploadIDBytes, false)
+	if err != nil {
+		return false, err
+	}
+
+	err = pm.CancelUploadFile(uploadID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (b *Backend) CreateFileMedia(entityIDBytes []byte, uploadIDBytes []byte) (*BackendGetMedia, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	uploadID, err := types.UnmarshalTextPttID(uploadIDBytes, false)
+	if err != nil {
+		return nil, err
+	}
+
+	media, err := pm.CreateFileMedia(uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	return mediaToBackendGetMedia(media), nil
+}
+
+func (b *Backend) GetMedia(entityIDBytes []byte, mediaIDBytes []byte) (*BackendGetMedia, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	mediaID, err := types.UnmarshalTextPttID(mediaIDBytes, false)
+	if err != nil {
+		return nil, err
+	}
+
+	media, err := pm.GetMedia(mediaID)
+	if err != nil {
+		return nil, err
+	}
+
+	return mediaToBackendGetMedia(media), nil
+}
+
+func (b *Backend) GetMediaChunk(entityIDBytes []byte, mediaIDBytes []byte, idx int) ([]byte, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	mediaID, err := types.UnmarshalTextPttID(mediaIDBytes, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return pm.GetMediaChunk(mediaID, idx)
+}
+
+/**********
+ * Subscribe
+ **********/
+
+/*
+SubscribeMessages subscribes the created / updated / deleted messages of the friend (all the friends if entityID is empty).
+*/
+func (b *Backend) SubscribeMessages(ctx context.Context, entityIDBytes []byte) (*rpc.Subscription, error) {
+	entityID, err := types.UnmarshalTextPttID(entityIDBytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return pkgservice.SubscribeEvent(ctx, b.SPM().EventMux(), &MessageEvent{}, func(ev interface{}) bool {
+		return entityID == nil || reflect.DeepEqual(ev.(*MessageEvent).FriendID, entityID)
+	})
+}
+
+/*
+SubscribeFriends subscribes the new friends.
+*/
+func (b *Backend) SubscribeFriends(ctx context.Context) (*rpc.Subscription, error) {
+	return pkgservice.SubscribeEvent(ctx, b.SPM().EventMux(), &FriendEvent{}, nil)
+}
+
+/*
+SubscribePresence subscribes the presence of the friend (all the friends if entityID is empty).
+*/
+func (b *Backend) SubscribePresence(ctx context.Context, entityIDBytes []byte) (*rpc.Subscription, error) {
+	entityID, err := types.UnmarshalTextPttID(entityIDBytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return pkgservice.SubscribeEvent(ctx, b.SPM().EventMux(), &PresenceEvent{}, func(ev interface{}) bool {
+		return entityID == nil || reflect.DeepEqual(ev.(*PresenceEvent).FriendID, entityID)
+	})
+}
+
+/*
+SubscribeTyping subscribes the typing of the friend (all the friends if entityID is empty).
+*/
+func (b *Backend) SubscribeTyping(ctx context.Context, entityIDBytes []byte) (*rpc.Subscription, error) {
+	entityID, err := types.UnmarshalTextPttID(entityIDBytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return pkgservice.SubscribeEvent(ctx, b.SPM().EventMux(), &TypingEvent{}, func(ev interface{}) bool {
+		return entityID == nil || reflect.DeepEqual(ev.(*TypingEvent).FriendID, entityID)
+	})
+}
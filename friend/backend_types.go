@@ -84,9 +84,13 @@ type BackendGetMessage struct {
 	BlockID   *types.PttID    //`json:"cID"`
 	NBlock    int             //`json:"N"`
 	Status    types.Status    `json:"S"`
+
+	ReceiptStatus MessageReceiptStatus `json:"RS"`
+
+	ExpireTS *types.Timestamp `json:"ET,omitempty"`
 }
 
-func messageToBackendGetMessage(m *Message) *BackendGetMessage {
+func messageToBackendGetMessage(m *Message, receiptStatus MessageReceiptStatus) *BackendGetMessage {
 
 	return &BackendGetMessage{
 		ID:        m.ID,
@@ -97,6 +101,10 @@ func messageToBackendGetMessage(m *Message) *BackendGetMessage {
 		BlockID:   m.BlockInfo.ID,
 		NBlock:    m.BlockInfo.NBlock,
 		Status:    m.Status,
+
+		ReceiptStatus: receiptStatus,
+
+		ExpireTS: m.ExpireTS,
 	}
 }
 
@@ -138,3 +146,61 @@ func contentBlockToBackendMessageBlock(msg *Message, blockInfoID *types.PttID, c
 		Buf: contentBlock.Buf,
 	}
 }
+
+type BackendUploadFile struct {
+	ID            *types.PttID
+	FriendID      *types.PttID `json:"FID"`
+	Filename      []byte       `json:"f"`
+	Size          int64        `json:"s"`
+	NChunk        int          `json:"NC"`
+	MissingChunks []int        `json:"M"`
+}
+
+func uploadFileToBackendUploadFile(u *pkgservice.UploadFile) *BackendUploadFile {
+	return &BackendUploadFile{
+		ID:            u.ID,
+		FriendID:      u.EntityID,
+		Filename:      u.Filename,
+		Size:          u.Size,
+		NChunk:        len(u.Chunks),
+		MissingChunks: u.MissingChunks(),
+	}
+}
+
+type BackendGetMedia struct {
+	ID        *types.PttID
+	CreateTS  types.Timestamp `json:"CT"`
+	CreatorID *types.PttID    `json:"CID"`
+	FriendID  *types.PttID    `json:"FID"`
+	Status    types.Status    `json:"S"`
+
+	Filename []byte `json:"f"`
+	Size     int64  `json:"s"`
+	NChunk   int    `json:"NC"`
+}
+
+func mediaToBackendGetMedia(m *pkgservice.Media) *BackendGetMedia {
+	backendMedia := &BackendGetMedia{
+		ID:        m.ID,
+		CreateTS:  m.CreateTS,
+		CreatorID: m.CreatorID,
+		FriendID:  m.EntityID,
+		Status:    m.Status,
+	}
+
+	fileData := m.GetFileData()
+	if fileData != nil {
+		backendMedia.Filename = fileData.Filename
+		backendMedia.Size = fileData.Size
+		backendMedia.NChunk = len(fileData.Chunks)
+	}
+
+	return backendMedia
+}
+
+type BackendGetPresence struct {
+	FriendID *types.PttID   `json:"FID"`
+	UserID   *types.PttID   `json:"UID"`
+	Status   PresenceStatus `json:"S"`
+	IsTyping bool           `json:"IT"`
+}
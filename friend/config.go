@@ -16,9 +16,14 @@
 
 package friend
 
+import "github.com/ailabstw/go-pttai-core/pttdb"
+
 type Config struct {
 	DataDir string
 
+	// DBEngine is the storage-engine of the dbs, pttdb.DefaultEngine if empty.
+	DBEngine pttdb.Engine
+
 	MaxSyncRandomSeconds int
 	MinSyncRandomSeconds int
 }
@@ -20,4 +20,13 @@ import "errors"
 
 var (
 	ErrInvalidFriend = errors.New("invalid friend")
+	ErrInvalidTTL    = errors.New("invalid ttl")
+
+	ErrInvalidPresence = errors.New("invalid presence")
+
+	ErrInvalidRatchet = errors.New("invalid ratchet")
+	ErrNoMessageKey   = errors.New("no message key")
+	ErrInvalidPrekey  = errors.New("invalid prekey")
+
+	ErrNoFriendDevices = errors.New("no friend devices")
 )
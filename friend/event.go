@@ -0,0 +1,129 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package friend
+
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+/*
+MessageEvent is posted when the message is created / updated / deleted.
+*/
+type MessageEvent struct {
+	FriendID  *types.PttID    `json:"FID"`
+	MessageID *types.PttID    `json:"ID"`
+	CreatorID *types.PttID    `json:"CID"`
+	Status    types.Status    `json:"S"`
+	UpdateTS  types.Timestamp `json:"UT"`
+}
+
+/*
+FriendEvent is posted when the friend is ready to chat.
+*/
+type FriendEvent struct {
+	FriendID *types.PttID `json:"FID"`
+	UserID   *types.PttID `json:"UID"`
+	Status   types.Status `json:"S"`
+}
+
+/*
+PresenceEvent is posted when the friend becomes online / offline.
+*/
+type PresenceEvent struct {
+	FriendID *types.PttID    `json:"FID"`
+	UserID   *types.PttID    `json:"UID"`
+	Status   PresenceStatus  `json:"S"`
+	UpdateTS types.Timestamp `json:"UT"`
+}
+
+/*
+TypingEvent is posted when the friend starts / stops typing.
+*/
+type TypingEvent struct {
+	FriendID *types.PttID    `json:"FID"`
+	UserID   *types.PttID    `json:"UID"`
+	IsTyping bool            `json:"IT"`
+	UpdateTS types.Timestamp `json:"UT"`
+}
+
+/*
+postMessageEvent posts the MessageEvent. The events of the messages from the muted users are suppressed.
+*/
+func (pm *ProtocolManager) postMessageEvent(obj pkgservice.Object, status types.Status, ts types.Timestamp) {
+	if pm.isMutedUser(obj.GetCreatorID()) {
+		return
+	}
+
+	pm.PostEvent(&MessageEvent{
+		FriendID:  pm.Entity().GetID(),
+		MessageID: obj.GetID(),
+		CreatorID: obj.GetCreatorID(),
+		Status:    status,
+		UpdateTS:  ts,
+	})
+}
+
+func (pm *ProtocolManager) postFriendEvent() {
+	f := pm.Entity().(*Friend)
+
+	pm.PostEvent(&FriendEvent{
+		FriendID: f.ID,
+		UserID:   f.FriendID,
+		Status:   f.Status,
+	})
+}
+
+/*
+postPresenceEvent posts the PresenceEvent. The events of the muted users are suppressed.
+*/
+func (pm *ProtocolManager) postPresenceEvent(status PresenceStatus, ts types.Timestamp) {
+	f := pm.Entity().(*Friend)
+	if pm.isMutedUser(f.FriendID) {
+		return
+	}
+
+	pm.PostEvent(&PresenceEvent{
+		FriendID: f.ID,
+		UserID:   f.FriendID,
+		Status:   status,
+		UpdateTS: ts,
+	})
+}
+
+func (pm *ProtocolManager) postTypingEvent(isTyping bool, ts types.Timestamp) {
+	f := pm.Entity().(*Friend)
+	if pm.isMutedUser(f.FriendID) {
+		return
+	}
+
+	pm.PostEvent(&TypingEvent{
+		FriendID: f.ID,
+		UserID:   f.FriendID,
+		IsTyping: isTyping,
+		UpdateTS: ts,
+	})
+}
+
+func (pm *ProtocolManager) isMutedUser(userID *types.PttID) bool {
+	myEntity := pm.Router().GetMyEntity()
+	if myEntity == nil || userID == nil {
+		return false
+	}
+
+	return myEntity.IsMutedUser(userID)
+}
@@ -47,10 +47,12 @@ type Friend struct {
 	// get from other dbs
 	LastSeen        types.Timestamp `json:"-"`
 	MessageCreateTS types.Timestamp `json:"-"`
+
+	db *friendDB
 }
 
-func NewEmptyFriend() *Friend {
-	return &Friend{BaseEntity: &pkgservice.BaseEntity{SyncInfo: &pkgservice.BaseSyncInfo{}}}
+func NewEmptyFriend(db *friendDB) *Friend {
+	return &Friend{BaseEntity: &pkgservice.BaseEntity{SyncInfo: &pkgservice.BaseSyncInfo{}}, db: db}
 }
 
 func NewFriend(friendID *types.PttID, router pkgservice.Router, service pkgservice.Service, spm pkgservice.ServiceProtocolManager, dbLock *types.LockMap) (*Friend, error) {
@@ -66,7 +68,9 @@ func NewFriend(friendID *types.PttID, router pkgservice.Router, service pkgservi
 		return nil, err
 	}
 
-	e := pkgservice.NewBaseEntity(id, ts, myID, types.StatusInit, dbFriend, dbLock)
+	db := spm.(*ServiceProtocolManager).db
+
+	e := pkgservice.NewBaseEntity(id, ts, myID, types.StatusInit, db.friend, dbLock)
 
 	var friend0ID *types.PttID
 	var friend1ID *types.PttID
@@ -85,6 +89,8 @@ func NewFriend(friendID *types.PttID, router pkgservice.Router, service pkgservi
 		Friend0ID: friend0ID,
 		Friend1ID: friend1ID,
 		FriendID:  friendID,
+
+		db: db,
 	}
 
 	err = f.Init(router, service, spm)
@@ -105,9 +111,14 @@ func (f *Friend) SetUpdateTS(ts types.Timestamp) {
 	f.UpdateTS = ts
 }
 
+func (f *Friend) GetFriendID() *types.PttID {
+	return f.FriendID
+}
+
 func (f *Friend) Init(router pkgservice.Router, service pkgservice.Service, spm pkgservice.ServiceProtocolManager) error {
 
-	f.SetDB(dbFriend, spm.GetDBLock())
+	f.db = spm.(*ServiceProtocolManager).db
+	f.SetDB(f.db.friend, spm.GetDBLock())
 
 	// friend-id
 
@@ -214,7 +225,7 @@ func (f *Friend) Save(isLocked bool) error {
 		},
 	}
 
-	_, err = dbFriend.ForcePutAll(idxKey, idx, kvs)
+	_, err = f.db.friend.ForcePutAll(idxKey, idx, kvs)
 	if err != nil {
 		return err
 	}
@@ -230,7 +241,7 @@ func (f *Friend) GetByFriendID(friendID *types.PttID) error {
 		return err
 	}
 
-	val, err := dbFriend.GetBy2ndIdxKey(idx2Key)
+	val, err := f.db.friend.GetBy2ndIdxKey(idx2Key)
 	log.Debug("GetByFriendID: after GetBy2ndIdxKey", "e", err)
 	if err != nil {
 		return err
@@ -259,7 +270,7 @@ func (f *Friend) SaveLastSeen(ts types.Timestamp) error {
 		return err
 	}
 
-	_, err = dbFriendCore.TryPut(key, marshaled, ts)
+	_, err = f.db.friendCore.TryPut(key, marshaled, ts)
 	if err != nil && err != pttdb.ErrInvalidUpdateTS {
 		return err
 	}
@@ -272,7 +283,7 @@ func (f *Friend) LoadLastSeen() (types.Timestamp, error) {
 	if err != nil {
 		return types.ZeroTimestamp, err
 	}
-	data, err := dbFriendCore.Get(key)
+	data, err := f.db.friendCore.Get(key)
 	if err != nil {
 		if err == leveldb.ErrNotFound {
 			err = nil
@@ -308,7 +319,7 @@ func (f *Friend) SaveMessageCreateTS(ts types.Timestamp) error {
 		return err
 	}
 
-	_, err = dbFriendCore.TryPut(key, marshaled, ts)
+	_, err = f.db.friendCore.TryPut(key, marshaled, ts)
 	if err != nil && err != pttdb.ErrInvalidUpdateTS {
 		return err
 	}
@@ -331,7 +342,7 @@ func (f *Friend) LoadMessageCreateTS() (types.Timestamp, error) {
 	if err != nil {
 		return types.ZeroTimestamp, err
 	}
-	data, err := dbFriendCore.Get(key)
+	data, err := f.db.friendCore.Get(key)
 	if err != nil {
 		if err == leveldb.ErrNotFound {
 			err = nil
@@ -380,7 +391,7 @@ func (f *Friend) SaveMessageCreateTS2(ts types.Timestamp) error {
 		&pttdb.KeyVal{K: key, V: marshaled},
 	}
 
-	_, err = dbFriend.TryPutAll(idxKey, idx, kvs, true, false)
+	_, err = f.db.friend.TryPutAll(idxKey, idx, kvs, true, false)
 	if err != nil && err != pttdb.ErrInvalidUpdateTS {
 		return err
 	}
@@ -405,7 +416,7 @@ func (f *Friend) LoadMessageCreateTS2() (types.Timestamp, error) {
 	if err != nil {
 		return types.ZeroTimestamp, err
 	}
-	val, err := dbFriend.GetByIdxKey(idxKey, 0)
+	val, err := f.db.friend.GetByIdxKey(idxKey, 0)
 	if err != nil {
 		return types.ZeroTimestamp, err
 	}
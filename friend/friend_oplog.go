@@ -19,6 +19,7 @@ package friend
 import (
 	"github.com/ailabstw/go-pttai-core/common/types"
 	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/pttdb"
 	pkgservice "github.com/ailabstw/go-pttai-core/service"
 )
 
@@ -30,9 +31,9 @@ func (o *FriendOplog) GetBaseOplog() *pkgservice.BaseOplog {
 	return o.BaseOplog
 }
 
-func NewFriendOplog(objID *types.PttID, ts types.Timestamp, doerID *types.PttID, op pkgservice.OpType, opData pkgservice.OpData, userID *types.PttID, dbLock *types.LockMap) (*FriendOplog, error) {
+func NewFriendOplog(objID *types.PttID, ts types.Timestamp, doerID *types.PttID, op pkgservice.OpType, opData pkgservice.OpData, db pttdb.IndexBatch, userID *types.PttID, dbLock *types.LockMap) (*FriendOplog, error) {
 
-	oplog, err := pkgservice.NewOplog(objID, ts, doerID, op, opData, dbFriend, userID, DBFriendOplogPrefix, DBFriendIdxOplogPrefix, DBFriendMerkleOplogPrefix, dbLock)
+	oplog, err := pkgservice.NewOplog(objID, ts, doerID, op, opData, db, userID, DBFriendOplogPrefix, DBFriendIdxOplogPrefix, DBFriendMerkleOplogPrefix, dbLock)
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +62,7 @@ func (pm *ProtocolManager) NewFriendOplogWithTS(objID *types.PttID, ts types.Tim
 	myID := pm.Router().GetMyEntity().GetID()
 	entityID := pm.Entity().GetID()
 
-	oplog, err := NewFriendOplog(objID, ts, myID, op, opData, entityID, pm.dbFriendLock)
+	oplog, err := NewFriendOplog(objID, ts, myID, op, opData, pm.db.friend, entityID, pm.dbFriendLock)
 	if err != nil {
 		return nil, err
 	}
@@ -74,12 +75,12 @@ func (spm *ServiceProtocolManager) NewFriendOplogWithTS(entityID *types.PttID, t
 	myID := spm.Router().GetMyEntity().GetID()
 	log.Debug("spm.NewFriendOplogWithTS: start", "ts", ts)
 
-	return NewFriendOplog(entityID, ts, myID, op, opData, entityID, spm.GetDBLogLock())
+	return NewFriendOplog(entityID, ts, myID, op, opData, spm.db.friend, entityID, spm.GetDBLogLock())
 }
 
 func (pm *ProtocolManager) SetFriendDB(oplog *pkgservice.BaseOplog) {
 	userID := pm.Entity().GetID()
-	oplog.SetDB(dbFriend, userID, DBFriendOplogPrefix, DBFriendIdxOplogPrefix, DBFriendMerkleOplogPrefix, pm.dbFriendLock)
+	oplog.SetDB(pm.db.friend, userID, DBFriendOplogPrefix, DBFriendIdxOplogPrefix, DBFriendMerkleOplogPrefix, pm.dbFriendLock)
 }
 
 func OplogsToFriendOplogs(logs []*pkgservice.BaseOplog) []*FriendOplog {
@@ -32,6 +32,9 @@ const (
 
 	FriendOpTypeCreateMedia
 
+	FriendOpTypeUpdateMessage
+	FriendOpTypeDeleteMessage
+
 	NFriendOpType
 )
 
@@ -47,7 +50,24 @@ type FriendOpCreateMessage struct {
 	Hashs       [][][]byte   `json:"H"`
 	NBlock      int          `json:"NB"`
 
+	TTL int64 `json:"TTL,omitempty"`
+
 	MediaIDs []*types.PttID `json:"ms,omitempty"`
+
+	Ratchet *RatchetHeader `json:"r,omitempty"`
+}
+
+type FriendOpUpdateMessage struct {
+	BlockInfoID *types.PttID `json:"BID"`
+	Hashs       [][][]byte   `json:"H"`
+	NBlock      int          `json:"NB"`
+
+	MediaIDs []*types.PttID `json:"ms,omitempty"`
+
+	Ratchet *RatchetHeader `json:"r,omitempty"`
+}
+
+type FriendOpDeleteMessage struct {
 }
 
 type FriendOpCreateMedia struct {
@@ -55,3 +75,46 @@ type FriendOpCreateMedia struct {
 	Hashs       [][][]byte   `json:"H"`
 	NBlock      int          `json:"NB"`
 }
+
+func (o *FriendOpCreateFriend) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.PttID(o.FriendID)
+}
+
+func (o *FriendOpDeleteFriend) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+}
+
+func (o *FriendOpCreateMessage) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.PttID(o.BlockInfoID)
+	enc.Hashs(o.Hashs)
+	enc.Int64(int64(o.NBlock))
+	enc.Int64(o.TTL)
+	enc.PttIDs(o.MediaIDs)
+	enc.Data(o.Ratchet)
+}
+
+func (o *FriendOpUpdateMessage) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.PttID(o.BlockInfoID)
+	enc.Hashs(o.Hashs)
+	enc.Int64(int64(o.NBlock))
+	enc.PttIDs(o.MediaIDs)
+	enc.Data(o.Ratchet)
+}
+
+func (o *FriendOpDeleteMessage) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+}
+
+func init() {
+	pkgservice.RegisterOpData(DBFriendOplogPrefix, map[pkgservice.OpType]func() pkgservice.CanonicalOpData{
+		FriendOpTypeCreateFriend:  func() pkgservice.CanonicalOpData { return &FriendOpCreateFriend{} },
+		FriendOpTypeDeleteFriend:  func() pkgservice.CanonicalOpData { return &FriendOpDeleteFriend{} },
+		FriendOpTypeCreateMessage: func() pkgservice.CanonicalOpData { return &FriendOpCreateMessage{} },
+		FriendOpTypeCreateMedia:   func() pkgservice.CanonicalOpData { return &pkgservice.OpCreateMedia{} },
+		FriendOpTypeUpdateMessage: func() pkgservice.CanonicalOpData { return &FriendOpUpdateMessage{} },
+		FriendOpTypeDeleteMessage: func() pkgservice.CanonicalOpData { return &FriendOpDeleteMessage{} },
+	})
+}
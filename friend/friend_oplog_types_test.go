@@ -0,0 +1,129 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package friend
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFriendOpData_EncodeCanonical(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	id := &types.PttID{1, 2}
+	ts := types.Timestamp{Ts: 1, NanoTs: 2}
+	nodeID := &discover.NodeID{3}
+	ratchet := &RatchetHeader{
+		From: nodeID,
+		Keys: []*RatchetKey{{E: []byte{4}, Key: []byte{5}, N: 6, P: ts, To: nodeID}},
+	}
+
+	// prepare test-cases
+	tests := []struct {
+		name string
+		op   pkgservice.OpType
+		data pkgservice.CanonicalOpData
+		want string
+	}{
+		{name: "CreateFriend", op: FriendOpTypeCreateFriend, data: &FriendOpCreateFriend{FriendID: id}, want: "01012801020000000000000000000000000000000000000000000000000000000000000000000000000000"},
+		{name: "DeleteFriend", op: FriendOpTypeDeleteFriend, data: &FriendOpDeleteFriend{}, want: "0101"},
+		{name: "CreateMessage", op: FriendOpTypeCreateMessage, data: &FriendOpCreateMessage{BlockInfoID: id, Hashs: [][][]byte{{{7}}}, NBlock: 1, TTL: 60, MediaIDs: []*types.PttID{id}, Ratchet: ratchet}, want: "010128010200000000000000000000000000000000000000000000000000000000000000000000000000000101010702780128010200000000000000000000000000000000000000000000000000000000000000000000000000000101400300000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001010101040105060000000000000001000000024003000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"},
+		{name: "CreateMessage-without-ratchet", op: FriendOpTypeCreateMessage, data: &FriendOpCreateMessage{BlockInfoID: id, Hashs: [][][]byte{{{7}}}, NBlock: 1}, want: "010128010200000000000000000000000000000000000000000000000000000000000000000000000000000101010702000000"},
+		{name: "UpdateMessage", op: FriendOpTypeUpdateMessage, data: &FriendOpUpdateMessage{BlockInfoID: id, Hashs: [][][]byte{{{7}}}, NBlock: 1, MediaIDs: []*types.PttID{id}, Ratchet: ratchet}, want: "0101280102000000000000000000000000000000000000000000000000000000000000000000000000000001010107020128010200000000000000000000000000000000000000000000000000000000000000000000000000000101400300000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001010101040105060000000000000001000000024003000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"},
+		{name: "DeleteMessage", op: FriendOpTypeDeleteMessage, data: &FriendOpDeleteMessage{}, want: "0101"},
+		{name: "CreateMedia", op: FriendOpTypeCreateMedia, data: &pkgservice.OpCreateMedia{BlockInfoID: id, Hashs: [][][]byte{{{7}}}, NBlock: 1, MediaType: pkgservice.MediaTypePNG}, want: "0101280102000000000000000000000000000000000000000000000000000000000000000000000000000000010101070202"},
+	}
+
+	// run test
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pkgservice.MarshalCanonicalData(tt.data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, hex.EncodeToString(got))
+
+			// the same for the op-data unmarshaled from the peers.
+			o, err := pkgservice.NewOplog(id, ts, id, tt.op, tt.data, nil, id, DBFriendOplogPrefix, nil, nil, nil)
+			assert.NoError(t, err)
+			o.SignV = pkgservice.SignVersionCanonical
+			want, err := o.MarshalCanonical()
+			assert.NoError(t, err)
+
+			marshaled, err := o.Marshal()
+			assert.NoError(t, err)
+			o2 := &pkgservice.BaseOplog{}
+			err = o2.Unmarshal(marshaled)
+			assert.NoError(t, err)
+			o2.SetDB(nil, id, DBFriendOplogPrefix, nil, nil, nil)
+
+			got2, err := o2.MarshalCanonical()
+			assert.NoError(t, err)
+			assert.Equal(t, want, got2)
+		})
+	}
+}
+
+func TestFriendOplog_SignCreateMedia(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	pkgservice.CurrentSignVersion = pkgservice.SignVersionCanonical
+	defer func() { pkgservice.CurrentSignVersion = pkgservice.SignVersionJSON }()
+
+	masterKey, _ := crypto.GenerateKey()
+	doerID, _ := types.NewPttIDFromKey(masterKey)
+	signKey, err := pkgservice.NewSignKeyInfo(doerID, masterKey)
+	assert.NoError(t, err)
+
+	id := &types.PttID{1, 2}
+	ts := types.Timestamp{Ts: 1, NanoTs: 2}
+	data := &pkgservice.OpCreateMedia{BlockInfoID: id, Hashs: [][][]byte{{{7}}}, NBlock: 1, MediaType: pkgservice.MediaTypePNG}
+
+	o, err := pkgservice.NewOplog(id, ts, doerID, FriendOpTypeCreateMedia, data, nil, id, DBFriendOplogPrefix, nil, nil, nil)
+	assert.NoError(t, err)
+
+	// sign with the local op-data.
+	err = o.Sign(signKey)
+	assert.NoError(t, err)
+	assert.Equal(t, pkgservice.SignVersionCanonical, o.SignV)
+
+	// verify with the op-data unmarshaled from the peers.
+	marshaled, err := o.Marshal()
+	assert.NoError(t, err)
+	o2 := &pkgservice.BaseOplog{}
+	err = o2.Unmarshal(marshaled)
+	assert.NoError(t, err)
+	o2.SetDB(nil, id, DBFriendOplogPrefix, nil, nil, nil)
+
+	assert.Equal(t, o.SignV, o2.SignV)
+	assert.NoError(t, o2.Verify())
+
+	// the media-type is signed.
+	o3 := &pkgservice.BaseOplog{}
+	o3.Unmarshal(marshaled)
+	o3.SetDB(nil, id, DBFriendOplogPrefix, nil, nil, nil)
+	o3.Data.(map[string]interface{})["T"] = float64(pkgservice.MediaTypeJPEG)
+	assert.Error(t, o3.Verify())
+}
@@ -18,9 +18,11 @@ package friend
 
 import (
 	"path/filepath"
+	"time"
 
 	"github.com/ailabstw/go-pttai-core/node"
 	"github.com/ailabstw/go-pttai-core/pttdb"
+	"github.com/ailabstw/go-pttai-core/search"
 	pkgservice "github.com/ailabstw/go-pttai-core/service"
 )
 
@@ -36,12 +38,6 @@ var (
 
 // db
 var (
-	dbFriendCore *pttdb.LDBDatabase = nil
-	dbFriend     *pttdb.LDBBatch    = nil
-	dbKey        *pttdb.LDBDatabase = nil
-
-	dbMeta *pttdb.LDBDatabase = nil
-
 	DBFriendIdxPrefix         = []byte(".frix")
 	DBFriendIdx2Prefix        = []byte(".fri2")
 	DBFriendPrefix            = []byte(".frdb")
@@ -58,6 +54,23 @@ var (
 	DBMessageCreateTS2Prefix   = []byte(".mcdb")
 
 	DBFriendListSeenPrefix = []byte(".frsn")
+
+	DBMessageReceiptPrefix = []byte(".frrc")
+
+	DBMessageSearchPrefix = []byte(".mgsx")
+
+	DBMessageExpirePrefix = []byte(".mgex")
+
+	DBPresenceSettingPrefix = []byte(".frps")
+
+	DBFriendDevicesPrefix = []byte(".frdv")
+	DBDevicePrekeyPrefix  = []byte(".frdp")
+
+	// dbKey
+	DBRatchetPrefix      = []byte(".frrt")
+	DBLocalMessagePrefix = []byte(".frlm")
+	DBPrekeyPrefix       = []byte(".frpk")
+	DBStorageKeyPrefix   = []byte(".frsk")
 )
 
 // protocol
@@ -95,6 +108,29 @@ const (
 	// init friend info
 	InitFriendInfoMsg
 	InitFriendInfoAckMsg
+
+	SyncUpdateMessageMsg
+	SyncUpdateMessageAckMsg
+
+	SyncUpdateMessageBlockMsg
+	SyncUpdateMessageBlockAckMsg
+
+	// message receipt
+	SyncMessageReceiptMsg
+
+	// media
+	SyncCreateMediaMsg
+	SyncCreateMediaAckMsg
+
+	SyncCreateMediaBlockMsg
+	SyncCreateMediaBlockAckMsg
+
+	// presence
+	SyncPresenceMsg
+	SyncTypingMsg
+
+	// ratchet
+	SyncDevicesMsg
 )
 
 // max-masters
@@ -117,49 +153,103 @@ var (
 // message
 const (
 	NFirstLineInBlock = 20
+
+	ExpireMessageSeconds = 10 * time.Second
 )
 
-func InitFriend(dataDir string) error {
+// message receipt
+const (
+	NSyncMessageReceipts = 100
+)
+
+// presence
+const (
+	HeartbeatPresenceSeconds = 30 * time.Second
+	ExpirePresenceSeconds    = 3 * HeartbeatPresenceSeconds
+	ExpireTypingSeconds      = 10 * time.Second
+)
+
+// ratchet
+const (
+	SizeRatchetKey = 32
+
+	MaxSkipRatchetKeys = 1000
+)
+
+var (
+	RatchetInfo = []byte("ptt-friend-ratchet")
+	PrekeyInfo  = []byte("ptt-friend-prekey")
+)
+
+// prekey
+var (
+	RenewPrekeySeconds  int64 = 86400
+	ExpirePrekeySeconds int64 = 604800
+)
+
+/*
+friendDB is the dbs of the friend-service of a node.
+*/
+type friendDB struct {
+	friendCore pttdb.Storage
+	friend     pttdb.IndexBatch
+	key        pttdb.Storage
+
+	meta pttdb.Storage
+
+	messageIndex *search.Index
+}
+
+func newFriendDB(dataDir string, engine pttdb.Engine) (*friendDB, error) {
 	var err error
 
-	dbFriendCore, err = pttdb.NewLDBDatabase("friend", dataDir, 0, 0)
+	db := &friendDB{}
+
+	db.friendCore, err = pttdb.NewStorageWithEngine(engine, "friend", dataDir)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	dbFriend, err = pttdb.NewLDBBatch(dbFriendCore)
+	db.friend, err = pttdb.NewStorageBatch(db.friendCore)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	dbMeta, err = pttdb.NewLDBDatabase("friendmeta", dataDir, 0, 0)
+	db.messageIndex, err = search.NewIndex(db.friendCore, DBMessageSearchPrefix)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	dbKey, err = pttdb.NewLDBDatabase("friendkey", dataDir, 0, 0)
+	db.meta, err = pttdb.NewStorageWithEngine(engine, "friendmeta", dataDir)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	db.key, err = pttdb.NewStorageWithEngine(engine, "friendkey", dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
 }
 
-func TeardownFriend() {
-	if dbKey != nil {
-		dbKey.Close()
-		dbKey = nil
+func (db *friendDB) Close() {
+	if db.key != nil {
+		db.key.Close()
+		db.key = nil
 	}
 
-	if dbFriendCore != nil {
-		dbFriendCore.Close()
-		dbFriendCore = nil
+	if db.friendCore != nil {
+		db.friendCore.Close()
+		db.friendCore = nil
 	}
-	if dbFriend != nil {
-		dbFriend = nil
+	if db.friend != nil {
+		db.friend = nil
 	}
 
-	if dbMeta != nil {
-		dbMeta.Close()
-		dbMeta = nil
+	db.messageIndex = nil
+
+	if db.meta != nil {
+		db.meta.Close()
+		db.meta = nil
 	}
 }
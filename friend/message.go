@@ -31,6 +31,9 @@ type Message struct {
 
 	UpdateTS types.Timestamp `json:"UT"`
 
+	// ExpireTS is set for the ephemeral messages, the message is removed once expired.
+	ExpireTS *types.Timestamp `json:"ET,omitempty"`
+
 	SyncInfo *pkgservice.BaseSyncInfo `json:"s,omitempty"`
 }
 
@@ -89,7 +92,7 @@ func AliveMessages(typedObjs []*Message) []*Message {
 }
 
 func (pm *ProtocolManager) SetMessageDB(m *Message) {
-	m.SetDB(dbFriend, pm.DBObjLock(), pm.Entity().GetID(), pm.dbMessagePrefix, pm.dbMessageIdxPrefix, pm.SetBlockInfoDB, pm.SetMediaDB)
+	m.SetDB(pm.db.friend, pm.DBObjLock(), pm.Entity().GetID(), pm.dbMessagePrefix, pm.dbMessageIdxPrefix, pm.SetBlockInfoDB, pm.SetMediaDB)
 }
 
 func (m *Message) Save(isLocked bool) error {
@@ -231,6 +234,13 @@ func (m *Message) SetSyncInfo(theSyncInfo pkgservice.SyncInfo) error {
 	return nil
 }
 
+func (m *Message) IsExpired(ts types.Timestamp) bool {
+	if m.ExpireTS == nil {
+		return false
+	}
+	return m.ExpireTS.IsLessEqual(ts)
+}
+
 func (m *Message) DeleteAll(isLocked bool) error {
 	var err error
 	if !isLocked {
@@ -242,11 +252,19 @@ func (m *Message) DeleteAll(isLocked bool) error {
 	}
 
 	// block-info
-	blockInfo := m.GetBlockInfo()
 	setBlockInfoDB := m.SetBlockInfoDB()
-	setBlockInfoDB(blockInfo, m.ID)
 
-	blockInfo.Remove(false)
+	blockInfo := m.GetBlockInfo()
+	if blockInfo != nil {
+		setBlockInfoDB(blockInfo, m.ID)
+		blockInfo.Remove(false)
+	}
+
+	// block-info of the pending update
+	if m.SyncInfo != nil && m.SyncInfo.BlockInfo != nil {
+		setBlockInfoDB(m.SyncInfo.BlockInfo, m.ID)
+		m.SyncInfo.BlockInfo.Remove(false)
+	}
 
 	// delete
 	m.Delete(true)
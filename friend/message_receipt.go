@@ -0,0 +1,158 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package friend
+
+import (
+	"encoding/json"
+
+	"github.com/ailabstw/go-pttai-core/common"
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+)
+
+type MessageReceiptStatus int
+
+const (
+	MessageReceiptStatusSent MessageReceiptStatus = iota
+	MessageReceiptStatusDelivered
+	MessageReceiptStatusRead
+
+	NMessageReceiptStatus
+)
+
+/*
+MessageReceipt is the delivery / read acknowledgement of a message by a user.
+
+Receipts are not oplogs. They are exchanged directly between the peers of the friend-entity
+and merged with Merge, so the devices of both users converge to the same receipt
+regardless of the order the receipts arrive.
+*/
+type MessageReceipt struct {
+	V         types.Version
+	FriendID  *types.PttID         `json:"FID"`
+	MessageID *types.PttID         `json:"MID"`
+	UserID    *types.PttID         `json:"UID"`
+	Status    MessageReceiptStatus `json:"S"`
+	UpdateTS  types.Timestamp      `json:"UT"`
+}
+
+func NewMessageReceipt(friendID *types.PttID, messageID *types.PttID, userID *types.PttID, status MessageReceiptStatus, ts types.Timestamp) *MessageReceipt {
+	return &MessageReceipt{
+		V:         types.CurrentVersion,
+		FriendID:  friendID,
+		MessageID: messageID,
+		UserID:    userID,
+		Status:    status,
+		UpdateTS:  ts,
+	}
+}
+
+func (r *MessageReceipt) IsValid() bool {
+	return r.FriendID != nil && r.MessageID != nil && r.UserID != nil &&
+		r.Status > MessageReceiptStatusSent && r.Status < NMessageReceiptStatus
+}
+
+/*
+Merge merges r2 into r. The higher status wins, and the earlier ts wins with the same status.
+Returns true if r is changed.
+*/
+func (r *MessageReceipt) Merge(r2 *MessageReceipt) bool {
+	switch {
+	case r2.Status > r.Status:
+	case r2.Status == r.Status && r2.UpdateTS.IsLess(r.UpdateTS):
+	default:
+		return false
+	}
+
+	r.Status = r2.Status
+	r.UpdateTS = r2.UpdateTS
+
+	return true
+}
+
+func (r *MessageReceipt) MarshalKey() ([]byte, error) {
+	return common.Concat([][]byte{DBMessageReceiptPrefix, r.FriendID[:], r.MessageID[:], r.UserID[:]})
+}
+
+func (r *MessageReceipt) Get(db pttdb.Storage) error {
+	key, err := r.MarshalKey()
+	if err != nil {
+		return err
+	}
+
+	val, err := db.Get(key)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(val, r)
+}
+
+func (r *MessageReceipt) Save(db pttdb.Storage) error {
+	key, err := r.MarshalKey()
+	if err != nil {
+		return err
+	}
+
+	marshaled, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	return db.Put(key, marshaled)
+}
+
+func (r *MessageReceipt) Delete(db pttdb.Storage) error {
+	key, err := r.MarshalKey()
+	if err != nil {
+		return err
+	}
+
+	return db.Delete(key)
+}
+
+func messageReceiptPrefix(friendID *types.PttID, messageID *types.PttID) ([]byte, error) {
+	if messageID == nil {
+		return common.Concat([][]byte{DBMessageReceiptPrefix, friendID[:]})
+	}
+	return common.Concat([][]byte{DBMessageReceiptPrefix, friendID[:], messageID[:]})
+}
+
+func getMessageReceiptList(db pttdb.Storage, friendID *types.PttID, messageID *types.PttID) ([]*MessageReceipt, error) {
+	prefix, err := messageReceiptPrefix(friendID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := db.NewIteratorWithPrefix(nil, prefix, pttdb.ListOrderNext)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Release()
+
+	receipts := make([]*MessageReceipt, 0)
+	for iter.Next() {
+		receipt := &MessageReceipt{}
+		err = json.Unmarshal(iter.Value(), receipt)
+		if err != nil {
+			continue
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	return receipts, nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package friend
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+)
+
+type PresenceStatus int
+
+const (
+	PresenceStatusOffline PresenceStatus = iota
+	PresenceStatusOnline
+
+	NPresenceStatus
+)
+
+/*
+PresenceSetting is the privacy setting of the presence and typing indicators.
+Both are shared with the friends by default.
+
+The setting is kept in the device only, and is not synced to my other devices.
+*/
+type PresenceSetting struct {
+	IsHidePresence bool `json:"HP"`
+	IsHideTyping   bool `json:"HT"`
+}
+
+func loadPresenceSetting(db pttdb.Storage) (*PresenceSetting, error) {
+	setting := &PresenceSetting{}
+
+	theBytes, err := db.Get(DBPresenceSettingPrefix)
+	if err != nil {
+		// not set yet.
+		return setting, nil
+	}
+
+	err = json.Unmarshal(theBytes, setting)
+	if err != nil {
+		return nil, err
+	}
+
+	return setting, nil
+}
+
+func (s *PresenceSetting) Save(db pttdb.Storage) error {
+	marshaled, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	return db.Put(DBPresenceSettingPrefix, marshaled)
+}
+
+/*
+presenceState tracks the presence of the devices of the friend and whether the friend is typing.
+
+The state is in memory only. The friend is online if any of the devices sent the heartbeat within
+ExpirePresenceSeconds, and is typing until the typing-stop or ExpireTypingSeconds after the last typing-start.
+*/
+type presenceState struct {
+	lock sync.Mutex
+
+	nodes    map[discover.NodeID]time.Time
+	typingTS time.Time
+}
+
+func newPresenceState() *presenceState {
+	return &presenceState{
+		nodes: make(map[discover.NodeID]time.Time),
+	}
+}
+
+func (s *presenceState) Status() PresenceStatus {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.status()
+}
+
+func (s *presenceState) status() PresenceStatus {
+	if len(s.nodes) == 0 {
+		return PresenceStatusOffline
+	}
+	return PresenceStatusOnline
+}
+
+func (s *presenceState) IsTyping() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return !s.typingTS.IsZero()
+}
+
+/*
+SetNode sets the presence of the device of the friend at now.
+Returns true if the presence of the friend is changed.
+*/
+func (s *presenceState) SetNode(nodeID *discover.NodeID, status PresenceStatus, now time.Time) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	origStatus := s.status()
+
+	switch status {
+	case PresenceStatusOnline:
+		s.nodes[*nodeID] = now
+	default:
+		delete(s.nodes, *nodeID)
+	}
+
+	return s.status() != origStatus
+}
+
+/*
+SetTyping sets whether the friend is typing at now.
+Returns true if changed.
+*/
+func (s *presenceState) SetTyping(isTyping bool, now time.Time) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	origIsTyping := !s.typingTS.IsZero()
+
+	if isTyping {
+		s.typingTS = now
+	} else {
+		s.typingTS = time.Time{}
+	}
+
+	return isTyping != origIsTyping
+}
+
+/*
+Expire removes the devices without the heartbeats and the stale typing.
+The friend stops typing when becoming offline.
+
+Return: isPresenceChanged, isTypingChanged
+*/
+func (s *presenceState) Expire(now time.Time) (bool, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	origStatus := s.status()
+	origIsTyping := !s.typingTS.IsZero()
+
+	for nodeID, ts := range s.nodes {
+		if now.Sub(ts) >= ExpirePresenceSeconds {
+			delete(s.nodes, nodeID)
+		}
+	}
+
+	status := s.status()
+	if origIsTyping && (status == PresenceStatusOffline || now.Sub(s.typingTS) >= ExpireTypingSeconds) {
+		s.typingTS = time.Time{}
+	}
+
+	return status != origStatus, origIsTyping && s.typingTS.IsZero()
+}
@@ -0,0 +1,68 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package friend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPresenceState(t *testing.T) {
+	setupTest(t)
+	defer teardownTest(t)
+
+	s := newPresenceState()
+	node1 := &discover.NodeID{1}
+	node2 := &discover.NodeID{2}
+	now := time.Now()
+
+	assert.Equal(t, PresenceStatusOffline, s.Status())
+
+	// online with any of the devices.
+	assert.True(t, s.SetNode(node1, PresenceStatusOnline, now))
+	assert.False(t, s.SetNode(node2, PresenceStatusOnline, now.Add(ExpirePresenceSeconds/2)))
+	assert.Equal(t, PresenceStatusOnline, s.Status())
+
+	assert.False(t, s.SetNode(node1, PresenceStatusOffline, now))
+	assert.Equal(t, PresenceStatusOnline, s.Status())
+
+	// typing
+	assert.True(t, s.SetTyping(true, now))
+	assert.False(t, s.SetTyping(true, now))
+	assert.True(t, s.IsTyping())
+
+	isPresenceChanged, isTypingChanged := s.Expire(now.Add(ExpireTypingSeconds - time.Second))
+	assert.False(t, isPresenceChanged)
+	assert.False(t, isTypingChanged)
+
+	isPresenceChanged, isTypingChanged = s.Expire(now.Add(ExpireTypingSeconds))
+	assert.False(t, isPresenceChanged)
+	assert.True(t, isTypingChanged)
+	assert.False(t, s.IsTyping())
+
+	// offline without the heartbeats, and stop typing.
+	assert.True(t, s.SetTyping(true, now.Add(ExpirePresenceSeconds)))
+
+	isPresenceChanged, isTypingChanged = s.Expire(now.Add(ExpirePresenceSeconds + ExpirePresenceSeconds/2))
+	assert.True(t, isPresenceChanged)
+	assert.True(t, isTypingChanged)
+	assert.Equal(t, PresenceStatusOffline, s.Status())
+	assert.False(t, s.IsTyping())
+}
@@ -44,7 +44,20 @@ func (pm *ProtocolManager) CleanObject() error {
 		pm.SetMessageDB(msg)
 
 		msg.DeleteAll(false)
+		pm.db.messageIndex.Delete(pm.Entity().GetID(), msg.ID)
 	}
 
+	// message receipt
+	receipts, err := pm.GetMessageReceipts(nil)
+	if err != nil {
+		return err
+	}
+	for _, receipt := range receipts {
+		receipt.Delete(pm.db.friendCore)
+	}
+
+	// ratchet
+	pm.cleanRatchet()
+
 	return nil
 }
@@ -0,0 +1,86 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package friend
+
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+/*
+CreateFileMedia creates the media of the large file from the completed chunked upload.
+*/
+func (pm *ProtocolManager) CreateFileMedia(uploadID *types.PttID) (*pkgservice.Media, error) {
+
+	myID := pm.Router().GetMyEntity().GetID()
+
+	if !pm.IsMaster(myID, false) {
+		return nil, types.ErrInvalidID
+	}
+
+	return pm.BaseProtocolManager.CreateFileMedia(
+		uploadID,
+		FriendOpTypeCreateMedia,
+
+		pm.friendOplogMerkle,
+
+		pm.NewFriendOplogWithTS,
+
+		pm.SetFriendDB,
+		pm.broadcastFriendOplogsCore,
+		pm.broadcastFriendOplogCore,
+	)
+}
+
+func (pm *ProtocolManager) BeginUploadFile(filename []byte, size int64) (*pkgservice.UploadFile, error) {
+
+	myID := pm.Router().GetMyEntity().GetID()
+
+	if !pm.IsMaster(myID, false) {
+		return nil, types.ErrInvalidID
+	}
+
+	return pm.BaseProtocolManager.BeginUploadFile(filename, size)
+}
+
+/**********
+ * Sync Media
+ **********/
+
+func (pm *ProtocolManager) HandleSyncCreateMediaAck(dataBytes []byte, peer *pkgservice.PttPeer) error {
+	return pm.BaseProtocolManager.HandleSyncCreateMediaAck(
+		dataBytes,
+		peer,
+
+		pm.friendOplogMerkle,
+
+		pm.SetFriendDB,
+		pm.broadcastFriendOplogCore,
+	)
+}
+
+func (pm *ProtocolManager) HandleSyncCreateMediaBlockAck(dataBytes []byte, peer *pkgservice.PttPeer) error {
+	return pm.BaseProtocolManager.HandleSyncCreateMediaBlockAck(
+		dataBytes,
+		peer,
+
+		pm.friendOplogMerkle,
+
+		pm.SetFriendDB,
+		pm.broadcastFriendOplogCore,
+	)
+}
@@ -27,9 +27,14 @@ import (
 type CreateMessage struct {
 	Msg      [][]byte
 	MediaIDs []*types.PttID
+	TTL      int64
 }
 
-func (pm *ProtocolManager) CreateMessage(msg [][]byte, mediaIDs []*types.PttID) (*Message, error) {
+/*
+CreateMessage creates the message. The message is ephemeral and removed from
+all the devices after ttl seconds if ttl is positive.
+*/
+func (pm *ProtocolManager) CreateMessage(msg [][]byte, mediaIDs []*types.PttID, ttl int64) (*Message, error) {
 
 	myID := pm.Router().GetMyEntity().GetID()
 
@@ -37,9 +42,14 @@ func (pm *ProtocolManager) CreateMessage(msg [][]byte, mediaIDs []*types.PttID)
 		return nil, types.ErrInvalidID
 	}
 
+	if ttl < 0 {
+		return nil, ErrInvalidTTL
+	}
+
 	data := &CreateMessage{
 		Msg:      msg,
 		MediaIDs: mediaIDs,
+		TTL:      ttl,
 	}
 
 	theMessage, err := pm.CreateObject(
@@ -109,10 +119,10 @@ func (pm *ProtocolManager) increateMessage(theObj pkgservice.Object, theData pkg
 	}
 
 	// block-info
-	blockID, blockHashs, err := pm.SplitContentBlocks(nil, obj.ID, data.Msg, NFirstLineInBlock)
-	log.Debug("increateMessage: after SplitContentBlocks", "obj", obj.ID, "blockID", blockID, "e", err)
+	blockID, blockHashs, ratchetHeader, err := pm.splitMessageBlocks(obj.ID, data.Msg)
+	log.Debug("increateMessage: after splitMessageBlocks", "obj", obj.ID, "blockID", blockID, "e", err)
 	if err != nil {
-		log.Error("increateMessage: Unable to SplitContentBlocks", "e", err)
+		log.Error("increateMessage: Unable to splitMessageBlocks", "e", err)
 		return err
 	}
 
@@ -129,6 +139,10 @@ func (pm *ProtocolManager) increateMessage(theObj pkgservice.Object, theData pkg
 	opData.NBlock = blockInfo.NBlock
 	opData.Hashs = blockHashs
 	opData.MediaIDs = data.MediaIDs
+	opData.TTL = data.TTL
+	opData.Ratchet = ratchetHeader
+
+	obj.ExpireTS = messageExpireTS(oplog.CreateTS, data.TTL)
 
 	return nil
 }
@@ -137,6 +151,19 @@ func (pm *ProtocolManager) postcreateMessage(theObj pkgservice.Object, oplog *pk
 
 	log.Debug("postcreateMessage: start")
 
+	opData := &FriendOpCreateMessage{}
+	err := oplog.GetData(opData)
+	if err == nil && opData.BlockInfoID != nil {
+		err = pm.openMessage(theObj, opData.BlockInfoID, opData.Ratchet)
+		if err != nil {
+			log.Warn("postcreateMessage: unable to open message", "message", theObj.GetID(), "e", err)
+		}
+	}
+
+	pm.indexMessage(theObj)
+
+	pm.saveMessageExpireTS(theObj)
+
 	entity := pm.Entity().(*Friend)
 	entity.SaveMessageCreateTS(oplog.UpdateTS)
 
@@ -145,7 +172,11 @@ func (pm *ProtocolManager) postcreateMessage(theObj pkgservice.Object, oplog *pk
 
 	if reflect.DeepEqual(myID, creatorID) {
 		pm.SaveLastSeen(oplog.UpdateTS)
+	} else if message, ok := theObj.(*Message); ok {
+		pm.MarkMessageDelivered(message)
 	}
 
+	pm.postMessageEvent(theObj, types.StatusAlive, oplog.UpdateTS)
+
 	return nil
 }
@@ -93,6 +93,8 @@ func (pm *ProtocolManager) newMessageWithOplog(oplog *pkgservice.BaseOplog, theO
 	blockInfo.InitIsGood()
 	obj.SetBlockInfo(blockInfo)
 
+	obj.ExpireTS = messageExpireTS(oplog.CreateTS, opData.TTL)
+
 	return obj
 }
 
@@ -0,0 +1,85 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package friend
+
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+/*
+DeleteMessage retracts a message.
+Only the creator of the message is allowed to delete it.
+*/
+func (pm *ProtocolManager) DeleteMessage(messageID *types.PttID) error {
+
+	myID := pm.Router().GetMyEntity().GetID()
+
+	if !pm.IsMaster(myID, false) {
+		return types.ErrInvalidID
+	}
+
+	origObj := NewEmptyMessage()
+	pm.SetMessageDB(origObj)
+
+	err := pm.checkMessageCreator(origObj, messageID, myID)
+	if err != nil {
+		return err
+	}
+
+	opData := &FriendOpDeleteMessage{}
+
+	err = pm.DeleteObject(
+		messageID,
+		FriendOpTypeDeleteMessage,
+
+		origObj,
+		opData,
+
+		pm.friendOplogMerkle,
+
+		pm.SetFriendDB,
+
+		pm.NewFriendOplog,
+		nil,
+		pm.setPendingDeleteMessageSyncInfo,
+		pm.broadcastFriendOplogCore,
+		pm.postdeleteMessage,
+	)
+	log.Debug("DeleteMessage: after DeleteObject", "messageID", messageID, "e", err)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (pm *ProtocolManager) setPendingDeleteMessageSyncInfo(theObj pkgservice.Object, status types.Status, oplog *pkgservice.BaseOplog) error {
+
+	obj, ok := theObj.(*Message)
+	if !ok {
+		return pkgservice.ErrInvalidData
+	}
+
+	syncInfo := &pkgservice.BaseSyncInfo{}
+	syncInfo.InitWithOplog(status, oplog)
+
+	obj.SyncInfo = syncInfo
+
+	return nil
+}
@@ -0,0 +1,201 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package friend
+
+import (
+	"time"
+
+	"github.com/ailabstw/go-pttai-core/common"
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+func messageExpireTS(createTS types.Timestamp, ttl int64) *types.Timestamp {
+	if ttl <= 0 {
+		return nil
+	}
+
+	return &types.Timestamp{Ts: createTS.Ts + ttl, NanoTs: createTS.NanoTs}
+}
+
+func (pm *ProtocolManager) marshalMessageExpirePrefix() []byte {
+	return append(DBMessageExpirePrefix, pm.Entity().GetID()[:]...)
+}
+
+func (pm *ProtocolManager) marshalMessageExpireKey(expireTS *types.Timestamp, messageID *types.PttID) ([]byte, error) {
+	marshaledTS, err := expireTS.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return common.Concat([][]byte{pm.marshalMessageExpirePrefix(), marshaledTS, messageID[:]})
+}
+
+/*
+saveMessageExpireTS saves the expire-ts of the ephemeral message, for the sweeper to find the expired messages in order.
+*/
+func (pm *ProtocolManager) saveMessageExpireTS(theObj pkgservice.Object) error {
+	message, ok := theObj.(*Message)
+	if !ok {
+		return pkgservice.ErrInvalidData
+	}
+
+	if message.ExpireTS == nil {
+		return nil
+	}
+
+	key, err := pm.marshalMessageExpireKey(message.ExpireTS, message.ID)
+	if err != nil {
+		return err
+	}
+
+	return pm.db.friendCore.Put(key, message.ID[:])
+}
+
+func (pm *ProtocolManager) ExpireMessageLoop() error {
+	ticker := time.NewTicker(ExpireMessageSeconds)
+	defer ticker.Stop()
+
+	pm.ExpireMessages()
+
+loop:
+	for {
+		select {
+		case <-ticker.C:
+			pm.ExpireMessages()
+		case <-pm.QuitSync():
+			log.Debug("ExpireMessageLoop: QuitSync", "entity", pm.Entity().GetID())
+			break loop
+		}
+	}
+
+	return nil
+}
+
+/*
+ExpireMessages removes the ephemeral messages that are expired.
+Every device removes the messages by itself based on the ttl in the signed create-message oplog.
+*/
+func (pm *ProtocolManager) ExpireMessages() error {
+	now, err := types.GetTimestamp()
+	if err != nil {
+		return err
+	}
+
+	iter, err := pm.db.friendCore.NewIteratorWithPrefix(nil, pm.marshalMessageExpirePrefix(), pttdb.ListOrderNext)
+	if err != nil {
+		return err
+	}
+
+	lenPrefix := len(DBMessageExpirePrefix) + types.SizePttID
+
+	toRemoveKeys := make([][]byte, 0)
+	messageIDs := make([]*types.PttID, 0)
+	for iter.Next() {
+		key := iter.Key()
+		if len(key) != lenPrefix+types.SizeTimestamp+types.SizePttID {
+			continue
+		}
+
+		expireTS, err := types.UnmarshalTimestamp(key[lenPrefix : lenPrefix+types.SizeTimestamp])
+		if err != nil {
+			continue
+		}
+		if now.IsLess(expireTS) {
+			break
+		}
+
+		messageID := &types.PttID{}
+		copy(messageID[:], iter.Value())
+
+		toRemoveKeys = append(toRemoveKeys, common.CloneBytes(key))
+		messageIDs = append(messageIDs, messageID)
+	}
+	iter.Release()
+
+	for i, messageID := range messageIDs {
+		err = pm.expireMessage(messageID, now)
+		if err != nil {
+			log.Warn("ExpireMessages: unable to expire message", "entity", pm.Entity().GetID(), "message", messageID, "e", err)
+			continue
+		}
+
+		pm.db.friendCore.Delete(toRemoveKeys[i])
+	}
+
+	return nil
+}
+
+/*
+expireMessage removes the blocks and the media of the message.
+The message itself is kept as deleted, so it is not re-created by the create-message oplog from the other devices.
+*/
+func (pm *ProtocolManager) expireMessage(messageID *types.PttID, ts types.Timestamp) error {
+	message := NewEmptyMessage()
+	pm.SetMessageDB(message)
+	message.SetID(messageID)
+
+	err := message.Lock()
+	if err != nil {
+		return err
+	}
+	defer message.Unlock()
+
+	err = message.GetByID(true)
+	if err == pttdb.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if message.Status >= types.StatusDeleted {
+		return nil
+	}
+
+	// block-info (including the media)
+	blockInfo := message.GetBlockInfo()
+	if blockInfo != nil {
+		pm.SetBlockInfoDB(blockInfo, messageID)
+		blockInfo.Remove(false)
+		pm.removeLocalMessage(blockInfo.ID)
+	}
+
+	// block-info of the pending update
+	if message.SyncInfo != nil && message.SyncInfo.BlockInfo != nil {
+		pm.SetBlockInfoDB(message.SyncInfo.BlockInfo, messageID)
+		message.SyncInfo.BlockInfo.Remove(false)
+		pm.removeLocalMessage(message.SyncInfo.BlockInfo.ID)
+	}
+
+	message.SyncInfo = nil
+	message.Status = types.StatusDeleted
+	message.UpdateTS = ts
+
+	err = message.Save(true)
+	if err != nil {
+		return err
+	}
+
+	pm.db.messageIndex.Delete(pm.Entity().GetID(), messageID)
+
+	pm.postMessageEvent(message, types.StatusDeleted, ts)
+
+	return nil
+}
@@ -25,7 +25,7 @@ func (pm *ProtocolManager) CreateFriendOplog(objID *types.PttID, ts types.Timest
 
 	myID := pm.Entity().GetID()
 
-	oplog, err := NewFriendOplog(objID, ts, myID, op, data, myID, pm.dbFriendLock)
+	oplog, err := NewFriendOplog(objID, ts, myID, op, data, pm.db.friend, myID, pm.dbFriendLock)
 	if err != nil {
 		return nil, err
 	}
@@ -47,6 +47,9 @@ func (pm *ProtocolManager) BroadcastFriendOplog(oplog *FriendOplog) error {
 }
 
 func (pm *ProtocolManager) broadcastFriendOplogCore(oplog *pkgservice.BaseOplog) error {
+	pm.relayFriendOplogsToHubs([]*pkgservice.BaseOplog{oplog})
+	pm.relayFriendOplogsToDevices([]*pkgservice.BaseOplog{oplog})
+
 	return pm.BroadcastOplog(oplog, AddFriendOplogMsg, AddPendingFriendOplogMsg)
 }
 
@@ -60,6 +63,9 @@ func (pm *ProtocolManager) BroadcastFriendOplogs(opKeyLogs []*FriendOplog) error
 }
 
 func (pm *ProtocolManager) broadcastFriendOplogsCore(oplogs []*pkgservice.BaseOplog) error {
+	pm.relayFriendOplogsToHubs(oplogs)
+	pm.relayFriendOplogsToDevices(oplogs)
+
 	return pm.BroadcastOplogs(oplogs, AddFriendOplogsMsg, AddPendingFriendOplogsMsg)
 }
 
@@ -19,7 +19,7 @@ package friend
 import "github.com/ailabstw/go-pttai-core/common/types"
 
 func (spm *ServiceProtocolManager) GetFriendByFriendID(friendID *types.PttID) (*Friend, error) {
-	f := NewEmptyFriend()
+	f := NewEmptyFriend(spm.db)
 
 	err := f.GetByFriendID(friendID)
 	if err != nil {
@@ -36,7 +36,7 @@ func (spm *ServiceProtocolManager) GetFriendByFriendID(friendID *types.PttID) (*
 }
 
 func (spm *ServiceProtocolManager) GetFriendEntityByFriendID(friendID *types.PttID) (*Friend, error) {
-	f := NewEmptyFriend()
+	f := NewEmptyFriend(spm.db)
 
 	err := f.GetByFriendID(friendID)
 	if err != nil {
@@ -24,7 +24,7 @@ import (
 )
 
 func (spm *ServiceProtocolManager) GetFriendList(startingFriendID *types.PttID, limit int, listOrder pttdb.ListOrder) ([]*Friend, error) {
-	iter, err := getFriendIter(startingFriendID, listOrder)
+	iter, err := spm.getFriendIter(startingFriendID, listOrder)
 	if err != nil {
 		return nil, err
 	}
@@ -44,7 +44,7 @@ func (spm *ServiceProtocolManager) GetFriendList(startingFriendID *types.PttID,
 		log.Debug("GetFriendList (in-for-loop)", "k", k)
 		v := iter.Value()
 
-		eachFriend := NewEmptyFriend()
+		eachFriend := NewEmptyFriend(spm.db)
 		err := eachFriend.Unmarshal(v)
 		if err != nil {
 			continue
@@ -64,13 +64,13 @@ func (spm *ServiceProtocolManager) GetFriendList(startingFriendID *types.PttID,
 	return friendList, nil
 }
 
-func getFriendIter(startingID *types.PttID, listOrder pttdb.ListOrder) (iterator.Iterator, error) {
+func (spm *ServiceProtocolManager) getFriendIter(startingID *types.PttID, listOrder pttdb.ListOrder) (iterator.Iterator, error) {
 	if startingID == nil {
-		return dbFriend.DB().NewIteratorWithPrefix(nil, DBFriendPrefix, listOrder)
+		return spm.db.friend.DB().NewIteratorWithPrefix(nil, DBFriendPrefix, listOrder)
 	}
 
 	// key
-	f := NewEmptyFriend()
+	f := NewEmptyFriend(spm.db)
 	f.SetID(startingID)
 
 	key, err := f.MarshalKey()
@@ -79,7 +79,7 @@ func getFriendIter(startingID *types.PttID, listOrder pttdb.ListOrder) (iterator
 	}
 
 	// iter
-	iter, err := dbFriend.DB().NewIteratorWithPrefix(key, DBFriendPrefix, listOrder)
+	iter, err := spm.db.friend.DB().NewIteratorWithPrefix(key, DBFriendPrefix, listOrder)
 	if err != nil {
 		return nil, err
 	}
@@ -23,7 +23,7 @@ import (
 )
 
 func (spm *ServiceProtocolManager) GetFriendListByMsgCreateTS(startingTS types.Timestamp, limit int, listOrder pttdb.ListOrder) ([]*Friend, error) {
-	iter, err := getFriendByMsgCreateTSIter(startingTS, listOrder)
+	iter, err := spm.getFriendByMsgCreateTSIter(startingTS, listOrder)
 
 	if err != nil {
 		return nil, err
@@ -60,9 +60,9 @@ func (spm *ServiceProtocolManager) GetFriendListByMsgCreateTS(startingTS types.T
 	return friendList, nil
 }
 
-func getFriendByMsgCreateTSIter(startingTS types.Timestamp, listOrder pttdb.ListOrder) (iterator.Iterator, error) {
+func (spm *ServiceProtocolManager) getFriendByMsgCreateTSIter(startingTS types.Timestamp, listOrder pttdb.ListOrder) (iterator.Iterator, error) {
 	if startingTS == types.ZeroTimestamp {
-		return dbFriend.DB().NewIteratorWithPrefix(nil, DBMessageCreateTS2Prefix, listOrder)
+		return spm.db.friend.DB().NewIteratorWithPrefix(nil, DBMessageCreateTS2Prefix, listOrder)
 	}
 
 	key, err := getFriendIterMarshalKey(startingTS, listOrder)
@@ -71,7 +71,7 @@ func getFriendByMsgCreateTSIter(startingTS types.Timestamp, listOrder pttdb.List
 	}
 
 	// iter
-	iter, err := dbFriend.DB().NewIteratorWithPrefix(key, DBMessageCreateTS2Prefix, listOrder)
+	iter, err := spm.db.friend.DB().NewIteratorWithPrefix(key, DBMessageCreateTS2Prefix, listOrder)
 	if err != nil {
 		return nil, err
 	}
@@ -80,7 +80,7 @@ func getFriendByMsgCreateTSIter(startingTS types.Timestamp, listOrder pttdb.List
 }
 
 func getFriendIterMarshalKey(ts types.Timestamp, listOrder pttdb.ListOrder) ([]byte, error) {
-	f := NewEmptyFriend()
+	f := NewEmptyFriend(nil)
 	f.ID = &types.PttID{}
 	if listOrder == pttdb.ListOrderPrev {
 		copy(f.ID[:], types.MaxID[:])
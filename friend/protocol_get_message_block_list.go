@@ -33,6 +33,14 @@ func (pm *ProtocolManager) GetMessageBlockList(msgID *types.PttID, limit uint32)
 		return nil, nil, err
 	}
 
+	now, err := types.GetTimestamp()
+	if err != nil {
+		return nil, nil, err
+	}
+	if msg.IsExpired(now) {
+		return nil, nil, types.ErrInvalidStatus
+	}
+
 	blockInfo := msg.GetBlockInfo()
 	log.Debug("GetMessageBlockList: after GetBlockInfo", "msgID", msgID, "blockInfo", blockInfo)
 	if blockInfo == nil {
@@ -46,5 +54,10 @@ func (pm *ProtocolManager) GetMessageBlockList(msgID *types.PttID, limit uint32)
 		return nil, nil, err
 	}
 
+	contentBlockList, err = pm.decryptContentBlocks(msg, contentBlockList)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return msg, contentBlockList, nil
 }
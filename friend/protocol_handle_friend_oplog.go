@@ -24,6 +24,8 @@ import (
 
 type ProcessFriendInfo struct {
 	CreateMessageInfo map[types.PttID]*pkgservice.BaseOplog
+	UpdateMessageInfo map[types.PttID]*pkgservice.BaseOplog
+	DeleteMessageInfo map[types.PttID]*pkgservice.BaseOplog
 
 	CreateMediaInfo map[types.PttID]*pkgservice.BaseOplog
 
@@ -35,6 +37,8 @@ type ProcessFriendInfo struct {
 func NewProcessFriendInfo() *ProcessFriendInfo {
 	return &ProcessFriendInfo{
 		CreateMessageInfo: make(map[types.PttID]*pkgservice.BaseOplog),
+		UpdateMessageInfo: make(map[types.PttID]*pkgservice.BaseOplog),
+		DeleteMessageInfo: make(map[types.PttID]*pkgservice.BaseOplog),
 
 		CreateMediaInfo: make(map[types.PttID]*pkgservice.BaseOplog),
 
@@ -59,8 +63,13 @@ func (pm *ProtocolManager) processFriendLog(oplog *pkgservice.BaseOplog, process
 		origLogs, err = pm.handleDeleteFriendLogs(oplog, info)
 	case FriendOpTypeCreateMessage:
 		origLogs, err = pm.handleCreateMessageLogs(oplog, info)
+	case FriendOpTypeUpdateMessage:
+		origLogs, err = pm.handleUpdateMessageLogs(oplog, info)
+	case FriendOpTypeDeleteMessage:
+		origLogs, err = pm.handleDeleteMessageLogs(oplog, info)
 
 	case FriendOpTypeCreateMedia:
+		origLogs, err = pm.handleCreateMediaLogs(oplog, info)
 	}
 	return
 }
@@ -81,8 +90,13 @@ func (pm *ProtocolManager) processPendingFriendLog(oplog *pkgservice.BaseOplog,
 
 	case FriendOpTypeCreateMessage:
 		isToSign, origLogs, err = pm.handlePendingCreateMessageLogs(oplog, info)
+	case FriendOpTypeUpdateMessage:
+		isToSign, origLogs, err = pm.handlePendingUpdateMessageLogs(oplog, info)
+	case FriendOpTypeDeleteMessage:
+		isToSign, origLogs, err = pm.handlePendingDeleteMessageLogs(oplog, info)
 
 	case FriendOpTypeCreateMedia:
+		isToSign, origLogs, err = pm.handlePendingCreateMediaLogs(oplog, info)
 	}
 
 	return
@@ -100,17 +114,28 @@ func (pm *ProtocolManager) postprocessFriendOplogs(processInfo pkgservice.Proces
 
 	// message
 	createMessageIDs := pkgservice.ProcessInfoToSyncIDList(info.CreateMessageInfo, FriendOpTypeCreateMessage)
+	updateMessageIDs := pkgservice.ProcessInfoToSyncIDList(info.UpdateMessageInfo, FriendOpTypeUpdateMessage)
 
-	log.Debug("postprocessFriendOplogs: to syncMessage", "createMessageIDs", createMessageIDs)
+	log.Debug("postprocessFriendOplogs: to syncMessage", "createMessageIDs", createMessageIDs, "updateMessageIDs", updateMessageIDs)
 
 	pm.SyncMessage(SyncCreateMessageMsg, createMessageIDs, peer)
+	pm.SyncMessage(SyncUpdateMessageMsg, updateMessageIDs, peer)
 
 	// blocks
 	blockIDs := pkgservice.ProcessInfoToSyncBlockIDList(info.BlockInfo, FriendOpTypeCreateMessage)
+	updateBlockIDs := pkgservice.ProcessInfoToSyncBlockIDList(info.BlockInfo, FriendOpTypeUpdateMessage)
 
-	log.Debug("postprocessFriendOplogs: to syncBlock", "blockIDs", blockIDs)
+	log.Debug("postprocessFriendOplogs: to syncBlock", "blockIDs", blockIDs, "updateBlockIDs", updateBlockIDs)
 
 	pm.SyncBlock(SyncCreateMessageBlockMsg, blockIDs, peer)
+	pm.SyncBlock(SyncUpdateMessageBlockMsg, updateBlockIDs, peer)
+
+	// media
+	createMediaIDs := pkgservice.ProcessInfoToSyncIDList(info.CreateMediaInfo, FriendOpTypeCreateMedia)
+	mediaBlockIDs := pkgservice.ProcessInfoToSyncBlockIDList(info.BlockInfo, FriendOpTypeCreateMedia)
+
+	pm.SyncMedia(SyncCreateMediaMsg, createMediaIDs, peer)
+	pm.SyncBlock(SyncCreateMediaBlockMsg, mediaBlockIDs, peer)
 
 	pm.broadcastFriendOplogsCore(toBroadcastLogs)
 
@@ -133,7 +158,12 @@ func (pm *ProtocolManager) SetNewestFriendOplog(oplog *pkgservice.BaseOplog) (er
 	case FriendOpTypeDeleteFriend:
 	case FriendOpTypeCreateMessage:
 		isNewer, err = pm.setNewestCreateMessageLog(oplog)
+	case FriendOpTypeUpdateMessage:
+		isNewer, err = pm.setNewestUpdateMessageLog(oplog)
+	case FriendOpTypeDeleteMessage:
+		isNewer, err = pm.setNewestDeleteMessageLog(oplog)
 	case FriendOpTypeCreateMedia:
+		isNewer, err = pm.setNewestCreateMediaLog(oplog)
 	}
 
 	oplog.IsNewer = isNewer
@@ -151,7 +181,12 @@ func (pm *ProtocolManager) HandleFailedFriendOplog(oplog *pkgservice.BaseOplog)
 	case FriendOpTypeDeleteFriend:
 	case FriendOpTypeCreateMessage:
 		err = pm.handleFailedCreateMessageLog(oplog)
+	case FriendOpTypeUpdateMessage:
+		err = pm.handleFailedUpdateMessageLog(oplog)
+	case FriendOpTypeDeleteMessage:
+		err = pm.handleFailedDeleteMessageLog(oplog)
 	case FriendOpTypeCreateMedia:
+		err = pm.handleFailedCreateMediaLog(oplog)
 	}
 
 	return
@@ -172,7 +207,12 @@ func (pm *ProtocolManager) HandleFailedValidFriendOplog(oplog *pkgservice.BaseOp
 	case FriendOpTypeDeleteFriend:
 	case FriendOpTypeCreateMessage:
 		err = pm.handleFailedValidCreateMessageLog(oplog, info)
+	case FriendOpTypeUpdateMessage:
+		err = pm.handleFailedValidUpdateMessageLog(oplog, info)
+	case FriendOpTypeDeleteMessage:
+		err = pm.handleFailedValidDeleteMessageLog(oplog, info)
 	case FriendOpTypeCreateMedia:
+		err = pm.handleFailedValidCreateMediaLog(oplog, info)
 	}
 
 	return
@@ -190,5 +230,12 @@ func (pm *ProtocolManager) postprocessFailedValidFriendOplogs(processInfo pkgser
 func (pm *ProtocolManager) postsyncFriendOplogs(peer *pkgservice.PttPeer) (err error) {
 	err = pm.SyncPendingFriendOplog(peer)
 
+	if peer != nil {
+		pm.SyncMessageReceipt(peer)
+		pm.ForceSyncMediaChunk(peer)
+		pm.syncPresence(peer)
+		pm.syncDevices(peer)
+	}
+
 	return
 }
@@ -169,6 +169,8 @@ func (pm *ProtocolManager) HandleSyncNewFriendOplogAck(dataBytes []byte, peer *p
 		pm.SetFriendDB,
 		pm.HandleFriendOplogs,
 		pm.postsyncFriendOplogs,
+
+		pm.friendOplogMerkle,
 	)
 }
 
@@ -115,6 +115,8 @@ func (pm *ProtocolManager) HandleInitFriendInfo(dataBytes []byte, peer *pkgservi
 
 	pm.postcreateFriend(f)
 
+	pm.PostEntityStatusEvent()
+
 	// ack
 	err = pm.InitFriendInfoAck(peer)
 
@@ -142,7 +144,7 @@ func (pm *ProtocolManager) postcreateFriend(entity pkgservice.Entity) error {
 
 	f := entity.(*Friend)
 
-	oplog, err := pkgservice.NewPttOplog(entity.GetID(), ts, f.FriendID, pkgservice.PttOpTypeCreateFriend, pkgservice.PttOpTypeCreateFriend, myID)
+	oplog, err := pm.Router().NewPttOplog(entity.GetID(), ts, f.FriendID, pkgservice.PttOpTypeCreateFriend, pkgservice.PttOpTypeCreateFriend, myID)
 	if err != nil {
 		return err
 	}
@@ -151,5 +153,7 @@ func (pm *ProtocolManager) postcreateFriend(entity pkgservice.Entity) error {
 		return err
 	}
 
+	pm.postFriendEvent()
+
 	return nil
 }
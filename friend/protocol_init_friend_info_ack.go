@@ -27,7 +27,7 @@ import (
 )
 
 func NewEmptyApproveJoinFriend() *pkgservice.ApproveJoinEntity {
-	return &pkgservice.ApproveJoinEntity{Entity: NewEmptyFriend()}
+	return &pkgservice.ApproveJoinEntity{Entity: NewEmptyFriend(nil)}
 }
 
 type InitFriendInfoAck struct {
@@ -171,7 +171,7 @@ func (pm *ProtocolManager) HandleInitFriendInfoAckCore(
 
 	myID := pm.Router().GetMyEntity().GetID()
 
-	pttOplog, err := pkgservice.NewPttOplog(f.GetID(), ts, f.FriendID, pkgservice.PttOpTypeCreateFriend, pkgservice.PttOpTypeCreateFriend, myID)
+	pttOplog, err := pm.Router().NewPttOplog(f.GetID(), ts, f.FriendID, pkgservice.PttOpTypeCreateFriend, pkgservice.PttOpTypeCreateFriend, myID)
 	if err != nil {
 		return err
 	}
@@ -180,6 +180,8 @@ func (pm *ProtocolManager) HandleInitFriendInfoAckCore(
 		return err
 	}
 
+	pm.postFriendEvent()
+
 	log.Debug("HandleInitFriendInfoAck: done")
 
 	return nil
@@ -17,6 +17,8 @@
 package friend
 
 import (
+	"sync"
+
 	"github.com/ailabstw/go-pttai-core/common/types"
 	"github.com/ailabstw/go-pttai-core/log"
 	pkgservice "github.com/ailabstw/go-pttai-core/service"
@@ -26,12 +28,23 @@ type ProtocolManager struct {
 	*pkgservice.BaseProtocolManager
 
 	// db
+	db                *friendDB
 	dbFriendLock      *types.LockMap
 	friendOplogMerkle *pkgservice.Merkle
 
 	// message
 	dbMessagePrefix    []byte
 	dbMessageIdxPrefix []byte
+
+	// message receipt
+	lockMessageReceipt sync.Mutex
+
+	// presence
+	presence *presenceState
+
+	// ratchet
+	lockRatchet    sync.Mutex
+	lockStorageKey sync.Mutex
 }
 
 func NewProtocolManager(f *Friend, router pkgservice.Router, svc pkgservice.Service) (*ProtocolManager, error) {
@@ -44,13 +57,15 @@ func NewProtocolManager(f *Friend, router pkgservice.Router, svc pkgservice.Serv
 	entityIDBytes, _ := entityID.MarshalText()
 	entityIDStr := string(entityIDBytes)
 
-	friendOplogMerkle, err := pkgservice.NewMerkle(DBFriendOplogPrefix, DBFriendMerkleOplogPrefix, f.ID, dbFriend, "("+entityIDStr+"/"+svc.Name()+":friend)")
+	friendOplogMerkle, err := pkgservice.NewMerkle(DBFriendOplogPrefix, DBFriendMerkleOplogPrefix, f.ID, f.db.friend, "("+entityIDStr+"/"+svc.Name()+":friend)")
 	if err != nil {
 		return nil, err
 	}
 	pm := &ProtocolManager{
+		db:                f.db,
 		dbFriendLock:      dbFriendLock,
 		friendOplogMerkle: friendOplogMerkle,
+		presence:          newPresenceState(),
 	}
 	b, err := pkgservice.NewBaseProtocolManager(
 		router,
@@ -91,13 +106,15 @@ func NewProtocolManager(f *Friend, router pkgservice.Router, svc pkgservice.Serv
 		f, // entity
 		svc,
 
-		dbFriend, // db
+		f.db.friend, // db
 	)
 	if err != nil {
 		return nil, err
 	}
 	pm.BaseProtocolManager = b
 
+	pm.SetOpRateLimit(ForceSyncFriendOplogByMerkleMsg, pkgservice.ForceSyncOplogByMerkleRateLimit)
+
 	// message
 	pm.dbMessagePrefix = append(DBMessagePrefix, entityID[:]...)
 	pm.dbMessageIdxPrefix = append(DBMessageIdxPrefix, entityID[:]...)
@@ -123,6 +140,20 @@ func (pm *ProtocolManager) Start() error {
 		pkgservice.PMOplogMerkleTreeLoop(pm, pm.friendOplogMerkle)
 	}()
 
+	// ephemeral message
+	syncWG.Add(1)
+	go func() {
+		defer syncWG.Done()
+		pm.ExpireMessageLoop()
+	}()
+
+	// presence
+	syncWG.Add(1)
+	go func() {
+		defer syncWG.Done()
+		pm.PresenceLoop()
+	}()
+
 	return nil
 }
 
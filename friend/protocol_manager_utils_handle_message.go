@@ -88,10 +88,43 @@ func (pm *ProtocolManager) HandleMessage(op pkgservice.OpType, dataBytes []byte,
 	case SyncCreateMessageAckMsg:
 		err = pm.HandleSyncCreateMessageAck(dataBytes, peer)
 	case SyncCreateMessageBlockMsg:
-		err = pm.HandleSyncMessageBlock(dataBytes, peer)
+		err = pm.HandleSyncMessageBlock(dataBytes, peer, SyncCreateMessageBlockAckMsg)
 	case SyncCreateMessageBlockAckMsg:
 		err = pm.HandleSyncCreateMessageBlockAck(dataBytes, peer)
 
+	case SyncUpdateMessageMsg:
+		err = pm.HandleSyncUpdateMessage(dataBytes, peer, SyncUpdateMessageAckMsg)
+	case SyncUpdateMessageAckMsg:
+		err = pm.HandleSyncUpdateMessageAck(dataBytes, peer)
+	case SyncUpdateMessageBlockMsg:
+		err = pm.HandleSyncMessageBlock(dataBytes, peer, SyncUpdateMessageBlockAckMsg)
+	case SyncUpdateMessageBlockAckMsg:
+		err = pm.HandleSyncUpdateMessageBlockAck(dataBytes, peer)
+
+	// message receipt
+	case SyncMessageReceiptMsg:
+		err = pm.HandleSyncMessageReceipt(dataBytes, peer)
+
+	// media
+	case SyncCreateMediaMsg:
+		err = pm.HandleSyncCreateMedia(dataBytes, peer, SyncCreateMediaAckMsg)
+	case SyncCreateMediaAckMsg:
+		err = pm.HandleSyncCreateMediaAck(dataBytes, peer)
+	case SyncCreateMediaBlockMsg:
+		err = pm.HandleSyncMediaBlock(dataBytes, peer, SyncCreateMediaBlockAckMsg)
+	case SyncCreateMediaBlockAckMsg:
+		err = pm.HandleSyncCreateMediaBlockAck(dataBytes, peer)
+
+	// presence
+	case SyncPresenceMsg:
+		err = pm.HandleSyncPresence(dataBytes, peer)
+	case SyncTypingMsg:
+		err = pm.HandleSyncTyping(dataBytes, peer)
+
+	// ratchet
+	case SyncDevicesMsg:
+		err = pm.HandleSyncDevices(dataBytes, peer)
+
 	default:
 		log.Error("invalid op", "op", op, "InitFriendInfoMsg", InitFriendInfoMsg)
 		err = pkgservice.ErrInvalidMsgCode
@@ -0,0 +1,257 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package friend
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+type SyncMessageReceipt struct {
+	Receipts []*MessageReceipt `json:"R"`
+}
+
+/**********
+ * Mark
+ **********/
+
+/*
+MarkMessageDelivered marks the message as delivered to me.
+The receipt is broadcast to the friend and to my other devices.
+*/
+func (pm *ProtocolManager) MarkMessageDelivered(message *Message) error {
+	myID := pm.Router().GetMyEntity().GetID()
+	if reflect.DeepEqual(message.CreatorID, myID) {
+		return nil
+	}
+
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return err
+	}
+
+	receipt := NewMessageReceipt(pm.Entity().GetID(), message.ID, myID, MessageReceiptStatusDelivered, ts)
+
+	return pm.saveAndBroadcastMessageReceipts([]*MessageReceipt{receipt}, nil)
+}
+
+/*
+MarkMessagesRead marks the messages from the friend created no later than ts as read.
+Messages are checked from the newest one until reaching a message already read.
+*/
+func (pm *ProtocolManager) MarkMessagesRead(ts types.Timestamp) error {
+	myID := pm.Router().GetMyEntity().GetID()
+	entityID := pm.Entity().GetID()
+
+	obj := NewEmptyMessage()
+	pm.SetMessageDB(obj)
+
+	iter, err := obj.GetObjIterWithObj(nil, pttdb.ListOrderPrev, false)
+	if err != nil {
+		return err
+	}
+	defer iter.Release()
+
+	iterFunc := pttdb.GetFuncIter(iter, pttdb.ListOrderPrev)
+
+	receipts := make([]*MessageReceipt, 0)
+	for iterFunc() {
+		message := NewEmptyMessage()
+		err = message.Unmarshal(iter.Value())
+		if err != nil {
+			continue
+		}
+
+		if ts.IsLess(message.CreateTS) {
+			continue
+		}
+		if reflect.DeepEqual(message.CreatorID, myID) || message.Status != types.StatusAlive {
+			continue
+		}
+
+		receipt := NewMessageReceipt(entityID, message.ID, myID, MessageReceiptStatusSent, types.ZeroTimestamp)
+		err = receipt.Get(pm.db.friendCore)
+		if err == nil && receipt.Status == MessageReceiptStatusRead {
+			break
+		}
+
+		receipts = append(receipts, NewMessageReceipt(entityID, message.ID, myID, MessageReceiptStatusRead, ts))
+	}
+
+	return pm.saveAndBroadcastMessageReceipts(receipts, nil)
+}
+
+/**********
+ * Get
+ **********/
+
+func (pm *ProtocolManager) GetMessageReceipts(messageID *types.PttID) ([]*MessageReceipt, error) {
+	return getMessageReceiptList(pm.db.friendCore, pm.Entity().GetID(), messageID)
+}
+
+/*
+GetMessageReceiptStatus returns the receipt-status of the message from the user other than the creator.
+*/
+func (pm *ProtocolManager) GetMessageReceiptStatus(message *Message) MessageReceiptStatus {
+	f := pm.Entity().(*Friend)
+	myID := pm.Router().GetMyEntity().GetID()
+
+	userID := myID
+	if reflect.DeepEqual(message.CreatorID, myID) {
+		userID = f.FriendID
+	}
+
+	receipt := NewMessageReceipt(f.ID, message.ID, userID, MessageReceiptStatusSent, types.ZeroTimestamp)
+	err := receipt.Get(pm.db.friendCore)
+	if err != nil {
+		return MessageReceiptStatusSent
+	}
+
+	return receipt.Status
+}
+
+/**********
+ * Sync
+ **********/
+
+/*
+SyncMessageReceipt sends the receipts of the latest messages to the peer.
+*/
+func (pm *ProtocolManager) SyncMessageReceipt(peer *pkgservice.PttPeer) error {
+	messages, err := pm.GetMessageList(nil, NSyncMessageReceipts, pttdb.ListOrderPrev, false)
+	if err != nil {
+		return err
+	}
+
+	entityID := pm.Entity().GetID()
+	receipts := make([]*MessageReceipt, 0)
+	for _, message := range messages {
+		eachReceipts, err := getMessageReceiptList(pm.db.friendCore, entityID, message.ID)
+		if err != nil {
+			continue
+		}
+		receipts = append(receipts, eachReceipts...)
+	}
+
+	if len(receipts) == 0 {
+		return nil
+	}
+
+	data := &SyncMessageReceipt{Receipts: receipts}
+
+	return pm.SendDataToPeer(SyncMessageReceiptMsg, data, peer)
+}
+
+func (pm *ProtocolManager) HandleSyncMessageReceipt(dataBytes []byte, peer *pkgservice.PttPeer) error {
+	data := &SyncMessageReceipt{}
+	err := json.Unmarshal(dataBytes, data)
+	if err != nil {
+		return err
+	}
+
+	f := pm.Entity().(*Friend)
+	myID := pm.Router().GetMyEntity().GetID()
+
+	receipts := make([]*MessageReceipt, 0, len(data.Receipts))
+	for _, receipt := range data.Receipts {
+		if !receipt.IsValid() || !reflect.DeepEqual(receipt.FriendID, f.ID) {
+			continue
+		}
+
+		// only the user-self is able to ack the message, while my devices are able to relay the receipts of the friend.
+		isValidUser := reflect.DeepEqual(receipt.UserID, peer.UserID) ||
+			peer.PeerType == pkgservice.PeerTypeMe && reflect.DeepEqual(receipt.UserID, f.FriendID)
+		if !isValidUser || !reflect.DeepEqual(receipt.UserID, myID) && !reflect.DeepEqual(receipt.UserID, f.FriendID) {
+			log.Warn("HandleSyncMessageReceipt: invalid user", "userID", receipt.UserID, "peer", peer)
+			continue
+		}
+
+		receipts = append(receipts, receipt)
+	}
+
+	return pm.saveAndBroadcastMessageReceipts(receipts, peer)
+}
+
+/*
+saveAndBroadcastMessageReceipts merges the receipts into the db and
+broadcasts the changed ones to the peers except the one the receipts come from.
+*/
+func (pm *ProtocolManager) saveAndBroadcastMessageReceipts(receipts []*MessageReceipt, fromPeer *pkgservice.PttPeer) error {
+	if len(receipts) == 0 {
+		return nil
+	}
+
+	changedReceipts, err := pm.saveMessageReceipts(receipts)
+	if err != nil {
+		return err
+	}
+	if len(changedReceipts) == 0 {
+		return nil
+	}
+
+	// broadcast
+	var peerList []*pkgservice.PttPeer
+	if fromPeer == nil {
+		peerList = pm.Peers().PeerList(false)
+	} else {
+		peerList = pm.Peers().MePeerList(false)
+	}
+
+	toSendPeers := make([]*pkgservice.PttPeer, 0, len(peerList))
+	for _, peer := range peerList {
+		if peer == fromPeer {
+			continue
+		}
+		toSendPeers = append(toSendPeers, peer)
+	}
+
+	data := &SyncMessageReceipt{Receipts: changedReceipts}
+
+	return pm.SendDataToPeers(SyncMessageReceiptMsg, data, toSendPeers)
+}
+
+func (pm *ProtocolManager) saveMessageReceipts(receipts []*MessageReceipt) ([]*MessageReceipt, error) {
+	pm.lockMessageReceipt.Lock()
+	defer pm.lockMessageReceipt.Unlock()
+
+	changedReceipts := make([]*MessageReceipt, 0, len(receipts))
+	for _, receipt := range receipts {
+		origReceipt := NewMessageReceipt(receipt.FriendID, receipt.MessageID, receipt.UserID, MessageReceiptStatusSent, types.ZeroTimestamp)
+		err := origReceipt.Get(pm.db.friendCore)
+		if err != nil && err != pttdb.ErrNotFound {
+			return nil, err
+		}
+
+		if !origReceipt.Merge(receipt) {
+			continue
+		}
+
+		err = origReceipt.Save(pm.db.friendCore)
+		if err != nil {
+			return nil, err
+		}
+
+		changedReceipts = append(changedReceipts, origReceipt)
+	}
+
+	return changedReceipts, nil
+}
@@ -0,0 +1,270 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package friend
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+/*
+SyncPresence is the presence heartbeat.
+
+The presence and the typing are ephemeral. They are sent directly to the devices of the friend,
+and are neither saved as the oplogs nor tracked in the merkle-tree.
+*/
+type SyncPresence struct {
+	Status PresenceStatus  `json:"S"`
+	TS     types.Timestamp `json:"T"`
+}
+
+/*
+SyncTyping is the typing start / stop signal.
+*/
+type SyncTyping struct {
+	IsTyping bool            `json:"IT"`
+	TS       types.Timestamp `json:"T"`
+}
+
+func (pm *ProtocolManager) presenceSetting() *PresenceSetting {
+	return pm.Entity().Service().SPM().(*ServiceProtocolManager).GetPresenceSetting()
+}
+
+/*
+friendPeerList returns the peers of the devices of the friend.
+*/
+func (pm *ProtocolManager) friendPeerList() []*pkgservice.PttPeer {
+	f := pm.Entity().(*Friend)
+
+	peerList := pm.Peers().PeerList(false)
+
+	friendPeers := make([]*pkgservice.PttPeer, 0, len(peerList))
+	for _, peer := range peerList {
+		if !reflect.DeepEqual(peer.UserID, f.FriendID) {
+			continue
+		}
+		friendPeers = append(friendPeers, peer)
+	}
+
+	return friendPeers
+}
+
+/**********
+ * Loop
+ **********/
+
+func (pm *ProtocolManager) PresenceLoop() error {
+	heartbeatTicker := time.NewTicker(HeartbeatPresenceSeconds)
+	defer heartbeatTicker.Stop()
+
+	expireTicker := time.NewTicker(ExpireTypingSeconds / 2)
+	defer expireTicker.Stop()
+
+loop:
+	for {
+		select {
+		case <-heartbeatTicker.C:
+			pm.SendPresence(nil)
+		case <-expireTicker.C:
+			pm.ExpirePresence()
+		case <-pm.QuitSync():
+			log.Debug("PresenceLoop: QuitSync", "entity", pm.Entity().GetID())
+			break loop
+		}
+	}
+
+	return nil
+}
+
+/*
+ExpirePresence posts the events if the friend becomes offline or stops typing without the signals.
+*/
+func (pm *ProtocolManager) ExpirePresence() {
+	isPresenceChanged, isTypingChanged := pm.presence.Expire(time.Now())
+
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return
+	}
+
+	if isPresenceChanged {
+		pm.postPresenceEvent(PresenceStatusOffline, ts)
+	}
+	if isTypingChanged {
+		pm.postTypingEvent(false, ts)
+	}
+}
+
+/**********
+ * Send
+ **********/
+
+/*
+SendPresence sends the heartbeat to the devices of the friend (only to the peer if the peer is set).
+Nothing is sent if my presence is hidden, and the friend sees me offline after ExpirePresenceSeconds.
+*/
+func (pm *ProtocolManager) SendPresence(peer *pkgservice.PttPeer) error {
+	if pm.presenceSetting().IsHidePresence {
+		return nil
+	}
+
+	return pm.sendPresence(PresenceStatusOnline, peer)
+}
+
+func (pm *ProtocolManager) sendPresence(status PresenceStatus, peer *pkgservice.PttPeer) error {
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return err
+	}
+
+	data := &SyncPresence{
+		Status: status,
+		TS:     ts,
+	}
+
+	if peer != nil {
+		return pm.SendDataToPeer(SyncPresenceMsg, data, peer)
+	}
+
+	peerList := pm.friendPeerList()
+	if len(peerList) == 0 {
+		return nil
+	}
+
+	return pm.SendDataToPeers(SyncPresenceMsg, data, peerList)
+}
+
+/*
+syncPresence sends my presence to the newly synced peer if the peer is the device of the friend.
+*/
+func (pm *ProtocolManager) syncPresence(peer *pkgservice.PttPeer) error {
+	f := pm.Entity().(*Friend)
+	if !reflect.DeepEqual(peer.UserID, f.FriendID) {
+		return nil
+	}
+
+	return pm.SendPresence(peer)
+}
+
+/*
+SendTyping sends the typing start / stop to the devices of the friend.
+The client is expected to resend the typing-start within ExpireTypingSeconds while typing.
+*/
+func (pm *ProtocolManager) SendTyping(isTyping bool) error {
+	if pm.presenceSetting().IsHideTyping {
+		return nil
+	}
+
+	return pm.sendTyping(isTyping)
+}
+
+func (pm *ProtocolManager) sendTyping(isTyping bool) error {
+	peerList := pm.friendPeerList()
+	if len(peerList) == 0 {
+		return nil
+	}
+
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return err
+	}
+
+	data := &SyncTyping{
+		IsTyping: isTyping,
+		TS:       ts,
+	}
+
+	return pm.SendDataToPeers(SyncTypingMsg, data, peerList)
+}
+
+/**********
+ * Handle
+ **********/
+
+func (pm *ProtocolManager) HandleSyncPresence(dataBytes []byte, peer *pkgservice.PttPeer) error {
+	data := &SyncPresence{}
+	err := json.Unmarshal(dataBytes, data)
+	if err != nil {
+		return err
+	}
+
+	if data.Status < PresenceStatusOffline || data.Status >= NPresenceStatus {
+		return ErrInvalidPresence
+	}
+
+	f := pm.Entity().(*Friend)
+	if !reflect.DeepEqual(peer.UserID, f.FriendID) {
+		log.Warn("HandleSyncPresence: not the friend", "userID", peer.UserID, "peer", peer)
+		return nil
+	}
+
+	// use the local time for the expiration, not to be affected by the clock of the peer.
+	isChanged := pm.presence.SetNode(peer.GetID(), data.Status, time.Now())
+	if !isChanged {
+		return nil
+	}
+
+	pm.postPresenceEvent(data.Status, data.TS)
+
+	if data.Status == PresenceStatusOffline && pm.presence.SetTyping(false, time.Now()) {
+		pm.postTypingEvent(false, data.TS)
+	}
+
+	return nil
+}
+
+func (pm *ProtocolManager) HandleSyncTyping(dataBytes []byte, peer *pkgservice.PttPeer) error {
+	data := &SyncTyping{}
+	err := json.Unmarshal(dataBytes, data)
+	if err != nil {
+		return err
+	}
+
+	f := pm.Entity().(*Friend)
+	if !reflect.DeepEqual(peer.UserID, f.FriendID) {
+		log.Warn("HandleSyncTyping: not the friend", "userID", peer.UserID, "peer", peer)
+		return nil
+	}
+
+	// typing implies the device is online.
+	now := time.Now()
+	if data.IsTyping && pm.presence.SetNode(peer.GetID(), PresenceStatusOnline, now) {
+		pm.postPresenceEvent(PresenceStatusOnline, data.TS)
+	}
+
+	isChanged := pm.presence.SetTyping(data.IsTyping, now)
+	if !isChanged {
+		return nil
+	}
+
+	pm.postTypingEvent(data.IsTyping, data.TS)
+
+	return nil
+}
+
+/**********
+ * Get
+ **********/
+
+func (pm *ProtocolManager) GetPresence() (PresenceStatus, bool) {
+	return pm.presence.Status(), pm.presence.IsTyping()
+}
@@ -0,0 +1,873 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package friend
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/ailabstw/go-pttai-core/common"
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+/*
+SyncDevices announces the devices of my user and the signed prekey of the sending device.
+
+The devices of the friend are the targets of the ratchet. The messages are not able to be
+sent until at least one of the devices of the friend announces the prekey.
+*/
+type SyncDevices struct {
+	NodeIDs []*discover.NodeID `json:"N"`
+	Prekey  *SignedPrekey      `json:"P,omitempty"`
+}
+
+/**********
+ * Devices
+ **********/
+
+func (pm *ProtocolManager) marshalFriendDevicesKey() ([]byte, error) {
+	return common.Concat([][]byte{DBFriendDevicesPrefix, pm.Entity().GetID()[:]})
+}
+
+/*
+GetFriendDevices gets the devices announced by the friend.
+*/
+func (pm *ProtocolManager) GetFriendDevices() ([]*discover.NodeID, error) {
+	key, err := pm.marshalFriendDevicesKey()
+	if err != nil {
+		return nil, err
+	}
+
+	theBytes, err := pm.db.meta.Get(key)
+	if err != nil {
+		// not announced yet.
+		return nil, nil
+	}
+
+	nodeIDs := make([]*discover.NodeID, 0)
+	err = json.Unmarshal(theBytes, &nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodeIDs, nil
+}
+
+func (pm *ProtocolManager) saveFriendDevices(nodeIDs []*discover.NodeID) error {
+	key, err := pm.marshalFriendDevicesKey()
+	if err != nil {
+		return err
+	}
+
+	marshaled, err := json.Marshal(nodeIDs)
+	if err != nil {
+		return err
+	}
+
+	return pm.db.meta.Put(key, marshaled)
+}
+
+func (pm *ProtocolManager) marshalDevicePrekeyKey(nodeID *discover.NodeID) ([]byte, error) {
+	return common.Concat([][]byte{DBDevicePrekeyPrefix, pm.Entity().GetID()[:], nodeID[:]})
+}
+
+/*
+getDevicePrekey gets the prekey announced by the device of the friend or my other device.
+*/
+func (pm *ProtocolManager) getDevicePrekey(nodeID *discover.NodeID) (*SignedPrekey, error) {
+	key, err := pm.marshalDevicePrekeyKey(nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	theBytes, err := pm.db.meta.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	prekey := &SignedPrekey{}
+	err = json.Unmarshal(theBytes, prekey)
+	if err != nil {
+		return nil, err
+	}
+
+	return prekey, nil
+}
+
+/*
+saveDevicePrekey saves the prekey of the device if the prekey is newer than the saved one.
+*/
+func (pm *ProtocolManager) saveDevicePrekey(prekey *SignedPrekey) error {
+	origPrekey, err := pm.getDevicePrekey(prekey.NodeID)
+	if err == nil && !origPrekey.TS.IsLess(prekey.TS) {
+		return nil
+	}
+
+	key, err := pm.marshalDevicePrekeyKey(prekey.NodeID)
+	if err != nil {
+		return err
+	}
+
+	marshaled, err := json.Marshal(prekey)
+	if err != nil {
+		return err
+	}
+
+	return pm.db.meta.Put(key, marshaled)
+}
+
+/*
+syncDevices sends my devices and the current prekey of my device to the newly synced peer
+if the peer is the device of the friend or my other device.
+*/
+func (pm *ProtocolManager) syncDevices(peer *pkgservice.PttPeer) error {
+	f := pm.Entity().(*Friend)
+	myID := pm.Router().GetMyEntity().GetID()
+	if !reflect.DeepEqual(peer.UserID, f.FriendID) && !reflect.DeepEqual(peer.UserID, myID) {
+		return nil
+	}
+
+	myRouter, ok := pm.Router().(pkgservice.MyRouter)
+	if !ok {
+		return ErrInvalidRatchet
+	}
+
+	nodeIDs := pm.Router().GetMyEntity().GetMyNodeIDs()
+	if len(nodeIDs) == 0 {
+		return nil
+	}
+
+	spm := pm.Entity().Service().SPM().(*ServiceProtocolManager)
+	currentPrekey, err := spm.currentPrekey()
+	if err != nil {
+		return err
+	}
+
+	key, err := crypto.ToECDSA(currentPrekey.Key)
+	if err != nil {
+		return err
+	}
+
+	signedPrekey, err := signPrekey(myRouter.MyNodeKey(), key, currentPrekey.TS)
+	if err != nil {
+		return err
+	}
+
+	data := &SyncDevices{
+		NodeIDs: nodeIDs,
+		Prekey:  signedPrekey,
+	}
+
+	return pm.SendDataToPeer(SyncDevicesMsg, data, peer)
+}
+
+func (pm *ProtocolManager) HandleSyncDevices(dataBytes []byte, peer *pkgservice.PttPeer) error {
+	data := &SyncDevices{}
+	err := json.Unmarshal(dataBytes, data)
+	if err != nil {
+		return err
+	}
+
+	f := pm.Entity().(*Friend)
+	myID := pm.Router().GetMyEntity().GetID()
+	isFriend := reflect.DeepEqual(peer.UserID, f.FriendID)
+	if !isFriend && !reflect.DeepEqual(peer.UserID, myID) {
+		log.Warn("HandleSyncDevices: not the friend", "userID", peer.UserID, "peer", peer)
+		return nil
+	}
+
+	if data.Prekey != nil {
+		if !reflect.DeepEqual(data.Prekey.NodeID, peer.GetID()) {
+			return ErrInvalidPrekey
+		}
+
+		err = data.Prekey.Verify()
+		if err != nil {
+			return err
+		}
+
+		err = pm.saveDevicePrekey(data.Prekey)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !isFriend {
+		return nil
+	}
+
+	// the sending peer is always one of the devices.
+	nodeIDs := make([]*discover.NodeID, 0, len(data.NodeIDs)+1)
+	nodeIDs = append(nodeIDs, peer.GetID())
+	for _, nodeID := range data.NodeIDs {
+		if nodeID == nil || reflect.DeepEqual(nodeID, peer.GetID()) {
+			continue
+		}
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+
+	return pm.saveFriendDevices(nodeIDs)
+}
+
+/*
+ratchetTargets returns the prekeys of the devices of the friend and my other devices.
+The devices without the valid prekey are skipped. Returns ErrNoFriendDevices
+if none of the devices of the friend announces the valid prekey (not synced yet,
+offline longer than the prekey expires, or not upgraded).
+*/
+func (pm *ProtocolManager) ratchetTargets() ([]*SignedPrekey, error) {
+	friendDevices, err := pm.GetFriendDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := types.GetTimestamp()
+	if err != nil {
+		return nil, err
+	}
+
+	myNodeID := pm.Router().MyNodeID()
+	myDevices := pm.Router().GetMyEntity().GetMyNodeIDs()
+
+	isFriendDevice := false
+	targets := make([]*SignedPrekey, 0, len(friendDevices)+len(myDevices))
+	targetMap := make(map[discover.NodeID]bool)
+	for i, nodeID := range append(friendDevices, myDevices...) {
+		if *nodeID == *myNodeID || targetMap[*nodeID] {
+			continue
+		}
+		targetMap[*nodeID] = true
+
+		prekey, err := pm.getDevicePrekey(nodeID)
+		if err != nil {
+			continue
+		}
+		if now.Ts-prekey.TS.Ts >= RenewPrekeySeconds+ExpirePrekeySeconds {
+			continue
+		}
+
+		if i < len(friendDevices) {
+			isFriendDevice = true
+		}
+		targets = append(targets, prekey)
+	}
+
+	if !isFriendDevice {
+		return nil, ErrNoFriendDevices
+	}
+
+	return targets, nil
+}
+
+/**********
+ * Chain
+ **********/
+
+func (pm *ProtocolManager) marshalRatchetKey(isSend bool, nodeID *discover.NodeID, ephemeral []byte) ([]byte, error) {
+	dir := []byte{0}
+	if isSend {
+		dir[0] = 1
+	}
+
+	return common.Concat([][]byte{DBRatchetPrefix, pm.Entity().GetID()[:], dir, nodeID[:], ephemeral})
+}
+
+func (pm *ProtocolManager) getRatchetChain(key []byte) (*ratchetChain, error) {
+	theBytes, err := pm.db.key.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := &ratchetChain{}
+	err = json.Unmarshal(theBytes, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	return chain, nil
+}
+
+/*
+loadSendingChain loads the chain to the device. A new chain is started
+if not exists or the device renews the prekey.
+Assuming lockRatchet is locked.
+*/
+func (pm *ProtocolManager) loadSendingChain(prekey *SignedPrekey) (*ratchetChain, error) {
+	key, err := pm.marshalRatchetKey(true, prekey.NodeID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	chain, err := pm.getRatchetChain(key)
+	if err == nil && chain.PrekeyTS.IsEqual(prekey.TS) {
+		return chain, nil
+	}
+	if err != nil && err != pttdb.ErrNotFound {
+		return nil, err
+	}
+
+	myRouter, ok := pm.Router().(pkgservice.MyRouter)
+	if !ok {
+		return nil, ErrInvalidRatchet
+	}
+
+	return newSendingChain(myRouter.MyNodeKey(), prekey, pm.Entity().GetID(), myRouter.MyNodeID(), prekey.NodeID)
+}
+
+/*
+loadReceivingChain loads the chain from the device with the ephemeral public-key in the ratchet-key.
+The chain is derived with my prekey if not exists.
+Assuming lockRatchet is locked.
+*/
+func (pm *ProtocolManager) loadReceivingChain(nodeID *discover.NodeID, ratchetKey *RatchetKey) (*ratchetChain, error) {
+	key, err := pm.marshalRatchetKey(false, nodeID, ratchetKey.E)
+	if err != nil {
+		return nil, err
+	}
+
+	chain, err := pm.getRatchetChain(key)
+	if err == nil {
+		return chain, nil
+	}
+	if err != pttdb.ErrNotFound {
+		return nil, err
+	}
+
+	pm.expireReceivingChains(nodeID)
+
+	spm := pm.Entity().Service().SPM().(*ServiceProtocolManager)
+	myPrekey, err := spm.getPrekey(ratchetKey.P)
+	if err != nil {
+		return nil, err
+	}
+
+	prekeyKey, err := crypto.ToECDSA(myPrekey.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReceivingChain(prekeyKey, ratchetKey.P, ratchetKey.E, pm.Entity().GetID(), nodeID, pm.Router().MyNodeID())
+}
+
+func (pm *ProtocolManager) saveRatchetChain(isSend bool, nodeID *discover.NodeID, chain *ratchetChain) error {
+	var ephemeral []byte
+	if !isSend {
+		ephemeral = chain.Ephemeral
+	}
+
+	key, err := pm.marshalRatchetKey(isSend, nodeID, ephemeral)
+	if err != nil {
+		return err
+	}
+
+	marshaled, err := json.Marshal(chain)
+	if err != nil {
+		return err
+	}
+
+	return pm.db.key.Put(key, marshaled)
+}
+
+/*
+expireReceivingChains erases the receiving chains from the device derived from the expired prekeys.
+Assuming lockRatchet is locked.
+*/
+func (pm *ProtocolManager) expireReceivingChains(nodeID *discover.NodeID) error {
+	now, err := types.GetTimestamp()
+	if err != nil {
+		return err
+	}
+
+	prefix, err := pm.marshalRatchetKey(false, nodeID, nil)
+	if err != nil {
+		return err
+	}
+
+	iter, err := pm.db.key.NewIteratorWithPrefix(nil, prefix, pttdb.ListOrderNext)
+	if err != nil {
+		return err
+	}
+
+	keys := make([][]byte, 0)
+	for iter.Next() {
+		chain := &ratchetChain{}
+		err = json.Unmarshal(iter.Value(), chain)
+		if err == nil && now.Ts-chain.PrekeyTS.Ts < RenewPrekeySeconds+ExpirePrekeySeconds {
+			continue
+		}
+		keys = append(keys, common.CloneBytes(iter.Key()))
+	}
+	iter.Release()
+
+	for _, key := range keys {
+		pm.db.key.Delete(key)
+	}
+
+	return nil
+}
+
+/**********
+ * Local Message
+ **********/
+
+/*
+localMessage is the content of the message in the ratchet kept in the device.
+The content is re-encrypted with the local storage-key, so the content-key is not kept.
+
+Ratchet is kept if the message is not able to be opened yet (ex: the content-blocks or the chain
+are not synced yet), and the message is re-opened with Ratchet when read.
+Both Blocks and Ratchet are empty if the message is in the ratchet but never able to be opened in the device.
+*/
+type localMessage struct {
+	Blocks  []*pkgservice.ContentBlock `json:"B,omitempty"`
+	Ratchet *RatchetHeader             `json:"r,omitempty"`
+}
+
+func (m *localMessage) isPending() bool {
+	return len(m.Blocks) == 0 && m.Ratchet != nil
+}
+
+func (pm *ProtocolManager) marshalLocalMessageKey(blockInfoID *types.PttID) ([]byte, error) {
+	return common.Concat([][]byte{DBLocalMessagePrefix, pm.Entity().GetID()[:], blockInfoID[:]})
+}
+
+/*
+storageKey gets the storage-key of the friend kept in the device.
+The storage-key is generated if not exists, and is erased in cleanRatchet.
+*/
+func (pm *ProtocolManager) storageKey() ([]byte, error) {
+	key, err := common.Concat([][]byte{DBStorageKeyPrefix, pm.Entity().GetID()[:]})
+	if err != nil {
+		return nil, err
+	}
+
+	pm.lockStorageKey.Lock()
+	defer pm.lockStorageKey.Unlock()
+
+	storageKey, err := pm.db.key.Get(key)
+	if err == nil {
+		return storageKey, nil
+	}
+	if err != pttdb.ErrNotFound {
+		return nil, err
+	}
+
+	storageKey, err = newStorageKey()
+	if err != nil {
+		return nil, err
+	}
+
+	err = pm.db.key.Put(key, storageKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return storageKey, nil
+}
+
+/*
+saveLocalMessage encrypts the content-blocks with the storage-key and keeps them in the device.
+*/
+func (pm *ProtocolManager) saveLocalMessage(objID *types.PttID, blockInfoID *types.PttID, contentBlocks []*pkgservice.ContentBlock) error {
+	key, err := pm.marshalLocalMessageKey(blockInfoID)
+	if err != nil {
+		return err
+	}
+
+	msg := &localMessage{}
+	if len(contentBlocks) != 0 {
+		storageKey, err := pm.storageKey()
+		if err != nil {
+			return err
+		}
+
+		msg.Blocks = make([]*pkgservice.ContentBlock, len(contentBlocks))
+		for i, contentBlock := range contentBlocks {
+			buf, err := encryptMessage(storageKey, objID, contentBlock.Buf)
+			if err != nil {
+				return err
+			}
+			msg.Blocks[i] = pkgservice.NewContentBlock(contentBlock.BlockID, buf)
+		}
+	}
+
+	return pm.putLocalMessage(key, msg)
+}
+
+/*
+savePendingMessage keeps the ratchet-header of the message not able to be opened yet.
+*/
+func (pm *ProtocolManager) savePendingMessage(blockInfoID *types.PttID, header *RatchetHeader) error {
+	key, err := pm.marshalLocalMessageKey(blockInfoID)
+	if err != nil {
+		return err
+	}
+
+	return pm.putLocalMessage(key, &localMessage{Ratchet: header})
+}
+
+func (pm *ProtocolManager) putLocalMessage(key []byte, msg *localMessage) error {
+	marshaled, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return pm.db.key.Put(key, marshaled)
+}
+
+/*
+getLocalMessage gets the content kept in the device.
+Returns isRatchet as false if the message is not in the ratchet.
+*/
+func (pm *ProtocolManager) getLocalMessage(blockInfoID *types.PttID) (*localMessage, bool, error) {
+	key, err := pm.marshalLocalMessageKey(blockInfoID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	theBytes, err := pm.db.key.Get(key)
+	if err == pttdb.ErrNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	msg := &localMessage{}
+	err = json.Unmarshal(theBytes, msg)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return msg, true, nil
+}
+
+/*
+removeLocalMessage erases the content of the deleted or expired message kept in the device.
+*/
+func (pm *ProtocolManager) removeLocalMessage(blockInfoID *types.PttID) error {
+	key, err := pm.marshalLocalMessageKey(blockInfoID)
+	if err != nil {
+		return err
+	}
+
+	return pm.db.key.Delete(key)
+}
+
+/**********
+ * Seal / Open
+ **********/
+
+/*
+splitMessageBlocks encrypts the message with a new content-key and splits the message to the content-blocks.
+The content-key is wrapped for the target devices and erased, and the message is kept in the device
+with the storage-key. The media are not in the ratchet.
+
+The message is never refused: if none of the devices of the friend has the valid prekey,
+the message is not in the ratchet, and is protected by the op-key of the friend as the other objects.
+*/
+func (pm *ProtocolManager) splitMessageBlocks(objID *types.PttID, msg [][]byte) (*types.PttID, [][][]byte, *RatchetHeader, error) {
+	targets, err := pm.ratchetTargets()
+	if err == ErrNoFriendDevices {
+		log.Warn("splitMessageBlocks: no friend devices with the valid prekey, not in the ratchet", "obj", objID)
+		blockID, blockHashs, err := pm.SplitContentBlocks(nil, objID, msg, NFirstLineInBlock)
+		return blockID, blockHashs, nil, err
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	contentKey, err := newContentKey()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	encMsg, err := encryptMessage(contentKey, objID, msg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	blockID, blockHashs, err := pm.SplitContentBlocks(nil, objID, encMsg, NFirstLineInBlock)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	header, err := pm.sealContentKey(blockID, contentKey, targets)
+	log.Debug("splitMessageBlocks: after sealContentKey", "obj", objID, "targets", len(targets), "e", err)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	err = pm.saveLocalMessage(objID, blockID, splitLines(msg))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return blockID, blockHashs, header, nil
+}
+
+/*
+splitLines splits the message to the content-blocks the same as SplitContentBlocks.
+*/
+func splitLines(msg [][]byte) []*pkgservice.ContentBlock {
+	contentBlocks := make([]*pkgservice.ContentBlock, 0)
+
+	nLineInBlock := NFirstLineInBlock
+	for blockID := uint32(0); len(msg) != 0; blockID++ {
+		lenBuf := common.MinInt(nLineInBlock, len(msg))
+		contentBlocks = append(contentBlocks, pkgservice.NewContentBlock(blockID, msg[:lenBuf]))
+		msg = msg[lenBuf:]
+
+		nLineInBlock = pkgservice.NLineInBlock
+	}
+
+	return contentBlocks
+}
+
+/*
+sealContentKey wraps the content-key for each of the target devices with the next message-key of the sending chain.
+*/
+func (pm *ProtocolManager) sealContentKey(blockInfoID *types.PttID, contentKey []byte, targets []*SignedPrekey) (*RatchetHeader, error) {
+	myNodeID := pm.Router().MyNodeID()
+
+	pm.lockRatchet.Lock()
+	defer pm.lockRatchet.Unlock()
+
+	header := &RatchetHeader{
+		From: myNodeID,
+		Keys: make([]*RatchetKey, 0, len(targets)),
+	}
+
+	for _, prekey := range targets {
+		nodeID := prekey.NodeID
+		chain, err := pm.loadSendingChain(prekey)
+		if err != nil {
+			log.Warn("sealContentKey: unable to load chain", "nodeID", nodeID, "e", err)
+			continue
+		}
+
+		n, messageKey := chain.next()
+		sealed, err := sealContentKey(messageKey, contentKey, ratchetAD(blockInfoID, myNodeID, nodeID))
+		if err != nil {
+			return nil, err
+		}
+
+		err = pm.saveRatchetChain(true, nodeID, chain)
+		if err != nil {
+			return nil, err
+		}
+
+		header.Keys = append(header.Keys, &RatchetKey{To: nodeID, N: n, Key: sealed, E: chain.Ephemeral, P: chain.PrekeyTS})
+	}
+
+	return header, nil
+}
+
+/*
+openMessage opens the content-key of the received message with the receiving chain,
+decrypts the content-blocks and keeps the content in the device with the storage-key.
+Both the message-key and the content-key are erased once used.
+
+The message is kept as pending and re-opened when read if not able to be opened yet
+(the content-blocks, the chain or the prekey are not synced yet, or the db fails).
+The message is marked as never able to be opened only if the message is not to my device,
+or the content-key is not able to be opened with the existing chain.
+*/
+func (pm *ProtocolManager) openMessage(theObj pkgservice.Object, blockInfoID *types.PttID, header *RatchetHeader) error {
+	if header == nil || header.From == nil {
+		return nil
+	}
+
+	msg, isRatchet, err := pm.getLocalMessage(blockInfoID)
+	if err != nil {
+		return err
+	}
+	if isRatchet && !msg.isPending() {
+		return nil
+	}
+
+	blockInfo := theObj.GetBlockInfo()
+	if blockInfo == nil || !reflect.DeepEqual(blockInfo.ID, blockInfoID) {
+		return pkgservice.ErrInvalidBlock
+	}
+
+	myNodeID := pm.Router().MyNodeID()
+	key := header.keyTo(myNodeID)
+	if key == nil {
+		pm.saveLocalMessage(theObj.GetID(), blockInfoID, nil)
+		return ErrNoMessageKey
+	}
+
+	pm.SetBlockInfoDB(blockInfo, theObj.GetID())
+	contentBlocks, err := pkgservice.GetContentBlockList(blockInfo, 0, true)
+	if err != nil {
+		pm.savePendingMessage(blockInfoID, header)
+		return err
+	}
+
+	pm.lockRatchet.Lock()
+	defer pm.lockRatchet.Unlock()
+
+	chain, err := pm.loadReceivingChain(header.From, key)
+	if err != nil {
+		pm.savePendingMessage(blockInfoID, header)
+		return err
+	}
+
+	// the message-key is already used or out of the window of the chain.
+	messageKey, nextChain, err := chain.messageKey(key.N)
+	if err != nil {
+		pm.saveLocalMessage(theObj.GetID(), blockInfoID, nil)
+		return err
+	}
+
+	contentKey, err := openContentKey(messageKey, key.Key, ratchetAD(blockInfoID, header.From, myNodeID))
+	if err != nil {
+		pm.saveLocalMessage(theObj.GetID(), blockInfoID, nil)
+		return err
+	}
+
+	decrypted := make([]*pkgservice.ContentBlock, len(contentBlocks))
+	for i, contentBlock := range contentBlocks {
+		buf, err := decryptMessage(contentKey, theObj.GetID(), contentBlock.Buf)
+		if err != nil {
+			pm.saveLocalMessage(theObj.GetID(), blockInfoID, nil)
+			return err
+		}
+		decrypted[i] = pkgservice.NewContentBlock(contentBlock.BlockID, buf)
+	}
+
+	err = pm.saveLocalMessage(theObj.GetID(), blockInfoID, decrypted)
+	if err != nil {
+		return err
+	}
+
+	return pm.saveRatchetChain(false, header.From, nextChain)
+}
+
+/*
+decryptContentBlocks decrypts the content-blocks of the message in the ratchet with the content kept in the device.
+The pending message is re-opened first. The content-blocks are returned as they are if the message is not in the ratchet.
+*/
+func (pm *ProtocolManager) decryptContentBlocks(theObj pkgservice.Object, contentBlocks []*pkgservice.ContentBlock) ([]*pkgservice.ContentBlock, error) {
+	blockInfo := theObj.GetBlockInfo()
+	if blockInfo == nil {
+		return nil, pkgservice.ErrInvalidBlock
+	}
+
+	msg, isRatchet, err := pm.getLocalMessage(blockInfo.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !isRatchet {
+		return contentBlocks, nil
+	}
+	if msg.isPending() {
+		err = pm.openMessage(theObj, blockInfo.ID, msg.Ratchet)
+		if err != nil {
+			return nil, err
+		}
+
+		msg, _, err = pm.getLocalMessage(blockInfo.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(msg.Blocks) == 0 {
+		return nil, ErrNoMessageKey
+	}
+
+	storageKey, err := pm.storageKey()
+	if err != nil {
+		return nil, err
+	}
+
+	localBlocks := make(map[uint32]*pkgservice.ContentBlock)
+	for _, localBlock := range msg.Blocks {
+		localBlocks[localBlock.BlockID] = localBlock
+	}
+
+	objID := theObj.GetID()
+	decrypted := make([]*pkgservice.ContentBlock, len(contentBlocks))
+	for i, contentBlock := range contentBlocks {
+		localBlock, ok := localBlocks[contentBlock.BlockID]
+		if !ok {
+			return nil, ErrNoMessageKey
+		}
+
+		buf, err := decryptMessage(storageKey, objID, localBlock.Buf)
+		if err != nil {
+			return nil, err
+		}
+		decrypted[i] = pkgservice.NewContentBlock(contentBlock.BlockID, buf)
+	}
+
+	return decrypted, nil
+}
+
+/*
+cleanRatchet erases the chains, the content kept in the device and the devices of the friend.
+*/
+func (pm *ProtocolManager) cleanRatchet() error {
+	entityID := pm.Entity().GetID()
+
+	pm.lockRatchet.Lock()
+	defer pm.lockRatchet.Unlock()
+
+	prefixs := []struct {
+		db     pttdb.Storage
+		prefix []byte
+	}{
+		{pm.db.key, DBRatchetPrefix},
+		{pm.db.key, DBLocalMessagePrefix},
+		{pm.db.key, DBStorageKeyPrefix},
+		{pm.db.meta, DBDevicePrekeyPrefix},
+	}
+	for _, each := range prefixs {
+		dbPrefix, err := common.Concat([][]byte{each.prefix, entityID[:]})
+		if err != nil {
+			return err
+		}
+
+		iter, err := each.db.NewIteratorWithPrefix(nil, dbPrefix, pttdb.ListOrderNext)
+		if err != nil {
+			return err
+		}
+
+		keys := make([][]byte, 0)
+		for iter.Next() {
+			keys = append(keys, common.CloneBytes(iter.Key()))
+		}
+		iter.Release()
+
+		for _, key := range keys {
+			each.db.Delete(key)
+		}
+	}
+
+	key, err := pm.marshalFriendDevicesKey()
+	if err != nil {
+		return err
+	}
+
+	return pm.db.meta.Delete(key)
+}
@@ -0,0 +1,311 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package friend
+
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+/*
+PushToHub pushes the pending friend-oplogs that are not relayed to the hub yet.
+*/
+func (pm *ProtocolManager) PushToHub(peer *pkgservice.PttPeer) error {
+	if pm.Entity().GetStatus() != types.StatusAlive {
+		return nil
+	}
+
+	friendOplogs, err := pm.GetFriendOplogList(nil, 0, pttdb.ListOrderNext, types.StatusPending)
+	if err != nil {
+		return err
+	}
+
+	return pm.relayFriendOplogs(FriendOplogsToOplogs(friendOplogs), peer)
+}
+
+/*
+relayFriendOplogsToHubs relays the pending friend-oplogs to the hubs if the friend is not connected,
+so that the friend is still able to receive the oplogs even if we are not online at the same time.
+*/
+func (pm *ProtocolManager) relayFriendOplogsToHubs(oplogs []*pkgservice.BaseOplog) error {
+	if len(pm.Peers().ImportantPeerList(false)) != 0 {
+		return nil
+	}
+
+	hubPeers := pm.Router().HubPeerList()
+	for _, peer := range hubPeers {
+		err := pm.relayFriendOplogs(oplogs, peer)
+		if err != nil {
+			log.Warn("relayFriendOplogsToHubs: unable to relay", "entity", pm.Entity().IDString(), "peer", peer, "e", err)
+		}
+	}
+
+	return nil
+}
+
+/*
+relayFriendOplogsToDevices relays the pending friend-oplogs to the devices of the friend through the connected peers
+if the friend is not connected, so that the friend is still able to receive the oplogs even if we are not able to dial the friend.
+*/
+func (pm *ProtocolManager) relayFriendOplogsToDevices(oplogs []*pkgservice.BaseOplog) error {
+	if len(pm.Peers().ImportantPeerList(false)) != 0 {
+		return nil
+	}
+
+	nodeIDs, err := pm.GetFriendDevices()
+	if err != nil {
+		return err
+	}
+	if len(nodeIDs) == 0 {
+		return nil
+	}
+
+	// pending oplogs
+	toRelayLogs := make([]*pkgservice.BaseOplog, 0, len(oplogs))
+	for _, oplog := range oplogs {
+		if oplog.MasterLogID != nil || oplog.InternalSigns != nil {
+			continue
+		}
+		toRelayLogs = append(toRelayLogs, oplog)
+	}
+	if len(toRelayLogs) == 0 {
+		return nil
+	}
+
+	// extras
+	origExtras := make([]interface{}, len(toRelayLogs))
+	for i, oplog := range toRelayLogs {
+		origExtras[i] = oplog.Extra
+		oplog.Extra = nil
+	}
+	defer func() {
+		for i, oplog := range toRelayLogs {
+			oplog.Extra = origExtras[i]
+		}
+	}()
+
+	// relay
+	var eachLogs []*pkgservice.BaseOplog
+	pLogs := toRelayLogs
+	for len(pLogs) > 0 {
+		lenEachLogs := pkgservice.MaxRelayOplogs
+		if lenEachLogs > len(pLogs) {
+			lenEachLogs = len(pLogs)
+		}
+
+		eachLogs, pLogs = pLogs[:lenEachLogs], pLogs[lenEachLogs:]
+
+		for _, nodeID := range nodeIDs {
+			err = pm.RelayDataToNode(AddPendingFriendOplogsMsg, &pkgservice.AddOplogs{Oplogs: eachLogs}, nodeID)
+			if err != nil {
+				log.Debug("relayFriendOplogsToDevices: unable to relay", "entity", pm.Entity().IDString(), "nodeID", nodeID, "e", err)
+			}
+		}
+	}
+
+	// objects
+	for _, nodeID := range nodeIDs {
+		eachNodeID := nodeID
+		err = pm.relayFriendObjects(toRelayLogs, func(op pkgservice.OpType, data interface{}) error {
+			return pm.RelayDataToNode(op, data, eachNodeID)
+		})
+		if err != nil {
+			log.Debug("relayFriendOplogsToDevices: unable to relay objects", "entity", pm.Entity().IDString(), "nodeID", nodeID, "e", err)
+		}
+	}
+
+	return nil
+}
+
+func (pm *ProtocolManager) relayFriendOplogs(oplogs []*pkgservice.BaseOplog, peer *pkgservice.PttPeer) error {
+	friendID := pm.Entity().(*Friend).FriendID
+	if friendID == nil {
+		return nil
+	}
+
+	relayTS, err := pm.GetHubRelayTS(peer.GetID())
+	if err != nil {
+		return err
+	}
+
+	// pending oplogs not relayed yet.
+	toRelayLogs := make([]*pkgservice.BaseOplog, 0, len(oplogs))
+	for _, oplog := range oplogs {
+		if oplog.MasterLogID != nil || oplog.InternalSigns != nil {
+			continue
+		}
+		if !relayTS.IsLess(oplog.UpdateTS) {
+			continue
+		}
+		toRelayLogs = append(toRelayLogs, oplog)
+	}
+	if len(toRelayLogs) == 0 {
+		return nil
+	}
+
+	// extras
+	origExtras := make([]interface{}, len(toRelayLogs))
+	for i, oplog := range toRelayLogs {
+		origExtras[i] = oplog.Extra
+		oplog.Extra = nil
+	}
+	defer func() {
+		for i, oplog := range toRelayLogs {
+			oplog.Extra = origExtras[i]
+		}
+	}()
+
+	// relay
+	var eachLogs []*pkgservice.BaseOplog
+	pLogs := toRelayLogs
+	for len(pLogs) > 0 {
+		lenEachLogs := pkgservice.MaxRelayOplogs
+		if lenEachLogs > len(pLogs) {
+			lenEachLogs = len(pLogs)
+		}
+
+		eachLogs, pLogs = pLogs[:lenEachLogs], pLogs[lenEachLogs:]
+
+		err = pm.RelayDataToHub(AddPendingFriendOplogsMsg, &pkgservice.AddOplogs{Oplogs: eachLogs}, friendID, peer)
+		if err != nil {
+			return err
+		}
+
+		for _, oplog := range eachLogs {
+			if relayTS.IsLess(oplog.UpdateTS) {
+				relayTS = oplog.UpdateTS
+			}
+		}
+
+		// objects, relayed before relay-ts is updated so that the objects are relayed again if failed.
+		err = pm.relayFriendObjects(eachLogs, func(op pkgservice.OpType, data interface{}) error {
+			return pm.RelayDataToHub(op, data, friendID, peer)
+		})
+		if err != nil {
+			return err
+		}
+
+		err = pm.SetHubRelayTS(peer.GetID(), relayTS)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+relayFriendObjects relays the messages, the media and the blocks of the relayed friend-oplogs as the sync-acks.
+The friend receiving the relayed oplogs is not able to request the objects from us, as we may be offline
+when the oplogs are delivered. The chunks of the chunked files are synced when the friend is connected.
+*/
+func (pm *ProtocolManager) relayFriendObjects(oplogs []*pkgservice.BaseOplog, relay func(op pkgservice.OpType, data interface{}) error) error {
+	createMessageIDs := make([]*pkgservice.SyncID, 0, len(oplogs))
+	updateMessageIDs := make([]*pkgservice.SyncID, 0, len(oplogs))
+	createMediaIDs := make([]*pkgservice.SyncID, 0, len(oplogs))
+	for _, oplog := range oplogs {
+		syncID := &pkgservice.SyncID{ID: oplog.ObjID, LogID: oplog.ID}
+		switch oplog.Op {
+		case FriendOpTypeCreateMessage:
+			createMessageIDs = append(createMessageIDs, syncID)
+		case FriendOpTypeUpdateMessage:
+			updateMessageIDs = append(updateMessageIDs, syncID)
+		case FriendOpTypeCreateMedia:
+			createMediaIDs = append(createMediaIDs, syncID)
+		}
+	}
+
+	// message
+	msg := NewEmptyMessage()
+	pm.SetMessageDB(msg)
+
+	err := pm.relayObjects(pm.GetSyncCreateObjects(createMessageIDs, msg), msg, SyncCreateMessageAckMsg, SyncCreateMessageBlockAckMsg, relay)
+	if err != nil {
+		return err
+	}
+
+	err = pm.relayObjects(pm.GetSyncUpdateObjects(updateMessageIDs, msg), msg, SyncUpdateMessageAckMsg, SyncUpdateMessageBlockAckMsg, relay)
+	if err != nil {
+		return err
+	}
+
+	// media
+	media := pkgservice.NewEmptyMedia()
+	pm.SetMediaDB(media)
+
+	return pm.relayObjects(pm.GetSyncCreateObjects(createMediaIDs, media), media, SyncCreateMediaAckMsg, SyncCreateMediaBlockAckMsg, relay)
+}
+
+func (pm *ProtocolManager) relayObjects(objs []pkgservice.Object, obj pkgservice.Object, syncAckMsg pkgservice.OpType, syncBlockAckMsg pkgservice.OpType, relay func(op pkgservice.OpType, data interface{}) error) error {
+	if len(objs) == 0 {
+		return nil
+	}
+
+	err := pkgservice.SendSyncObjectAck(objs, syncAckMsg, relay)
+	if err != nil {
+		return err
+	}
+
+	syncBlockIDs := make([]*pkgservice.SyncBlockID, 0, len(objs))
+	for _, eachObj := range objs {
+		blockInfo := eachObj.GetBlockInfo()
+		if blockInfo == nil {
+			continue
+		}
+
+		syncBlockIDs = append(syncBlockIDs, &pkgservice.SyncBlockID{ID: blockInfo.ID, ObjID: eachObj.GetID()})
+	}
+
+	return pkgservice.SendSyncBlockAck(syncBlockAckMsg, pm.GetSyncBlocks(syncBlockIDs, obj), relay)
+}
+
+/*
+HandleRelayedMessage handles the message relayed through the hub or the relays.
+The pending friend-oplogs are relayed with the objects and the blocks as the sync-acks,
+as there is no peer to request the objects from.
+*/
+func (pm *ProtocolManager) HandleRelayedMessage(op pkgservice.OpType, dataBytes []byte) error {
+
+	log.Debug("friend.HandleRelayedMessage: start", "op", op, "entity", pm.Entity().IDString())
+
+	switch op {
+	case AddPendingFriendOplogMsg:
+		return pm.HandleAddPendingFriendOplog(dataBytes, nil)
+	case AddPendingFriendOplogsMsg:
+		return pm.HandleAddPendingFriendOplogs(dataBytes, nil)
+
+	// message
+	case SyncCreateMessageAckMsg:
+		return pm.HandleSyncCreateMessageAck(dataBytes, nil)
+	case SyncCreateMessageBlockAckMsg:
+		return pm.HandleSyncCreateMessageBlockAck(dataBytes, nil)
+	case SyncUpdateMessageAckMsg:
+		return pm.HandleSyncUpdateMessageAck(dataBytes, nil)
+	case SyncUpdateMessageBlockAckMsg:
+		return pm.HandleSyncUpdateMessageBlockAck(dataBytes, nil)
+
+	// media
+	case SyncCreateMediaAckMsg:
+		return pm.HandleSyncCreateMediaAck(dataBytes, nil)
+	case SyncCreateMediaBlockAckMsg:
+		return pm.HandleSyncCreateMediaBlockAck(dataBytes, nil)
+	}
+
+	return pkgservice.ErrInvalidMsg
+}
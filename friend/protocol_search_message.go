@@ -0,0 +1,120 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package friend
+
+import (
+	"sort"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+/*
+indexMessage indexes the content of the message. The message is locked while being called from post-create / post-update.
+*/
+func (pm *ProtocolManager) indexMessage(theObj pkgservice.Object) error {
+	blockInfo := theObj.GetBlockInfo()
+	if blockInfo == nil {
+		return pkgservice.ErrInvalidBlock
+	}
+	pm.SetBlockInfoDB(blockInfo, theObj.GetID())
+
+	contentBlocks, err := pkgservice.GetContentBlockList(blockInfo, 0, true)
+	if err != nil {
+		log.Warn("indexMessage: unable to get content blocks", "message", theObj.GetID(), "e", err)
+		return err
+	}
+
+	contentBlocks, err = pm.decryptContentBlocks(theObj, contentBlocks)
+	if err != nil {
+		log.Warn("indexMessage: unable to decrypt content blocks", "message", theObj.GetID(), "e", err)
+		return err
+	}
+
+	texts := make([][]byte, 0)
+	for _, contentBlock := range contentBlocks {
+		texts = append(texts, contentBlock.Buf...)
+	}
+
+	return pm.db.messageIndex.Put(pm.Entity().GetID(), theObj.GetID(), texts...)
+}
+
+func (pm *ProtocolManager) postupdateMessage(theObj pkgservice.Object, oplog *pkgservice.BaseOplog) error {
+	opData := &FriendOpUpdateMessage{}
+	err := oplog.GetData(opData)
+	if err == nil && opData.BlockInfoID != nil {
+		err = pm.openMessage(theObj, opData.BlockInfoID, opData.Ratchet)
+		if err != nil {
+			log.Warn("postupdateMessage: unable to open message", "message", theObj.GetID(), "e", err)
+		}
+	}
+
+	pm.postMessageEvent(theObj, types.StatusAlive, oplog.UpdateTS)
+
+	return pm.indexMessage(theObj)
+}
+
+func (pm *ProtocolManager) postdeleteMessage(id *types.PttID, oplog *pkgservice.BaseOplog, opData pkgservice.OpData, origObj pkgservice.Object, blockInfo *pkgservice.BlockInfo) error {
+	pm.postMessageEvent(origObj, types.StatusDeleted, oplog.UpdateTS)
+
+	if blockInfo != nil {
+		pm.removeLocalMessage(blockInfo.ID)
+	}
+
+	return pm.db.messageIndex.Delete(pm.Entity().GetID(), id)
+}
+
+/*
+SearchMessages searches the alive messages in the friend (all the friends if entityID is nil),
+ordered by create-ts from the newest.
+*/
+func (spm *ServiceProtocolManager) SearchMessages(query string, entityID *types.PttID, limit int) ([]*Message, error) {
+	docs, err := spm.db.messageIndex.Search(query, entityID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*Message, 0, len(docs))
+	for _, doc := range docs {
+		entity := spm.Entity(doc.ScopeID)
+		if entity == nil {
+			continue
+		}
+		pm := entity.PM().(*ProtocolManager)
+
+		message := NewEmptyMessage()
+		pm.SetMessageDB(message)
+		message.SetID(doc.ID)
+		err = message.GetByID(false)
+		if err != nil || message.Status != types.StatusAlive {
+			continue
+		}
+
+		messages = append(messages, message)
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[j].CreateTS.IsLess(messages[i].CreateTS)
+	})
+
+	if limit > 0 && len(messages) > limit {
+		messages = messages[:limit]
+	}
+
+	return messages, nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package friend
+
+import (
+	"reflect"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+type UpdateMessage struct {
+	Msg      [][]byte
+	MediaIDs []*types.PttID
+}
+
+/*
+UpdateMessage replaces the content of a message.
+Only the creator of the message is allowed to update it.
+*/
+func (pm *ProtocolManager) UpdateMessage(messageID *types.PttID, msg [][]byte, mediaIDs []*types.PttID) (*Message, error) {
+
+	myID := pm.Router().GetMyEntity().GetID()
+
+	if !pm.IsMaster(myID, false) {
+		return nil, types.ErrInvalidID
+	}
+
+	origObj := NewEmptyMessage()
+	pm.SetMessageDB(origObj)
+
+	err := pm.checkMessageCreator(origObj, messageID, myID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &UpdateMessage{
+		Msg:      msg,
+		MediaIDs: mediaIDs,
+	}
+
+	opData := &FriendOpUpdateMessage{}
+
+	err = pm.UpdateObject(
+		messageID,
+
+		data,
+		FriendOpTypeUpdateMessage,
+
+		origObj,
+
+		opData,
+
+		pm.friendOplogMerkle,
+
+		pm.SetFriendDB,
+
+		pm.NewFriendOplog,
+
+		pm.inupdateMessage,
+
+		nil,
+
+		pm.broadcastFriendOplogCore,
+		pm.postupdateMessage,
+	)
+	log.Debug("UpdateMessage: after UpdateObject", "messageID", messageID, "e", err)
+	if err != nil {
+		return nil, err
+	}
+
+	return origObj, nil
+}
+
+func (pm *ProtocolManager) inupdateMessage(obj pkgservice.Object, theData pkgservice.UpdateData, oplog *pkgservice.BaseOplog, theOpData pkgservice.OpData) (pkgservice.SyncInfo, error) {
+
+	data, ok := theData.(*UpdateMessage)
+	if !ok {
+		return nil, pkgservice.ErrInvalidData
+	}
+
+	opData, ok := theOpData.(*FriendOpUpdateMessage)
+	if !ok {
+		return nil, pkgservice.ErrInvalidData
+	}
+
+	// block-info
+	blockID, blockHashs, ratchetHeader, err := pm.splitMessageBlocks(obj.GetID(), data.Msg)
+	if err != nil {
+		log.Error("inupdateMessage: Unable to splitMessageBlocks", "e", err)
+		return nil, err
+	}
+
+	blockInfo, err := pkgservice.NewBlockInfo(blockID, blockHashs, data.MediaIDs, obj.GetCreatorID())
+	if err != nil {
+		return nil, err
+	}
+	blockInfo.SetIsAllGood()
+
+	// op-data
+	opData.BlockInfoID = blockID
+	opData.NBlock = blockInfo.NBlock
+	opData.Hashs = blockHashs
+	opData.MediaIDs = data.MediaIDs
+	opData.Ratchet = ratchetHeader
+
+	// sync-info
+	syncInfo := &pkgservice.BaseSyncInfo{}
+	syncInfo.InitWithOplog(oplog.ToStatus(), oplog)
+	syncInfo.BlockInfo = blockInfo
+
+	return syncInfo, nil
+}
+
+/*
+checkMessageCreator loads the message and ensures that it is created by myID.
+Both friends are masters of the friend-entity, so the master-check in
+UpdateObject / DeleteObject does not prevent editing the message of the other.
+*/
+func (pm *ProtocolManager) checkMessageCreator(obj *Message, messageID *types.PttID, myID *types.PttID) error {
+	obj.SetID(messageID)
+	err := obj.GetByID(false)
+	if err != nil {
+		return err
+	}
+
+	if !reflect.DeepEqual(obj.CreatorID, myID) {
+		return types.ErrInvalidID
+	}
+
+	return nil
+}
@@ -0,0 +1,471 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package friend
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"io"
+
+	"github.com/ailabstw/go-pttai-core/common"
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+)
+
+/*
+RatchetHeader carries the content-key of the message wrapped for each of the target devices.
+
+The content of the message is encrypted with a random content-key. The content-key is wrapped
+with the message-key of the ratchet between the sending device and each target device
+(the devices of the friend and my other devices), so neither the op-key nor the me-key is able
+to decrypt the message.
+*/
+type RatchetHeader struct {
+	From *discover.NodeID `json:"F"`
+	Keys []*RatchetKey    `json:"K"`
+}
+
+/*
+RatchetKey is the content-key wrapped with the N-th message-key of the ratchet from the sending device to the device To.
+E is the ephemeral public-key of the chain and P is the ts of the prekey of the device To that the chain is derived from.
+*/
+type RatchetKey struct {
+	E   []byte           `json:"E"`
+	Key []byte           `json:"K"`
+	N   uint32           `json:"N"`
+	P   types.Timestamp  `json:"P"`
+	To  *discover.NodeID `json:"T"`
+}
+
+func (h *RatchetHeader) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.NodeID(h.From)
+	enc.Uint64(uint64(len(h.Keys)))
+	for _, key := range h.Keys {
+		enc.Data(key)
+	}
+}
+
+func (k *RatchetKey) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.Bytes(k.E)
+	enc.Bytes(k.Key)
+	enc.Uint64(uint64(k.N))
+	enc.Timestamp(k.P)
+	enc.NodeID(k.To)
+}
+
+func (h *RatchetHeader) keyTo(nodeID *discover.NodeID) *RatchetKey {
+	for _, key := range h.Keys {
+		if key.To != nil && *key.To == *nodeID {
+			return key
+		}
+	}
+	return nil
+}
+
+/*
+SignedPrekey is the prekey of the device, signed with the node-key of the device.
+
+The prekey is renewed every RenewPrekeySeconds and the private-key is erased
+ExpirePrekeySeconds after renewed, so the chains derived from the prekey are not able
+to be rebuilt with the node-keys afterwards.
+*/
+type SignedPrekey struct {
+	NodeID *discover.NodeID `json:"N"`
+	Pubkey []byte           `json:"K"`
+	TS     types.Timestamp  `json:"TS"`
+	Sig    []byte           `json:"S"`
+}
+
+func prekeyHash(pubkey []byte, ts types.Timestamp) ([]byte, error) {
+	tsBytes, err := ts.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return crypto.Keccak256(PrekeyInfo, pubkey, tsBytes), nil
+}
+
+func signPrekey(nodeKey *ecdsa.PrivateKey, prekey *ecdsa.PrivateKey, ts types.Timestamp) (*SignedPrekey, error) {
+	pubkey := crypto.CompressPubkey(&prekey.PublicKey)
+
+	hash, err := prekeyHash(pubkey, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := crypto.Sign(hash, nodeKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeID := discover.PubkeyID(&nodeKey.PublicKey)
+
+	return &SignedPrekey{
+		NodeID: &nodeID,
+		Pubkey: pubkey,
+		TS:     ts,
+		Sig:    sig,
+	}, nil
+}
+
+/*
+Verify verifies that the prekey is signed by the node-key of the device.
+*/
+func (p *SignedPrekey) Verify() error {
+	if p.NodeID == nil || len(p.Pubkey) == 0 {
+		return ErrInvalidPrekey
+	}
+
+	hash, err := prekeyHash(p.Pubkey, p.TS)
+	if err != nil {
+		return err
+	}
+
+	pubkey, err := crypto.SigToPub(hash, p.Sig)
+	if err != nil {
+		return ErrInvalidPrekey
+	}
+
+	if discover.PubkeyID(pubkey) != *p.NodeID {
+		return ErrInvalidPrekey
+	}
+
+	_, err = crypto.DecompressPubkey(p.Pubkey)
+	if err != nil {
+		return ErrInvalidPrekey
+	}
+
+	return nil
+}
+
+/*
+ratchetChain is the symmetric-key ratchet in one direction between 2 devices.
+
+The chain-key is replaced by the next chain-key once a message-key is derived,
+and the message-key is erased once used, so the compromise of the current state
+does not reveal the messages already read. The message-keys skipped by the
+out-of-order messages are kept in Skipped until used.
+
+Ephemeral and PrekeyTS are the ephemeral public-key of the sending device
+and the prekey of the receiving device that the chain is derived from.
+*/
+type ratchetChain struct {
+	ChainKey []byte            `json:"CK"`
+	N        uint32            `json:"N"`
+	Skipped  map[uint32][]byte `json:"s,omitempty"`
+
+	Ephemeral []byte          `json:"E"`
+	PrekeyTS  types.Timestamp `json:"P"`
+}
+
+/*
+newSendingChain starts the chain to the device with a new ephemeral-key.
+
+The initial chain-key is derived from the ecdh of the ephemeral-key and the prekey of the device,
+and the ecdh of my node-key and the prekey of the device to authenticate the sending device.
+The private ephemeral-key is dropped once the chain-key is derived.
+*/
+func newSendingChain(myNodeKey *ecdsa.PrivateKey, theirPrekey *SignedPrekey, entityID *types.PttID, fromID *discover.NodeID, toID *discover.NodeID) (*ratchetChain, error) {
+	prekeyPubkey, err := crypto.DecompressPubkey(theirPrekey.Pubkey)
+	if err != nil {
+		return nil, ErrInvalidPrekey
+	}
+
+	ephemeralKey, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	ephemeral := crypto.CompressPubkey(&ephemeralKey.PublicKey)
+
+	ephemeralShared, err := ecdh(ephemeralKey, prekeyPubkey)
+	if err != nil {
+		return nil, err
+	}
+
+	staticShared, err := ecdh(myNodeKey, prekeyPubkey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ratchetChain{
+		ChainKey:  deriveChainKey(ephemeralShared, staticShared, ephemeral, entityID, fromID, toID),
+		Ephemeral: ephemeral,
+		PrekeyTS:  theirPrekey.TS,
+	}, nil
+}
+
+/*
+newReceivingChain derives the chain from the device with my prekey and the ephemeral public-key in the ratchet-key.
+*/
+func newReceivingChain(myPrekey *ecdsa.PrivateKey, prekeyTS types.Timestamp, ephemeral []byte, entityID *types.PttID, fromID *discover.NodeID, toID *discover.NodeID) (*ratchetChain, error) {
+	ephemeralPubkey, err := crypto.DecompressPubkey(ephemeral)
+	if err != nil {
+		return nil, ErrInvalidRatchet
+	}
+
+	theirPubkey, err := fromID.Pubkey()
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeralShared, err := ecdh(myPrekey, ephemeralPubkey)
+	if err != nil {
+		return nil, err
+	}
+
+	staticShared, err := ecdh(myPrekey, theirPubkey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ratchetChain{
+		ChainKey:  deriveChainKey(ephemeralShared, staticShared, ephemeral, entityID, fromID, toID),
+		Ephemeral: ephemeral,
+		PrekeyTS:  prekeyTS,
+	}, nil
+}
+
+func ecdh(key *ecdsa.PrivateKey, pubkey *ecdsa.PublicKey) ([]byte, error) {
+	return ecies.ImportECDSA(key).GenerateShared(ecies.ImportECDSAPublic(pubkey), SizeRatchetKey, 0)
+}
+
+/*
+deriveChainKey binds the shared secrets with the ephemeral public-key, the friend-entity and the direction.
+*/
+func deriveChainKey(ephemeralShared []byte, staticShared []byte, ephemeral []byte, entityID *types.PttID, fromID *discover.NodeID, toID *discover.NodeID) []byte {
+	mac := hmac.New(sha256.New, append(common.CloneBytes(ephemeralShared), staticShared...))
+	mac.Write(RatchetInfo)
+	mac.Write(ephemeral)
+	mac.Write(entityID[:])
+	mac.Write(fromID[:])
+	mac.Write(toID[:])
+
+	return mac.Sum(nil)
+}
+
+func kdfChain(chainKey []byte) ([]byte, []byte) {
+	mac := hmac.New(sha256.New, chainKey)
+	mac.Write([]byte{0x01})
+	messageKey := mac.Sum(nil)
+
+	mac = hmac.New(sha256.New, chainKey)
+	mac.Write([]byte{0x02})
+	nextChainKey := mac.Sum(nil)
+
+	return messageKey, nextChainKey
+}
+
+/*
+next derives the message-key for sending and moves forward the chain.
+*/
+func (c *ratchetChain) next() (uint32, []byte) {
+	n := c.N
+	messageKey, nextChainKey := kdfChain(c.ChainKey)
+
+	c.ChainKey = nextChainKey
+	c.N++
+
+	return n, messageKey
+}
+
+/*
+messageKey takes the n-th message-key for receiving.
+
+The chain is not changed. The chain with the message-key erased is returned as next,
+and is to be saved only after the message is opened with the message-key,
+so that a message failed to be opened does not consume the message-key or skip the chain.
+*/
+func (c *ratchetChain) messageKey(n uint32) ([]byte, *ratchetChain, error) {
+	next := c.clone()
+
+	if n < next.N {
+		messageKey, ok := next.Skipped[n]
+		if !ok {
+			return nil, nil, ErrInvalidRatchet
+		}
+		delete(next.Skipped, n)
+		return messageKey, next, nil
+	}
+
+	if n-next.N > MaxSkipRatchetKeys || len(next.Skipped)+int(n-next.N) > MaxSkipRatchetKeys {
+		return nil, nil, ErrInvalidRatchet
+	}
+
+	for next.N < n {
+		skipped, messageKey := next.next()
+		next.Skipped[skipped] = messageKey
+	}
+
+	_, messageKey := next.next()
+
+	return messageKey, next, nil
+}
+
+func (c *ratchetChain) clone() *ratchetChain {
+	skipped := make(map[uint32][]byte, len(c.Skipped))
+	for n, messageKey := range c.Skipped {
+		skipped[n] = messageKey
+	}
+
+	return &ratchetChain{
+		ChainKey:  c.ChainKey,
+		N:         c.N,
+		Skipped:   skipped,
+		Ephemeral: c.Ephemeral,
+		PrekeyTS:  c.PrekeyTS,
+	}
+}
+
+/*
+ratchetAD is the associated data of the wrapped content-key.
+*/
+func ratchetAD(blockInfoID *types.PttID, fromID *discover.NodeID, toID *discover.NodeID) []byte {
+	ad := make([]byte, 0, types.SizePttID+2*len(fromID))
+	ad = append(ad, blockInfoID[:]...)
+	ad = append(ad, fromID[:]...)
+	ad = append(ad, toID[:]...)
+
+	return ad
+}
+
+/*
+sealContentKey wraps the content-key with the message-key:
+
+	nonce (12 bytes) | aes-gcm(content-key)
+
+The message-key is expected to be used only once, but the nonce is still random,
+so that the same message-key re-derived from a restored chain does not reuse the nonce.
+*/
+func sealContentKey(messageKey []byte, contentKey []byte, ad []byte) ([]byte, error) {
+	aead, err := newRatchetAEAD(messageKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(contentKey)+aead.Overhead())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, contentKey, ad), nil
+}
+
+func openContentKey(messageKey []byte, sealed []byte, ad []byte) ([]byte, error) {
+	aead, err := newRatchetAEAD(messageKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrInvalidRatchet
+	}
+
+	nonce := sealed[:aead.NonceSize()]
+	contentKey, err := aead.Open(nil, nonce, sealed[aead.NonceSize():], ad)
+	if err != nil {
+		return nil, ErrInvalidRatchet
+	}
+
+	return contentKey, nil
+}
+
+func newContentKey() ([]byte, error) {
+	contentKey := make([]byte, SizeRatchetKey)
+	_, err := io.ReadFull(rand.Reader, contentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return contentKey, nil
+}
+
+/*
+newStorageKey generates the local storage-key of the friend.
+The content kept in the device is encrypted with the storage-key, which never leaves the device.
+
+The storage-key is random instead of derived from the node-key, so that the content is erased
+with the storage-key once the friend is deleted, even if the node-key is compromised later.
+*/
+func newStorageKey() ([]byte, error) {
+	return newContentKey()
+}
+
+/*
+encryptMessage encrypts each line of the message with the content-key:
+
+	nonce (12 bytes) | aes-gcm(line)
+*/
+func encryptMessage(contentKey []byte, objID *types.PttID, msg [][]byte) ([][]byte, error) {
+	aead, err := newRatchetAEAD(contentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	encMsg := make([][]byte, len(msg))
+	for i, line := range msg {
+		nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(line)+aead.Overhead())
+		_, err = io.ReadFull(rand.Reader, nonce)
+		if err != nil {
+			return nil, err
+		}
+
+		encMsg[i] = aead.Seal(nonce, nonce, line, objID[:])
+	}
+
+	return encMsg, nil
+}
+
+func decryptMessage(contentKey []byte, objID *types.PttID, encMsg [][]byte) ([][]byte, error) {
+	aead, err := newRatchetAEAD(contentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := make([][]byte, len(encMsg))
+	for i, encLine := range encMsg {
+		if len(encLine) < aead.NonceSize()+aead.Overhead() {
+			return nil, ErrInvalidRatchet
+		}
+
+		nonce := encLine[:aead.NonceSize()]
+		msg[i], err = aead.Open(nil, nonce, encLine[aead.NonceSize():], objID[:])
+		if err != nil {
+			return nil, ErrInvalidRatchet
+		}
+	}
+
+	return msg, nil
+}
+
+func newRatchetAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
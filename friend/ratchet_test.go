@@ -0,0 +1,217 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package friend
+
+import (
+	"testing"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRatchetChain(t *testing.T) {
+	setupTest(t)
+	defer teardownTest(t)
+
+	key1, _ := crypto.GenerateKey()
+	key2, _ := crypto.GenerateKey()
+	prekey2, _ := crypto.GenerateKey()
+	node1 := discover.PubkeyID(&key1.PublicKey)
+	node2 := discover.PubkeyID(&key2.PublicKey)
+	entityID := &types.PttID{1}
+	ts := types.Timestamp{Ts: 1234567890}
+
+	signedPrekey2, err := signPrekey(key2, prekey2, ts)
+	assert.NoError(t, err)
+
+	// the sending chain of node1 is the receiving chain of node2.
+	sendChain, err := newSendingChain(key1, signedPrekey2, entityID, &node1, &node2)
+	assert.NoError(t, err)
+	assert.Equal(t, ts, sendChain.PrekeyTS)
+	recvChain, err := newReceivingChain(prekey2, ts, sendChain.Ephemeral, entityID, &node1, &node2)
+	assert.NoError(t, err)
+	assert.Equal(t, sendChain.ChainKey, recvChain.ChainKey)
+
+	// the node-keys only are not able to derive the chain.
+	nodeKeyChain, err := newReceivingChain(key2, ts, sendChain.Ephemeral, entityID, &node1, &node2)
+	assert.NoError(t, err)
+	assert.NotEqual(t, sendChain.ChainKey, nodeKeyChain.ChainKey)
+
+	// the new sending chain is with a new ephemeral-key.
+	otherChain, err := newSendingChain(key1, signedPrekey2, entityID, &node1, &node2)
+	assert.NoError(t, err)
+	assert.NotEqual(t, sendChain.Ephemeral, otherChain.Ephemeral)
+	assert.NotEqual(t, sendChain.ChainKey, otherChain.ChainKey)
+
+	// the other direction is a different chain.
+	otherChain, err = newReceivingChain(prekey2, ts, sendChain.Ephemeral, entityID, &node2, &node1)
+	assert.NoError(t, err)
+	assert.NotEqual(t, sendChain.ChainKey, otherChain.ChainKey)
+
+	messageKeys := make([][]byte, 3)
+	for i := range messageKeys {
+		var n uint32
+		n, messageKeys[i] = sendChain.next()
+		assert.Equal(t, uint32(i), n)
+	}
+
+	// out-of-order
+	messageKey, next, err := recvChain.messageKey(2)
+	assert.NoError(t, err)
+	assert.Equal(t, messageKeys[2], messageKey)
+	assert.Equal(t, 2, len(next.Skipped))
+
+	// the chain is not changed until the next chain is taken.
+	assert.Equal(t, uint32(0), recvChain.N)
+	assert.Equal(t, 0, len(recvChain.Skipped))
+	messageKey, _, err = recvChain.messageKey(2)
+	assert.NoError(t, err)
+	assert.Equal(t, messageKeys[2], messageKey)
+	recvChain = next
+
+	messageKey, next, err = recvChain.messageKey(0)
+	assert.NoError(t, err)
+	assert.Equal(t, messageKeys[0], messageKey)
+	assert.Equal(t, 2, len(recvChain.Skipped))
+	recvChain = next
+
+	// erased once used.
+	_, _, err = recvChain.messageKey(0)
+	assert.Equal(t, ErrInvalidRatchet, err)
+	_, _, err = recvChain.messageKey(2)
+	assert.Equal(t, ErrInvalidRatchet, err)
+
+	messageKey, next, err = recvChain.messageKey(1)
+	assert.NoError(t, err)
+	assert.Equal(t, messageKeys[1], messageKey)
+	assert.Equal(t, 0, len(next.Skipped))
+	recvChain = next
+
+	// too many skipped
+	_, _, err = recvChain.messageKey(recvChain.N + MaxSkipRatchetKeys + 1)
+	assert.Equal(t, ErrInvalidRatchet, err)
+}
+
+func TestSignedPrekey(t *testing.T) {
+	setupTest(t)
+	defer teardownTest(t)
+
+	key1, _ := crypto.GenerateKey()
+	key2, _ := crypto.GenerateKey()
+	prekey1, _ := crypto.GenerateKey()
+	node2 := discover.PubkeyID(&key2.PublicKey)
+	ts := types.Timestamp{Ts: 1234567890}
+
+	signedPrekey, err := signPrekey(key1, prekey1, ts)
+	assert.NoError(t, err)
+	assert.NoError(t, signedPrekey.Verify())
+
+	// ts
+	tampered := *signedPrekey
+	tampered.TS = types.Timestamp{Ts: 1234567891}
+	assert.Equal(t, ErrInvalidPrekey, tampered.Verify())
+
+	// pubkey
+	tampered = *signedPrekey
+	tampered.Pubkey = crypto.CompressPubkey(&key2.PublicKey)
+	assert.Equal(t, ErrInvalidPrekey, tampered.Verify())
+
+	// node
+	tampered = *signedPrekey
+	tampered.NodeID = &node2
+	assert.Equal(t, ErrInvalidPrekey, tampered.Verify())
+}
+
+func TestRatchetMessage(t *testing.T) {
+	setupTest(t)
+	defer teardownTest(t)
+
+	objID := &types.PttID{1}
+	blockInfoID := &types.PttID{2}
+	node1 := &discover.NodeID{1}
+	node2 := &discover.NodeID{2}
+	msg := [][]byte{[]byte("line1"), []byte(""), []byte("line3")}
+
+	contentKey, err := newContentKey()
+	assert.NoError(t, err)
+
+	encMsg, err := encryptMessage(contentKey, objID, msg)
+	assert.NoError(t, err)
+	assert.Equal(t, len(msg), len(encMsg))
+	assert.NotEqual(t, msg[0], encMsg[0])
+
+	decrypted, err := decryptMessage(contentKey, objID, encMsg)
+	assert.NoError(t, err)
+	assert.Equal(t, msg[0], decrypted[0])
+	assert.Equal(t, 0, len(decrypted[1]))
+	assert.Equal(t, msg[2], decrypted[2])
+
+	_, err = decryptMessage(contentKey, &types.PttID{3}, encMsg)
+	assert.Equal(t, ErrInvalidRatchet, err)
+
+	// wrapped content-key
+	_, messageKey := (&ratchetChain{ChainKey: make([]byte, SizeRatchetKey)}).next()
+	sealed, err := sealContentKey(messageKey, contentKey, ratchetAD(blockInfoID, node1, node2))
+	assert.NoError(t, err)
+
+	opened, err := openContentKey(messageKey, sealed, ratchetAD(blockInfoID, node1, node2))
+	assert.NoError(t, err)
+	assert.Equal(t, contentKey, opened)
+
+	// the nonce is random.
+	sealed2, err := sealContentKey(messageKey, contentKey, ratchetAD(blockInfoID, node1, node2))
+	assert.NoError(t, err)
+	assert.NotEqual(t, sealed, sealed2)
+
+	_, err = openContentKey(messageKey, sealed[:10], ratchetAD(blockInfoID, node1, node2))
+	assert.Equal(t, ErrInvalidRatchet, err)
+
+	_, err = openContentKey(messageKey, sealed, ratchetAD(blockInfoID, node2, node1))
+	assert.Equal(t, ErrInvalidRatchet, err)
+
+	header := &RatchetHeader{From: node1, Keys: []*RatchetKey{&RatchetKey{To: node2, Key: sealed}}}
+	assert.Equal(t, sealed, header.keyTo(node2).Key)
+	assert.Nil(t, header.keyTo(node1))
+
+	// local storage-key
+	storageKey, err := newStorageKey()
+	assert.NoError(t, err)
+	assert.Equal(t, SizeRatchetKey, len(storageKey))
+	storageKey2, err := newStorageKey()
+	assert.NoError(t, err)
+	assert.NotEqual(t, storageKey, storageKey2)
+}
+
+func TestSplitLines(t *testing.T) {
+	setupTest(t)
+	defer teardownTest(t)
+
+	msg := make([][]byte, NFirstLineInBlock+pkgservice.NLineInBlock+1)
+	for i := range msg {
+		msg[i] = []byte{byte(i)}
+	}
+
+	contentBlocks := splitLines(msg)
+	assert.Equal(t, 3, len(contentBlocks))
+	assert.Equal(t, NFirstLineInBlock, len(contentBlocks[0].Buf))
+	assert.Equal(t, pkgservice.NLineInBlock, len(contentBlocks[1].Buf))
+	assert.Equal(t, uint32(2), contentBlocks[2].BlockID)
+	assert.Equal(t, msg[len(msg)-1], contentBlocks[2].Buf[0])
+}
@@ -17,23 +17,42 @@
 package friend
 
 import (
+	"sync"
+
 	"github.com/ailabstw/go-pttai-core/pttdb"
 	pkgservice "github.com/ailabstw/go-pttai-core/service"
 )
 
 type ServiceProtocolManager struct {
 	*pkgservice.BaseServiceProtocolManager
+
+	// presence
+	lockPresenceSetting sync.RWMutex
+	presenceSetting     *PresenceSetting
+
+	// ratchet
+	lockPrekey sync.Mutex
+
+	db *friendDB
 }
 
-func NewServiceProtocolManager(router pkgservice.Router, service pkgservice.Service) (*ServiceProtocolManager, error) {
+func NewServiceProtocolManager(router pkgservice.Router, service pkgservice.Service, db *friendDB) (*ServiceProtocolManager, error) {
 
 	b, err := pkgservice.NewBaseServiceProtocolManager(router, service)
 	if err != nil {
 		return nil, err
 	}
 
+	presenceSetting, err := loadPresenceSetting(db.meta)
+	if err != nil {
+		return nil, err
+	}
+
 	spm := &ServiceProtocolManager{
 		BaseServiceProtocolManager: b,
+		presenceSetting:            presenceSetting,
+
+		db: db,
 	}
 
 	// load friends
@@ -59,5 +78,5 @@ func NewServiceProtocolManager(router pkgservice.Router, service pkgservice.Serv
 }
 
 func (spm *ServiceProtocolManager) NewEmptyEntity() pkgservice.Entity {
-	return NewEmptyFriend()
+	return NewEmptyFriend(spm.db)
 }
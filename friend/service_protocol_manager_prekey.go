@@ -0,0 +1,179 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package friend
+
+import (
+	"encoding/json"
+
+	"github.com/ailabstw/go-pttai-core/common"
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+/*
+prekey is the private prekey of my device, shared by all the friends.
+*/
+type prekey struct {
+	Key []byte          `json:"K"`
+	TS  types.Timestamp `json:"TS"`
+}
+
+func marshalPrekeyKey(ts types.Timestamp) ([]byte, error) {
+	tsBytes, err := ts.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return common.Concat([][]byte{DBPrekeyPrefix, tsBytes})
+}
+
+/*
+currentPrekey gets the current prekey of my device. The prekey is renewed every RenewPrekeySeconds,
+and the private-keys renewed more than ExpirePrekeySeconds ago are erased.
+*/
+func (spm *ServiceProtocolManager) currentPrekey() (*prekey, error) {
+	now, err := types.GetTimestamp()
+	if err != nil {
+		return nil, err
+	}
+
+	spm.lockPrekey.Lock()
+	defer spm.lockPrekey.Unlock()
+
+	prekeys, err := spm.getPrekeys()
+	if err != nil {
+		return nil, err
+	}
+
+	var current *prekey
+	if len(prekeys) != 0 {
+		current = prekeys[len(prekeys)-1]
+	}
+	if current == nil || now.Ts-current.TS.Ts >= RenewPrekeySeconds {
+		current, err = spm.newPrekey(now)
+		if err != nil {
+			return nil, err
+		}
+		prekeys = append(prekeys, current)
+	}
+
+	// the prekeys replaced by the next prekey more than ExpirePrekeySeconds ago.
+	for i, each := range prekeys[:len(prekeys)-1] {
+		if now.Ts-prekeys[i+1].TS.Ts < ExpirePrekeySeconds {
+			break
+		}
+
+		key, err := marshalPrekeyKey(each.TS)
+		if err != nil {
+			return nil, err
+		}
+
+		err = spm.db.key.Delete(key)
+		log.Debug("currentPrekey: expired", "ts", each.TS, "e", err)
+	}
+
+	return current, nil
+}
+
+/*
+getPrekeys gets my prekeys ordered by ts.
+Assuming lockPrekey is locked.
+*/
+func (spm *ServiceProtocolManager) getPrekeys() ([]*prekey, error) {
+	iter, err := spm.db.key.NewIteratorWithPrefix(nil, DBPrekeyPrefix, pttdb.ListOrderNext)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Release()
+
+	prekeys := make([]*prekey, 0)
+	for iter.Next() {
+		each := &prekey{}
+		err = json.Unmarshal(iter.Value(), each)
+		if err != nil {
+			log.Warn("getPrekeys: unable to unmarshal", "e", err)
+			continue
+		}
+		prekeys = append(prekeys, each)
+	}
+
+	return prekeys, nil
+}
+
+/*
+newPrekey generates and saves the new prekey.
+Assuming lockPrekey is locked.
+*/
+func (spm *ServiceProtocolManager) newPrekey(ts types.Timestamp) (*prekey, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &prekey{
+		Key: crypto.FromECDSA(key),
+		TS:  ts,
+	}
+
+	dbKeyKey, err := marshalPrekeyKey(ts)
+	if err != nil {
+		return nil, err
+	}
+
+	marshaled, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	err = spm.db.key.Put(dbKeyKey, marshaled)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+/*
+getPrekey gets my prekey by the ts. Returns ErrInvalidPrekey if the prekey is already erased.
+*/
+func (spm *ServiceProtocolManager) getPrekey(ts types.Timestamp) (*prekey, error) {
+	key, err := marshalPrekeyKey(ts)
+	if err != nil {
+		return nil, err
+	}
+
+	spm.lockPrekey.Lock()
+	defer spm.lockPrekey.Unlock()
+
+	theBytes, err := spm.db.key.Get(key)
+	if err == pttdb.ErrNotFound {
+		return nil, ErrInvalidPrekey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p := &prekey{}
+	err = json.Unmarshal(theBytes, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
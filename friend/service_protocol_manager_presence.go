@@ -0,0 +1,75 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package friend
+
+import "github.com/ailabstw/go-pttai-core/log"
+
+func (spm *ServiceProtocolManager) GetPresenceSetting() *PresenceSetting {
+	spm.lockPresenceSetting.RLock()
+	defer spm.lockPresenceSetting.RUnlock()
+
+	setting := *spm.presenceSetting
+	return &setting
+}
+
+/*
+SetPresenceSetting saves the privacy setting of the presence and typing.
+The friends are notified that I'm offline / not typing once hidden,
+and are sent the heartbeats again once shown.
+*/
+func (spm *ServiceProtocolManager) SetPresenceSetting(setting *PresenceSetting) error {
+	spm.lockPresenceSetting.Lock()
+	origSetting := *spm.presenceSetting
+
+	err := setting.Save(spm.db.meta)
+	if err != nil {
+		spm.lockPresenceSetting.Unlock()
+		return err
+	}
+
+	newSetting := *setting
+	spm.presenceSetting = &newSetting
+	spm.lockPresenceSetting.Unlock()
+
+	isPresenceChanged := origSetting.IsHidePresence != setting.IsHidePresence
+	isHideTyping := !origSetting.IsHideTyping && setting.IsHideTyping
+	if !isPresenceChanged && !isHideTyping {
+		return nil
+	}
+
+	for _, entity := range spm.Entities() {
+		pm := entity.PM().(*ProtocolManager)
+
+		if isPresenceChanged && setting.IsHidePresence {
+			err = pm.sendPresence(PresenceStatusOffline, nil)
+		} else if isPresenceChanged {
+			err = pm.SendPresence(nil)
+		}
+		if err != nil {
+			log.Warn("SetPresenceSetting: unable to send presence", "entity", entity.GetID(), "e", err)
+		}
+
+		if isHideTyping {
+			err = pm.sendTyping(false)
+			if err != nil {
+				log.Warn("SetPresenceSetting: unable to send typing", "entity", entity.GetID(), "e", err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -14,6 +14,7 @@ require (
 	github.com/go-stack/stack v1.8.0
 	github.com/golang/snappy v0.0.0-20180518054509-2e65f85255db
 	github.com/gorilla/mux v1.7.1
+	github.com/gorilla/websocket v1.4.0
 	github.com/huin/goupnp v1.0.0
 	github.com/influxdata/influxdb v1.7.6 // indirect
 	github.com/ipfs/go-cid v0.0.1
@@ -30,7 +31,8 @@ require (
 	github.com/mattn/go-colorable v0.1.1
 	github.com/multiformats/go-multiaddr v0.0.2
 	github.com/multiformats/go-multihash v0.0.1
-	github.com/naoina/toml v0.1.1 // indirect
+	github.com/naoina/go-stringutil v0.1.0 // indirect
+	github.com/naoina/toml v0.1.1
 	github.com/nbio/st v0.0.0-20140626010706-e9e8d9816f32 // indirect
 	github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646
 	github.com/oklog/oklog v0.3.2
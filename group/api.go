@@ -0,0 +1,276 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package group
+
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+type PrivateAPI struct {
+	b *Backend
+}
+
+func NewPrivateAPI(b *Backend) *PrivateAPI {
+	return &PrivateAPI{b}
+}
+
+/**********
+ * Op
+ **********/
+
+func (api *PrivateAPI) CreateGroup(title []byte) (*BackendGetGroup, error) {
+	return api.b.CreateGroup(title)
+}
+
+func (api *PrivateAPI) CreateMessage(entityID string, message [][]byte, mediaIDs []string) (*BackendCreateMessage, error) {
+	return api.b.CreateMessage(
+		[]byte(entityID),
+		message,
+		mediaIDs,
+	)
+}
+
+func (api *PrivateAPI) DeleteGroup(entityID string) (bool, error) {
+	return api.b.DeleteGroup([]byte(entityID))
+}
+
+func (api *PrivateAPI) MarkGroupSeen(entityID string) (types.Timestamp, error) {
+	return api.b.MarkGroupSeen([]byte(entityID))
+}
+
+/**********
+ * Join
+ **********/
+
+func (api *PrivateAPI) ShowURL(entityID string) (*pkgservice.BackendJoinURL, error) {
+	return api.b.ShowURL([]byte(entityID))
+}
+
+func (api *PrivateAPI) JoinGroup(url []byte) (*pkgservice.BackendJoinRequest, error) {
+	return api.b.JoinGroup(url)
+}
+
+func (api *PrivateAPI) LeaveGroup(entityID string) (bool, error) {
+	return api.b.LeaveGroup([]byte(entityID))
+}
+
+func (api *PrivateAPI) AddMember(entityID string, userID string) (bool, error) {
+	return api.b.AddMember([]byte(entityID), []byte(userID))
+}
+
+func (api *PrivateAPI) DeleteMember(entityID string, userID string) (bool, error) {
+	return api.b.DeleteMember([]byte(entityID), []byte(userID))
+}
+
+/**********
+ * Get Group
+ **********/
+
+func (api *PrivateAPI) GetGroup(entityID string) (*BackendGetGroup, error) {
+	return api.b.GetGroup([]byte(entityID))
+}
+
+func (api *PrivateAPI) GetRawGroup(entityID string) (*Group, error) {
+	return api.b.GetRawGroup([]byte(entityID))
+}
+
+func (api *PrivateAPI) GetGroupList(startingGroupID string, limit int) ([]*BackendGetGroup, error) {
+	return api.b.GetGroupList(
+		[]byte(startingGroupID),
+		limit,
+		pttdb.ListOrderNext,
+	)
+}
+
+/**********
+ * Get Message
+ **********/
+
+func (api *PrivateAPI) GetMessageList(entityID string, startingMessageID string, limit int, listOrder pttdb.ListOrder) ([]*BackendGetMessage, error) {
+	return api.b.GetMessageList(
+		[]byte(entityID),
+		[]byte(startingMessageID),
+		limit,
+		listOrder,
+	)
+}
+
+func (api *PrivateAPI) GetMessageBlockList(entityID string, messageID string, dummy0 string, dummy1 pkgservice.ContentType, dummy2 uint32, limit uint32) ([]*BackendMessageBlock, error) {
+	return api.b.GetMessageBlockList([]byte(entityID), []byte(messageID), limit)
+}
+
+/**********
+ * GroupOplog
+ **********/
+
+func (api *PrivateAPI) GetGroupOplogList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*GroupOplog, error) {
+	return api.b.GetGroupOplogList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetPendingGroupOplogMasterList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*GroupOplog, error) {
+	return api.b.GetPendingGroupOplogMasterList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetPendingGroupOplogInternalList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*GroupOplog, error) {
+	return api.b.GetPendingGroupOplogInternalList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetGroupOplogMerkleNodeList(entityID string, level uint8, startKey []byte, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.BackendMerkleNode, error) {
+	return api.b.GetGroupOplogMerkleNodeList([]byte(entityID), pkgservice.MerkleTreeLevel(level), startKey, limit, listOrder)
+}
+
+func (api *PrivateAPI) ForceSyncGroupMerkle(entityID string) (bool, error) {
+	return api.b.ForceSyncGroupMerkle([]byte(entityID))
+}
+
+/**********
+ * MasterOplog
+ **********/
+
+func (api *PrivateAPI) GetMasterOplogList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.MasterOplog, error) {
+	return api.b.GetMasterOplogList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetPendingMasterOplogMasterList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.MasterOplog, error) {
+	return api.b.GetPendingMasterOplogMasterList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetPendingMasterOplogInternalList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.MasterOplog, error) {
+	return api.b.GetPendingMasterOplogInternalList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetMasterOplogMerkleNodeList(entityID string, level uint8, startKey []byte, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.BackendMerkleNode, error) {
+	return api.b.GetMasterOplogMerkleNodeList([]byte(entityID), pkgservice.MerkleTreeLevel(level), startKey, limit, listOrder)
+}
+
+func (api *PrivateAPI) ForceSyncMasterMerkle(entityID string) (bool, error) {
+	return api.b.ForceSyncMasterMerkle([]byte(entityID))
+}
+
+/**********
+ * MemberOplog
+ **********/
+
+func (api *PrivateAPI) GetMemberOplogList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.MemberOplog, error) {
+	return api.b.GetMemberOplogList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetPendingMemberOplogMasterList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.MemberOplog, error) {
+	return api.b.GetPendingMemberOplogMasterList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetPendingMemberOplogInternalList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.MemberOplog, error) {
+	return api.b.GetPendingMemberOplogInternalList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetMemberOplogMerkleNodeList(entityID string, level uint8, startKey []byte, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.BackendMerkleNode, error) {
+	return api.b.GetMemberOplogMerkleNodeList([]byte(entityID), pkgservice.MerkleTreeLevel(level), startKey, limit, listOrder)
+}
+
+func (api *PrivateAPI) ForceSyncMemberMerkle(entityID string) (bool, error) {
+	return api.b.ForceSyncMemberMerkle([]byte(entityID))
+}
+
+/**********
+ * OpKeyOplog
+ **********/
+
+func (api *PrivateAPI) GetOpKeyOplogList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.OpKeyOplog, error) {
+	return api.b.GetOpKeyOplogList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetPendingOpKeyOplogMasterList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.OpKeyOplog, error) {
+	return api.b.GetPendingOpKeyOplogMasterList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+func (api *PrivateAPI) GetPendingOpKeyOplogInternalList(entityID string, logID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.OpKeyOplog, error) {
+	return api.b.GetPendingOpKeyOplogInternalList([]byte(entityID), []byte(logID), limit, listOrder)
+}
+
+/**********
+ * Master
+ **********/
+
+func (api *PrivateAPI) GetMasterListFromCache(entityID string) ([]*pkgservice.Master, error) {
+	return api.b.GetMasterListFromCache([]byte(entityID))
+}
+
+func (api *PrivateAPI) GetMasterList(entityID string, startID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.Master, error) {
+	return api.b.GetMasterList([]byte(entityID), []byte(startID), limit, listOrder)
+}
+
+/**********
+ * Member
+ **********/
+
+func (api *PrivateAPI) GetMemberList(entityID string, startID string, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.Member, error) {
+	return api.b.GetMemberList([]byte(entityID), []byte(startID), limit, listOrder)
+}
+
+/**********
+ * MyMemberOplog
+ **********/
+
+func (api *PrivateAPI) GetMyMemberLog(entityID string) (*pkgservice.BaseOplog, error) {
+	return api.b.GetMyMemberLog([]byte(entityID))
+}
+
+/**********
+ * Op
+ **********/
+
+func (api *PrivateAPI) ShowValidateKey() (*types.PttID, error) {
+	return api.b.ShowValidateKey()
+}
+
+func (api *PrivateAPI) ValidateValidateKey(key string) (bool, error) {
+	return api.b.ValidateValidateKey([]byte(key))
+}
+
+func (api *PrivateAPI) GetOpKeyInfos(entityID string) ([]*pkgservice.KeyInfo, error) {
+	return api.b.GetOpKeys([]byte(entityID))
+}
+
+func (api *PrivateAPI) RevokeOpKey(entityID string, keyID string, myKey string) (bool, error) {
+	return api.b.RevokeOpKey([]byte(entityID), []byte(keyID), []byte(myKey))
+}
+
+func (api *PrivateAPI) GetOpKeyInfosFromDB(entityID string) ([]*pkgservice.KeyInfo, error) {
+	return api.b.GetOpKeysFromDB([]byte(entityID))
+}
+
+/**********
+ * Peer
+ **********/
+
+func (api *PrivateAPI) CountPeers(entityID string) (int, error) {
+	return api.b.CountPeers([]byte(entityID))
+}
+
+func (api *PrivateAPI) GetPeers(entityID string) ([]*pkgservice.BackendPeer, error) {
+	return api.b.GetPeers([]byte(entityID))
+}
+
+func (api *PrivateAPI) ForceSync(entityID string) (bool, error) {
+	return api.b.ForceSync([]byte(entityID))
+}
+
+func (api *PrivateAPI) ForceOpKey(entityID string) (bool, error) {
+	return api.b.ForceOpKey([]byte(entityID))
+}
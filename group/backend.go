@@ -0,0 +1,84 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package group
+
+import (
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type Backend struct {
+	*pkgservice.BaseService
+
+	db *groupDB
+}
+
+func NewBackend(ctx *pkgservice.RouterContext, cfg *Config, router pkgservice.Router) (*Backend, error) {
+	// init group
+	db, err := newGroupDB(cfg.DataDir, cfg.DBEngine)
+	if err != nil {
+		return nil, err
+	}
+
+	// backend
+	backend := &Backend{
+		db: db,
+	}
+
+	// spm
+	spm, err := NewServiceProtocolManager(router, backend, db)
+	if err != nil {
+		return nil, err
+	}
+
+	// base-ptt-service
+	b, err := pkgservice.NewBaseService(router, spm)
+	if err != nil {
+		return nil, err
+	}
+	backend.BaseService = b
+
+	return backend, nil
+
+}
+
+func (b *Backend) Start() error {
+	b.SPM().(*ServiceProtocolManager).Start()
+	return nil
+}
+
+func (b *Backend) Stop() error {
+	b.SPM().(*ServiceProtocolManager).Stop()
+
+	b.db.Close()
+	return nil
+}
+
+func (b *Backend) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "group",
+			Version:   "1.0",
+			Service:   NewPrivateAPI(b),
+			Public:    pkgservice.IsPrivateAsPublic,
+		},
+	}
+}
+
+func (b *Backend) Name() string {
+	return "group"
+}
@@ -0,0 +1,386 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package group
+
+import (
+	"reflect"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+/**********
+ * Group
+ **********/
+
+func (b *Backend) CreateGroup(title []byte) (*BackendGetGroup, error) {
+	g, err := b.SPM().(*ServiceProtocolManager).CreateGroup(title)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupToBackendGetGroup(g), nil
+}
+
+func (b *Backend) GetGroup(entityIDBytes []byte) (*BackendGetGroup, error) {
+	g, err := b.GetRawGroup(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	g.LastSeen, _ = g.LoadLastSeen()
+	g.MessageCreateTS, _ = g.LoadMessageCreateTS()
+
+	return groupToBackendGetGroup(g), nil
+}
+
+func (b *Backend) GetRawGroup(entityIDBytes []byte) (*Group, error) {
+
+	entity, err := b.EntityIDToEntity(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*Group), nil
+}
+
+func (b *Backend) GetGroupList(startIDBytes []byte, limit int, listOrder pttdb.ListOrder) ([]*BackendGetGroup, error) {
+
+	startID, err := types.UnmarshalTextPttID(startIDBytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	groupList, err := b.SPM().(*ServiceProtocolManager).GetGroupList(startID, limit, listOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	backendGroupList := make([]*BackendGetGroup, len(groupList))
+	for i, g := range groupList {
+		backendGroupList[i] = groupToBackendGetGroup(g)
+	}
+
+	return backendGroupList, nil
+}
+
+func (b *Backend) DeleteGroup(entityIDBytes []byte) (bool, error) {
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return false, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	err = pm.DeleteGroup()
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (b *Backend) MarkGroupSeen(entityIDBytes []byte) (types.Timestamp, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return types.ZeroTimestamp, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	return pm.SaveLastSeen(types.ZeroTimestamp)
+}
+
+/**********
+ * Join Group
+ **********/
+
+func (b *Backend) ShowURL(entityIDBytes []byte) (*pkgservice.BackendJoinURL, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	ptt := b.Router()
+	myID := ptt.GetMyEntity().GetID()
+	myNodeID := ptt.MyNodeID()
+
+	keyInfo, err := pm.GetJoinKey()
+	if err != nil {
+		return nil, err
+	}
+
+	g := pm.Entity().(*Group)
+
+	return pkgservice.MarshalBackendJoinURL(myID, myNodeID, keyInfo, g.Title, pkgservice.PathJoinGroup)
+}
+
+func (b *Backend) JoinGroup(groupURL []byte) (*pkgservice.BackendJoinRequest, error) {
+	joinRequest, err := pkgservice.ParseBackendJoinURL(groupURL, pkgservice.PathJoinGroup)
+	log.Debug("JoinGroup: after parse", "joinRequest", joinRequest, "e", err)
+	if err != nil {
+		return nil, err
+	}
+
+	ptt := b.Router()
+	myNodeID := ptt.MyNodeID()
+	if reflect.DeepEqual(myNodeID, joinRequest.NodeID) {
+		return nil, ErrInvalidNode
+	}
+
+	spm := b.SPM().(*ServiceProtocolManager)
+	err = ptt.GetMyEntity().JoinEntity(joinRequest, spm.HandleApproveJoinGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	backendJoinRequest := pkgservice.JoinRequestToBackendJoinRequest(joinRequest)
+
+	return backendJoinRequest, nil
+}
+
+/**********
+ * Member
+ **********/
+
+func (b *Backend) AddMember(entityIDBytes []byte, userIDBytes []byte) (bool, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return false, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	userID, err := types.UnmarshalTextPttID(userIDBytes, false)
+	if err != nil {
+		return false, err
+	}
+
+	_, _, err = pm.AddMember(userID, false)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (b *Backend) DeleteMember(entityIDBytes []byte, userIDBytes []byte) (bool, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return false, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	userID, err := types.UnmarshalTextPttID(userIDBytes, false)
+	if err != nil {
+		return false, err
+	}
+
+	return pm.DeleteMember(userID)
+}
+
+func (b *Backend) LeaveGroup(entityIDBytes []byte) (bool, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return false, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	myID := b.Router().GetMyEntity().GetID()
+
+	return pm.DeleteMember(myID)
+}
+
+/**********
+ * GroupOplog
+ **********/
+
+func (b *Backend) GetGroupOplogList(entityIDBytes []byte, logIDBytes []byte, limit int, listOrder pttdb.ListOrder) ([]*GroupOplog, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	logID, err := types.UnmarshalTextPttID(logIDBytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return pm.GetGroupOplogList(logID, limit, listOrder, types.StatusAlive)
+}
+
+func (b *Backend) GetPendingGroupOplogMasterList(entityIDBytes []byte, logIDBytes []byte, limit int, listOrder pttdb.ListOrder) ([]*GroupOplog, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	logID, err := types.UnmarshalTextPttID(logIDBytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return pm.GetGroupOplogList(logID, limit, listOrder, types.StatusPending)
+}
+
+func (b *Backend) GetPendingGroupOplogInternalList(entityIDBytes []byte, logIDBytes []byte, limit int, listOrder pttdb.ListOrder) ([]*GroupOplog, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	logID, err := types.UnmarshalTextPttID(logIDBytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return pm.GetGroupOplogList(logID, limit, listOrder, types.StatusInternalPending)
+}
+
+func (b *Backend) GetGroupOplogMerkleNodeList(entityIDBytes []byte, level pkgservice.MerkleTreeLevel, startKey []byte, limit int, listOrder pttdb.ListOrder) ([]*pkgservice.BackendMerkleNode, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	merkleNodeList, err := pm.GetGroupOplogMerkleNodeList(level, startKey, limit, listOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*pkgservice.BackendMerkleNode, len(merkleNodeList))
+	for i, eachMerkleNode := range merkleNodeList {
+		results[i] = pkgservice.MerkleNodeToBackendMerkleNode(eachMerkleNode)
+	}
+
+	return results, nil
+}
+
+func (b *Backend) ForceSyncGroupMerkle(entityIDBytes []byte) (bool, error) {
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return false, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	return pm.ForceSyncGroupMerkle()
+}
+
+func (b *Backend) CreateMessage(entityIDBytes []byte, message [][]byte, mediaIDStrs []string) (*BackendCreateMessage, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	lenMediaIDs := len(mediaIDStrs)
+	var mediaIDs []*types.PttID = nil
+	var eachMediaID *types.PttID
+	if len(mediaIDStrs) != 0 {
+		mediaIDs = make([]*types.PttID, lenMediaIDs)
+		for i, mediaIDStr := range mediaIDStrs {
+			eachMediaID, err = types.UnmarshalTextPttID([]byte(mediaIDStr), false)
+			if err != nil {
+				return nil, err
+			}
+			mediaIDs[i] = eachMediaID
+		}
+	}
+
+	theMessage, err := pm.CreateMessage(message, mediaIDs)
+	log.Debug("CreateMessage: after CreateMessage", "e", err)
+	if err != nil {
+		return nil, err
+	}
+
+	return messageToBackendCreateMessage(theMessage), nil
+}
+
+func (b *Backend) GetMessageList(entityIDBytes []byte, startIDBytes []byte, limit int, listOrder pttdb.ListOrder) ([]*BackendGetMessage, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	startID, err := types.UnmarshalTextPttID(startIDBytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	messageList, err := pm.GetMessageList(startID, limit, listOrder, true)
+	if err != nil {
+		return nil, err
+	}
+
+	backendMessageList := make([]*BackendGetMessage, len(messageList))
+	for i, message := range messageList {
+		backendMessageList[i] = messageToBackendGetMessage(message)
+	}
+
+	return backendMessageList, nil
+}
+
+func (b *Backend) GetMessageBlockList(entityIDBytes []byte, msgIDBytes []byte, limit uint32) ([]*BackendMessageBlock, error) {
+
+	thePM, err := b.EntityIDToPM(entityIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pm := thePM.(*ProtocolManager)
+
+	msgID, err := types.UnmarshalTextPttID(msgIDBytes, false)
+	if err != nil {
+		return nil, err
+	}
+	if msgID == nil {
+		return nil, types.ErrInvalidID
+	}
+
+	msg, contentBlocks, err := pm.GetMessageBlockList(msgID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	blockInfo := msg.GetBlockInfo()
+	if blockInfo == nil {
+		return nil, pkgservice.ErrInvalidBlock
+	}
+	blockInfoID := blockInfo.ID
+
+	backendMsgBlocks := make([]*BackendMessageBlock, len(contentBlocks))
+	for i, contentBlock := range contentBlocks {
+		backendMsgBlocks[i] = contentBlockToBackendMessageBlock(msg, blockInfoID, contentBlock)
+	}
+
+	return backendMsgBlocks, nil
+}
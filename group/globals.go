@@ -0,0 +1,159 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package group
+
+import (
+	"path/filepath"
+
+	"github.com/ailabstw/go-pttai-core/node"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+// config
+var (
+	DefaultConfig = Config{
+		DataDir: filepath.Join(node.DefaultDataDir(), "group"),
+
+		MaxSyncRandomSeconds: 7,
+		MinSyncRandomSeconds: 5,
+	}
+)
+
+// db
+var (
+	DBGroupIdxPrefix         = []byte(".grix")
+	DBGroupPrefix            = []byte(".grdb")
+	DBGroupOplogPrefix       = []byte(".grlg")
+	DBGroupIdxOplogPrefix    = []byte(".grig")
+	DBGroupMerkleOplogPrefix = []byte(".grmk")
+
+	DBMessagePrefix    = []byte(".gmdb")
+	DBMessageIdxPrefix = []byte(".gmix")
+
+	DBLastSeenPrefix        = []byte(".grls")
+	DBMessageCreateTSPrefix = []byte(".grmc")
+
+	DBGroupNodePrefix = []byte(".grnd")
+)
+
+// protocol
+const (
+	_ pkgservice.OpType = iota + pkgservice.NMsg
+	// group-oplog
+	AddGroupOplogMsg //30
+	AddGroupOplogsMsg
+
+	AddPendingGroupOplogMsg
+	AddPendingGroupOplogsMsg
+
+	SyncGroupOplogMsg
+	SyncGroupOplogAckMsg
+	SyncGroupOplogNewOplogsMsg
+	SyncGroupOplogNewOplogsAckMsg
+
+	InvalidSyncGroupOplogMsg
+
+	ForceSyncGroupOplogMsg
+	ForceSyncGroupOplogAckMsg
+	ForceSyncGroupOplogByMerkleMsg
+	ForceSyncGroupOplogByMerkleAckMsg
+	ForceSyncGroupOplogByOplogAckMsg
+
+	SyncPendingGroupOplogMsg
+	SyncPendingGroupOplogAckMsg
+
+	SyncCreateMessageMsg
+	SyncCreateMessageAckMsg
+
+	SyncCreateMessageBlockMsg
+	SyncCreateMessageBlockAckMsg
+)
+
+// max-masters
+const (
+	MaxMasters = 1
+)
+
+// sync
+var (
+	MaxSyncRandomSeconds = 20
+	MinSyncRandomSeconds = 10
+)
+
+// op-key
+var (
+	RenewOpKeySeconds  int64 = 86400
+	ExpireOpKeySeconds int64 = 259200
+)
+
+// group
+const (
+	MaxTitleLength = 128
+)
+
+// message
+const (
+	NFirstLineInBlock = 20
+)
+
+/*
+groupDB is the dbs of the group-service of a node.
+*/
+type groupDB struct {
+	groupCore pttdb.Storage
+	group     pttdb.IndexBatch
+
+	meta pttdb.Storage
+}
+
+func newGroupDB(dataDir string, engine pttdb.Engine) (*groupDB, error) {
+	var err error
+
+	db := &groupDB{}
+
+	db.groupCore, err = pttdb.NewStorageWithEngine(engine, "group", dataDir)
+	if err != nil {
+		return nil, err
+	}
+	db.group, err = pttdb.NewStorageBatch(db.groupCore)
+	if err != nil {
+		return nil, err
+	}
+
+	db.meta, err = pttdb.NewStorageWithEngine(engine, "groupmeta", dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func (db *groupDB) Close() {
+	if db.groupCore != nil {
+		db.groupCore.Close()
+		db.groupCore = nil
+	}
+	if db.group != nil {
+		db.group = nil
+	}
+
+	if db.meta != nil {
+		db.meta.Close()
+		db.meta = nil
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package group
+
+import (
+	"encoding/json"
+
+	"github.com/ailabstw/go-pttai-core/common"
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+type Group struct {
+	*pkgservice.BaseEntity `json:"e"`
+
+	UpdateTS types.Timestamp `json:"UT"`
+
+	Title []byte `json:"T,omitempty"`
+
+	// get from other dbs
+	LastSeen        types.Timestamp `json:"-"`
+	MessageCreateTS types.Timestamp `json:"-"`
+
+	db *groupDB
+}
+
+func NewEmptyGroup(db *groupDB) *Group {
+	return &Group{BaseEntity: &pkgservice.BaseEntity{SyncInfo: &pkgservice.BaseSyncInfo{}}, db: db}
+}
+
+func NewGroup(title []byte, router pkgservice.Router, service pkgservice.Service, spm pkgservice.ServiceProtocolManager, dbLock *types.LockMap) (*Group, error) {
+
+	myID := router.GetMyEntity().GetID()
+	id, err := pkgservice.NewPttIDWithMyID(myID)
+	if err != nil {
+		return nil, err
+	}
+
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return nil, err
+	}
+
+	db := spm.(*ServiceProtocolManager).db
+
+	e := pkgservice.NewBaseEntity(id, ts, myID, types.StatusInit, db.group, dbLock)
+	e.EntityType = pkgservice.EntityTypePrivate
+
+	g := &Group{
+		BaseEntity: e,
+		UpdateTS:   ts,
+
+		Title: title,
+
+		db: db,
+	}
+
+	err = g.Init(router, service, spm)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("NewGroup: done", "createTS", g.CreateTS)
+
+	return g, nil
+}
+
+func (g *Group) GetUpdateTS() types.Timestamp {
+	return g.UpdateTS
+}
+
+func (g *Group) SetUpdateTS(ts types.Timestamp) {
+	g.UpdateTS = ts
+}
+
+func (g *Group) Init(router pkgservice.Router, service pkgservice.Service, spm pkgservice.ServiceProtocolManager) error {
+
+	g.db = spm.(*ServiceProtocolManager).db
+	g.SetDB(g.db.group, spm.GetDBLock())
+
+	g.SetName(string(g.Title))
+
+	err := g.InitPM(router, service)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (g *Group) InitPM(router pkgservice.Router, service pkgservice.Service) error {
+	pm, err := NewProtocolManager(g, router, service)
+	if err != nil {
+		return err
+	}
+
+	g.BaseEntity.Init(pm, router, service)
+
+	return nil
+}
+
+func (g *Group) MarshalKey() ([]byte, error) {
+	marshalTimestamp, err := g.JoinTS.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return common.Concat([][]byte{DBGroupPrefix, marshalTimestamp, g.ID[:]})
+}
+
+func (g *Group) IdxKey() ([]byte, error) {
+	return common.Concat([][]byte{DBGroupIdxPrefix, g.ID[:]})
+}
+
+func (g *Group) Marshal() ([]byte, error) {
+	return json.Marshal(g)
+}
+
+func (g *Group) Unmarshal(theBytes []byte) error {
+	err := json.Unmarshal(theBytes, g)
+	if err != nil {
+		return err
+	}
+
+	// postprocess
+
+	return nil
+}
+
+func (g *Group) Save(isLocked bool) error {
+	if !isLocked {
+		err := g.Lock()
+		if err != nil {
+			return err
+		}
+		defer g.Unlock()
+	}
+
+	key, err := g.MarshalKey()
+	if err != nil {
+		return err
+	}
+
+	marshaled, err := g.Marshal()
+	if err != nil {
+		return err
+	}
+
+	idxKey, err := g.IdxKey()
+	if err != nil {
+		return err
+	}
+
+	idx := &pttdb.Index{Keys: [][]byte{key}, UpdateTS: g.UpdateTS}
+
+	kvs := []*pttdb.KeyVal{
+		&pttdb.KeyVal{
+			K: key,
+			V: marshaled,
+		},
+	}
+
+	_, err = g.db.group.ForcePutAll(idxKey, idx, kvs)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (g *Group) SaveLastSeen(ts types.Timestamp) error {
+	g.LastSeen = ts
+
+	key, err := g.MarshalLastSeenKey()
+	if err != nil {
+		return err
+	}
+
+	return g.saveTS(key, ts)
+}
+
+func (g *Group) LoadLastSeen() (types.Timestamp, error) {
+	key, err := g.MarshalLastSeenKey()
+	if err != nil {
+		return types.ZeroTimestamp, err
+	}
+
+	return g.loadTS(key)
+}
+
+func (g *Group) MarshalLastSeenKey() ([]byte, error) {
+	return common.Concat([][]byte{DBLastSeenPrefix, g.ID[:]})
+}
+
+func (g *Group) SaveMessageCreateTS(ts types.Timestamp) error {
+	g.MessageCreateTS = ts
+
+	key, err := g.MarshalMessageCreateTSKey()
+	if err != nil {
+		return err
+	}
+
+	return g.saveTS(key, ts)
+}
+
+func (g *Group) LoadMessageCreateTS() (types.Timestamp, error) {
+	key, err := g.MarshalMessageCreateTSKey()
+	if err != nil {
+		return types.ZeroTimestamp, err
+	}
+
+	return g.loadTS(key)
+}
+
+func (g *Group) MarshalMessageCreateTSKey() ([]byte, error) {
+	return common.Concat([][]byte{DBMessageCreateTSPrefix, g.ID[:]})
+}
+
+func (g *Group) saveTS(key []byte, ts types.Timestamp) error {
+	val := &pttdb.DBable{
+		UpdateTS: ts,
+	}
+	marshaled, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.db.groupCore.TryPut(key, marshaled, ts)
+	if err != nil && err != pttdb.ErrInvalidUpdateTS {
+		return err
+	}
+
+	return nil
+}
+
+func (g *Group) loadTS(key []byte) (types.Timestamp, error) {
+	data, err := g.db.groupCore.Get(key)
+	if err != nil {
+		if err == pttdb.ErrNotFound {
+			err = nil
+		}
+		return types.ZeroTimestamp, err
+	}
+
+	val := &pttdb.DBable{}
+	err = json.Unmarshal(data, val)
+	if err != nil {
+		return types.ZeroTimestamp, err
+	}
+
+	return val.UpdateTS, nil
+}
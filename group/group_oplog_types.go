@@ -0,0 +1,83 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package group
+
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+// optype
+const (
+	GroupOpTypeInvalid pkgservice.OpType = iota
+
+	GroupOpTypeCreateGroup
+	GroupOpTypeDeleteGroup
+
+	GroupOpTypeCreateMessage
+
+	GroupOpTypeCreateMedia
+
+	NGroupOpType
+)
+
+type GroupOpCreateGroup struct {
+	Title []byte `json:"T"`
+}
+
+type GroupOpDeleteGroup struct {
+}
+
+type GroupOpCreateMessage struct {
+	BlockInfoID *types.PttID `json:"BID"`
+	Hashs       [][][]byte   `json:"H"`
+	NBlock      int          `json:"NB"`
+
+	MediaIDs []*types.PttID `json:"ms,omitempty"`
+}
+
+type GroupOpCreateMedia struct {
+	BlockInfoID *types.PttID `json:"BID"` // resized content-block-id
+	Hashs       [][][]byte   `json:"H"`
+	NBlock      int          `json:"NB"`
+}
+
+func (o *GroupOpCreateGroup) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.Bytes(o.Title)
+}
+
+func (o *GroupOpDeleteGroup) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+}
+
+func (o *GroupOpCreateMessage) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.PttID(o.BlockInfoID)
+	enc.Hashs(o.Hashs)
+	enc.Int64(int64(o.NBlock))
+	enc.PttIDs(o.MediaIDs)
+}
+
+func init() {
+	pkgservice.RegisterOpData(DBGroupOplogPrefix, map[pkgservice.OpType]func() pkgservice.CanonicalOpData{
+		GroupOpTypeCreateGroup:   func() pkgservice.CanonicalOpData { return &GroupOpCreateGroup{} },
+		GroupOpTypeDeleteGroup:   func() pkgservice.CanonicalOpData { return &GroupOpDeleteGroup{} },
+		GroupOpTypeCreateMessage: func() pkgservice.CanonicalOpData { return &GroupOpCreateMessage{} },
+		GroupOpTypeCreateMedia:   func() pkgservice.CanonicalOpData { return &pkgservice.OpCreateMedia{} },
+	})
+}
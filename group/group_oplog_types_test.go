@@ -0,0 +1,75 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package group
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupOpData_EncodeCanonical(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	id := &types.PttID{1, 2}
+	ts := types.Timestamp{Ts: 1, NanoTs: 2}
+
+	// prepare test-cases
+	tests := []struct {
+		name string
+		op   pkgservice.OpType
+		data pkgservice.CanonicalOpData
+		want string
+	}{
+		{name: "CreateGroup", op: GroupOpTypeCreateGroup, data: &GroupOpCreateGroup{Title: []byte("title")}, want: "0101057469746c65"},
+		{name: "DeleteGroup", op: GroupOpTypeDeleteGroup, data: &GroupOpDeleteGroup{}, want: "0101"},
+		{name: "CreateMessage", op: GroupOpTypeCreateMessage, data: &GroupOpCreateMessage{BlockInfoID: id, Hashs: [][][]byte{{{7}}}, NBlock: 1, MediaIDs: []*types.PttID{id}}, want: "010128010200000000000000000000000000000000000000000000000000000000000000000000000000000101010702012801020000000000000000000000000000000000000000000000000000000000000000000000000000"},
+		{name: "CreateMedia", op: GroupOpTypeCreateMedia, data: &pkgservice.OpCreateMedia{BlockInfoID: id, Hashs: [][][]byte{{{7}}}, NBlock: 1, MediaType: pkgservice.MediaTypePNG}, want: "0101280102000000000000000000000000000000000000000000000000000000000000000000000000000000010101070202"},
+	}
+
+	// run test
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pkgservice.MarshalCanonicalData(tt.data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, hex.EncodeToString(got))
+
+			// the same for the op-data unmarshaled from the peers.
+			o, err := pkgservice.NewOplog(id, ts, id, tt.op, tt.data, nil, id, DBGroupOplogPrefix, nil, nil, nil)
+			assert.NoError(t, err)
+			o.SignV = pkgservice.SignVersionCanonical
+			want, err := o.MarshalCanonical()
+			assert.NoError(t, err)
+
+			marshaled, err := o.Marshal()
+			assert.NoError(t, err)
+			o2 := &pkgservice.BaseOplog{}
+			err = o2.Unmarshal(marshaled)
+			assert.NoError(t, err)
+			o2.SetDB(nil, id, DBGroupOplogPrefix, nil, nil, nil)
+
+			got2, err := o2.MarshalCanonical()
+			assert.NoError(t, err)
+			assert.Equal(t, want, got2)
+		})
+	}
+}
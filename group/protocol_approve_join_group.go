@@ -0,0 +1,86 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package group
+
+import (
+	"encoding/json"
+
+	"github.com/ailabstw/go-pttai-core/log"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func (pm *ProtocolManager) GetJoinType(hash *common.Address) (pkgservice.JoinType, error) {
+	if pm.IsJoinKeyHash(hash) {
+		return pkgservice.JoinTypeGroup, nil
+	}
+
+	return pkgservice.JoinTypeInvalid, pkgservice.ErrInvalidData
+}
+
+/*
+ApproveJoin approves the join-group and remembers the node of the joiner for LoadPeers. (invitor)
+*/
+func (pm *ProtocolManager) ApproveJoin(joinEntity *pkgservice.JoinEntity, keyInfo *pkgservice.KeyInfo, peer *pkgservice.PttPeer) (*pkgservice.KeyInfo, interface{}, error) {
+
+	opKey, data, err := pm.BaseProtocolManager.ApproveJoin(joinEntity, keyInfo, peer)
+	log.Debug("ApproveJoin: after BaseProtocolManager.ApproveJoin", "e", err, "entity", pm.Entity().IDString())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pm.SavePeerNodeID(peer.GetID())
+
+	return opKey, data, nil
+}
+
+/*
+HandleApproveJoinGroup creates the group from the approve-join of the invitor. (joiner)
+*/
+func (spm *ServiceProtocolManager) HandleApproveJoinGroup(dataBytes []byte, joinRequest *pkgservice.JoinRequest, peer *pkgservice.PttPeer) error {
+
+	approveJoin := &pkgservice.ApproveJoin{Data: &pkgservice.ApproveJoinEntity{Entity: NewEmptyGroup(spm.db)}}
+	err := json.Unmarshal(dataBytes, approveJoin)
+	if err != nil {
+		log.Error("HandleApproveJoinGroup: unable to unmarshal", "e", err)
+		return err
+	}
+
+	approveJoinEntity, ok := approveJoin.Data.(*pkgservice.ApproveJoinEntity)
+	if !ok {
+		return pkgservice.ErrInvalidData
+	}
+
+	// group is not synced among my devices through me-oplog (isForceNotBroadcast)
+	entity, err := spm.CreateJoinEntity(approveJoinEntity, peer, nil, true, true, true, false, true)
+	log.Debug("HandleApproveJoinGroup: after CreateJoinEntity", "e", err)
+	if err != nil {
+		return err
+	}
+
+	g, ok := entity.(*Group)
+	if !ok {
+		return pkgservice.ErrInvalidEntity
+	}
+
+	g.SaveMessageCreateTS(g.GetCreateTS())
+
+	pm := g.PM().(*ProtocolManager)
+	pm.SavePeerNodeID(peer.GetID())
+
+	return nil
+}
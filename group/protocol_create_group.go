@@ -0,0 +1,68 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package group
+
+import (
+	"github.com/ailabstw/go-pttai-core/log"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+func (spm *ServiceProtocolManager) CreateGroup(title []byte) (*Group, error) {
+
+	myID := spm.Router().GetMyEntity().GetID()
+
+	if len(title) == 0 || len(title) > MaxTitleLength {
+		return nil, ErrInvalidTitle
+	}
+
+	data := &GroupOpCreateGroup{
+		Title: title,
+	}
+	entity, err := spm.CreateEntity(data, GroupOpTypeCreateGroup, spm.NewGroup, spm.NewGroupOplogWithTS, nil, nil)
+	log.Debug("CreateGroup: after CreateEntity", "e", err)
+	if err != nil {
+		return nil, err
+	}
+
+	g, ok := entity.(*Group)
+	if !ok {
+		return nil, pkgservice.ErrInvalidEntity
+	}
+
+	// save message-create-ts
+	g.SaveMessageCreateTS(g.GetCreateTS())
+	g.SaveLastSeen(g.GetCreateTS())
+
+	log.Debug("CreateGroup: done", "group", g.ID, "myID", myID)
+
+	return g, nil
+}
+
+func (spm *ServiceProtocolManager) NewGroup(theData pkgservice.CreateData, router pkgservice.Router, service pkgservice.Service) (pkgservice.Entity, pkgservice.OpData, error) {
+
+	data, ok := theData.(*GroupOpCreateGroup)
+	if !ok {
+		return nil, nil, pkgservice.ErrInvalidData
+	}
+
+	g, err := NewGroup(data.Title, router, service, spm, spm.GetDBLock())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return g, data, nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package group
+
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+)
+
+func (spm *ServiceProtocolManager) GetGroupList(startingGroupID *types.PttID, limit int, listOrder pttdb.ListOrder) ([]*Group, error) {
+	iter, err := spm.getGroupIter(startingGroupID, listOrder)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Release()
+
+	iterFunc := pttdb.GetFuncIter(iter, listOrder)
+
+	groupList := make([]*Group, 0)
+
+	i := 0
+	for iterFunc() {
+		if limit > 0 && i >= limit {
+			break
+		}
+
+		k := iter.Key()
+		log.Debug("GetGroupList (in-for-loop)", "k", k)
+		v := iter.Value()
+
+		eachGroup := NewEmptyGroup(spm.db)
+		err := eachGroup.Unmarshal(v)
+		if err != nil {
+			continue
+		}
+
+		ts, _ := eachGroup.LoadLastSeen()
+		eachGroup.LastSeen = ts
+
+		ts, _ = eachGroup.LoadMessageCreateTS()
+		eachGroup.MessageCreateTS = ts
+
+		groupList = append(groupList, eachGroup)
+
+		i++
+	}
+
+	return groupList, nil
+}
+
+func (spm *ServiceProtocolManager) getGroupIter(startingID *types.PttID, listOrder pttdb.ListOrder) (iterator.Iterator, error) {
+	if startingID == nil {
+		return spm.db.group.DB().NewIteratorWithPrefix(nil, DBGroupPrefix, listOrder)
+	}
+
+	// key
+	g := NewEmptyGroup(spm.db)
+	g.SetID(startingID)
+
+	idxKey, err := g.IdxKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := spm.db.group.GetKeyByIdxKey(idxKey, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// iter
+	iter, err := spm.db.group.DB().NewIteratorWithPrefix(key, DBGroupPrefix, listOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	return iter, nil
+}
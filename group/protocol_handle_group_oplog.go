@@ -0,0 +1,194 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package group
+
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+type ProcessGroupInfo struct {
+	CreateMessageInfo map[types.PttID]*pkgservice.BaseOplog
+
+	CreateMediaInfo map[types.PttID]*pkgservice.BaseOplog
+
+	BlockInfo map[types.PttID]*pkgservice.BaseOplog
+
+	GroupInfo map[types.PttID]*pkgservice.BaseOplog
+}
+
+func NewProcessGroupInfo() *ProcessGroupInfo {
+	return &ProcessGroupInfo{
+		CreateMessageInfo: make(map[types.PttID]*pkgservice.BaseOplog),
+
+		CreateMediaInfo: make(map[types.PttID]*pkgservice.BaseOplog),
+
+		BlockInfo: make(map[types.PttID]*pkgservice.BaseOplog),
+
+		GroupInfo: make(map[types.PttID]*pkgservice.BaseOplog),
+	}
+}
+
+/**********
+ * Process Oplog
+ **********/
+
+func (pm *ProtocolManager) processGroupLog(oplog *pkgservice.BaseOplog, processInfo pkgservice.ProcessInfo) (origLogs []*pkgservice.BaseOplog, err error) {
+	info, ok := processInfo.(*ProcessGroupInfo)
+	if !ok {
+		return nil, pkgservice.ErrInvalidData
+	}
+
+	switch oplog.Op {
+	case GroupOpTypeDeleteGroup:
+		origLogs, err = pm.handleDeleteGroupLogs(oplog, info)
+	case GroupOpTypeCreateMessage:
+		origLogs, err = pm.handleCreateMessageLogs(oplog, info)
+
+	case GroupOpTypeCreateMedia:
+	}
+	return
+}
+
+/**********
+ * Process Pending Oplog
+ **********/
+
+func (pm *ProtocolManager) processPendingGroupLog(oplog *pkgservice.BaseOplog, processInfo pkgservice.ProcessInfo) (isToSign types.Bool, origLogs []*pkgservice.BaseOplog, err error) {
+	info, ok := processInfo.(*ProcessGroupInfo)
+	if !ok {
+		return false, nil, pkgservice.ErrInvalidData
+	}
+
+	switch oplog.Op {
+	case GroupOpTypeDeleteGroup:
+		isToSign, origLogs, err = pm.handlePendingDeleteGroupLogs(oplog, info)
+
+	case GroupOpTypeCreateMessage:
+		isToSign, origLogs, err = pm.handlePendingCreateMessageLogs(oplog, info)
+
+	case GroupOpTypeCreateMedia:
+	}
+
+	return
+}
+
+/**********
+ * Postprocess Oplog
+ **********/
+
+func (pm *ProtocolManager) postprocessGroupOplogs(processInfo pkgservice.ProcessInfo, toBroadcastLogs []*pkgservice.BaseOplog, peer *pkgservice.PttPeer, isPending bool) (err error) {
+	info, ok := processInfo.(*ProcessGroupInfo)
+	if !ok {
+		err = pkgservice.ErrInvalidData
+	}
+
+	// message
+	createMessageIDs := pkgservice.ProcessInfoToSyncIDList(info.CreateMessageInfo, GroupOpTypeCreateMessage)
+
+	log.Debug("postprocessGroupOplogs: to syncMessage", "createMessageIDs", createMessageIDs)
+
+	pm.SyncMessage(SyncCreateMessageMsg, createMessageIDs, peer)
+
+	// blocks
+	blockIDs := pkgservice.ProcessInfoToSyncBlockIDList(info.BlockInfo, GroupOpTypeCreateMessage)
+
+	log.Debug("postprocessGroupOplogs: to syncBlock", "blockIDs", blockIDs)
+
+	pm.SyncBlock(SyncCreateMessageBlockMsg, blockIDs, peer)
+
+	pm.broadcastGroupOplogsCore(toBroadcastLogs)
+
+	// post-delete-group
+	if !isPending && len(info.GroupInfo) > 0 {
+		pm.postdeleteGroup(nil, false)
+	}
+
+	return
+}
+
+/**********
+ * Set Newest Oplog
+ **********/
+
+func (pm *ProtocolManager) SetNewestGroupOplog(oplog *pkgservice.BaseOplog) (err error) {
+	var isNewer types.Bool
+
+	switch oplog.Op {
+	case GroupOpTypeDeleteGroup:
+	case GroupOpTypeCreateMessage:
+		isNewer, err = pm.setNewestCreateMessageLog(oplog)
+	case GroupOpTypeCreateMedia:
+	}
+
+	oplog.IsNewer = isNewer
+
+	return
+}
+
+/**********
+ * Handle Failed Oplog
+ **********/
+
+func (pm *ProtocolManager) HandleFailedGroupOplog(oplog *pkgservice.BaseOplog) (err error) {
+
+	switch oplog.Op {
+	case GroupOpTypeDeleteGroup:
+	case GroupOpTypeCreateMessage:
+		err = pm.handleFailedCreateMessageLog(oplog)
+	case GroupOpTypeCreateMedia:
+	}
+
+	return
+}
+
+/**********
+ * Handle Failed Oplog
+ **********/
+
+func (pm *ProtocolManager) HandleFailedValidGroupOplog(oplog *pkgservice.BaseOplog, processInfo pkgservice.ProcessInfo) (err error) {
+
+	info, ok := processInfo.(*ProcessGroupInfo)
+	if !ok {
+		return pkgservice.ErrInvalidData
+	}
+
+	switch oplog.Op {
+	case GroupOpTypeDeleteGroup:
+	case GroupOpTypeCreateMessage:
+		err = pm.handleFailedValidCreateMessageLog(oplog, info)
+	case GroupOpTypeCreateMedia:
+	}
+
+	return
+}
+
+func (pm *ProtocolManager) postprocessFailedValidGroupOplogs(processInfo pkgservice.ProcessInfo, peer *pkgservice.PttPeer) error {
+
+	return nil
+}
+
+/**********
+ * Postsync Oplog
+ **********/
+
+func (pm *ProtocolManager) postsyncGroupOplogs(peer *pkgservice.PttPeer) (err error) {
+	err = pm.SyncPendingGroupOplog(peer)
+
+	return
+}
@@ -0,0 +1,244 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package group
+
+import pkgservice "github.com/ailabstw/go-pttai-core/service"
+
+/**********
+ * AddGroupOplog
+ **********/
+
+func (pm *ProtocolManager) HandleAddGroupOplog(dataBytes []byte, peer *pkgservice.PttPeer) error {
+	return pm.HandleAddOplog(dataBytes, pm.HandleGroupOplogs, peer)
+}
+
+func (pm *ProtocolManager) HandleAddGroupOplogs(dataBytes []byte, peer *pkgservice.PttPeer) error {
+	return pm.HandleAddOplogs(dataBytes, pm.HandleGroupOplogs, peer)
+}
+
+func (pm *ProtocolManager) HandleAddPendingGroupOplog(dataBytes []byte, peer *pkgservice.PttPeer) error {
+	return pm.HandleAddPendingOplog(dataBytes, pm.HandlePendingGroupOplogs, peer)
+}
+
+func (pm *ProtocolManager) HandleAddPendingGroupOplogs(dataBytes []byte, peer *pkgservice.PttPeer) error {
+	return pm.HandleAddPendingOplogs(dataBytes, pm.HandlePendingGroupOplogs, peer)
+}
+
+/**********
+ * SyncGroupOplog
+ **********/
+
+func (pm *ProtocolManager) HandleSyncGroupOplog(dataBytes []byte, peer *pkgservice.PttPeer) error {
+	return pm.HandleSyncOplog(
+		dataBytes,
+		peer,
+
+		pm.groupOplogMerkle,
+
+		ForceSyncGroupOplogByMerkleMsg,
+		ForceSyncGroupOplogByMerkleAckMsg,
+		InvalidSyncGroupOplogMsg,
+		SyncGroupOplogAckMsg,
+	)
+}
+
+func (pm *ProtocolManager) HandleForceSyncGroupOplogByMerkle(dataBytes []byte, peer *pkgservice.PttPeer) error {
+	return pm.HandleForceSyncOplogByMerkle(
+		dataBytes,
+		peer,
+
+		ForceSyncGroupOplogByMerkleAckMsg,
+		ForceSyncGroupOplogByOplogAckMsg,
+
+		pm.SetGroupDB,
+		pm.SetNewestGroupOplog,
+
+		pm.groupOplogMerkle,
+	)
+}
+
+func (pm *ProtocolManager) HandleForceSyncGroupOplogByMerkleAck(dataBytes []byte, peer *pkgservice.PttPeer) error {
+	return pm.HandleForceSyncOplogByMerkleAck(
+		dataBytes,
+		peer,
+
+		ForceSyncGroupOplogByMerkleMsg,
+
+		pm.groupOplogMerkle,
+	)
+}
+
+func (pm *ProtocolManager) HandleForceSyncGroupOplogByOplogAck(dataBytes []byte, peer *pkgservice.PttPeer) error {
+	return pm.HandleForceSyncOplogByOplogAck(
+		dataBytes,
+		peer,
+
+		pm.HandleGroupOplogs,
+
+		pm.groupOplogMerkle,
+	)
+}
+
+func (pm *ProtocolManager) HandleForceSyncGroupOplog(dataBytes []byte, peer *pkgservice.PttPeer) error {
+	return pm.HandleForceSyncOplog(
+		dataBytes,
+		peer,
+
+		pm.groupOplogMerkle,
+		ForceSyncGroupOplogAckMsg,
+	)
+}
+
+func (pm *ProtocolManager) HandleForceSyncGroupOplogAck(dataBytes []byte, peer *pkgservice.PttPeer) error {
+
+	info := NewProcessGroupInfo()
+
+	return pm.HandleForceSyncOplogAck(
+		dataBytes,
+		peer,
+
+		pm.groupOplogMerkle,
+		info,
+
+		pm.SetGroupDB,
+		pm.HandleFailedValidGroupOplog,
+		pm.SetNewestGroupOplog,
+		pm.postprocessFailedValidGroupOplogs,
+
+		SyncGroupOplogNewOplogsMsg,
+	)
+}
+
+func (pm *ProtocolManager) HandleSyncGroupOplogInvalid(dataBytes []byte, peer *pkgservice.PttPeer) error {
+
+	return pm.HandleSyncOplogInvalid(
+		dataBytes,
+		peer,
+
+		pm.groupOplogMerkle,
+		ForceSyncGroupOplogMsg,
+	)
+}
+
+func (pm *ProtocolManager) HandleSyncGroupOplogAck(dataBytes []byte, peer *pkgservice.PttPeer) error {
+	return pm.HandleSyncOplogAck(
+		dataBytes,
+		peer,
+
+		pm.groupOplogMerkle,
+		pm.SetGroupDB,
+		pm.SetNewestGroupOplog,
+		pm.postsyncGroupOplogs,
+
+		SyncGroupOplogNewOplogsMsg,
+	)
+}
+
+func (pm *ProtocolManager) HandleSyncNewGroupOplog(dataBytes []byte, peer *pkgservice.PttPeer) error {
+	return pm.HandleSyncOplogNewOplogs(
+		dataBytes,
+		peer,
+
+		pm.SetGroupDB,
+		pm.HandleGroupOplogs,
+		pm.SetNewestGroupOplog,
+
+		SyncGroupOplogNewOplogsAckMsg,
+	)
+}
+
+func (pm *ProtocolManager) HandleSyncNewGroupOplogAck(dataBytes []byte, peer *pkgservice.PttPeer) error {
+	return pm.HandleSyncOplogNewOplogsAck(
+		dataBytes,
+		peer,
+
+		pm.SetGroupDB,
+		pm.HandleGroupOplogs,
+		pm.postsyncGroupOplogs,
+
+		pm.groupOplogMerkle,
+	)
+}
+
+/**********
+ * SyncPendingGroupOplog
+ **********/
+
+func (pm *ProtocolManager) HandleSyncPendingGroupOplog(dataBytes []byte, peer *pkgservice.PttPeer) error {
+	return pm.HandleSyncPendingOplog(
+		dataBytes,
+		peer,
+
+		pm.HandlePendingGroupOplogs,
+		pm.SetGroupDB,
+		pm.HandleFailedGroupOplog,
+
+		SyncPendingGroupOplogAckMsg,
+	)
+}
+
+func (pm *ProtocolManager) HandleSyncPendingGroupOplogAck(dataBytes []byte, peer *pkgservice.PttPeer) error {
+	return pm.HandleSyncPendingOplogAck(
+		dataBytes,
+		peer,
+
+		pm.HandlePendingGroupOplogs,
+	)
+}
+
+/**********
+ * HandleOplogs
+ **********/
+
+func (pm *ProtocolManager) HandleGroupOplogs(oplogs []*pkgservice.BaseOplog, peer *pkgservice.PttPeer, isUpdateSyncTime bool) error {
+
+	info := NewProcessGroupInfo()
+
+	return pkgservice.HandleOplogs(
+		oplogs,
+		peer,
+
+		isUpdateSyncTime,
+		pm,
+		info,
+		pm.groupOplogMerkle,
+
+		pm.SetGroupDB,
+		pm.processGroupLog,
+		pm.postprocessGroupOplogs,
+	)
+}
+
+func (pm *ProtocolManager) HandlePendingGroupOplogs(oplogs []*pkgservice.BaseOplog, peer *pkgservice.PttPeer) error {
+
+	info := NewProcessGroupInfo()
+
+	return pkgservice.HandlePendingOplogs(
+		oplogs,
+		peer,
+
+		pm,
+		info,
+
+		pm.groupOplogMerkle,
+
+		pm.SetGroupDB,
+		pm.processPendingGroupLog,
+		pm.processGroupLog,
+		pm.postprocessGroupOplogs,
+	)
+}
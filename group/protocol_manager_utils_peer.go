@@ -0,0 +1,111 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package group
+
+import (
+	"github.com/ailabstw/go-pttai-core/common"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+/*
+LoadPeers dials the nodes that we met in the join-process of the group.
+Group members are not necessarily friends, so we do not have their user-nodes from the profiles.
+*/
+func (pm *ProtocolManager) LoadPeers() error {
+	log.Debug("LoadPeers: start", "entity", pm.Entity().GetID())
+
+	nodeIDs, err := pm.GetPeerNodeIDs()
+	if err != nil {
+		return err
+	}
+	if len(nodeIDs) == 0 {
+		return nil
+	}
+
+	opKey, err := pm.GetOldestOpKey(false)
+	if err != nil {
+		return err
+	}
+
+	ptt := pm.Router()
+	for _, nodeID := range nodeIDs {
+		ptt.AddDial(nodeID, opKey.Hash, pkgservice.PeerTypeMember, true)
+	}
+
+	return nil
+}
+
+func (pm *ProtocolManager) SavePeerNodeID(nodeID *discover.NodeID) error {
+	key, err := pm.marshalPeerNodeKey(nodeID)
+	if err != nil {
+		return err
+	}
+
+	return pm.db.meta.Put(key, nodeID[:])
+}
+
+func (pm *ProtocolManager) GetPeerNodeIDs() ([]*discover.NodeID, error) {
+	prefix, err := pm.marshalPeerNodeKey(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := pm.db.meta.NewIteratorWithPrefix(nil, prefix, pttdb.ListOrderNext)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Release()
+
+	nodeIDs := make([]*discover.NodeID, 0)
+	for iter.Next() {
+		nodeID := &discover.NodeID{}
+		copy(nodeID[:], iter.Value())
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+
+	return nodeIDs, nil
+}
+
+func (pm *ProtocolManager) CleanPeerNodeIDs() error {
+	nodeIDs, err := pm.GetPeerNodeIDs()
+	if err != nil {
+		return err
+	}
+
+	var key []byte
+	for _, nodeID := range nodeIDs {
+		key, err = pm.marshalPeerNodeKey(nodeID)
+		if err != nil {
+			continue
+		}
+		pm.db.meta.Delete(key)
+	}
+
+	return nil
+}
+
+func (pm *ProtocolManager) marshalPeerNodeKey(nodeID *discover.NodeID) ([]byte, error) {
+	entityID := pm.Entity().GetID()
+	if nodeID == nil {
+		return common.Concat([][]byte{DBGroupNodePrefix, entityID[:]})
+	}
+
+	return common.Concat([][]byte{DBGroupNodePrefix, entityID[:], nodeID[:]})
+}
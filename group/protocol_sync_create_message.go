@@ -14,7 +14,7 @@
 // You should have received a copy of the GNU Lesser General Public License
 // along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
 
-package friend
+package group
 
 import (
 	"github.com/ailabstw/go-pttai-core/log"
@@ -61,10 +61,10 @@ func (pm *ProtocolManager) HandleSyncCreateMessageBlockAck(dataBytes []byte, pee
 		peer,
 
 		obj,
-		pm.friendOplogMerkle,
+		pm.groupOplogMerkle,
 
-		pm.SetFriendDB,
+		pm.SetGroupDB,
 		pm.postcreateMessage,
-		pm.broadcastFriendOplogCore,
+		pm.broadcastGroupOplogCore,
 	)
 }
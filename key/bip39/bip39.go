@@ -0,0 +1,129 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package bip39 encodes the entropy as the mnemonic words and decodes the mnemonic words back to the entropy,
+following BIP39 (https://github.com/bitcoin/bips/blob/master/bip-0039.mediawiki) with the english wordlist.
+
+Different from BIP39, the size of the entropy is not limited to 128-256 bits,
+any multiple of 32 bits no less than 128 bits is accepted,
+so that the identity key and the postfix are able to be encoded together.
+*/
+package bip39
+
+import (
+	"crypto/sha256"
+	"strings"
+)
+
+const (
+	NBitsInWord = 11
+
+	MinEntropySize = 16
+)
+
+/*
+NewMnemonic encodes the entropy as the mnemonic words, with the checksum as the first len(entropy) / 4 bits of sha256(entropy).
+*/
+func NewMnemonic(entropy []byte) (string, error) {
+	lenEntropy := len(entropy)
+	if lenEntropy < MinEntropySize || lenEntropy%4 != 0 {
+		return "", ErrInvalidEntropy
+	}
+
+	checksum := sha256.Sum256(entropy)
+
+	nBitsChecksum := lenEntropy / 4
+	nBits := lenEntropy*8 + nBitsChecksum
+	nWords := nBits / NBitsInWord
+
+	data := make([]byte, 0, lenEntropy+len(checksum))
+	data = append(data, entropy...)
+	data = append(data, checksum[:]...)
+
+	words := make([]string, nWords)
+	for i := 0; i < nWords; i++ {
+		idx := 0
+		for j := 0; j < NBitsInWord; j++ {
+			idx = idx<<1 | getBit(data, i*NBitsInWord+j)
+		}
+		words[i] = English[idx]
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+/*
+MnemonicToEntropy decodes the mnemonic words back to the entropy, and validates the checksum.
+*/
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+
+	nBits := len(words) * NBitsInWord
+	if nBits%33 != 0 {
+		return nil, ErrInvalidMnemonic
+	}
+
+	nBitsChecksum := nBits / 33
+	lenEntropy := nBitsChecksum * 4
+	if lenEntropy < MinEntropySize {
+		return nil, ErrInvalidMnemonic
+	}
+
+	data := make([]byte, (nBits+7)/8)
+	for i, word := range words {
+		idx, ok := englishIndex[strings.ToLower(word)]
+		if !ok {
+			return nil, ErrInvalidMnemonic
+		}
+
+		for j := 0; j < NBitsInWord; j++ {
+			bit := (idx >> uint(NBitsInWord-1-j)) & 1
+			setBit(data, i*NBitsInWord+j, bit)
+		}
+	}
+
+	entropy := data[:lenEntropy]
+	checksum := sha256.Sum256(entropy)
+	for i := 0; i < nBitsChecksum; i++ {
+		if getBit(data, lenEntropy*8+i) != getBit(checksum[:], i) {
+			return nil, ErrInvalidChecksum
+		}
+	}
+
+	return entropy, nil
+}
+
+/*
+IsMnemonicValid checks whether the mnemonic words are valid.
+*/
+func IsMnemonicValid(mnemonic string) bool {
+	_, err := MnemonicToEntropy(mnemonic)
+
+	return err == nil
+}
+
+func getBit(data []byte, i int) int {
+	return int(data[i/8]>>uint(7-i%8)) & 1
+}
+
+func setBit(data []byte, i int, bit int) {
+	if bit == 0 {
+		return
+	}
+
+	data[i/8] |= 1 << uint(7-i%8)
+}
@@ -0,0 +1,126 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package bip39
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestNewMnemonic(t *testing.T) {
+	setupTest(t)
+	defer teardownTest(t)
+
+	// test vectors from BIP39
+	tests := []struct {
+		name     string
+		entropy  string
+		mnemonic string
+	}{
+		{
+			name:     "zero",
+			entropy:  "00000000000000000000000000000000",
+			mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		},
+		{
+			name:     "7f",
+			entropy:  "7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			mnemonic: "legal winner thank year wave sausage worth useful legal winner thank yellow",
+		},
+		{
+			name:     "ff",
+			entropy:  "ffffffffffffffffffffffffffffffff",
+			mnemonic: "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+		},
+		{
+			name:     "256 bits",
+			entropy:  "0000000000000000000000000000000000000000000000000000000000000000",
+			mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entropy, _ := hex.DecodeString(tt.entropy)
+			got, err := NewMnemonic(entropy)
+			if err != nil {
+				t.Errorf("NewMnemonic() e: %v", err)
+			}
+			if got != tt.mnemonic {
+				t.Errorf("NewMnemonic() = %v, want %v", got, tt.mnemonic)
+			}
+
+			gotEntropy, err := MnemonicToEntropy(got)
+			if err != nil {
+				t.Errorf("MnemonicToEntropy() e: %v", err)
+			}
+			if !bytes.Equal(gotEntropy, entropy) {
+				t.Errorf("MnemonicToEntropy() = %x, want %x", gotEntropy, entropy)
+			}
+		})
+	}
+}
+
+func TestMnemonicToEntropy(t *testing.T) {
+	setupTest(t)
+	defer teardownTest(t)
+
+	// key + postfix
+	entropy := make([]byte, 52)
+	for i := range entropy {
+		entropy[i] = byte(i)
+	}
+
+	mnemonic, err := NewMnemonic(entropy)
+	if err != nil {
+		t.Errorf("NewMnemonic: e: %v", err)
+	}
+	if n := len(strings.Fields(mnemonic)); n != 39 {
+		t.Errorf("NewMnemonic: words: %v want: 39", n)
+	}
+
+	got, err := MnemonicToEntropy(mnemonic)
+	if err != nil || !bytes.Equal(got, entropy) {
+		t.Errorf("MnemonicToEntropy: %x e: %v", got, err)
+	}
+
+	// invalid checksum
+	words := strings.Fields(mnemonic)
+	if words[0] == "abandon" {
+		words[0] = "ability"
+	} else {
+		words[0] = "abandon"
+	}
+	_, err = MnemonicToEntropy(strings.Join(words, " "))
+	if err != ErrInvalidChecksum {
+		t.Errorf("MnemonicToEntropy: e: %v want: %v", err, ErrInvalidChecksum)
+	}
+
+	// invalid word
+	_, err = MnemonicToEntropy("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon pttai")
+	if err != ErrInvalidMnemonic {
+		t.Errorf("MnemonicToEntropy: e: %v want: %v", err, ErrInvalidMnemonic)
+	}
+
+	// invalid entropy
+	_, err = NewMnemonic(entropy[:15])
+	if err != ErrInvalidEntropy {
+		t.Errorf("NewMnemonic: e: %v want: %v", err, ErrInvalidEntropy)
+	}
+}
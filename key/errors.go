@@ -20,4 +20,9 @@ import "errors"
 
 var (
 	ErrInvalidKey = errors.New("invalid key")
+
+	ErrKeyLocked              = errors.New("key is locked")
+	ErrInvalidPassphrase      = errors.New("invalid passphrase")
+	ErrInvalidKeystore        = errors.New("invalid keystore")
+	ErrInvalidKeystoreVersion = errors.New("invalid keystore version")
 )
@@ -30,6 +30,32 @@ var (
 	BitSize = 256
 )
 
+// keystore
+const (
+	KeystoreVersion uint8 = 1
+
+	KeystoreCipher = "aes-256-gcm"
+	KeystoreKDF    = "scrypt"
+
+	KeystoreSaltSize = 32
+	KeystoreDKLen    = 32
+)
+
+var (
+	// the same as the standard scrypt params in go-ethereum keystore.
+	ScryptN = 1 << 18
+	ScryptR = 8
+	ScryptP = 1
+)
+
+// the bounds of the scrypt params in the keystores to decrypt,
+// not to exhaust the memory / cpu with the crafted keystores.
+const (
+	MaxScryptN = 1 << 20
+	MaxScryptR = 8
+	MaxScryptP = 16
+)
+
 func init() {
 	priv := new(ecdsa.PrivateKey)
 	priv.PublicKey.Curve = crypto.S256()
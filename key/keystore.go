@@ -0,0 +1,305 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package key
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/scrypt"
+)
+
+/*
+The keystore stores the private-key encrypted with the key derived from the passphrase.
+
+The key is derived with scrypt, and the private-key is encrypted with aes-256-gcm,
+with the version as the additional data. The scrypt params are kept in the keystore,
+so the keystores are still able to be decrypted if the default params are changed.
+
+The plaintext key-files (hex, compatible with crypto.LoadECDSA) are still able to be loaded,
+and are migrated to the keystore when saving with the passphrase.
+*/
+
+type ScryptParams struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+type KeystoreCrypto struct {
+	Cipher     string        `json:"cipher"`
+	CipherText string        `json:"ciphertext"`
+	Nonce      string        `json:"nonce"`
+	KDF        string        `json:"kdf"`
+	KDFParams  *ScryptParams `json:"kdfparams"`
+}
+
+type Keystore struct {
+	Version uint8           `json:"version"`
+	Crypto  *KeystoreCrypto `json:"crypto"`
+}
+
+/*
+EncryptKey encrypts the private-key with the passphrase as the keystore json.
+*/
+func EncryptKey(key *ecdsa.PrivateKey, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, ErrInvalidPassphrase
+	}
+
+	salt := make([]byte, KeystoreSaltSize)
+	_, err := io.ReadFull(rand.Reader, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &ScryptParams{
+		N:     ScryptN,
+		R:     ScryptR,
+		P:     ScryptP,
+		DKLen: KeystoreDKLen,
+		Salt:  hex.EncodeToString(salt),
+	}
+
+	aead, err := keystoreAEAD(passphrase, params)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	keyBytes := crypto.FromECDSA(key)
+	cipherText := aead.Seal(nil, nonce, keyBytes, []byte{KeystoreVersion})
+
+	ks := &Keystore{
+		Version: KeystoreVersion,
+		Crypto: &KeystoreCrypto{
+			Cipher:     KeystoreCipher,
+			CipherText: hex.EncodeToString(cipherText),
+			Nonce:      hex.EncodeToString(nonce),
+			KDF:        KeystoreKDF,
+			KDFParams:  params,
+		},
+	}
+
+	return json.Marshal(ks)
+}
+
+/*
+DecryptKey decrypts the keystore json with the passphrase.
+*/
+func DecryptKey(keyJSON []byte, passphrase string) (*ecdsa.PrivateKey, error) {
+	ks := &Keystore{}
+	err := json.Unmarshal(keyJSON, ks)
+	if err != nil {
+		return nil, ErrInvalidKeystore
+	}
+
+	if ks.Version != KeystoreVersion {
+		return nil, ErrInvalidKeystoreVersion
+	}
+
+	if ks.Crypto == nil || ks.Crypto.KDFParams == nil || ks.Crypto.Cipher != KeystoreCipher || ks.Crypto.KDF != KeystoreKDF {
+		return nil, ErrInvalidKeystore
+	}
+
+	nonce, err := hex.DecodeString(ks.Crypto.Nonce)
+	if err != nil {
+		return nil, ErrInvalidKeystore
+	}
+
+	cipherText, err := hex.DecodeString(ks.Crypto.CipherText)
+	if err != nil {
+		return nil, ErrInvalidKeystore
+	}
+
+	// not to derive the key with the crafted params.
+	err = validateScryptParams(ks.Crypto.KDFParams)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := keystoreAEAD(passphrase, ks.Crypto.KDFParams)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) != aead.NonceSize() {
+		return nil, ErrInvalidKeystore
+	}
+
+	keyBytes, err := aead.Open(nil, nonce, cipherText, []byte{ks.Version})
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+
+	return crypto.ToECDSA(keyBytes)
+}
+
+/*
+validateScryptParams checks the scrypt params of the keystore:
+N is a power of 2 within MaxScryptN, r / p within MaxScryptR / MaxScryptP, the key-length for aes-256 and the non-empty salt.
+*/
+func validateScryptParams(params *ScryptParams) error {
+	if params.N <= 1 || params.N > MaxScryptN || params.N&(params.N-1) != 0 {
+		return ErrInvalidKeystore
+	}
+
+	if params.R <= 0 || params.R > MaxScryptR || params.P <= 0 || params.P > MaxScryptP {
+		return ErrInvalidKeystore
+	}
+
+	if params.DKLen != KeystoreDKLen {
+		return ErrInvalidKeystore
+	}
+
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil || len(salt) == 0 {
+		return ErrInvalidKeystore
+	}
+
+	return nil
+}
+
+func keystoreAEAD(passphrase string, params *ScryptParams) (cipher.AEAD, error) {
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil {
+		return nil, ErrInvalidKeystore
+	}
+
+	derivedKey, err := scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, params.DKLen)
+	if err != nil {
+		return nil, ErrInvalidKeystore
+	}
+
+	block, err := aes.NewCipher(derivedKey)
+	if err != nil {
+		return nil, ErrInvalidKeystore
+	}
+
+	return cipher.NewGCM(block)
+}
+
+/*
+IsKeystore checks whether the content of the key-file is the keystore json.
+*/
+func IsKeystore(content []byte) bool {
+	content = bytes.TrimSpace(content)
+
+	return len(content) != 0 && content[0] == '{'
+}
+
+/*
+IsKeystoreFile checks whether the key-file is the keystore.
+*/
+func IsKeystoreFile(filename string) (bool, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return false, err
+	}
+
+	return IsKeystore(content), nil
+}
+
+/*
+LoadKeyFile loads the private-key from either the keystore or the plaintext key-file.
+
+Return: key, isKeystore, error
+*/
+func LoadKeyFile(filename string, passphrase string) (*ecdsa.PrivateKey, bool, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !IsKeystore(content) {
+		key, err := crypto.LoadECDSA(filename)
+		return key, false, err
+	}
+
+	if passphrase == "" {
+		return nil, true, ErrKeyLocked
+	}
+
+	key, err := DecryptKey(content, passphrase)
+	return key, true, err
+}
+
+/*
+SaveKeyFile saves the private-key as the keystore if passphrase is set, as the plaintext key-file otherwise.
+
+The key-file is written to a tmp-file in the same dir and renamed to filename,
+so the key-file is never left partially written.
+*/
+func SaveKeyFile(filename string, key *ecdsa.PrivateKey, passphrase string) error {
+	var content []byte
+	var err error
+	if passphrase == "" {
+		content = []byte(hex.EncodeToString(crypto.FromECDSA(key)))
+	} else {
+		content, err = EncryptKey(key, passphrase)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeFileAtomic(filename, content)
+}
+
+func writeFileAtomic(filename string, content []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpFilename := f.Name()
+
+	_, err = f.Write(content)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpFilename, 0600)
+	}
+	if err == nil {
+		err = os.Rename(tmpFilename, filename)
+	}
+	if err != nil {
+		os.Remove(tmpFilename)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package key
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestKeystore(t *testing.T) {
+	setupTest(t)
+	defer teardownTest(t)
+
+	ScryptN = 1 << 4
+	defer func() { ScryptN = 1 << 18 }()
+
+	privKey, _ := GenerateKey()
+
+	keyJSON, err := EncryptKey(privKey, "test-passphrase")
+	if err != nil {
+		t.Errorf("EncryptKey: e: %v", err)
+	}
+	if !IsKeystore(keyJSON) {
+		t.Errorf("IsKeystore: false")
+	}
+
+	// decrypt
+	decrypted, err := DecryptKey(keyJSON, "test-passphrase")
+	if err != nil {
+		t.Errorf("DecryptKey: e: %v", err)
+	}
+	if !reflect.DeepEqual(crypto.FromECDSA(decrypted), crypto.FromECDSA(privKey)) {
+		t.Errorf("DecryptKey: key not match")
+	}
+
+	// invalid passphrase
+	_, err = DecryptKey(keyJSON, "test-passphrase2")
+	if err != ErrInvalidPassphrase {
+		t.Errorf("DecryptKey: e: %v want: %v", err, ErrInvalidPassphrase)
+	}
+}
+
+func TestDecryptKey_InvalidScryptParams(t *testing.T) {
+	setupTest(t)
+	defer teardownTest(t)
+
+	ScryptN = 1 << 4
+	defer func() { ScryptN = 1 << 18 }()
+
+	privKey, _ := GenerateKey()
+	keyJSON, _ := EncryptKey(privKey, "test-passphrase")
+
+	tests := []struct {
+		name   string
+		modify func(params *ScryptParams)
+	}{
+		{"N not power of 2", func(params *ScryptParams) { params.N = 3 << 4 }},
+		{"N too small", func(params *ScryptParams) { params.N = 1 }},
+		{"N too large", func(params *ScryptParams) { params.N = MaxScryptN << 1 }},
+		{"R zero", func(params *ScryptParams) { params.R = 0 }},
+		{"R too large", func(params *ScryptParams) { params.R = MaxScryptR + 1 }},
+		{"P too large", func(params *ScryptParams) { params.P = MaxScryptP + 1 }},
+		{"DKLen", func(params *ScryptParams) { params.DKLen = 16 }},
+		{"empty salt", func(params *ScryptParams) { params.Salt = "" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ks := &Keystore{}
+			json.Unmarshal(keyJSON, ks)
+			tt.modify(ks.Crypto.KDFParams)
+			modified, _ := json.Marshal(ks)
+
+			_, err := DecryptKey(modified, "test-passphrase")
+			if err != ErrInvalidKeystore {
+				t.Errorf("DecryptKey: e: %v want: %v", err, ErrInvalidKeystore)
+			}
+		})
+	}
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	setupTest(t)
+	defer teardownTest(t)
+
+	ScryptN = 1 << 4
+	defer func() { ScryptN = 1 << 18 }()
+
+	dir, _ := ioutil.TempDir("", "keystore")
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "key")
+	privKey, _ := GenerateKey()
+
+	// plaintext
+	SaveKeyFile(filename, privKey, "")
+	loaded, isKeystore, err := LoadKeyFile(filename, "test-passphrase")
+	if err != nil || isKeystore {
+		t.Errorf("LoadKeyFile: isKeystore: %v e: %v", isKeystore, err)
+	}
+	if !reflect.DeepEqual(crypto.FromECDSA(loaded), crypto.FromECDSA(privKey)) {
+		t.Errorf("LoadKeyFile: key not match")
+	}
+
+	// keystore
+	SaveKeyFile(filename, privKey, "test-passphrase")
+	_, isKeystore, err = LoadKeyFile(filename, "")
+	if err != ErrKeyLocked || !isKeystore {
+		t.Errorf("LoadKeyFile: isKeystore: %v e: %v want: %v", isKeystore, err, ErrKeyLocked)
+	}
+
+	loaded, _, err = LoadKeyFile(filename, "test-passphrase")
+	if err != nil {
+		t.Errorf("LoadKeyFile: e: %v", err)
+	}
+	if !reflect.DeepEqual(crypto.FromECDSA(loaded), crypto.FromECDSA(privKey)) {
+		t.Errorf("LoadKeyFile: key not match")
+	}
+}
+
+func TestSaveKeyFile(t *testing.T) {
+	setupTest(t)
+	defer teardownTest(t)
+
+	ScryptN = 1 << 4
+	defer func() { ScryptN = 1 << 18 }()
+
+	dir, _ := ioutil.TempDir("", "keystore")
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "key")
+	privKey, _ := GenerateKey()
+
+	// overwrite the existing key-file
+	ioutil.WriteFile(filename, []byte("orig"), 0644)
+	err := SaveKeyFile(filename, privKey, "test-passphrase")
+	if err != nil {
+		t.Errorf("SaveKeyFile: e: %v", err)
+	}
+
+	info, _ := os.Stat(filename)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("SaveKeyFile: mode: %v", info.Mode())
+	}
+	tmpFilenames, _ := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	if len(tmpFilenames) != 0 {
+		t.Errorf("SaveKeyFile: tmp-files: %v", tmpFilenames)
+	}
+
+	// the key-file is kept if not able to save
+	err = SaveKeyFile(filepath.Join(dir, "not-exists", "key"), privKey, "")
+	if err == nil {
+		t.Errorf("SaveKeyFile: no error in the not-existing dir")
+	}
+	loaded, _, err := LoadKeyFile(filename, "test-passphrase")
+	if err != nil || !reflect.DeepEqual(crypto.FromECDSA(loaded), crypto.FromECDSA(privKey)) {
+		t.Errorf("LoadKeyFile: e: %v", err)
+	}
+}
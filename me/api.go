@@ -17,10 +17,13 @@
 package me
 
 import (
+	"context"
+
 	"github.com/ailabstw/go-pttai-core/account"
 	"github.com/ailabstw/go-pttai-core/common/types"
 	"github.com/ailabstw/go-pttai-core/pttdb"
 	pkgservice "github.com/ailabstw/go-pttai-core/service"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 type PrivateAPI struct {
@@ -141,6 +144,32 @@ func (api *PrivateAPI) RemoveFriendRequests(entityID string, hash []byte) (bool,
 	return api.b.RemoveFriendRequests([]byte(entityID), hash)
 }
 
+/**********
+ * Block-list
+ **********/
+
+/*
+BlockUser blocks the user: the join-requests, the identification and the friend-ops from the user are refused.
+*/
+func (api *PrivateAPI) BlockUser(userID string) (*BlockUser, error) {
+	return api.b.SetBlockUser([]byte(userID), BlockModeBlock)
+}
+
+/*
+MuteUser mutes the user: keeps syncing with the user, but the notifications are suppressed.
+*/
+func (api *PrivateAPI) MuteUser(userID string) (*BlockUser, error) {
+	return api.b.SetBlockUser([]byte(userID), BlockModeMute)
+}
+
+func (api *PrivateAPI) UnblockUser(userID string) (*BlockUser, error) {
+	return api.b.SetBlockUser([]byte(userID), BlockModeNone)
+}
+
+func (api *PrivateAPI) GetBlockList() ([]*BlockUser, error) {
+	return api.b.GetBlockList()
+}
+
 /**********
  * Op
  **********/
@@ -429,6 +458,38 @@ func (api *PrivateAPI) RefreshMyNodeSignKey() (*pkgservice.KeyInfo, error) {
 	return api.b.RefreshMyNodeSignKey()
 }
 
+/**********
+ * Keystore
+ **********/
+
+func (api *PrivateAPI) Unlock(passphrase string) (bool, error) {
+	return api.b.Unlock(passphrase)
+}
+
+func (api *PrivateAPI) Lock() (bool, error) {
+	return api.b.Lock()
+}
+
+func (api *PrivateAPI) IsLocked() (bool, error) {
+	return api.b.IsLocked()
+}
+
+func (api *PrivateAPI) ChangePassphrase(oldPassphrase string, newPassphrase string) (bool, error) {
+	return api.b.ChangePassphrase(oldPassphrase, newPassphrase)
+}
+
+/**********
+ * Mnemonic
+ **********/
+
+func (api *PrivateAPI) ShowMnemonic() (string, error) {
+	return api.b.ShowMnemonic()
+}
+
+func (api *PrivateAPI) RecoverMe(mnemonic string, nodeID string) (*pkgservice.BackendJoinRequest, error) {
+	return api.b.RecoverMe(mnemonic, []byte(nodeID))
+}
+
 /**********
  * Misc
  **********/
@@ -437,6 +498,26 @@ func (api *PublicAPI) GetMeList() ([]*BackendMyInfo, error) {
 	return api.b.GetMeList()
 }
 
+/**********
+ * Subscribe
+ **********/
+
+func (api *PrivateAPI) FriendRequests(ctx context.Context) (*rpc.Subscription, error) {
+	return api.b.SubscribeFriendRequests(ctx)
+}
+
+func (api *PrivateAPI) Profiles(ctx context.Context, userID string) (*rpc.Subscription, error) {
+	return api.b.SubscribeProfiles(ctx, []byte(userID))
+}
+
+func (api *PrivateAPI) EntityStatus(ctx context.Context, entityID string) (*rpc.Subscription, error) {
+	return api.b.SubscribeEntityStatus(ctx, []byte(entityID))
+}
+
+func (api *PrivateAPI) MerkleSync(ctx context.Context, entityID string) (*rpc.Subscription, error) {
+	return api.b.SubscribeMerkleSync(ctx, []byte(entityID))
+}
+
 /**********
  * public
  **********/
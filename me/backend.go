@@ -33,10 +33,12 @@ type Backend struct {
 	friendBackend  *friend.Backend
 
 	myRouter pkgservice.MyRouter
+
+	db *meDB
 }
 
 func NewBackend(ctx *pkgservice.RouterContext, cfg *Config, router pkgservice.MyRouter, accountBackend *account.Backend, friendBacked *friend.Backend) (*Backend, error) {
-	err := InitMe(cfg.DataDir)
+	db, err := newMeDB(cfg.DataDir, cfg.DBEngine)
 	if err != nil {
 		return nil, err
 	}
@@ -47,9 +49,11 @@ func NewBackend(ctx *pkgservice.RouterContext, cfg *Config, router pkgservice.My
 
 		accountBackend: accountBackend,
 		friendBackend:  friendBacked,
+
+		db: db,
 	}
 
-	spm, err := NewServiceProtocolManager(cfg.ID, router, backend)
+	spm, err := NewServiceProtocolManager(cfg.ID, router, backend, db)
 	if err != nil {
 		return nil, err
 	}
@@ -81,11 +85,11 @@ func (b *Backend) Start() error {
 func (b *Backend) Stop() error {
 	b.SPM().(*ServiceProtocolManager).Stop()
 
-	log.Debug("Stop: to TeardownMe")
+	log.Debug("Stop: to close db")
 
-	TeardownMe()
+	b.db.Close()
 
-	log.Debug("Stop: after TeardownMe")
+	log.Debug("Stop: after close db")
 
 	return nil
 }
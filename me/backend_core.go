@@ -17,15 +17,18 @@
 package me
 
 import (
+	"context"
 	"reflect"
 
 	"github.com/ailabstw/go-pttai-core/account"
 	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/key"
 	"github.com/ailabstw/go-pttai-core/log"
 	"github.com/ailabstw/go-pttai-core/p2p/discover"
 	"github.com/ailabstw/go-pttai-core/pttdb"
 	pkgservice "github.com/ailabstw/go-pttai-core/service"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 func (b *Backend) SetMyName(name []byte) (*account.UserName, error) {
@@ -63,6 +66,10 @@ func (b *Backend) SetMyImage(imgStr string) (*account.UserImg, error) {
  **********/
 
 func (b *Backend) ShowMyMasterKey() ([]byte, error) {
+	if b.Config.IsLocked() {
+		return nil, key.ErrKeyLocked
+	}
+
 	myInfo := b.SPM().(*ServiceProtocolManager).MyInfo
 
 	masterKey := myInfo.GetMasterKey()
@@ -83,6 +90,10 @@ func (b *Backend) ValidateMyMasterKey(keyBytes []byte) (bool, error) {
 }
 
 func (b *Backend) ShowMyNodeKey() ([]byte, error) {
+	if b.Config.IsLocked() {
+		return nil, key.ErrKeyLocked
+	}
+
 	myInfo := b.SPM().(*ServiceProtocolManager).MyInfo
 
 	key := myInfo.GetNodeKey()
@@ -147,6 +158,71 @@ func (b *Backend) RefreshMyNodeSignKey() (*pkgservice.KeyInfo, error) {
 	return key, nil
 }
 
+/**********
+ * Keystore
+ **********/
+
+func (b *Backend) Unlock(passphrase string) (bool, error) {
+	err := b.Config.Unlock(passphrase)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (b *Backend) Lock() (bool, error) {
+	err := b.Config.Lock()
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (b *Backend) IsLocked() (bool, error) {
+	return b.Config.IsLocked(), nil
+}
+
+func (b *Backend) ChangePassphrase(oldPassphrase string, newPassphrase string) (bool, error) {
+	err := b.Config.ChangePassphrase(oldPassphrase, newPassphrase)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+/**********
+ * Mnemonic
+ **********/
+
+func (b *Backend) ShowMnemonic() (string, error) {
+	return b.Config.Mnemonic()
+}
+
+func (b *Backend) RecoverMe(mnemonic string, nodeIDBytes []byte) (*pkgservice.BackendJoinRequest, error) {
+	nodeID := &discover.NodeID{}
+	err := nodeID.UnmarshalText(nodeIDBytes)
+	if err != nil {
+		return nil, ErrInvalidNode
+	}
+
+	myNodeID := b.myRouter.MyNodeID()
+	if reflect.DeepEqual(myNodeID, nodeID) {
+		return nil, ErrInvalidNode
+	}
+
+	myInfo := b.SPM().(*ServiceProtocolManager).MyInfo
+	pm := myInfo.PM().(*ProtocolManager)
+	joinRequest, err := pm.RecoverMe(mnemonic, nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pkgservice.JoinRequestToBackendJoinRequest(joinRequest), nil
+}
+
 /**********
  * Join Me
  **********/
@@ -319,6 +395,29 @@ func (b *Backend) RemoveFriendRequests(entityIDBytes []byte, hash []byte) (bool,
 	return pm.RemoveFriendRequests(hash)
 }
 
+/**********
+ * Block-list
+ **********/
+
+func (b *Backend) SetBlockUser(userIDBytes []byte, mode BlockMode) (*BlockUser, error) {
+	userID, err := types.UnmarshalTextPttID(userIDBytes, false)
+	if err != nil {
+		return nil, err
+	}
+
+	myInfo := b.SPM().(*ServiceProtocolManager).MyInfo
+	pm := myInfo.PM().(*ProtocolManager)
+
+	return pm.SetBlockUser(userID, mode)
+}
+
+func (b *Backend) GetBlockList() ([]*BlockUser, error) {
+	myInfo := b.SPM().(*ServiceProtocolManager).MyInfo
+	pm := myInfo.PM().(*ProtocolManager)
+
+	return pm.GetBlockUsers(), nil
+}
+
 /**********
  * MyInfo
  **********/
@@ -597,3 +696,28 @@ func (b *Backend) GetMyProfile(entityIDBytes []byte) (*account.Profile, error) {
 	return entity.(*MyInfo).Profile, nil
 
 }
+
+/**********
+ * Subscribe
+ **********/
+
+/*
+SubscribeFriendRequests subscribes the friend-requests made / accepted.
+*/
+func (b *Backend) SubscribeFriendRequests(ctx context.Context) (*rpc.Subscription, error) {
+	return pkgservice.SubscribeEvent(ctx, b.SPM().EventMux(), &FriendRequestEvent{}, nil)
+}
+
+/*
+SubscribeProfiles subscribes the changes of the user-name / user-img / name-card of the user (all the users if userID is empty).
+*/
+func (b *Backend) SubscribeProfiles(ctx context.Context, userIDBytes []byte) (*rpc.Subscription, error) {
+	userID, err := types.UnmarshalTextPttID(userIDBytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return pkgservice.SubscribeEvent(ctx, b.accountBackend.SPM().EventMux(), &account.ProfileEvent{}, func(ev interface{}) bool {
+		return userID == nil || reflect.DeepEqual(ev.(*account.ProfileEvent).UserID, userID)
+	})
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package me
+
+import (
+	"encoding/json"
+
+	"github.com/ailabstw/go-pttai-core/common"
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+)
+
+/*
+BlockMode is the mode of the user in the block-list.
+*/
+type BlockMode uint8
+
+const (
+	BlockModeNone BlockMode = iota
+
+	// BlockModeMute keeps syncing with the user, but the notifications are suppressed.
+	BlockModeMute
+
+	// BlockModeBlock refuses the join-requests, the identification and the ops from the user.
+	BlockModeBlock
+
+	NBlockMode
+)
+
+var blockModeStr = map[BlockMode]string{
+	BlockModeNone:  "none",
+	BlockModeMute:  "mute",
+	BlockModeBlock: "block",
+}
+
+func (m BlockMode) String() string {
+	return blockModeStr[m]
+}
+
+/*
+BlockUser is the user in the block-list of me, synced across my devices with the me-oplogs.
+*/
+type BlockUser struct {
+	V        types.Version
+	ID       *types.PttID    `json:"ID"`
+	UserID   *types.PttID    `json:"UID"`
+	Mode     BlockMode       `json:"M"`
+	UpdateTS types.Timestamp `json:"UT"`
+	LogID    *types.PttID    `json:"l,omitempty"`
+}
+
+func NewBlockUser(myID *types.PttID, userID *types.PttID, mode BlockMode, ts types.Timestamp, logID *types.PttID) *BlockUser {
+	return &BlockUser{
+		V:        types.CurrentVersion,
+		ID:       myID,
+		UserID:   userID,
+		Mode:     mode,
+		UpdateTS: ts,
+		LogID:    logID,
+	}
+}
+
+func (b *BlockUser) Save(db pttdb.Storage) error {
+	key, err := b.MarshalKey()
+	if err != nil {
+		return err
+	}
+
+	marshaled, err := b.Marshal()
+	if err != nil {
+		return err
+	}
+
+	return db.Put(key, marshaled)
+}
+
+func (b *BlockUser) DBPrefix() ([]byte, error) {
+	return common.Concat([][]byte{DBBlockUserPrefix, b.ID[:]})
+}
+
+func (b *BlockUser) MarshalKey() ([]byte, error) {
+	return common.Concat([][]byte{DBBlockUserPrefix, b.ID[:], b.UserID[:]})
+}
+
+func (b *BlockUser) Marshal() ([]byte, error) {
+	return json.Marshal(b)
+}
+
+func (b *BlockUser) Unmarshal(theBytes []byte) error {
+	return json.Unmarshal(theBytes, b)
+}
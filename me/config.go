@@ -18,15 +18,20 @@ package me
 
 import (
 	"crypto/ecdsa"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/ailabstw/go-pttai-core/common/types"
 	"github.com/ailabstw/go-pttai-core/key"
+	"github.com/ailabstw/go-pttai-core/key/bip39"
 	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/pttdb"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 )
@@ -34,9 +39,19 @@ import (
 type Config struct {
 	DataDir string
 
+	// DBEngine is the storage-engine of the dbs, pttdb.DefaultEngine if empty.
+	DBEngine pttdb.Engine
+
 	PrivateKey *ecdsa.PrivateKey `toml:"-"`
 	ID         *types.PttID      `toml:"-"` // we also need ID because other services need to know ID, but cannot directly acccess private-key and postfix.
 	Postfix    string
+
+	// Passphrase is to encrypt / decrypt the key-files as the keystore.
+	// The key-files are in plaintext if passphrase is not set.
+	Passphrase string `toml:"-"`
+
+	lockPassphrase sync.RWMutex
+	isKeystore     bool
 }
 
 func (c *Config) SetMyKey(hex string, file string, postfix string, isSave bool) error {
@@ -50,7 +65,7 @@ func (c *Config) SetMyKey(hex string, file string, postfix string, isSave bool)
 	case file != "" && hex != "":
 		return ErrInvalidPrivateKeyFileHex
 	case file != "":
-		if key, err = crypto.LoadECDSA(file); err != nil {
+		if key, _, err = c.loadKeyFile(file); err != nil {
 			return ErrInvalidPrivateKeyFile
 		}
 		c.PrivateKey = key
@@ -143,7 +158,11 @@ func (c *Config) myKey() (*ecdsa.PrivateKey, string, *types.PttID, error) {
 
 	// retrieve key / id from file
 	keyfile := c.ResolvePath(DataDirPrivateKey)
-	privKey, err := crypto.LoadECDSA(keyfile)
+	privKey, isKeystore, err := c.loadKeyFile(keyfile)
+	if isKeystore && err != nil {
+		// do not overwrite the keystore unable to be decrypted.
+		return nil, "", nil, err
+	}
 	postfixBytes, err2 := ioutil.ReadFile(keyfile + ".postfix")
 	if err == nil && err2 == nil {
 		id, err := types.NewPttIDFromKeyPostfix(privKey, postfixBytes)
@@ -151,6 +170,24 @@ func (c *Config) myKey() (*ecdsa.PrivateKey, string, *types.PttID, error) {
 			return nil, "", nil, ErrInvalidMe
 		}
 
+		c.lockPassphrase.Lock()
+		c.isKeystore = isKeystore
+		isMigrate := !isKeystore && c.Passphrase != ""
+		c.lockPassphrase.Unlock()
+
+		// migrate the plaintext key-files to the keystore.
+		if isMigrate {
+			err = c.saveKeyFile(DataDirPrivateKey, privKey, string(postfixBytes), id)
+			if err != nil {
+				return nil, "", nil, err
+			}
+
+			err = c.migrateKeyFiles()
+			if err != nil {
+				return nil, "", nil, err
+			}
+		}
+
 		return privKey, string(postfixBytes), id, nil
 	}
 
@@ -178,15 +215,107 @@ func (c *Config) myKey() (*ecdsa.PrivateKey, string, *types.PttID, error) {
 		return nil, "", nil, err
 	}
 
+	c.lockPassphrase.Lock()
+	c.isKeystore = c.Passphrase != ""
+	c.lockPassphrase.Unlock()
+
 	return privKey, postfix, id, err
 }
 
+/**********
+ * Mnemonic
+ **********/
+
+/*
+Mnemonic encodes my key and postfix as the mnemonic words, for the backup of my identity.
+The mnemonic is not shown when the keystore is locked.
+*/
+func (c *Config) Mnemonic() (string, error) {
+	if c.PrivateKey == nil {
+		return "", ErrInvalidMe
+	}
+
+	if c.IsLocked() {
+		return "", key.ErrKeyLocked
+	}
+
+	return keyToMnemonic(c.PrivateKey, []byte(c.Postfix))
+}
+
+/*
+RestoreMnemonic restores my key and postfix from the mnemonic words, with the same ID re-derived.
+The existing key-file is kept as deleted.
+*/
+func (c *Config) RestoreMnemonic(mnemonic string) error {
+	privKey, postfix, _, err := mnemonicToKey(mnemonic)
+	if err != nil {
+		return err
+	}
+
+	if c.DataDir != "" {
+		if err := os.MkdirAll(c.DataDir, 0700); err != nil {
+			return err
+		}
+
+		keyfile := c.ResolvePath(DataDirPrivateKey)
+		if _, err := os.Stat(keyfile); err == nil {
+			err = c.DeleteKey()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	c.PrivateKey = nil
+	keyHex := hex.EncodeToString(crypto.FromECDSA(privKey))
+
+	return c.SetMyKey(keyHex, "", string(postfix), true)
+}
+
+func keyToMnemonic(privKey *ecdsa.PrivateKey, postfix []byte) (string, error) {
+	if len(postfix) != common.AddressLength {
+		return "", ErrInvalidPrivateKeyPostfix
+	}
+
+	entropy := make([]byte, 0, SizeMnemonicEntropy)
+	entropy = append(entropy, crypto.FromECDSA(privKey)...)
+	entropy = append(entropy, postfix...)
+
+	return bip39.NewMnemonic(entropy)
+}
+
+func mnemonicToKey(mnemonic string) (*ecdsa.PrivateKey, []byte, *types.PttID, error) {
+	entropy, err := bip39.MnemonicToEntropy(mnemonic)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(entropy) != SizeMnemonicEntropy {
+		return nil, nil, nil, ErrInvalidMnemonic
+	}
+
+	privKey, err := crypto.ToECDSA(entropy[:SizePrivateKey])
+	if err != nil {
+		return nil, nil, nil, ErrInvalidMnemonic
+	}
+
+	postfix := entropy[SizePrivateKey:]
+
+	id, err := types.NewPttIDFromKeyPostfix(privKey, postfix)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return privKey, postfix, id, nil
+}
+
 func (c *Config) GetDataPrivateKeyByID(myID *types.PttID) (*ecdsa.PrivateKey, error) {
 	keyfile, err := c.ResolvePrivateKeyWithIDPath(myID)
 	if err != nil {
 		return nil, err
 	}
-	return crypto.LoadECDSA(keyfile)
+
+	privKey, _, err := c.loadKeyFile(keyfile)
+	return privKey, err
 }
 
 func (c *Config) ResolvePrivateKeyWithIDPath(myID *types.PttID) (string, error) {
@@ -214,8 +343,17 @@ func (c *Config) ResolvePath(path string) string {
 	return filepath.Join(c.DataDir, path)
 }
 
-func (c *Config) SaveKey(filename string, key *ecdsa.PrivateKey, postfix string) error {
-	err := crypto.SaveECDSA(filename, key)
+func (c *Config) SaveKey(filename string, privKey *ecdsa.PrivateKey, postfix string) error {
+	c.lockPassphrase.RLock()
+	passphrase, isKeystore := c.Passphrase, c.isKeystore
+	c.lockPassphrase.RUnlock()
+
+	// do not save the key in plaintext when the keystore is locked.
+	if isKeystore && passphrase == "" {
+		return key.ErrKeyLocked
+	}
+
+	err := key.SaveKeyFile(filename, privKey, passphrase)
 	if err != nil {
 		return err
 	}
@@ -230,17 +368,214 @@ func (c *Config) SaveKey(filename string, key *ecdsa.PrivateKey, postfix string)
 }
 
 func (c *Config) LoadKey(filename string) (*ecdsa.PrivateKey, *types.PttID, error) {
-	key, err := crypto.LoadECDSA(filename)
+	privKey, _, err := c.loadKeyFile(filename)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	id, err := types.NewPttIDFromKey(key)
+	id, err := types.NewPttIDFromKey(privKey)
 	if err != nil {
 		return nil, nil, ErrInvalidMe
 	}
 
-	return key, id, nil
+	return privKey, id, nil
+}
+
+func (c *Config) loadKeyFile(filename string) (*ecdsa.PrivateKey, bool, error) {
+	c.lockPassphrase.RLock()
+	defer c.lockPassphrase.RUnlock()
+
+	return key.LoadKeyFile(filename, c.Passphrase)
+}
+
+/**********
+ * Keystore
+ **********/
+
+/*
+IsLocked checks whether the key-files are the keystore and the passphrase is not set.
+*/
+func (c *Config) IsLocked() bool {
+	c.lockPassphrase.RLock()
+	defer c.lockPassphrase.RUnlock()
+
+	return c.isKeystore && c.Passphrase == ""
+}
+
+/*
+Unlock verifies the passphrase with the keystore, and keeps the passphrase to access the key-files.
+*/
+func (c *Config) Unlock(passphrase string) error {
+	keyfile := c.ResolvePath(DataDirPrivateKey)
+
+	_, isKeystore, err := key.LoadKeyFile(keyfile, passphrase)
+	if err != nil {
+		return err
+	}
+	if !isKeystore {
+		return ErrNotKeystore
+	}
+
+	c.lockPassphrase.Lock()
+	defer c.lockPassphrase.Unlock()
+
+	c.Passphrase = passphrase
+	c.isKeystore = true
+
+	return nil
+}
+
+/*
+Lock forgets the passphrase. The running node still needs the keys in memory,
+so locking does not wipe them. Instead, the key-files are unable to be saved,
+and the mnemonic and the keys are unable to be exported until unlocked.
+*/
+func (c *Config) Lock() error {
+	c.lockPassphrase.Lock()
+	defer c.lockPassphrase.Unlock()
+
+	if !c.isKeystore {
+		return ErrNotKeystore
+	}
+
+	c.Passphrase = ""
+
+	return nil
+}
+
+/*
+ChangePassphrase re-encrypts all the key-files with the new passphrase.
+
+The plaintext key-files are migrated to the keystore if oldPassphrase is empty,
+and the keystore is decrypted to the plaintext key-files if newPassphrase is empty.
+*/
+func (c *Config) ChangePassphrase(oldPassphrase string, newPassphrase string) error {
+	if oldPassphrase == newPassphrase {
+		return key.ErrInvalidPassphrase
+	}
+
+	c.lockPassphrase.Lock()
+	defer c.lockPassphrase.Unlock()
+
+	filenames, err := c.keyFilenames()
+	if err != nil {
+		return err
+	}
+
+	// 1. decrypt all the key-files with the old passphrase.
+	privKeys := make([]*ecdsa.PrivateKey, len(filenames))
+	for i, filename := range filenames {
+		privKeys[i], _, err = key.LoadKeyFile(filename, oldPassphrase)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 2. save with the new passphrase to the tmp-files.
+	tmpFilenames := make([]string, 0, len(filenames))
+	defer func() {
+		for _, tmpFilename := range tmpFilenames {
+			os.Remove(tmpFilename)
+		}
+	}()
+
+	for i, filename := range filenames {
+		tmpFilename := filename + ".tmp"
+		tmpFilenames = append(tmpFilenames, tmpFilename)
+		err = key.SaveKeyFile(tmpFilename, privKeys[i], newPassphrase)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 3. replace the key-files with the tmp-files.
+	for i, filename := range filenames {
+		err = renameKeyFile(tmpFilenames[i], filename)
+		if err != nil {
+			c.rollbackKeyFiles(filenames[:i], privKeys, oldPassphrase)
+			return err
+		}
+	}
+
+	c.Passphrase = newPassphrase
+	c.isKeystore = newPassphrase != ""
+
+	return nil
+}
+
+/*
+migrateKeyFiles encrypts the remaining plaintext key-files (ex: the deleted key-files) with the passphrase,
+so that the old keys are not left in plaintext after migrating to the keystore.
+*/
+func (c *Config) migrateKeyFiles() error {
+	c.lockPassphrase.RLock()
+	defer c.lockPassphrase.RUnlock()
+
+	filenames, err := c.keyFilenames()
+	if err != nil {
+		return err
+	}
+
+	for _, filename := range filenames {
+		privKey, isKeystore, err := key.LoadKeyFile(filename, "")
+		if isKeystore {
+			continue
+		}
+		if err != nil {
+			log.Warn("migrateKeyFiles: unable to load key-file", "filename", filename, "e", err)
+			continue
+		}
+
+		err = key.SaveKeyFile(filename, privKey, c.Passphrase)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+rollbackKeyFiles saves the already replaced key-files back with the old passphrase,
+so the key-files are not left with mixed passphrases if ChangePassphrase fails.
+*/
+func (c *Config) rollbackKeyFiles(filenames []string, privKeys []*ecdsa.PrivateKey, oldPassphrase string) {
+	for i, filename := range filenames {
+		err := key.SaveKeyFile(filename, privKeys[i], oldPassphrase)
+		if err != nil {
+			log.Error("ChangePassphrase: unable to rollback key-file", "filename", filename, "e", err)
+		}
+	}
+}
+
+/*
+keyFilenames lists DataDirPrivateKey, the key-files with ids and the deleted key-files.
+The deleted key-files are kept as the backup, and are encrypted / decrypted as the other key-files.
+*/
+func (c *Config) keyFilenames() ([]string, error) {
+	keyfile := c.ResolvePath(DataDirPrivateKey)
+	if keyfile == "" {
+		return nil, ErrInvalidMe
+	}
+
+	matches, err := filepath.Glob(keyfile + "*")
+	if err != nil {
+		return nil, err
+	}
+
+	filenames := make([]string, 0, len(matches))
+	for _, filename := range matches {
+		if filename != keyfile && !strings.HasPrefix(filename, keyfile+".") {
+			continue
+		}
+		if strings.HasSuffix(filename, ".postfix") || strings.HasSuffix(filename, ".tmp") {
+			continue
+		}
+
+		filenames = append(filenames, filename)
+	}
+
+	return filenames, nil
 }
 
 func (c *Config) DeleteKey() error {
@@ -0,0 +1,277 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package me
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ailabstw/go-pttai-core/key"
+)
+
+func TestConfig_ChangePassphrase(t *testing.T) {
+	setupTest(t)
+	defer teardownTest(t)
+
+	key.ScryptN = 1 << 4
+	defer func() { key.ScryptN = 1 << 18 }()
+
+	dir, _ := ioutil.TempDir("", "me")
+	defer os.RemoveAll(dir)
+
+	// plaintext
+	c := &Config{DataDir: dir}
+	err := c.SetMyKey("", "", "", false)
+	if err != nil {
+		t.Errorf("SetMyKey: e: %v", err)
+	}
+	myID := c.ID
+
+	// migrate to keystore
+	err = c.ChangePassphrase("", "test-passphrase")
+	if err != nil {
+		t.Errorf("ChangePassphrase: e: %v", err)
+	}
+
+	filenames, _ := c.keyFilenames()
+	if len(filenames) != 2 {
+		t.Errorf("keyFilenames: %v", filenames)
+	}
+	for _, filename := range filenames {
+		isKeystore, _ := key.IsKeystoreFile(filename)
+		if !isKeystore {
+			t.Errorf("ChangePassphrase: %v is not keystore", filename)
+		}
+	}
+	tmpFilenames, _ := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	if len(tmpFilenames) != 0 {
+		t.Errorf("ChangePassphrase: tmp-files: %v", tmpFilenames)
+	}
+
+	// locked
+	c2 := &Config{DataDir: dir}
+	err = c2.SetMyKey("", "", "", false)
+	if err != key.ErrKeyLocked {
+		t.Errorf("SetMyKey: e: %v want: %v", err, key.ErrKeyLocked)
+	}
+
+	// unlock
+	err = c.Lock()
+	if err != nil || !c.IsLocked() {
+		t.Errorf("Lock: e: %v locked: %v", err, c.IsLocked())
+	}
+	_, err = c.Mnemonic()
+	if err != key.ErrKeyLocked {
+		t.Errorf("Mnemonic: e: %v want: %v", err, key.ErrKeyLocked)
+	}
+	err = c.Unlock("test-passphrase2")
+	if err != key.ErrInvalidPassphrase {
+		t.Errorf("Unlock: e: %v want: %v", err, key.ErrInvalidPassphrase)
+	}
+	err = c.Unlock("test-passphrase")
+	if err != nil || c.IsLocked() {
+		t.Errorf("Unlock: e: %v locked: %v", err, c.IsLocked())
+	}
+	_, err = c.Mnemonic()
+	if err != nil {
+		t.Errorf("Mnemonic: e: %v", err)
+	}
+
+	// with passphrase
+	c3 := &Config{DataDir: dir, Passphrase: "test-passphrase"}
+	err = c3.SetMyKey("", "", "", false)
+	if err != nil {
+		t.Errorf("SetMyKey: e: %v", err)
+	}
+	if !reflect.DeepEqual(c3.ID, myID) {
+		t.Errorf("SetMyKey: id: %v want: %v", c3.ID, myID)
+	}
+
+	// invalid old passphrase
+	err = c.ChangePassphrase("test-passphrase2", "test-passphrase3")
+	if err != key.ErrInvalidPassphrase {
+		t.Errorf("ChangePassphrase: e: %v want: %v", err, key.ErrInvalidPassphrase)
+	}
+}
+
+func TestConfig_ChangePassphraseRollback(t *testing.T) {
+	setupTest(t)
+	defer teardownTest(t)
+
+	key.ScryptN = 1 << 4
+	defer func() { key.ScryptN = 1 << 18 }()
+
+	dir, _ := ioutil.TempDir("", "me")
+	defer os.RemoveAll(dir)
+
+	c := &Config{DataDir: dir}
+	err := c.SetMyKey("", "", "", false)
+	if err != nil {
+		t.Errorf("SetMyKey: e: %v", err)
+	}
+
+	// failed in replacing the 2nd key-file.
+	errRename := errors.New("rename")
+	nRename := 0
+	renameKeyFile = func(oldpath string, newpath string) error {
+		nRename++
+		if nRename == 2 {
+			return errRename
+		}
+		return os.Rename(oldpath, newpath)
+	}
+	defer func() { renameKeyFile = os.Rename }()
+
+	err = c.ChangePassphrase("", "test-passphrase")
+	if err != errRename {
+		t.Errorf("ChangePassphrase: e: %v want: %v", err, errRename)
+	}
+	if c.Passphrase != "" || c.IsLocked() {
+		t.Errorf("ChangePassphrase: passphrase changed")
+	}
+
+	// the replaced key-file is rolled back.
+	filenames, _ := c.keyFilenames()
+	if len(filenames) != 2 {
+		t.Errorf("keyFilenames: %v", filenames)
+	}
+	for _, filename := range filenames {
+		isKeystore, _ := key.IsKeystoreFile(filename)
+		if isKeystore {
+			t.Errorf("ChangePassphrase: %v is not rolled back", filename)
+		}
+		_, _, err = key.LoadKeyFile(filename, "")
+		if err != nil {
+			t.Errorf("LoadKeyFile: %v e: %v", filename, err)
+		}
+	}
+	tmpFilenames, _ := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	if len(tmpFilenames) != 0 {
+		t.Errorf("ChangePassphrase: tmp-files: %v", tmpFilenames)
+	}
+}
+
+func TestConfig_DeletedKeyFiles(t *testing.T) {
+	setupTest(t)
+	defer teardownTest(t)
+
+	key.ScryptN = 1 << 4
+	defer func() { key.ScryptN = 1 << 18 }()
+
+	dir, _ := ioutil.TempDir("", "me")
+	defer os.RemoveAll(dir)
+
+	// plaintext with the deleted key-file
+	c := &Config{DataDir: dir}
+	err := c.SetMyKey("", "", "", false)
+	if err != nil {
+		t.Errorf("SetMyKey: e: %v", err)
+	}
+
+	deletedKey, _ := key.GenerateKey()
+	deletedFilename := c.ResolvePath(DataDirPrivateKey) + ".2019-01-01_00-00-00.000.deleted"
+	key.SaveKeyFile(deletedFilename, deletedKey, "")
+
+	// migrated with the passphrase
+	c2 := &Config{DataDir: dir, Passphrase: "test-passphrase"}
+	err = c2.SetMyKey("", "", "", false)
+	if err != nil {
+		t.Errorf("SetMyKey: e: %v", err)
+	}
+
+	filenames, _ := c2.keyFilenames()
+	if len(filenames) != 3 {
+		t.Errorf("keyFilenames: %v", filenames)
+	}
+	for _, filename := range filenames {
+		isKeystore, _ := key.IsKeystoreFile(filename)
+		if !isKeystore {
+			t.Errorf("SetMyKey: %v is not migrated", filename)
+		}
+	}
+
+	// re-encrypted with the new passphrase
+	err = c2.ChangePassphrase("test-passphrase", "test-passphrase2")
+	if err != nil {
+		t.Errorf("ChangePassphrase: e: %v", err)
+	}
+
+	loaded, _, err := key.LoadKeyFile(deletedFilename, "test-passphrase2")
+	if err != nil || !reflect.DeepEqual(loaded.D, deletedKey.D) {
+		t.Errorf("LoadKeyFile: %v e: %v", deletedFilename, err)
+	}
+}
+
+func TestConfig_RestoreMnemonic(t *testing.T) {
+	setupTest(t)
+	defer teardownTest(t)
+
+	dir, _ := ioutil.TempDir("", "me")
+	defer os.RemoveAll(dir)
+
+	c := &Config{DataDir: filepath.Join(dir, "orig")}
+	err := c.SetMyKey("", "", "", false)
+	if err != nil {
+		t.Errorf("SetMyKey: e: %v", err)
+	}
+
+	mnemonic, err := c.Mnemonic()
+	if err != nil {
+		t.Errorf("Mnemonic: e: %v", err)
+	}
+	if n := len(strings.Fields(mnemonic)); n != 39 {
+		t.Errorf("Mnemonic: words: %v want: 39", n)
+	}
+
+	// restore to another data-dir
+	c2 := &Config{DataDir: filepath.Join(dir, "restored")}
+	err = c2.RestoreMnemonic(mnemonic)
+	if err != nil {
+		t.Errorf("RestoreMnemonic: e: %v", err)
+	}
+	if !reflect.DeepEqual(c2.ID, c.ID) {
+		t.Errorf("RestoreMnemonic: id: %v want: %v", c2.ID, c.ID)
+	}
+
+	// load from the restored data-dir
+	c3 := &Config{DataDir: filepath.Join(dir, "restored")}
+	err = c3.SetMyKey("", "", "", false)
+	if err != nil {
+		t.Errorf("SetMyKey: e: %v", err)
+	}
+	if !reflect.DeepEqual(c3.ID, c.ID) {
+		t.Errorf("SetMyKey: id: %v want: %v", c3.ID, c.ID)
+	}
+
+	// the same recover-key
+	recoverKey, _ := deriveRecoverKey(c.PrivateKey)
+	recoverKey3, _ := deriveRecoverKey(c3.PrivateKey)
+	if !reflect.DeepEqual(recoverKey.D, recoverKey3.D) {
+		t.Errorf("deriveRecoverKey: key not match")
+	}
+
+	// invalid
+	err = c2.RestoreMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != ErrInvalidMnemonic {
+		t.Errorf("RestoreMnemonic: e: %v want: %v", err, ErrInvalidMnemonic)
+	}
+}
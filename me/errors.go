@@ -29,6 +29,10 @@ var (
 	ErrInvalidPrivateKeyFile    = errors.New("invalid private key file")
 	ErrInvalidPrivateKeyHex     = errors.New("invalid private key hex")
 
+	ErrNotKeystore = errors.New("key is not encrypted")
+
+	ErrInvalidMnemonic = errors.New("invalid mnemonic")
+
 	ErrAlreadyMyNode = errors.New("already my node")
 
 	ErrInvalidEntry     = errors.New("invalid raft entry")
@@ -37,4 +41,6 @@ var (
 	ErrUnableToBeLead = errors.New("unable to be lead")
 
 	ErrWithLead = errors.New("with lead")
+
+	ErrInvalidBlockUser = errors.New("invalid block user")
 )
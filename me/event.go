@@ -0,0 +1,55 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package me
+
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+/*
+FriendRequestEvent is posted when the friend-request is made / accepted.
+IsIncoming is true if the request is from the other user (joining with my join-key).
+*/
+type FriendRequestEvent struct {
+	UserID     *types.PttID          `json:"ID"`
+	Hash       []byte                `json:"H,omitempty"`
+	Status     pkgservice.JoinStatus `json:"S"`
+	IsIncoming bool                  `json:"I"`
+}
+
+/*
+postFriendRequestEvent posts the FriendRequestEvent. The incoming requests from the muted users are suppressed.
+*/
+func (pm *ProtocolManager) postFriendRequestEvent(userID *types.PttID, hash *common.Address, status pkgservice.JoinStatus, isIncoming bool) {
+	if isIncoming && pm.IsMutedUser(userID) {
+		return
+	}
+
+	var hashBytes []byte
+	if hash != nil {
+		hashBytes = hash[:]
+	}
+
+	pm.PostEvent(&FriendRequestEvent{
+		UserID:     userID,
+		Hash:       hashBytes,
+		Status:     status,
+		IsIncoming: isIncoming,
+	})
+}
@@ -17,12 +17,14 @@
 package me
 
 import (
+	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/ailabstw/go-pttai-core/node"
 	"github.com/ailabstw/go-pttai-core/pttdb"
 	pkgservice "github.com/ailabstw/go-pttai-core/service"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 // default config
@@ -36,9 +38,24 @@ var (
 var (
 	DataDirPrivateKey = "mykey"
 
+	// replacing the key-files in ChangePassphrase (replaced in testing)
+	renameKeyFile = os.Rename
+
+	// mnemonic as my-key + postfix
+	SizeMnemonicEntropy = SizePrivateKey + common.AddressLength
+
 	DefaultTitle = []byte("")
 )
 
+const (
+	SizePrivateKey = 32
+)
+
+// recover-me
+var (
+	RecoverKeySalt = []byte("pttai-recover-me")
+)
+
 // protocol
 const (
 	_ pkgservice.OpType = iota + pkgservice.NMsg
@@ -91,21 +108,13 @@ const (
 var (
 	SleepTimeLock = 10
 
-	DBMePrefix                    = []byte(".medb")
-	dbMeCore   *pttdb.LDBDatabase = nil
-	dbMe       *pttdb.LDBBatch    = nil
+	DBMePrefix = []byte(".medb")
 
 	DBMyNodePrefix = []byte(".mndb")
 
-	DBRaftPrefix                    = []byte(".rfdb")
-	dbRaft       *pttdb.LDBDatabase = nil
-
-	dbMyNodes *pttdb.LDBDatabase = nil
+	DBBlockUserPrefix = []byte(".bkus")
 
-	dbMeta *pttdb.LDBDatabase = nil
-
-	dbKeyCore *pttdb.LDBDatabase = nil
-	dbKey     *pttdb.LDBBatch    = nil
+	DBRaftPrefix = []byte(".rfdb")
 
 	DBKeyRaftHardState = []byte(".rfhs")
 	DBKeyRaftSnapshot  = []byte(".rfsn")
@@ -204,79 +213,97 @@ const (
 	InitMeInfoTickTime = 3 * time.Second
 )
 
-func InitMe(dataDir string) error {
+/*
+meDB is the dbs of the me-service of a node.
+*/
+type meDB struct {
+	meCore pttdb.Storage
+	me     pttdb.IndexBatch
+
+	myNodes pttdb.Storage
+
+	raft pttdb.Storage
+
+	meta pttdb.Storage
+
+	keyCore pttdb.Storage
+	key     pttdb.IndexBatch
+}
+
+func newMeDB(dataDir string, engine pttdb.Engine) (*meDB, error) {
 	var err error
 
-	// db
-	dbMeCore, err = pttdb.NewLDBDatabase("me", dataDir, 0, 0)
+	db := &meDB{}
+
+	db.meCore, err = pttdb.NewStorageWithEngine(engine, "me", dataDir)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	dbMe, err = pttdb.NewLDBBatch(dbMeCore)
+	db.me, err = pttdb.NewStorageBatch(db.meCore)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	dbMyNodes, err = pttdb.NewLDBDatabase("mynodes", dataDir, 0, 0)
+	db.myNodes, err = pttdb.NewStorageWithEngine(engine, "mynodes", dataDir)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	dbRaft, err = pttdb.NewLDBDatabase("raft", dataDir, 0, 0)
+	db.raft, err = pttdb.NewStorageWithEngine(engine, "raft", dataDir)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	dbMeta, err = pttdb.NewLDBDatabase("memeta", dataDir, 0, 0)
+	db.meta, err = pttdb.NewStorageWithEngine(engine, "memeta", dataDir)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	dbKeyCore, err = pttdb.NewLDBDatabase("signkey", dataDir, 0, 0)
+	db.keyCore, err = pttdb.NewStorageWithEngine(engine, "signkey", dataDir)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	dbKey, err = pttdb.NewLDBBatch(dbKeyCore)
+	db.key, err = pttdb.NewStorageBatch(db.keyCore)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return db, nil
 }
 
-func TeardownMe() {
-	if dbMeCore != nil {
-		dbMeCore.Close()
-		dbMeCore = nil
+func (db *meDB) Close() {
+	if db.meCore != nil {
+		db.meCore.Close()
+		db.meCore = nil
 	}
 
-	if dbMe != nil {
-		dbMe = nil
+	if db.me != nil {
+		db.me = nil
 	}
 
-	if dbMyNodes != nil {
-		dbMyNodes.Close()
-		dbMyNodes = nil
+	if db.myNodes != nil {
+		db.myNodes.Close()
+		db.myNodes = nil
 	}
 
-	if dbRaft != nil {
-		dbRaft.Close()
-		dbRaft = nil
+	if db.raft != nil {
+		db.raft.Close()
+		db.raft = nil
 	}
 
-	if dbMeta != nil {
-		dbMeta.Close()
-		dbMeta = nil
+	if db.meta != nil {
+		db.meta.Close()
+		db.meta = nil
 	}
 
-	if dbKeyCore != nil {
-		dbKeyCore.Close()
-		dbKeyCore = nil
+	if db.keyCore != nil {
+		db.keyCore.Close()
+		db.keyCore = nil
 	}
 
-	if dbKey != nil {
-		dbKey = nil
+	if db.key != nil {
+		db.key = nil
 	}
 }
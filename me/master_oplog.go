@@ -18,6 +18,7 @@ package me
 
 import (
 	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/pttdb"
 	pkgservice "github.com/ailabstw/go-pttai-core/service"
 )
 
@@ -28,9 +29,9 @@ type MasterOplog struct {
 	*pkgservice.BaseOplog `json:"O"`
 }
 
-func NewMasterOplog(id *types.PttID, ts types.Timestamp, doerID *types.PttID, op pkgservice.OpType, data interface{}, dbLock *types.LockMap) (*MasterOplog, error) {
+func NewMasterOplog(id *types.PttID, ts types.Timestamp, doerID *types.PttID, op pkgservice.OpType, data interface{}, db pttdb.IndexBatch, dbLock *types.LockMap) (*MasterOplog, error) {
 
-	oplog, err := pkgservice.NewOplog(id, ts, doerID, op, data, dbMe, id, DBMasterOplogPrefix, DBMasterIdxOplogPrefix, nil, dbLock)
+	oplog, err := pkgservice.NewOplog(id, ts, doerID, op, data, db, id, DBMasterOplogPrefix, DBMasterIdxOplogPrefix, nil, dbLock)
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +43,7 @@ func NewMasterOplog(id *types.PttID, ts types.Timestamp, doerID *types.PttID, op
 
 func (pm *ProtocolManager) SetMasterDB(oplog *pkgservice.BaseOplog) {
 	myID := pm.Entity().GetID()
-	oplog.SetDB(dbMe, myID, DBMasterOplogPrefix, DBMasterIdxOplogPrefix, nil, pm.dbMasterLock)
+	oplog.SetDB(pm.db.me, myID, DBMasterOplogPrefix, DBMasterIdxOplogPrefix, nil, pm.dbMasterLock)
 }
 
 func OplogsToMasterOplogs(logs []*pkgservice.BaseOplog) []*MasterOplog {
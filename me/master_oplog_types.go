@@ -40,3 +40,25 @@ type MasterOpRevokeMaster struct {
 	From    *types.PttID               `json:"f"`
 	Masters map[discover.NodeID]uint32 `json:"M"`
 }
+
+func (o *MasterOpAddMaster) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.NodeID(o.ID)
+	enc.PttID(o.From)
+	enc.NodeIDWeights(o.Masters)
+	enc.Uint64(uint64(o.Weight))
+}
+
+func (o *MasterOpRevokeMaster) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.NodeID(o.ID)
+	enc.PttID(o.From)
+	enc.NodeIDWeights(o.Masters)
+}
+
+func init() {
+	pkgservice.RegisterOpData(DBMasterOplogPrefix, map[pkgservice.OpType]func() pkgservice.CanonicalOpData{
+		MasterOpTypeAddMaster:    func() pkgservice.CanonicalOpData { return &MasterOpAddMaster{} },
+		MasterOpTypeRevokeMaster: func() pkgservice.CanonicalOpData { return &MasterOpRevokeMaster{} },
+	})
+}
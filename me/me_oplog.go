@@ -18,6 +18,7 @@ package me
 
 import (
 	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/pttdb"
 	pkgservice "github.com/ailabstw/go-pttai-core/service"
 )
 
@@ -29,9 +30,9 @@ func (o *MeOplog) GetBaseOplog() *pkgservice.BaseOplog {
 	return o.BaseOplog
 }
 
-func NewMeOplog(objID *types.PttID, ts types.Timestamp, doerID *types.PttID, op pkgservice.OpType, opData pkgservice.OpData, myID *types.PttID, dbLock *types.LockMap) (*MeOplog, error) {
+func NewMeOplog(objID *types.PttID, ts types.Timestamp, doerID *types.PttID, op pkgservice.OpType, opData pkgservice.OpData, db pttdb.IndexBatch, myID *types.PttID, dbLock *types.LockMap) (*MeOplog, error) {
 
-	oplog, err := pkgservice.NewOplog(objID, ts, doerID, op, opData, dbMe, myID, DBMeOplogPrefix, DBMeIdxOplogPrefix, DBMeMerkleOplogPrefix, dbLock)
+	oplog, err := pkgservice.NewOplog(objID, ts, doerID, op, opData, db, myID, DBMeOplogPrefix, DBMeIdxOplogPrefix, DBMeMerkleOplogPrefix, dbLock)
 	if err != nil {
 		return nil, err
 	}
@@ -51,12 +52,12 @@ func (pm *ProtocolManager) NewMeOplog(objID *types.PttID, op pkgservice.OpType,
 		return nil, err
 	}
 
-	return NewMeOplog(objID, ts, myID, op, opData, entityID, pm.dbMeLock)
+	return NewMeOplog(objID, ts, myID, op, opData, pm.db.me, entityID, pm.dbMeLock)
 }
 
 func (pm *ProtocolManager) SetMeDB(oplog *pkgservice.BaseOplog) {
 	myID := pm.Entity().GetID()
-	oplog.SetDB(dbMe, myID, DBMeOplogPrefix, DBMeIdxOplogPrefix, DBMeMerkleOplogPrefix, pm.dbMeLock)
+	oplog.SetDB(pm.db.me, myID, DBMeOplogPrefix, DBMeIdxOplogPrefix, DBMeMerkleOplogPrefix, pm.dbMeLock)
 }
 
 func OplogsToMeOplogs(logs []*pkgservice.BaseOplog) []*MeOplog {
@@ -37,6 +37,8 @@ const (
 	MeOpTypeMigrateMe
 	MeOpTypeDeleteMe
 
+	MeOpTypeSetBlockUser
+
 	NMeOpType
 )
 
@@ -66,3 +68,53 @@ type MeOpMigrateMe struct {
 }
 
 type MeOpDeleteMe struct{}
+
+type MeOpSetBlockUser struct {
+	Mode BlockMode `json:"M"`
+}
+
+func (o *MeOpCreateMe) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.NodeID(o.NodeID)
+	enc.Int64(int64(o.NodeType))
+	enc.Bytes(o.NodeName)
+}
+
+func (o *MeOpSetNodeName) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.NodeID(o.NodeID)
+	enc.Bytes(o.Name)
+}
+
+func (o *MeOpEntity) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.PttID(o.LogID)
+}
+
+func (o *MeOpMigrateMe) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.PttID(o.ID)
+}
+
+func (o *MeOpDeleteMe) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+}
+
+func (o *MeOpSetBlockUser) EncodeCanonical(enc *pkgservice.CanonicalEncoder) {
+	enc.Version(1)
+	enc.Uint64(uint64(o.Mode))
+}
+
+func init() {
+	pkgservice.RegisterOpData(DBMeOplogPrefix, map[pkgservice.OpType]func() pkgservice.CanonicalOpData{
+		MeOpTypeCreateMe:     func() pkgservice.CanonicalOpData { return &MeOpCreateMe{} },
+		MeOpTypeSetNodeName:  func() pkgservice.CanonicalOpData { return &MeOpSetNodeName{} },
+		MeOpTypeCreateBoard:  func() pkgservice.CanonicalOpData { return &MeOpEntity{} },
+		MeOpTypeJoinBoard:    func() pkgservice.CanonicalOpData { return &MeOpEntity{} },
+		MeOpTypeCreateFriend: func() pkgservice.CanonicalOpData { return &MeOpEntity{} },
+		MeOpTypeJoinFriend:   func() pkgservice.CanonicalOpData { return &MeOpEntity{} },
+		MeOpTypeMigrateMe:    func() pkgservice.CanonicalOpData { return &MeOpMigrateMe{} },
+		MeOpTypeDeleteMe:     func() pkgservice.CanonicalOpData { return &MeOpDeleteMe{} },
+		MeOpTypeSetBlockUser: func() pkgservice.CanonicalOpData { return &MeOpSetBlockUser{} },
+	})
+}
@@ -0,0 +1,82 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package me
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeOpData_EncodeCanonical(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	id := &types.PttID{1, 2}
+	ts := types.Timestamp{Ts: 1, NanoTs: 2}
+	nodeID := &discover.NodeID{3}
+
+	// prepare test-cases
+	tests := []struct {
+		name string
+		op   pkgservice.OpType
+		data pkgservice.CanonicalOpData
+		want string
+	}{
+		{name: "CreateMe", op: MeOpTypeCreateMe, data: &MeOpCreateMe{NodeID: nodeID, NodeType: pkgservice.NodeTypeDesktop, NodeName: []byte("node")}, want: "0101400300000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004046e6f6465"},
+		{name: "SetNodeName", op: MeOpTypeSetNodeName, data: &MeOpSetNodeName{NodeID: nodeID, Name: []byte("node")}, want: "01014003000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000046e6f6465"},
+		{name: "CreateBoard", op: MeOpTypeCreateBoard, data: &MeOpEntity{LogID: id}, want: "01012801020000000000000000000000000000000000000000000000000000000000000000000000000000"},
+		{name: "JoinBoard", op: MeOpTypeJoinBoard, data: &MeOpEntity{LogID: id}, want: "01012801020000000000000000000000000000000000000000000000000000000000000000000000000000"},
+		{name: "CreateFriend", op: MeOpTypeCreateFriend, data: &MeOpEntity{LogID: id}, want: "01012801020000000000000000000000000000000000000000000000000000000000000000000000000000"},
+		{name: "JoinFriend", op: MeOpTypeJoinFriend, data: &MeOpEntity{LogID: id}, want: "01012801020000000000000000000000000000000000000000000000000000000000000000000000000000"},
+		{name: "MigrateMe", op: MeOpTypeMigrateMe, data: &MeOpMigrateMe{ID: id}, want: "01012801020000000000000000000000000000000000000000000000000000000000000000000000000000"},
+		{name: "DeleteMe", op: MeOpTypeDeleteMe, data: &MeOpDeleteMe{}, want: "0101"},
+		{name: "SetBlockUser", op: MeOpTypeSetBlockUser, data: &MeOpSetBlockUser{Mode: BlockModeMute}, want: "010101"},
+	}
+
+	// run test
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pkgservice.MarshalCanonicalData(tt.data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, hex.EncodeToString(got))
+
+			// the same for the op-data unmarshaled from the peers.
+			o, err := pkgservice.NewOplog(id, ts, id, tt.op, tt.data, nil, id, DBMeOplogPrefix, nil, nil, nil)
+			assert.NoError(t, err)
+			o.SignV = pkgservice.SignVersionCanonical
+			want, err := o.MarshalCanonical()
+			assert.NoError(t, err)
+
+			marshaled, err := o.Marshal()
+			assert.NoError(t, err)
+			o2 := &pkgservice.BaseOplog{}
+			err = o2.Unmarshal(marshaled)
+			assert.NoError(t, err)
+			o2.SetDB(nil, id, DBMeOplogPrefix, nil, nil, nil)
+
+			got2, err := o2.MarshalCanonical()
+			assert.NoError(t, err)
+			assert.Equal(t, want, got2)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package me
+
+import (
+	pttmetrics "github.com/ailabstw/go-pttai-core/metrics"
+)
+
+const (
+	MetricRaftIsLeader      = "ptt_raft_is_leader"
+	MetricRaftTerm          = "ptt_raft_term"
+	MetricRaftCommitIndex   = "ptt_raft_commit_index"
+	MetricRaftAppliedIndex  = "ptt_raft_applied_index"
+	MetricRaftSnapshotIndex = "ptt_raft_snapshot_index"
+	MetricRaftLastIndex     = "ptt_raft_last_index"
+	MetricRaftNodes         = "ptt_raft_nodes"
+)
+
+/*
+CollectMetrics collects the raft-state of me. Collected through the router with the other entities.
+*/
+func (pm *ProtocolManager) CollectMetrics() []*pttmetrics.Sample {
+	status, err := pm.GetRaftStatus()
+	if err != nil {
+		return nil
+	}
+
+	entityID := pm.Entity().GetID().String()
+
+	isLeader := 0.0
+	if status.Lead != 0 && status.Lead == pm.myRouter.MyRaftID() {
+		isLeader = 1.0
+	}
+
+	return []*pttmetrics.Sample{
+		pttmetrics.NewGaugeSample(MetricRaftIsLeader, isLeader, "entity", entityID),
+		pttmetrics.NewGaugeSample(MetricRaftTerm, float64(status.HardState.Term), "entity", entityID),
+		pttmetrics.NewGaugeSample(MetricRaftCommitIndex, float64(status.HardState.Commit), "entity", entityID),
+		pttmetrics.NewGaugeSample(MetricRaftAppliedIndex, float64(status.AppliedInex), "entity", entityID),
+		pttmetrics.NewGaugeSample(MetricRaftSnapshotIndex, float64(status.SnapshotIndex), "entity", entityID),
+		pttmetrics.NewGaugeSample(MetricRaftLastIndex, float64(status.LastIndex), "entity", entityID),
+		pttmetrics.NewGaugeSample(MetricRaftNodes, float64(len(status.ConfState.Nodes)), "entity", entityID),
+	}
+}
@@ -47,10 +47,12 @@ type MyInfo struct {
 	nodeKey *ecdsa.PrivateKey
 
 	validateKey *types.PttID
+
+	db *meDB
 }
 
-func NewEmptyMyInfo() *MyInfo {
-	return &MyInfo{BaseEntity: &pkgservice.BaseEntity{SyncInfo: &pkgservice.BaseSyncInfo{}}}
+func NewEmptyMyInfo(db *meDB) *MyInfo {
+	return &MyInfo{BaseEntity: &pkgservice.BaseEntity{SyncInfo: &pkgservice.BaseSyncInfo{}}, db: db}
 }
 
 func NewMyInfo(id *types.PttID, myKey *ecdsa.PrivateKey, router pkgservice.MyRouter, service pkgservice.Service, spm pkgservice.ServiceProtocolManager, dbLock *types.LockMap) (*MyInfo, error) {
@@ -59,12 +61,16 @@ func NewMyInfo(id *types.PttID, myKey *ecdsa.PrivateKey, router pkgservice.MyRou
 		return nil, err
 	}
 
-	e := pkgservice.NewBaseEntity(id, ts, id, types.StatusPending, dbMe, dbLock)
+	db := spm.(*ServiceProtocolManager).db
+
+	e := pkgservice.NewBaseEntity(id, ts, id, types.StatusPending, db.me, dbLock)
 
 	m := &MyInfo{
 		BaseEntity: e,
 		UpdateTS:   ts,
 		myKey:      myKey,
+
+		db: db,
 	}
 
 	// new my node
@@ -77,7 +83,7 @@ func NewMyInfo(id *types.PttID, myKey *ecdsa.PrivateKey, router pkgservice.MyRou
 	myNode.Status = types.StatusAlive
 	myNode.NodeType = router.MyNodeType()
 
-	_, err = myNode.Save()
+	_, err = myNode.Save(db.myNodes)
 	if err != nil {
 		return nil, err
 	}
@@ -107,7 +113,8 @@ func (m *MyInfo) Init(router pkgservice.Router, service pkgservice.Service, spm
 	}
 
 	MyID := spm.(*ServiceProtocolManager).MyID
-	m.SetDB(dbMe, spm.GetDBLock())
+	m.db = spm.(*ServiceProtocolManager).db
+	m.SetDB(m.db.me, spm.GetDBLock())
 
 	err := m.InitPM(myRouter, service)
 	if err != nil {
@@ -172,6 +179,11 @@ func (m *MyInfo) Init(router pkgservice.Router, service pkgservice.Service, spm
 func (m *MyInfo) loadMyKey() (*ecdsa.PrivateKey, error) {
 	cfg := m.Service().(*Backend).Config
 
+	// the key is not saved in the key-files with the empty data dir.
+	if cfg.PrivateKey != nil && reflect.DeepEqual(cfg.ID, m.ID) {
+		return cfg.PrivateKey, nil
+	}
+
 	return cfg.GetDataPrivateKeyByID(m.ID)
 }
 
@@ -227,7 +239,7 @@ func (m *MyInfo) Save(isLocked bool) error {
 		return err
 	}
 
-	err = dbMeCore.Put(key, marshaled)
+	err = m.db.meCore.Put(key, marshaled)
 	if err != nil {
 		return err
 	}
@@ -251,7 +263,7 @@ func (m *MyInfo) MustSave(isLocked bool) error {
 		return err
 	}
 
-	err = dbMeCore.Put(key, marshaled)
+	err = m.db.meCore.Put(key, marshaled)
 	if err != nil {
 		return err
 	}
@@ -284,6 +296,43 @@ func (m *MyInfo) GetValidateKey() *types.PttID {
 	return m.validateKey
 }
 
+func (m *MyInfo) IsBlockedUser(id *types.PttID) bool {
+	pm, ok := m.PM().(*ProtocolManager)
+	if !ok {
+		return false
+	}
+
+	return pm.IsBlockedUser(id)
+}
+
+func (m *MyInfo) IsMutedUser(id *types.PttID) bool {
+	pm, ok := m.PM().(*ProtocolManager)
+	if !ok {
+		return false
+	}
+
+	return pm.IsMutedUser(id)
+}
+
+func (m *MyInfo) GetMyNodeIDs() []*discover.NodeID {
+	pm, ok := m.PM().(*ProtocolManager)
+	if !ok {
+		return nil
+	}
+
+	myNodeList := pm.GetMyNodeList(false)
+
+	nodeIDs := make([]*discover.NodeID, 0, len(myNodeList))
+	for _, myNode := range myNodeList {
+		if myNode.Status != types.StatusAlive || myNode.NodeID == nil {
+			continue
+		}
+		nodeIDs = append(nodeIDs, myNode.NodeID)
+	}
+
+	return nodeIDs
+}
+
 func (m *MyInfo) GetProfile() pkgservice.Entity {
 	return m.Profile
 }
@@ -24,6 +24,7 @@ import (
 	"github.com/ailabstw/go-pttai-core/common/types"
 	"github.com/ailabstw/go-pttai-core/log"
 	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	"github.com/ailabstw/go-pttai-core/pttdb"
 	pkgservice "github.com/ailabstw/go-pttai-core/service"
 	"github.com/syndtr/goleveldb/leveldb"
 )
@@ -82,7 +83,7 @@ func NewMyNode(ts types.Timestamp, myID *types.PttID, nodeID *discover.NodeID, w
 	}, nil
 }
 
-func (m *MyNode) Save() ([]byte, error) {
+func (m *MyNode) Save(db pttdb.Storage) ([]byte, error) {
 	key, err := m.MarshalKey()
 	if err != nil {
 		return nil, err
@@ -93,7 +94,7 @@ func (m *MyNode) Save() ([]byte, error) {
 		return nil, err
 	}
 
-	err = dbMyNodes.Put(key, marshaled)
+	err = db.Put(key, marshaled)
 	if err != nil {
 		return nil, err
 	}
@@ -101,16 +102,16 @@ func (m *MyNode) Save() ([]byte, error) {
 	return nil, nil
 }
 
-func (m *MyNode) Delete(isLocked bool) error {
+func (m *MyNode) Delete(db pttdb.Storage, isLocked bool) error {
 	key, err := m.MarshalKey()
 	if err != nil {
 		return err
 	}
 
-	return dbMyNodes.Delete(key)
+	return db.Delete(key)
 }
 
-func (m *MyNode) Get(myID *types.PttID, nodeID *discover.NodeID) error {
+func (m *MyNode) Get(db pttdb.Storage, myID *types.PttID, nodeID *discover.NodeID) error {
 	m.ID = myID
 	m.NodeID = nodeID
 	key, err := m.MarshalKey()
@@ -118,7 +119,7 @@ func (m *MyNode) Get(myID *types.PttID, nodeID *discover.NodeID) error {
 		return err
 	}
 
-	theBytes, err := dbMyNodes.Get(key)
+	theBytes, err := db.Get(key)
 	if err != nil {
 		log.Error("unable to Get", "nodeID", nodeID, "e", err)
 		return err
@@ -170,8 +171,8 @@ func (m *MyNode) Unmarshal(theBytes []byte) error {
 	return nil
 }
 
-func (m *MyNode) DeleteRawKey(key []byte) error {
-	err := dbMyNodes.Delete(key)
+func (m *MyNode) DeleteRawKey(db pttdb.Storage, key []byte) error {
+	err := db.Delete(key)
 	if err != nil {
 		return err
 	}
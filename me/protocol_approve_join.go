@@ -50,6 +50,8 @@ func (pm *ProtocolManager) HandleApproveJoin(dataBytes []byte, hash *common.Addr
 		err = pm.HandleApproveJoinFriend(dataBytes, joinRequest, peer)
 	case pm.IsJoinMeRequests(hash):
 		err = pm.HandleApproveJoinMe(dataBytes, joinRequest, peer)
+	case pm.IsJoinEntityRequests(hash):
+		err = pm.HandleApproveJoinEntity(dataBytes, joinRequest, peer)
 	}
 
 	return err
@@ -73,6 +73,8 @@ func (pm *ProtocolManager) ApproveJoinFriend(joinEntity *pkgservice.JoinEntity,
 		FriendData: friendData.(*friend.ApproveJoin),
 	}
 
+	pm.postFriendRequestEvent(joinEntity.ID, nil, pkgservice.JoinStatusAccepted, true)
+
 	return friendOpKeyInfo, data, nil
 }
 
@@ -192,6 +194,8 @@ func (pm *ProtocolManager) HandleApproveJoinFriend(dataBytes []byte, joinRequest
 
 	delete(pm.joinFriendRequests, *joinRequest.Hash)
 
+	pm.postFriendRequestEvent(friendID, joinRequest.Hash, pkgservice.JoinStatusAccepted, false)
+
 	// init-friend-info
 	log.Debug("HandleApproveJoinFriend: to InitFriendInfo", "f", f.ID)
 	err = newPM.InitFriendInfo(peer)
@@ -124,7 +124,7 @@ func (pm *ProtocolManager) HandleApproveJoinMe(dataBytes []byte, joinRequest *pk
 		return err
 	}
 	newMyNode.Status = types.StatusInit
-	_, err = newMyNode.Save()
+	_, err = newMyNode.Save(pm.db.myNodes)
 	log.Debug("HandleApproveJoinMe: after myNode2.Save", "myNode2", newMyNode, "ID", newMyNode.ID, "e", err)
 	if err != nil {
 		return err
@@ -135,7 +135,7 @@ func (pm *ProtocolManager) HandleApproveJoinMe(dataBytes []byte, joinRequest *pk
 		return err
 	}
 	newMyNode2.Status = types.StatusAlive
-	_, err = newMyNode2.Save()
+	_, err = newMyNode2.Save(pm.db.myNodes)
 	log.Debug("HandleApproveJoinMe: after myNode.Save", "myNode", newMyNode2, "ID", newMyNode2.ID, "e", err)
 	if err != nil {
 		return err
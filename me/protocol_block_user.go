@@ -0,0 +1,61 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package me
+
+import (
+	"reflect"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+)
+
+/*
+SetBlockUser sets the block-mode of the user, and syncs to my devices with the me-oplog.
+*/
+func (pm *ProtocolManager) SetBlockUser(userID *types.PttID, mode BlockMode) (*BlockUser, error) {
+
+	myID := pm.Entity().GetID()
+	if userID == nil || reflect.DeepEqual(userID, myID) || mode >= NBlockMode {
+		return nil, ErrInvalidBlockUser
+	}
+
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return nil, err
+	}
+
+	oplog, err := pm.CreateMeOplog(userID, ts, MeOpTypeSetBlockUser, &MeOpSetBlockUser{Mode: mode})
+	if err != nil {
+		return nil, err
+	}
+
+	oplog.IsSync = true
+
+	err = oplog.Save(false, pm.meOplogMerkle)
+	if err != nil {
+		return nil, err
+	}
+
+	blockUser := NewBlockUser(myID, userID, mode, oplog.UpdateTS, oplog.ID)
+	_, err = pm.integrateBlockUser(blockUser)
+	if err != nil {
+		return nil, err
+	}
+
+	pm.BroadcastMeOplog(oplog)
+
+	return blockUser, nil
+}
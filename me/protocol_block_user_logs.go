@@ -0,0 +1,57 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package me
+
+import (
+	"reflect"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+func (pm *ProtocolManager) handleSetBlockUserLog(oplog *pkgservice.BaseOplog, info *ProcessMeInfo) ([]*pkgservice.BaseOplog, error) {
+
+	opData := &MeOpSetBlockUser{}
+	err := oplog.GetData(opData)
+	if err != nil {
+		return nil, err
+	}
+
+	if opData.Mode >= NBlockMode {
+		return nil, ErrInvalidBlockUser
+	}
+
+	myID := pm.Entity().GetID()
+	blockUser := NewBlockUser(myID, oplog.ObjID, opData.Mode, oplog.UpdateTS, oplog.ID)
+
+	_, err = pm.integrateBlockUser(blockUser)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (pm *ProtocolManager) setNewestSetBlockUserLog(oplog *pkgservice.BaseOplog) (types.Bool, error) {
+
+	blockUser := pm.GetBlockUser(oplog.ObjID)
+	if blockUser == nil {
+		return false, nil
+	}
+
+	return !types.Bool(reflect.DeepEqual(blockUser.LogID, oplog.ID)), nil
+}
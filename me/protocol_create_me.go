@@ -123,7 +123,7 @@ func (pm *ProtocolManager) CreateFullMe(oplog *MasterOplog) error {
 	}
 
 	myNode.NodeName = myHostname
-	myNode.Save()
+	myNode.Save(pm.db.myNodes)
 
 	// meOplog save
 	meOplog.Save(false, pm.meOplogMerkle)
@@ -25,7 +25,7 @@ import (
 )
 
 func (spm *ServiceProtocolManager) GetMeList(myID *types.PttID, startingID *types.PttID, limit int) (*MyInfo, []*MyInfo, error) {
-	iter, err := getMeIter(startingID)
+	iter, err := spm.getMeIter(startingID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -41,7 +41,7 @@ func (spm *ServiceProtocolManager) GetMeList(myID *types.PttID, startingID *type
 
 		v := iter.Value()
 
-		eachMe := &MyInfo{}
+		eachMe := &MyInfo{db: spm.db}
 		err = eachMe.Unmarshal(v)
 		if err != nil {
 			continue
@@ -59,13 +59,13 @@ func (spm *ServiceProtocolManager) GetMeList(myID *types.PttID, startingID *type
 	return myInfo, meList, nil
 }
 
-func getMeIter(startingID *types.PttID) (iterator.Iterator, error) {
+func (spm *ServiceProtocolManager) getMeIter(startingID *types.PttID) (iterator.Iterator, error) {
 	if startingID == nil {
-		return dbMe.DB().NewIteratorWithPrefix(nil, DBMePrefix, pttdb.ListOrderNext)
+		return spm.db.me.DB().NewIteratorWithPrefix(nil, DBMePrefix, pttdb.ListOrderNext)
 	}
 
 	// key
-	myInfo := NewEmptyMyInfo()
+	myInfo := NewEmptyMyInfo(spm.db)
 	myInfo.SetID(startingID)
 
 	key, err := myInfo.MarshalKey()
@@ -74,7 +74,7 @@ func getMeIter(startingID *types.PttID) (iterator.Iterator, error) {
 	}
 
 	// iter
-	iter, err := dbMe.DB().NewIteratorWithPrefix(key, DBMePrefix, pttdb.ListOrderNext)
+	iter, err := spm.db.me.DB().NewIteratorWithPrefix(key, DBMePrefix, pttdb.ListOrderNext)
 	if err != nil {
 		return nil, err
 	}
@@ -60,6 +60,9 @@ func (pm *ProtocolManager) processMeLog(oplog *pkgservice.BaseOplog, processInfo
 	case MeOpTypeJoinFriend:
 		origLogs, err = pm.handleFriendLog(oplog, info)
 
+	case MeOpTypeSetBlockUser:
+		origLogs, err = pm.handleSetBlockUserLog(oplog, info)
+
 	case MeOpTypeSetNodeName:
 	}
 	return
@@ -133,6 +136,9 @@ func (pm *ProtocolManager) SetNewestMeOplog(oplog *pkgservice.BaseOplog) (err er
 	case MeOpTypeJoinFriend:
 		isNewer, err = pm.setNewestFriendLog(oplog)
 
+	case MeOpTypeSetBlockUser:
+		isNewer, err = pm.setNewestSetBlockUserLog(oplog)
+
 	case MeOpTypeSetNodeName:
 	}
 
@@ -170,6 +170,8 @@ func (pm *ProtocolManager) HandleSyncNewMeOplogAck(dataBytes []byte, peer *pkgse
 		pm.SetMeDB,
 		pm.HandleMeOplogs,
 		pm.postsyncMeOplogs,
+
+		pm.meOplogMerkle,
 	)
 }
 
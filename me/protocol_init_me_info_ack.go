@@ -56,7 +56,7 @@ func (pm *ProtocolManager) InitMeInfoAck(data *InitMeInfo, peer *pkgservice.PttP
 		myNode := pm.MyNodes[myRaftID]
 		myNode.Status = myInfo.Status
 		myNode.UpdateTS = ts
-		_, err = myNode.Save()
+		_, err = myNode.Save(pm.db.myNodes)
 		if err != nil {
 			return err
 		}
@@ -113,7 +113,7 @@ func (pm *ProtocolManager) handleInitMeInfoCore(status types.Status, peer *pkgse
 
 	myNode.Status = status
 	myNode.UpdateTS = ts
-	_, err = myNode.Save()
+	_, err = myNode.Save(pm.db.myNodes)
 	if err != nil {
 		return err
 	}
@@ -43,13 +43,15 @@ func (pm *ProtocolManager) InternalSyncToAlive(oplog *MasterOplog, weight uint32
 		return err
 	}
 
+	pm.PostEntityStatusEvent()
+
 	myRaftID := pm.myRouter.MyRaftID()
 	myNode := pm.MyNodes[myRaftID]
 	myNode.Status = types.StatusAlive
 	myNode.UpdateTS = oplog.UpdateTS
 	myNode.LogID = oplog.ID
 
-	_, err = myNode.Save()
+	_, err = myNode.Save(pm.db.myNodes)
 	if err != nil {
 		return err
 	}
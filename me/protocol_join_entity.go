@@ -0,0 +1,192 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package me
+
+import (
+	"time"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type JoinEntityEvent struct {
+	JoinRequest *pkgservice.JoinRequest
+}
+
+/*
+JoinEntity requests joining the entity from the other services (ex: group).
+The approve-join is passed to handleApproveJoin. (joiner)
+*/
+func (m *MyInfo) JoinEntity(joinRequest *pkgservice.JoinRequest, handleApproveJoin pkgservice.ApproveJoinHandler) error {
+	return m.PM().(*ProtocolManager).JoinEntity(joinRequest, handleApproveJoin)
+}
+
+func (pm *ProtocolManager) JoinEntity(joinRequest *pkgservice.JoinRequest, handleApproveJoin pkgservice.ApproveJoinHandler) error {
+
+	myInfo := pm.Entity().(*MyInfo)
+	if myInfo.Status != types.StatusAlive {
+		return types.ErrInvalidStatus
+	}
+
+	// lock
+	pm.lockJoinEntityRequest.Lock()
+	defer pm.lockJoinEntityRequest.Unlock()
+
+	// hash-val
+	hashVal := *joinRequest.Hash
+
+	_, ok := pm.joinEntityRequests[hashVal]
+	if ok {
+		log.Error("JoinEntity: entity-request already exists", "hash", joinRequest.Hash)
+		return types.ErrAlreadyExists
+	}
+
+	pm.joinEntityRequests[hashVal] = joinRequest
+	pm.joinEntityHandlers[hashVal] = handleApproveJoin
+
+	pm.EventMux().Post(&JoinEntityEvent{JoinRequest: joinRequest})
+
+	return nil
+}
+
+func (pm *ProtocolManager) SyncJoinEntityLoop() error {
+	log.Debug("SyncJoinEntityLoop: Start")
+	ticker := time.NewTicker(SyncJoinSeconds)
+	defer ticker.Stop()
+
+	pm.SyncJoinEntity()
+
+loop:
+	for {
+		select {
+		case <-ticker.C:
+			pm.SyncJoinEntity()
+		case <-pm.QuitSync():
+			log.Debug("SyncJoinEntityLoop: QuitSync", "entity", pm.Entity().GetID())
+			break loop
+		}
+	}
+
+	return nil
+}
+
+func (pm *ProtocolManager) SyncJoinEntity() error {
+	pm.lockJoinEntityRequest.Lock()
+	defer pm.lockJoinEntityRequest.Unlock()
+
+	now, err := types.GetTimestamp()
+	if err != nil {
+		return err
+	}
+
+	toRemoveHashs := make([]*common.Address, 0)
+	for _, joinRequest := range pm.joinEntityRequests {
+		if joinRequest.CreateTS.Ts < now.Ts-pkgservice.IntRenewJoinKeySeconds {
+			log.Warn("SyncJoinEntity: expired", "joinRequest", joinRequest.CreateTS, "now", now)
+			toRemoveHashs = append(toRemoveHashs, joinRequest.Hash)
+			continue
+		}
+
+		if joinRequest.Status != pkgservice.JoinStatusPending {
+			continue
+		}
+
+		pm.processJoinEntityEvent(joinRequest, true)
+	}
+
+	log.Debug("SyncJoinEntity: to remove hashs", "hashs", toRemoveHashs)
+	for _, hash := range toRemoveHashs {
+		delete(pm.joinEntityRequests, *hash)
+		delete(pm.joinEntityHandlers, *hash)
+	}
+
+	return nil
+}
+
+func (pm *ProtocolManager) JoinEntityLoop() error {
+	for obj := range pm.joinEntitySub.Chan() {
+		ev, ok := obj.Data.(*JoinEntityEvent)
+		if !ok {
+			log.Error("JoinEntityLoop: unable to get JoinEntityEvent", "data", obj.Data)
+			continue
+		}
+
+		err := pm.processJoinEntityEvent(ev.JoinRequest, false)
+		if err != nil {
+			log.Error("unable to process join entity event", "e", err)
+		}
+	}
+
+	return nil
+}
+
+func (pm *ProtocolManager) processJoinEntityEvent(request *pkgservice.JoinRequest, isLocked bool) error {
+	if !isLocked {
+		pm.lockJoinEntityRequest.Lock()
+		defer pm.lockJoinEntityRequest.Unlock()
+	}
+
+	if request.Status != pkgservice.JoinStatusPending {
+		return pkgservice.ErrInvalidStatus
+	}
+
+	hash, key, challenge := request.Hash, request.Key, request.Challenge
+
+	ptt := pm.Router()
+	err := ptt.TryJoin(challenge, hash, key, request)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (pm *ProtocolManager) IsJoinEntityRequests(hash *common.Address) bool {
+	pm.lockJoinEntityRequest.RLock()
+	defer pm.lockJoinEntityRequest.RUnlock()
+
+	_, ok := pm.joinEntityRequests[*hash]
+
+	return ok
+}
+
+func (pm *ProtocolManager) HandleApproveJoinEntity(dataBytes []byte, joinRequest *pkgservice.JoinRequest, peer *pkgservice.PttPeer) error {
+
+	pm.lockJoinEntityRequest.RLock()
+	handleApproveJoin, ok := pm.joinEntityHandlers[*joinRequest.Hash]
+	pm.lockJoinEntityRequest.RUnlock()
+	if !ok {
+		return pkgservice.ErrInvalidData
+	}
+
+	err := handleApproveJoin(dataBytes, joinRequest, peer)
+	log.Debug("HandleApproveJoinEntity: after handleApproveJoin", "e", err)
+	if err != nil {
+		return err
+	}
+
+	// remove joinEntityRequest
+	pm.lockJoinEntityRequest.Lock()
+	defer pm.lockJoinEntityRequest.Unlock()
+
+	delete(pm.joinEntityRequests, *joinRequest.Hash)
+	delete(pm.joinEntityHandlers, *joinRequest.Hash)
+
+	return nil
+}
@@ -53,6 +53,8 @@ func (pm *ProtocolManager) JoinFriend(joinRequest *pkgservice.JoinRequest) error
 
 	pm.EventMux().Post(&JoinFriendEvent{JoinRequest: joinRequest})
 
+	pm.postFriendRequestEvent(joinRequest.CreatorID, joinRequest.Hash, joinRequest.Status, false)
+
 	return nil
 }
 
@@ -35,6 +35,9 @@ type ProtocolManager struct {
 
 	myRouter pkgservice.MyRouter
 
+	// db
+	db *meDB
+
 	// key-infos for providing join-friend
 	lockJoinFriendKeyInfo sync.RWMutex
 	joinFriendKeyInfos    []*pkgservice.KeyInfo
@@ -44,16 +47,29 @@ type ProtocolManager struct {
 	joinFriendRequests    map[common.Address]*pkgservice.JoinRequest
 	joinFriendSub         *event.TypeMuxSubscription
 
+	// requests to join-entity (other services)
+	lockJoinEntityRequest sync.RWMutex
+	joinEntityRequests    map[common.Address]*pkgservice.JoinRequest
+	joinEntityHandlers    map[common.Address]pkgservice.ApproveJoinHandler
+	joinEntitySub         *event.TypeMuxSubscription
+
 	// my-nodes
 	lockJoinMeRequest sync.RWMutex
 	joinMeRequests    map[common.Address]*pkgservice.JoinRequest
 	joinMeSub         *event.TypeMuxSubscription
 
+	// recover-me
+	recoverKeyInfo *pkgservice.KeyInfo
+
 	lockMyNodes         sync.RWMutex
 	MyNodes             map[uint64]*MyNode
 	MyNodeByNodeSignIDs map[types.PttID]*MyNode
 	totalWeight         uint32
 
+	// block-list
+	lockBlockUsers sync.RWMutex
+	blockUsers     map[types.PttID]*BlockUser
+
 	// master-oplog
 	dbMasterLock *types.LockMap
 
@@ -106,7 +122,7 @@ func NewProtocolManager(myInfo *MyInfo, router pkgservice.MyRouter, svc pkgservi
 	entityIDBytes, _ := myID.MarshalText()
 	entityIDStr := string(entityIDBytes)
 
-	meOplogMerkle, err := pkgservice.NewMerkle(DBMeOplogPrefix, DBMeMerkleOplogPrefix, myID, dbMe, "("+entityIDStr+"/"+svc.Name()+":me)")
+	meOplogMerkle, err := pkgservice.NewMerkle(DBMeOplogPrefix, DBMeMerkleOplogPrefix, myID, myInfo.db.me, "("+entityIDStr+"/"+svc.Name()+":me)")
 	if err != nil {
 		return nil, err
 	}
@@ -115,6 +131,7 @@ func NewProtocolManager(myInfo *MyInfo, router pkgservice.MyRouter, svc pkgservi
 
 	pm := &ProtocolManager{
 		myRouter: router,
+		db:       myInfo.db,
 
 		// dblock
 		dbMeLock:     dbMeLock,
@@ -126,6 +143,12 @@ func NewProtocolManager(myInfo *MyInfo, router pkgservice.MyRouter, svc pkgservi
 
 		joinMeRequests: make(map[common.Address]*pkgservice.JoinRequest),
 
+		joinEntityRequests: make(map[common.Address]*pkgservice.JoinRequest),
+		joinEntityHandlers: make(map[common.Address]pkgservice.ApproveJoinHandler),
+
+		// block-list
+		blockUsers: make(map[types.PttID]*BlockUser),
+
 		// merkle
 		meOplogMerkle: meOplogMerkle,
 
@@ -176,13 +199,15 @@ func NewProtocolManager(myInfo *MyInfo, router pkgservice.MyRouter, svc pkgservi
 		myInfo, // entity
 		svc,
 
-		dbMe, // db
+		myInfo.db.me, // db
 	)
 	if err != nil {
 		return nil, err
 	}
 	pm.BaseProtocolManager = b
 
+	pm.SetOpRateLimit(ForceSyncMeOplogByMerkleMsg, pkgservice.ForceSyncOplogByMerkleRateLimit)
+
 	// master-log
 	masterLogs, err := pm.GetMasterOplogList(nil, 1, pttdb.ListOrderNext, types.StatusAlive)
 	if len(masterLogs) == 1 {
@@ -196,6 +221,13 @@ func NewProtocolManager(myInfo *MyInfo, router pkgservice.MyRouter, svc pkgservi
 		return nil, err
 	}
 
+	// load-block-users
+	err = pm.LoadBlockUsers()
+	if err != nil {
+		log.Error("NewProtocolManager: unable to LoadBlockUsers", "e", err)
+		return nil, err
+	}
+
 	return pm, nil
 }
 
@@ -263,6 +295,12 @@ func (pm *ProtocolManager) Start() error {
 		pm.SyncJoinMeLoop()
 	}()
 
+	// recover-me
+	err = pm.createRecoverKey()
+	if err != nil {
+		log.Warn("Start: unable to create recover key", "e", err)
+	}
+
 	// join-friend
 	pm.joinFriendSub = pm.EventMux().Subscribe(&JoinFriendEvent{})
 	go pm.JoinFriendLoop()
@@ -279,6 +317,16 @@ func (pm *ProtocolManager) Start() error {
 		pm.SyncJoinFriendLoop()
 	}()
 
+	// join-entity
+	pm.joinEntitySub = pm.EventMux().Subscribe(&JoinEntityEvent{})
+	go pm.JoinEntityLoop()
+
+	syncWG.Add(1)
+	go func() {
+		defer syncWG.Done()
+		pm.SyncJoinEntityLoop()
+	}()
+
 	// oplog-merkle-tree
 	syncWG.Add(1)
 	go func() {
@@ -305,6 +353,9 @@ func (pm *ProtocolManager) Stop() error {
 
 	pm.joinFriendSub.Unsubscribe()
 	pm.joinMeSub.Unsubscribe()
+	pm.joinEntitySub.Unsubscribe()
+
+	pm.removeRecoverKey()
 
 	pm.StopRaft()
 
@@ -29,6 +29,11 @@ func (pm *ProtocolManager) GetJoinKeyFromHash(hash *common.Address) (*pkgservice
 		return keyInfo, nil
 	}
 
+	keyInfo, err = pm.getRecoverKeyFromHash(hash)
+	if err == nil {
+		return keyInfo, nil
+	}
+
 	pm.lockJoinFriendRequest.RLock()
 	defer pm.lockJoinFriendRequest.RUnlock()
 
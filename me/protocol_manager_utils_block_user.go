@@ -0,0 +1,145 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package me
+
+import (
+	"sort"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+)
+
+func (pm *ProtocolManager) LoadBlockUsers() error {
+	myID := pm.Entity().GetID()
+
+	blockUser := &BlockUser{ID: myID}
+	prefix, err := blockUser.DBPrefix()
+	if err != nil {
+		return err
+	}
+
+	iter, err := pm.db.meta.NewIteratorWithPrefix(nil, prefix, pttdb.ListOrderNext)
+	if err != nil {
+		return err
+	}
+	defer iter.Release()
+
+	blockUsers := make(map[types.PttID]*BlockUser)
+	for iter.Next() {
+		v := iter.Value()
+
+		eachBlockUser := &BlockUser{}
+		err = eachBlockUser.Unmarshal(v)
+		if err != nil {
+			log.Warn("LoadBlockUsers: unable to unmarshal", "k", iter.Key(), "e", err)
+			continue
+		}
+
+		blockUsers[*eachBlockUser.UserID] = eachBlockUser
+	}
+
+	pm.lockBlockUsers.Lock()
+	defer pm.lockBlockUsers.Unlock()
+
+	pm.blockUsers = blockUsers
+
+	return nil
+}
+
+func (pm *ProtocolManager) GetBlockUser(userID *types.PttID) *BlockUser {
+	pm.lockBlockUsers.RLock()
+	defer pm.lockBlockUsers.RUnlock()
+
+	return pm.blockUsers[*userID]
+}
+
+/*
+GetBlockUsers returns the blocked / muted users (sorted by update-ts).
+*/
+func (pm *ProtocolManager) GetBlockUsers() []*BlockUser {
+	pm.lockBlockUsers.RLock()
+	defer pm.lockBlockUsers.RUnlock()
+
+	blockUsers := make([]*BlockUser, 0, len(pm.blockUsers))
+	for _, blockUser := range pm.blockUsers {
+		if blockUser.Mode == BlockModeNone {
+			continue
+		}
+		blockUsers = append(blockUsers, blockUser)
+	}
+
+	sort.Slice(blockUsers, func(i, j int) bool {
+		return blockUsers[i].UpdateTS.IsLess(blockUsers[j].UpdateTS)
+	})
+
+	return blockUsers
+}
+
+func (pm *ProtocolManager) getBlockMode(userID *types.PttID) BlockMode {
+	if userID == nil {
+		return BlockModeNone
+	}
+
+	pm.lockBlockUsers.RLock()
+	defer pm.lockBlockUsers.RUnlock()
+
+	blockUser, ok := pm.blockUsers[*userID]
+	if !ok {
+		return BlockModeNone
+	}
+
+	return blockUser.Mode
+}
+
+/*
+IsBlockedUser checks whether the user is blocked.
+*/
+func (pm *ProtocolManager) IsBlockedUser(userID *types.PttID) bool {
+	return pm.getBlockMode(userID) == BlockModeBlock
+}
+
+/*
+IsMutedUser checks whether the notifications from the user are suppressed (muted or blocked).
+*/
+func (pm *ProtocolManager) IsMutedUser(userID *types.PttID) bool {
+	return pm.getBlockMode(userID) != BlockModeNone
+}
+
+/*
+integrateBlockUser integrates the block-user if it's newer than the existing one (last-writer-wins by update-ts).
+*/
+func (pm *ProtocolManager) integrateBlockUser(blockUser *BlockUser) (bool, error) {
+	pm.lockBlockUsers.Lock()
+	defer pm.lockBlockUsers.Unlock()
+
+	origBlockUser, ok := pm.blockUsers[*blockUser.UserID]
+	if ok && !origBlockUser.UpdateTS.IsLess(blockUser.UpdateTS) {
+		return false, nil
+	}
+
+	err := blockUser.Save(pm.db.meta)
+	if err != nil {
+		return false, err
+	}
+
+	pm.blockUsers[*blockUser.UserID] = blockUser
+
+	log.Info("integrateBlockUser: done", "userID", blockUser.UserID, "mode", blockUser.Mode)
+
+	return true, nil
+}
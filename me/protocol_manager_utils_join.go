@@ -37,6 +37,12 @@ func (pm *ProtocolManager) GetJoinRequest(hash *common.Address) (*pkgservice.Joi
 		return joinRequest, nil
 	}
 
+	// entity
+	joinRequest, err = pm.getJoinRequestCore(hash, &pm.lockJoinEntityRequest, pm.joinEntityRequests)
+	if err == nil {
+		return joinRequest, nil
+	}
+
 	return nil, pkgservice.ErrInvalidMsg
 
 }
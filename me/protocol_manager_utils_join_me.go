@@ -23,7 +23,13 @@ import (
 )
 
 func (pm *ProtocolManager) IsJoinMeKeyHash(hash *common.Address) bool {
-	return pm.BaseProtocolManager.IsJoinKeyHash(hash)
+	if pm.BaseProtocolManager.IsJoinKeyHash(hash) {
+		return true
+	}
+
+	_, err := pm.getRecoverKeyFromHash(hash)
+
+	return err == nil
 }
 
 func (pm *ProtocolManager) IsJoinMeRequests(hash *common.Address) bool {
@@ -75,7 +75,7 @@ func (pm *ProtocolManager) LoadMyNodes() error {
 		return err
 	}
 
-	iter, err := dbMyNodes.NewIteratorWithPrefix(nil, key, pttdb.ListOrderNext)
+	iter, err := pm.db.myNodes.NewIteratorWithPrefix(nil, key, pttdb.ListOrderNext)
 	if err != nil {
 		return err
 	}
@@ -127,7 +127,7 @@ func (pm *ProtocolManager) LoadMyNodes() error {
 
 	myNode = &MyNode{}
 	for _, eachID := range toRemoveIDs {
-		err := myNode.DeleteRawKey(eachID)
+		err := myNode.DeleteRawKey(pm.db.myNodes, eachID)
 		if err != nil {
 			continue
 		}
@@ -32,7 +32,7 @@ func (pm *ProtocolManager) CreateMasterOplog(raftIdx uint64, ts types.Timestamp,
 	myID := myEntity.ID
 	nodeSignID := myEntity.NodeSignID
 
-	oplog, err := NewMasterOplog(myID, ts, nodeSignID, op, data, pm.dbMasterLock)
+	oplog, err := NewMasterOplog(myID, ts, nodeSignID, op, data, pm.db.me, pm.dbMasterLock)
 	if err != nil {
 		return nil, err
 	}
@@ -25,7 +25,7 @@ func (pm *ProtocolManager) CreateMeOplog(objID *types.PttID, ts types.Timestamp,
 
 	myID := pm.Entity().GetID()
 
-	oplog, err := NewMeOplog(objID, ts, myID, op, data, myID, pm.dbMeLock)
+	oplog, err := NewMeOplog(objID, ts, myID, op, data, pm.db.me, myID, pm.dbMeLock)
 	if err != nil {
 		return nil, err
 	}
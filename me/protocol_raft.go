@@ -48,7 +48,7 @@ func (pm *ProtocolManager) StartRaft(peers []raft.Peer, isNew bool) error {
 	if !isNew {
 		log.Debug("StartRaft: to RestartNode")
 
-		rs, err := NewRaftStorage(false, myID)
+		rs, err := NewRaftStorage(false, myID, pm.db.raft)
 		if err != nil {
 			return err
 		}
@@ -59,7 +59,7 @@ func (pm *ProtocolManager) StartRaft(peers []raft.Peer, isNew bool) error {
 
 		log.Debug("StartRaft: after RestartNode")
 	} else {
-		rs, err := NewRaftStorage(true, myID)
+		rs, err := NewRaftStorage(true, myID, pm.db.raft)
 		if err != nil {
 			return err
 		}
@@ -233,7 +233,7 @@ func (pm *ProtocolManager) SetRaftAppliedIndex(idx uint64, isLocked bool) error
 	val := make([]byte, 8)
 	binary.BigEndian.PutUint64(val, idx)
 
-	err = dbMeCore.Put(key, val)
+	err = pm.db.meCore.Put(key, val)
 	if err != nil {
 		return err
 	}
@@ -268,7 +268,7 @@ func (pm *ProtocolManager) LoadRaftAppliedIndex(isLocked bool) error {
 		return err
 	}
 
-	val, err := dbMeCore.Get(key)
+	val, err := pm.db.meCore.Get(key)
 	if err != nil {
 		return err
 	}
@@ -295,7 +295,7 @@ func (pm *ProtocolManager) SetRaftSnapshotIndex(idx uint64, isLocked bool) error
 	val := make([]byte, 8)
 	binary.BigEndian.PutUint64(val, idx)
 
-	err = dbMeCore.Put(key, val)
+	err = pm.db.meCore.Put(key, val)
 	if err != nil {
 		return err
 	}
@@ -330,7 +330,7 @@ func (pm *ProtocolManager) LoadRaftSnapshotIndex(isLocked bool) error {
 		return err
 	}
 
-	val, err := dbMeCore.Get(key)
+	val, err := pm.db.meCore.Get(key)
 	if err != nil {
 		return err
 	}
@@ -357,7 +357,7 @@ func (pm *ProtocolManager) SetRaftLastIndex(idx uint64, isLocked bool) error {
 	val := make([]byte, 8)
 	binary.BigEndian.PutUint64(val, idx)
 
-	err = dbMeCore.Put(key, val)
+	err = pm.db.meCore.Put(key, val)
 	if err != nil {
 		return err
 	}
@@ -392,7 +392,7 @@ func (pm *ProtocolManager) LoadRaftLastIndex(isLocked bool) error {
 		return err
 	}
 
-	val, err := dbMeCore.Get(key)
+	val, err := pm.db.meCore.Get(key)
 	if err != nil {
 		return err
 	}
@@ -443,7 +443,7 @@ func (pm *ProtocolManager) SetRaftConfState(cs pb.ConfState, isLocked bool) erro
 		return err
 	}
 
-	err = dbMeCore.Put(key, val)
+	err = pm.db.meCore.Put(key, val)
 	if err != nil {
 		return err
 	}
@@ -478,7 +478,7 @@ func (pm *ProtocolManager) LoadRaftConfState(isLocked bool) error {
 		return err
 	}
 
-	val, err := dbMeCore.Get(key)
+	val, err := pm.db.meCore.Get(key)
 	if err != nil {
 		return err
 	}
@@ -512,7 +512,7 @@ func (pm *ProtocolManager) CleanRaftStorage(isLocked bool) error {
 	if err != nil {
 		return err
 	}
-	err = dbMeCore.Delete(key)
+	err = pm.db.meCore.Delete(key)
 	if err != nil {
 		return err
 	}
@@ -522,7 +522,7 @@ func (pm *ProtocolManager) CleanRaftStorage(isLocked bool) error {
 	if err != nil {
 		return err
 	}
-	err = dbMeCore.Delete(key)
+	err = pm.db.meCore.Delete(key)
 	if err != nil {
 		return err
 	}
@@ -532,7 +532,7 @@ func (pm *ProtocolManager) CleanRaftStorage(isLocked bool) error {
 	if err != nil {
 		return err
 	}
-	err = dbMeCore.Delete(key)
+	err = pm.db.meCore.Delete(key)
 	if err != nil {
 		return err
 	}
@@ -542,14 +542,14 @@ func (pm *ProtocolManager) CleanRaftStorage(isLocked bool) error {
 	if err != nil {
 		return err
 	}
-	err = dbMeCore.Delete(key)
+	err = pm.db.meCore.Delete(key)
 	if err != nil {
 		return err
 	}
 
 	// raft-storage
 	myID := pm.Entity().GetID()
-	err = CleanRaftStorage(myID, pm.rs, false)
+	err = CleanRaftStorage(myID, pm.db.raft, pm.rs, false)
 	if err != nil {
 		return err
 	}
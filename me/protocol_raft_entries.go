@@ -334,7 +334,7 @@ func (pm *ProtocolManager) publishEntriesAddNodeCreateMasterOplogAndSetMyNode(ts
 
 	pm.MyNodeByNodeSignIDs[*nodeSignID] = myNode
 
-	_, err = myNode.Save()
+	_, err = myNode.Save(pm.db.myNodes)
 	log.Debug("publishEntriesAddNode: after myNode.Save", "e", err, "myNode", myNode)
 	if err != nil {
 		return nil, err
@@ -388,7 +388,7 @@ func (pm *ProtocolManager) publishEntriesRemoveNode(ent *pb.Entry, cc *pb.ConfCh
 	myNode.Status = types.StatusDeleted
 	myNode.LogID = oplog.ID
 
-	_, err = myNode.Save()
+	_, err = myNode.Save(pm.db.myNodes)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,163 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package me
+
+import (
+	"crypto/ecdsa"
+	"reflect"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+/*
+The recover-key is the join-me-key derived from my key.
+
+All my nodes with my key accept the recover-key as the join-me-key,
+so the node restored from the mnemonic words is able to join me through any of my nodes,
+without showing the me-url from my nodes.
+*/
+
+func deriveRecoverKey(myKey *ecdsa.PrivateKey) (*ecdsa.PrivateKey, error) {
+	return crypto.ToECDSA(crypto.Keccak256(RecoverKeySalt, crypto.FromECDSA(myKey)))
+}
+
+func (pm *ProtocolManager) createRecoverKey() error {
+	myInfo := pm.Entity().(*MyInfo)
+	if myInfo.myKey == nil {
+		return nil
+	}
+
+	statusClass := types.StatusToStatusClass(myInfo.Status)
+	if statusClass >= types.StatusClassDeleted {
+		return nil
+	}
+
+	recoverKey, err := deriveRecoverKey(myInfo.myKey)
+	if err != nil {
+		return err
+	}
+
+	keyInfo, err := pkgservice.NewJoinKeyInfoFromKey(recoverKey, myInfo.ID)
+	if err != nil {
+		return err
+	}
+
+	pm.lockJoinMeRequest.Lock()
+	defer pm.lockJoinMeRequest.Unlock()
+
+	pm.recoverKeyInfo = keyInfo
+
+	return pm.myRouter.AddJoinKey(keyInfo.Hash, myInfo.ID, false)
+}
+
+func (pm *ProtocolManager) removeRecoverKey() error {
+	pm.lockJoinMeRequest.Lock()
+	defer pm.lockJoinMeRequest.Unlock()
+
+	if pm.recoverKeyInfo == nil {
+		return nil
+	}
+
+	err := pm.myRouter.RemoveJoinKey(pm.recoverKeyInfo.Hash, pm.Entity().GetID(), false)
+	pm.recoverKeyInfo = nil
+
+	return err
+}
+
+func (pm *ProtocolManager) getRecoverKeyFromHash(hash *common.Address) (*pkgservice.KeyInfo, error) {
+	pm.lockJoinMeRequest.RLock()
+	defer pm.lockJoinMeRequest.RUnlock()
+
+	if pm.recoverKeyInfo == nil || !reflect.DeepEqual(pm.recoverKeyInfo.Hash, hash) {
+		return nil, pkgservice.ErrInvalidKeyInfo
+	}
+
+	return pm.recoverKeyInfo, nil
+}
+
+/*
+RecoverMe requests joining the me restored from the mnemonic words through one of the nodes of the restored me. (joiner)
+
+The join-me procedure is the same as JoinMe, except that the join-key is the recover-key.
+After approved, this node is added as my-node through raft,
+and my identity is migrated to the restored one (with the same ID) in InitMeInfoSync.
+*/
+func (pm *ProtocolManager) RecoverMe(mnemonic string, nodeID *discover.NodeID) (*pkgservice.JoinRequest, error) {
+	myInfo := pm.Entity().(*MyInfo)
+	if myInfo.Status != types.StatusAlive {
+		return nil, types.ErrInvalidStatus
+	}
+
+	log.Debug("RecoverMe: start", "nodeID", nodeID)
+
+	recoverMyKey, _, recoverID, err := mnemonicToKey(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	// already restored as me.
+	if reflect.DeepEqual(recoverID, myInfo.ID) {
+		return nil, ErrInvalidMe
+	}
+
+	recoverKey, err := deriveRecoverKey(recoverMyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return nil, err
+	}
+
+	hash := crypto.PubkeyToAddress(recoverKey.PublicKey)
+
+	joinRequest := &pkgservice.JoinRequest{
+		CreatorID: recoverID,
+		CreateTS:  ts,
+		NodeID:    nodeID,
+		Hash:      &hash,
+		Key:       recoverKey,
+		Status:    pkgservice.JoinStatusPending,
+		Challenge: pkgservice.GenChallenge(),
+	}
+
+	// lock
+	pm.lockJoinMeRequest.Lock()
+	defer pm.lockJoinMeRequest.Unlock()
+
+	// already with other nodes
+	if len(pm.MyNodes) > 1 {
+		return nil, ErrAlreadyMyNode
+	}
+
+	_, ok := pm.joinMeRequests[hash]
+	if ok {
+		return nil, types.ErrAlreadyExists
+	}
+
+	pm.joinMeRequests[hash] = joinRequest
+
+	pm.EventMux().Post(&JoinMeEvent{JoinMeRequest: joinRequest})
+
+	return joinRequest, nil
+}
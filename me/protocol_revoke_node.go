@@ -151,6 +151,8 @@ func (pm *ProtocolManager) revokeMyNodeSetEntity(oplog *MasterOplog, isLocked bo
 	entity.Status = types.StatusRevoked
 
 	entity.MustSave(true)
+
+	pm.PostEntityStatusEvent()
 }
 
 func (pm *ProtocolManager) revokeMyNodeCleanMyNodes(isLocked bool) {
@@ -167,7 +169,7 @@ func (pm *ProtocolManager) revokeMyNodeCleanMyNodes(isLocked bool) {
 
 		delete(pm.MyNodes, raftID)
 		delete(pm.MyNodeByNodeSignIDs, *nodeSignID)
-		node.Delete(true)
+		node.Delete(pm.db.myNodes, true)
 	}
 
 }
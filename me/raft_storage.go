@@ -42,14 +42,16 @@ type RaftStorage struct {
 	snapshot  pb.Snapshot
 
 	myID *types.PttID
+
+	db pttdb.Storage
 }
 
-func NewRaftStorage(isClean bool, myID *types.PttID) (*RaftStorage, error) {
+func NewRaftStorage(isClean bool, myID *types.PttID, db pttdb.Storage) (*RaftStorage, error) {
 	if isClean {
-		return NewRaftStorageWithClean(myID)
+		return NewRaftStorageWithClean(myID, db)
 	}
 
-	rs := &RaftStorage{myID: myID}
+	rs := &RaftStorage{myID: myID, db: db}
 
 	// firstIdx
 	iter, err := rs.GetIter(0)
@@ -125,13 +127,13 @@ func NewRaftStorage(isClean bool, myID *types.PttID) (*RaftStorage, error) {
 	return rs, nil
 }
 
-func NewRaftStorageWithClean(myID *types.PttID) (*RaftStorage, error) {
-	err := CleanRaftStorage(myID, nil, true)
+func NewRaftStorageWithClean(myID *types.PttID, db pttdb.Storage) (*RaftStorage, error) {
+	err := CleanRaftStorage(myID, db, nil, true)
 	if err != nil {
 		return nil, err
 	}
 
-	rs := &RaftStorage{myID: myID}
+	rs := &RaftStorage{myID: myID, db: db}
 
 	rs.SaveEntry(pb.Entry{}, false)
 	rs.firstIdx = 1
@@ -141,9 +143,9 @@ func NewRaftStorageWithClean(myID *types.PttID) (*RaftStorage, error) {
 	return rs, nil
 }
 
-func CleanRaftStorage(myID *types.PttID, rs *RaftStorage, isLocked bool) error {
+func CleanRaftStorage(myID *types.PttID, db pttdb.Storage, rs *RaftStorage, isLocked bool) error {
 	if rs == nil {
-		rs = &RaftStorage{myID: myID}
+		rs = &RaftStorage{myID: myID, db: db}
 	}
 
 	if !isLocked {
@@ -158,7 +160,7 @@ func CleanRaftStorage(myID *types.PttID, rs *RaftStorage, isLocked bool) error {
 
 	for iter.Next() {
 		key := iter.Key()
-		dbRaft.Delete(key)
+		rs.db.Delete(key)
 	}
 
 	return nil
@@ -183,7 +185,7 @@ func (rs *RaftStorage) SetHardState(st pb.HardState) error {
 		return err
 	}
 
-	err = dbRaft.Put(key, data)
+	err = rs.db.Put(key, data)
 	if err != nil {
 		return err
 	}
@@ -203,7 +205,7 @@ func (rs *RaftStorage) LoadHardState() (pb.HardState, error) {
 		return hs, err
 	}
 
-	data, err := dbRaft.Get(key)
+	data, err := rs.db.Get(key)
 	if err != nil {
 		return hs, err
 	}
@@ -269,7 +271,7 @@ func (rs *RaftStorage) Term(idx uint64) (uint64, error) {
 		return 0, err
 	}
 
-	val, err := dbRaft.Get(key)
+	val, err := rs.db.Get(key)
 	if err != nil {
 		return 0, err
 	}
@@ -324,7 +326,7 @@ func (rs *RaftStorage) ApplySnapshot(snap pb.Snapshot) error {
 		return err
 	}
 
-	err = dbRaft.Put(key, data)
+	err = rs.db.Put(key, data)
 	if err != nil {
 		return err
 	}
@@ -350,7 +352,7 @@ func (rs *RaftStorage) LoadSnapshot() (pb.Snapshot, error) {
 		return snapshot, err
 	}
 
-	data, err := dbRaft.Get(key)
+	data, err := rs.db.Get(key)
 	if err != nil {
 		return snapshot, err
 	}
@@ -416,7 +418,7 @@ func (rs *RaftStorage) Compact(idx uint64, isLocked bool) error {
 	}
 
 	for _, key := range toRemoveKeys {
-		dbRaft.Delete(key)
+		rs.db.Delete(key)
 	}
 
 	return nil
@@ -470,7 +472,7 @@ func (rs *RaftStorage) SaveEntry(ent pb.Entry, isLocked bool) error {
 		return err
 	}
 
-	err = dbRaft.Put(key, data)
+	err = rs.db.Put(key, data)
 	if err != nil {
 		return err
 	}
@@ -488,7 +490,7 @@ func (rs *RaftStorage) GetEntry(idx uint64) (pb.Entry, error) {
 	if err != nil {
 		return ent, err
 	}
-	data, err := dbRaft.Get(key)
+	data, err := rs.db.Get(key)
 	if err != nil {
 		return ent, err
 	}
@@ -549,7 +551,7 @@ func (rs *RaftStorage) GetIter(idx uint64) (iterator.Iterator, error) {
 		Start: startKey,
 		Limit: endKey,
 	}
-	iter := dbRaft.NewIteratorWithRange(r, pttdb.ListOrderNext)
+	iter := rs.db.NewIteratorWithRange(r, pttdb.ListOrderNext)
 	return iter, nil
 }
 
@@ -565,7 +567,7 @@ func (rs *RaftStorage) GetPrevIter(idx uint64) (iterator.Iterator, error) {
 		Limit: endKey,
 	}
 
-	iter := dbRaft.NewIteratorWithRange(r, pttdb.ListOrderPrev)
+	iter := rs.db.NewIteratorWithRange(r, pttdb.ListOrderPrev)
 
 	return iter, nil
 }
@@ -29,11 +29,13 @@ type ServiceProtocolManager struct {
 	MyInfo *MyInfo
 
 	myRouter pkgservice.MyRouter
+
+	db *meDB
 }
 
-func NewServiceProtocolManager(myID *types.PttID, router pkgservice.MyRouter, service pkgservice.Service) (*ServiceProtocolManager, error) {
+func NewServiceProtocolManager(myID *types.PttID, router pkgservice.MyRouter, service pkgservice.Service, db *meDB) (*ServiceProtocolManager, error) {
 
-	spm := &ServiceProtocolManager{myRouter: router, MyID: myID}
+	spm := &ServiceProtocolManager{myRouter: router, MyID: myID, db: db}
 	b, err := pkgservice.NewBaseServiceProtocolManager(router, service)
 	if err != nil {
 		return nil, err
@@ -68,5 +70,5 @@ func NewServiceProtocolManager(myID *types.PttID, router pkgservice.MyRouter, se
 }
 
 func (spm *ServiceProtocolManager) NewEmptyEntity() pkgservice.Entity {
-	return NewEmptyMyInfo()
+	return NewEmptyMyInfo(spm.db)
 }
@@ -0,0 +1,79 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import "sync"
+
+/*
+Sample is the metric-value collected at the scrape-time.
+*/
+type Sample struct {
+	Name   string
+	Labels []string // key-value pairs
+	Type   SampleType
+	Value  float64
+}
+
+func NewGaugeSample(name string, value float64, labels ...string) *Sample {
+	return &Sample{Name: name, Labels: labels, Type: SampleTypeGauge, Value: value}
+}
+
+func NewCounterSample(name string, value float64, labels ...string) *Sample {
+	return &Sample{Name: name, Labels: labels, Type: SampleTypeCounter, Value: value}
+}
+
+/*
+Collector collects the samples of the states (ex: peers, sync-lag) at the scrape-time.
+*/
+type Collector interface {
+	CollectMetrics() []*Sample
+}
+
+var (
+	lockCollectors sync.RWMutex
+	collectors     = make(map[string]Collector)
+)
+
+func RegisterCollector(name string, c Collector) {
+	lockCollectors.Lock()
+	defer lockCollectors.Unlock()
+
+	collectors[name] = c
+}
+
+func UnregisterCollector(name string) {
+	lockCollectors.Lock()
+	defer lockCollectors.Unlock()
+
+	delete(collectors, name)
+}
+
+func collectSamples() []*Sample {
+	lockCollectors.RLock()
+	cs := make([]Collector, 0, len(collectors))
+	for _, c := range collectors {
+		cs = append(cs, c)
+	}
+	lockCollectors.RUnlock()
+
+	samples := make([]*Sample, 0)
+	for _, c := range cs {
+		samples = append(samples, c.CollectMetrics()...)
+	}
+
+	return samples
+}
@@ -0,0 +1,152 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+type family struct {
+	sampleType SampleType
+	lines      []string
+}
+
+type families map[string]*family
+
+func (fs families) add(name string, t SampleType, labels string, value float64) {
+	fs.addWithSuffix(name, "", t, labels, value)
+}
+
+func (fs families) addWithSuffix(name string, suffix string, t SampleType, labels string, value float64) {
+	f, ok := fs[name]
+	if !ok {
+		f = &family{sampleType: t}
+		fs[name] = f
+	}
+
+	f.lines = append(f.lines, name+suffix+labels+" "+formatValue(value))
+}
+
+func (fs families) addSummary(name string, labels string, count int64, sum int64, ps []float64) {
+	for i, q := range Quantiles {
+		fs.add(name, SampleTypeSummary, appendLabels(labels, "quantile", formatValue(q)), ps[i])
+	}
+	fs.addWithSuffix(name, "_sum", SampleTypeSummary, labels, float64(sum))
+	fs.addWithSuffix(name, "_count", SampleTypeSummary, labels, float64(count))
+}
+
+/*
+WriteText writes the metrics in the registry and the samples from the collectors
+in the prometheus text exposition format.
+*/
+func WriteText(w io.Writer, r metrics.Registry) error {
+	fs := make(families)
+
+	r.Each(func(theName string, i interface{}) {
+		name, labels := splitName(theName)
+
+		switch m := i.(type) {
+		case metrics.Counter:
+			fs.add(name, SampleTypeCounter, labels, float64(m.Count()))
+		case metrics.Gauge:
+			fs.add(name, SampleTypeGauge, labels, float64(m.Value()))
+		case metrics.GaugeFloat64:
+			fs.add(name, SampleTypeGauge, labels, m.Value())
+		case metrics.Meter:
+			fs.add(name, SampleTypeCounter, labels, float64(m.Count()))
+		case metrics.Timer:
+			t := m.Snapshot()
+			fs.addSummary(name, labels, t.Count(), t.Sum(), t.Percentiles(Quantiles))
+		case metrics.Histogram:
+			h := m.Snapshot()
+			fs.addSummary(name, labels, h.Count(), h.Sum(), h.Percentiles(Quantiles))
+		}
+	})
+
+	for _, s := range collectSamples() {
+		labels := ""
+		if len(s.Labels) >= 2 {
+			var b strings.Builder
+			writeLabels(&b, s.Labels)
+			labels = b.String()
+		}
+		fs.add(sanitizeName(s.Name), s.Type, labels, s.Value)
+	}
+
+	names := make([]string, 0, len(fs))
+	for name := range fs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		f := fs[name]
+		sort.Strings(f.lines)
+
+		bw.WriteString("# TYPE " + name + " " + string(f.sampleType) + "\n")
+		for _, line := range f.lines {
+			bw.WriteString(line)
+			bw.WriteByte('\n')
+		}
+	}
+
+	return bw.Flush()
+}
+
+/*
+Handler serves the metrics in the default registry.
+*/
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentTypeText)
+
+		err := WriteText(w, metrics.DefaultRegistry)
+		if err != nil {
+			log.Warn("metrics.Handler: unable to write", "e", err)
+		}
+	})
+}
+
+func appendLabels(labels string, key string, val string) string {
+	var b strings.Builder
+	if labels == "" {
+		writeLabels(&b, []string{key, val})
+		return b.String()
+	}
+
+	b.WriteString(labels[:len(labels)-1])
+	b.WriteByte(',')
+	b.WriteString(sanitizeName(key))
+	b.WriteString(`="`)
+	b.WriteString(escapeLabelValue(val))
+	b.WriteString(`"}`)
+
+	return b.String()
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
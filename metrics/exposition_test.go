@@ -0,0 +1,83 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCollector struct{}
+
+func (c *testCollector) CollectMetrics() []*Sample {
+	return []*Sample{
+		NewGaugeSample("ptt_peers", 2, "type", "me"),
+		NewGaugeSample("ptt_peers", 1, "type", "random"),
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	r := metrics.NewRegistry()
+
+	c := metrics.NewCounterForced()
+	c.Inc(3)
+	r.Register(Name("ptt_msg_total", "dir", "in", "op", "12"), c)
+
+	c2 := metrics.NewCounterForced()
+	c2.Inc(5)
+	r.Register(Name("ptt_msg_total", "dir", "out", "op", "12"), c2)
+
+	m := metrics.NewMeterForced()
+	m.Mark(10)
+	r.Register("p2p/InboundTraffic", m)
+
+	g := metrics.NewGauge()
+	r.Register("pttdb/me/value", g)
+
+	RegisterCollector("test", &testCollector{})
+	defer UnregisterCollector("test")
+
+	buf := &bytes.Buffer{}
+	err := WriteText(buf, r)
+	assert.NoError(t, err)
+
+	expected := `# TYPE p2p_InboundTraffic counter
+p2p_InboundTraffic 10
+# TYPE ptt_msg_total counter
+ptt_msg_total{dir="in",op="12"} 3
+ptt_msg_total{dir="out",op="12"} 5
+# TYPE ptt_peers gauge
+ptt_peers{type="me"} 2
+ptt_peers{type="random"} 1
+# TYPE pttdb_me_value gauge
+pttdb_me_value 0
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestName(t *testing.T) {
+	assert.Equal(t, "ptt_peers", Name("ptt_peers"))
+	assert.Equal(t, `ptt_peers{type="me"}`, Name("ptt_peers", "type", "me"))
+	assert.Equal(t, `ptt_sync{entity="a\"b"}`, Name("ptt_sync", "entity", `a"b`))
+
+	name, labels := splitName(Name("ptt/sync-lag", "entity", "abc"))
+	assert.Equal(t, "ptt_sync_lag", name)
+	assert.Equal(t, `{entity="abc"}`, labels)
+}
@@ -0,0 +1,36 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package metrics
+
+const (
+	MetricsPath = "/metrics"
+
+	ContentTypeText = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// SampleType is the type of the metric-family in the text exposition format.
+type SampleType string
+
+const (
+	SampleTypeCounter SampleType = "counter"
+	SampleTypeGauge   SampleType = "gauge"
+	SampleTypeSummary SampleType = "summary"
+)
+
+var (
+	Quantiles = []float64{0.5, 0.75, 0.95, 0.99}
+)
@@ -0,0 +1,129 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package metrics exposes the metrics of the node in the prometheus text exposition format.
+
+The metrics are from the registry of go-ethereum/metrics (with the labels encoded in the name by Name),
+and the samples collected from the registered Collectors at the scrape-time.
+*/
+package metrics
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+/*
+Enable enables the metrics system.
+
+Enable needs to be called before the meters are created (before the services are constructed).
+*/
+func Enable() {
+	metrics.Enabled = true
+}
+
+func Enabled() bool {
+	return metrics.Enabled
+}
+
+/*
+Name encodes the labels (key-value pairs) into the metric-name.
+*/
+func Name(name string, labels ...string) string {
+	if len(labels) < 2 {
+		return name
+	}
+
+	var b strings.Builder
+	b.WriteString(name)
+	writeLabels(&b, labels)
+
+	return b.String()
+}
+
+/*
+GetOrRegisterCounter returns the counter of the name in the default registry.
+Returns the nil-counter without registering if the metrics system is disabled.
+*/
+func GetOrRegisterCounter(name string) metrics.Counter {
+	if !metrics.Enabled {
+		return metrics.NilCounter{}
+	}
+
+	return metrics.GetOrRegisterCounter(name, metrics.DefaultRegistry)
+}
+
+/*
+GetOrRegisterGauge returns the gauge of the name in the default registry.
+Returns the nil-gauge without registering if the metrics system is disabled.
+*/
+func GetOrRegisterGauge(name string) metrics.Gauge {
+	if !metrics.Enabled {
+		return metrics.NilGauge{}
+	}
+
+	return metrics.GetOrRegisterGauge(name, metrics.DefaultRegistry)
+}
+
+func writeLabels(b *strings.Builder, labels []string) {
+	b.WriteByte('{')
+	for i := 0; i+1 < len(labels); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(sanitizeName(labels[i]))
+		b.WriteString(`="`)
+		b.WriteString(escapeLabelValue(labels[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+}
+
+/*
+splitName splits the metric-name encoded by Name into the name and the label-string (including the braces).
+*/
+func splitName(name string) (string, string) {
+	idx := strings.IndexByte(name, '{')
+	if idx < 0 || !strings.HasSuffix(name, "}") {
+		return sanitizeName(name), ""
+	}
+
+	return sanitizeName(name[:idx]), name[idx:]
+}
+
+/*
+sanitizeName converts the name to match [a-zA-Z_:][a-zA-Z0-9_:]*.
+*/
+func sanitizeName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_', c == ':':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(val string) string {
+	return labelValueReplacer.Replace(val)
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/ailabstw/go-pttai-core/p2p"
 	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	pttrpc "github.com/ailabstw/go-pttai-core/rpc"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -227,6 +228,54 @@ func (api *PrivateAdminAPI) StopWS() (bool, error) {
 	return true, nil
 }
 
+// IssuedToken is the newly issued RPC api-token. The secret is shown only once.
+type IssuedToken struct {
+	Secret string        `json:"secret"`
+	Token  *pttrpc.Token `json:"token"`
+}
+
+// IssueToken issues an api-token for the HTTP/websocket RPC with the permissions
+// (namespaces, "*", or method patterns like "friend_get*").
+// The token-management is permitted only over IPC (see rpc.IPCOnlyMethods).
+func (api *PrivateAdminAPI) IssueToken(name string, permissions []string) (*IssuedToken, error) {
+	tokens, err := api.node.tokenStore()
+	if err != nil {
+		return nil, err
+	}
+
+	secret, token, err := tokens.Issue(name, permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IssuedToken{Secret: secret, Token: token}, nil
+}
+
+// RevokeToken revokes the api-token by id.
+func (api *PrivateAdminAPI) RevokeToken(id string) (bool, error) {
+	tokens, err := api.node.tokenStore()
+	if err != nil {
+		return false, err
+	}
+
+	err = tokens.Revoke(id)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ListTokens lists the api-tokens (without the secrets).
+func (api *PrivateAdminAPI) ListTokens() ([]*pttrpc.Token, error) {
+	tokens, err := api.node.tokenStore()
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens.List(), nil
+}
+
 // PublicAdminAPI is the collection of administrative API methods exposed over
 // both secure and unsecure RPC channels.
 type PublicAdminAPI struct {
@@ -0,0 +1,667 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"compress/gzip"
+	"crypto/ecdsa"
+	"encoding/json"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/key"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	ArchiveVersion uint32 = 2
+)
+
+// archive-entry
+const (
+	ArchiveEntryDB   uint8 = iota // start of the db in P
+	ArchiveEntryKV                // key-val (K, V) of the last db
+	ArchiveEntryFile              // file in P with the data in V
+	ArchiveEntrySign              // pubkey (K) and sig (V) of the archive, the last entry
+)
+
+var (
+	archiveSkipNames = map[string]bool{
+		"LOCK":              true,
+		DataDirNodeDatabase: true,
+	}
+
+	// the data-dir is restored to the staging-dir first, and renamed to the data-dir on success.
+	archiveStagingSuffix = ".importing"
+)
+
+/*
+Archive is the offline dump of the data-dir.
+
+The archive file is the gzip stream of the json header followed by the json entries:
+All the dbs (me, friend, account and service) are dumped as key-values,
+and the other files (ex: the key-files in me and the instance-dir)
+are dumped as they are. The entries are streamed in both exporting and importing,
+so the archive is never held in memory.
+
+The last entry is the signature by the node-key of the exporting node,
+signed with the keccak256 of the header and all the entries.
+
+Archive is the header of the archive file, with the paths and the signature
+filled in reading and importing.
+*/
+type Archive struct {
+	Version  uint32          `json:"V"`
+	CreateTS types.Timestamp `json:"CT"`
+
+	DBs   []string `json:"-"`
+	Files []string `json:"-"`
+
+	Pubkey []byte `json:"-"`
+	Sig    []byte `json:"-"`
+}
+
+type ArchiveEntry struct {
+	Type uint8  `json:"T"`
+	Path string `json:"P,omitempty"`
+	K    []byte `json:"K,omitempty"`
+	V    []byte `json:"V,omitempty"`
+}
+
+/*
+ExportData dumps the whole dataDir to the archive file.
+
+The dbs can not be opened by the other process,
+so the node is required to be stopped.
+*/
+func ExportData(dataDir string, filename string, key *ecdsa.PrivateKey) (err error) {
+	if dataDir == "" {
+		return ErrEphemeralDataDir
+	}
+
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(filename)
+		}
+	}()
+
+	archive := &Archive{
+		Version:  ArchiveVersion,
+		CreateTS: ts,
+	}
+
+	w := newArchiveWriter(f)
+	err = w.write(archive)
+	if err != nil {
+		return err
+	}
+
+	err = archive.writeDataDir(w, dataDir)
+	if err != nil {
+		return err
+	}
+
+	err = archive.sign(w, key)
+	if err != nil {
+		return err
+	}
+
+	err = w.close()
+	if err != nil {
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	log.Info("ExportData: done", "filename", filename, "DBs", len(archive.DBs), "Files", len(archive.Files))
+
+	return nil
+}
+
+type archiveWriter struct {
+	w    *gzip.Writer
+	hash hash.Hash
+}
+
+func newArchiveWriter(w io.Writer) *archiveWriter {
+	return &archiveWriter{
+		w:    gzip.NewWriter(w),
+		hash: sha3.NewLegacyKeccak256(),
+	}
+}
+
+func (w *archiveWriter) write(v interface{}) error {
+	marshaled, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	w.hash.Write(marshaled)
+
+	_, err = w.w.Write(append(marshaled, '\n'))
+	return err
+}
+
+func (w *archiveWriter) close() error {
+	return w.w.Close()
+}
+
+func (a *Archive) writeDataDir(w *archiveWriter, dataDir string) error {
+	return filepath.Walk(dataDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if archiveSkipNames[info.Name()] {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dataDir, path)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if relPath == "." || !isLDBDir(path) {
+				return nil
+			}
+
+			err = writeLDB(w, path, relPath)
+			if err != nil {
+				return err
+			}
+			a.DBs = append(a.DBs, relPath)
+			return filepath.SkipDir
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		err = w.write(&ArchiveEntry{Type: ArchiveEntryFile, Path: relPath, V: data})
+		if err != nil {
+			return err
+		}
+		a.Files = append(a.Files, relPath)
+
+		return nil
+	})
+}
+
+func isLDBDir(path string) bool {
+	_, err := os.Stat(filepath.Join(path, "CURRENT"))
+	return err == nil
+}
+
+func writeLDB(w *archiveWriter, path string, relPath string) error {
+	db, err := pttdb.NewLDBDatabase(filepath.Base(path), filepath.Dir(path), 0, 0)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	err = w.write(&ArchiveEntry{Type: ArchiveEntryDB, Path: relPath})
+	if err != nil {
+		return err
+	}
+
+	iter := db.NewIterator(pttdb.ListOrderNext)
+	defer iter.Release()
+
+	for iter.Next() {
+		err = w.write(&ArchiveEntry{Type: ArchiveEntryKV, K: iter.Key(), V: iter.Value()})
+		if err != nil {
+			return err
+		}
+	}
+
+	return iter.Error()
+}
+
+func (a *Archive) sign(w *archiveWriter, key *ecdsa.PrivateKey) error {
+	a.Pubkey = crypto.FromECDSAPub(&key.PublicKey)
+
+	sig, err := crypto.Sign(w.hash.Sum(nil), key)
+	if err != nil {
+		return err
+	}
+	a.Sig = sig
+
+	marshaled, err := json.Marshal(&ArchiveEntry{Type: ArchiveEntrySign, K: a.Pubkey, V: a.Sig})
+	if err != nil {
+		return err
+	}
+
+	_, err = w.w.Write(append(marshaled, '\n'))
+	return err
+}
+
+/*
+ImportData restores the archive file to the dataDir.
+The archive is required to be signed by signer.
+
+The dataDir is expected to be fresh (not exists or empty). The entities are loaded from the dbs
+and re-registered to the router in the next node-start.
+*/
+func ImportData(filename string, dataDir string, signer *ecdsa.PublicKey) (*Archive, error) {
+	if signer == nil {
+		return nil, ErrInvalidArchiveSig
+	}
+
+	return importData(filename, dataDir, func(pubkey []byte, stagingDir string) error {
+		if !reflect.DeepEqual(pubkey, crypto.FromECDSAPub(signer)) {
+			return ErrInvalidArchiveSig
+		}
+		return nil
+	})
+}
+
+/*
+checkSignerFunc checks the pubkey of the verified signature with the restored staging-dir.
+*/
+type checkSignerFunc func(pubkey []byte, stagingDir string) error
+
+/*
+importData restores the archive file to the staging-dir, and renames the staging-dir
+to dataDir only if the whole archive is restored and the signer is checked.
+*/
+func importData(filename string, dataDir string, checkSigner checkSignerFunc) (*Archive, error) {
+	if dataDir == "" {
+		return nil, ErrEphemeralDataDir
+	}
+
+	err := checkFreshDataDir(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	stagingDir := filepath.Clean(dataDir) + archiveStagingSuffix
+	err = os.RemoveAll(stagingDir)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(stagingDir)
+
+	err = os.MkdirAll(stagingDir, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &archiveRestorer{dataDir: stagingDir}
+	archive, err := readArchive(filename, r.restore)
+	r.closeDB()
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkSigner(archive.Pubkey, stagingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// the empty data-dir is replaced by the staging-dir.
+	err = os.Remove(dataDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	err = os.Rename(stagingDir, dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("ImportData: done", "dataDir", dataDir, "DBs", len(archive.DBs), "Files", len(archive.Files))
+
+	return archive, nil
+}
+
+func checkFreshDataDir(dataDir string) error {
+	f, err := os.Open(dataDir)
+	if os.IsNotExist(err) {
+		return os.MkdirAll(filepath.Dir(filepath.Clean(dataDir)), 0700)
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Readdirnames(1)
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return ErrDataDirNotFresh
+}
+
+func checkArchivePath(relPath string) error {
+	cleaned := filepath.Clean(relPath)
+	if relPath == "" || cleaned == "." || filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return ErrInvalidArchive
+	}
+
+	return nil
+}
+
+/*
+archiveRestorer restores the entries to dataDir with the db of the last db-entry opened.
+*/
+type archiveRestorer struct {
+	dataDir string
+
+	db    *pttdb.LDBDatabase
+	batch pttdb.Batch
+}
+
+func (r *archiveRestorer) restore(entry *ArchiveEntry) error {
+	switch entry.Type {
+	case ArchiveEntryDB:
+		err := r.closeDB()
+		if err != nil {
+			return err
+		}
+		return r.openDB(filepath.Join(r.dataDir, entry.Path))
+	case ArchiveEntryKV:
+		if r.batch == nil {
+			return ErrInvalidArchive
+		}
+		err := r.batch.Put(entry.K, entry.V)
+		if err != nil {
+			return err
+		}
+		if r.batch.ValueSize() < pttdb.IdealBatchSize {
+			return nil
+		}
+		err = r.batch.Write()
+		if err != nil {
+			return err
+		}
+		r.batch.Reset()
+	case ArchiveEntryFile:
+		err := r.closeDB()
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(r.dataDir, entry.Path)
+		err = os.MkdirAll(filepath.Dir(path), 0700)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(path, entry.V, 0600)
+	case ArchiveEntrySign:
+		return r.closeDB()
+	}
+
+	return nil
+}
+
+func (r *archiveRestorer) openDB(path string) error {
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+
+	r.db, err = pttdb.NewLDBDatabase(filepath.Base(path), filepath.Dir(path), 0, 0)
+	if err != nil {
+		return err
+	}
+	r.batch = r.db.NewBatch()
+
+	return nil
+}
+
+func (r *archiveRestorer) closeDB() error {
+	if r.db == nil {
+		return nil
+	}
+
+	err := r.batch.Write()
+	r.db.Close()
+
+	r.db = nil
+	r.batch = nil
+
+	return err
+}
+
+/*
+ReadArchive reads the archive file and verifies the version and the signature.
+The signer is not checked, and is returned as Pubkey of the archive.
+*/
+func ReadArchive(filename string) (*Archive, error) {
+	return readArchive(filename, nil)
+}
+
+/*
+readArchive reads the entries of the archive file one by one, and handles the entry with onEntry.
+The entries handled are not verified until the signature entry, so the result of onEntry is
+required to be dropped if readArchive fails.
+*/
+func readArchive(filename string, onEntry func(entry *ArchiveEntry) error) (*Archive, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, ErrInvalidArchive
+	}
+	defer gr.Close()
+
+	r := &archiveReader{
+		dec:  json.NewDecoder(gr),
+		hash: sha3.NewLegacyKeccak256(),
+	}
+
+	archive := &Archive{}
+	raw, err := r.read(archive)
+	if err != nil {
+		return nil, err
+	}
+	r.hash.Write(raw)
+
+	if archive.Version != ArchiveVersion {
+		return nil, ErrInvalidArchiveVersion
+	}
+
+	paths := make(map[string]bool)
+	for {
+		entry := &ArchiveEntry{}
+		raw, err = r.read(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		switch entry.Type {
+		case ArchiveEntryDB, ArchiveEntryFile:
+			err = checkArchivePath(entry.Path)
+			if err != nil {
+				return nil, err
+			}
+			if paths[entry.Path] {
+				return nil, ErrInvalidArchive
+			}
+			paths[entry.Path] = true
+
+			if entry.Type == ArchiveEntryDB {
+				archive.DBs = append(archive.DBs, entry.Path)
+			} else {
+				archive.Files = append(archive.Files, entry.Path)
+			}
+		case ArchiveEntryKV:
+		case ArchiveEntrySign:
+			return archive, r.verify(archive, entry, onEntry)
+		default:
+			return nil, ErrInvalidArchive
+		}
+		r.hash.Write(raw)
+
+		if onEntry == nil {
+			continue
+		}
+		err = onEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+type archiveReader struct {
+	dec  *json.Decoder
+	hash hash.Hash
+}
+
+func (r *archiveReader) read(v interface{}) (json.RawMessage, error) {
+	var raw json.RawMessage
+	err := r.dec.Decode(&raw)
+	if err != nil {
+		return nil, ErrInvalidArchive
+	}
+
+	err = json.Unmarshal(raw, v)
+	if err != nil {
+		return nil, ErrInvalidArchive
+	}
+
+	return raw, nil
+}
+
+/*
+verify verifies the signature entry, which is required to be the last entry.
+*/
+func (r *archiveReader) verify(archive *Archive, entry *ArchiveEntry, onEntry func(entry *ArchiveEntry) error) error {
+	var extra json.RawMessage
+	if r.dec.Decode(&extra) != io.EOF {
+		return ErrInvalidArchive
+	}
+
+	if len(entry.V) < 64 || len(entry.K) == 0 {
+		return ErrInvalidArchiveSig
+	}
+
+	if !crypto.VerifySignature(entry.K, r.hash.Sum(nil), entry.V[:64]) {
+		return ErrInvalidArchiveSig
+	}
+
+	archive.Pubkey = entry.K
+	archive.Sig = entry.V
+
+	if onEntry == nil {
+		return nil
+	}
+
+	return onEntry(entry)
+}
+
+/*
+ExportData dumps the data-dir of the stopped node to the archive file.
+The archive is signed by the existing node-key of the instance,
+and the keystore is required to be unlocked with KeyPassphrase.
+*/
+func (n *Node) ExportData(filename string) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.server != nil {
+		return ErrNodeRunning
+	}
+
+	if n.Config.DataDir == "" {
+		return ErrEphemeralDataDir
+	}
+
+	keyfile := n.Config.ResolvePath(DataDirPrivateKey)
+	nodeKey, _, err := key.LoadKeyFile(keyfile, n.Config.KeyPassphrase)
+	if err == key.ErrKeyLocked || err == key.ErrInvalidPassphrase {
+		return err
+	}
+	if err != nil {
+		return ErrNodeKeyNotFound
+	}
+
+	return ExportData(n.Config.DataDir, filename, nodeKey)
+}
+
+/*
+ImportData restores the archive file to the data-dir of the stopped node.
+The entities are re-registered to the router when the node starts.
+
+The archive is required to be signed by signer, or by the node-key in the archive if signer is nil.
+The node-key in the archive is loaded with KeyPassphrase.
+*/
+func (n *Node) ImportData(filename string, signer *ecdsa.PublicKey) (*Archive, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.server != nil {
+		return nil, ErrNodeRunning
+	}
+
+	if signer != nil {
+		return ImportData(filename, n.Config.DataDir, signer)
+	}
+
+	return importData(filename, n.Config.DataDir, func(pubkey []byte, stagingDir string) error {
+		keyfile := filepath.Join(stagingDir, n.Config.name(), DataDirPrivateKey)
+		nodeKey, _, err := key.LoadKeyFile(keyfile, n.Config.KeyPassphrase)
+		if err == key.ErrKeyLocked || err == key.ErrInvalidPassphrase {
+			return err
+		}
+		if err != nil {
+			return ErrNodeKeyNotFound
+		}
+
+		if !reflect.DeepEqual(pubkey, crypto.FromECDSAPub(&nodeKey.PublicKey)) {
+			return ErrInvalidArchiveSig
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,268 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/ecdsa"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ailabstw/go-pttai-core/key"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	tArchiveKVs = []*pttdb.KeyVal{
+		&pttdb.KeyVal{K: []byte("k1"), V: []byte("v1")},
+		&pttdb.KeyVal{K: []byte("k2"), V: []byte("v2")},
+	}
+)
+
+func tPrepareDataDir(t *testing.T, dataDir string, passphrase string) *Config {
+	cfg := &Config{Name: "gptt", DataDir: dataDir, KeyPassphrase: passphrase}
+	cfg.NodeKey()
+
+	db, err := pttdb.NewLDBDatabase("ptt", filepath.Join(dataDir, "service"), 0, 0)
+	assert.NoError(t, err)
+	for _, kv := range tArchiveKVs {
+		assert.NoError(t, db.Put(kv.K, kv.V))
+	}
+	db.Close()
+
+	// skipped
+	nodeDB, err := pttdb.NewLDBDatabase(DataDirNodeDatabase, cfg.instanceDir(), 0, 0)
+	assert.NoError(t, err)
+	nodeDB.Close()
+
+	return cfg
+}
+
+// tReadArchiveEntries reads the header and the entries (with the sign-entry) without verifying.
+func tReadArchiveEntries(t *testing.T, filename string) (*Archive, []*ArchiveEntry) {
+	f, err := os.Open(filename)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	r, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+
+	archive := &Archive{}
+	assert.True(t, scanner.Scan())
+	assert.NoError(t, json.Unmarshal(scanner.Bytes(), archive))
+
+	entries := make([]*ArchiveEntry, 0)
+	for scanner.Scan() {
+		entry := &ArchiveEntry{}
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), entry))
+		entries = append(entries, entry)
+	}
+	assert.NoError(t, scanner.Err())
+
+	return archive, entries
+}
+
+// tWriteArchive writes the header and the entries, and signs with key if key is not nil.
+func tWriteArchive(t *testing.T, filename string, archive *Archive, entries []*ArchiveEntry, key *ecdsa.PrivateKey) {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	w := newArchiveWriter(f)
+	assert.NoError(t, w.write(archive))
+	for _, entry := range entries {
+		assert.NoError(t, w.write(entry))
+	}
+	if key != nil {
+		assert.NoError(t, archive.sign(w, key))
+	}
+	assert.NoError(t, w.close())
+}
+
+func tAssertNotImported(t *testing.T, dataDir string) {
+	_, err := os.Stat(dataDir)
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = os.Stat(dataDir + archiveStagingSuffix)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestArchive_ExportImport(t *testing.T) {
+	key.ScryptN = 1 << 4
+	defer func() { key.ScryptN = 1 << 18 }()
+
+	dir, err := ioutil.TempDir("", "test-archive")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	srcDir := filepath.Join(dir, "src")
+	dstDir := filepath.Join(dir, "dst")
+	filename := filepath.Join(dir, "archive.gz")
+
+	cfg := tPrepareDataDir(t, srcDir, "test-passphrase")
+	nodeKey := cfg.NodeKey()
+
+	// locked keystore
+	n := &Node{Config: &Config{Name: cfg.Name, DataDir: srcDir}}
+	err = n.ExportData(filename)
+	assert.Equal(t, key.ErrKeyLocked, err)
+
+	n = &Node{Config: cfg}
+	err = n.ExportData(filename)
+	assert.NoError(t, err)
+
+	// the node-key in the archive is not able to be loaded.
+	n2 := &Node{Config: &Config{Name: cfg.Name, DataDir: dstDir, KeyPassphrase: "test-passphrase2"}}
+	_, err = n2.ImportData(filename, nil)
+	assert.Equal(t, key.ErrInvalidPassphrase, err)
+	tAssertNotImported(t, dstDir)
+
+	// import with the node-key in the archive
+	n2.Config.KeyPassphrase = "test-passphrase"
+	archive, err := n2.ImportData(filename, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.FromECDSAPub(&nodeKey.PublicKey), archive.Pubkey)
+	assert.Equal(t, 1, len(archive.DBs))
+
+	_, err = os.Stat(dstDir + archiveStagingSuffix)
+	assert.True(t, os.IsNotExist(err))
+
+	// keys
+	nodeKey2, err := n2.Config.LoadKey(n2.Config.ResolvePath(DataDirPrivateKey))
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.FromECDSA(nodeKey), crypto.FromECDSA(nodeKey2))
+
+	// dbs
+	db, err := pttdb.NewLDBDatabase("ptt", filepath.Join(dstDir, "service"), 0, 0)
+	assert.NoError(t, err)
+	for _, kv := range tArchiveKVs {
+		val, err := db.Get(kv.K)
+		assert.NoError(t, err)
+		assert.Equal(t, kv.V, val)
+	}
+	db.Close()
+
+	_, err = os.Stat(filepath.Join(n2.Config.instanceDir(), DataDirNodeDatabase))
+	assert.True(t, os.IsNotExist(err))
+
+	// import over the existing data-dir
+	_, err = n2.ImportData(filename, nil)
+	assert.Equal(t, ErrDataDirNotFresh, err)
+
+	// import with the caller-supplied signer
+	otherKey, _ := crypto.GenerateKey()
+	_, err = ImportData(filename, filepath.Join(dir, "dst-other"), &otherKey.PublicKey)
+	assert.Equal(t, ErrInvalidArchiveSig, err)
+	tAssertNotImported(t, filepath.Join(dir, "dst-other"))
+
+	// import to the empty data-dir
+	emptyDir := filepath.Join(dir, "dst-empty")
+	assert.NoError(t, os.MkdirAll(emptyDir, 0700))
+	_, err = ImportData(filename, emptyDir, &nodeKey.PublicKey)
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(emptyDir, "service", "ptt"))
+	assert.NoError(t, err)
+}
+
+func TestArchive_Invalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-archive")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	srcDir := filepath.Join(dir, "src")
+	filename := filepath.Join(dir, "archive.gz")
+
+	cfg := tPrepareDataDir(t, srcDir, "")
+	nodeKey := cfg.NodeKey()
+	signer := &nodeKey.PublicKey
+
+	err = ExportData(srcDir, filename, nodeKey)
+	assert.NoError(t, err)
+
+	content, err := ioutil.ReadFile(filename)
+	assert.NoError(t, err)
+
+	// truncated
+	truncatedFilename := filepath.Join(dir, "truncated.gz")
+	assert.NoError(t, ioutil.WriteFile(truncatedFilename, content[:len(content)/2], 0600))
+	_, err = ImportData(truncatedFilename, filepath.Join(dir, "dst-truncated"), signer)
+	assert.Equal(t, ErrInvalidArchive, err)
+	tAssertNotImported(t, filepath.Join(dir, "dst-truncated"))
+
+	// corrupted
+	corruptedFilename := filepath.Join(dir, "corrupted.gz")
+	assert.NoError(t, ioutil.WriteFile(corruptedFilename, []byte("not an archive"), 0600))
+	_, err = ImportData(corruptedFilename, filepath.Join(dir, "dst-corrupted"), signer)
+	assert.Equal(t, ErrInvalidArchive, err)
+
+	// modified
+	archive, entries := tReadArchiveEntries(t, filename)
+	assert.Equal(t, ArchiveEntrySign, entries[len(entries)-1].Type)
+
+	modifiedFilename := filepath.Join(dir, "modified.gz")
+	for _, entry := range entries {
+		if entry.Type == ArchiveEntryKV {
+			entry.V = []byte("modified")
+			break
+		}
+	}
+	tWriteArchive(t, modifiedFilename, archive, entries, nil)
+	_, err = ImportData(modifiedFilename, filepath.Join(dir, "dst-modified"), signer)
+	assert.Equal(t, ErrInvalidArchiveSig, err)
+	tAssertNotImported(t, filepath.Join(dir, "dst-modified"))
+
+	// without the sign-entry
+	entries = entries[:len(entries)-1]
+	tWriteArchive(t, modifiedFilename, archive, entries, nil)
+	_, err = ImportData(modifiedFilename, filepath.Join(dir, "dst-unsigned"), signer)
+	assert.Equal(t, ErrInvalidArchive, err)
+
+	// signed by the other key
+	otherKey, _ := crypto.GenerateKey()
+	tWriteArchive(t, modifiedFilename, archive, entries, otherKey)
+	readArchive, err := ReadArchive(modifiedFilename)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.FromECDSAPub(&otherKey.PublicKey), readArchive.Pubkey)
+	_, err = ImportData(modifiedFilename, filepath.Join(dir, "dst-other"), signer)
+	assert.Equal(t, ErrInvalidArchiveSig, err)
+	tAssertNotImported(t, filepath.Join(dir, "dst-other"))
+
+	// version
+	archive.Version = ArchiveVersion + 1
+	tWriteArchive(t, modifiedFilename, archive, entries, nodeKey)
+	_, err = ImportData(modifiedFilename, filepath.Join(dir, "dst-version"), signer)
+	assert.Equal(t, ErrInvalidArchiveVersion, err)
+
+	// path out of the data-dir
+	archive.Version = ArchiveVersion
+	entries = append(entries, &ArchiveEntry{Type: ArchiveEntryFile, Path: "../outside", V: []byte("outside")})
+	tWriteArchive(t, modifiedFilename, archive, entries, nodeKey)
+	_, err = ImportData(modifiedFilename, filepath.Join(dir, "dst-path"), signer)
+	assert.Equal(t, ErrInvalidArchive, err)
+	_, err = os.Stat(filepath.Join(dir, "outside"))
+	assert.True(t, os.IsNotExist(err))
+	tAssertNotImported(t, filepath.Join(dir, "dst-path"))
+}
@@ -42,11 +42,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ailabstw/go-pttai-core/key"
 	"github.com/ailabstw/go-pttai-core/log"
 	"github.com/ailabstw/go-pttai-core/p2p"
 	"github.com/ailabstw/go-pttai-core/p2p/discover"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
 )
 
 type Config struct {
@@ -76,6 +76,10 @@ type Config struct {
 	// is created by New and destroyed when the node is stopped.
 	KeyStoreDir string `toml:",omitempty"`
 
+	// KeyPassphrase, if set, is used to encrypt the node key as the keystore.
+	// The existing plaintext node key is migrated to the keystore when loaded.
+	KeyPassphrase string `toml:"-"`
+
 	// IPCPath is the requested location to place the IPC endpoint. If the path is
 	// a simple file name, it is placed inside the data directory (or on the root
 	// pipe path on Windows), whereas if it's a resolvable path name (absolute or
@@ -138,6 +142,31 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// RPCAuth requires the HTTP and websocket RPC requests to carry an api-token
+	// (bearer authorization header or token query parameter). The methods are
+	// checked against the permissions of the token, and the calls of the private
+	// APIs are written to the audit log. The tokens are issued and revoked through
+	// the admin APIs over IPC.
+	RPCAuth bool `toml:",omitempty"`
+
+	// SignalHost is the host interface on which to start the embedded webrtc
+	// signal server. If this field is empty, no signal server will be started.
+	//
+	// If P2P.SignalServerURL is not set, the node connects to the embedded
+	// signal server.
+	SignalHost string `toml:",omitempty"`
+
+	// SignalPort is the TCP port number on which to start the embedded signal server.
+	SignalPort int `toml:",omitempty"`
+
+	// MetricsHost is the host interface on which to expose the metrics (/metrics)
+	// in the prometheus text exposition format. If this field is empty, the metrics
+	// system is not enabled and no metrics endpoint will be started.
+	MetricsHost string `toml:",omitempty"`
+
+	// MetricsPort is the TCP port number on which to expose the metrics.
+	MetricsPort int `toml:",omitempty"`
+
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:",omitempty"`
 
@@ -223,14 +252,24 @@ func (c *Config) NodeKey() *ecdsa.PrivateKey {
 
 	// retrieve key / postfix from file
 	keyfile := c.ResolvePath(DataDirPrivateKey)
-	key, err := discover.LoadECDSA(keyfile)
+	nodeKey, isKeystore, err := key.LoadKeyFile(keyfile, c.KeyPassphrase)
+	if isKeystore && err != nil {
+		// do not overwrite the keystore unable to be decrypted.
+		log.Crit(fmt.Sprintf("Failed to decrypt node key: %v", err))
+	}
 	if err == nil {
-		return key
+		// migrate the plaintext node key to the keystore.
+		if !isKeystore && c.KeyPassphrase != "" {
+			if err := c.SaveKey(keyfile, nodeKey); err != nil {
+				log.Error(fmt.Sprintf("Failed to migrate node key: %v", err))
+			}
+		}
+		return nodeKey
 	}
 
 	log.Warn(fmt.Sprintf("Failed to load key: %v. create a new one.", err))
 	// No persistent key found, generate and store a new one.
-	key, err = discover.GenerateNodeKey()
+	nodeKey, err = discover.GenerateNodeKey()
 	if err != nil {
 		log.Crit(fmt.Sprintf("Failed to generate node key: %v", err))
 	}
@@ -238,27 +277,27 @@ func (c *Config) NodeKey() *ecdsa.PrivateKey {
 	instanceDir := filepath.Join(c.DataDir, c.name())
 	if err := os.MkdirAll(instanceDir, 0700); err != nil {
 		log.Error(fmt.Sprintf("Failed to persist node key: %v", err))
-		return key
+		return nodeKey
 	}
 
 	keyfile = filepath.Join(instanceDir, DataDirPrivateKey)
-	if err := c.SaveKey(keyfile, key); err != nil {
+	if err := c.SaveKey(keyfile, nodeKey); err != nil {
 		log.Error(fmt.Sprintf("Failed to persist node key: %v", err))
 	}
-	return key
+	return nodeKey
 }
 
-func (c *Config) SaveKey(filename string, key *ecdsa.PrivateKey) error {
-	return crypto.SaveECDSA(filename, key)
+func (c *Config) SaveKey(filename string, nodeKey *ecdsa.PrivateKey) error {
+	return key.SaveKeyFile(filename, nodeKey, c.KeyPassphrase)
 }
 
 func (c *Config) LoadKey(filename string) (*ecdsa.PrivateKey, error) {
-	key, err := crypto.LoadECDSA(filename)
+	nodeKey, _, err := key.LoadKeyFile(filename, c.KeyPassphrase)
 	if err != nil {
 		return nil, err
 	}
 
-	return key, nil
+	return nodeKey, nil
 }
 
 func (c *Config) RevokeKeyPath() error {
@@ -377,3 +416,21 @@ func DefaultWSEndpoint() string {
 	config := &Config{WSHost: DefaultWSHost, WSPort: DefaultWSPort}
 	return config.WSEndpoint()
 }
+
+// SignalEndpoint resolves the endpoint of the embedded signal server based on
+// the configured host interface and port parameters.
+func (c *Config) SignalEndpoint() string {
+	if c.SignalHost == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", c.SignalHost, c.SignalPort)
+}
+
+// MetricsEndpoint resolves the endpoint of the metrics based on the configured
+// host interface and port parameters.
+func (c *Config) MetricsEndpoint() string {
+	if c.MetricsHost == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", c.MetricsHost, c.MetricsPort)
+}
@@ -31,6 +31,13 @@ var (
 
 	ErrNodeRestart = errors.New("node restart")
 
+	ErrEphemeralDataDir      = errors.New("ephemeral datadir")
+	ErrNodeKeyNotFound       = errors.New("node key not found")
+	ErrDataDirNotFresh       = errors.New("datadir is not fresh")
+	ErrInvalidArchive        = errors.New("invalid archive")
+	ErrInvalidArchiveVersion = errors.New("invalid archive version")
+	ErrInvalidArchiveSig     = errors.New("invalid archive signature")
+
 	dataDirInUseErrnos = map[uint]bool{11: true, 32: true, 35: true}
 )
 
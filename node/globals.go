@@ -38,12 +38,20 @@ const (
 	DataDirStaticNodes     = "static-nodes.json"  // Path within the datadir to the static node list
 	DataDirTrustedNodes    = "trusted-nodes.json" // Path within the datadir to the trusted node list
 	DataDirNodeDatabase    = "nodes"              // Path within the datadir to store the node infos
+	DataDirRPCTokens       = "rpc-tokens.json"    // Path within the datadir to the rpc api-tokens
+	DataDirRPCAudit        = "rpc-audit.log"      // Path within the datadir to the audit-log of the privileged rpc calls
 
 	DefaultHTTPHost = ""    // Default host interface for the HTTP RPC server
 	DefaultHTTPPort = 14779 // Default TCP port for the HTTP RPC server
 	DefaultWSHost   = ""    // Default host interface for the websocket RPC server
 	DefaultWSPort   = 15779 // Default TCP port for the websocket RPC server
 
+	DefaultSignalHost = ""   // Default host interface for the embedded signal server
+	DefaultSignalPort = 9489 // Default TCP port for the embedded signal server
+
+	DefaultMetricsHost = ""    // Default host interface for the metrics endpoint
+	DefaultMetricsPort = 16779 // Default TCP port for the metrics endpoint
+
 	DefaultNetworkID = Devnet
 )
 
@@ -55,8 +63,10 @@ var (
 		HTTPPort:         DefaultHTTPPort,
 		HTTPCors:         []string{"localhost"},
 		HTTPVirtualHosts: []string{"localhost"},
-		HTTPModules:      []string{"debug", "net", "admin", "ptt", "account", "content", "me", "friend"},
+		HTTPModules:      []string{"debug", "net", "admin", "ptt", "account", "content", "me", "friend", "group"},
 		WSPort:           DefaultWSPort,
+		SignalPort:       DefaultSignalPort,
+		MetricsPort:      DefaultMetricsPort,
 		P2P:              p2p.Config{},
 		NetworkID:        DefaultNetworkID,
 	}
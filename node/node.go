@@ -44,7 +44,9 @@ import (
 	"sync"
 
 	"github.com/ailabstw/go-pttai-core/log"
+	pttmetrics "github.com/ailabstw/go-pttai-core/metrics"
 	"github.com/ailabstw/go-pttai-core/p2p"
+	"github.com/ailabstw/go-pttai-core/p2p/signaling"
 	"github.com/ailabstw/go-pttai-core/rpc"
 	pkgservice "github.com/ailabstw/go-pttai-core/service"
 	"github.com/ethereum/go-ethereum/event"
@@ -82,6 +84,15 @@ type Node struct {
 	wsListener net.Listener // Websocket RPC listener socket to server API requests
 	wsHandler  *erpc.Server // Websocket RPC request handler to process the API requests
 
+	rpcTokens *rpc.TokenStore // API tokens of the HTTP/websocket RPC
+	rpcAuth   *rpc.Auth       // Token authentication of the HTTP/websocket RPC (nil = disabled)
+
+	signalEndpoint string            // Signal server endpoint (interface + port) to listen at (empty = signal server disabled)
+	signalServer   *signaling.Server // Embedded webrtc signal server
+
+	metricsEndpoint string       // Metrics endpoint (interface + port) to listen at (empty = metrics disabled)
+	metricsServer   *http.Server // Metrics HTTP server serving the metrics in the text exposition format
+
 	lock     sync.RWMutex
 	StopChan chan error
 
@@ -122,6 +133,11 @@ func New(cfg *Config) (*Node, error) {
 		cfg.Logger = log.New()
 	}
 
+	// The metrics system needs to be enabled before the services create the meters.
+	if cfg.MetricsEndpoint() != "" {
+		pttmetrics.Enable()
+	}
+
 	return &Node{
 		Config:       cfg,
 		routerFuncs:  []pkgservice.ServiceConstructor{},
@@ -130,6 +146,9 @@ func New(cfg *Config) (*Node, error) {
 		wsEndpoint:   cfg.WSEndpoint(),
 		eventmux:     new(event.TypeMux),
 		log:          cfg.Logger,
+
+		signalEndpoint:  cfg.SignalEndpoint(),
+		metricsEndpoint: cfg.MetricsEndpoint(),
 	}, nil
 }
 
@@ -174,6 +193,12 @@ func (n *Node) Start() error {
 	if n.serverConfig.NodeDatabase == "" {
 		n.serverConfig.NodeDatabase = n.Config.NodeDB()
 	}
+
+	// The embedded signal server is started before the p2p server
+	// so that the webrtc of the p2p server is able to connect to it.
+	if err := n.startSignal(n.signalEndpoint); err != nil {
+		return err
+	}
 	running := &p2p.Server{Config: n.serverConfig}
 	n.log.Info("Starting peer-to-peer node", "instance", n.serverConfig.Name)
 
@@ -192,11 +217,13 @@ func (n *Node) Start() error {
 		// n.log.Info("in serviceFunc-loop", "services", services, "service", service, "e", err)
 		if err != nil {
 			log.Error("in routerFunc-loop: unable to constructor", "e", err)
+			n.stopSignal()
 			return err
 		}
 		kind := reflect.TypeOf(router)
 		if _, exists := routers[kind]; exists {
 			log.Error("in routerFunc-loop: dup-resource", "kind", kind)
+			n.stopSignal()
 			return &DuplicateRouterError{Kind: kind}
 		}
 		routers[kind] = router
@@ -212,6 +239,7 @@ func (n *Node) Start() error {
 
 	// p2p-server start
 	if err := running.Start(); err != nil {
+		n.stopSignal()
 		return ConvertFileLockError(err)
 	}
 
@@ -228,6 +256,7 @@ func (n *Node) Start() error {
 				routers[kind].Stop()
 			}
 			running.Stop()
+			n.stopSignal()
 
 			return err
 		}
@@ -242,6 +271,21 @@ func (n *Node) Start() error {
 			service.Stop()
 		}
 		running.Stop()
+		n.stopSignal()
+		return err
+	}
+
+	if err := n.startMetrics(n.metricsEndpoint); err != nil {
+		n.log.Error("something went wrong with startMetrics", "e", err)
+		n.stopWS()
+		n.stopHTTP()
+		n.stopIPC()
+		n.stopInProc()
+		for _, service := range routers {
+			service.Stop()
+		}
+		running.Stop()
+		n.stopSignal()
 		return err
 	}
 
@@ -263,9 +307,11 @@ func (n *Node) Stop(isRevoke bool, isRestart bool) error {
 	}
 
 	// Terminate the API, services and the p2p server.
+	n.stopMetrics()
 	n.stopWS()
 	n.stopHTTP()
 	n.stopIPC()
+	n.stopRPCAuth()
 	n.rpcAPIs = nil
 	failure := &StopError{
 		Routers: make(map[reflect.Type]error),
@@ -288,6 +334,8 @@ func (n *Node) Stop(isRevoke bool, isRestart bool) error {
 	n.server.Stop()
 	log.Info("after stop server")
 
+	n.stopSignal()
+
 	// set nil
 	n.routers = nil
 	n.server = nil
@@ -394,19 +442,26 @@ func (n *Node) startRPC(services map[reflect.Type]pkgservice.NodeRouter) error {
 	}
 
 	// Start the various API endpoints, terminating all in case of errors
+	log.Debug("startRPC: to startRPCAuth")
+	if err := n.startRPCAuth(apis); err != nil {
+		return err
+	}
 	log.Debug("startRPC: to startInProc")
 	if err := n.startInProc(apis); err != nil {
+		n.stopRPCAuth()
 		return err
 	}
 	log.Debug("startRPC: to startIPC")
 	if err := n.startIPC(apis); err != nil {
 		n.stopInProc()
+		n.stopRPCAuth()
 		return err
 	}
 	log.Debug("startRPC: to startHTTP")
 	if err := n.startHTTP(n.httpEndpoint, apis, n.Config.HTTPModules, n.Config.HTTPCors, n.Config.HTTPVirtualHosts); err != nil {
 		n.stopIPC()
 		n.stopInProc()
+		n.stopRPCAuth()
 		return err
 	}
 	log.Debug("startRPC: to startWS")
@@ -414,6 +469,7 @@ func (n *Node) startRPC(services map[reflect.Type]pkgservice.NodeRouter) error {
 		n.stopHTTP()
 		n.stopIPC()
 		n.stopInProc()
+		n.stopRPCAuth()
 		return err
 	}
 	// All API endpoints started successfully
@@ -484,11 +540,11 @@ func (n *Node) startHTTP(endpoint string, apis []erpc.API, modules []string, cor
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, httpServer, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, erpc.DefaultHTTPTimeouts)
+	listener, handler, httpServer, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, erpc.DefaultHTTPTimeouts, n.rpcAuth)
 	if err != nil {
 		return err
 	}
-	n.log.Info("HTTP endpoint opened", "url", fmt.Sprintf("http://%s", endpoint), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","))
+	n.log.Info("HTTP endpoint opened", "url", fmt.Sprintf("http://%s", endpoint), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","), "auth", n.rpcAuth != nil)
 	// All listeners booted successfully
 	n.httpEndpoint = endpoint
 	n.httpListener = listener
@@ -524,11 +580,11 @@ func (n *Node) startWS(endpoint string, apis []erpc.API, modules []string, wsOri
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := erpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll)
+	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll, n.rpcAuth)
 	if err != nil {
 		return err
 	}
-	n.log.Info("WebSocket endpoint opened", "url", fmt.Sprintf("ws://%s", listener.Addr()))
+	n.log.Info("WebSocket endpoint opened", "url", fmt.Sprintf("ws://%s", listener.Addr()), "auth", n.rpcAuth != nil)
 	// All listeners booted successfully
 	n.wsEndpoint = endpoint
 	n.wsListener = listener
@@ -551,6 +607,132 @@ func (n *Node) stopWS() {
 	}
 }
 
+// startRPCAuth loads the api-tokens and initializes the token authentication
+// of the HTTP/websocket RPC if required.
+func (n *Node) startRPCAuth(apis []erpc.API) error {
+	if n.rpcTokens == nil {
+		tokens, err := rpc.NewTokenStore(n.Config.ResolvePath(DataDirRPCTokens))
+		if err != nil {
+			return err
+		}
+		n.rpcTokens = tokens
+	}
+
+	if !n.Config.RPCAuth {
+		return nil
+	}
+
+	auth, err := rpc.NewAuth(n.rpcTokens, apis, n.Config.ResolvePath(DataDirRPCAudit))
+	if err != nil {
+		return err
+	}
+	n.rpcAuth = auth
+
+	n.log.Info("RPC token authentication enabled", "tokens", len(n.rpcTokens.List()))
+
+	return nil
+}
+
+// tokenStore returns the api-tokens of the HTTP/websocket RPC.
+func (n *Node) tokenStore() (*rpc.TokenStore, error) {
+	if n.rpcTokens == nil {
+		return nil, ErrNodeStopped
+	}
+
+	return n.rpcTokens, nil
+}
+
+// stopRPCAuth closes the audit-log of the token authentication.
+func (n *Node) stopRPCAuth() {
+	if n.rpcAuth == nil {
+		return
+	}
+
+	n.rpcAuth.Close()
+	n.rpcAuth = nil
+}
+
+// startSignal initializes and starts the embedded webrtc signal server.
+// The p2p server connects to the embedded signal server if no signal server is configured.
+func (n *Node) startSignal(endpoint string) error {
+	// Short circuit if the signal server isn't being exposed
+	if endpoint == "" {
+		return nil
+	}
+
+	server := signaling.NewServer()
+	if err := server.Start(endpoint); err != nil {
+		return err
+	}
+
+	signalURL := server.URL()
+	n.log.Info("Signal server opened", "url", signalURL.String())
+
+	if n.serverConfig.SignalServerURL.Host == "" {
+		host, port, err := net.SplitHostPort(signalURL.Host)
+		if err != nil {
+			server.Stop()
+			return err
+		}
+		if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+			signalURL.Host = net.JoinHostPort("127.0.0.1", port)
+		}
+		n.serverConfig.SignalServerURL = signalURL
+	}
+
+	n.signalServer = server
+
+	return nil
+}
+
+// stopSignal terminates the embedded webrtc signal server.
+func (n *Node) stopSignal() {
+	if n.signalServer == nil {
+		return
+	}
+
+	n.signalServer.Stop()
+	n.signalServer = nil
+
+	n.log.Info("Signal server closed", "endpoint", n.signalEndpoint)
+}
+
+// startMetrics initializes and starts the metrics endpoint.
+func (n *Node) startMetrics(endpoint string) error {
+	// Short circuit if the metrics endpoint isn't being exposed
+	if endpoint == "" {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(pttmetrics.MetricsPath, pttmetrics.Handler())
+
+	n.metricsServer = &http.Server{Handler: mux}
+
+	go n.metricsServer.Serve(listener)
+
+	n.log.Info("Metrics endpoint opened", "url", fmt.Sprintf("http://%s%s", listener.Addr(), pttmetrics.MetricsPath))
+
+	return nil
+}
+
+// stopMetrics terminates the metrics endpoint.
+func (n *Node) stopMetrics() {
+	if n.metricsServer == nil {
+		return
+	}
+
+	n.metricsServer.Close()
+	n.metricsServer = nil
+
+	n.log.Info("Metrics endpoint closed", "endpoint", n.metricsEndpoint)
+}
+
 // apis returns the collection of RPC descriptors this node offers.
 func (n *Node) apis() []erpc.API {
 	return []erpc.API{
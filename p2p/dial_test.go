@@ -432,6 +432,31 @@ func TestDialStateNetRestrict(t *testing.T) {
 	})
 }
 
+// This test checks that the banned nodes are not dialed.
+func TestDialStateBanned(t *testing.T) {
+	wantStatic := []*discover.Node{
+		{ID: uintID(1)},
+		{ID: uintID(2)},
+		{ID: uintID(3)},
+	}
+	dialer := newDialState(wantStatic, nil, fakeTable{}, 0, nil, nil, nil)
+	dialer.isBanned = func(id discover.NodeID) bool {
+		return id == uintID(2)
+	}
+
+	runDialTest(t, dialtest{
+		init: dialer,
+		rounds: []round{
+			{
+				new: []task{
+					&dialTask{flags: staticDialedConn, dest: &discover.Node{ID: uintID(1)}},
+					&dialTask{flags: staticDialedConn, dest: &discover.Node{ID: uintID(3)}},
+				},
+			},
+		},
+	})
+}
+
 // This test checks that static dials are launched.
 func TestDialStateStaticDial(t *testing.T) {
 	wantStatic := []*discover.Node{
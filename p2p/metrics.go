@@ -24,11 +24,13 @@ import (
 	"github.com/ethereum/go-ethereum/metrics"
 )
 
+// The meters are forced because the metrics system may be enabled after the package init
+// (ex: by the node-config). newMeteredConn still short circuits if metrics are disabled.
 var (
-	ingressConnectMeter = metrics.NewRegisteredMeter("p2p/InboundConnects", nil)
-	ingressTrafficMeter = metrics.NewRegisteredMeter("p2p/InboundTraffic", nil)
-	egressConnectMeter  = metrics.NewRegisteredMeter("p2p/OutboundConnects", nil)
-	egressTrafficMeter  = metrics.NewRegisteredMeter("p2p/OutboundTraffic", nil)
+	ingressConnectMeter = metrics.NewRegisteredMeterForced("p2p/InboundConnects", nil)
+	ingressTrafficMeter = metrics.NewRegisteredMeterForced("p2p/InboundTraffic", nil)
+	egressConnectMeter  = metrics.NewRegisteredMeterForced("p2p/OutboundConnects", nil)
+	egressTrafficMeter  = metrics.NewRegisteredMeterForced("p2p/OutboundTraffic", nil)
 )
 
 // meteredConn is a wrapper around a net.Conn that meters both the
@@ -52,6 +52,11 @@ type Protocol struct {
 	// about a certain peer in the network. If an info retrieval function is set,
 	// but returns nil, it is assumed that the protocol handshake is still running.
 	PeerInfo func(id discover.NodeID) interface{}
+
+	// IsBanned is an optional helper method to tell whether a certain peer is
+	// temporarily banned by the protocol. Banned peers are neither dialed nor
+	// accepted.
+	IsBanned func(id discover.NodeID) bool
 }
 
 func (p Protocol) cap() Cap {
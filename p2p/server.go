@@ -838,6 +838,7 @@ func (srv *Server) Start() (err error) {
 
 	dynPeers := srv.maxDialedConns()
 	dialer := newDialState(srv.StaticNodes, srv.BootstrapNodes, srv.ntab, dynPeers, srv.NetRestrict, srv.p2pserver, srv.p2pctx)
+	dialer.isBanned = srv.isBanned
 
 	// handshake
 	srv.ourHandshake = &protoHandshake{Version: baseProtocolVersion, Name: srv.Name, ID: discover.PubkeyID(&srv.PrivateKey.PublicKey)}
@@ -1109,11 +1110,23 @@ func (srv *Server) encHandshakeChecks(peers map[discover.NodeID]*Peer, inboundCo
 		return DiscAlreadyConnected
 	case c.id == srv.Self().ID:
 		return DiscSelf
+	case srv.isBanned(c.id):
+		return DiscUselessPeer
 	default:
 		return nil
 	}
 }
 
+// isBanned returns whether any of the protocols bans the peer.
+func (srv *Server) isBanned(id discover.NodeID) bool {
+	for _, p := range srv.Protocols {
+		if p.IsBanned != nil && p.IsBanned(id) {
+			return true
+		}
+	}
+	return false
+}
+
 func (srv *Server) maxInboundConns() int {
 	return srv.MaxPeers - srv.maxDialedConns()
 }
@@ -0,0 +1,32 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package signaling
+
+import "time"
+
+const (
+	SignalPath = "/signal"
+
+	SizeChallenge    = 256
+	SizeSignature    = 65
+	SizeWriteChannel = 64
+
+	MaxSignalSize = 64 * 1024
+
+	TimeoutHandshake = 10 * time.Second
+	TimeoutWrite     = 10 * time.Second
+)
@@ -0,0 +1,93 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package signaling
+
+import (
+	"sync"
+	"time"
+
+	signalserver "github.com/ailabstw/pttai-signal-server"
+	"github.com/ethereum/go-ethereum/p2p/discv5"
+	"github.com/gorilla/websocket"
+)
+
+/*
+NodeConn is the authenticated websocket connection of a node.
+*/
+type NodeConn struct {
+	NodeID discv5.NodeID
+
+	wsConn *websocket.Conn
+
+	writeChan chan *signalserver.Signal
+	quitChan  chan struct{}
+
+	closeOnce sync.Once
+}
+
+func NewNodeConn(nodeID discv5.NodeID, wsConn *websocket.Conn) *NodeConn {
+	return &NodeConn{
+		NodeID: nodeID,
+
+		wsConn: wsConn,
+
+		writeChan: make(chan *signalserver.Signal, SizeWriteChannel),
+		quitChan:  make(chan struct{}),
+	}
+}
+
+func (nc *NodeConn) Close() {
+	nc.closeOnce.Do(func() {
+		close(nc.quitChan)
+		nc.wsConn.Close()
+	})
+}
+
+/*
+Send queues the signal to the node without blocking the sender.
+Returns false if the queue of the node is full or the node is closed.
+*/
+func (nc *NodeConn) Send(signal *signalserver.Signal) bool {
+	select {
+	case <-nc.quitChan:
+		return false
+	default:
+	}
+
+	select {
+	case nc.writeChan <- signal:
+		return true
+	default:
+		return false
+	}
+}
+
+func (nc *NodeConn) writeLoop() error {
+	for {
+		select {
+		case signal := <-nc.writeChan:
+			nc.wsConn.SetWriteDeadline(time.Now().Add(TimeoutWrite))
+			err := nc.wsConn.WriteJSON(signal)
+			if err != nil {
+				nc.Close()
+				return err
+			}
+		case <-nc.quitChan:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,307 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package signaling implements the in-process signal-server for bootstrapping the webrtc-connections.
+
+The server is compatible with the client of github.com/ailabstw/pttai-signal-server:
+the node is authenticated by signing the challenge with the node-key,
+and the signals are forwarded to the ToID only if the FromID is the authenticated node.
+*/
+package signaling
+
+import (
+	"crypto/rand"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/ailabstw/go-pttai-core/log"
+	signalserver "github.com/ailabstw/pttai-signal-server"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/discv5"
+	"github.com/gorilla/websocket"
+)
+
+type challenge struct {
+	Challenge []byte `json:"C"`
+}
+
+type challengeResponse struct {
+	NodeID discv5.NodeID
+
+	Signature []byte
+	Hash      [32]byte
+}
+
+type challengeAck struct {
+	NodeID discv5.NodeID
+}
+
+type Server struct {
+	upgrader websocket.Upgrader
+
+	lock      sync.RWMutex
+	nodeConns map[discv5.NodeID]*NodeConn
+
+	serverLock sync.Mutex
+	listener   net.Listener
+	httpServer *http.Server
+
+	wg sync.WaitGroup
+}
+
+func NewServer() *Server {
+	return &Server{
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		nodeConns: make(map[discv5.NodeID]*NodeConn),
+	}
+}
+
+/*
+Start listens on addr and serves the signals at SignalPath.
+*/
+func (s *Server) Start(addr string) error {
+	s.serverLock.Lock()
+	defer s.serverLock.Unlock()
+
+	if s.httpServer != nil {
+		return ErrServerRunning
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(SignalPath, s)
+
+	s.listener = listener
+	s.httpServer = &http.Server{Handler: mux}
+
+	go s.httpServer.Serve(listener)
+
+	log.Info("Signal server started", "addr", listener.Addr())
+
+	return nil
+}
+
+/*
+Stop closes the listener and all the connected nodes.
+*/
+func (s *Server) Stop() error {
+	s.serverLock.Lock()
+	defer s.serverLock.Unlock()
+
+	if s.httpServer == nil {
+		return ErrServerStopped
+	}
+
+	err := s.httpServer.Close()
+
+	s.lock.Lock()
+	for _, nc := range s.nodeConns {
+		nc.Close()
+	}
+	s.lock.Unlock()
+
+	s.wg.Wait()
+
+	log.Info("Signal server stopped", "addr", s.listener.Addr())
+
+	s.httpServer = nil
+	s.listener = nil
+
+	return err
+}
+
+/*
+URL returns the url for signalserver.NewClient.
+*/
+func (s *Server) URL() url.URL {
+	s.serverLock.Lock()
+	defer s.serverLock.Unlock()
+
+	return s.urlCore()
+}
+
+func (s *Server) urlCore() url.URL {
+	if s.listener == nil {
+		return url.URL{}
+	}
+
+	return url.URL{Scheme: "ws", Host: s.listener.Addr().String(), Path: SignalPath}
+}
+
+func (s *Server) NumNodes() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return len(s.nodeConns)
+}
+
+/*
+ServeHTTP upgrades the request to websocket, authenticates the node, and forwards the signals from the node.
+*/
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	wsConn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Warn("ServeHTTP: unable to upgrade", "remote", r.RemoteAddr, "e", err)
+		return
+	}
+	wsConn.SetReadLimit(MaxSignalSize)
+
+	nodeID, err := s.identifyNode(wsConn)
+	if err != nil {
+		log.Warn("ServeHTTP: unable to identify node", "remote", r.RemoteAddr, "e", err)
+		wsConn.Close()
+		return
+	}
+
+	nc := NewNodeConn(nodeID, wsConn)
+	defer s.removeNodeConn(nc)
+
+	wsConn.SetWriteDeadline(time.Now().Add(TimeoutWrite))
+	err = wsConn.WriteJSON(&challengeAck{NodeID: nodeID})
+	if err != nil {
+		return
+	}
+
+	s.addNodeConn(nc)
+
+	go nc.writeLoop()
+
+	err = s.readLoop(nc)
+	log.Debug("ServeHTTP: node disconnected", "nodeID", nodeID, "e", err)
+}
+
+func (s *Server) identifyNode(wsConn *websocket.Conn) (discv5.NodeID, error) {
+	c := make([]byte, SizeChallenge)
+	_, err := io.ReadFull(rand.Reader, c)
+	if err != nil {
+		return discv5.NodeID{}, err
+	}
+
+	deadline := time.Now().Add(TimeoutHandshake)
+	wsConn.SetReadDeadline(deadline)
+	wsConn.SetWriteDeadline(deadline)
+	defer wsConn.SetReadDeadline(time.Time{})
+
+	err = wsConn.WriteJSON(&challenge{Challenge: c})
+	if err != nil {
+		return discv5.NodeID{}, err
+	}
+
+	resp := &challengeResponse{}
+	err = wsConn.ReadJSON(resp)
+	if err != nil {
+		return discv5.NodeID{}, err
+	}
+
+	err = verifyChallengeResponse(c, resp)
+	if err != nil {
+		return discv5.NodeID{}, err
+	}
+
+	return resp.NodeID, nil
+}
+
+func verifyChallengeResponse(c []byte, resp *challengeResponse) error {
+	if resp.Hash != crypto.Keccak256Hash(c) {
+		return ErrInvalidHash
+	}
+
+	if len(resp.Signature) != SizeSignature {
+		return ErrInvalidSignature
+	}
+
+	pubKey, err := resp.NodeID.Pubkey()
+	if err != nil {
+		return err
+	}
+
+	if !crypto.VerifySignature(crypto.FromECDSAPub(pubKey), resp.Hash[:], resp.Signature[:SizeSignature-1]) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func (s *Server) readLoop(nc *NodeConn) error {
+	for {
+		signal := &signalserver.Signal{}
+		err := nc.wsConn.ReadJSON(signal)
+		if err != nil {
+			return err
+		}
+
+		if signal.FromID != nc.NodeID {
+			log.Warn("readLoop: invalid from-id", "nodeID", nc.NodeID, "fromID", signal.FromID)
+			return ErrInvalidFromID
+		}
+
+		s.dispatch(signal)
+	}
+}
+
+func (s *Server) dispatch(signal *signalserver.Signal) {
+	s.lock.RLock()
+	nc, ok := s.nodeConns[signal.ToID]
+	s.lock.RUnlock()
+
+	if !ok {
+		log.Debug("dispatch: node not connected", "fromID", signal.FromID, "toID", signal.ToID)
+		return
+	}
+
+	if !nc.Send(signal) {
+		log.Warn("dispatch: unable to send", "fromID", signal.FromID, "toID", signal.ToID)
+	}
+}
+
+/*
+addNodeConn registers the node-conn and replaces the original conn of the same node.
+*/
+func (s *Server) addNodeConn(nc *NodeConn) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if origConn, ok := s.nodeConns[nc.NodeID]; ok {
+		origConn.Close()
+	}
+
+	s.nodeConns[nc.NodeID] = nc
+}
+
+func (s *Server) removeNodeConn(nc *NodeConn) {
+	nc.Close()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if origConn, ok := s.nodeConns[nc.NodeID]; ok && origConn == nc {
+		delete(s.nodeConns, nc.NodeID)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package signaling
+
+import (
+	"crypto/ecdsa"
+	"testing"
+	"time"
+
+	signalserver "github.com/ailabstw/pttai-signal-server"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/discv5"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(t *testing.T, s *Server) (*signalserver.Client, discv5.NodeID, *ecdsa.PrivateKey) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	nodeID := discv5.PubkeyID(&key.PublicKey)
+
+	client, err := signalserver.NewClient(nodeID, key, s.URL())
+	assert.NoError(t, err)
+
+	return client, nodeID, key
+}
+
+func waitNumNodes(t *testing.T, s *Server, n int) {
+	for i := 0; i < 100 && s.NumNodes() != n; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, n, s.NumNodes())
+}
+
+func TestServer(t *testing.T) {
+	setupTest(t)
+	defer teardownTest(t)
+
+	s := NewServer()
+	err := s.Start("127.0.0.1:0")
+	assert.NoError(t, err)
+	err = s.Start("127.0.0.1:0")
+	assert.Equal(t, ErrServerRunning, err)
+
+	client1, nodeID1, _ := newTestClient(t, s)
+	client2, nodeID2, _ := newTestClient(t, s)
+	client3, nodeID3, _ := newTestClient(t, s)
+	defer client3.Close()
+	waitNumNodes(t, s, 3)
+
+	// signal
+	err = client1.Send(nodeID2, []byte("offer"), []byte("extra"))
+	assert.NoError(t, err)
+
+	signal, err := client2.Receive()
+	assert.NoError(t, err)
+	assert.Equal(t, nodeID1, signal.FromID)
+	assert.Equal(t, nodeID2, signal.ToID)
+	assert.Equal(t, []byte("offer"), signal.Msg)
+	assert.Equal(t, []byte("extra"), signal.Extra)
+
+	err = client2.Send(nodeID1, []byte("answer"), nil)
+	assert.NoError(t, err)
+
+	signal, err = client1.Receive()
+	assert.NoError(t, err)
+	assert.Equal(t, nodeID2, signal.FromID)
+	assert.Equal(t, []byte("answer"), signal.Msg)
+
+	// spoofed from-id: client1 is disconnected and the signal is not forwarded.
+	err = client1.Conn.WsConn.WriteJSON(&signalserver.Signal{FromID: nodeID3, ToID: nodeID2, Msg: []byte("spoofed")})
+	assert.NoError(t, err)
+	waitNumNodes(t, s, 2)
+
+	err = client3.Send(nodeID2, []byte("valid"), nil)
+	assert.NoError(t, err)
+
+	signal, err = client2.Receive()
+	assert.NoError(t, err)
+	assert.Equal(t, nodeID3, signal.FromID)
+	assert.Equal(t, []byte("valid"), signal.Msg)
+
+	// stop
+	err = s.Stop()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, s.NumNodes())
+
+	_, err = client2.Receive()
+	assert.Error(t, err)
+
+	err = s.Stop()
+	assert.Equal(t, ErrServerStopped, err)
+}
+
+func TestVerifyChallengeResponse(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	nodeID := discv5.PubkeyID(&key.PublicKey)
+
+	otherKey, _ := crypto.GenerateKey()
+
+	c := []byte("challenge")
+	hash := crypto.Keccak256Hash(c)
+	sig, _ := crypto.Sign(hash[:], key)
+	otherSig, _ := crypto.Sign(hash[:], otherKey)
+
+	tests := []struct {
+		name    string
+		resp    *challengeResponse
+		wantErr error
+	}{
+		{"valid", &challengeResponse{NodeID: nodeID, Signature: sig, Hash: hash}, nil},
+		{"invalid hash", &challengeResponse{NodeID: nodeID, Signature: sig, Hash: crypto.Keccak256Hash([]byte("other"))}, ErrInvalidHash},
+		{"short signature", &challengeResponse{NodeID: nodeID, Signature: sig[:10], Hash: hash}, ErrInvalidSignature},
+		{"other key", &challengeResponse{NodeID: nodeID, Signature: otherSig, Hash: hash}, ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyChallengeResponse(c, tt.resp)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
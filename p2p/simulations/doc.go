@@ -0,0 +1,33 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package simulations runs several ptt nodes in one process and wires them
+together through in-memory pipes (p2p/simulations/pipes), so that sync
+protocols can be exercised by deterministic go tests instead of the e2e
+binaries.
+
+A Network owns the nodes and the links between them. Test scripts create
+nodes, connect / disconnect them, split the network into partitions, heal
+it, restart nodes and finally wait until a per-node digest (ex: the merkle
+root or the list of object ids) is the same on every node.
+
+Each node is a node.Node with the same services as gptt (me / friend /
+account / group / content), reachable through the Stack of the node. The
+dbs are with pttdb.EngineMemory in the data dir of the node, so the state
+is kept through the restarts without touching disk.
+*/
+package simulations
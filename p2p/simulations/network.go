@@ -0,0 +1,494 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulations
+
+import (
+	"bytes"
+	"net"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/p2p"
+	"github.com/ailabstw/go-pttai-core/p2p/simulations/pipes"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+)
+
+/*
+PipeFunc creates the 2 ends of a connection between nodes.
+*/
+type PipeFunc func() (net.Conn, net.Conn, error)
+
+/*
+DigestFunc returns the state of the node to be compared in WaitConverged.
+*/
+type DigestFunc func(n *Node) ([]byte, error)
+
+type link struct {
+	a string
+	b string
+}
+
+func newLink(a, b string) link {
+	if a > b {
+		a, b = b, a
+	}
+	return link{a: a, b: b}
+}
+
+/*
+Network is the set of the simulated nodes and the links between them.
+
+The links are the intended topology. They are kept through partitions and
+restarts, Heal and Restart reconnect the links that are allowed again.
+*/
+type Network struct {
+	pipe    PipeFunc
+	dataDir string
+
+	lock      sync.Mutex
+	nodes     map[string]*Node
+	nodeList  []*Node
+	links     map[link]bool
+	partition map[string]int
+}
+
+/*
+NewNetwork creates a network connecting the nodes with pipe, pipes.BufPipe if pipe is nil.
+
+The nodes of the network keep their dbs with pttdb.EngineMemory in the (virtual) data dir
+of the network.
+*/
+func NewNetwork(pipe PipeFunc) *Network {
+	if pipe == nil {
+		pipe = pipes.BufPipe
+	}
+
+	id := atomic.AddUint32(&nNetworks, 1)
+
+	return &Network{
+		pipe:    pipe,
+		dataDir: filepath.Join(DataDirPrefix, strconv.Itoa(int(id))),
+		nodes:   make(map[string]*Node),
+		links:   make(map[link]bool),
+	}
+}
+
+/*
+NewNode creates and starts the node.
+*/
+func (nw *Network) NewNode(name string) (*Node, error) {
+	return nw.newNode(name, false)
+}
+
+/*
+NewHubNode creates and starts the node as the hub, storing and forwarding the data relayed to the offline users.
+*/
+func (nw *Network) NewHubNode(name string) (*Node, error) {
+	return nw.newNode(name, true)
+}
+
+func (nw *Network) newNode(name string, isHub bool) (*Node, error) {
+	nw.lock.Lock()
+	defer nw.lock.Unlock()
+
+	if _, ok := nw.nodes[name]; ok {
+		return nil, ErrNodeExists
+	}
+
+	n, err := newNode(name, filepath.Join(nw.dataDir, name))
+	if err != nil {
+		return nil, err
+	}
+	n.IsHub = isHub
+
+	err = n.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	nw.nodes[name] = n
+	nw.nodeList = append(nw.nodeList, n)
+
+	return n, nil
+}
+
+func (nw *Network) Node(name string) *Node {
+	nw.lock.Lock()
+	defer nw.lock.Unlock()
+
+	return nw.nodes[name]
+}
+
+/*
+Nodes returns the nodes in the order of creation.
+*/
+func (nw *Network) Nodes() []*Node {
+	nw.lock.Lock()
+	defer nw.lock.Unlock()
+
+	nodes := make([]*Node, len(nw.nodeList))
+	copy(nodes, nw.nodeList)
+
+	return nodes
+}
+
+func (nw *Network) getNodePair(a, b string) (*Node, *Node, error) {
+	if a == b {
+		return nil, nil, ErrSameNode
+	}
+
+	nodeA, ok := nw.nodes[a]
+	if !ok {
+		return nil, nil, ErrNodeNotFound
+	}
+	nodeB, ok := nw.nodes[b]
+	if !ok {
+		return nil, nil, ErrNodeNotFound
+	}
+
+	return nodeA, nodeB, nil
+}
+
+func (nw *Network) isPartitioned(a, b string) bool {
+	if nw.partition == nil {
+		return false
+	}
+
+	return nw.partition[a] != nw.partition[b]
+}
+
+/*
+Connect adds the link between a and b and connects the nodes.
+*/
+func (nw *Network) Connect(a, b string) error {
+	nw.lock.Lock()
+	defer nw.lock.Unlock()
+
+	nodeA, nodeB, err := nw.getNodePair(a, b)
+	if err != nil {
+		return err
+	}
+
+	if nw.isPartitioned(a, b) {
+		return ErrPartitioned
+	}
+
+	nw.links[newLink(a, b)] = true
+
+	return nw.connect(nodeA, nodeB)
+}
+
+/*
+connect connects a to b, retrying as the dialer of p2p does when the handshakes fail.
+*/
+func (nw *Network) connect(a, b *Node) error {
+	var err error
+	for i := 0; i < NConnectRetries; i++ {
+		err = nw.connectCore(a, b)
+		if err == nil || err == ErrNodeDown {
+			return err
+		}
+	}
+
+	return err
+}
+
+/*
+connectCore runs the handshakes of both ends of a new pipe. a is the dialer.
+*/
+func (nw *Network) connectCore(a, b *Node) error {
+	if a.IsConnected(b) && b.IsConnected(a) {
+		return nil
+	}
+
+	serverA, serverB := a.Server(), b.Server()
+	if serverA == nil || serverB == nil {
+		return ErrNodeDown
+	}
+
+	connA, connB, err := nw.pipe()
+	if err != nil {
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- serverB.SetupConn(connB, 0, nil)
+	}()
+
+	// 1 as the dialed-conn flag of p2p.
+	err = serverA.SetupConn(connA, 1, b.DiscoverNode())
+	errB := <-errCh
+	if err == nil {
+		err = errB
+	}
+	if err != nil {
+		log.Error("connect: unable to setup conn", "a", a.Name, "b", b.Name, "e", err)
+		return err
+	}
+
+	return waitFor(DefaultTimeout, func() bool {
+		return a.IsConnected(b) && b.IsConnected(a)
+	})
+}
+
+/*
+Disconnect removes the link between a and b and disconnects the nodes.
+*/
+func (nw *Network) Disconnect(a, b string) error {
+	nw.lock.Lock()
+	defer nw.lock.Unlock()
+
+	nodeA, nodeB, err := nw.getNodePair(a, b)
+	if err != nil {
+		return err
+	}
+
+	theLink := newLink(a, b)
+	if !nw.links[theLink] {
+		return ErrNotConnected
+	}
+	delete(nw.links, theLink)
+
+	return nw.disconnect(nodeA, nodeB)
+}
+
+func (nw *Network) disconnect(a, b *Node) error {
+	peer := a.getPeer(b.ID)
+	if peer != nil {
+		peer.Disconnect(p2p.DiscRequested)
+	}
+
+	return waitFor(DefaultTimeout, func() bool {
+		return !a.IsConnected(b) && !b.IsConnected(a)
+	})
+}
+
+/*
+Partition splits the network into groups. The connections across the groups
+are dropped and can not be made until Heal. Nodes not in any group form their
+own group.
+*/
+func (nw *Network) Partition(groups ...[]string) error {
+	nw.lock.Lock()
+	defer nw.lock.Unlock()
+
+	partition := make(map[string]int)
+	for i, group := range groups {
+		for _, name := range group {
+			if _, ok := nw.nodes[name]; !ok {
+				return ErrNodeNotFound
+			}
+			if _, ok := partition[name]; ok {
+				return ErrInvalidGroups
+			}
+			partition[name] = i + 1
+		}
+	}
+	nw.partition = partition
+
+	for theLink := range nw.links {
+		if !nw.isPartitioned(theLink.a, theLink.b) {
+			continue
+		}
+
+		err := nw.disconnect(nw.nodes[theLink.a], nw.nodes[theLink.b])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+Heal removes the partition and reconnects all the links.
+*/
+func (nw *Network) Heal() error {
+	nw.lock.Lock()
+	defer nw.lock.Unlock()
+
+	nw.partition = nil
+
+	return nw.reconnect("")
+}
+
+/*
+reconnect connects the links that are not partitioned and with both nodes up.
+Only the links of name are reconnected if name is not empty.
+*/
+func (nw *Network) reconnect(name string) error {
+	for theLink := range nw.links {
+		if name != "" && theLink.a != name && theLink.b != name {
+			continue
+		}
+		if nw.isPartitioned(theLink.a, theLink.b) {
+			continue
+		}
+
+		nodeA, nodeB := nw.nodes[theLink.a], nw.nodes[theLink.b]
+		if !nodeA.IsUp() || !nodeB.IsUp() {
+			continue
+		}
+
+		err := nw.connect(nodeA, nodeB)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+StopNode stops the node and waits until the other nodes drop it. The links of
+the node are kept and reconnected in StartNode.
+*/
+func (nw *Network) StopNode(name string) error {
+	nw.lock.Lock()
+	defer nw.lock.Unlock()
+
+	n, ok := nw.nodes[name]
+	if !ok {
+		return ErrNodeNotFound
+	}
+
+	err := n.Stop()
+	if err != nil {
+		return err
+	}
+
+	return waitFor(DefaultTimeout, func() bool {
+		for _, other := range nw.nodeList {
+			if other.IsConnected(n) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (nw *Network) StartNode(name string) error {
+	nw.lock.Lock()
+	defer nw.lock.Unlock()
+
+	n, ok := nw.nodes[name]
+	if !ok {
+		return ErrNodeNotFound
+	}
+
+	err := n.Start()
+	if err != nil {
+		return err
+	}
+
+	return nw.reconnect(name)
+}
+
+func (nw *Network) Restart(name string) error {
+	err := nw.StopNode(name)
+	if err != nil {
+		return err
+	}
+
+	return nw.StartNode(name)
+}
+
+/*
+Shutdown stops all the nodes and drops the dbs of the network.
+*/
+func (nw *Network) Shutdown() {
+	for _, n := range nw.Nodes() {
+		if !n.IsUp() {
+			continue
+		}
+		n.Stop()
+	}
+
+	pttdb.DropMemDatabases(nw.dataDir)
+}
+
+/*
+WaitConverged waits until digest returns the same value on the running nodes
+in names, all the running nodes if names is empty.
+*/
+func (nw *Network) WaitConverged(timeout time.Duration, digest DigestFunc, names ...string) error {
+	nodes := nw.Nodes()
+	if len(names) != 0 {
+		nodes = make([]*Node, 0, len(names))
+		for _, name := range names {
+			n := nw.Node(name)
+			if n == nil {
+				return ErrNodeNotFound
+			}
+			nodes = append(nodes, n)
+		}
+	}
+
+	err := waitFor(timeout, func() bool {
+		var first []byte
+		isFirst := true
+		for _, n := range nodes {
+			if !n.IsUp() {
+				continue
+			}
+
+			val, err := digest(n)
+			if err != nil {
+				return false
+			}
+
+			if isFirst {
+				first, isFirst = val, false
+				continue
+			}
+			if !bytes.Equal(first, val) {
+				return false
+			}
+		}
+		return true
+	})
+	if err == ErrTimeout {
+		return ErrNotConverged
+	}
+
+	return err
+}
+
+func waitFor(timeout time.Duration, cond func() bool) error {
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		if cond() {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-timer.C:
+			return ErrTimeout
+		}
+	}
+}
@@ -0,0 +1,356 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulations
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/friend"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestNetwork(t *testing.T, names ...string) *Network {
+	nw := NewNetwork(nil)
+	for _, name := range names {
+		_, err := nw.NewNode(name)
+		assert.NoError(t, err)
+	}
+
+	return nw
+}
+
+// friendsDigest is the ids of the friend entities of the node, not ready if the node is without friends.
+func friendsDigest(n *Node) ([]byte, error) {
+	stack := n.Stack()
+	if stack == nil {
+		return nil, ErrNodeDown
+	}
+
+	friends, err := stack.Friend.GetFriendList(nil, 0, pttdb.ListOrderNext)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(friends) == 0 {
+		return nil, ErrNotConverged
+	}
+
+	ids := make([]string, len(friends))
+	for i, f := range friends {
+		ids[i] = f.ID.String()
+	}
+
+	return []byte(strings.Join(ids, ",")), nil
+}
+
+// messagesDigest is the ids of the messages of the friend entity.
+func messagesDigest(entityID []byte) DigestFunc {
+	return func(n *Node) ([]byte, error) {
+		stack := n.Stack()
+		if stack == nil {
+			return nil, ErrNodeDown
+		}
+
+		messages, err := stack.Friend.GetMessageList(entityID, nil, 0, pttdb.ListOrderNext)
+		if err != nil {
+			return nil, err
+		}
+
+		ids := make([]string, len(messages))
+		for i, m := range messages {
+			ids[i] = m.ID.String()
+		}
+
+		return []byte(strings.Join(ids, ",")), nil
+	}
+}
+
+func joinFriend(t *testing.T, nw *Network, a, b string) {
+	url, err := nw.Node(a).Stack().Me.ShowURL()
+	assert.NoError(t, err)
+
+	_, err = nw.Node(b).Stack().Me.JoinFriend([]byte(url.URL))
+	assert.NoError(t, err)
+}
+
+func countFriends(n *Node) int {
+	friends, err := n.Stack().Friend.GetFriendList(nil, 0, pttdb.ListOrderNext)
+	if err != nil {
+		return -1
+	}
+
+	return len(friends)
+}
+
+// countFriendNodes is the number of the nodes of the friend known to the node.
+func countFriendNodes(n *Node, entityID []byte) int {
+	stack := n.Stack()
+
+	f, err := stack.Friend.GetRawFriend(entityID)
+	if err != nil || f.Profile == nil {
+		return -1
+	}
+
+	nodes, err := stack.Account.GetUserNodeList([]byte(f.Profile.GetID().String()), nil, 0, pttdb.ListOrderNext)
+	if err != nil {
+		return -1
+	}
+
+	return len(nodes)
+}
+
+// countHubRelays is the number of the data stored in the hub.
+func countHubRelays(n *Node) int {
+	iter, err := n.Stack().Router.DBMeta().NewIteratorWithPrefix(nil, pkgservice.DBHubRelayPrefix, pttdb.ListOrderNext)
+	if err != nil {
+		return -1
+	}
+	defer iter.Release()
+
+	count := 0
+	for iter.Next() {
+		count++
+	}
+
+	return count
+}
+
+func TestNetworkConnect(t *testing.T) {
+	nw := newTestNetwork(t, "a", "b")
+	defer nw.Shutdown()
+
+	assert.Equal(t, ErrSameNode, nw.Connect("a", "a"))
+	assert.Equal(t, ErrNodeNotFound, nw.Connect("a", "c"))
+	assert.Equal(t, ErrNotConnected, nw.Disconnect("a", "b"))
+
+	a, b := nw.Node("a"), nw.Node("b")
+	assert.NotNil(t, a.Stack())
+
+	// the engine of the other dbs in the process is not changed.
+	assert.Equal(t, pttdb.EngineLevelDB, pttdb.DefaultEngine)
+
+	assert.NoError(t, nw.Connect("a", "b"))
+	assert.True(t, a.IsConnected(b))
+	assert.True(t, b.IsConnected(a))
+
+	assert.NoError(t, nw.Disconnect("b", "a"))
+	assert.False(t, a.IsConnected(b))
+	assert.Equal(t, 0, b.PeerCount())
+}
+
+func TestNetworkJoinFriendPartitionRestart(t *testing.T) {
+	nw := newTestNetwork(t, "a", "b", "c")
+	defer nw.Shutdown()
+
+	a, b, c := nw.Node("a"), nw.Node("b"), nw.Node("c")
+
+	assert.NoError(t, nw.Connect("a", "b"))
+	assert.NoError(t, nw.Connect("b", "c"))
+
+	// a - b
+	joinFriend(t, nw, "a", "b")
+	assert.NoError(t, nw.WaitConverged(DefaultTimeout, friendsDigest, "a", "b"))
+
+	friends, err := a.Stack().Friend.GetFriendList(nil, 0, pttdb.ListOrderNext)
+	assert.NoError(t, err)
+	entityID := []byte(friends[0].ID.String())
+
+	// the peers are identified after the partition once the nodes of the friends are synced.
+	assert.NoError(t, waitFor(DefaultTimeout, func() bool {
+		return countFriendNodes(a, entityID) == 1 && countFriendNodes(b, entityID) == 1
+	}))
+
+	_, err = a.Stack().Friend.CreateMessage(entityID, [][]byte{[]byte("test1")}, nil, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, nw.WaitConverged(DefaultTimeout, messagesDigest(entityID), "a", "b"))
+
+	// partition
+	assert.NoError(t, nw.Partition([]string{"a"}, []string{"b", "c"}))
+	assert.Equal(t, ErrPartitioned, nw.Connect("a", "c"))
+	assert.False(t, a.IsConnected(b))
+
+	// message from a is not synced to b within the partition.
+	_, err = a.Stack().Friend.CreateMessage(entityID, [][]byte{[]byte("test2")}, nil, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, ErrNotConverged, nw.WaitConverged(500*time.Millisecond, messagesDigest(entityID), "a", "b"))
+
+	// c - b within the partition
+	joinFriend(t, nw, "c", "b")
+	assert.NoError(t, waitFor(DefaultTimeout, func() bool {
+		return countFriends(b) == 2 && countFriends(c) == 1
+	}))
+	assert.Equal(t, 1, countFriends(a))
+
+	// heal
+	assert.NoError(t, nw.Heal())
+	assert.True(t, a.IsConnected(b))
+	assert.NoError(t, nw.WaitConverged(DefaultTimeout, messagesDigest(entityID), "a", "b"))
+
+	// restart, the friends and the messages are kept in the dbs of b.
+	assert.NoError(t, nw.Restart("b"))
+	assert.True(t, b.IsConnected(a))
+	assert.True(t, b.IsConnected(c))
+
+	assert.Equal(t, 2, countFriends(b))
+	assert.NoError(t, nw.WaitConverged(DefaultTimeout, messagesDigest(entityID), "a", "b"))
+
+	messages, err := b.Stack().Friend.GetMessageList(entityID, nil, 0, pttdb.ListOrderNext)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(messages))
+}
+
+func TestNetworkMessageWithoutPrekey(t *testing.T) {
+	// all the prekeys are expired once announced.
+	origExpirePrekeySeconds := friend.ExpirePrekeySeconds
+	friend.ExpirePrekeySeconds = -friend.RenewPrekeySeconds
+	defer func() {
+		friend.ExpirePrekeySeconds = origExpirePrekeySeconds
+	}()
+
+	nw := newTestNetwork(t, "a", "b")
+	defer nw.Shutdown()
+
+	a, b := nw.Node("a"), nw.Node("b")
+	assert.NoError(t, nw.Connect("a", "b"))
+
+	joinFriend(t, nw, "a", "b")
+	assert.NoError(t, nw.WaitConverged(DefaultTimeout, friendsDigest, "a", "b"))
+
+	friends, err := a.Stack().Friend.GetFriendList(nil, 0, pttdb.ListOrderNext)
+	assert.NoError(t, err)
+	entityID := []byte(friends[0].ID.String())
+
+	// the message is not refused without the valid prekey of b.
+	msg, err := a.Stack().Friend.CreateMessage(entityID, [][]byte{[]byte("test")}, nil, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, nw.WaitConverged(DefaultTimeout, messagesDigest(entityID), "a", "b"))
+
+	// the content-blocks are synced after the message.
+	var blocks []*friend.BackendMessageBlock
+	assert.NoError(t, waitFor(DefaultTimeout, func() bool {
+		blocks, err = b.Stack().Friend.GetMessageBlockList(entityID, []byte(msg.MessageID.String()), 0)
+		return err == nil && len(blocks) == 1
+	}))
+	if assert.Equal(t, 1, len(blocks)) {
+		assert.Equal(t, [][]byte{[]byte("test")}, blocks[0].Buf)
+	}
+}
+
+func TestNetworkHubRelayNeverOnlineTogether(t *testing.T) {
+	nw := newTestNetwork(t, "a", "b")
+	defer nw.Shutdown()
+
+	h, err := nw.NewHubNode("h")
+	assert.NoError(t, err)
+
+	a, b := nw.Node("a"), nw.Node("b")
+	assert.NoError(t, nw.Connect("a", "b"))
+
+	joinFriend(t, nw, "a", "b")
+	assert.NoError(t, nw.WaitConverged(DefaultTimeout, friendsDigest, "a", "b"))
+
+	friends, err := a.Stack().Friend.GetFriendList(nil, 0, pttdb.ListOrderNext)
+	assert.NoError(t, err)
+	entityID := []byte(friends[0].ID.String())
+
+	assert.NoError(t, waitFor(DefaultTimeout, func() bool {
+		return countFriendNodes(a, entityID) == 1 && countFriendNodes(b, entityID) == 1
+	}))
+
+	// a and b are connected only to the hub.
+	assert.NoError(t, nw.Disconnect("a", "b"))
+	assert.NoError(t, nw.Connect("a", "h"))
+	assert.NoError(t, nw.Connect("b", "h"))
+	assert.NoError(t, waitFor(DefaultTimeout, func() bool {
+		return len(a.Stack().Router.HubPeerList()) == 1 && len(b.Stack().Router.HubPeerList()) == 1
+	}))
+
+	// b is offline when a creates the message.
+	assert.NoError(t, nw.StopNode("b"))
+
+	msg, err := a.Stack().Friend.CreateMessage(entityID, [][]byte{[]byte("test")}, nil, 0)
+	assert.NoError(t, err)
+
+	// the oplog, the message and the blocks are stored in the hub.
+	assert.NoError(t, waitFor(DefaultTimeout, func() bool {
+		return countHubRelays(h) >= 3
+	}))
+
+	// a is offline when b is back.
+	assert.NoError(t, nw.StopNode("a"))
+	assert.NoError(t, nw.StartNode("b"))
+
+	var blocks []*friend.BackendMessageBlock
+	assert.NoError(t, waitFor(DefaultTimeout, func() bool {
+		blocks, err = b.Stack().Friend.GetMessageBlockList(entityID, []byte(msg.MessageID.String()), 0)
+		return err == nil && len(blocks) == 1
+	}))
+	if assert.Equal(t, 1, len(blocks)) {
+		assert.Equal(t, [][]byte{[]byte("test")}, blocks[0].Buf)
+	}
+
+	messages, err := b.Stack().Friend.GetMessageList(entityID, nil, 0, pttdb.ListOrderNext)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(messages))
+	assert.Equal(t, 0, countHubRelays(h))
+}
+
+func TestNetworkGetMessageListNotFound(t *testing.T) {
+	nw := newTestNetwork(t, "a", "b")
+	defer nw.Shutdown()
+
+	a := nw.Node("a")
+	assert.NoError(t, nw.Connect("a", "b"))
+
+	notFoundID, err := types.NewPttID()
+	assert.NoError(t, err)
+	notFoundIDBytes := []byte(notFoundID.String())
+
+	// group
+	_, err = a.Stack().Group.GetMessageList(notFoundIDBytes, nil, 0, pttdb.ListOrderNext)
+	assert.Error(t, err)
+
+	g, err := a.Stack().Group.CreateGroup([]byte("test"))
+	assert.NoError(t, err)
+	groupID := []byte(g.ID.String())
+
+	messages, err := a.Stack().Group.GetMessageList(groupID, nil, 0, pttdb.ListOrderNext)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(messages))
+
+	_, err = a.Stack().Group.GetMessageList(groupID, notFoundIDBytes, 0, pttdb.ListOrderNext)
+	assert.Equal(t, pttdb.ErrNotFound, err)
+
+	// friend
+	_, err = a.Stack().Friend.GetMessageList(notFoundIDBytes, nil, 0, pttdb.ListOrderNext)
+	assert.Error(t, err)
+
+	joinFriend(t, nw, "a", "b")
+	assert.NoError(t, nw.WaitConverged(DefaultTimeout, friendsDigest, "a", "b"))
+
+	friends, err := a.Stack().Friend.GetFriendList(nil, 0, pttdb.ListOrderNext)
+	assert.NoError(t, err)
+	friendID := []byte(friends[0].ID.String())
+
+	_, err = a.Stack().Friend.GetMessageList(friendID, notFoundIDBytes, 0, pttdb.ListOrderNext)
+	assert.Equal(t, pttdb.ErrNotFound, err)
+}
@@ -0,0 +1,225 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulations
+
+import (
+	"crypto/ecdsa"
+	"net"
+	"path/filepath"
+	"sync"
+
+	"github.com/ailabstw/go-pttai-core/me"
+	"github.com/ailabstw/go-pttai-core/node"
+	"github.com/ailabstw/go-pttai-core/p2p"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+/*
+Node is a node.Node with the ptt services hosted in the simulated network.
+
+It does not listen, dial, discover or connect to the signal server by itself,
+all the connections are made by the Network. The dbs are in memory and kept
+through the restarts of the node.
+*/
+type Node struct {
+	Name  string
+	ID    discover.NodeID
+	IsHub bool
+
+	key      *ecdsa.PrivateKey
+	meConfig *me.Config
+	dataDir  string
+
+	lock  sync.RWMutex
+	node  *node.Node
+	stack *Stack
+}
+
+func newNode(name string, dataDir string) (*Node, error) {
+	key, err := discover.GenerateNodeKey()
+	if err != nil {
+		return nil, err
+	}
+
+	// the key of me is generated in memory with the empty data dir.
+	meConfig := &me.Config{}
+	err = meConfig.SetMyKey("", "", "", false)
+	if err != nil {
+		return nil, err
+	}
+	meConfig.DataDir = filepath.Join(dataDir, "me")
+	meConfig.DBEngine = DBEngine
+
+	return &Node{
+		Name:     name,
+		ID:       discover.PubkeyID(&key.PublicKey),
+		key:      key,
+		meConfig: meConfig,
+		dataDir:  dataDir,
+	}, nil
+}
+
+/*
+DiscoverNode returns the discover.Node used as the dial-destination of the node.
+*/
+func (n *Node) DiscoverNode() *discover.Node {
+	return discover.NewNode(n.ID, net.IPv4(127, 0, 0, 1), 0, 0)
+}
+
+/*
+newConfig returns the config of the node.Node. The data dir is empty so the
+node.Node does not touch disk, the services are with their own data dirs.
+*/
+func (n *Node) newConfig() *node.Config {
+	return &node.Config{
+		Name: n.Name,
+		P2P: p2p.Config{
+			PrivateKey:  n.key,
+			MaxPeers:    MaxPeers,
+			NoDiscovery: true,
+			NoDial:      true,
+		},
+	}
+}
+
+/*
+Start creates and starts a new node.Node, as the stopped node.Node is not able
+to be restarted in the same process.
+*/
+func (n *Node) Start() error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.node != nil {
+		return ErrNodeUp
+	}
+
+	theNode, err := node.New(n.newConfig())
+	if err != nil {
+		return err
+	}
+
+	var stack *Stack
+	err = theNode.Register(func(ctx *pkgservice.RouterContext) (pkgservice.NodeRouter, error) {
+		stack, err = newStack(ctx, n)
+		if err != nil {
+			return nil, err
+		}
+		return stack.Router, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = theNode.Start()
+	if err != nil {
+		return err
+	}
+
+	n.node = theNode
+	n.stack = stack
+
+	return nil
+}
+
+func (n *Node) Stop() error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.node == nil {
+		return ErrNodeDown
+	}
+
+	err := n.node.Stop(false, false)
+	n.node = nil
+	n.stack = nil
+
+	return err
+}
+
+func (n *Node) IsUp() bool {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	return n.node != nil
+}
+
+/*
+Server returns the running p2p server, nil if the node is down.
+*/
+func (n *Node) Server() *p2p.Server {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if n.node == nil {
+		return nil
+	}
+
+	return n.node.Server()
+}
+
+/*
+Stack returns the services of the running node, nil if the node is down.
+*/
+func (n *Node) Stack() *Stack {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	return n.stack
+}
+
+func (n *Node) getPeer(id discover.NodeID) *p2p.Peer {
+	server := n.Server()
+	if server == nil {
+		return nil
+	}
+
+	for _, peer := range server.Peers() {
+		if peer.ID() == id {
+			return peer
+		}
+	}
+
+	return nil
+}
+
+/*
+IsConnected returns whether other is a peer of n in both the p2p server and the router,
+as the join requests are sent only to the peers registered in the router.
+*/
+func (n *Node) IsConnected(other *Node) bool {
+	if n.getPeer(other.ID) == nil {
+		return false
+	}
+
+	stack := n.Stack()
+	if stack == nil {
+		return false
+	}
+
+	return stack.Router.GetPeer(&other.ID, false) != nil
+}
+
+func (n *Node) PeerCount() int {
+	server := n.Server()
+	if server == nil {
+		return 0
+	}
+
+	return server.PeerCount()
+}
@@ -0,0 +1,228 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package pipes
+
+import (
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// BufPipe creates an in process full duplex pipe like net.Pipe, except that
+// the writes are buffered and return without waiting for the reads of the
+// other end. As in net.Pipe a read does not cross the boundary of a write,
+// which the rlpx handshake relies on.
+func BufPipe() (net.Conn, net.Conn, error) {
+	a2b, b2a := newBufQueue(), newBufQueue()
+
+	a := newBufConn(b2a, a2b)
+	b := newBufConn(a2b, b2a)
+
+	return a, b, nil
+}
+
+// bufQueue is the buffered writes of one direction of the pipe.
+type bufQueue struct {
+	lock   sync.Mutex
+	bufs   [][]byte
+	closed bool
+
+	notify chan struct{}
+}
+
+func newBufQueue() *bufQueue {
+	return &bufQueue{notify: make(chan struct{}, 1)}
+}
+
+func (q *bufQueue) signal() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (q *bufQueue) push(b []byte) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.closed {
+		return io.ErrClosedPipe
+	}
+
+	buf := make([]byte, len(b))
+	copy(buf, b)
+	q.bufs = append(q.bufs, buf)
+	q.signal()
+
+	return nil
+}
+
+// pop reads the first write in the queue into b, ok is false if the queue is empty.
+func (q *bufQueue) pop(b []byte) (n int, ok bool, err error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if len(q.bufs) == 0 {
+		if q.closed {
+			return 0, false, io.EOF
+		}
+		return 0, false, nil
+	}
+
+	n = copy(b, q.bufs[0])
+	if n < len(q.bufs[0]) {
+		q.bufs[0] = q.bufs[0][n:]
+	} else {
+		q.bufs[0] = nil
+		q.bufs = q.bufs[1:]
+	}
+
+	return n, true, nil
+}
+
+func (q *bufQueue) close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.closed = true
+	q.signal()
+}
+
+type bufConn struct {
+	in  *bufQueue
+	out *bufQueue
+
+	lock         sync.Mutex
+	readDeadline time.Time
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newBufConn(in *bufQueue, out *bufQueue) *bufConn {
+	return &bufConn{
+		in:   in,
+		out:  out,
+		done: make(chan struct{}),
+	}
+}
+
+func (c *bufConn) Read(b []byte) (int, error) {
+	for {
+		select {
+		case <-c.done:
+			return 0, io.ErrClosedPipe
+		default:
+		}
+
+		n, ok, err := c.in.pop(b)
+		if ok || err != nil {
+			return n, err
+		}
+
+		c.lock.Lock()
+		deadline := c.readDeadline
+		c.lock.Unlock()
+
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if !deadline.IsZero() {
+			d := time.Until(deadline)
+			if d <= 0 {
+				return 0, timeoutError{}
+			}
+			timer = time.NewTimer(d)
+			timeout = timer.C
+		}
+
+		select {
+		case <-c.in.notify:
+		case <-timeout:
+			return 0, timeoutError{}
+		case <-c.done:
+			return 0, io.ErrClosedPipe
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+func (c *bufConn) Write(b []byte) (int, error) {
+	select {
+	case <-c.done:
+		return 0, io.ErrClosedPipe
+	default:
+	}
+
+	err := c.out.push(b)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(b), nil
+}
+
+func (c *bufConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.out.close()
+		c.in.close()
+	})
+
+	return nil
+}
+
+func (c *bufConn) LocalAddr() net.Addr {
+	return bufAddr{}
+}
+
+func (c *bufConn) RemoteAddr() net.Addr {
+	return bufAddr{}
+}
+
+func (c *bufConn) SetDeadline(t time.Time) error {
+	return c.SetReadDeadline(t)
+}
+
+func (c *bufConn) SetReadDeadline(t time.Time) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.readDeadline = t
+	c.in.signal()
+
+	return nil
+}
+
+// SetWriteDeadline is a no-op as the writes do not block.
+func (c *bufConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+type bufAddr struct{}
+
+func (bufAddr) Network() string { return "bufpipe" }
+func (bufAddr) String() string  { return "bufpipe" }
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
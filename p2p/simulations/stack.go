@@ -0,0 +1,134 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulations
+
+import (
+	"path/filepath"
+
+	"github.com/ailabstw/go-pttai-core/account"
+	"github.com/ailabstw/go-pttai-core/content"
+	"github.com/ailabstw/go-pttai-core/friend"
+	"github.com/ailabstw/go-pttai-core/group"
+	"github.com/ailabstw/go-pttai-core/me"
+	pkgservice "github.com/ailabstw/go-pttai-core/service"
+)
+
+/*
+Stack is the ptt services of a running node, the same set as a gptt node.
+A new Stack is created on every start of the node.
+*/
+type Stack struct {
+	Router *pkgservice.BaseRouter
+
+	Account *account.Backend
+	Friend  *friend.Backend
+	Group   *group.Backend
+	Content *content.Backend
+	Me      *me.Backend
+}
+
+/*
+newStack creates the router and the services of n with the dbs in the data dir of n.
+*/
+func newStack(ctx *pkgservice.RouterContext, n *Node) (*Stack, error) {
+	routerConfig := pkgservice.DefaultConfig
+	routerConfig.DataDir = filepath.Join(n.dataDir, "service")
+	routerConfig.DBEngine = DBEngine
+	routerConfig.IsHub = n.IsHub
+
+	accountConfig := account.DefaultConfig
+	accountConfig.DataDir = filepath.Join(n.dataDir, "account")
+	accountConfig.DBEngine = DBEngine
+
+	friendConfig := friend.DefaultConfig
+	friendConfig.DataDir = filepath.Join(n.dataDir, "friend")
+	friendConfig.DBEngine = DBEngine
+
+	groupConfig := group.DefaultConfig
+	groupConfig.DataDir = filepath.Join(n.dataDir, "group")
+	groupConfig.DBEngine = DBEngine
+
+	contentConfig := content.DefaultConfig
+	contentConfig.DataDir = filepath.Join(n.dataDir, "content")
+	contentConfig.DBEngine = DBEngine
+
+	stack := &Stack{}
+
+	// router
+	ptt, err := pkgservice.NewRouter(ctx, &routerConfig, &n.ID, n.key)
+	if err != nil {
+		return nil, err
+	}
+	stack.Router = ptt
+
+	// account
+	stack.Account, err = account.NewBackend(ctx, &accountConfig, ptt)
+	if err != nil {
+		return nil, err
+	}
+	err = ptt.RegisterService(stack.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	// friend
+	stack.Friend, err = friend.NewBackend(ctx, &friendConfig, n.meConfig.ID, ptt, stack.Account)
+	if err != nil {
+		return nil, err
+	}
+	err = ptt.RegisterService(stack.Friend)
+	if err != nil {
+		return nil, err
+	}
+
+	// group
+	stack.Group, err = group.NewBackend(ctx, &groupConfig, ptt)
+	if err != nil {
+		return nil, err
+	}
+	err = ptt.RegisterService(stack.Group)
+	if err != nil {
+		return nil, err
+	}
+
+	// content
+	stack.Content, err = content.NewBackend(ctx, &contentConfig, ptt)
+	if err != nil {
+		return nil, err
+	}
+	err = ptt.RegisterService(stack.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	// me
+	stack.Me, err = me.NewBackend(ctx, n.meConfig, ptt, stack.Account, stack.Friend)
+	if err != nil {
+		return nil, err
+	}
+	err = ptt.RegisterService(stack.Me)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ptt.Prestart()
+	if err != nil {
+		return nil, err
+	}
+
+	return stack, nil
+}
@@ -23,7 +23,10 @@ const (
 	OfferIDOffset = len(OfferIDPrefix)
 )
 
-var ()
+var (
+	// ICE-servers of the peer-connections, only the host candidates are gathered if empty.
+	ICEServers = []string{"stun:stun.l.google.com:19302"}
+)
 
 func init() {
 }
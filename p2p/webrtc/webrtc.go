@@ -86,12 +86,13 @@ func NewWebrtc(
 
 	api := webrtc.NewAPI(webrtc.WithSettingEngine(s))
 
-	config := webrtc.Configuration{
-		ICEServers: []webrtc.ICEServer{
+	config := webrtc.Configuration{}
+	if len(ICEServers) != 0 {
+		config.ICEServers = []webrtc.ICEServer{
 			{
-				URLs: []string{"stun:stun.l.google.com:19302"},
+				URLs: ICEServers,
 			},
-		},
+		}
 	}
 
 	w := &Webrtc{
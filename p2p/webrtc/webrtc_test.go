@@ -17,34 +17,34 @@
 package webrtc
 
 import (
-	"net/http"
-	"net/url"
+	"bytes"
 	"testing"
 	"time"
 
-	signalserver "github.com/ailabstw/pttai-signal-server"
-
-	"github.com/ailabstw/go-pttai-core/log"
 	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	"github.com/ailabstw/go-pttai-core/p2p/signaling"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 )
 
-func handleWebrtcWithTest(t *testing.T) func(conn *WebrtcConn) {
+// handleWebrtcWithTest reads "test" and writes "test2", and returns the result in errChan.
+func handleWebrtcWithTest(t *testing.T, errChan chan error) func(conn *WebrtcConn) {
 	return func(conn *WebrtcConn) {
-		t.Logf("handleWebrtcWithTest: start: conn: %v", conn)
-		b := make([]byte, 10)
-		n, err := conn.Read(b)
-		t.Logf("handleWebrtcWithTest: after Read: n: %v e: %v b: %v", n, err, b)
-		assert.NoError(t, err)
-		assert.Equal(t, 4, n)
-		assert.Equal(t, []byte("test"), b[:n])
-
-		n, err = conn.Write([]byte("test2"))
-		t.Logf("handleWebrtcWithTest: after Write: n: %v e: %v", n, err)
-		assert.NoError(t, err)
-		assert.Equal(t, 5, n)
+		errChan <- func() error {
+			b := make([]byte, 10)
+			n, err := conn.Read(b)
+			t.Logf("handleWebrtcWithTest: after Read: n: %v e: %v b: %v", n, err, b)
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal([]byte("test"), b[:n]) {
+				return ErrInvalidWebrtc
+			}
+
+			n, err = conn.Write([]byte("test2"))
+			t.Logf("handleWebrtcWithTest: after Write: n: %v e: %v", n, err)
+			return err
+		}()
 	}
 }
 
@@ -52,62 +52,62 @@ func TestWebrtc(t *testing.T) {
 	setupTest(t)
 	defer teardownTest(t)
 
-	addr := "127.0.0.1:9488"
-	go func() {
-		server := signalserver.NewServer()
+	// only the local host-candidates with the in-process signaling-server.
+	origICEServers := ICEServers
+	ICEServers = nil
+	defer func() { ICEServers = origICEServers }()
 
-		srv := &http.Server{Addr: addr}
-		r := mux.NewRouter()
-		r.HandleFunc("/signal", server.SignalHandler)
-		srv.Handler = r
-
-		srv.ListenAndServe()
-	}()
-
-	url := url.URL{Scheme: "ws", Host: addr, Path: "/signal"}
+	server := signaling.NewServer()
+	err := server.Start("127.0.0.1:0")
+	assert.NoError(t, err)
+	defer server.Stop()
 
-	handle := handleWebrtcWithTest(t)
+	url := server.URL()
 
 	key1, err := crypto.GenerateKey()
-	if err != nil {
-		t.Errorf("failed generate key1: %v", err)
-	}
-	nodeID1 := discover.PubkeyID(&key1.PublicKey)
-
+	assert.NoError(t, err)
 	key2, err := crypto.GenerateKey()
-	if err != nil {
-		t.Errorf("failed generate key2: %v", err)
-	}
-	nodeID2 := discover.PubkeyID(&key2.PublicKey)
-
-	w1, err := NewWebrtc(nodeID1, key1, url, handle)
-	t.Logf("TestClientSendReceive: after c1: e: %v", err)
 	assert.NoError(t, err)
+	nodeID1 := discover.PubkeyID(&key1.PublicKey)
+	nodeID2 := discover.PubkeyID(&key2.PublicKey)
 
-	go func() {
-		_, err = NewWebrtc(nodeID2, key2, url, handle)
-		t.Logf("TestClientSendReceive: after c2: e: %v", err)
-		assert.NoError(t, err)
+	errChan1 := make(chan error, 1)
+	errChan2 := make(chan error, 1)
 
-		select {}
-	}()
+	w1, err := NewWebrtc(nodeID1, key1, url, handleWebrtcWithTest(t, errChan1))
+	assert.NoError(t, err)
+	defer w1.Close()
 
-	conn1, err := w1.CreateOffer(nodeID2)
+	w2, err := NewWebrtc(nodeID2, key2, url, handleWebrtcWithTest(t, errChan2))
 	assert.NoError(t, err)
+	defer w2.Close()
 
-	time.Sleep(1 * time.Second)
+	conn1, err := w1.CreateOffer(nodeID2)
+	if !assert.NoError(t, err) {
+		return
+	}
 
 	n, err := conn1.Write([]byte("test"))
 	assert.NoError(t, err)
 	assert.Equal(t, 4, n)
 
+	// handled by w2
+	select {
+	case err = <-errChan2:
+		assert.NoError(t, err)
+	case <-time.After(TimeoutSecondConnectWebrtc * time.Second):
+		t.Fatalf("timeout in handling the conn")
+	}
+
 	b := make([]byte, 10)
 	n, err = conn1.Read(b)
 	assert.NoError(t, err)
-	assert.Equal(t, 5, n)
 	assert.Equal(t, []byte("test2"), b[:n])
 
-	time.Sleep(1 * time.Second)
-
-	log.Debug("after Sleep")
+	// the offering side is not handled.
+	select {
+	case err = <-errChan1:
+		t.Errorf("handled in the offering side: e: %v", err)
+	default:
+	}
 }
@@ -26,6 +26,7 @@ import (
 	"testing"
 
 	"github.com/ailabstw/go-pttai-core/pttdb"
+	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 func newTestLDB() (*pttdb.LDBDatabase, func()) {
@@ -123,6 +124,48 @@ func testPutGet(db pttdb.Database, t *testing.T) {
 	}
 }
 
+func TestLDB_PrevIteratorWithRange(t *testing.T) {
+	db, remove := newTestLDB()
+	defer remove()
+	testPrevIteratorWithRange(db, t)
+}
+
+func TestMemoryDB_PrevIteratorWithRange(t *testing.T) {
+	testPrevIteratorWithRange(pttdb.NewMemDatabase(), t)
+}
+
+func testPrevIteratorWithRange(db pttdb.Storage, t *testing.T) {
+	for _, k := range []string{"a", "b", "c"} {
+		err := db.Put([]byte(k), []byte(k))
+		if err != nil {
+			t.Fatalf("put failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		r    *util.Range
+		want string
+	}{
+		{"nil", nil, "cba"},
+		{"without limit", &util.Range{Start: []byte("b")}, "cb"},
+		{"with limit", &util.Range{Limit: []byte("c")}, "ba"},
+	}
+	for _, tt := range tests {
+		iter := db.NewIteratorWithRange(tt.r, pttdb.ListOrderPrev)
+
+		got := ""
+		for iter.Prev() {
+			got += string(iter.Key())
+		}
+		iter.Release()
+
+		if got != tt.want {
+			t.Errorf("%v: got %q expected %q", tt.name, got, tt.want)
+		}
+	}
+}
+
 func TestLDB_ParallelPutGet(t *testing.T) {
 	db, remove := newTestLDB()
 	defer remove()
@@ -16,7 +16,11 @@
 
 package pttdb
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
 
 var (
 	ErrInvalidPrefix   = errors.New("invalid prefix")
@@ -25,4 +29,7 @@ var (
 	ErrInvalidLock     = errors.New("invalid db lock")
 	ErrBusy            = errors.New("db busy")
 	ErrInvalidKeys     = errors.New("invalid db keys")
+	ErrInvalidEngine   = errors.New("invalid db engine")
+
+	ErrNotFound = leveldb.ErrNotFound
 )
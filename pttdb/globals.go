@@ -33,6 +33,9 @@ const (
 
 	SizeDBKeyPrefix          = 5
 	OffsetDBKeyPrefixPostfix = 3
+
+	// MetricsPrefix is the prefix of the leveldb meters (compaction / write-delay / disk-io).
+	MetricsPrefix = "pttdb/"
 )
 
 var (
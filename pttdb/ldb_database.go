@@ -17,10 +17,8 @@
 package pttdb
 
 import (
-	"encoding/json"
 	"fmt"
 	"path/filepath"
-	"reflect"
 	"strconv"
 	"strings"
 	"sync"
@@ -55,8 +53,7 @@ type LDBDatabase struct {
 
 	log log.Logger // Contextual logger tracking the database path
 
-	lockLockMap sync.Mutex
-	lockMap     map[string]int
+	*keyLockMap
 }
 
 // NewLDBDatabase returns a LevelDB wrapped object.
@@ -91,11 +88,11 @@ func NewLDBDatabase(file string, dataDir string, cache int, handles int) (*LDBDa
 		return nil, err
 	}
 	return &LDBDatabase{
-		name:    file,
-		fn:      fullFilename,
-		db:      db,
-		log:     logger,
-		lockMap: make(map[string]int),
+		name:       file,
+		fn:         fullFilename,
+		db:         db,
+		log:        logger,
+		keyLockMap: newKeyLockMap(),
 	}, nil
 }
 
@@ -114,126 +111,7 @@ TryPut tries to put the key/val based on the updateTS of val.
 Value is the jsonified bytes. the obj of the bytes needs to include UpdateTS.
 */
 func (db *LDBDatabase) TryPut(key []byte, value []byte, updateTS types.Timestamp) ([]byte, error) {
-	//log.Debug("TryPut: start", "key", key)
-
-	err := db.TryLockMap(key)
-	if err != nil {
-		return nil, err
-	}
-	defer db.UnlockMap(key)
-
-	isHasKey, err := db.Has(key)
-	if err != nil {
-		return nil, err
-	}
-
-	if !isHasKey { // new-one
-		err := db.Put(key, value)
-		return nil, err
-	}
-
-	v, err := db.Get(key)
-	if err != nil {
-		return nil, err
-	}
-
-	d := &DBable{}
-
-	err = json.Unmarshal(v, d)
-	if err != nil {
-		return nil, ErrInvalidDBable
-	}
-
-	if updateTS.IsLess(d.UpdateTS) {
-		log.Warn("updateTS < d.UpdateTS", "updateTS", updateTS, "d.UpdateTS", d.UpdateTS, "key", key)
-		return v, ErrInvalidUpdateTS
-	}
-
-	// put to db
-
-	err = db.Put(key, value)
-	if err != nil {
-		return nil, err
-	}
-
-	return v, nil
-}
-
-func (db *LDBDatabase) TryLockMap(key []byte) error {
-	mapKey := string(key)
-
-	db.lockLockMap.Lock()
-	defer db.lockLockMap.Unlock()
-
-	//log.Debug("to TryLockMap", "key", key)
-
-	// try to get lock
-	val, ok := db.lockMap[mapKey]
-	if ok { // someone-else is using the map-key
-		log.Error("TryLockMap: busy", "mapKey", mapKey, "val", val)
-		return ErrBusy
-	}
-	db.lockMap[mapKey] = -1
-
-	return nil
-}
-
-func (db *LDBDatabase) UnlockMap(key []byte) error {
-	mapKey := string(key)
-
-	db.lockLockMap.Lock()
-	defer db.lockLockMap.Unlock()
-
-	_, ok := db.lockMap[mapKey]
-	if !ok { // should not happen
-		return ErrInvalidLock
-	}
-
-	delete(db.lockMap, mapKey)
-
-	return nil
-}
-
-func (db *LDBDatabase) TryRLockMap(key []byte) error {
-	mapKey := string(key)
-
-	db.lockLockMap.Lock()
-	defer db.lockLockMap.Unlock()
-
-	// try to get lock
-	i, ok := db.lockMap[mapKey]
-	if ok && i < 0 { // write-lock
-		log.Error("TryRLockMap: busy", "i", i, "ok", ok)
-		return ErrBusy
-	}
-
-	log.Debug("after TryRLockMap (pass lock)", "i", i, "ok", ok)
-
-	if !ok {
-		db.lockMap[mapKey] = 0
-	}
-	db.lockMap[mapKey]++
-
-	return nil
-}
-
-func (db *LDBDatabase) RUnlockMap(key []byte) error {
-	mapKey := string(key)
-
-	db.lockLockMap.Lock()
-	defer db.lockLockMap.Unlock()
-
-	i, ok := db.lockMap[mapKey]
-	if !ok || i == 0 { // should not happen
-		panic("db invalid unlock")
-	}
-	db.lockMap[mapKey]--
-
-	if i == 1 {
-		delete(db.lockMap, mapKey)
-	}
-
-	return nil
+	return tryPut(db, key, value, updateTS)
 }
 
 // Put puts the given key / value to the queue
@@ -266,24 +144,7 @@ func (db *LDBDatabase) Delete(key []byte) error {
 
 // Delete With Get
 func (db *LDBDatabase) Pop(key []byte) ([]byte, error) {
-	err := db.TryLockMap(key)
-	if err != nil {
-		return nil, err
-	}
-	defer db.UnlockMap(key)
-
-	val, err := db.Get(key)
-	if err != nil {
-		// Unable to get key. possibly no key in the db. no need to do delete
-		return nil, err
-	}
-
-	err = db.Delete(key)
-	if err != nil {
-		return nil, err
-	}
-
-	return val, nil
+	return pop(db, key)
 }
 
 func (db *LDBDatabase) NewIterator(listOrder ListOrder) iterator.Iterator {
@@ -298,7 +159,7 @@ func (db *LDBDatabase) NewIterator(listOrder ListOrder) iterator.Iterator {
 func (db *LDBDatabase) NewIteratorWithRange(r *util.Range, listOrder ListOrder) iterator.Iterator {
 	iter := db.db.NewIterator(r, nil)
 	if listOrder == ListOrderPrev {
-		iter.Seek(r.Limit)
+		seekRangeLimit(iter, r)
 	}
 
 	return iter
@@ -306,47 +167,7 @@ func (db *LDBDatabase) NewIteratorWithRange(r *util.Range, listOrder ListOrder)
 
 // NewIteratorWithPrefix returns a iterator to iterate over subset of database content with a particular prefix.
 func (db *LDBDatabase) NewIteratorWithPrefix(start []byte, prefix []byte, listOrder ListOrder) (iterator.Iterator, error) {
-	// both as nil
-	if len(start) == 0 && len(prefix) == 0 {
-		return db.NewIterator(listOrder), nil
-	}
-
-	// start as nil
-	if len(start) == 0 {
-		r := util.BytesPrefix(prefix)
-		return db.NewIteratorWithRange(r, listOrder), nil
-	}
-
-	// prefix as nil
-	if len(prefix) == 0 {
-		startRange := util.BytesPrefix(start)
-		var r *util.Range
-		switch listOrder {
-		case ListOrderPrev:
-			r = &util.Range{Limit: startRange.Limit}
-		case ListOrderNext:
-			r = &util.Range{Start: startRange.Start}
-		}
-
-		return db.NewIteratorWithRange(r, listOrder), nil
-	}
-
-	// both non-nil
-	if !strings.HasPrefix(string(start), string(prefix)) {
-		return nil, ErrInvalidPrefix
-	}
-
-	startRange := util.BytesPrefix(start)
-	prefixRange := util.BytesPrefix(prefix)
-	var r *util.Range
-	switch listOrder {
-	case ListOrderPrev:
-		r = &util.Range{Start: prefixRange.Start, Limit: startRange.Limit}
-	case ListOrderNext:
-		r = &util.Range{Start: startRange.Start, Limit: prefixRange.Limit}
-	}
-
-	return db.NewIteratorWithRange(r, listOrder), nil
+	return newIteratorWithPrefix(db, start, prefix, listOrder)
 }
 
 func (db *LDBDatabase) Close() {
@@ -375,18 +196,21 @@ func (db *LDBDatabase) LDB() *leveldb.DB {
 }
 
 // Meter configures the database metrics collectors and
+// starts the periodic collector.
+//
+// The meters are get-or-registered so that the re-opened database reports to the same meters.
 func (db *LDBDatabase) Meter(prefix string) {
 	if metrics.Enabled {
 		// Initialize all the metrics collector at the requested prefix
-		db.compTimeMeter = metrics.NewRegisteredMeter(prefix+"compact/time", nil)
-		db.compReadMeter = metrics.NewRegisteredMeter(prefix+"compact/input", nil)
-		db.compWriteMeter = metrics.NewRegisteredMeter(prefix+"compact/output", nil)
-		db.diskReadMeter = metrics.NewRegisteredMeter(prefix+"disk/read", nil)
-		db.diskWriteMeter = metrics.NewRegisteredMeter(prefix+"disk/write", nil)
+		db.compTimeMeter = metrics.GetOrRegisterMeter(prefix+"compact/time", nil)
+		db.compReadMeter = metrics.GetOrRegisterMeter(prefix+"compact/input", nil)
+		db.compWriteMeter = metrics.GetOrRegisterMeter(prefix+"compact/output", nil)
+		db.diskReadMeter = metrics.GetOrRegisterMeter(prefix+"disk/read", nil)
+		db.diskWriteMeter = metrics.GetOrRegisterMeter(prefix+"disk/write", nil)
 	}
 	// Initialize write delay metrics no matter we are in metric mode or not.
-	db.writeDelayMeter = metrics.NewRegisteredMeter(prefix+"compact/writedelay/duration", nil)
-	db.writeDelayNMeter = metrics.NewRegisteredMeter(prefix+"compact/writedelay/counter", nil)
+	db.writeDelayMeter = metrics.GetOrRegisterMeter(prefix+"compact/writedelay/duration", nil)
+	db.writeDelayNMeter = metrics.GetOrRegisterMeter(prefix+"compact/writedelay/counter", nil)
 
 	// Create a quit channel for the periodic collector and run it
 	db.quitLock.Lock()
@@ -607,14 +431,6 @@ func (b *ldbBatch) Put(key, value []byte) error {
 	return nil
 }
 
-func (b *ldbBatch) DBGet(key []byte) ([]byte, error) {
-	return b.db.Get(key)
-}
-
-func (b *ldbBatch) DBDelete(key []byte) error {
-	return b.db.Delete(key)
-}
-
 func (b *ldbBatch) Delete(key []byte) error {
 	b.b.Delete(key)
 
@@ -634,418 +450,3 @@ func (b *ldbBatch) Reset() {
 	b.b.Reset()
 	b.size = 0
 }
-
-func (b *ldbBatch) DB() *LDBDatabase {
-	return b.db
-}
-
-type LDBBatch struct {
-	*ldbBatch
-}
-
-func NewLDBBatch(db *LDBDatabase) (*LDBBatch, error) {
-	batch := &ldbBatch{
-		db: db,
-		b:  new(leveldb.Batch),
-	}
-
-	return &LDBBatch{
-		ldbBatch: batch,
-	}, nil
-}
-
-func (b *LDBBatch) PutAllWithKeyIndex(key []byte, idx *Index, kvs []*KeyVal) error {
-	b.Reset()
-	marshaledIdx, err := idx.Marshal()
-	if err != nil {
-		return err
-	}
-
-	err = b.Put(key, marshaledIdx)
-	if err != nil {
-		return err
-	}
-
-	return b.PutAll(kvs, false)
-}
-
-func (b *LDBBatch) PutAll(kvs []*KeyVal, isInit bool) error {
-	if isInit {
-		b.Reset()
-	}
-	for _, kv := range kvs {
-		err := b.Put(kv.K, kv.V)
-		if err != nil {
-			return err
-		}
-	}
-
-	err := b.Write()
-	if err != nil {
-		return err
-	}
-
-	b.Reset()
-	return nil
-}
-
-/*
-TryPutAll tries to put all the key-vals with comparing the updateTS of the 1st item.
-
-This is used when the real content is stored in ts-based key, but we want to refer the content directly from id-key.
-
-This assumes 1-record per key. The old data will be deleted if key conflict.
-
-For oplog: the id of each oplog is unique.
-For content:
-*/
-func (b *LDBBatch) TryPutAll(idxKey []byte, idx *Index, kvs []*KeyVal, isDeleteOrig bool, isGetOrig bool) ([]*KeyVal, error) {
-	log.Debug("TryPutAll: start", "idxKey", idxKey)
-
-	db := b.ldbBatch.DB()
-
-	err := db.TryLockMap(idxKey)
-	if err != nil {
-		log.Error("TryPutAll: unable to lock", "idxKey", idxKey, "e", err)
-		return nil, err
-	}
-	defer db.UnlockMap(idxKey)
-
-	isHasKey, err := db.Has(idxKey)
-	if err != nil {
-		log.Error("TryPutAll: unable to has", "idxKey", idxKey, "e", err)
-		return nil, err
-	}
-
-	if !isHasKey { // new-one
-		err := b.PutAllWithKeyIndex(idxKey, idx, kvs)
-		log.Debug("TryPutAll: after PutAllWithKeyIndex (new-one)", "idxKey", idxKey, "e", err)
-		return nil, err
-	}
-
-	v, err := db.Get(idxKey)
-	if err != nil {
-		log.Error("TryPutAll: unable to Get", "idxKey", idxKey, "e", err)
-		return nil, err
-	}
-
-	d := &Index{}
-	err = d.Unmarshal(v)
-	if err != nil { // unable to get original data.
-		log.Error("TryPutAll: unable to unmarshal index", "idxKey", idxKey, "v", v, "e", err)
-		return nil, ErrInvalidDBable
-	}
-	var origKVs []*KeyVal
-	i := 0
-	var key []byte
-	if isGetOrig {
-		origKVs = make([]*KeyVal, len(d.Keys))
-		for i, key = range d.Keys {
-			v, err = db.Get(key)
-			if err != nil {
-				log.Error("TryPutAll: (GetOrig) unable to get key", "idxKey", idxKey, "k", key, "e", err)
-				return nil, err
-			}
-			origKVs[i] = &KeyVal{
-				K: key,
-				V: v,
-			}
-		}
-	}
-
-	if idx.UpdateTS.IsLess(d.UpdateTS) {
-		log.Warn("updateTS < d.UpdateTS", "idxKey", idxKey, "updateTS", idx.UpdateTS, "d.UpdateTS", d.UpdateTS, "d.Keys", d.Keys)
-		return origKVs, ErrInvalidUpdateTS
-	}
-
-	// delete original data
-	if isDeleteOrig {
-		for _, eachKey := range d.Keys {
-			db.Delete(eachKey)
-		}
-	}
-
-	// put to db
-
-	err = b.PutAllWithKeyIndex(idxKey, idx, kvs)
-	if err != nil {
-		log.Error("TryPutAll: unable to PutAllWithKeyIndex", "idxKey", idxKey, "e", err)
-		return nil, err
-	}
-
-	return origKVs, nil
-}
-
-/*
-TryPutAll tries to put all the key-vals with comparing the updateTS of the 1st item.
-
-This is used when the real content is stored in ts-based key, but we want to refer the content directly from id-key.
-
-This assumes 1-record per key. The old data will be deleted if key conflict.
-
-For oplog: the id of each oplog is unique.
-For content:
-*/
-func (b *LDBBatch) TryPutAllSameUT(idxKey []byte, idx *Index, kvs []*KeyVal, isDeleteOrig bool) ([][]byte, error) {
-	log.Debug("TryPutAllSameUT: start", "idxKey", idxKey)
-
-	db := b.ldbBatch.DB()
-
-	err := db.TryLockMap(idxKey)
-	if err != nil {
-		log.Error("TryPutAllSameUT: unable to lock", "idxKey", idxKey, "e", err)
-		return nil, err
-	}
-	defer db.UnlockMap(idxKey)
-
-	isHasKey, err := db.Has(idxKey)
-	if err != nil {
-		log.Error("TryPutAllSameUT: unable to has", "idxKey", idxKey, "e", err)
-		return nil, err
-	}
-
-	if !isHasKey { // new-one
-		err := b.PutAllWithKeyIndex(idxKey, idx, kvs)
-		log.Debug("TryPutAllSameUT: after PutAllWithKeyIndex (new-one)", "idxKey", idxKey, "e", err)
-		return nil, err
-	}
-
-	v, err := db.Get(idxKey)
-	if err != nil {
-		log.Error("TryPutAllSameUT: unable to Get", "idxKey", idxKey, "e", err)
-		return nil, err
-	}
-
-	d := &Index{}
-	err = d.Unmarshal(v)
-	if err != nil { // unable to get original data.
-		return nil, ErrInvalidDBable
-	}
-
-	if idx.UpdateTS.IsLess(d.UpdateTS) {
-		log.Warn("TryPutAllSameUT: updateTS < d.UpdateTS", "idxKey", idxKey, "updateTS", idx.UpdateTS, "d.UpdateTS", d.UpdateTS)
-		return d.Keys, ErrInvalidUpdateTS
-	}
-
-	if idx.UpdateTS == d.UpdateTS && !reflect.DeepEqual(d, idx) {
-		log.Warn("updateTS == d.UpdateTS but idx diff", "idxKey", idxKey, "updateTS", idx.UpdateTS, "d.UpdateTS", d.UpdateTS, "idx", idx, "d", d)
-		return d.Keys, ErrInvalidUpdateTS
-	}
-
-	// delete original data
-	if isDeleteOrig {
-		for _, eachKey := range d.Keys {
-			db.Delete(eachKey)
-		}
-	}
-
-	// put to db
-
-	err = b.PutAllWithKeyIndex(idxKey, idx, kvs)
-	if err != nil {
-		log.Error("TryPutAllSameUT: unable to PutAllWithKeyIndex", "idxKey", idxKey, "e", err)
-		return nil, err
-	}
-
-	return d.Keys, nil
-}
-
-/*
-ForcePutAll tries to put all the key-vals with comparing the updateTS of the 1st item.
-
-This is used when the real content is stored in ts-based key, but we want to refer the content directly from id-key.
-
-This assumes 1-record per key. The old data will be deleted if key conflict.
-
-For oplog: the id of each oplog is unique.
-For content:
-*/
-func (b *LDBBatch) ForcePutAll(idxKey []byte, idx *Index, kvs []*KeyVal) ([][]byte, error) {
-	log.Debug("ForcePutAll: start", "idxKey", idxKey)
-
-	db := b.ldbBatch.DB()
-
-	err := db.TryLockMap(idxKey)
-	if err != nil {
-		log.Error("ForcePutAll: unable to lock", "idxKey", idxKey, "e", err)
-		return nil, err
-	}
-	defer db.UnlockMap(idxKey)
-
-	isHasKey, err := db.Has(idxKey)
-	if err != nil {
-		log.Error("ForcePutAll: unable to has", "idxKey", idxKey, "e", err)
-		return nil, err
-	}
-
-	if !isHasKey { // new-one
-		err := b.PutAllWithKeyIndex(idxKey, idx, kvs)
-		log.Debug("ForcePutAll: after PutAllWithKeyIndex (new-one)", "idxKey", idxKey, "e", err)
-		return nil, err
-	}
-
-	v, err := db.Get(idxKey)
-	if err != nil {
-		log.Error("ForcePutAll: unable to Get", "idxKey", idxKey, "e", err)
-		return nil, err
-	}
-
-	d := &Index{}
-	err = d.Unmarshal(v)
-	if err != nil { // unable to get original data.
-		return nil, ErrInvalidDBable
-	}
-
-	// delete original data
-	for _, eachKey := range d.Keys {
-		db.Delete(eachKey)
-	}
-
-	// put to db
-
-	err = b.PutAllWithKeyIndex(idxKey, idx, kvs)
-	if err != nil {
-		log.Error("TryPutAll: unable to PutAllWithKeyIndex", "idxKey", idxKey, "e", err)
-		return nil, err
-	}
-
-	return d.Keys, nil
-}
-
-func (b *LDBBatch) DeleteAllKeys(keys [][]byte) error {
-	if keys == nil {
-		return nil
-	}
-
-	db := b.ldbBatch.DB()
-	for _, key := range keys {
-		db.Delete(key)
-	}
-
-	return nil
-}
-
-func (b *LDBBatch) DeleteAll(idxKey []byte) error {
-	db := b.ldbBatch.DB()
-
-	err := db.TryLockMap(idxKey)
-	if err != nil {
-		return err
-	}
-	defer db.UnlockMap(idxKey)
-
-	v, err := db.Get(idxKey)
-	if err == leveldb.ErrNotFound {
-		return nil
-	}
-
-	if err != nil {
-		return err
-	}
-
-	d := &Index{}
-	err = d.Unmarshal(v)
-	if err != nil { // unable to get original data.
-		return ErrInvalidDBable
-	}
-
-	for _, eachKey := range d.Keys {
-		db.Delete(eachKey)
-	}
-
-	return db.Delete(idxKey)
-}
-
-func (b *LDBBatch) GetByIdxKey(idxKey []byte, idx int) ([]byte, error) {
-	db := b.ldbBatch.DB()
-	err := db.TryRLockMap(idxKey)
-	if err != nil {
-		return nil, err
-	}
-	defer db.RUnlockMap(idxKey)
-
-	v, err := db.Get(idxKey)
-	if err != nil {
-		return nil, err
-	}
-
-	d := &Index{}
-	err = d.Unmarshal(v)
-	if err != nil { // unable to get original data.
-		return nil, ErrInvalidDBable
-	}
-
-	if d.Keys == nil || len(d.Keys) <= idx || d.Keys[idx] == nil {
-		return nil, ErrInvalidKeys
-	}
-
-	v, err = db.Get(d.Keys[idx])
-	if err != nil {
-		return nil, err
-	}
-
-	return v, nil
-}
-
-func (b *LDBBatch) GetKeyByIdxKey(idxKey []byte, idx int) ([]byte, error) {
-	db := b.ldbBatch.DB()
-	err := db.TryRLockMap(idxKey)
-	if err != nil {
-		return nil, err
-	}
-	defer db.RUnlockMap(idxKey)
-
-	v, err := db.Get(idxKey)
-	if err != nil {
-		return nil, err
-	}
-
-	d := &Index{}
-	err = d.Unmarshal(v)
-	if err != nil { // unable to get original data.
-		return nil, ErrInvalidDBable
-	}
-
-	if d.Keys == nil || len(d.Keys) <= idx || d.Keys[idx] == nil {
-		return nil, ErrInvalidKeys
-	}
-
-	return d.Keys[idx], nil
-}
-
-func (b *LDBBatch) GetBy2ndIdxKey(idxKey []byte) ([]byte, error) {
-	db := b.ldbBatch.DB()
-	err := db.TryRLockMap(idxKey)
-	if err != nil {
-		return nil, err
-	}
-	defer db.RUnlockMap(idxKey)
-
-	key, err := db.Get(idxKey)
-	if err != nil {
-		return nil, err
-	}
-
-	v, err := db.Get(key)
-	if err != nil {
-		return nil, err
-	}
-
-	return v, nil
-}
-
-func (b *LDBBatch) GetKeyBy2ndIdxKey(idxKey []byte) ([]byte, error) {
-	db := b.ldbBatch.DB()
-	err := db.TryRLockMap(idxKey)
-	if err != nil {
-		return nil, err
-	}
-	defer db.RUnlockMap(idxKey)
-
-	key, err := db.Get(idxKey)
-	if err != nil {
-		return nil, err
-	}
-
-	return key, nil
-}
@@ -0,0 +1,114 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package pttdb
+
+import (
+	"sync"
+
+	"github.com/ailabstw/go-pttai-core/log"
+)
+
+/*
+keyLockMap implements the TryLockMap family shared by the storage-engines.
+
+The value of the map is -1 for write-lock and the number of readers for read-lock.
+*/
+type keyLockMap struct {
+	lockLockMap sync.Mutex
+	lockMap     map[string]int
+}
+
+func newKeyLockMap() *keyLockMap {
+	return &keyLockMap{
+		lockMap: make(map[string]int),
+	}
+}
+
+func (m *keyLockMap) TryLockMap(key []byte) error {
+	mapKey := string(key)
+
+	m.lockLockMap.Lock()
+	defer m.lockLockMap.Unlock()
+
+	// try to get lock
+	val, ok := m.lockMap[mapKey]
+	if ok { // someone-else is using the map-key
+		log.Error("TryLockMap: busy", "mapKey", mapKey, "val", val)
+		return ErrBusy
+	}
+	m.lockMap[mapKey] = -1
+
+	return nil
+}
+
+func (m *keyLockMap) UnlockMap(key []byte) error {
+	mapKey := string(key)
+
+	m.lockLockMap.Lock()
+	defer m.lockLockMap.Unlock()
+
+	_, ok := m.lockMap[mapKey]
+	if !ok { // should not happen
+		return ErrInvalidLock
+	}
+
+	delete(m.lockMap, mapKey)
+
+	return nil
+}
+
+func (m *keyLockMap) TryRLockMap(key []byte) error {
+	mapKey := string(key)
+
+	m.lockLockMap.Lock()
+	defer m.lockLockMap.Unlock()
+
+	// try to get lock
+	i, ok := m.lockMap[mapKey]
+	if ok && i < 0 { // write-lock
+		log.Error("TryRLockMap: busy", "i", i, "ok", ok)
+		return ErrBusy
+	}
+
+	log.Debug("after TryRLockMap (pass lock)", "i", i, "ok", ok)
+
+	if !ok {
+		m.lockMap[mapKey] = 0
+	}
+	m.lockMap[mapKey]++
+
+	return nil
+}
+
+func (m *keyLockMap) RUnlockMap(key []byte) error {
+	mapKey := string(key)
+
+	m.lockLockMap.Lock()
+	defer m.lockLockMap.Unlock()
+
+	i, ok := m.lockMap[mapKey]
+	if !ok || i == 0 { // should not happen
+		panic("db invalid unlock")
+	}
+	m.lockMap[mapKey]--
+
+	if i == 1 {
+		delete(m.lockMap, mapKey)
+	}
+
+	return nil
+}
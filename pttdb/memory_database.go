@@ -17,32 +17,98 @@
 package pttdb
 
 import (
-	"errors"
+	"bytes"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 
+	"github.com/ailabstw/go-pttai-core/common/types"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 /*
- * This is a test memory database. Do not use for any production it does not get persisted
- */
+MemDatabase is the in-memory storage-engine. It does not get persisted.
+
+The iterators take the snapshot of the requested range
+(the same as the iterators of LevelDB), which is O(n) for each iterator.
+Do not use for any production.
+*/
 type MemDatabase struct {
+	name string
+
 	db   map[string][]byte
 	lock sync.RWMutex
+
+	*keyLockMap
 }
 
 func NewMemDatabase() *MemDatabase {
 	return &MemDatabase{
-		db: make(map[string][]byte),
+		db:         make(map[string][]byte),
+		keyLockMap: newKeyLockMap(),
 	}
 }
 
 func NewMemDatabaseWithCap(size int) *MemDatabase {
 	return &MemDatabase{
-		db: make(map[string][]byte, size),
+		db:         make(map[string][]byte, size),
+		keyLockMap: newKeyLockMap(),
+	}
+}
+
+/*
+OpenMemDatabase returns the MemDatabase of file in dataDir.
+
+The content is kept in the process even if the db is closed,
+so the services can be restarted without touching disk.
+Use DropMemDatabases to release the content.
+*/
+func OpenMemDatabase(file string, dataDir string) *MemDatabase {
+	fullFilename := filepath.Join(dataDir, file)
+
+	memDBsLock.Lock()
+	defer memDBsLock.Unlock()
+
+	db, ok := memDBs[fullFilename]
+	if ok {
+		return db
+	}
+
+	db = NewMemDatabase()
+	db.name = file
+	memDBs[fullFilename] = db
+
+	return db
+}
+
+/*
+DropMemDatabases releases the MemDatabases opened in dataDir (including the sub-dirs).
+*/
+func DropMemDatabases(dataDir string) {
+	dataDir = filepath.Clean(dataDir)
+
+	memDBsLock.Lock()
+	defer memDBsLock.Unlock()
+
+	for fullFilename := range memDBs {
+		if fullFilename == dataDir || strings.HasPrefix(fullFilename, dataDir+string(filepath.Separator)) {
+			delete(memDBs, fullFilename)
+		}
 	}
 }
 
+var (
+	memDBs     = make(map[string]*MemDatabase)
+	memDBsLock sync.Mutex
+)
+
+func (db *MemDatabase) Name() string {
+	return db.name
+}
+
 func (db *MemDatabase) Put(key []byte, value []byte) error {
 	db.lock.Lock()
 	defer db.lock.Unlock()
@@ -66,7 +132,7 @@ func (db *MemDatabase) Get(key []byte) ([]byte, error) {
 	if entry, ok := db.db[string(key)]; ok {
 		return common.CopyBytes(entry), nil
 	}
-	return nil, errors.New("not found")
+	return nil, ErrNotFound
 }
 
 func (db *MemDatabase) Keys() [][]byte {
@@ -88,6 +154,61 @@ func (db *MemDatabase) Delete(key []byte) error {
 	return nil
 }
 
+/*
+TryPut tries to put the key/val based on the updateTS of val.
+*/
+func (db *MemDatabase) TryPut(key []byte, value []byte, updateTS types.Timestamp) ([]byte, error) {
+	return tryPut(db, key, value, updateTS)
+}
+
+// Delete With Get
+func (db *MemDatabase) Pop(key []byte) ([]byte, error) {
+	return pop(db, key)
+}
+
+func (db *MemDatabase) NewIterator(listOrder ListOrder) iterator.Iterator {
+	iter := db.newSnapshotIterator(nil)
+	if listOrder == ListOrderPrev {
+		iter.Seek(dbLastKey)
+	}
+
+	return iter
+}
+
+func (db *MemDatabase) NewIteratorWithRange(r *util.Range, listOrder ListOrder) iterator.Iterator {
+	iter := db.newSnapshotIterator(r)
+	if listOrder == ListOrderPrev {
+		seekRangeLimit(iter, r)
+	}
+
+	return iter
+}
+
+// NewIteratorWithPrefix returns a iterator to iterate over subset of database content with a particular prefix.
+func (db *MemDatabase) NewIteratorWithPrefix(start []byte, prefix []byte, listOrder ListOrder) (iterator.Iterator, error) {
+	return newIteratorWithPrefix(db, start, prefix, listOrder)
+}
+
+func (db *MemDatabase) newSnapshotIterator(r *util.Range) iterator.Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	kvs := make(memKVs, 0, len(db.db))
+	for k, v := range db.db {
+		key := []byte(k)
+		if r != nil && r.Start != nil && bytes.Compare(key, r.Start) < 0 {
+			continue
+		}
+		if r != nil && r.Limit != nil && bytes.Compare(key, r.Limit) >= 0 {
+			continue
+		}
+		kvs = append(kvs, &KeyVal{K: key, V: common.CopyBytes(v)})
+	}
+	sort.Sort(kvs)
+
+	return iterator.NewArrayIterator(kvs)
+}
+
 func (db *MemDatabase) Close() {}
 
 func (db *MemDatabase) NewBatch() Batch {
@@ -96,17 +217,39 @@ func (db *MemDatabase) NewBatch() Batch {
 
 func (db *MemDatabase) Len() int { return len(db.db) }
 
-type kv struct{ k, v []byte }
+/*
+memKVs is the sorted key-vals for the snapshot iterator (implementing iterator.Array).
+*/
+type memKVs []*KeyVal
+
+func (kvs memKVs) Len() int { return len(kvs) }
 
+func (kvs memKVs) Less(i, j int) bool { return bytes.Compare(kvs[i].K, kvs[j].K) < 0 }
+
+func (kvs memKVs) Swap(i, j int) { kvs[i], kvs[j] = kvs[j], kvs[i] }
+
+func (kvs memKVs) Search(key []byte) int {
+	return sort.Search(len(kvs), func(i int) bool { return bytes.Compare(kvs[i].K, key) >= 0 })
+}
+
+func (kvs memKVs) Index(i int) ([]byte, []byte) { return kvs[i].K, kvs[i].V }
+
+/*
+memBatch keeps the puts and the deletes in order.
+*/
 type memBatch struct {
-	db      *MemDatabase
-	writes  []kv
-	deletes [][]byte
-	size    int
+	db   *MemDatabase
+	ops  []*memBatchOp
+	size int
+}
+
+type memBatchOp struct {
+	kv       *KeyVal
+	isDelete bool
 }
 
 func (b *memBatch) Put(key, value []byte) error {
-	b.writes = append(b.writes, kv{common.CopyBytes(key), common.CopyBytes(value)})
+	b.ops = append(b.ops, &memBatchOp{kv: &KeyVal{K: common.CopyBytes(key), V: common.CopyBytes(value)}})
 	b.size += len(value)
 	return nil
 }
@@ -115,18 +258,19 @@ func (b *memBatch) Write() error {
 	b.db.lock.Lock()
 	defer b.db.lock.Unlock()
 
-	for _, kv := range b.writes {
-		b.db.db[string(kv.k)] = kv.v
+	for _, op := range b.ops {
+		if op.isDelete {
+			delete(b.db.db, string(op.kv.K))
+			continue
+		}
+		b.db.db[string(op.kv.K)] = op.kv.V
 	}
 
-	for _, key := range b.deletes {
-		delete(b.db.db, string(key))
-	}
 	return nil
 }
 
 func (b *memBatch) Delete(key []byte) error {
-	b.deletes = append(b.deletes, key)
+	b.ops = append(b.ops, &memBatchOp{kv: &KeyVal{K: common.CopyBytes(key)}, isDelete: true})
 	return nil
 }
 
@@ -135,6 +279,6 @@ func (b *memBatch) ValueSize() int {
 }
 
 func (b *memBatch) Reset() {
-	b.writes = b.writes[:0]
+	b.ops = b.ops[:0]
 	b.size = 0
 }
@@ -0,0 +1,253 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package pttdb
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+/*
+Storage is the storage-engine abstraction used by the services.
+
+The services only rely on the key-value operations, the ts-based TryPut,
+the prefix / range iterators and the TryLockMap family.
+LDBDatabase (persisted) and MemDatabase (in-memory) are the engines.
+*/
+type Storage interface {
+	Database
+
+	Name() string
+
+	TryPut(key []byte, value []byte, updateTS types.Timestamp) ([]byte, error)
+	Pop(key []byte) ([]byte, error)
+
+	// lock-map
+	TryLockMap(key []byte) error
+	UnlockMap(key []byte) error
+	TryRLockMap(key []byte) error
+	RUnlockMap(key []byte) error
+
+	// iterator
+	NewIterator(listOrder ListOrder) iterator.Iterator
+	NewIteratorWithRange(r *util.Range, listOrder ListOrder) iterator.Iterator
+	NewIteratorWithPrefix(start []byte, prefix []byte, listOrder ListOrder) (iterator.Iterator, error)
+}
+
+/*
+IndexBatch is the index-aware batch on top of a Storage.
+
+The real content is stored in the ts-based keys and referred by an idx-key
+with Index as the value.
+*/
+type IndexBatch interface {
+	Batch
+
+	DB() Storage
+	DBGet(key []byte) ([]byte, error)
+	DBDelete(key []byte) error
+
+	PutAllWithKeyIndex(key []byte, idx *Index, kvs []*KeyVal) error
+	PutAll(kvs []*KeyVal, isInit bool) error
+
+	TryPutAll(idxKey []byte, idx *Index, kvs []*KeyVal, isDeleteOrig bool, isGetOrig bool) ([]*KeyVal, error)
+	TryPutAllSameUT(idxKey []byte, idx *Index, kvs []*KeyVal, isDeleteOrig bool) ([][]byte, error)
+	ForcePutAll(idxKey []byte, idx *Index, kvs []*KeyVal) ([][]byte, error)
+
+	DeleteAllKeys(keys [][]byte) error
+	DeleteAll(idxKey []byte) error
+
+	GetByIdxKey(idxKey []byte, idx int) ([]byte, error)
+	GetKeyByIdxKey(idxKey []byte, idx int) ([]byte, error)
+	GetBy2ndIdxKey(idxKey []byte) ([]byte, error)
+	GetKeyBy2ndIdxKey(idxKey []byte) ([]byte, error)
+}
+
+// Engine is the type of the storage-engine.
+type Engine string
+
+const (
+	EngineLevelDB Engine = "leveldb"
+	EngineMemory  Engine = "memory"
+)
+
+/*
+DefaultEngine is the engine used by NewStorage.
+
+Set as EngineMemory to run the whole node without touching disk (ex: tests).
+*/
+var (
+	DefaultEngine = EngineLevelDB
+)
+
+/*
+NewStorage opens the storage of file in dataDir with DefaultEngine.
+*/
+func NewStorage(file string, dataDir string) (Storage, error) {
+	return NewStorageWithEngine(DefaultEngine, file, dataDir)
+}
+
+/*
+NewStorageWithEngine opens the storage of file in dataDir with engine, DefaultEngine if engine is empty.
+*/
+func NewStorageWithEngine(engine Engine, file string, dataDir string) (Storage, error) {
+	if engine == "" {
+		engine = DefaultEngine
+	}
+
+	switch engine {
+	case EngineLevelDB:
+		db, err := NewLDBDatabase(file, dataDir, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		if metrics.Enabled {
+			db.Meter(MetricsPrefix + file + "/")
+		}
+		return db, nil
+	case EngineMemory:
+		return OpenMemDatabase(file, dataDir), nil
+	}
+
+	return nil, ErrInvalidEngine
+}
+
+/**********
+ * engine-independent implementations
+ **********/
+
+/*
+tryPut tries to put the key/val based on the updateTS of val.
+
+Value is the jsonified bytes. the obj of the bytes needs to include UpdateTS.
+*/
+func tryPut(db Storage, key []byte, value []byte, updateTS types.Timestamp) ([]byte, error) {
+	err := db.TryLockMap(key)
+	if err != nil {
+		return nil, err
+	}
+	defer db.UnlockMap(key)
+
+	isHasKey, err := db.Has(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isHasKey { // new-one
+		err := db.Put(key, value)
+		return nil, err
+	}
+
+	v, err := db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DBable{}
+
+	err = json.Unmarshal(v, d)
+	if err != nil {
+		return nil, ErrInvalidDBable
+	}
+
+	if updateTS.IsLess(d.UpdateTS) {
+		log.Warn("updateTS < d.UpdateTS", "updateTS", updateTS, "d.UpdateTS", d.UpdateTS, "key", key)
+		return v, ErrInvalidUpdateTS
+	}
+
+	// put to db
+
+	err = db.Put(key, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// pop deletes the key with get.
+func pop(db Storage, key []byte) ([]byte, error) {
+	err := db.TryLockMap(key)
+	if err != nil {
+		return nil, err
+	}
+	defer db.UnlockMap(key)
+
+	val, err := db.Get(key)
+	if err != nil {
+		// Unable to get key. possibly no key in the db. no need to do delete
+		return nil, err
+	}
+
+	err = db.Delete(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return val, nil
+}
+
+// newIteratorWithPrefix returns a iterator to iterate over subset of database content with a particular prefix.
+func newIteratorWithPrefix(db Storage, start []byte, prefix []byte, listOrder ListOrder) (iterator.Iterator, error) {
+	// both as nil
+	if len(start) == 0 && len(prefix) == 0 {
+		return db.NewIterator(listOrder), nil
+	}
+
+	// start as nil
+	if len(start) == 0 {
+		r := util.BytesPrefix(prefix)
+		return db.NewIteratorWithRange(r, listOrder), nil
+	}
+
+	// prefix as nil
+	if len(prefix) == 0 {
+		startRange := util.BytesPrefix(start)
+		var r *util.Range
+		switch listOrder {
+		case ListOrderPrev:
+			r = &util.Range{Limit: startRange.Limit}
+		case ListOrderNext:
+			r = &util.Range{Start: startRange.Start}
+		}
+
+		return db.NewIteratorWithRange(r, listOrder), nil
+	}
+
+	// both non-nil
+	if !strings.HasPrefix(string(start), string(prefix)) {
+		return nil, ErrInvalidPrefix
+	}
+
+	startRange := util.BytesPrefix(start)
+	prefixRange := util.BytesPrefix(prefix)
+	var r *util.Range
+	switch listOrder {
+	case ListOrderPrev:
+		r = &util.Range{Start: prefixRange.Start, Limit: startRange.Limit}
+	case ListOrderNext:
+		r = &util.Range{Start: startRange.Start, Limit: prefixRange.Limit}
+	}
+
+	return db.NewIteratorWithRange(r, listOrder), nil
+}
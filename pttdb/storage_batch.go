@@ -0,0 +1,475 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package pttdb
+
+import (
+	"reflect"
+
+	"github.com/ailabstw/go-pttai-core/log"
+)
+
+/*
+StorageBatch implements IndexBatch on top of any Storage.
+*/
+type StorageBatch struct {
+	db Storage
+	b  Batch
+}
+
+func NewStorageBatch(db Storage) (*StorageBatch, error) {
+	return &StorageBatch{
+		db: db,
+		b:  db.NewBatch(),
+	}, nil
+}
+
+/*
+LDBBatch is the StorageBatch on top of LDBDatabase.
+*/
+type LDBBatch = StorageBatch
+
+func NewLDBBatch(db *LDBDatabase) (*LDBBatch, error) {
+	return NewStorageBatch(db)
+}
+
+func (b *StorageBatch) Put(key, value []byte) error {
+	return b.b.Put(key, value)
+}
+
+func (b *StorageBatch) Delete(key []byte) error {
+	return b.b.Delete(key)
+}
+
+func (b *StorageBatch) Write() error {
+	return b.b.Write()
+}
+
+func (b *StorageBatch) ValueSize() int {
+	return b.b.ValueSize()
+}
+
+func (b *StorageBatch) Reset() {
+	b.b.Reset()
+}
+
+func (b *StorageBatch) DB() Storage {
+	return b.db
+}
+
+func (b *StorageBatch) DBGet(key []byte) ([]byte, error) {
+	return b.db.Get(key)
+}
+
+func (b *StorageBatch) DBDelete(key []byte) error {
+	return b.db.Delete(key)
+}
+
+func (b *StorageBatch) PutAllWithKeyIndex(key []byte, idx *Index, kvs []*KeyVal) error {
+	b.Reset()
+	marshaledIdx, err := idx.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = b.Put(key, marshaledIdx)
+	if err != nil {
+		return err
+	}
+
+	return b.PutAll(kvs, false)
+}
+
+func (b *StorageBatch) PutAll(kvs []*KeyVal, isInit bool) error {
+	if isInit {
+		b.Reset()
+	}
+	for _, kv := range kvs {
+		err := b.Put(kv.K, kv.V)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := b.Write()
+	if err != nil {
+		return err
+	}
+
+	b.Reset()
+	return nil
+}
+
+/*
+TryPutAll tries to put all the key-vals with comparing the updateTS of the 1st item.
+
+This is used when the real content is stored in ts-based key, but we want to refer the content directly from id-key.
+
+This assumes 1-record per key. The old data will be deleted if key conflict.
+
+For oplog: the id of each oplog is unique.
+For content:
+*/
+func (b *StorageBatch) TryPutAll(idxKey []byte, idx *Index, kvs []*KeyVal, isDeleteOrig bool, isGetOrig bool) ([]*KeyVal, error) {
+	log.Debug("TryPutAll: start", "idxKey", idxKey)
+
+	db := b.db
+
+	err := db.TryLockMap(idxKey)
+	if err != nil {
+		log.Error("TryPutAll: unable to lock", "idxKey", idxKey, "e", err)
+		return nil, err
+	}
+	defer db.UnlockMap(idxKey)
+
+	isHasKey, err := db.Has(idxKey)
+	if err != nil {
+		log.Error("TryPutAll: unable to has", "idxKey", idxKey, "e", err)
+		return nil, err
+	}
+
+	if !isHasKey { // new-one
+		err := b.PutAllWithKeyIndex(idxKey, idx, kvs)
+		log.Debug("TryPutAll: after PutAllWithKeyIndex (new-one)", "idxKey", idxKey, "e", err)
+		return nil, err
+	}
+
+	v, err := db.Get(idxKey)
+	if err != nil {
+		log.Error("TryPutAll: unable to Get", "idxKey", idxKey, "e", err)
+		return nil, err
+	}
+
+	d := &Index{}
+	err = d.Unmarshal(v)
+	if err != nil { // unable to get original data.
+		log.Error("TryPutAll: unable to unmarshal index", "idxKey", idxKey, "v", v, "e", err)
+		return nil, ErrInvalidDBable
+	}
+	var origKVs []*KeyVal
+	i := 0
+	var key []byte
+	if isGetOrig {
+		origKVs = make([]*KeyVal, len(d.Keys))
+		for i, key = range d.Keys {
+			v, err = db.Get(key)
+			if err != nil {
+				log.Error("TryPutAll: (GetOrig) unable to get key", "idxKey", idxKey, "k", key, "e", err)
+				return nil, err
+			}
+			origKVs[i] = &KeyVal{
+				K: key,
+				V: v,
+			}
+		}
+	}
+
+	if idx.UpdateTS.IsLess(d.UpdateTS) {
+		log.Warn("updateTS < d.UpdateTS", "idxKey", idxKey, "updateTS", idx.UpdateTS, "d.UpdateTS", d.UpdateTS, "d.Keys", d.Keys)
+		return origKVs, ErrInvalidUpdateTS
+	}
+
+	// delete original data
+	if isDeleteOrig {
+		for _, eachKey := range d.Keys {
+			db.Delete(eachKey)
+		}
+	}
+
+	// put to db
+
+	err = b.PutAllWithKeyIndex(idxKey, idx, kvs)
+	if err != nil {
+		log.Error("TryPutAll: unable to PutAllWithKeyIndex", "idxKey", idxKey, "e", err)
+		return nil, err
+	}
+
+	return origKVs, nil
+}
+
+/*
+TryPutAll tries to put all the key-vals with comparing the updateTS of the 1st item.
+
+This is used when the real content is stored in ts-based key, but we want to refer the content directly from id-key.
+
+This assumes 1-record per key. The old data will be deleted if key conflict.
+
+For oplog: the id of each oplog is unique.
+For content:
+*/
+func (b *StorageBatch) TryPutAllSameUT(idxKey []byte, idx *Index, kvs []*KeyVal, isDeleteOrig bool) ([][]byte, error) {
+	log.Debug("TryPutAllSameUT: start", "idxKey", idxKey)
+
+	db := b.db
+
+	err := db.TryLockMap(idxKey)
+	if err != nil {
+		log.Error("TryPutAllSameUT: unable to lock", "idxKey", idxKey, "e", err)
+		return nil, err
+	}
+	defer db.UnlockMap(idxKey)
+
+	isHasKey, err := db.Has(idxKey)
+	if err != nil {
+		log.Error("TryPutAllSameUT: unable to has", "idxKey", idxKey, "e", err)
+		return nil, err
+	}
+
+	if !isHasKey { // new-one
+		err := b.PutAllWithKeyIndex(idxKey, idx, kvs)
+		log.Debug("TryPutAllSameUT: after PutAllWithKeyIndex (new-one)", "idxKey", idxKey, "e", err)
+		return nil, err
+	}
+
+	v, err := db.Get(idxKey)
+	if err != nil {
+		log.Error("TryPutAllSameUT: unable to Get", "idxKey", idxKey, "e", err)
+		return nil, err
+	}
+
+	d := &Index{}
+	err = d.Unmarshal(v)
+	if err != nil { // unable to get original data.
+		return nil, ErrInvalidDBable
+	}
+
+	if idx.UpdateTS.IsLess(d.UpdateTS) {
+		log.Warn("TryPutAllSameUT: updateTS < d.UpdateTS", "idxKey", idxKey, "updateTS", idx.UpdateTS, "d.UpdateTS", d.UpdateTS)
+		return d.Keys, ErrInvalidUpdateTS
+	}
+
+	if idx.UpdateTS == d.UpdateTS && !reflect.DeepEqual(d, idx) {
+		log.Warn("updateTS == d.UpdateTS but idx diff", "idxKey", idxKey, "updateTS", idx.UpdateTS, "d.UpdateTS", d.UpdateTS, "idx", idx, "d", d)
+		return d.Keys, ErrInvalidUpdateTS
+	}
+
+	// delete original data
+	if isDeleteOrig {
+		for _, eachKey := range d.Keys {
+			db.Delete(eachKey)
+		}
+	}
+
+	// put to db
+
+	err = b.PutAllWithKeyIndex(idxKey, idx, kvs)
+	if err != nil {
+		log.Error("TryPutAllSameUT: unable to PutAllWithKeyIndex", "idxKey", idxKey, "e", err)
+		return nil, err
+	}
+
+	return d.Keys, nil
+}
+
+/*
+ForcePutAll tries to put all the key-vals with comparing the updateTS of the 1st item.
+
+This is used when the real content is stored in ts-based key, but we want to refer the content directly from id-key.
+
+This assumes 1-record per key. The old data will be deleted if key conflict.
+
+For oplog: the id of each oplog is unique.
+For content:
+*/
+func (b *StorageBatch) ForcePutAll(idxKey []byte, idx *Index, kvs []*KeyVal) ([][]byte, error) {
+	log.Debug("ForcePutAll: start", "idxKey", idxKey)
+
+	db := b.db
+
+	err := db.TryLockMap(idxKey)
+	if err != nil {
+		log.Error("ForcePutAll: unable to lock", "idxKey", idxKey, "e", err)
+		return nil, err
+	}
+	defer db.UnlockMap(idxKey)
+
+	isHasKey, err := db.Has(idxKey)
+	if err != nil {
+		log.Error("ForcePutAll: unable to has", "idxKey", idxKey, "e", err)
+		return nil, err
+	}
+
+	if !isHasKey { // new-one
+		err := b.PutAllWithKeyIndex(idxKey, idx, kvs)
+		log.Debug("ForcePutAll: after PutAllWithKeyIndex (new-one)", "idxKey", idxKey, "e", err)
+		return nil, err
+	}
+
+	v, err := db.Get(idxKey)
+	if err != nil {
+		log.Error("ForcePutAll: unable to Get", "idxKey", idxKey, "e", err)
+		return nil, err
+	}
+
+	d := &Index{}
+	err = d.Unmarshal(v)
+	if err != nil { // unable to get original data.
+		return nil, ErrInvalidDBable
+	}
+
+	// delete original data
+	for _, eachKey := range d.Keys {
+		db.Delete(eachKey)
+	}
+
+	// put to db
+
+	err = b.PutAllWithKeyIndex(idxKey, idx, kvs)
+	if err != nil {
+		log.Error("TryPutAll: unable to PutAllWithKeyIndex", "idxKey", idxKey, "e", err)
+		return nil, err
+	}
+
+	return d.Keys, nil
+}
+
+func (b *StorageBatch) DeleteAllKeys(keys [][]byte) error {
+	if keys == nil {
+		return nil
+	}
+
+	db := b.db
+	for _, key := range keys {
+		db.Delete(key)
+	}
+
+	return nil
+}
+
+func (b *StorageBatch) DeleteAll(idxKey []byte) error {
+	db := b.db
+
+	err := db.TryLockMap(idxKey)
+	if err != nil {
+		return err
+	}
+	defer db.UnlockMap(idxKey)
+
+	v, err := db.Get(idxKey)
+	if err == ErrNotFound {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	d := &Index{}
+	err = d.Unmarshal(v)
+	if err != nil { // unable to get original data.
+		return ErrInvalidDBable
+	}
+
+	for _, eachKey := range d.Keys {
+		db.Delete(eachKey)
+	}
+
+	return db.Delete(idxKey)
+}
+
+func (b *StorageBatch) GetByIdxKey(idxKey []byte, idx int) ([]byte, error) {
+	db := b.db
+	err := db.TryRLockMap(idxKey)
+	if err != nil {
+		return nil, err
+	}
+	defer db.RUnlockMap(idxKey)
+
+	v, err := db.Get(idxKey)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Index{}
+	err = d.Unmarshal(v)
+	if err != nil { // unable to get original data.
+		return nil, ErrInvalidDBable
+	}
+
+	if d.Keys == nil || len(d.Keys) <= idx || d.Keys[idx] == nil {
+		return nil, ErrInvalidKeys
+	}
+
+	v, err = db.Get(d.Keys[idx])
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (b *StorageBatch) GetKeyByIdxKey(idxKey []byte, idx int) ([]byte, error) {
+	db := b.db
+	err := db.TryRLockMap(idxKey)
+	if err != nil {
+		return nil, err
+	}
+	defer db.RUnlockMap(idxKey)
+
+	v, err := db.Get(idxKey)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Index{}
+	err = d.Unmarshal(v)
+	if err != nil { // unable to get original data.
+		return nil, ErrInvalidDBable
+	}
+
+	if d.Keys == nil || len(d.Keys) <= idx || d.Keys[idx] == nil {
+		return nil, ErrInvalidKeys
+	}
+
+	return d.Keys[idx], nil
+}
+
+func (b *StorageBatch) GetBy2ndIdxKey(idxKey []byte) ([]byte, error) {
+	db := b.db
+	err := db.TryRLockMap(idxKey)
+	if err != nil {
+		return nil, err
+	}
+	defer db.RUnlockMap(idxKey)
+
+	key, err := db.Get(idxKey)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (b *StorageBatch) GetKeyBy2ndIdxKey(idxKey []byte) ([]byte, error) {
+	db := b.db
+	err := db.TryRLockMap(idxKey)
+	if err != nil {
+		return nil, err
+	}
+	defer db.RUnlockMap(idxKey)
+
+	key, err := db.Get(idxKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
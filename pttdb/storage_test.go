@@ -0,0 +1,94 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package pttdb
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+)
+
+func testStorageEngine(t *testing.T, engine Engine) {
+	dataDir := "./test.out/storage"
+	defer os.RemoveAll(dataDir)
+	defer DropMemDatabases(dataDir)
+
+	db, err := NewStorageWithEngine(engine, "test", dataDir)
+	if err != nil {
+		t.Errorf("unable to new storage: engine: %v e: %v", engine, err)
+		return
+	}
+	defer db.Close()
+
+	db.Put([]byte("test123"), []byte("1"))
+	db.Put([]byte("test125"), []byte("2"))
+	db.Put([]byte("tesu"), []byte("3"))
+
+	// prev-iterator with prefix
+	iter, err := db.NewIteratorWithPrefix(nil, []byte("test"), ListOrderPrev)
+	if err != nil {
+		t.Errorf("NewIteratorWithPrefix: engine: %v e: %v", engine, err)
+		return
+	}
+	var got [][]byte
+	for iter.Prev() {
+		got = append(got, append([]byte{}, iter.Key()...))
+	}
+	iter.Release()
+	want := [][]byte{[]byte("test125"), []byte("test123")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewIteratorWithPrefix: engine: %v got: %v want: %v", engine, got, want)
+	}
+
+	// try-put
+	ts, _ := types.GetTimestamp()
+	dbable := &DBable{UpdateTS: ts}
+	marshaled, _ := json.Marshal(dbable)
+	_, err = db.TryPut([]byte("tesv"), marshaled, ts)
+	if err != nil {
+		t.Errorf("TryPut: engine: %v e: %v", engine, err)
+	}
+
+	// batch
+	b, err := NewStorageBatch(db)
+	if err != nil {
+		t.Errorf("NewStorageBatch: engine: %v e: %v", engine, err)
+		return
+	}
+	idx := &Index{Keys: [][]byte{[]byte("k1")}, UpdateTS: ts}
+	kvs := []*KeyVal{{K: []byte("k1"), V: []byte("v1")}}
+	_, err = b.TryPutAll([]byte("idx1"), idx, kvs, true, false)
+	if err != nil {
+		t.Errorf("TryPutAll: engine: %v e: %v", engine, err)
+		return
+	}
+	val, err := b.GetByIdxKey([]byte("idx1"), 0)
+	if err != nil || string(val) != "v1" {
+		t.Errorf("GetByIdxKey: engine: %v val: %s e: %v", engine, val, err)
+	}
+}
+
+func TestStorage_LevelDB(t *testing.T) {
+	testStorageEngine(t, EngineLevelDB)
+}
+
+func TestStorage_Memory(t *testing.T) {
+	testStorageEngine(t, EngineMemory)
+}
@@ -20,9 +20,10 @@ import (
 	"github.com/ailabstw/go-pttai-core/common"
 	"github.com/ailabstw/go-pttai-core/common/types"
 	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
-func GetIterByID(db *LDBDatabase, prefix []byte, idxPrefix []byte, startID *types.PttID, listOrder ListOrder) (iterator.Iterator, error) {
+func GetIterByID(db Storage, prefix []byte, idxPrefix []byte, startID *types.PttID, listOrder ListOrder) (iterator.Iterator, error) {
 	if startID == nil {
 		return db.NewIteratorWithPrefix(nil, prefix, listOrder)
 	}
@@ -50,3 +51,17 @@ func GetFuncIter(iter iterator.Iterator, listOrder ListOrder) func() bool {
 
 	return nil
 }
+
+/*
+seekRangeLimit seeks the iterator to the limit of the range for ListOrderPrev,
+so that the first Prev is the last key in the range.
+The nil range or the range without the limit seeks to the last key of the db.
+*/
+func seekRangeLimit(iter iterator.Iterator, r *util.Range) {
+	if r == nil || r.Limit == nil {
+		iter.Seek(dbLastKey)
+		return
+	}
+
+	iter.Seek(r.Limit)
+}
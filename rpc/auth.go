@@ -0,0 +1,349 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/ailabstw/go-pttai-core/log"
+	erpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+/*
+Auth authenticates the rpc-requests by the api-tokens,
+checks the permissions of the methods,
+and audits the privileged calls (the methods of the non-public apis).
+*/
+type Auth struct {
+	store *TokenStore
+
+	privileged map[string]bool
+
+	connLock sync.Mutex
+	conns    map[string]map[io.Closer]struct{} // the open websocket-connections by token-id
+
+	auditLock sync.Mutex
+	auditFile *os.File
+	auditEnc  *json.Encoder
+}
+
+/*
+AuditRecord is the record of a privileged call in the audit-log.
+*/
+type AuditRecord struct {
+	TS        time.Time `json:"t"`
+	Transport string    `json:"transport"`
+	Remote    string    `json:"remote"`
+	TokenID   string    `json:"tokenID,omitempty"`
+	TokenName string    `json:"tokenName,omitempty"`
+	Method    string    `json:"method"`
+	IsAllowed bool      `json:"allowed"`
+}
+
+/*
+NewAuth creates the Auth.
+The privileged methods are from the non-public apis. The audit-log is appended to auditFilename (no audit if empty).
+*/
+func NewAuth(store *TokenStore, apis []erpc.API, auditFilename string) (*Auth, error) {
+	a := &Auth{
+		store:      store,
+		privileged: privilegedMethods(apis),
+		conns:      make(map[string]map[io.Closer]struct{}),
+	}
+	store.OnRevoke(a.closeConns)
+
+	if auditFilename == "" {
+		return a, nil
+	}
+
+	f, err := os.OpenFile(auditFilename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	a.auditFile = f
+	a.auditEnc = json.NewEncoder(f)
+
+	return a, nil
+}
+
+/*
+Close closes the audit-log.
+*/
+func (a *Auth) Close() error {
+	a.auditLock.Lock()
+	defer a.auditLock.Unlock()
+
+	if a.auditFile == nil {
+		return nil
+	}
+
+	err := a.auditFile.Close()
+	a.auditFile = nil
+	a.auditEnc = nil
+
+	return err
+}
+
+/*
+Authenticate returns the token of the secret.
+*/
+func (a *Auth) Authenticate(secret string) (*Token, error) {
+	return a.store.Authenticate(secret)
+}
+
+/*
+addConn registers the open connection of the token, to be closed when the token is revoked.
+*/
+func (a *Auth) addConn(token *Token, conn io.Closer) {
+	a.connLock.Lock()
+	defer a.connLock.Unlock()
+
+	conns, ok := a.conns[token.ID]
+	if !ok {
+		conns = make(map[io.Closer]struct{})
+		a.conns[token.ID] = conns
+	}
+	conns[conn] = struct{}{}
+}
+
+func (a *Auth) removeConn(token *Token, conn io.Closer) {
+	a.connLock.Lock()
+	defer a.connLock.Unlock()
+
+	conns, ok := a.conns[token.ID]
+	if !ok {
+		return
+	}
+	delete(conns, conn)
+	if len(conns) == 0 {
+		delete(a.conns, token.ID)
+	}
+}
+
+/*
+closeConns closes the open connections of the revoked token.
+*/
+func (a *Auth) closeConns(token *Token) {
+	a.connLock.Lock()
+	conns := a.conns[token.ID]
+	delete(a.conns, token.ID)
+	a.connLock.Unlock()
+
+	for conn := range conns {
+		conn.Close()
+	}
+}
+
+/*
+IsPrivileged checks whether the method is from the non-public apis.
+*/
+func (a *Auth) IsPrivileged(method string) bool {
+	return a.privileged[method]
+}
+
+/*
+Check checks whether all the methods are permitted by the token
+and are not IPCOnlyMethods, and audits the privileged methods.
+
+The subscriptions are checked as the methods of the subscriptions (see headerMethods).
+The unsubscriptions are permitted for all the tokens, as the subscriptions
+are only able to be unsubscribed in the same connection.
+*/
+func (a *Auth) Check(token *Token, methods []string, transport string, remote string) error {
+	var err error
+	for _, method := range methods {
+		isAllowed := (token.IsPermitted(method) || strings.HasSuffix(method, SuffixUnsubscribe)) && !IPCOnlyMethods[method]
+		if !isAllowed {
+			err = ErrMethodNotPermitted
+		}
+
+		if a.IsPrivileged(method) {
+			a.audit(token, method, transport, remote, isAllowed)
+		}
+	}
+
+	return err
+}
+
+func (a *Auth) audit(token *Token, method string, transport string, remote string, isAllowed bool) {
+	record := &AuditRecord{
+		TS:        time.Now().UTC(),
+		Transport: transport,
+		Remote:    remote,
+		Method:    method,
+		IsAllowed: isAllowed,
+	}
+	if token != nil {
+		record.TokenID = token.ID
+		record.TokenName = token.Name
+	}
+
+	log.Info("rpc: privileged call", "transport", transport, "remote", remote, "token", record.TokenID, "method", method, "allowed", isAllowed)
+
+	a.auditLock.Lock()
+	defer a.auditLock.Unlock()
+
+	if a.auditEnc == nil {
+		return
+	}
+
+	err := a.auditEnc.Encode(record)
+	if err != nil {
+		log.Warn("rpc: unable to write audit", "e", err)
+	}
+}
+
+/*
+privilegedMethods collects the rpc-method-names of the non-public apis,
+following the naming of the go-ethereum rpc (namespace_lowerFirstMethod),
+and the subscribe / unsubscribe of the namespaces of the non-public apis.
+*/
+func privilegedMethods(apis []erpc.API) map[string]bool {
+	privileged := make(map[string]bool)
+	for _, api := range apis {
+		if api.Public {
+			continue
+		}
+
+		privileged[api.Namespace+SuffixSubscribe] = true
+		privileged[api.Namespace+SuffixUnsubscribe] = true
+
+		serviceType := reflect.TypeOf(api.Service)
+		for i := 0; i < serviceType.NumMethod(); i++ {
+			method := serviceType.Method(i)
+			if method.PkgPath != "" {
+				continue
+			}
+			privileged[api.Namespace+"_"+lowerFirst(method.Name)] = true
+		}
+	}
+
+	return privileged
+}
+
+func lowerFirst(name string) string {
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+type rpcRequestHeader struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+/*
+checkedMethod returns the method-name to be checked.
+
+The subscription (namespace_subscribe with the name of the subscription in params[0])
+is checked as namespace_name, the rpc-method-name of the subscription in the api.
+namespace_subscribe is returned if the name is unable to be parsed.
+*/
+func (h *rpcRequestHeader) checkedMethod() string {
+	if !strings.HasSuffix(h.Method, SuffixSubscribe) {
+		return h.Method
+	}
+
+	params := make([]json.RawMessage, 0)
+	if err := json.Unmarshal(h.Params, &params); err != nil || len(params) == 0 {
+		return h.Method
+	}
+
+	var name string
+	if err := json.Unmarshal(params[0], &name); err != nil || name == "" {
+		return h.Method
+	}
+
+	return strings.TrimSuffix(h.Method, SuffixSubscribe) + "_" + name
+}
+
+/*
+parseRequestHeaders parses the ids and the method-names of the single or the batch json-rpc request.
+*/
+func parseRequestHeaders(msg json.RawMessage) ([]*rpcRequestHeader, bool, error) {
+	trimmed := strings.TrimLeft(string(msg), " \t\r\n")
+	if !strings.HasPrefix(trimmed, "[") {
+		header := &rpcRequestHeader{}
+		err := json.Unmarshal(msg, header)
+		if err != nil {
+			return nil, false, err
+		}
+		return []*rpcRequestHeader{header}, false, nil
+	}
+
+	headers := make([]*rpcRequestHeader, 0)
+	err := json.Unmarshal(msg, &headers)
+	if err != nil {
+		return nil, true, err
+	}
+
+	return headers, true, nil
+}
+
+func headerMethods(headers []*rpcRequestHeader) []string {
+	methods := make([]string, len(headers))
+	for i, header := range headers {
+		methods[i] = header.checkedMethod()
+	}
+	return methods
+}
+
+type rpcErrorResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   *rpcError       `json:"error"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+/*
+errorResponses constructs the json-rpc error-responses (in batch if isBatch) for the rejected request.
+*/
+func errorResponses(headers []*rpcRequestHeader, isBatch bool, code int, err error) interface{} {
+	if len(headers) == 0 {
+		headers = []*rpcRequestHeader{&rpcRequestHeader{}}
+	}
+
+	responses := make([]*rpcErrorResponse, len(headers))
+	for i, header := range headers {
+		id := header.ID
+		if len(id) == 0 {
+			id = json.RawMessage("null")
+		}
+		responses[i] = &rpcErrorResponse{
+			Version: "2.0",
+			ID:      id,
+			Error:   &rpcError{Code: code, Message: err.Error()},
+		}
+	}
+
+	if !isBatch {
+		return responses[0]
+	}
+	return responses
+}
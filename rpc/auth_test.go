@@ -0,0 +1,423 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	erpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+type TestService struct{}
+
+func (s *TestService) GetName() string {
+	return "name"
+}
+
+func (s *TestService) Revoke() bool {
+	return true
+}
+
+func (s *TestService) FriendRequests(ctx context.Context) (*erpc.Subscription, error) {
+	notifier, ok := erpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, erpc.ErrNotificationsUnsupported
+	}
+
+	return notifier.CreateSubscription(), nil
+}
+
+func testAuthAPIs() []erpc.API {
+	return []erpc.API{
+		{Namespace: "friend", Version: "1.0", Service: &TestService{}, Public: false},
+		{Namespace: "me", Version: "1.0", Service: &TestService{}, Public: false},
+		{Namespace: "pub", Version: "1.0", Service: &TestService{}, Public: true},
+	}
+}
+
+func testNewAuth(t *testing.T) (*Auth, *TokenStore, string) {
+	dir, err := ioutil.TempDir("", "test-rpc-auth")
+	assert.NoError(t, err)
+
+	store, err := NewTokenStore(filepath.Join(dir, "tokens.json"))
+	assert.NoError(t, err)
+
+	auth, err := NewAuth(store, testAuthAPIs(), filepath.Join(dir, "audit.log"))
+	assert.NoError(t, err)
+
+	return auth, store, dir
+}
+
+func TestTokenIsPermitted(t *testing.T) {
+	tests := []struct {
+		perm     string
+		method   string
+		expected bool
+	}{
+		{"*", "me_revoke", true},
+		{"friend", "friend_getFriend", true},
+		{"friend", "friendx_getFriend", false},
+		{"friend_get*", "friend_getFriendList", true},
+		{"friend_get*", "friend_deleteFriend", false},
+		{"ptt_shutdown", "ptt_shutdown", true},
+		{"ptt_shutdown", "ptt_shutdownAll", false},
+	}
+
+	for _, each := range tests {
+		token := &Token{Permissions: []string{each.perm}}
+		assert.Equal(t, each.expected, token.IsPermitted(each.method), each.perm+" "+each.method)
+	}
+}
+
+func TestTokenStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-rpc-token")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "tokens.json")
+	store, err := NewTokenStore(filename)
+	assert.NoError(t, err)
+
+	_, _, err = store.Issue("invalid", nil)
+	assert.Equal(t, ErrInvalidPermission, err)
+	_, _, err = store.Issue("invalid", []string{"friend_[get"})
+	assert.Equal(t, ErrInvalidPermission, err)
+
+	secret, token, err := store.Issue("reader", []string{"friend_get*"})
+	assert.NoError(t, err)
+	assert.Equal(t, SizeTokenSecret*2, len(secret))
+	assert.Equal(t, SizeTokenID*2, len(token.ID))
+	assert.NotContains(t, token.Hash, secret)
+
+	info, err := os.Stat(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// reload
+	store2, err := NewTokenStore(filename)
+	assert.NoError(t, err)
+	token2, err := store2.Authenticate(secret)
+	assert.NoError(t, err)
+	assert.Equal(t, token.ID, token2.ID)
+	assert.Equal(t, token.Permissions, token2.Permissions)
+
+	_, err = store2.Authenticate("invalid")
+	assert.Equal(t, ErrInvalidToken, err)
+	_, err = store2.Authenticate("")
+	assert.Equal(t, ErrInvalidToken, err)
+
+	// revoke
+	assert.Equal(t, ErrTokenNotFound, store2.Revoke("invalid"))
+	assert.NoError(t, store2.Revoke(token.ID))
+	assert.Equal(t, 0, len(store2.List()))
+
+	store3, err := NewTokenStore(filename)
+	assert.NoError(t, err)
+	_, err = store3.Authenticate(secret)
+	assert.Equal(t, ErrInvalidToken, err)
+}
+
+func TestHTTPHandler(t *testing.T) {
+	auth, store, dir := testNewAuth(t)
+	defer os.RemoveAll(dir)
+	defer auth.Close()
+
+	assert.True(t, auth.IsPrivileged("friend_revoke"))
+	assert.True(t, auth.IsPrivileged("friend_getName"))
+	assert.False(t, auth.IsPrivileged("pub_revoke"))
+
+	srv := erpc.NewServer()
+	for _, api := range testAuthAPIs() {
+		assert.NoError(t, srv.RegisterName(api.Namespace, api.Service))
+	}
+	defer srv.Stop()
+
+	ts := httptest.NewServer(auth.HTTPHandler(srv))
+	defer ts.Close()
+
+	secret, token, err := store.Issue("reader", []string{"friend_get*", "pub"})
+	assert.NoError(t, err)
+
+	call := func(secret string, isQuery bool, body string) (int, string) {
+		url := ts.URL
+		if isQuery {
+			url += "?" + QueryToken + "=" + secret
+		}
+		req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if !isQuery && secret != "" {
+			req.Header.Set(HeaderAuthorization, AuthSchemeBearer+" "+secret)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		respBody, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		return resp.StatusCode, string(respBody)
+	}
+
+	// no token
+	status, body := call("", false, `{"jsonrpc":"2.0","id":1,"method":"friend_getName"}`)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Contains(t, body, "-32001")
+
+	// permitted
+	status, body = call(secret, false, `{"jsonrpc":"2.0","id":1,"method":"friend_getName"}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"result":"name"`)
+
+	status, body = call(secret, true, `{"jsonrpc":"2.0","id":1,"method":"pub_revoke"}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"result":true`)
+
+	// not permitted
+	status, body = call(secret, false, `{"jsonrpc":"2.0","id":2,"method":"friend_revoke"}`)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Contains(t, body, `"id":2`)
+	assert.Contains(t, body, "-32003")
+
+	status, _ = call(secret, false, `[{"jsonrpc":"2.0","id":1,"method":"friend_getName"},{"jsonrpc":"2.0","id":2,"method":"friend_revoke"}]`)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// malformed
+	status, body = call(secret, false, `{"jsonrpc":"2.0","id":1,"method":`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, "-32700")
+
+	status, body = call(secret, false, `[{"jsonrpc":"2.0","id":1,"method":"friend_revoke"},1]`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, "-32700")
+
+	// ipc-only
+	adminSecret, _, err := store.Issue("admin", []string{"*"})
+	assert.NoError(t, err)
+
+	status, body = call(adminSecret, false, `{"jsonrpc":"2.0","id":3,"method":"admin_issueToken","params":["all",["*"]]}`)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Contains(t, body, "-32003")
+
+	// audit
+	auth.Close()
+	audit, err := ioutil.ReadFile(filepath.Join(dir, "audit.log"))
+	assert.NoError(t, err)
+
+	records := make([]*AuditRecord, 0)
+	dec := json.NewDecoder(bytes.NewReader(audit))
+	for dec.More() {
+		record := &AuditRecord{}
+		assert.NoError(t, dec.Decode(record))
+		records = append(records, record)
+	}
+	assert.Equal(t, 4, len(records))
+	assert.Equal(t, "friend_getName", records[0].Method)
+	assert.True(t, records[0].IsAllowed)
+	assert.Equal(t, token.ID, records[0].TokenID)
+	assert.Equal(t, "friend_revoke", records[1].Method)
+	assert.False(t, records[1].IsAllowed)
+	assert.Equal(t, TransportHTTP, records[1].Transport)
+}
+
+func TestWebsocketHandler(t *testing.T) {
+	auth, store, dir := testNewAuth(t)
+	defer os.RemoveAll(dir)
+	defer auth.Close()
+
+	srv := erpc.NewServer()
+	for _, api := range testAuthAPIs() {
+		assert.NoError(t, srv.RegisterName(api.Namespace, api.Service))
+	}
+	defer srv.Stop()
+
+	ts := httptest.NewServer(auth.WebsocketHandler(srv, []string{"*"}))
+	defer ts.Close()
+
+	secret, _, err := store.Issue("reader", []string{"friend_get*"})
+	assert.NoError(t, err)
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http")
+
+	// no token
+	_, err = websocket.Dial(wsURL, "", "http://localhost")
+	assert.Error(t, err)
+
+	conn, err := websocket.Dial(wsURL+"?"+QueryToken+"="+secret, "", "http://localhost")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	call := func(req string) string {
+		assert.NoError(t, websocket.Message.Send(conn, req))
+		var resp string
+		assert.NoError(t, websocket.Message.Receive(conn, &resp))
+		return resp
+	}
+
+	resp := call(`{"jsonrpc":"2.0","id":1,"method":"friend_revoke"}`)
+	assert.Contains(t, resp, "-32003")
+
+	resp = call(`{"jsonrpc":"2.0","id":1,"method":`)
+	assert.Contains(t, resp, "-32700")
+
+	// the connection is still served after the rejected request
+	resp = call(`{"jsonrpc":"2.0","id":2,"method":"friend_getName"}`)
+	assert.Contains(t, resp, `"result":"name"`)
+}
+
+func TestWebsocketSubscription(t *testing.T) {
+	auth, store, dir := testNewAuth(t)
+	defer os.RemoveAll(dir)
+	defer auth.Close()
+
+	assert.True(t, auth.IsPrivileged("me_subscribe"))
+	assert.True(t, auth.IsPrivileged("me_unsubscribe"))
+	assert.True(t, auth.IsPrivileged("me_friendRequests"))
+
+	srv := erpc.NewServer()
+	for _, api := range testAuthAPIs() {
+		assert.NoError(t, srv.RegisterName(api.Namespace, api.Service))
+	}
+	defer srv.Stop()
+
+	ts := httptest.NewServer(auth.WebsocketHandler(srv, []string{"*"}))
+	defer ts.Close()
+
+	secret, _, err := store.Issue("friend", []string{"friend"})
+	assert.NoError(t, err)
+
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"?"+QueryToken+"="+secret, "", "http://localhost")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	call := func(req string) string {
+		assert.NoError(t, websocket.Message.Send(conn, req))
+		var resp string
+		assert.NoError(t, websocket.Message.Receive(conn, &resp))
+		return resp
+	}
+
+	// subscription of the other namespace
+	resp := call(`{"jsonrpc":"2.0","id":1,"method":"me_subscribe","params":["friendRequests"]}`)
+	assert.Contains(t, resp, "-32003")
+
+	resp = call(`{"jsonrpc":"2.0","id":2,"method":"me_subscribe","params":[1]}`)
+	assert.Contains(t, resp, "-32003")
+
+	// subscription of the namespace
+	resp = call(`{"jsonrpc":"2.0","id":3,"method":"friend_subscribe","params":["friendRequests"]}`)
+	assert.NotContains(t, resp, "error")
+
+	subResp := &struct {
+		Result string `json:"result"`
+	}{}
+	assert.NoError(t, json.Unmarshal([]byte(resp), subResp))
+
+	resp = call(`{"jsonrpc":"2.0","id":4,"method":"friend_unsubscribe","params":["` + subResp.Result + `"]}`)
+	assert.Contains(t, resp, `"result":true`)
+
+	// unsubscribe is permitted, the subscriptions are within the connection.
+	resp = call(`{"jsonrpc":"2.0","id":5,"method":"me_unsubscribe","params":["0x1"]}`)
+	assert.NotContains(t, resp, "-32003")
+
+	// audit
+	auth.Close()
+	audit, err := ioutil.ReadFile(filepath.Join(dir, "audit.log"))
+	assert.NoError(t, err)
+
+	records := make([]*AuditRecord, 0)
+	dec := json.NewDecoder(bytes.NewReader(audit))
+	for dec.More() {
+		record := &AuditRecord{}
+		assert.NoError(t, dec.Decode(record))
+		records = append(records, record)
+	}
+	assert.Equal(t, 5, len(records))
+	assert.Equal(t, "me_friendRequests", records[0].Method)
+	assert.False(t, records[0].IsAllowed)
+	assert.Equal(t, "me_subscribe", records[1].Method)
+	assert.False(t, records[1].IsAllowed)
+	assert.Equal(t, "friend_friendRequests", records[2].Method)
+	assert.True(t, records[2].IsAllowed)
+	assert.Equal(t, "friend_unsubscribe", records[3].Method)
+	assert.Equal(t, "me_unsubscribe", records[4].Method)
+	assert.True(t, records[4].IsAllowed)
+}
+
+func TestWebsocketRevoke(t *testing.T) {
+	auth, store, dir := testNewAuth(t)
+	defer os.RemoveAll(dir)
+	defer auth.Close()
+
+	srv := erpc.NewServer()
+	for _, api := range testAuthAPIs() {
+		assert.NoError(t, srv.RegisterName(api.Namespace, api.Service))
+	}
+	defer srv.Stop()
+
+	ts := httptest.NewServer(auth.WebsocketHandler(srv, []string{"*"}))
+	defer ts.Close()
+
+	secret, token, err := store.Issue("friend", []string{"friend"})
+	assert.NoError(t, err)
+	secret2, _, err := store.Issue("friend2", []string{"friend"})
+	assert.NoError(t, err)
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "?" + QueryToken + "="
+	conn, err := websocket.Dial(wsURL+secret, "", "http://localhost")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	conn2, err := websocket.Dial(wsURL+secret2, "", "http://localhost")
+	assert.NoError(t, err)
+	defer conn2.Close()
+
+	call := func(conn *websocket.Conn, req string) (string, error) {
+		if err := websocket.Message.Send(conn, req); err != nil {
+			return "", err
+		}
+		var resp string
+		err := websocket.Message.Receive(conn, &resp)
+		return resp, err
+	}
+
+	resp, err := call(conn, `{"jsonrpc":"2.0","id":1,"method":"friend_getName"}`)
+	assert.NoError(t, err)
+	assert.Contains(t, resp, `"result":"name"`)
+
+	// revoke while connected
+	assert.NoError(t, store.Revoke(token.ID))
+
+	_, err = call(conn, `{"jsonrpc":"2.0","id":2,"method":"friend_getName"}`)
+	assert.Error(t, err)
+
+	// the connections of the other tokens are not affected.
+	resp, err = call(conn2, `{"jsonrpc":"2.0","id":3,"method":"friend_getName"}`)
+	assert.NoError(t, err)
+	assert.Contains(t, resp, `"result":"name"`)
+}
@@ -24,7 +24,8 @@ import (
 )
 
 // StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules
-func StartHTTPEndpoint(endpoint string, apis []erpc.API, modules []string, cors []string, vhosts []string, timeouts erpc.HTTPTimeouts) (net.Listener, *erpc.Server, *http.Server, error) {
+// The requests are token-authenticated if auth is not nil.
+func StartHTTPEndpoint(endpoint string, apis []erpc.API, modules []string, cors []string, vhosts []string, timeouts erpc.HTTPTimeouts, auth *Auth) (net.Listener, *erpc.Server, *http.Server, error) {
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
 	for _, module := range modules {
@@ -48,6 +49,45 @@ func StartHTTPEndpoint(endpoint string, apis []erpc.API, modules []string, cors
 		return nil, nil, nil, err
 	}
 	httpServer := erpc.NewHTTPServer(cors, vhosts, timeouts, handler)
+	if auth != nil {
+		httpServer.Handler = auth.HTTPHandler(httpServer.Handler)
+	}
 	go httpServer.Serve(listener)
 	return listener, handler, httpServer, err
 }
+
+// StartWSEndpoint starts the websocket RPC endpoint, configured with origins/modules
+// The connections are token-authenticated if auth is not nil.
+func StartWSEndpoint(endpoint string, apis []erpc.API, modules []string, wsOrigins []string, exposeAll bool, auth *Auth) (net.Listener, *erpc.Server, error) {
+	// Generate the whitelist based on the allowed modules
+	whitelist := make(map[string]bool)
+	for _, module := range modules {
+		whitelist[module] = true
+	}
+	// Register all the APIs exposed by the services
+	handler := erpc.NewServer()
+	for _, api := range apis {
+		if exposeAll || whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
+			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	// All APIs registered, start the HTTP listener
+	var (
+		listener net.Listener
+		err      error
+	)
+	if listener, err = net.Listen("tcp", endpoint); err != nil {
+		return nil, nil, err
+	}
+
+	var wsHandler http.Handler
+	if auth != nil {
+		wsHandler = auth.WebsocketHandler(handler, wsOrigins)
+	} else {
+		wsHandler = handler.WebsocketHandler(wsOrigins)
+	}
+	go (&http.Server{Handler: wsHandler}).Serve(listener)
+	return listener, handler, err
+}
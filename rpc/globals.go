@@ -0,0 +1,51 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+const (
+	SizeTokenSecret = 32
+	SizeTokenID     = 8
+
+	// MaxRequestContentLength is the same as the limit of the go-ethereum rpc.
+	MaxRequestContentLength = 1024 * 512
+
+	HeaderAuthorization = "Authorization"
+	AuthSchemeBearer    = "Bearer"
+	QueryToken          = "token"
+
+	// json-rpc error-codes for the rejected requests.
+	ErrCodeParse        = -32700
+	ErrCodeUnauthorized = -32001
+	ErrCodeForbidden    = -32003
+
+	// the rpc-method-suffixes of the subscriptions of the go-ethereum rpc.
+	SuffixSubscribe   = "_subscribe"
+	SuffixUnsubscribe = "_unsubscribe"
+
+	TransportHTTP = "http"
+	TransportWS   = "ws"
+)
+
+var (
+	// IPCOnlyMethods are not permitted over the HTTP/websocket RPC by any token,
+	// so that a token is unable to issue the tokens with more permissions.
+	IPCOnlyMethods = map[string]bool{
+		"admin_issueToken":  true,
+		"admin_revokeToken": true,
+		"admin_listTokens":  true,
+	}
+)
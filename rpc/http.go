@@ -0,0 +1,92 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+/*
+HTTPHandler wraps the http rpc-handler with the token-authentication and the permission-check.
+The token is from the bearer authorization-header or from the token query-parameter.
+*/
+func (a *Auth) HTTPHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// cors-preflight and health-check are handled by the rpc-handler.
+		if r.Method == http.MethodOptions || (r.Method == http.MethodGet && r.ContentLength == 0) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, err := a.Authenticate(tokenFromRequest(r))
+		if err != nil {
+			writeHTTPError(w, http.StatusUnauthorized, nil, false, ErrCodeUnauthorized, err)
+			return
+		}
+
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, MaxRequestContentLength+1))
+		r.Body.Close()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(body) > MaxRequestContentLength {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		// the requests unable to be checked are rejected.
+		headers, isBatch, err := parseRequestHeaders(body)
+		if err != nil {
+			writeHTTPError(w, http.StatusBadRequest, nil, false, ErrCodeParse, err)
+			return
+		}
+
+		err = a.Check(token, headerMethods(headers), TransportHTTP, r.RemoteAddr)
+		if err != nil {
+			writeHTTPError(w, http.StatusForbidden, headers, isBatch, ErrCodeForbidden, err)
+			return
+		}
+
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+func tokenFromRequest(r *http.Request) string {
+	authorization := r.Header.Get(HeaderAuthorization)
+	if strings.HasPrefix(authorization, AuthSchemeBearer+" ") {
+		return strings.TrimSpace(authorization[len(AuthSchemeBearer)+1:])
+	}
+
+	return r.URL.Query().Get(QueryToken)
+}
+
+func writeHTTPError(w http.ResponseWriter, status int, headers []*rpcRequestHeader, isBatch bool, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", AuthSchemeBearer)
+	}
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(errorResponses(headers, isBatch, code, err))
+}
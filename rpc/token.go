@@ -0,0 +1,296 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+)
+
+/*
+Token is the api-token scoped to a set of permissions.
+
+Only the sha256-hash of the secret is stored.
+A permission is one of:
+ 1. "*": all the methods.
+ 2. namespace (ex: "friend"): all the methods of the namespace.
+ 3. method-pattern (ex: "friend_get*"): the methods matching the pattern (path.Match).
+*/
+type Token struct {
+	ID          string          `json:"ID"`
+	Name        string          `json:"N"`
+	Hash        string          `json:"H"`
+	Permissions []string        `json:"P"`
+	CreateTS    types.Timestamp `json:"CT"`
+}
+
+/*
+IsPermitted checks whether the method is permitted by the permissions of the token.
+*/
+func (t *Token) IsPermitted(method string) bool {
+	for _, perm := range t.Permissions {
+		if matchPermission(perm, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPermission(perm string, method string) bool {
+	if perm == "*" {
+		return true
+	}
+
+	if !strings.ContainsAny(perm, "_*?[") {
+		return strings.HasPrefix(method, perm+"_")
+	}
+
+	isMatch, _ := path.Match(perm, method)
+	return isMatch
+}
+
+func validatePermission(perm string) error {
+	if perm == "" {
+		return ErrInvalidPermission
+	}
+
+	if _, err := path.Match(perm, ""); err != nil {
+		return ErrInvalidPermission
+	}
+
+	return nil
+}
+
+/*
+TokenStore is the file-backed store of the api-tokens.
+*/
+type TokenStore struct {
+	lock sync.RWMutex
+
+	filename string
+
+	tokens map[string]*Token // by hash
+
+	onRevokes []func(token *Token)
+}
+
+/*
+NewTokenStore loads the tokens from filename. The file is created when the first token is issued.
+The tokens are in-memory only if filename is empty.
+*/
+func NewTokenStore(filename string) (*TokenStore, error) {
+	s := &TokenStore{
+		filename: filename,
+		tokens:   make(map[string]*Token),
+	}
+	if filename == "" {
+		return s, nil
+	}
+
+	content, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*Token, 0)
+	err = json.Unmarshal(content, &tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, token := range tokens {
+		s.tokens[token.Hash] = token
+	}
+
+	return s, nil
+}
+
+/*
+Issue issues a new token with the permissions.
+Returns the secret, which is not stored and is shown only once.
+*/
+func (s *TokenStore) Issue(name string, permissions []string) (string, *Token, error) {
+	if len(permissions) == 0 {
+		return "", nil, ErrInvalidPermission
+	}
+	for _, perm := range permissions {
+		if err := validatePermission(perm); err != nil {
+			return "", nil, err
+		}
+	}
+
+	secretBytes := make([]byte, SizeTokenSecret)
+	_, err := io.ReadFull(rand.Reader, secretBytes)
+	if err != nil {
+		return "", nil, err
+	}
+	secret := hex.EncodeToString(secretBytes)
+
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return "", nil, err
+	}
+
+	hash := hashSecret(secret)
+	token := &Token{
+		ID:          hash[:SizeTokenID*2],
+		Name:        name,
+		Hash:        hash,
+		Permissions: permissions,
+		CreateTS:    ts,
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.tokens[hash] = token
+
+	err = s.save()
+	if err != nil {
+		delete(s.tokens, hash)
+		return "", nil, err
+	}
+
+	return secret, token, nil
+}
+
+/*
+Revoke revokes the token by id.
+*/
+func (s *TokenStore) Revoke(id string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var token *Token
+	for _, eachToken := range s.tokens {
+		if eachToken.ID == id {
+			token = eachToken
+			break
+		}
+	}
+	if token == nil {
+		return ErrTokenNotFound
+	}
+
+	delete(s.tokens, token.Hash)
+
+	err := s.save()
+	if err != nil {
+		s.tokens[token.Hash] = token
+		return err
+	}
+
+	for _, onRevoke := range s.onRevokes {
+		onRevoke(token)
+	}
+
+	return nil
+}
+
+/*
+OnRevoke registers f to be called with the token after the token is revoked.
+*/
+func (s *TokenStore) OnRevoke(f func(token *Token)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.onRevokes = append(s.onRevokes, f)
+}
+
+/*
+List lists the tokens (sorted by create-ts).
+*/
+func (s *TokenStore) List() []*Token {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	tokens := make([]*Token, 0, len(s.tokens))
+	for _, token := range s.tokens {
+		tokens = append(tokens, token)
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreateTS.IsLess(tokens[j].CreateTS)
+	})
+
+	return tokens
+}
+
+/*
+Authenticate returns the token of the secret.
+*/
+func (s *TokenStore) Authenticate(secret string) (*Token, error) {
+	if secret == "" {
+		return nil, ErrInvalidToken
+	}
+
+	hash := hashSecret(secret)
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	token, ok := s.tokens[hash]
+	if !ok || subtle.ConstantTimeCompare([]byte(token.Hash), []byte(hash)) != 1 {
+		return nil, ErrInvalidToken
+	}
+
+	return token, nil
+}
+
+func (s *TokenStore) save() error {
+	if s.filename == "" {
+		return nil
+	}
+
+	tokens := make([]*Token, 0, len(s.tokens))
+	for _, token := range s.tokens {
+		tokens = append(tokens, token)
+	}
+
+	marshaled, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	tmpFilename := s.filename + ".tmp"
+	err = ioutil.WriteFile(tmpFilename, marshaled, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFilename, s.filename)
+}
+
+func hashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
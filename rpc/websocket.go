@@ -0,0 +1,147 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ailabstw/go-pttai-core/log"
+	erpc "github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/net/websocket"
+)
+
+/*
+WebsocketHandler serves the websocket json-rpc with the token-authentication and the permission-check.
+
+The token is checked in the handshake and is looked up again for each message,
+and the connection is closed when the token is revoked.
+The requests with not-permitted methods are responded with the json-rpc errors
+and are not passed to the rpc-server.
+*/
+func (a *Auth) WebsocketHandler(srv *erpc.Server, allowedOrigins []string) http.Handler {
+	isValidOrigin := wsOriginValidator(allowedOrigins)
+
+	return websocket.Server{
+		Handshake: func(cfg *websocket.Config, r *http.Request) error {
+			if err := isValidOrigin(r); err != nil {
+				return err
+			}
+			_, err := a.Authenticate(tokenFromRequest(r))
+			return err
+		},
+		Handler: func(conn *websocket.Conn) {
+			conn.MaxPayloadBytes = MaxRequestContentLength
+
+			r := conn.Request()
+			secret := tokenFromRequest(r)
+			token, err := a.Authenticate(secret)
+			if err != nil {
+				conn.Close()
+				return
+			}
+
+			a.addConn(token, conn)
+			defer a.removeConn(token, conn)
+
+			var encLock sync.Mutex
+			encoder := func(v interface{}) error {
+				encLock.Lock()
+				defer encLock.Unlock()
+
+				return websocket.JSON.Send(conn, v)
+			}
+			decoder := func(v interface{}) error {
+				for {
+					var msg []byte
+					if err := websocket.Message.Receive(conn, &msg); err != nil {
+						return err
+					}
+
+					headers, isBatch, err := parseRequestHeaders(msg)
+					if err != nil {
+						if err := encoder(errorResponses(nil, false, ErrCodeParse, err)); err != nil {
+							return err
+						}
+						continue
+					}
+
+					// the token may be revoked after the handshake.
+					token, err := a.Authenticate(secret)
+					if err != nil {
+						encoder(errorResponses(headers, isBatch, ErrCodeUnauthorized, err))
+						return err
+					}
+
+					err = a.Check(token, headerMethods(headers), TransportWS, r.RemoteAddr)
+					if err != nil {
+						if err := encoder(errorResponses(headers, isBatch, ErrCodeForbidden, err)); err != nil {
+							return err
+						}
+						continue
+					}
+
+					if raw, ok := v.(*json.RawMessage); ok {
+						*raw = msg
+						return nil
+					}
+					return json.Unmarshal(msg, v)
+				}
+			}
+
+			srv.ServeCodec(erpc.NewCodec(conn, encoder, decoder), erpc.OptionMethodInvocation|erpc.OptionSubscriptions)
+		},
+	}
+}
+
+/*
+wsOriginValidator follows the origin-check of the go-ethereum websocket rpc.
+Allows http://localhost and http://hostname if no origins are specified.
+*/
+func wsOriginValidator(allowedOrigins []string) func(r *http.Request) error {
+	origins := make(map[string]bool)
+	isAllowAll := false
+
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			isAllowAll = true
+		}
+		if origin != "" {
+			origins[strings.ToLower(origin)] = true
+		}
+	}
+
+	if len(origins) == 0 {
+		origins["http://localhost"] = true
+		if hostname, err := os.Hostname(); err == nil {
+			origins["http://"+strings.ToLower(hostname)] = true
+		}
+	}
+
+	return func(r *http.Request) error {
+		origin := strings.ToLower(r.Header.Get("Origin"))
+		if isAllowAll || origins[origin] {
+			return nil
+		}
+
+		log.Warn("rpc: origin not allowed on ws", "origin", origin)
+		return ErrInvalidOrigin
+	}
+}
@@ -0,0 +1,24 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package search implements a local inverted index on top of pttdb.
+
+Texts are tokenized with Tokenize. Latin words are lower-cased as a whole,
+while CJK texts (PTT users write mostly Chinese) are indexed as unigrams and bigrams,
+so that a query can match in the middle of a sentence without word-segmentation.
+*/
+package search
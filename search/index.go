@@ -0,0 +1,247 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package search
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/ailabstw/go-pttai-core/common"
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+)
+
+/*
+Doc is the reference of the indexed object. ScopeID is the entity the object belongs to.
+*/
+type Doc struct {
+	ScopeID *types.PttID
+	ID      *types.PttID
+}
+
+/*
+Index is the inverted index stored under its own prefix in a pttdb.Storage.
+
+	token-key: prefix + "t" + token + 0x00 + scope-id + doc-id
+	doc-key:   prefix + "d" + scope-id + doc-id => the tokens of the doc
+*/
+type Index struct {
+	db pttdb.Storage
+
+	tokenPrefix []byte
+	docPrefix   []byte
+
+	lock sync.Mutex
+}
+
+func NewIndex(db pttdb.Storage, prefix []byte) (*Index, error) {
+	tokenPrefix, err := common.Concat([][]byte{prefix, tokenPostfix})
+	if err != nil {
+		return nil, err
+	}
+
+	docPrefix, err := common.Concat([][]byte{prefix, docPostfix})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Index{
+		db:          db,
+		tokenPrefix: tokenPrefix,
+		docPrefix:   docPrefix,
+	}, nil
+}
+
+/*
+Put (re-)indexes the doc with the texts. A nil scopeID is treated as the empty-id.
+*/
+func (idx *Index) Put(scopeID *types.PttID, docID *types.PttID, texts ...[]byte) error {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	err := idx.deleteCore(scopeID, docID)
+	if err != nil {
+		return err
+	}
+
+	tokens := Tokenize(texts...)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	for _, token := range tokens {
+		key, err := idx.tokenKey(token, scopeID, docID)
+		if err != nil {
+			return err
+		}
+
+		err = idx.db.Put(key, docID[:])
+		if err != nil {
+			return err
+		}
+	}
+
+	docKey, err := idx.docKey(scopeID, docID)
+	if err != nil {
+		return err
+	}
+
+	marshaled, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	return idx.db.Put(docKey, marshaled)
+}
+
+func (idx *Index) Delete(scopeID *types.PttID, docID *types.PttID) error {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	return idx.deleteCore(scopeID, docID)
+}
+
+func (idx *Index) deleteCore(scopeID *types.PttID, docID *types.PttID) error {
+	docKey, err := idx.docKey(scopeID, docID)
+	if err != nil {
+		return err
+	}
+
+	val, err := idx.db.Get(docKey)
+	if err == pttdb.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var tokens []string
+	err = json.Unmarshal(val, &tokens)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		key, err := idx.tokenKey(token, scopeID, docID)
+		if err != nil {
+			return err
+		}
+		idx.db.Delete(key)
+	}
+
+	return idx.db.Delete(docKey)
+}
+
+/*
+Search returns the docs containing all the tokens of the query.
+The docs are restricted in scopeID if scopeID is not nil. limit <= 0 means no limit.
+*/
+func (idx *Index) Search(query string, scopeID *types.PttID, limit int) ([]*Doc, error) {
+	tokens := TokenizeQuery(query)
+	if len(tokens) == 0 {
+		return nil, ErrInvalidQuery
+	}
+
+	// iterate through the most specific token and check the others.
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return len(tokens[i]) > len(tokens[j])
+	})
+
+	prefix, err := idx.tokenScopePrefix(tokens[0], scopeID)
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := idx.db.NewIteratorWithPrefix(nil, prefix, pttdb.ListOrderNext)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Release()
+
+	docs := make([]*Doc, 0)
+	for iter.Next() {
+		if limit > 0 && len(docs) >= limit {
+			break
+		}
+
+		doc, err := keyToDoc(iter.Key())
+		if err != nil {
+			continue
+		}
+
+		if !idx.isDocWithTokens(doc, tokens[1:]) {
+			continue
+		}
+
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+func (idx *Index) isDocWithTokens(doc *Doc, tokens []string) bool {
+	for _, token := range tokens {
+		key, err := idx.tokenKey(token, doc.ScopeID, doc.ID)
+		if err != nil {
+			return false
+		}
+
+		isExists, err := idx.db.Has(key)
+		if err != nil || !isExists {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (idx *Index) tokenScopePrefix(token string, scopeID *types.PttID) ([]byte, error) {
+	if scopeID == nil {
+		return common.Concat([][]byte{idx.tokenPrefix, []byte(token), tokenSeparator})
+	}
+	return common.Concat([][]byte{idx.tokenPrefix, []byte(token), tokenSeparator, scopeID[:]})
+}
+
+func (idx *Index) tokenKey(token string, scopeID *types.PttID, docID *types.PttID) ([]byte, error) {
+	if scopeID == nil {
+		scopeID = &types.EmptyID
+	}
+	return common.Concat([][]byte{idx.tokenPrefix, []byte(token), tokenSeparator, scopeID[:], docID[:]})
+}
+
+func (idx *Index) docKey(scopeID *types.PttID, docID *types.PttID) ([]byte, error) {
+	if scopeID == nil {
+		scopeID = &types.EmptyID
+	}
+	return common.Concat([][]byte{idx.docPrefix, scopeID[:], docID[:]})
+}
+
+func keyToDoc(key []byte) (*Doc, error) {
+	lenKey := len(key)
+	if lenKey < types.SizePttID*2 {
+		return nil, ErrInvalidKey
+	}
+
+	scopeID := &types.PttID{}
+	copy(scopeID[:], key[lenKey-types.SizePttID*2:lenKey-types.SizePttID])
+
+	docID := &types.PttID{}
+	copy(docID[:], key[lenKey-types.SizePttID:])
+
+	return &Doc{ScopeID: scopeID, ID: docID}, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+)
+
+func TestIndex(t *testing.T) {
+	// setup test
+	dataDir := "./test.out"
+	defer pttdb.DropMemDatabases(dataDir)
+
+	db, err := pttdb.NewStorageWithEngine(pttdb.EngineMemory, "search", dataDir)
+	if err != nil {
+		t.Errorf("unable to new storage: e: %v", err)
+		return
+	}
+	defer db.Close()
+
+	idx, err := NewIndex(db, []byte(".test"))
+	if err != nil {
+		t.Errorf("unable to new index: e: %v", err)
+		return
+	}
+
+	scopeID := &types.PttID{1}
+	doc1 := &types.PttID{2}
+	doc2 := &types.PttID{3}
+
+	idx.Put(scopeID, doc1, []byte("今天去批踢踢"), []byte("hello"))
+	idx.Put(scopeID, doc2, []byte("批踢踢實業坊"))
+
+	// search
+	got, err := idx.Search("批踢", nil, 0)
+	want := []*Doc{{ScopeID: scopeID, ID: doc1}, {ScopeID: scopeID, ID: doc2}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Search() = %v, want %v e: %v", got, want, err)
+	}
+
+	got, err = idx.Search("踢踢 HELLO", scopeID, 0)
+	want = []*Doc{{ScopeID: scopeID, ID: doc1}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Search() = %v, want %v e: %v", got, want, err)
+	}
+
+	got, _ = idx.Search("批踢", &types.PttID{4}, 0)
+	if len(got) != 0 {
+		t.Errorf("Search() with other scope = %v, want empty", got)
+	}
+
+	// re-index
+	idx.Put(scopeID, doc1, []byte("goodbye"))
+	got, _ = idx.Search("hello", nil, 0)
+	if len(got) != 0 {
+		t.Errorf("Search() after re-index = %v, want empty", got)
+	}
+
+	// delete
+	idx.Delete(scopeID, doc2)
+	got, _ = idx.Search("實業", nil, 0)
+	if len(got) != 0 {
+		t.Errorf("Search() after delete = %v, want empty", got)
+	}
+
+	_, err = idx.Search(" ", nil, 0)
+	if err != ErrInvalidQuery {
+		t.Errorf("Search() with empty query: e: %v, want %v", err, ErrInvalidQuery)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package search
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+type runeClass int
+
+const (
+	runeClassSeparator runeClass = iota
+	runeClassWord
+	runeClassCJK
+)
+
+/*
+Tokenize splits the texts into the tokens to be indexed.
+Words are lower-cased, and the runs of CJK characters are split into unigrams and bigrams.
+*/
+func Tokenize(texts ...[]byte) []string {
+	return tokenize(texts, true)
+}
+
+/*
+TokenizeQuery splits the query into the tokens to be looked up.
+Runs of CJK characters are split into bigrams only, except the single-character runs.
+*/
+func TokenizeQuery(query string) []string {
+	return tokenize([][]byte{[]byte(query)}, false)
+}
+
+func tokenize(texts [][]byte, isIndex bool) []string {
+	tokens := make([]string, 0)
+	exists := make(map[string]bool)
+
+	addToken := func(token []rune) {
+		str := string(token)
+		if len(str) > MaxTokenLength {
+			str = truncate(str, MaxTokenLength)
+		}
+		if exists[str] {
+			return
+		}
+		exists[str] = true
+		tokens = append(tokens, str)
+	}
+
+	for _, text := range texts {
+		var word []rune
+		var cjk []rune
+
+		flush := func() {
+			if len(word) > 0 {
+				addToken(word)
+				word = nil
+			}
+			if len(cjk) > 0 {
+				addCJKTokens(cjk, isIndex, addToken)
+				cjk = nil
+			}
+		}
+
+		for _, r := range string(text) {
+			r = normalizeRune(r)
+
+			switch classifyRune(r) {
+			case runeClassWord:
+				if len(cjk) > 0 {
+					flush()
+				}
+				word = append(word, r)
+			case runeClassCJK:
+				if len(word) > 0 {
+					flush()
+				}
+				cjk = append(cjk, r)
+			default:
+				flush()
+			}
+		}
+		flush()
+	}
+
+	return tokens
+}
+
+func addCJKTokens(cjk []rune, isIndex bool, addToken func(token []rune)) {
+	if len(cjk) == 1 || isIndex {
+		for i := range cjk {
+			addToken(cjk[i : i+1])
+		}
+	}
+
+	for i := 0; i+1 < len(cjk); i++ {
+		addToken(cjk[i : i+2])
+	}
+}
+
+func classifyRune(r rune) runeClass {
+	switch {
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+		return runeClassCJK
+	case unicode.IsLetter(r) || unicode.IsDigit(r):
+		return runeClassWord
+	}
+
+	return runeClassSeparator
+}
+
+/*
+normalizeRune lower-cases the rune and converts the full-width ascii to the half-width one.
+*/
+func normalizeRune(r rune) rune {
+	if r >= 0xff01 && r <= 0xff5e {
+		r -= 0xfee0
+	}
+
+	return unicode.ToLower(r)
+}
+
+func truncate(str string, size int) string {
+	for size > 0 && !utf8.RuneStart(str[size]) {
+		size--
+	}
+
+	return str[:size]
+}
@@ -0,0 +1,100 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	// define test-structure
+	type args struct {
+		texts [][]byte
+	}
+
+	// prepare test-cases
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "words",
+			args: args{[][]byte{[]byte("Hello, PTT world! hello")}},
+			want: []string{"hello", "ptt", "world"},
+		},
+		{
+			name: "cjk",
+			args: args{[][]byte{[]byte("批踢踢")}},
+			want: []string{"批", "踢", "批踢", "踢踢"},
+		},
+		{
+			name: "mixed",
+			args: args{[][]byte{[]byte("ＰＴＴ實業坊"), []byte("go語")}},
+			want: []string{"ptt", "實", "業", "坊", "實業", "業坊", "go", "語"},
+		},
+	}
+
+	// run test
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tokenize(tt.args.texts...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeQuery(t *testing.T) {
+	// define test-structure
+	type args struct {
+		query string
+	}
+
+	// prepare test-cases
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "cjk bigrams",
+			args: args{"實業坊"},
+			want: []string{"實業", "業坊"},
+		},
+		{
+			name: "cjk unigram",
+			args: args{"PTT 坊"},
+			want: []string{"ptt", "坊"},
+		},
+		{
+			name: "empty",
+			args: args{" ,. "},
+			want: []string{},
+		},
+	}
+
+	// run test
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TokenizeQuery(tt.args.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TokenizeQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
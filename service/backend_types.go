@@ -34,6 +34,7 @@ const (
 	PathJoinMe     = "/joinme"
 	PathJoinFriend = "/joinfriend"
 	PathJoinBoard  = "/joinboard"
+	PathJoinGroup  = "/joingroup"
 )
 
 type BackendCountPeers struct {
@@ -48,6 +49,10 @@ type BackendPeer struct {
 	PeerType PeerType         `json:"T"`
 	UserID   *types.PttID     `json:"UID"`
 	Addrs    []string         `json:"A"`
+
+	// misbehaviour score and the ts that the peer is banned until.
+	Score int             `json:"S"`
+	BanTS types.Timestamp `json:"BT"`
 }
 
 func PeerToBackendPeer(peer *PttPeer) *BackendPeer {
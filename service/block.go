@@ -55,7 +55,7 @@ type Block struct {
 	Pub      []byte        `json:"K,omitempty"`
 	KeyExtra *KeyExtraInfo `json:"k,omitempty"`
 
-	db           *pttdb.LDBBatch
+	db           pttdb.IndexBatch
 	fullDBPrefix []byte
 }
 
@@ -80,7 +80,7 @@ func NewBlock(
 }
 
 func (b *Block) SetDB(
-	db *pttdb.LDBBatch,
+	db pttdb.IndexBatch,
 	fullDBPrefix []byte,
 
 	objID *types.PttID,
@@ -36,7 +36,7 @@ type BlockInfo struct {
 
 	UpdaterID *types.PttID `json:"U"`
 
-	db           *pttdb.LDBBatch
+	db           pttdb.IndexBatch
 	dbLock       *types.LockMap
 	fullDBPrefix []byte
 
@@ -95,7 +95,7 @@ func (b *BlockInfo) Init(nBlock int) {
 }
 
 func (b *BlockInfo) SetDB(
-	db *pttdb.LDBBatch,
+	db pttdb.IndexBatch,
 	dbLock *types.LockMap,
 	fullDBPrefix []byte,
 
@@ -16,6 +16,8 @@
 
 package service
 
+import "github.com/ailabstw/go-pttai-core/pttdb"
+
 type Config struct {
 	MaxPeers          int
 	MaxHubPeers       int
@@ -25,6 +27,7 @@ type Config struct {
 	MaxRandomPeers    int
 
 	DataDir   string
+	DBEngine  pttdb.Engine // pttdb.DefaultEngine if empty
 	Version   string
 	GitCommit string
 
@@ -35,4 +38,20 @@ type Config struct {
 	IsE2E bool
 
 	IsPrivateAsPublic bool
+
+	// hub
+	IsHub    bool
+	HubNodes []string
+
+	HubQuotaBytes      int
+	HubNodeQuotaBytes  int
+	HubTotalQuotaBytes int
+	HubExpireSeconds   int
+
+	// relay
+	IsRelay         bool
+	RelayQuotaBytes int
+
+	// sign the oplogs in the canonical encoding (see CurrentSignVersion)
+	IsSignCanonical bool
 }
@@ -69,6 +69,17 @@ func (h *DialHistory) Get(id *discover.NodeID) *DialInfo {
 	return h.theMap[*id]
 }
 
+/*
+Remove removes the dial-info of the node (ex: the node is banned.)
+The entry in the heap is kept and popped when expired.
+*/
+func (h *DialHistory) Remove(id *discover.NodeID) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	delete(h.theMap, *id)
+}
+
 func (h *DialHistory) Expire() {
 	expireTS, err := types.GetTimestamp()
 	if err != nil {
@@ -87,6 +98,11 @@ func (h *DialHistory) Pop() {
 
 	dialInfo := heap.Pop(h.hist).(*DialInfo)
 
+	// the node may be removed and added again.
+	if h.theMap[*dialInfo.NodeID] != dialInfo {
+		return
+	}
+
 	delete(h.theMap, *dialInfo.NodeID)
 }
 
@@ -92,9 +92,9 @@ type Entity interface {
 	Name() string
 	SetName(name string)
 
-	DB() *pttdb.LDBBatch
+	DB() pttdb.IndexBatch
 	DBLock() *types.LockMap
-	SetDB(db *pttdb.LDBBatch, dbLock *types.LockMap)
+	SetDB(db pttdb.IndexBatch, dbLock *types.LockMap)
 
 	MustLock() error
 	Lock() error
@@ -108,6 +108,16 @@ type Entity interface {
 	IDString() string
 }
 
+/*
+FriendEntity is the entity with only one other user (ex: friend).
+The ops of the entity are dropped by the router if the other user is blocked.
+*/
+type FriendEntity interface {
+	Entity
+
+	GetFriendID() *types.PttID
+}
+
 type BaseEntity struct {
 	V         types.Version
 	ID        *types.PttID
@@ -134,7 +144,7 @@ type BaseEntity struct {
 	ptt     Router
 	service Service
 
-	db     *pttdb.LDBBatch
+	db     pttdb.IndexBatch
 	dbLock *types.LockMap
 
 	SyncInfo SyncInfo
@@ -142,7 +152,7 @@ type BaseEntity struct {
 	idString string
 }
 
-func NewBaseEntity(id *types.PttID, createTS types.Timestamp, creatorID *types.PttID, status types.Status, db *pttdb.LDBBatch, dbLock *types.LockMap) *BaseEntity {
+func NewBaseEntity(id *types.PttID, createTS types.Timestamp, creatorID *types.PttID, status types.Status, db pttdb.IndexBatch, dbLock *types.LockMap) *BaseEntity {
 
 	e := &BaseEntity{
 		V:         types.CurrentVersion,
@@ -170,7 +180,7 @@ func (e *BaseEntity) Init(pm ProtocolManager, ptt Router, service Service) {
 	e.idString = "(" + e.ID.String() + "/" + service.Name() + ")"
 }
 
-func (e *BaseEntity) SetDB(db *pttdb.LDBBatch, dbLock *types.LockMap) {
+func (e *BaseEntity) SetDB(db pttdb.IndexBatch, dbLock *types.LockMap) {
 	e.db = db
 	e.dbLock = dbLock
 }
@@ -361,7 +371,7 @@ func (e *BaseEntity) GetEntityType() EntityType {
 func (e *BaseEntity) SetEntityType(t EntityType) {
 	e.EntityType = t
 }
-func (e *BaseEntity) DB() *pttdb.LDBBatch {
+func (e *BaseEntity) DB() pttdb.IndexBatch {
 	return e.db
 }
 
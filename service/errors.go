@@ -53,6 +53,8 @@ var (
 
 	ErrInvalidData = errors.New("invalid data")
 
+	ErrInvalidEncVersion = errors.New("invalid enc version")
+
 	ErrInvalidObject = errors.New("invalid object")
 
 	ErrTimeout = errors.New("timeout")
@@ -88,6 +90,10 @@ var (
 
 	ErrInvalidBlock = errors.New("invalid block")
 
+	ErrInvalidChunk     = errors.New("invalid chunk")
+	ErrChunkNotFound    = errors.New("chunk not found")
+	ErrIncompleteUpload = errors.New("incomplete upload")
+
 	ErrAlreadyPending = errors.New("already pending")
 
 	ErrNotAlive = errors.New("not alive")
@@ -95,6 +101,12 @@ var (
 	ErrInvalidFunc = errors.New("invalid function")
 
 	ErrInvalidMerkle = errors.New("invalid merkle")
+
+	ErrNotHub = errors.New("not hub")
+
+	ErrBlockedUser = errors.New("blocked user")
+
+	ErrBanned = errors.New("banned")
 )
 
 func ErrResp(code error, format string, v ...interface{}) error {
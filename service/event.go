@@ -0,0 +1,151 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"context"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+/*
+EntityStatusEvent is posted when the status of the entity is changed.
+*/
+type EntityStatusEvent struct {
+	EntityID *types.PttID `json:"ID"`
+	Status   types.Status `json:"S"`
+}
+
+/*
+MerkleSyncEvent is posted when the oplogs of the merkle are synced with the peer.
+NOplog is the number of the oplogs received from the peer in the sync.
+*/
+type MerkleSyncEvent struct {
+	EntityID *types.PttID     `json:"ID"`
+	Merkle   string           `json:"M"`
+	PeerID   *discover.NodeID `json:"P"`
+	NOplog   int              `json:"N"`
+}
+
+/*
+PostEvent posts the event to the event-mux of the service of the entity.
+*/
+func (pm *BaseProtocolManager) PostEvent(ev interface{}) {
+	svc := pm.Entity().Service()
+	if svc == nil {
+		return
+	}
+
+	spm := svc.SPM()
+	if spm == nil {
+		return
+	}
+
+	spm.EventMux().Post(ev)
+}
+
+/*
+PostEntityStatusEvent posts the current status of the entity.
+*/
+func (pm *BaseProtocolManager) PostEntityStatusEvent() {
+	entity := pm.Entity()
+
+	pm.PostEvent(&EntityStatusEvent{
+		EntityID: entity.GetID(),
+		Status:   entity.GetStatus(),
+	})
+}
+
+func (pm *BaseProtocolManager) postMerkleSyncEvent(merkle *Merkle, peer *PttPeer, nOplog int) {
+	if peer == nil {
+		return
+	}
+
+	pm.PostEvent(&MerkleSyncEvent{
+		EntityID: pm.Entity().GetID(),
+		Merkle:   GetMerkleName(merkle, pm),
+		PeerID:   peer.GetID(),
+		NOplog:   nOplog,
+	})
+}
+
+/*
+SubscribeEvent creates the rpc-subscription notifying the events of the type of evType posted to the mux.
+The events are filtered by filter if filter is not nil.
+
+The events are buffered for each subscription (SizeEventBuffer), so posting the events
+is not blocked by the slow subscribers. The events are dropped if the buffer is full.
+*/
+func SubscribeEvent(ctx context.Context, mux *event.TypeMux, evType interface{}, filter func(ev interface{}) bool) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	// subscribe before returning to have all the events after the subscription.
+	sub := mux.Subscribe(evType)
+
+	events := make(chan interface{}, SizeEventBuffer)
+	quit := make(chan struct{})
+
+	// receive the events from the mux.
+	go func() {
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case obj, ok := <-sub.Chan():
+				if !ok {
+					return
+				}
+				if filter != nil && !filter(obj.Data) {
+					continue
+				}
+				select {
+				case events <- obj.Data:
+				default:
+					log.Warn("SubscribeEvent: buffer is full, dropped", "subID", rpcSub.ID, "ev", obj.Data)
+				}
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	// notify the events to the subscriber.
+	go func() {
+		defer close(quit)
+
+		for {
+			select {
+			case ev := <-events:
+				notifier.Notify(rpcSub.ID, ev)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
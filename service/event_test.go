@@ -0,0 +1,133 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type EventTestAPI struct {
+	mux      *event.TypeMux
+	entityID *types.PttID
+}
+
+func (api *EventTestAPI) EntityStatus(ctx context.Context) (*rpc.Subscription, error) {
+	return SubscribeEvent(ctx, api.mux, &EntityStatusEvent{}, func(ev interface{}) bool {
+		return reflect.DeepEqual(ev.(*EntityStatusEvent).EntityID, api.entityID)
+	})
+}
+
+func TestSubscribeEvent(t *testing.T) {
+	entityID, _ := types.NewPttID()
+	otherID, _ := types.NewPttID()
+
+	mux := new(event.TypeMux)
+	defer mux.Stop()
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	err := server.RegisterName("test", &EventTestAPI{mux: mux, entityID: entityID})
+	if err != nil {
+		t.Fatalf("RegisterName: e: %v", err)
+	}
+
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	ch := make(chan *EntityStatusEvent, 2)
+	sub, err := client.Subscribe(context.Background(), "test", ch, "entityStatus")
+	if err != nil {
+		t.Fatalf("Subscribe: e: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	// the event of the other entity is filtered.
+	mux.Post(&EntityStatusEvent{EntityID: otherID, Status: types.StatusAlive})
+	mux.Post(&EntityStatusEvent{EntityID: entityID, Status: types.StatusDeleted})
+
+	select {
+	case ev := <-ch:
+		if !reflect.DeepEqual(ev.EntityID, entityID) || ev.Status != types.StatusDeleted {
+			t.Errorf("SubscribeEvent: got: %v want: %v/%v", ev, entityID, types.StatusDeleted)
+		}
+	case err := <-sub.Err():
+		t.Fatalf("SubscribeEvent: e: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("SubscribeEvent: timeout")
+	}
+
+	select {
+	case ev := <-ch:
+		t.Errorf("SubscribeEvent: unexpected event: %v", ev)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSubscribeEvent_NotBlocking(t *testing.T) {
+	entityID, _ := types.NewPttID()
+
+	mux := new(event.TypeMux)
+	defer mux.Stop()
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	err := server.RegisterName("test", &EventTestAPI{mux: mux, entityID: entityID})
+	if err != nil {
+		t.Fatalf("RegisterName: e: %v", err)
+	}
+
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	// the subscriber does not read the events while posting.
+	ch := make(chan *EntityStatusEvent)
+	sub, err := client.Subscribe(context.Background(), "test", ch, "entityStatus")
+	if err != nil {
+		t.Fatalf("Subscribe: e: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 2*SizeEventBuffer; i++ {
+			mux.Post(&EntityStatusEvent{EntityID: entityID, Status: types.StatusAlive})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("SubscribeEvent: post blocked")
+	}
+
+	select {
+	case ev := <-ch:
+		if !reflect.DeepEqual(ev.EntityID, entityID) {
+			t.Errorf("SubscribeEvent: got: %v want: %v", ev.EntityID, entityID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("SubscribeEvent: timeout")
+	}
+}
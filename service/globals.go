@@ -41,6 +41,16 @@ var (
 		IsE2E: false,
 
 		IsPrivateAsPublic: false,
+
+		IsHub: false,
+
+		HubQuotaBytes:      4194304,   // 4MB for each user
+		HubNodeQuotaBytes:  16777216,  // 16MB from each sending node
+		HubTotalQuotaBytes: 268435456, // 256MB in total
+		HubExpireSeconds:   259200,    // 3 days, as the op-keys are expired.
+
+		IsRelay:         true,
+		RelayQuotaBytes: 1048576, // 1MB for each peer in each relay-window
 	}
 )
 
@@ -48,14 +58,39 @@ var (
 const (
 	_ uint = iota + 3
 	Ptt4
+	Ptt5 // aead-envelope for the encrypted data
 )
 
+// ProtocolVersions are ordered from the newest. The p2p-layer picks the highest
+// version supported by both peers, so ptt4-nodes and ptt5-nodes can still talk.
 var (
-	ProtocolVersions = [1]uint{Ptt4}
+	ProtocolVersions = [2]uint{Ptt5, Ptt4}
 	ProtocolName     = "ptt4"
-	ProtocolLengths  = [1]uint64{uint64(NCodeType)}
+	ProtocolLengths  = [2]uint64{uint64(NCodeType), uint64(NCodeType)}
+)
+
+// enc-version
+const (
+	EncVersionLegacy uint8 = iota // aes-cfb without version-byte (ptt4)
+	EncVersionAEAD                // aes-gcm with version-byte (ptt5)
+)
+
+// sign-version of the oplogs
+const (
+	SignVersionJSON      uint8 = iota // json of the oplog (legacy)
+	SignVersionCanonical              // canonical binary-encoding of the oplog
 )
 
+/*
+CurrentSignVersion is the sign-version of the newly signed oplogs.
+
+The oplogs are signed in json by default, because the legacy peers (< Ptt5) verify the oplogs only in json,
+and the oplogs are synced to all the members regardless of the peers connected when signing.
+The canonical encoding is activated (Router.IsSignCanonical in gptt) only when all the nodes of the members are >= Ptt5.
+The oplogs in both of the sign-versions are accepted in verifying.
+*/
+var CurrentSignVersion = SignVersionJSON
+
 // ptt-layer
 const (
 	ProtocolMaxMsgSize = 20 * 1024 * 1024 // 20MB for video-streaming
@@ -63,6 +98,8 @@ const (
 	SizeOpType   = 4 // optype uint32
 	SizeCodeType = 8 // codetype uint64
 
+	SizeEncVersion = 1 // enc-version uint8
+
 	SizeChallenge = 16
 
 	HandshakeTimeout    = 60 * time.Second
@@ -164,6 +201,10 @@ const (
 	BoardLastSeenMsg
 	ArticleLastSeenMsg
 
+	// media-chunk
+	SyncMediaChunkMsg
+	SyncMediaChunkAckMsg
+
 	NMsg
 )
 
@@ -235,6 +276,12 @@ const (
 
 	MaxUploadMediaSize = 10485760 // 10MB
 
+	// The chunks and the hashs of the chunks (in the create-media oplog) are base64-ed twice in ptt-layer,
+	// and need to fit in one webrtc-message (64KB).
+	NByteInChunk      = 8192    // 8KB
+	MaxUploadFileSize = 4194304 // 4MB, 512 chunks
+	MaxSyncMediaChunk = 3
+
 	MaxUploadImageWidth  = 8192
 	MaxUploadImageHeight = 8192
 )
@@ -242,6 +289,11 @@ const (
 var (
 	DBMediaPrefix    = []byte(".mddb")
 	DBMediaIdxPrefix = []byte(".mdix")
+
+	DBMediaChunkPrefix        = []byte(".fcdb")
+	DBMediaChunkRefPrefix     = []byte(".fcrf")
+	DBMediaChunkPendingPrefix = []byte(".fcpd")
+	DBUploadFilePrefix        = []byte(".fupl")
 )
 
 // db
@@ -250,16 +302,13 @@ const (
 
 	SleepTimeLock = 10
 
+	SleepTimeMediaChunkLock = 10
+
 	MaxCountPttOplog = 2000
 	PPttOplog        = 12 // 2^12 = 4096
 )
 
 var (
-	dbOplog     *pttdb.LDBBatch
-	dbOplogCore *pttdb.LDBDatabase
-
-	dbMeta *pttdb.LDBDatabase
-
 	DBNewestMasterLogIDPrefix = []byte(".nmld")
 	DBMasterLog0HashPrefix    = []byte(".ml0h")
 
@@ -267,7 +316,6 @@ var (
 
 	DBPttOplogPrefix    = []byte(".ptlg") // .ptlm, .ptli is used as well
 	DBPttIdxOplogPrefix = []byte(".ptig")
-	DBPttLockMap        *types.LockMap
 
 	DBLocalePrefix     = []byte(".locl")
 	DBPttLogSeenPrefix = []byte(".ptsn")
@@ -302,16 +350,92 @@ var (
 	ExpireGenerateOplogMerkleTreeSeconds int64 = 450               // 7.5 mins
 )
 
+// hub
+const (
+	// The relayed oplogs are encrypted and base64-ed several times in ptt-layer,
+	// and need to fit in one webrtc-message (64KB).
+	MaxRelayOplogs = 5
+
+	MaxDeliverHubRelay = 50
+)
+
+var (
+	DBHubRelayPrefix   = []byte(".hbrl")
+	DBHubNodePrefix    = []byte(".hbnd")
+	DBHubRelayTSPrefix = []byte(".hbts")
+
+	HubLoopInterval = 60 * time.Second
+)
+
+// relay
+const (
+	// MaxRelayHops is the max number of the hops from the requester to the target node.
+	// At most MaxRelayHops - 1 nodes forward the data.
+	MaxRelayHops uint8 = 4
+
+	// MaxRelayPeers is the max number of the peers that the data is forwarded to
+	// if the target node is not connected.
+	MaxRelayPeers = 3
+)
+
+var (
+	// RelayWindowSeconds is the window of the relay-quota and of the seen relayed data.
+	RelayWindowSeconds int64 = 60
+)
+
+// peer-score
+const (
+	// misbehaviour scores
+	ScoreDecryptFail   = 10
+	ScoreInvalidOplog  = 20
+	ScoreFailChallenge = 25
+	ScoreRateLimited   = 2
+
+	// BanScore is the score that the peer is banned for BanSeconds.
+	BanScore = 100
+)
+
+var (
+	// ScoreDecaySeconds is the seconds that the score decays 1 point.
+	ScoreDecaySeconds int64 = 6
+	BanSeconds        int64 = 600
+
+	PeerScoreLoopInterval = 60 * time.Second
+
+	// router-level rate-limits
+	DefaultCodeRateLimit = &RateLimit{Rate: 100, Burst: 1000}
+	CodeRateLimits       = map[CodeType]*RateLimit{
+		CodeTypeJoin:                             {Rate: 1, Burst: 10},
+		CodeTypeIdentifyPeerWithMyID:             {Rate: 0.5, Burst: 10},
+		CodeTypeIdentifyPeerWithMyIDChallenge:    {Rate: 0.5, Burst: 10},
+		CodeTypeIdentifyPeerWithMyIDChallengeAck: {Rate: 0.5, Burst: 10},
+		CodeTypeIdentifyPeerWithMyIDAck:          {Rate: 0.5, Burst: 10},
+	}
+
+	// op-level rate-limits, for each entity.
+	DefaultOpRateLimit              = &RateLimit{Rate: 50, Burst: 500}
+	ForceSyncOplogByMerkleRateLimit = &RateLimit{Rate: 2, Burst: 50}
+	OpRateLimits                    = map[OpType]*RateLimit{
+		IdentifyPeerMsg:                 {Rate: 0.2, Burst: 10},
+		ForceSyncMasterOplogByMerkleMsg: ForceSyncOplogByMerkleRateLimit,
+		ForceSyncMemberOplogByMerkleMsg: ForceSyncOplogByMerkleRateLimit,
+	}
+)
+
 // dial-history
 var (
 	ExpireDialHistorySeconds int64 = 30
 	DialHistoryLoopInterval        = 30 * time.Second
 )
 
+// event
+const (
+	SizeEventBuffer = 100
+)
+
 // locale
 var (
 	DefaultLocale Locale = LocaleTW
-	CurrentLocale Locale = DefaultLocale
 )
 
 // misc
@@ -325,49 +449,3 @@ var (
 var (
 	DBFix190Prefix = []byte(".f04H") // 190 in base58
 )
-
-func InitService(dataDir string) error {
-	dbOplogCore, err := pttdb.NewLDBDatabase("oplog", dataDir, 0, 0)
-	if err != nil {
-		return err
-	}
-
-	dbOplog, err = pttdb.NewLDBBatch(dbOplogCore)
-	if err != nil {
-		return err
-	}
-
-	dbMeta, err = pttdb.NewLDBDatabase("meta", dataDir, 0, 0)
-	if err != nil {
-		return err
-	}
-
-	DBPttLockMap, err = types.NewLockMap(SleepTimePttLock)
-	if err != nil {
-		return err
-	}
-
-	CurrentLocale = LoadLocale()
-
-	return nil
-}
-
-func TeardownService() {
-	if dbOplog != nil {
-		dbOplog = nil
-	}
-
-	if dbOplogCore != nil {
-		dbOplogCore.Close()
-		dbOplogCore = nil
-	}
-
-	if dbMeta != nil {
-		dbMeta.Close()
-		dbMeta = nil
-	}
-
-	if DBPttLockMap != nil {
-		DBPttLockMap = nil
-	}
-}
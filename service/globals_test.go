@@ -218,6 +218,62 @@ var (
 		Key:       []byte{},
 	}
 
+	// the merkle-nodes of the oplogs signed in the canonical encoding (SignVersionCanonical).
+	tDefaultMerkleNode1NowCanonical = &MerkleNode{
+		Level: MerkleTreeLevelNow,
+		Addr: []byte{
+			151, 187, 242, 181, 83, 215, 38, 208, 155, 151,
+			155, 22, 255, 0, 207, 240, 6, 79, 77, 208,
+		},
+		UpdateTS:  types.Timestamp{Ts: 1234567890, NanoTs: 0},
+		NChildren: 0,
+		Key:       tDefaultMerkleNode1Now.Key,
+	}
+
+	tDefaultMerkleNode2NowCanonical = &MerkleNode{
+		Level: MerkleTreeLevelNow,
+		Addr: []byte{
+			107, 188, 130, 216, 220, 47, 63, 183, 221, 81,
+			178, 30, 153, 234, 78, 119, 198, 193, 70, 3,
+		},
+		UpdateTS:  types.Timestamp{Ts: 1234567891, NanoTs: 0},
+		NChildren: 0,
+		Key:       tDefaultMerkleNode2Now.Key,
+	}
+
+	tDefaultMerkleNodeDayCanonical = &MerkleNode{
+		Level: MerkleTreeLevelDay,
+		Addr: []byte{
+			6, 19, 65, 39, 2, 48, 1, 241, 21, 56,
+			170, 41, 20, 88, 20, 138, 241, 55, 43, 45,
+		},
+		UpdateTS:  types.Timestamp{Ts: 1234483200, NanoTs: 0},
+		NChildren: 1,
+		Key:       []byte{},
+	}
+
+	tDefaultMerkleNodeMonthCanonical = &MerkleNode{
+		Level: MerkleTreeLevelMonth,
+		Addr: []byte{
+			182, 14, 154, 2, 177, 25, 227, 89, 199, 75,
+			97, 33, 134, 179, 241, 150, 172, 193, 87, 98,
+		},
+		UpdateTS:  types.Timestamp{Ts: 1233446400, NanoTs: 0},
+		NChildren: 1,
+		Key:       []byte{},
+	}
+
+	tDefaultMerkleNodeYearCanonical = &MerkleNode{
+		Level: MerkleTreeLevelYear,
+		Addr: []byte{
+			98, 224, 48, 2, 126, 244, 70, 242, 253, 28,
+			202, 68, 117, 162, 175, 64, 1, 44, 187, 195,
+		},
+		UpdateTS:  types.Timestamp{Ts: 1230768000, NanoTs: 0},
+		NChildren: 1,
+		Key:       []byte{},
+	}
+
 	tDefaultMerkle *Merkle = nil
 
 	tDBOplog             *pttdb.LDBBatch    = nil
@@ -0,0 +1,338 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/ailabstw/go-pttai-core/common"
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+)
+
+/*
+The hub stores the data relayed to the users who are offline,
+and delivers the data when the devices of the users are connected.
+
+The relayed data is encrypted with the op-key of the entity,
+the hub is unable to read the data, and is not required to be a member of the entity.
+*/
+
+type HubRelayData struct {
+	ID       *types.PttID     `json:"ID"`
+	UserID   *types.PttID     `json:"U"`
+	NodeID   *discover.NodeID `json:"N,omitempty"`
+	UpdateTS types.Timestamp  `json:"UT"`
+	Data     *RouterData      `json:"D"`
+}
+
+func (d *HubRelayData) MarshalKey() ([]byte, error) {
+	marshaledTS, err := d.UpdateTS.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return common.Concat([][]byte{DBHubRelayPrefix, d.UserID[:], marshaledTS, d.ID[:]})
+}
+
+func marshalHubRelayPrefix(userID *types.PttID) ([]byte, error) {
+	return common.Concat([][]byte{DBHubRelayPrefix, userID[:]})
+}
+
+/*
+HubRelays keeps the relayed data in the hub.
+
+The size of the relayed data is limited for each user, for each sending node, and in total.
+The sizes are loaded once and kept updated with the relayed data, and the quotas are
+checked and the data are saved within the same lock.
+*/
+type HubRelays struct {
+	lock sync.Mutex
+
+	db pttdb.Storage
+
+	userQuota  int
+	nodeQuota  int
+	totalQuota int
+
+	total     int
+	userSizes map[types.PttID]int
+	nodeSizes map[discover.NodeID]int
+}
+
+func NewHubRelays(db pttdb.Storage, userQuota int, nodeQuota int, totalQuota int) (*HubRelays, error) {
+	h := &HubRelays{
+		db: db,
+
+		userQuota:  userQuota,
+		nodeQuota:  nodeQuota,
+		totalQuota: totalQuota,
+
+		userSizes: make(map[types.PttID]int),
+		nodeSizes: make(map[discover.NodeID]int),
+	}
+
+	iter, err := db.NewIteratorWithPrefix(nil, DBHubRelayPrefix, pttdb.ListOrderNext)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Release()
+
+	for iter.Next() {
+		relay := &HubRelayData{}
+		err = json.Unmarshal(iter.Value(), relay)
+		if err != nil {
+			continue
+		}
+
+		h.addSize(relay, len(iter.Value()))
+	}
+
+	return h, nil
+}
+
+/*
+addSize adds the size of the relayed data.
+Assuming lock is locked.
+*/
+func (h *HubRelays) addSize(relay *HubRelayData, size int) {
+	h.total += size
+
+	if relay.UserID != nil {
+		h.userSizes[*relay.UserID] += size
+		if h.userSizes[*relay.UserID] <= 0 {
+			delete(h.userSizes, *relay.UserID)
+		}
+	}
+
+	if relay.NodeID != nil {
+		h.nodeSizes[*relay.NodeID] += size
+		if h.nodeSizes[*relay.NodeID] <= 0 {
+			delete(h.nodeSizes, *relay.NodeID)
+		}
+	}
+}
+
+/*
+Save saves the data relayed from the node to the user within the quotas.
+*/
+func (h *HubRelays) Save(userID *types.PttID, nodeID *discover.NodeID, data *RouterData) error {
+	id, err := types.NewPttID()
+	if err != nil {
+		return err
+	}
+
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return err
+	}
+
+	relay := &HubRelayData{
+		ID:       id,
+		UserID:   userID,
+		NodeID:   nodeID,
+		UpdateTS: ts,
+		Data:     data,
+	}
+
+	marshaled, err := json.Marshal(relay)
+	if err != nil {
+		return err
+	}
+	size := len(marshaled)
+
+	key, err := relay.MarshalKey()
+	if err != nil {
+		return err
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.userSizes[*userID]+size > h.userQuota ||
+		h.nodeSizes[*nodeID]+size > h.nodeQuota ||
+		h.total+size > h.totalQuota {
+		return ErrQuota
+	}
+
+	err = h.db.Put(key, marshaled)
+	if err != nil {
+		return err
+	}
+
+	h.addSize(relay, size)
+
+	return nil
+}
+
+/*
+Get gets the oldest relayed data to the user.
+
+Return: relays, keys, error
+*/
+func (h *HubRelays) Get(userID *types.PttID, limit int) ([]*HubRelayData, [][]byte, error) {
+	prefix, err := marshalHubRelayPrefix(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	iter, err := h.db.NewIteratorWithPrefix(nil, prefix, pttdb.ListOrderNext)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer iter.Release()
+
+	relays := make([]*HubRelayData, 0, limit)
+	keys := make([][]byte, 0, limit)
+	for iter.Next() {
+		relay := &HubRelayData{}
+		err = json.Unmarshal(iter.Value(), relay)
+		if err != nil {
+			continue
+		}
+
+		relays = append(relays, relay)
+		keys = append(keys, common.CloneBytes(iter.Key()))
+
+		if len(relays) == limit {
+			break
+		}
+	}
+
+	return relays, keys, nil
+}
+
+/*
+Remove removes the delivered data.
+*/
+func (h *HubRelays) Remove(key []byte) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return h.remove(key)
+}
+
+/*
+remove removes the relayed data and the size.
+Assuming lock is locked.
+*/
+func (h *HubRelays) remove(key []byte) error {
+	val, err := h.db.Get(key)
+	if err == pttdb.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = h.db.Delete(key)
+	if err != nil {
+		return err
+	}
+
+	relay := &HubRelayData{}
+	err = json.Unmarshal(val, relay)
+	if err != nil {
+		h.total -= len(val)
+		return nil
+	}
+
+	h.addSize(relay, -len(val))
+
+	return nil
+}
+
+/*
+Expire removes the relayed data earlier than expireTS.
+*/
+func (h *HubRelays) Expire(expireTS types.Timestamp) error {
+	iter, err := h.db.NewIteratorWithPrefix(nil, DBHubRelayPrefix, pttdb.ListOrderNext)
+	if err != nil {
+		return err
+	}
+
+	offsetTS := len(DBHubRelayPrefix) + types.SizePttID
+
+	toRemoveKeys := make([][]byte, 0)
+	for iter.Next() {
+		key := iter.Key()
+		if len(key) != offsetTS+types.SizeTimestamp+types.SizePttID {
+			continue
+		}
+
+		ts, err := types.UnmarshalTimestamp(key[offsetTS : offsetTS+types.SizeTimestamp])
+		if err != nil {
+			continue
+		}
+		if !ts.IsLess(expireTS) {
+			continue
+		}
+
+		toRemoveKeys = append(toRemoveKeys, common.CloneBytes(key))
+	}
+	iter.Release()
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for _, key := range toRemoveKeys {
+		err = h.remove(key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/**********
+ * Hub Node
+ **********/
+
+func marshalHubNodeKey(nodeID *discover.NodeID) ([]byte, error) {
+	return common.Concat([][]byte{DBHubNodePrefix, nodeID[:]})
+}
+
+func saveHubNode(db pttdb.Storage, nodeID *discover.NodeID) error {
+	key, err := marshalHubNodeKey(nodeID)
+	if err != nil {
+		return err
+	}
+
+	return db.Put(key, nodeID[:])
+}
+
+func loadHubNodes(db pttdb.Storage) ([]*discover.NodeID, error) {
+	iter, err := db.NewIteratorWithPrefix(nil, DBHubNodePrefix, pttdb.ListOrderNext)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Release()
+
+	nodeIDs := make([]*discover.NodeID, 0)
+	for iter.Next() {
+		nodeID, err := discover.BytesID(iter.Value())
+		if err != nil {
+			continue
+		}
+		nodeIDs = append(nodeIDs, &nodeID)
+	}
+
+	return nodeIDs, nil
+}
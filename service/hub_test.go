@@ -0,0 +1,186 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+)
+
+func TestHubRelay(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	db := tDBOplogCore
+	userID, _ := types.NewPttID()
+	userID2, _ := types.NewPttID()
+	nodeID := &discover.NodeID{1}
+
+	data := &RouterData{Code: CodeTypeOp, EvWithSalt: []byte("test-relay")}
+
+	h, err := NewHubRelays(db, 1024, 4096, 4096)
+	if err != nil {
+		t.Errorf("NewHubRelays: e: %v", err)
+	}
+
+	// save
+	err = h.Save(userID, nodeID, data)
+	if err != nil {
+		t.Errorf("Save: e: %v", err)
+	}
+	err = h.Save(userID, nodeID, data)
+	if err != nil {
+		t.Errorf("Save: e: %v", err)
+	}
+
+	// quota is for each user.
+	h.userQuota = 500
+	err = h.Save(userID, nodeID, data)
+	if err != ErrQuota {
+		t.Errorf("Save: e: %v want: %v", err, ErrQuota)
+	}
+	err = h.Save(userID2, nodeID, data)
+	if err != nil {
+		t.Errorf("Save: e: %v", err)
+	}
+
+	// the sizes are loaded.
+	h2, err := NewHubRelays(db, 1024, 4096, 4096)
+	if err != nil {
+		t.Errorf("NewHubRelays: e: %v", err)
+	}
+	if h2.total != h.total || h2.userSizes[*userID] != h.userSizes[*userID] || h2.nodeSizes[*nodeID] != h.total {
+		t.Errorf("NewHubRelays: total: %v userSize: %v nodeSize: %v want: %v", h2.total, h2.userSizes[*userID], h2.nodeSizes[*nodeID], h.total)
+	}
+
+	// get
+	relays, keys, err := h.Get(userID, 1)
+	if err != nil {
+		t.Errorf("Get: e: %v", err)
+	}
+	if len(relays) != 1 || len(keys) != 1 {
+		t.Errorf("Get: relays: %v keys: %v", len(relays), len(keys))
+	}
+
+	relays, _, _ = h.Get(userID, MaxDeliverHubRelay)
+	if len(relays) != 2 {
+		t.Errorf("Get: relays: %v want: 2", len(relays))
+	}
+	if !reflect.DeepEqual(relays[0].Data, data) || !reflect.DeepEqual(relays[0].NodeID, nodeID) {
+		t.Errorf("Get: data: %v want: %v", relays[0].Data, data)
+	}
+
+	// expire
+	expireTS := relays[0].UpdateTS
+	expireTS.Ts -= 1
+	h.Expire(expireTS)
+	relays, _, _ = h.Get(userID, MaxDeliverHubRelay)
+	if len(relays) != 2 {
+		t.Errorf("Expire: relays: %v want: 2", len(relays))
+	}
+
+	expireTS.Ts += 3600
+	h.Expire(expireTS)
+	relays, _, _ = h.Get(userID, MaxDeliverHubRelay)
+	if len(relays) != 0 {
+		t.Errorf("Expire: relays: %v want: 0", len(relays))
+	}
+	relays, _, _ = h.Get(userID2, MaxDeliverHubRelay)
+	if len(relays) != 0 {
+		t.Errorf("Expire: relays: %v want: 0", len(relays))
+	}
+	if h.total != 0 || len(h.userSizes) != 0 || len(h.nodeSizes) != 0 {
+		t.Errorf("Expire: total: %v users: %v nodes: %v want: 0", h.total, len(h.userSizes), len(h.nodeSizes))
+	}
+}
+
+func TestHubRelay_Quota(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	db := tDBOplogCore
+	userID, _ := types.NewPttID()
+	userID2, _ := types.NewPttID()
+	userID3, _ := types.NewPttID()
+	nodeID := &discover.NodeID{1}
+	nodeID2 := &discover.NodeID{2}
+
+	data := &RouterData{Code: CodeTypeOp, EvWithSalt: []byte("test-relay")}
+
+	h, _ := NewHubRelays(db, 1024, 1024, 1024)
+	h.Save(userID, nodeID, data)
+	size := h.total
+
+	// quota is for each sending node.
+	h.nodeQuota = size + size/2
+	err := h.Save(userID2, nodeID, data)
+	if err != ErrQuota {
+		t.Errorf("Save: e: %v want: %v", err, ErrQuota)
+	}
+	err = h.Save(userID2, nodeID2, data)
+	if err != nil {
+		t.Errorf("Save: e: %v", err)
+	}
+
+	// total quota
+	h.totalQuota = 2*size + size/2
+	err = h.Save(userID3, nodeID2, data)
+	if err != ErrQuota {
+		t.Errorf("Save: e: %v want: %v", err, ErrQuota)
+	}
+
+	// the quota is released once delivered.
+	_, keys, _ := h.Get(userID, MaxDeliverHubRelay)
+	err = h.Remove(keys[0])
+	if err != nil {
+		t.Errorf("Remove: e: %v", err)
+	}
+	if h.total != size || h.nodeSizes[*nodeID] != 0 {
+		t.Errorf("Remove: total: %v nodeSize: %v", h.total, h.nodeSizes[*nodeID])
+	}
+	err = h.Save(userID3, nodeID, data)
+	if err != nil {
+		t.Errorf("Save: e: %v", err)
+	}
+}
+
+func TestHubNode(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	db := tDBOplogCore
+	nodeID := discover.NodeID{1, 2, 3}
+
+	err := saveHubNode(db, &nodeID)
+	if err != nil {
+		t.Errorf("saveHubNode: e: %v", err)
+	}
+
+	nodeIDs, err := loadHubNodes(db)
+	if err != nil {
+		t.Errorf("loadHubNodes: e: %v", err)
+	}
+	if len(nodeIDs) != 1 || !reflect.DeepEqual(*nodeIDs[0], nodeID) {
+		t.Errorf("loadHubNodes: nodeIDs: %v want: %v", nodeIDs, nodeID)
+	}
+}
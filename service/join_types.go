@@ -33,8 +33,16 @@ const (
 	JoinTypeMe
 	JoinTypeFriend
 	JoinTypeBoard
+	JoinTypeGroup
 )
 
+// ApproveJoinHandler
+
+/*
+ApproveJoinHandler handles the approve-join from the invitor for the join-requests requested through MyEntity.JoinEntity. (joiner)
+*/
+type ApproveJoinHandler func(dataBytes []byte, joinRequest *JoinRequest, peer *PttPeer) error
+
 // JoinStatus
 
 type JoinStatus int
@@ -60,6 +60,19 @@ func NewJoinKeyInfo(entityID *types.PttID) (*KeyInfo, error) {
 	return newKeyInfo(extendedKey, nil, entityID, nil)
 }
 
+/*
+NewJoinKeyInfoFromKey generates the join-key-info from the key,
+for the join-key required to be derived by both the joiner and the invitor.
+*/
+func NewJoinKeyInfoFromKey(key *ecdsa.PrivateKey, entityID *types.PttID) (*KeyInfo, error) {
+	extendedKey, err := bip32.PrivKeyToExtKey(key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return newKeyInfo(extendedKey, nil, entityID, nil)
+}
+
 func NewOpKeyInfo(entityID *types.PttID, doerID *types.PttID, masterKey *ecdsa.PrivateKey) (*KeyInfo, error) {
 	key, extra, err := deriveOpKey(masterKey)
 	if err != nil {
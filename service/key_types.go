@@ -50,6 +50,17 @@ type KeyBIP32 struct {
 	Child  uint32      `json:"c"`
 }
 
+func (k *KeyBIP32) EncodeCanonical(enc *CanonicalEncoder) {
+	enc.Version(1)
+	enc.Bytes(k.Parent)
+	if k.Salt == nil {
+		enc.Bytes(nil)
+	} else {
+		enc.Bytes(k.Salt[:])
+	}
+	enc.Uint64(uint64(k.Child))
+}
+
 func (k *KeyExtraInfo) IsValid(pubKeyBytes []byte, doerID *types.PttID) bool {
 	switch k.KeyType {
 	case KeyTypeBIP32:
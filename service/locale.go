@@ -28,8 +28,8 @@ const (
 	NLocale
 )
 
-func LoadLocale() Locale {
-	value, err := dbMeta.Get(DBLocalePrefix)
+func (r *BaseRouter) LoadLocale() Locale {
+	value, err := r.dbMeta.Get(DBLocalePrefix)
 	if err != nil {
 		return DefaultLocale
 	}
@@ -41,8 +41,21 @@ func LoadLocale() Locale {
 	return Locale(value[0])
 }
 
-func SetLocale(locale Locale) error {
-	CurrentLocale = locale
+/*
+Locale returns the locale of the router.
+*/
+func (r *BaseRouter) Locale() Locale {
+	r.localeLock.RLock()
+	defer r.localeLock.RUnlock()
+
+	return r.locale
+}
+
+func (r *BaseRouter) SetLocale(locale Locale) error {
+	r.localeLock.Lock()
+	defer r.localeLock.Unlock()
+
+	r.locale = locale
 	value := []byte{uint8(locale)}
-	return dbMeta.Put(DBLocalePrefix, value)
+	return r.dbMeta.Put(DBLocalePrefix, value)
 }
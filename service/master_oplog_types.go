@@ -25,3 +25,15 @@ const (
 
 type MasterOpCreateMaster struct {
 }
+
+func (o *MasterOpCreateMaster) EncodeCanonical(enc *CanonicalEncoder) {
+	enc.Version(1)
+}
+
+func init() {
+	RegisterOpData(DBMasterOplogPrefix, map[OpType]func() CanonicalOpData{
+		MasterOpTypeAddMaster:      func() CanonicalOpData { return &MasterOpCreateMaster{} },
+		MasterOpTypeMigrateMaster:  func() CanonicalOpData { return &PersonOpTransferPerson{} },
+		MasterOpTypeTransferMaster: func() CanonicalOpData { return &PersonOpTransferPerson{} },
+	})
+}
@@ -170,6 +170,17 @@ func (m *Media) DeleteAll(
 		return err
 	}
 
+	// chunks (the media may be with only the id)
+	if m.MediaData == nil {
+		m.GetByID(true)
+	}
+	if m.IsChunkedFile() {
+		err = m.removeChunks()
+		if err != nil {
+			return err
+		}
+	}
+
 	return m.Delete(true)
 }
 
@@ -235,9 +246,84 @@ func (m *Media) Unmarshal(data []byte) error {
 		return err
 	}
 
+	return m.unmarshalMediaData()
+}
+
+/*
+unmarshalMediaData converts the json-unmarshaled MediaData to the typed MediaData.
+*/
+func (m *Media) unmarshalMediaData() error {
+	if m.MediaType != MediaTypeFile || m.MediaData == nil {
+		return nil
+	}
+
+	if _, ok := m.MediaData.(*MediaDataFile); ok {
+		return nil
+	}
+
+	marshaled, err := json.Marshal(m.MediaData)
+	if err != nil {
+		return err
+	}
+
+	mediaData := &MediaDataFile{}
+	err = json.Unmarshal(marshaled, mediaData)
+	if err != nil {
+		return err
+	}
+
+	m.MediaData = mediaData
+
 	return nil
 }
 
+/**********
+ * File
+ **********/
+
+func (m *Media) GetFileData() *MediaDataFile {
+	if m.MediaType != MediaTypeFile {
+		return nil
+	}
+
+	mediaData, _ := m.MediaData.(*MediaDataFile)
+
+	return mediaData
+}
+
+/*
+IsChunkedFile returns whether the media is a file stored as chunks instead of blocks.
+*/
+func (m *Media) IsChunkedFile() bool {
+	return m.GetBlockInfo() == nil && m.GetFileData() != nil
+}
+
+func (m *Media) GetChunk(idx int) ([]byte, error) {
+	fileData := m.GetFileData()
+	if fileData == nil {
+		return nil, ErrInvalidObject
+	}
+
+	if idx < 0 || idx >= len(fileData.Chunks) {
+		return nil, ErrInvalidChunk
+	}
+
+	return getMediaChunk(m.DB().DB(), fileData.Chunks[idx])
+}
+
+func (m *Media) removeChunks() error {
+	fileData := m.GetFileData()
+
+	db := m.DB().DB()
+
+	err := unrefMediaChunks(db, fileData.Chunks, m.ID)
+	if err != nil {
+		return err
+	}
+
+	return removeMediaChunkPending(db, m.EntityID, m.ID)
+}
+
 /**********
  * Sync Info
  **********/
@@ -0,0 +1,315 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"bytes"
+
+	"github.com/ailabstw/go-pttai-core/common"
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+/*
+The chunks of the files are content-addressed by the hash of the chunk,
+so the chunks of the duplicated files are stored only once.
+
+The chunks are referenced by the media and the upload-files (ref-id),
+and are removed when there is no reference to the chunk.
+
+The chunk is locked by the hash in saving / referring the chunk and in removing the reference,
+so the chunk is not removed while being referred.
+*/
+
+var mediaChunkLockMap, _ = types.NewLockMap(SleepTimeMediaChunkLock)
+
+func mediaChunkLockID(hash []byte) *types.PttID {
+	id := &types.PttID{}
+	copy(id[:], hash)
+	return id
+}
+
+func MediaChunkHash(buf []byte) []byte {
+	return crypto.Keccak256(buf)
+}
+
+/*
+NMediaChunk returns the number of the chunks of the file with the size.
+*/
+func NMediaChunk(size int64) int {
+	return int((size + NByteInChunk - 1) / NByteInChunk)
+}
+
+/*
+MediaChunkSize returns the size of the idx-th chunk of the file with the size.
+*/
+func MediaChunkSize(size int64, idx int) int {
+	offset := int64(idx) * NByteInChunk
+	if offset+NByteInChunk > size {
+		return int(size - offset)
+	}
+	return NByteInChunk
+}
+
+func marshalMediaChunkKey(hash []byte) ([]byte, error) {
+	return common.Concat([][]byte{DBMediaChunkPrefix, hash})
+}
+
+func marshalMediaChunkRefPrefix(hash []byte) ([]byte, error) {
+	return common.Concat([][]byte{DBMediaChunkRefPrefix, hash})
+}
+
+func marshalMediaChunkRefKey(hash []byte, refID *types.PttID) ([]byte, error) {
+	return common.Concat([][]byte{DBMediaChunkRefPrefix, hash, refID[:]})
+}
+
+/*
+saveMediaChunk saves the chunk, and refers the chunk from the ref-id if refID is not nil.
+*/
+func saveMediaChunk(db pttdb.Storage, buf []byte, refID *types.PttID) ([]byte, error) {
+	hash := MediaChunkHash(buf)
+
+	key, err := marshalMediaChunkKey(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	lockID := mediaChunkLockID(hash)
+	err = mediaChunkLockMap.Lock(lockID)
+	if err != nil {
+		return nil, err
+	}
+	defer mediaChunkLockMap.Unlock(lockID)
+
+	if refID != nil {
+		err = refMediaChunk(db, hash, refID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	isExists, err := db.Has(key)
+	if err != nil {
+		return nil, err
+	}
+	if isExists {
+		return hash, nil
+	}
+
+	err = db.Put(key, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return hash, nil
+}
+
+func getMediaChunk(db pttdb.Storage, hash []byte) ([]byte, error) {
+	key, err := marshalMediaChunkKey(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := db.Get(key)
+	if err == pttdb.ErrNotFound {
+		return nil, ErrChunkNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+func hasMediaChunk(db pttdb.Storage, hash []byte) bool {
+	key, err := marshalMediaChunkKey(hash)
+	if err != nil {
+		return false
+	}
+
+	isExists, err := db.Has(key)
+	if err != nil {
+		return false
+	}
+
+	return isExists
+}
+
+func refMediaChunks(db pttdb.Storage, hashs [][]byte, refID *types.PttID) error {
+	for _, hash := range hashs {
+		if hash == nil {
+			continue
+		}
+
+		lockID := mediaChunkLockID(hash)
+		err := mediaChunkLockMap.Lock(lockID)
+		if err != nil {
+			return err
+		}
+
+		err = refMediaChunk(db, hash, refID)
+		mediaChunkLockMap.Unlock(lockID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+refMediaChunk refers the chunk from the ref-id.
+Assuming the chunk is locked.
+*/
+func refMediaChunk(db pttdb.Storage, hash []byte, refID *types.PttID) error {
+	key, err := marshalMediaChunkRefKey(hash, refID)
+	if err != nil {
+		return err
+	}
+
+	return db.Put(key, refID[:])
+}
+
+/*
+unrefMediaChunks removes the references of the chunks from the ref-id,
+and removes the chunks without any reference.
+*/
+func unrefMediaChunks(db pttdb.Storage, hashs [][]byte, refID *types.PttID) error {
+	for _, hash := range hashs {
+		if hash == nil {
+			continue
+		}
+
+		lockID := mediaChunkLockID(hash)
+		err := mediaChunkLockMap.Lock(lockID)
+		if err != nil {
+			return err
+		}
+
+		err = unrefMediaChunk(db, hash, refID)
+		mediaChunkLockMap.Unlock(lockID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+unrefMediaChunk removes the reference of the chunk from the ref-id, and removes the chunk without any reference.
+Assuming the chunk is locked.
+*/
+func unrefMediaChunk(db pttdb.Storage, hash []byte, refID *types.PttID) error {
+	key, err := marshalMediaChunkRefKey(hash, refID)
+	if err != nil {
+		return err
+	}
+
+	err = db.Delete(key)
+	if err != nil {
+		return err
+	}
+
+	if isMediaChunkReferred(db, hash) {
+		return nil
+	}
+
+	chunkKey, err := marshalMediaChunkKey(hash)
+	if err != nil {
+		return err
+	}
+
+	return db.Delete(chunkKey)
+}
+
+func isMediaChunkReferred(db pttdb.Storage, hash []byte) bool {
+	prefix, err := marshalMediaChunkRefPrefix(hash)
+	if err != nil {
+		return false
+	}
+
+	iter, err := db.NewIteratorWithPrefix(nil, prefix, pttdb.ListOrderNext)
+	if err != nil {
+		return false
+	}
+	defer iter.Release()
+
+	return iter.Next()
+}
+
+/*
+missingMediaChunks returns the hashs of the chunks that are not stored yet.
+*/
+func missingMediaChunks(db pttdb.Storage, hashs [][]byte, limit int) [][]byte {
+	missings := make([][]byte, 0)
+	for _, hash := range hashs {
+		if hasMediaChunk(db, hash) {
+			continue
+		}
+		if isHashInList(missings, hash) {
+			continue
+		}
+
+		missings = append(missings, hash)
+		if limit > 0 && len(missings) == limit {
+			break
+		}
+	}
+
+	return missings
+}
+
+func isHashInList(hashs [][]byte, hash []byte) bool {
+	for _, each := range hashs {
+		if bytes.Equal(each, hash) {
+			return true
+		}
+	}
+	return false
+}
+
+/**********
+ * Pending
+ **********/
+
+func marshalMediaChunkPendingPrefix(entityID *types.PttID) ([]byte, error) {
+	return common.Concat([][]byte{DBMediaChunkPendingPrefix, entityID[:]})
+}
+
+func marshalMediaChunkPendingKey(entityID *types.PttID, mediaID *types.PttID) ([]byte, error) {
+	return common.Concat([][]byte{DBMediaChunkPendingPrefix, entityID[:], mediaID[:]})
+}
+
+func saveMediaChunkPending(db pttdb.Storage, entityID *types.PttID, mediaID *types.PttID) error {
+	key, err := marshalMediaChunkPendingKey(entityID, mediaID)
+	if err != nil {
+		return err
+	}
+
+	return db.Put(key, mediaID[:])
+}
+
+func removeMediaChunkPending(db pttdb.Storage, entityID *types.PttID, mediaID *types.PttID) error {
+	key, err := marshalMediaChunkPendingKey(entityID, mediaID)
+	if err != nil {
+		return err
+	}
+
+	return db.Delete(key)
+}
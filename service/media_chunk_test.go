@@ -0,0 +1,172 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+)
+
+func TestNMediaChunk(t *testing.T) {
+	tests := []struct {
+		name       string
+		size       int64
+		wantNChunk int
+		wantLast   int
+	}{
+		{name: "empty", size: 0, wantNChunk: 0, wantLast: 0},
+		{name: "one byte", size: 1, wantNChunk: 1, wantLast: 1},
+		{name: "one chunk", size: NByteInChunk, wantNChunk: 1, wantLast: NByteInChunk},
+		{name: "one chunk and one byte", size: NByteInChunk + 1, wantNChunk: 2, wantLast: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nChunk := NMediaChunk(tt.size)
+			if nChunk != tt.wantNChunk {
+				t.Errorf("NMediaChunk() = %v, want %v", nChunk, tt.wantNChunk)
+			}
+			if nChunk == 0 {
+				return
+			}
+			if got := MediaChunkSize(tt.size, nChunk-1); got != tt.wantLast {
+				t.Errorf("MediaChunkSize() = %v, want %v", got, tt.wantLast)
+			}
+		})
+	}
+}
+
+func TestMediaChunk_Ref(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	db := tDBOplogCore
+	buf := []byte("test-chunk")
+
+	// duplicated chunks are stored once.
+	hash, err := saveMediaChunk(db, buf, nil)
+	if err != nil {
+		t.Errorf("saveMediaChunk: e: %v", err)
+	}
+	hash2, _ := saveMediaChunk(db, append([]byte{}, buf...), nil)
+	if !reflect.DeepEqual(hash, hash2) {
+		t.Errorf("saveMediaChunk: hash: %v want: %v", hash2, hash)
+	}
+
+	refID1, _ := types.NewPttID()
+	refID2, _ := types.NewPttID()
+	refMediaChunks(db, [][]byte{hash}, refID1)
+	refMediaChunks(db, [][]byte{hash}, refID2)
+
+	// still referred by refID2
+	unrefMediaChunks(db, [][]byte{hash}, refID1)
+	got, err := getMediaChunk(db, hash)
+	if err != nil || !reflect.DeepEqual(got, buf) {
+		t.Errorf("getMediaChunk: got: %v e: %v", got, err)
+	}
+
+	// no more reference
+	unrefMediaChunks(db, [][]byte{hash}, refID2)
+	_, err = getMediaChunk(db, hash)
+	if err != ErrChunkNotFound {
+		t.Errorf("getMediaChunk: e: %v want: %v", err, ErrChunkNotFound)
+	}
+}
+
+func TestMediaChunk_ConcurrentRef(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	db := tDBOplogCore
+	buf := []byte("test-concurrent-chunk")
+	hash := MediaChunkHash(buf)
+
+	// the even refs are removed right after saved, the odd refs are kept.
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			refID, _ := types.NewPttID()
+			_, err := saveMediaChunk(db, buf, refID)
+			if err != nil {
+				t.Errorf("saveMediaChunk: e: %v", err)
+				return
+			}
+
+			if i%2 == 0 {
+				err = unrefMediaChunks(db, [][]byte{hash}, refID)
+				if err != nil {
+					t.Errorf("unrefMediaChunks: e: %v", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	got, err := getMediaChunk(db, hash)
+	if err != nil || !reflect.DeepEqual(got, buf) {
+		t.Errorf("getMediaChunk: got: %v e: %v", got, err)
+	}
+}
+
+func TestMediaReader_Read(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	size := int64(NByteInChunk*2 + 10)
+	buf := make([]byte, size)
+	for i := range buf {
+		buf[i] = byte(i % 251)
+	}
+
+	chunks := make([][]byte, 0, NMediaChunk(size))
+	for i := 0; i < NMediaChunk(size); i++ {
+		offset := i * NByteInChunk
+		hash, err := saveMediaChunk(tDBOplogCore, buf[offset:offset+MediaChunkSize(size, i)], nil)
+		if err != nil {
+			t.Errorf("saveMediaChunk: e: %v", err)
+		}
+		chunks = append(chunks, hash)
+	}
+
+	media := NewEmptyMedia()
+	media.db = tDBOplog
+	media.MediaType = MediaTypeFile
+	media.MediaData = &MediaDataFile{Filename: []byte("test.bin"), Size: size, Chunks: chunks}
+
+	if !media.IsChunkedFile() {
+		t.Errorf("IsChunkedFile: false")
+	}
+
+	got, err := ioutil.ReadAll(NewMediaReader(media))
+	if err != nil {
+		t.Errorf("ReadAll: e: %v", err)
+	}
+	if !bytes.Equal(got, buf) {
+		t.Errorf("ReadAll: len: %v want: %v", len(got), len(buf))
+	}
+}
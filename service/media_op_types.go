@@ -18,10 +18,29 @@ package service
 
 import "github.com/ailabstw/go-pttai-core/common/types"
 
+/**********
+ * OpCreateMedia requires json-order
+ **********/
+
 type OpCreateMedia struct {
-	BlockInfoID *types.PttID `json:"BID"`
-	Hashs       [][][]byte   `json:"H"`
-	NBlock      int          `json:"NB"`
+	BlockInfoID *types.PttID   `json:"BID"`
+	File        *MediaDataFile `json:"F,omitempty"`
+	Hashs       [][][]byte     `json:"H"`
+	NBlock      int            `json:"NB"`
+	MediaType   MediaType      `json:"T,omitempty"`
+}
+
+func (o *OpCreateMedia) EncodeCanonical(enc *CanonicalEncoder) {
+	enc.Version(1)
+	enc.PttID(o.BlockInfoID)
+	enc.Data(o.File)
+	enc.Hashs(o.Hashs)
+	enc.Int64(int64(o.NBlock))
+	enc.Uint64(uint64(o.MediaType))
 }
 
 type OpDeleteMedia struct{}
+
+func (o *OpDeleteMedia) EncodeCanonical(enc *CanonicalEncoder) {
+	enc.Version(1)
+}
@@ -0,0 +1,60 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import "io"
+
+/*
+MediaReader reads the chunked file chunk by chunk,
+so the whole file is not loaded into the memory.
+*/
+type MediaReader struct {
+	media *Media
+
+	idx int
+	buf []byte
+}
+
+func NewMediaReader(media *Media) *MediaReader {
+	return &MediaReader{media: media}
+}
+
+func (r *MediaReader) Read(p []byte) (int, error) {
+	fileData := r.media.GetFileData()
+	if fileData == nil {
+		return 0, ErrInvalidObject
+	}
+
+	for len(r.buf) == 0 {
+		if r.idx >= len(fileData.Chunks) {
+			return 0, io.EOF
+		}
+
+		buf, err := r.media.GetChunk(r.idx)
+		if err != nil {
+			return 0, err
+		}
+
+		r.buf = buf
+		r.idx++
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+
+	return n, nil
+}
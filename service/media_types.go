@@ -42,6 +42,21 @@ type MediaDataGIF struct {
 	Height uint16 `json:"H"`
 }
 
+/*
+MediaDataFile is the meta of the file.
+The large file is stored as content-addressed chunks (with Size and Chunks) instead of blocks.
+
+MediaDataFile is in OpCreateMedia and requires json-order.
+*/
 type MediaDataFile struct {
-	Filename []byte `json:"f"`
+	Chunks   [][]byte `json:"c,omitempty"`
+	Filename []byte   `json:"f"`
+	Size     int64    `json:"s,omitempty"`
+}
+
+func (f *MediaDataFile) EncodeCanonical(enc *CanonicalEncoder) {
+	enc.Version(1)
+	enc.BytesList(f.Chunks)
+	enc.Bytes(f.Filename)
+	enc.Int64(f.Size)
 }
@@ -28,3 +28,19 @@ type MemberOpAddMember struct {
 
 type MemberOpDeleteMember struct {
 }
+
+func (o *MemberOpAddMember) EncodeCanonical(enc *CanonicalEncoder) {
+	enc.Version(1)
+}
+
+func (o *MemberOpDeleteMember) EncodeCanonical(enc *CanonicalEncoder) {
+	enc.Version(1)
+}
+
+func init() {
+	RegisterOpData(DBMemberOplogPrefix, map[OpType]func() CanonicalOpData{
+		MemberOpTypeAddMember:     func() CanonicalOpData { return &MemberOpAddMember{} },
+		MemberOpTypeDeleteMember:  func() CanonicalOpData { return &MemberOpDeleteMember{} },
+		MemberOpTypeMigrateMember: func() CanonicalOpData { return &PersonOpTransferPerson{} },
+	})
+}
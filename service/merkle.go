@@ -44,7 +44,7 @@ type Merkle struct {
 	dbMerkleToUpdatePrefixWithID []byte
 	dbMerkleUpdatingPrefixWithID []byte
 	PrefixID                     *types.PttID
-	db                           *pttdb.LDBBatch
+	db                           pttdb.IndexBatch
 	LastGenerateTS               types.Timestamp
 	BusyGenerateTS               types.Timestamp
 	LastSyncTS                   types.Timestamp
@@ -63,7 +63,7 @@ type Merkle struct {
 	Name string
 }
 
-func NewMerkle(dbOplogPrefix []byte, dbMerklePrefix []byte, prefixID *types.PttID, db *pttdb.LDBBatch, name string) (*Merkle, error) {
+func NewMerkle(dbOplogPrefix []byte, dbMerklePrefix []byte, prefixID *types.PttID, db pttdb.IndexBatch, name string) (*Merkle, error) {
 
 	prefixIDBytes := prefixID[:]
 
@@ -77,6 +77,26 @@ func TestMerkle_GetMerkleTreeList(t *testing.T) {
 	setupTest(t)
 	defer teardownTest(t)
 
+	testMerkleGetMerkleTreeList(t, tDefaultMerkleNode1Now, tDefaultMerkleNode2Now, tDefaultMerkleNodeDay, tDefaultMerkleNodeMonth, tDefaultMerkleNodeYear)
+}
+
+func TestMerkle_GetMerkleTreeListCanonical(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	CurrentSignVersion = SignVersionCanonical
+	defer func() { CurrentSignVersion = SignVersionJSON }()
+
+	tDefaultOplog.Sign(tKeyInfoMe)
+	tDefaultOplog.MasterLogID = tUserIDMe
+	tDefaultOplog2.Sign(tKeyInfoMe)
+	tDefaultOplog2.MasterLogID = tUserIDMe
+
+	testMerkleGetMerkleTreeList(t, tDefaultMerkleNode1NowCanonical, tDefaultMerkleNode2NowCanonical, tDefaultMerkleNodeDayCanonical, tDefaultMerkleNodeMonthCanonical, tDefaultMerkleNodeYearCanonical)
+}
+
+func testMerkleGetMerkleTreeList(t *testing.T, node1Now *MerkleNode, node2Now *MerkleNode, nodeDay *MerkleNode, nodeMonth *MerkleNode, nodeYear *MerkleNode) {
 	tDefaultMerkle.ResetUpdateTS()
 	tDefaultOplog.Save(true, tDefaultMerkle)
 	tDefaultOplog2.Save(true, tDefaultMerkle)
@@ -107,30 +127,30 @@ func TestMerkle_GetMerkleTreeList(t *testing.T) {
 			m:     tDefaultMerkle,
 			args:  args{types.Timestamp{Ts: 1234567892, NanoTs: 0}},
 			want:  []*MerkleNode{},
-			want1: []*MerkleNode{tDefaultMerkleNode1Now, tDefaultMerkleNode2Now},
+			want1: []*MerkleNode{node1Now, node2Now},
 		},
 		{
 			m:     tDefaultMerkle,
 			args:  args{types.Timestamp{Ts: 1234571490, NanoTs: 0}}, // +3600
-			want:  []*MerkleNode{tDefaultMerkleNodeDay},
+			want:  []*MerkleNode{nodeDay},
 			want1: []*MerkleNode{},
 		},
 		{
 			m:     tDefaultMerkle,
 			args:  args{types.Timestamp{Ts: 1234654290, NanoTs: 0}}, // +86400
-			want:  []*MerkleNode{tDefaultMerkleNodeDay},
+			want:  []*MerkleNode{nodeDay},
 			want1: []*MerkleNode{},
 		},
 		{
 			m:     tDefaultMerkle,
 			args:  args{types.Timestamp{Ts: 1237332690, NanoTs: 0}}, // +86400 * 32
-			want:  []*MerkleNode{tDefaultMerkleNodeMonth},
+			want:  []*MerkleNode{nodeMonth},
 			want1: []*MerkleNode{},
 		},
 		{
 			m:     tDefaultMerkle,
 			args:  args{types.Timestamp{Ts: 1266535890, NanoTs: 0}}, // +86400 * 370
-			want:  []*MerkleNode{tDefaultMerkleNodeYear},
+			want:  []*MerkleNode{nodeYear},
 			want1: []*MerkleNode{},
 		},
 	}
@@ -0,0 +1,147 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"strconv"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	pttmetrics "github.com/ailabstw/go-pttai-core/metrics"
+)
+
+const (
+	MetricCodeCount     = "ptt_code_msgs_total"
+	MetricCodeBytes     = "ptt_code_bytes_total"
+	MetricOpCount       = "ptt_op_msgs_total"
+	MetricOpBytes       = "ptt_op_bytes_total"
+	MetricPeers         = "ptt_peers"
+	MetricEntities      = "ptt_entities"
+	MetricSyncLag       = "ptt_entity_sync_lag_seconds"
+	MetricPendingOplogs = "ptt_entity_pending_oplogs"
+
+	MetricDirIn  = "in"
+	MetricDirOut = "out"
+
+	MetricsCollectorRouter = "router"
+)
+
+/*
+markCodeMsg meters the count / bytes of the router-level messages by code.
+*/
+func markCodeMsg(dir string, code CodeType, size uint32) {
+	if !pttmetrics.Enabled() {
+		return
+	}
+
+	codeStr := code.String()
+	if codeStr == "" {
+		codeStr = strconv.Itoa(int(code))
+	}
+
+	pttmetrics.GetOrRegisterCounter(pttmetrics.Name(MetricCodeCount, "dir", dir, "code", codeStr)).Inc(1)
+	pttmetrics.GetOrRegisterCounter(pttmetrics.Name(MetricCodeBytes, "dir", dir, "code", codeStr)).Inc(int64(size))
+}
+
+/*
+markOpMsg meters the count / bytes of the op-messages of the service by op.
+*/
+func markOpMsg(dir string, pm ProtocolManager, op OpType, size int) {
+	if !pttmetrics.Enabled() {
+		return
+	}
+
+	serviceName := pm.Entity().Service().Name()
+	opStr := strconv.Itoa(int(op))
+
+	pttmetrics.GetOrRegisterCounter(pttmetrics.Name(MetricOpCount, "dir", dir, "service", serviceName, "op", opStr)).Inc(1)
+	pttmetrics.GetOrRegisterCounter(pttmetrics.Name(MetricOpBytes, "dir", dir, "service", serviceName, "op", opStr)).Inc(int64(size))
+}
+
+/*
+CollectMetrics collects the peers by peer-type, and the sync-lag / pending-oplogs of the entities.
+The protocol-manager implementing metrics.Collector (ex: raft-state in me) is collected as well.
+*/
+func (r *BaseRouter) CollectMetrics() []*pttmetrics.Sample {
+	samples := make([]*pttmetrics.Sample, 0)
+
+	// peers
+	r.peerLock.RLock()
+	peerCounts := map[PeerType]int{
+		PeerTypeMe:        len(r.myPeers),
+		PeerTypeHub:       len(r.hubPeers),
+		PeerTypeImportant: len(r.importantPeers),
+		PeerTypeMember:    len(r.memberPeers),
+		PeerTypePending:   len(r.pendingPeers),
+		PeerTypeRandom:    len(r.randomPeers),
+	}
+	r.peerLock.RUnlock()
+
+	for peerType, count := range peerCounts {
+		samples = append(samples, pttmetrics.NewGaugeSample(MetricPeers, float64(count), "type", peerType.String()))
+	}
+
+	// entities
+	now, err := types.GetTimestamp()
+	if err != nil {
+		return samples
+	}
+
+	r.entityLock.RLock()
+	entities := make([]Entity, 0, len(r.entities))
+	for _, entity := range r.entities {
+		entities = append(entities, entity)
+	}
+	r.entityLock.RUnlock()
+
+	entityCounts := make(map[string]int)
+	for _, entity := range entities {
+		serviceName := entity.Service().Name()
+		entityCounts[serviceName]++
+
+		if entity.GetStatus() != types.StatusAlive {
+			continue
+		}
+
+		pm := entity.PM()
+		entityID := entity.GetID().String()
+
+		if merkle := pm.Log0Merkle(); merkle != nil {
+			syncTS, err := merkle.GetSyncTime()
+			if err == nil && syncTS.Ts > 0 {
+				samples = append(samples, pttmetrics.NewGaugeSample(MetricSyncLag, float64(now.Ts-syncTS.Ts), "service", serviceName, "entity", entityID))
+			}
+		}
+
+		count, err := pm.CountPendingLog0s()
+		if err != nil {
+			log.Warn("CollectMetrics: unable to count pending oplogs", "entity", entityID, "e", err)
+		} else {
+			samples = append(samples, pttmetrics.NewGaugeSample(MetricPendingOplogs, float64(count), "service", serviceName, "entity", entityID))
+		}
+
+		if collector, ok := pm.(pttmetrics.Collector); ok {
+			samples = append(samples, collector.CollectMetrics()...)
+		}
+	}
+
+	for serviceName, count := range entityCounts {
+		samples = append(samples, pttmetrics.NewGaugeSample(MetricEntities, float64(count), "service", serviceName))
+	}
+
+	return samples
+}
@@ -0,0 +1,88 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	pttmetrics "github.com/ailabstw/go-pttai-core/metrics"
+	"github.com/ailabstw/go-pttai-core/p2p"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeteredMsgReadWriter(t *testing.T) {
+	setupTest(t)
+	defer teardownTest(t)
+
+	origEnabled := metrics.Enabled
+	pttmetrics.Enable()
+	defer func() { metrics.Enabled = origEnabled }()
+
+	rw1, rw2 := p2p.MsgPipe()
+	defer rw1.Close()
+
+	m1, _ := NewBaseMeteredMsgReadWriter(rw1, 1)
+	m2, _ := NewBaseMeteredMsgReadWriter(rw2, 1)
+
+	outName := pttmetrics.Name(MetricCodeCount, "dir", MetricDirOut, "code", CodeTypeOp.String())
+	inName := pttmetrics.Name(MetricCodeCount, "dir", MetricDirIn, "code", CodeTypeOp.String())
+	origOut := pttmetrics.GetOrRegisterCounter(outName).Count()
+	origIn := pttmetrics.GetOrRegisterCounter(inName).Count()
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- p2p.Send(m1, uint64(CodeTypeOp), &RouterData{Code: CodeTypeOp})
+	}()
+
+	msg, err := m2.ReadMsg()
+	assert.NoError(t, err)
+	msg.Discard()
+	assert.NoError(t, <-errc)
+
+	assert.Equal(t, origOut+1, pttmetrics.GetOrRegisterCounter(outName).Count())
+	assert.Equal(t, origIn+1, pttmetrics.GetOrRegisterCounter(inName).Count())
+}
+
+func TestRouter_CollectMetrics(t *testing.T) {
+	setupTest(t)
+	defer teardownTest(t)
+
+	r := &BaseRouter{
+		myPeers:        make(map[discover.NodeID]*PttPeer),
+		hubPeers:       make(map[discover.NodeID]*PttPeer),
+		importantPeers: make(map[discover.NodeID]*PttPeer),
+		memberPeers:    make(map[discover.NodeID]*PttPeer),
+		pendingPeers:   make(map[discover.NodeID]*PttPeer),
+		randomPeers:    make(map[discover.NodeID]*PttPeer),
+	}
+	r.randomPeers[discover.NodeID{1}] = &PttPeer{}
+	r.randomPeers[discover.NodeID{2}] = &PttPeer{}
+	r.myPeers[discover.NodeID{3}] = &PttPeer{}
+
+	counts := make(map[string]float64)
+	for _, s := range r.CollectMetrics() {
+		assert.Equal(t, MetricPeers, s.Name)
+		counts[s.Labels[1]] = s.Value
+	}
+
+	assert.Equal(t, 6, len(counts))
+	assert.Equal(t, 2.0, counts[PeerTypeRandom.String()])
+	assert.Equal(t, 1.0, counts[PeerTypeMe.String()])
+	assert.Equal(t, 0.0, counts[PeerTypeHub.String()])
+}
@@ -41,6 +41,9 @@ type MyEntity interface {
 
 	GetUserNodeID(id *types.PttID) (*discover.NodeID, error)
 
+	// the node-ids of all my devices
+	GetMyNodeIDs() []*discover.NodeID
+
 	Sign(oplog *BaseOplog) error
 	InternalSign(oplog *BaseOplog) error
 	MasterSign(oplog *BaseOplog) error
@@ -51,7 +54,13 @@ type MyEntity interface {
 	CreateEntityOplog(entity Entity) error
 	CreateJoinEntityOplog(entity Entity) error
 
+	JoinEntity(joinRequest *JoinRequest, handleApproveJoin ApproveJoinHandler) error
+
 	GetValidateKey() *types.PttID
+
+	// block-list
+	IsBlockedUser(id *types.PttID) bool
+	IsMutedUser(id *types.PttID) bool
 }
 
 /*
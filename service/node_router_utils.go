@@ -34,6 +34,7 @@ func (r *BaseRouter) generateProtocols() []p2p.Protocol {
 			Run:      r.generateRun(version),
 			NodeInfo: r.generateNodeInfo(),
 			PeerInfo: r.generatePeerInfo(),
+			IsBanned: r.generateIsBanned(),
 		}
 
 		subProtocols = append(subProtocols, protocol)
@@ -89,6 +90,12 @@ func (r *BaseRouter) generatePeerInfo() func(id discover.NodeID) interface{} {
 	}
 }
 
+func (r *BaseRouter) generateIsBanned() func(id discover.NodeID) bool {
+	return func(id discover.NodeID) bool {
+		return r.IsBanned(&id)
+	}
+}
+
 func (r *BaseRouter) routerAPIs() []rpc.API {
 	return []rpc.API{
 		{
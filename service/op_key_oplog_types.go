@@ -27,3 +27,18 @@ type OpKeyOpCreateOpKey struct {
 
 type OpKeyOpRevokeOpKey struct {
 }
+
+func (o *OpKeyOpCreateOpKey) EncodeCanonical(enc *CanonicalEncoder) {
+	enc.Version(1)
+}
+
+func (o *OpKeyOpRevokeOpKey) EncodeCanonical(enc *CanonicalEncoder) {
+	enc.Version(1)
+}
+
+func init() {
+	RegisterOpData(DBOpKeyOplogPrefix, map[OpType]func() CanonicalOpData{
+		OpKeyOpTypeCreateOpKey: func() CanonicalOpData { return &OpKeyOpCreateOpKey{} },
+		OpKeyOpTypeRevokeOpKey: func() CanonicalOpData { return &OpKeyOpRevokeOpKey{} },
+	})
+}
@@ -53,6 +53,10 @@ const (
 	CodeTypeOpCheckMember
 	CodeTypeOpCheckMemberAck
 
+	CodeTypeHubAnnounce
+	CodeTypeHubRelay
+	CodeTypeHubDeliver
+
 	NCodeType
 )
 
@@ -82,6 +86,10 @@ var codeTypeStr = map[CodeType]string{
 
 	CodeTypeOpCheckMember:    "op-check-member",
 	CodeTypeOpCheckMemberAck: "op-check-member-ack",
+
+	CodeTypeHubAnnounce: "hub-announce",
+	CodeTypeHubRelay:    "hub-relay",
+	CodeTypeHubDeliver:  "hub-deliver",
 }
 
 func (c CodeType) String() string {
@@ -51,9 +51,12 @@ type BaseOplog struct {
 
 	PreLogID *types.PttID `json:"p,omitempty"`
 
+	// empty for the legacy json-signed oplogs
+	SignV uint8 `json:"sv,omitempty"`
+
 	Data OpData `json:"D,omitempty"`
 
-	db               *pttdb.LDBBatch
+	db               pttdb.IndexBatch
 	dbPrefixID       *types.PttID
 	dbPrefix         []byte
 	dbIdxPrefix      []byte
@@ -82,11 +85,11 @@ type BaseOplog struct {
 	Extra   interface{} `json:"e,omitempty"`
 }
 
-func NewOplogForLoadData(data interface{}, db *pttdb.LDBBatch) *BaseOplog {
+func NewOplogForLoadData(data interface{}, db pttdb.IndexBatch) *BaseOplog {
 	return &BaseOplog{Data: data, db: db}
 }
 
-func NewOplog(id *types.PttID, ts types.Timestamp, doerID *types.PttID, op OpType, data interface{}, db *pttdb.LDBBatch, dbPrefixID *types.PttID, dbPrefix []byte, dbIdxPrefix []byte, dbMerklePrefix []byte, dbLock *types.LockMap) (*BaseOplog, error) {
+func NewOplog(id *types.PttID, ts types.Timestamp, doerID *types.PttID, op OpType, data interface{}, db pttdb.IndexBatch, dbPrefixID *types.PttID, dbPrefix []byte, dbIdxPrefix []byte, dbMerklePrefix []byte, dbLock *types.LockMap) (*BaseOplog, error) {
 
 	opID, err := types.NewPttID()
 	if err != nil {
@@ -124,7 +127,7 @@ func NewOplog(id *types.PttID, ts types.Timestamp, doerID *types.PttID, op OpTyp
 	return oplog, nil
 }
 
-func (o *BaseOplog) SetDB(db *pttdb.LDBBatch, id *types.PttID, prefix []byte, idxPrefix []byte, merklePrefix []byte, dbLock *types.LockMap) {
+func (o *BaseOplog) SetDB(db pttdb.IndexBatch, id *types.PttID, prefix []byte, idxPrefix []byte, merklePrefix []byte, dbLock *types.LockMap) {
 	dbPrefixInternal := dbPrefixToDBPrefixInternal(prefix)
 	dbPrefixMaster := dbPrefixToDBPrefixMaster(prefix)
 
@@ -138,7 +141,7 @@ func (o *BaseOplog) SetDB(db *pttdb.LDBBatch, id *types.PttID, prefix []byte, id
 	o.dbLock = dbLock
 }
 
-func (o *BaseOplog) GetDB() *pttdb.LDBBatch {
+func (o *BaseOplog) GetDB() pttdb.IndexBatch {
 	return o.db
 }
 
@@ -542,7 +545,9 @@ func (o *BaseOplog) Sign(keyInfo *KeyInfo) error {
 	o.IsNewer = false
 	o.Extra = nil
 
-	marshaled, err := o.Marshal()
+	o.SignV = CurrentSignVersion
+
+	marshaled, err := o.MarshalSign()
 	if err != nil {
 		return err
 	}
@@ -644,7 +649,7 @@ func (o *BaseOplog) MasterSign(id *types.PttID, keyInfo *KeyInfo) error {
 	o.Extra = nil
 
 	// sign
-	marshaled, err := o.Marshal()
+	marshaled, err := o.MarshalSign()
 	if err != nil {
 		return err
 	}
@@ -721,7 +726,7 @@ func (o *BaseOplog) InternalSign(id *types.PttID, keyInfo *KeyInfo) error {
 	o.Extra = nil
 
 	// sign
-	marshaled, err := o.Marshal()
+	marshaled, err := o.MarshalSign()
 	if err != nil {
 		return err
 	}
@@ -817,7 +822,7 @@ func (o *BaseOplog) Verify() error {
 	o.IsNewer = false
 	o.Extra = nil
 
-	marshaled, err := o.Marshal()
+	marshaled, err := o.MarshalSign()
 	if err != nil {
 		return err
 	}
@@ -839,7 +844,7 @@ func (o *BaseOplog) Verify() error {
 	// master signs
 	if origMasterSigns != nil {
 		for _, masterSign := range origMasterSigns {
-			marshaled, err = o.Marshal()
+			marshaled, err = o.MarshalSign()
 			if err != nil {
 				return err
 			}
@@ -856,7 +861,7 @@ func (o *BaseOplog) Verify() error {
 	// internal signs
 	if origInternalSigns != nil {
 		for _, internalSign := range origInternalSigns {
-			marshaled, err = o.Marshal()
+			marshaled, err = o.MarshalSign()
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,339 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"sort"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+)
+
+/*
+CanonicalOpData is the op-data with the explicit canonical field-encoding for signing.
+
+EncodeCanonical writes the version of the field-encoding of the type first (enc.Version),
+and then the fields in the fixed order. The version is to be increased when the fields are changed,
+so that the oplogs signed with the old fields are not verified as the new fields.
+*/
+type CanonicalOpData interface {
+	EncodeCanonical(enc *CanonicalEncoder)
+}
+
+// op-data types by the oplog db-prefix and the op, for the op-data unmarshaled from the peers.
+var opDataTypes = make(map[string]map[OpType]func() CanonicalOpData)
+
+/*
+RegisterOpData registers the op-data types of the oplogs with dbPrefix (in init).
+
+The op-data of the oplogs from the peers are unmarshaled as maps,
+and are converted to the registered types for the canonical-encoding.
+*/
+func RegisterOpData(dbPrefix []byte, newOpDatas map[OpType]func() CanonicalOpData) {
+	key := string(dbPrefix)
+	if opDataTypes[key] == nil {
+		opDataTypes[key] = make(map[OpType]func() CanonicalOpData)
+	}
+
+	for op, newOpData := range newOpDatas {
+		opDataTypes[key][op] = newOpData
+	}
+}
+
+/*
+CanonicalEncoder is the canonical binary-encoding of the oplogs and the op-data.
+
+	uint:      uvarint
+	int:       varint
+	bool:      0x00 / 0x01
+	bytes:     uvarint(len) | bytes
+	id:        bytes (empty if nil)
+	timestamp: uint64 | uint32 (big-endian)
+	list:      uvarint(n) | items
+	data:      0x00 (nil) / 0x01 | version | fields
+*/
+type CanonicalEncoder struct {
+	buf bytes.Buffer
+
+	versionPos int
+	err        error
+}
+
+func NewCanonicalEncoder() *CanonicalEncoder {
+	return &CanonicalEncoder{versionPos: -1}
+}
+
+/*
+Encoded returns the encoded bytes, or ErrInvalidData if any of the data is without the version.
+*/
+func (e *CanonicalEncoder) Encoded() ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	return e.buf.Bytes(), nil
+}
+
+/*
+Version writes the version of the field-encoding of the op-data.
+*/
+func (e *CanonicalEncoder) Version(v uint8) {
+	if e.versionPos == -1 {
+		e.versionPos = e.buf.Len()
+	}
+	e.buf.WriteByte(v)
+}
+
+func (e *CanonicalEncoder) Uint64(n uint64) {
+	theBytes := make([]byte, binary.MaxVarintLen64)
+	size := binary.PutUvarint(theBytes, n)
+	e.buf.Write(theBytes[:size])
+}
+
+func (e *CanonicalEncoder) Int64(n int64) {
+	theBytes := make([]byte, binary.MaxVarintLen64)
+	size := binary.PutVarint(theBytes, n)
+	e.buf.Write(theBytes[:size])
+}
+
+func (e *CanonicalEncoder) Bool(b bool) {
+	if b {
+		e.buf.WriteByte(1)
+	} else {
+		e.buf.WriteByte(0)
+	}
+}
+
+func (e *CanonicalEncoder) Bytes(theBytes []byte) {
+	e.Uint64(uint64(len(theBytes)))
+	e.buf.Write(theBytes)
+}
+
+func (e *CanonicalEncoder) BytesList(list [][]byte) {
+	e.Uint64(uint64(len(list)))
+	for _, each := range list {
+		e.Bytes(each)
+	}
+}
+
+/*
+Hashs writes the block-hashs (by block and by sub-block).
+*/
+func (e *CanonicalEncoder) Hashs(hashs [][][]byte) {
+	e.Uint64(uint64(len(hashs)))
+	for _, each := range hashs {
+		e.BytesList(each)
+	}
+}
+
+func (e *CanonicalEncoder) PttID(id *types.PttID) {
+	if id == nil {
+		e.Bytes(nil)
+		return
+	}
+	e.Bytes(id[:])
+}
+
+func (e *CanonicalEncoder) PttIDs(ids []*types.PttID) {
+	e.Uint64(uint64(len(ids)))
+	for _, id := range ids {
+		e.PttID(id)
+	}
+}
+
+func (e *CanonicalEncoder) NodeID(id *discover.NodeID) {
+	if id == nil {
+		e.Bytes(nil)
+		return
+	}
+	e.Bytes(id[:])
+}
+
+/*
+NodeIDWeights writes the map of the node-ids sorted by the node-ids.
+*/
+func (e *CanonicalEncoder) NodeIDWeights(weights map[discover.NodeID]uint32) {
+	ids := make([]discover.NodeID, 0, len(weights))
+	for id := range weights {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return bytes.Compare(ids[i][:], ids[j][:]) < 0
+	})
+
+	e.Uint64(uint64(len(ids)))
+	for _, id := range ids {
+		e.Bytes(id[:])
+		e.Uint64(uint64(weights[id]))
+	}
+}
+
+func (e *CanonicalEncoder) Timestamp(ts types.Timestamp) {
+	theBytes := make([]byte, 12)
+	binary.BigEndian.PutUint64(theBytes, uint64(ts.Ts))
+	binary.BigEndian.PutUint32(theBytes[8:], ts.NanoTs)
+	e.buf.Write(theBytes)
+}
+
+/*
+Data writes the nested data (nil if the data is a nil-pointer) with the version of the data.
+*/
+func (e *CanonicalEncoder) Data(data CanonicalOpData) {
+	if data == nil {
+		e.Bool(false)
+		return
+	}
+	if v := reflect.ValueOf(data); v.Kind() == reflect.Ptr && v.IsNil() {
+		e.Bool(false)
+		return
+	}
+
+	e.Bool(true)
+
+	start := e.buf.Len()
+	origVersionPos := e.versionPos
+	e.versionPos = -1
+
+	data.EncodeCanonical(e)
+	if e.versionPos != start {
+		e.err = ErrInvalidData
+	}
+
+	e.versionPos = origVersionPos
+}
+
+/*
+MarshalCanonicalData marshals the op-data in the canonical binary-encoding.
+*/
+func MarshalCanonicalData(data CanonicalOpData) ([]byte, error) {
+	enc := NewCanonicalEncoder()
+	enc.Data(data)
+
+	return enc.Encoded()
+}
+
+/*
+MarshalSign marshals the oplog for signing based on the sign-version of the oplog.
+*/
+func (o *BaseOplog) MarshalSign() ([]byte, error) {
+	switch o.SignV {
+	case SignVersionJSON:
+		return o.Marshal()
+	case SignVersionCanonical:
+		return o.MarshalCanonical()
+	}
+
+	return nil, ErrInvalidOplog
+}
+
+/*
+MarshalCanonical marshals the oplog in the canonical binary-encoding.
+
+The fields are in the fixed order, and the data are in the field-encoding of the op-data type
+(see CanonicalOpData). The op-data unmarshaled from the peers are converted to the op-data type
+registered with the db-prefix and the op of the oplog (see RegisterOpData).
+
+The creator-sign (with the key-extra) is included only if the oplog is signed by the creator
+(for the master-signs and the internal-signs).
+*/
+func (o *BaseOplog) MarshalCanonical() ([]byte, error) {
+	data, err := o.canonicalData()
+	if err != nil {
+		return nil, err
+	}
+
+	enc := NewCanonicalEncoder()
+
+	enc.Uint64(uint64(o.SignV))
+	enc.Uint64(uint64(o.V))
+	enc.PttID(o.ID)
+	enc.PttID(o.CreatorID)
+	enc.Timestamp(o.CreateTS)
+	enc.PttID(o.ObjID)
+	enc.Uint64(uint64(o.Op))
+	enc.PttID(o.PreLogID)
+	enc.Data(data)
+
+	if len(o.CreatorHash) == 0 {
+		return enc.Encoded()
+	}
+
+	enc.Bytes(o.CreatorHash)
+	enc.Bytes(o.Salt[:])
+	enc.Bytes(o.Sig)
+	enc.Bytes(o.Pubkey)
+
+	err = encodeCanonicalKeyExtra(enc, o.KeyExtra)
+	if err != nil {
+		return nil, err
+	}
+
+	return enc.Encoded()
+}
+
+func (o *BaseOplog) canonicalData() (CanonicalOpData, error) {
+	if o.Data == nil {
+		return nil, nil
+	}
+
+	if data, ok := o.Data.(CanonicalOpData); ok {
+		return data, nil
+	}
+
+	newOpData, ok := opDataTypes[string(o.dbPrefix)][o.Op]
+	if !ok {
+		return nil, ErrInvalidData
+	}
+
+	data := newOpData()
+	err := o.GetData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func encodeCanonicalKeyExtra(enc *CanonicalEncoder, k *KeyExtraInfo) error {
+	if k == nil {
+		enc.Bool(false)
+		return nil
+	}
+
+	enc.Bool(true)
+	enc.Uint64(uint64(k.KeyType))
+
+	switch k.KeyType {
+	case KeyTypeBIP32:
+		keyBIP32, ok := k.Data.(*KeyBIP32)
+		if !ok {
+			keyBIP32 = &KeyBIP32{}
+			err := k.GetData(keyBIP32)
+			if err != nil {
+				return err
+			}
+		}
+		enc.Data(keyBIP32)
+	default:
+		return ErrInvalidData
+	}
+
+	return nil
+}
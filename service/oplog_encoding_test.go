@@ -0,0 +1,330 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	"github.com/stretchr/testify/assert"
+)
+
+type tOpData struct {
+	B []byte       `json:"B"`
+	A *types.PttID `json:"A"`
+	N uint64       `json:"N"`
+	S string       `json:"S,omitempty"`
+}
+
+func (d *tOpData) EncodeCanonical(enc *CanonicalEncoder) {
+	enc.Version(1)
+	enc.Bytes(d.B)
+	enc.PttID(d.A)
+	enc.Uint64(d.N)
+	enc.Bytes([]byte(d.S))
+}
+
+// without the version.
+type tInvalidOpData struct{}
+
+func (d *tInvalidOpData) EncodeCanonical(enc *CanonicalEncoder) {
+	enc.Uint64(1)
+}
+
+func init() {
+	RegisterOpData(tDBOplogPrefix, map[OpType]func() CanonicalOpData{
+		tDefaultOpType: func() CanonicalOpData { return &tOpData{} },
+	})
+}
+
+// signed by the json-encoding before the canonical-encoding.
+const tLegacyOplogJSON = `{"V":2,"ID":"6dd4jwZnrpCtjqYvYW5aSpHJANS7q8RdhYgby1eSzFQGddsBrPmg1Rb","CID":"f8FnBNeGR37bqtFqZ4zZjXGYdKpqFB91ijXjWztGUdmV7dV5aBFZsU","CT":{"T":123456789,"NT":2},"OID":"f8FnBNeGR37bqtFqZ4zZjXGYdKqUuch97qNKXhV4Hhni6iHUbuAejB","O":1,"D":{"A":"6dd4jwZnrpCtjqYvYW5aSpHJANS7q8RdhYgby1eSzFQGddsBrPmg1Rb","B":"ZGF0YQ==","N":7,"S":"s"},"cH":"G9lcZFqM3heSXuQniiBtbMUnfqllMfWPH2FztrYIrPs=","s":"4F85ZySpwyY6FuH7mQYyyr5b8nV9zFRBLj92AJa37sMr","S":"WmCwpZkzFVZrYHB9BWMjXnp/s7EDIZDvLVJnMu9ekOllc8B+zlu0KmR/nNsR7nI6VA2sz83LMdK/cbd3ZT2DogA=","K":"BP2hz/Z0yQyaGXU5/j37UwhqzmT4PtfG6r7HQffzgcyAPlKrLNVdVWm85DRxB6MQ39X4igEM0v/RAFykBvGEKHc=","UT":{"T":123456789,"NT":2},"H":"Jn5oXgCcNvJueVurntVpwGoQzIYt5Hwbb0oerk3EyVk=","m":[{"ID":"62Rm6MPdZs5WqccFHRNmRGDN6tgjWYyS9G3D7LHQgZRDvAPXs8gt7Ua","CT":{"T":123456789,"NT":2},"H":"5MJBJdDBU9HN17i8NRjHynhq+Ii7WI7DfxNQZ//VY/0=","s":"4F85ZySpwyY6FuH7mQYyyr5b8nV9zFRBLj92AJa37sMr","S":"bpI17NIXt4omlPSGjJVCpHxBTa3YnYPipkLZDtYJ49BJ8Om+KbdVphwOj+s5wxgbsU9nIftB+dN/312iZktcLwE=","K":"BDtnASjsUpReb4xvgiA5fnaxk/LYWC/tz7470Q3iWus58EATYXDYb3b59bvMQplJGt275j0cLncxqE7NVHfJJ1A="}],"y":1}`
+
+func tNewCanonicalOplog() *BaseOplog {
+	return &BaseOplog{
+		V:         types.CurrentVersion,
+		ID:        tDefaultID,
+		CreatorID: tUserIDMe,
+		CreateTS:  tDefaultTimestamp,
+		UpdateTS:  tDefaultTimestamp,
+		ObjID:     tMyID,
+		Op:        tDefaultOpType,
+		Data:      &tOpData{B: []byte("data"), A: tDefaultID, N: 7, S: "s"},
+		IsSync:    true,
+	}
+}
+
+// tUnmarshalOplog unmarshals the oplog as from the peers, with the op-data as a map.
+func tUnmarshalOplog(t *testing.T, o *BaseOplog) *BaseOplog {
+	marshaled, err := o.Marshal()
+	assert.NoError(t, err)
+
+	o2 := &BaseOplog{}
+	err = o2.Unmarshal(marshaled)
+	assert.NoError(t, err)
+	o2.SetDB(tDBOplog, tDefaultID, tDBOplogPrefix, tDBOplogIdxPrefix, tDBOplogMerklePrefix, tDBLock)
+
+	return o2
+}
+
+func TestCanonicalEncoder(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	id := &types.PttID{1, 2}
+	nodeID := &discover.NodeID{3}
+
+	// prepare test-cases
+	tests := []struct {
+		name   string
+		encode func(enc *CanonicalEncoder)
+		want   string
+	}{
+		{name: "uint", encode: func(enc *CanonicalEncoder) { enc.Uint64(300) }, want: "ac02"},
+		{name: "int", encode: func(enc *CanonicalEncoder) { enc.Int64(-2) }, want: "03"},
+		{name: "bool", encode: func(enc *CanonicalEncoder) { enc.Bool(false); enc.Bool(true) }, want: "0001"},
+		{name: "bytes", encode: func(enc *CanonicalEncoder) { enc.Bytes([]byte("ab")); enc.Bytes(nil) }, want: "02616200"},
+		{name: "bytes-list", encode: func(enc *CanonicalEncoder) { enc.BytesList([][]byte{{1}, nil}) }, want: "02010100"},
+		{name: "hashs", encode: func(enc *CanonicalEncoder) { enc.Hashs([][][]byte{{{1}}, {}}) }, want: "0201010100"},
+		{name: "id", encode: func(enc *CanonicalEncoder) { enc.PttID(id); enc.PttID(nil) }, want: "280102000000000000000000000000000000000000000000000000000000000000000000000000000000"},
+		{name: "ids", encode: func(enc *CanonicalEncoder) { enc.PttIDs([]*types.PttID{nil}) }, want: "0100"},
+		{name: "node-id", encode: func(enc *CanonicalEncoder) { enc.NodeID(nodeID); enc.NodeID(nil) }, want: "400300000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"},
+		{name: "node-id-weights", encode: func(enc *CanonicalEncoder) {
+			enc.NodeIDWeights(map[discover.NodeID]uint32{{3}: 2, {1}: 1})
+		}, want: "02400100000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001400300000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000002"},
+		{name: "timestamp", encode: func(enc *CanonicalEncoder) { enc.Timestamp(types.Timestamp{Ts: 1, NanoTs: 2}) }, want: "000000000000000100000002"},
+		{name: "data", encode: func(enc *CanonicalEncoder) { enc.Data(nil); enc.Data((*tOpData)(nil)) }, want: "0000"},
+	}
+
+	// run test
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc := NewCanonicalEncoder()
+			tt.encode(enc)
+			got, err := enc.Encoded()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, hex.EncodeToString(got))
+		})
+	}
+}
+
+func TestMarshalCanonicalData(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	id := &types.PttID{1, 2}
+	salt := &types.Salt{4}
+
+	// prepare test-cases
+	tests := []struct {
+		name string
+		data CanonicalOpData
+		want string
+	}{
+		{name: "test", data: &tOpData{B: []byte{1}, A: id, N: 2}, want: "0101010128010200000000000000000000000000000000000000000000000000000000000000000000000000000200"},
+		{name: "OpCreateMedia", data: &OpCreateMedia{BlockInfoID: id, File: &MediaDataFile{Chunks: [][]byte{{1}}, Filename: []byte("f"), Size: 3}, Hashs: [][][]byte{{{2}}}, NBlock: 1, MediaType: MediaTypeFile}, want: "010128010200000000000000000000000000000000000000000000000000000000000000000000000000000101010101016606010101020203"},
+		{name: "OpCreateMedia-without-file", data: &OpCreateMedia{BlockInfoID: id, Hashs: [][][]byte{{{2}}}, NBlock: 1, MediaType: MediaTypePNG}, want: "0101280102000000000000000000000000000000000000000000000000000000000000000000000000000000010101020202"},
+		{name: "OpDeleteMedia", data: &OpDeleteMedia{}, want: "0101"},
+		{name: "PersonOpTransferPerson", data: &PersonOpTransferPerson{ToID: id}, want: "01012801020000000000000000000000000000000000000000000000000000000000000000000000000000"},
+		{name: "MasterOpCreateMaster", data: &MasterOpCreateMaster{}, want: "0101"},
+		{name: "MemberOpAddMember", data: &MemberOpAddMember{}, want: "0101"},
+		{name: "MemberOpDeleteMember", data: &MemberOpDeleteMember{}, want: "0101"},
+		{name: "OpKeyOpCreateOpKey", data: &OpKeyOpCreateOpKey{}, want: "0101"},
+		{name: "OpKeyOpRevokeOpKey", data: &OpKeyOpRevokeOpKey{}, want: "0101"},
+		{name: "KeyBIP32", data: &KeyBIP32{Parent: []byte{5}, Salt: salt, Child: 6}, want: "0101010520040000000000000000000000000000000000000000000000000000000000000006"},
+	}
+
+	// run test
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MarshalCanonicalData(tt.data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, hex.EncodeToString(got))
+		})
+	}
+
+	_, err := MarshalCanonicalData(&tInvalidOpData{})
+	assert.Equal(t, ErrInvalidData, err)
+}
+
+func TestRegisterOpData(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	// prepare test-cases
+	tests := []struct {
+		dbPrefix []byte
+		op       OpType
+		data     CanonicalOpData
+	}{
+		{DBMasterOplogPrefix, MasterOpTypeAddMaster, &MasterOpCreateMaster{}},
+		{DBMasterOplogPrefix, MasterOpTypeMigrateMaster, &PersonOpTransferPerson{ToID: tDefaultID}},
+		{DBMasterOplogPrefix, MasterOpTypeTransferMaster, &PersonOpTransferPerson{ToID: tDefaultID}},
+		{DBMemberOplogPrefix, MemberOpTypeAddMember, &MemberOpAddMember{}},
+		{DBMemberOplogPrefix, MemberOpTypeDeleteMember, &MemberOpDeleteMember{}},
+		{DBMemberOplogPrefix, MemberOpTypeMigrateMember, &PersonOpTransferPerson{ToID: tDefaultID}},
+		{DBOpKeyOplogPrefix, OpKeyOpTypeCreateOpKey, &OpKeyOpCreateOpKey{}},
+		{DBOpKeyOplogPrefix, OpKeyOpTypeRevokeOpKey, &OpKeyOpRevokeOpKey{}},
+	}
+
+	// run test
+	for _, tt := range tests {
+		o, err := NewOplog(tDefaultID, tDefaultTimestamp, tMyID, tt.op, tt.data, nil, tDefaultID, tt.dbPrefix, nil, nil, nil)
+		assert.NoError(t, err)
+		o.SignV = SignVersionCanonical
+
+		want, err := o.MarshalCanonical()
+		assert.NoError(t, err)
+
+		// the same for the op-data unmarshaled from the peers.
+		marshaled, err := o.Marshal()
+		assert.NoError(t, err)
+		o2 := &BaseOplog{}
+		err = o2.Unmarshal(marshaled)
+		assert.NoError(t, err)
+		o2.SetDB(nil, tDefaultID, tt.dbPrefix, nil, nil, nil)
+
+		got, err := o2.MarshalCanonical()
+		assert.NoError(t, err)
+		assert.Equal(t, want, got, string(tt.dbPrefix))
+	}
+}
+
+func TestOplog_MarshalCanonical(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	o := tNewCanonicalOplog()
+	o.SignV = SignVersionCanonical
+
+	got, err := o.MarshalCanonical()
+	assert.NoError(t, err)
+	assert.Equal(t, "01022871562b71999873db5b286df957af199ec94617f7303132333435363738396162636465666768696a280d3ab14bbad3d99f4203bd7a11acb94882050e7e0194fdc2fa2ffcc041d3ff12045b73c86e4ff95f00000000075bcd1500000002280d3ab14bbad3d99f4203bd7a11acb94882050e7e303132333435363738396162636465666768696a0100010104646174612871562b71999873db5b286df957af199ec94617f7303132333435363738396162636465666768696a070173", hex.EncodeToString(got))
+
+	// the same for the op-data unmarshaled from the peers.
+	o2 := tUnmarshalOplog(t, o)
+	_, ok := o2.Data.(map[string]interface{})
+	assert.True(t, ok)
+
+	got2, err := o2.MarshalCanonical()
+	assert.NoError(t, err)
+	assert.Equal(t, got, got2)
+
+	// the op-data of the op not registered with the db-prefix.
+	o2.Op = tDefaultOpType + 1
+	_, err = o2.MarshalCanonical()
+	assert.Equal(t, ErrInvalidData, err)
+
+	o2.Op = tDefaultOpType
+	o2.SetDB(tDBOplog, tDefaultID, []byte(".xxlg"), tDBOplogIdxPrefix, tDBOplogMerklePrefix, tDBLock)
+	_, err = o2.MarshalCanonical()
+	assert.Equal(t, ErrInvalidData, err)
+}
+
+func TestOplog_SignCanonical(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	CurrentSignVersion = SignVersionCanonical
+	defer func() { CurrentSignVersion = SignVersionJSON }()
+
+	o := tNewCanonicalOplog()
+	err := o.Sign(tKeyInfoMe)
+	assert.NoError(t, err)
+	assert.Equal(t, SignVersionCanonical, o.SignV)
+	assert.Equal(t, "9f6f0fea9687e060e2142c1c0641281768fe50f2f3d18accefe2253ec437c7fd", hex.EncodeToString(o.CreatorHash))
+
+	err = o.MasterSign(tDefaultDoerID2, tDefaultSignKeyInfo2)
+	assert.NoError(t, err)
+	assert.Equal(t, "0f8467fe1517078d2bdb847a784ccfcb4ebe73021bea29c36433e1756048e8dc", hex.EncodeToString(o.MasterSigns[0].Hash))
+
+	assert.NoError(t, o.Verify())
+
+	// able to be verified after unmarshaled from the peers.
+	o2 := tUnmarshalOplog(t, o)
+	assert.NoError(t, o2.Verify())
+
+	// modified
+	o2.Data.(map[string]interface{})["N"] = 8
+	assert.Error(t, o2.Verify())
+
+	o3 := tUnmarshalOplog(t, o)
+	o3.SignV = SignVersionJSON
+	assert.Error(t, o3.Verify())
+
+	o3.SignV = SignVersionCanonical + 1
+	assert.Error(t, o3.Verify())
+}
+
+func TestOplog_SignVersion(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	// the legacy oplog is accepted in verifying.
+	legacy := &BaseOplog{}
+	err := json.Unmarshal([]byte(tLegacyOplogJSON), legacy)
+	assert.NoError(t, err)
+	assert.NoError(t, legacy.Verify())
+
+	// the newly signed oplog is in json by default.
+	o := tNewCanonicalOplog()
+	err = o.Sign(tKeyInfoMe)
+	assert.NoError(t, err)
+	assert.Equal(t, SignVersionJSON, o.SignV)
+	assert.NoError(t, o.Verify())
+
+	// canonical if activated.
+	CurrentSignVersion = SignVersionCanonical
+	defer func() { CurrentSignVersion = SignVersionJSON }()
+
+	o = tNewCanonicalOplog()
+	err = o.Sign(tKeyInfoMe)
+	assert.NoError(t, err)
+	assert.Equal(t, SignVersionCanonical, o.SignV)
+	assert.NoError(t, tUnmarshalOplog(t, o).Verify())
+}
+
+func TestOplog_VerifyLegacy(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	o := &BaseOplog{}
+	err := json.Unmarshal([]byte(tLegacyOplogJSON), o)
+	assert.NoError(t, err)
+	assert.Equal(t, SignVersionJSON, o.SignV)
+	assert.Equal(t, 1, len(o.MasterSigns))
+	assert.NoError(t, o.Verify())
+
+	// still in the legacy json after saved.
+	marshaled, err := o.Marshal()
+	assert.NoError(t, err)
+	assert.NotContains(t, string(marshaled), "\"sv\"")
+
+	// modified
+	o.Data.(map[string]interface{})["N"] = 8
+	assert.Error(t, o.Verify())
+}
@@ -0,0 +1,339 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"sync"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+)
+
+/*
+RateLimit is the token-bucket limit of the messages from a peer.
+
+Rate is the number of the tokens refilled per second,
+and Burst is the capacity of the bucket.
+*/
+type RateLimit struct {
+	Rate  float64
+	Burst float64
+}
+
+/*
+RateKey is the key of the token-buckets of a peer.
+
+The router-level limits are with only Code, and the op-level limits are with Code, EntityID and Op.
+*/
+type RateKey struct {
+	Code     CodeType
+	EntityID types.PttID
+	Op       OpType
+}
+
+type tokenBucket struct {
+	tokens   float64
+	updateTS types.Timestamp
+
+	limit *RateLimit
+}
+
+func (b *tokenBucket) refill(ts types.Timestamp) {
+	elapsed := float64(ts.Ts-b.updateTS.Ts) + (float64(ts.NanoTs)-float64(b.updateTS.NanoTs))/1e9
+	if elapsed > 0 {
+		b.tokens += elapsed * b.limit.Rate
+		b.updateTS = ts
+	}
+	if b.tokens > b.limit.Burst {
+		b.tokens = b.limit.Burst
+	}
+}
+
+func (b *tokenBucket) take(limit *RateLimit, ts types.Timestamp) bool {
+	b.limit = limit
+	b.refill(ts)
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+
+	return true
+}
+
+/*
+PeerScore is the misbehaviour score of a peer.
+
+The score decays 1 point every ScoreDecaySeconds.
+The peer is banned until BanTS once the score reaches BanScore.
+*/
+type PeerScore struct {
+	Score    int
+	UpdateTS types.Timestamp
+	BanTS    types.Timestamp
+
+	buckets map[RateKey]*tokenBucket
+}
+
+func (s *PeerScore) decay(ts types.Timestamp) {
+	if s.Score == 0 {
+		s.UpdateTS = ts
+		return
+	}
+
+	n := (ts.Ts - s.UpdateTS.Ts) / ScoreDecaySeconds
+	if n <= 0 {
+		return
+	}
+
+	if int64(s.Score) <= n {
+		s.Score = 0
+		s.UpdateTS = ts
+		return
+	}
+
+	s.Score -= int(n)
+	s.UpdateTS.Ts += n * ScoreDecaySeconds
+}
+
+func (s *PeerScore) isBanned(ts types.Timestamp) bool {
+	return ts.IsLess(s.BanTS)
+}
+
+/*
+isExpired checks whether the score is decayed to 0, the peer is not banned,
+and all the token-buckets are refilled, which is the same as the new peer-score.
+*/
+func (s *PeerScore) isExpired(ts types.Timestamp) bool {
+	s.decay(ts)
+	if s.Score != 0 || s.isBanned(ts) {
+		return false
+	}
+
+	for _, bucket := range s.buckets {
+		bucket.refill(ts)
+		if bucket.tokens < bucket.limit.Burst {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+PeerScores keeps the token-buckets, the misbehaviour scores and the bans of the peers.
+*/
+type PeerScores struct {
+	lock sync.Mutex
+
+	scores map[discover.NodeID]*PeerScore
+}
+
+func NewPeerScores() *PeerScores {
+	return &PeerScores{
+		scores: make(map[discover.NodeID]*PeerScore),
+	}
+}
+
+func (s *PeerScores) getOrNew(id *discover.NodeID, ts types.Timestamp) *PeerScore {
+	score, ok := s.scores[*id]
+	if !ok {
+		score = &PeerScore{UpdateTS: ts, buckets: make(map[RateKey]*tokenBucket)}
+		s.scores[*id] = score
+	}
+
+	return score
+}
+
+/*
+Allow takes a token from the bucket of the key of the peer.
+The bucket is full when it is first used. No limit if limit is nil.
+*/
+func (s *PeerScores) Allow(id *discover.NodeID, key *RateKey, limit *RateLimit) (bool, error) {
+	if limit == nil {
+		return true, nil
+	}
+
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return false, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	score := s.getOrNew(id, ts)
+	bucket, ok := score.buckets[*key]
+	if !ok {
+		bucket = &tokenBucket{tokens: limit.Burst, updateTS: ts, limit: limit}
+		score.buckets[*key] = bucket
+	}
+
+	return bucket.take(limit, ts), nil
+}
+
+/*
+Add adds the misbehaviour score to the peer.
+The peer is banned for BanSeconds and the score is reset if the score reaches BanScore.
+
+Return: isBanned (newly banned), error
+*/
+func (s *PeerScores) Add(id *discover.NodeID, delta int) (bool, error) {
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return false, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	score := s.getOrNew(id, ts)
+	if score.isBanned(ts) {
+		return false, nil
+	}
+
+	score.decay(ts)
+	score.Score += delta
+	if score.Score < BanScore {
+		return false, nil
+	}
+
+	score.Score = 0
+	score.UpdateTS = ts
+	score.BanTS = ts
+	score.BanTS.Ts += BanSeconds
+	score.buckets = make(map[RateKey]*tokenBucket)
+
+	return true, nil
+}
+
+func (s *PeerScores) IsBanned(id *discover.NodeID) bool {
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return false
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	score, ok := s.scores[*id]
+	if !ok {
+		return false
+	}
+
+	return score.isBanned(ts)
+}
+
+/*
+Get gets the current score of the peer and the ts that the peer is banned until
+(zero-ts if the peer is not banned).
+*/
+func (s *PeerScores) Get(id *discover.NodeID) (int, types.Timestamp, error) {
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return 0, types.ZeroTimestamp, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	score, ok := s.scores[*id]
+	if !ok {
+		return 0, types.ZeroTimestamp, nil
+	}
+
+	score.decay(ts)
+	if !score.isBanned(ts) {
+		return score.Score, types.ZeroTimestamp, nil
+	}
+
+	return score.Score, score.BanTS, nil
+}
+
+/*
+BannedNodes returns the nodes currently banned.
+*/
+func (s *PeerScores) BannedNodes() ([]*discover.NodeID, error) {
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	nodeIDs := make([]*discover.NodeID, 0)
+	for id, score := range s.scores {
+		if !score.isBanned(ts) {
+			continue
+		}
+
+		nodeID := id
+		nodeIDs = append(nodeIDs, &nodeID)
+	}
+
+	return nodeIDs, nil
+}
+
+/*
+Remove removes the expired peer-score of the disconnected peer.
+The token-buckets, the score and the ban are kept until they expire,
+so that the peer is unable to refill the buckets by reconnecting.
+*/
+func (s *PeerScores) Remove(id *discover.NodeID) error {
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	score, ok := s.scores[*id]
+	if !ok {
+		return nil
+	}
+
+	if score.isExpired(ts) {
+		delete(s.scores, *id)
+	}
+
+	return nil
+}
+
+/*
+Expire removes the expired peer-scores.
+*/
+func (s *PeerScores) Expire() error {
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for id, score := range s.scores {
+		if !score.isExpired(ts) {
+			continue
+		}
+
+		delete(s.scores, id)
+	}
+
+	return nil
+}
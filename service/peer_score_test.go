@@ -0,0 +1,258 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerScores_Allow(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	ts := tDefaultTimestamp
+	types.GetTimestamp = func() (types.Timestamp, error) {
+		return ts, nil
+	}
+
+	s := NewPeerScores()
+	id := &discover.NodeID{1}
+	id2 := &discover.NodeID{2}
+	limit := &RateLimit{Rate: 2, Burst: 3}
+	key := &RateKey{Code: CodeTypeOp, EntityID: *tDefaultID, Op: IdentifyPeerMsg}
+	key2 := &RateKey{Code: CodeTypeOp, EntityID: *tDefaultID, Op: IdentifyPeerAckMsg}
+
+	// burst
+	for i := 0; i < 3; i++ {
+		isAllowed, err := s.Allow(id, key, limit)
+		assert.NoError(t, err)
+		assert.True(t, isAllowed)
+	}
+	isAllowed, _ := s.Allow(id, key, limit)
+	assert.False(t, isAllowed)
+
+	// buckets are for each peer and each key.
+	isAllowed, _ = s.Allow(id2, key, limit)
+	assert.True(t, isAllowed)
+	isAllowed, _ = s.Allow(id, key2, limit)
+	assert.True(t, isAllowed)
+
+	// no limit
+	isAllowed, _ = s.Allow(id, key, nil)
+	assert.True(t, isAllowed)
+
+	// refill: 2 tokens in 1 second.
+	ts.Ts++
+	isAllowed, _ = s.Allow(id, key, limit)
+	assert.True(t, isAllowed)
+	isAllowed, _ = s.Allow(id, key, limit)
+	assert.True(t, isAllowed)
+	isAllowed, _ = s.Allow(id, key, limit)
+	assert.False(t, isAllowed)
+
+	// refill with nano-ts: 1 token in 0.5 second.
+	ts.NanoTs += 500000000
+	isAllowed, _ = s.Allow(id, key, limit)
+	assert.True(t, isAllowed)
+	isAllowed, _ = s.Allow(id, key, limit)
+	assert.False(t, isAllowed)
+
+	// not more than burst.
+	ts.Ts += 100
+	for i := 0; i < 3; i++ {
+		isAllowed, _ = s.Allow(id, key, limit)
+		assert.True(t, isAllowed)
+	}
+	isAllowed, _ = s.Allow(id, key, limit)
+	assert.False(t, isAllowed)
+
+	// the buckets are kept after the peer is removed (reconnecting).
+	s.Remove(id)
+	s.Expire()
+	isAllowed, _ = s.Allow(id, key, limit)
+	assert.False(t, isAllowed)
+
+	// expired after the buckets are refilled.
+	ts.Ts++
+	s.Expire()
+	assert.Equal(t, 1, len(s.scores))
+
+	ts.Ts++
+	s.Expire()
+	assert.Equal(t, 0, len(s.scores))
+}
+
+func TestPeerScores_Add(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	ts := tDefaultTimestamp
+	types.GetTimestamp = func() (types.Timestamp, error) {
+		return ts, nil
+	}
+
+	s := NewPeerScores()
+	id := &discover.NodeID{1}
+
+	limit := &RateLimit{Rate: 1, Burst: 1}
+	key := &RateKey{Code: CodeTypeOp}
+
+	isAllowed, _ := s.Allow(id, key, limit)
+	assert.True(t, isAllowed)
+
+	isBanned, err := s.Add(id, 60)
+	assert.NoError(t, err)
+	assert.False(t, isBanned)
+
+	// removed peer keeps the buckets and the score.
+	s.Remove(id)
+	s.Expire()
+	isAllowed, _ = s.Allow(id, key, limit)
+	assert.False(t, isAllowed)
+
+	score, banTS, _ := s.Get(id)
+	assert.Equal(t, 60, score)
+	assert.Equal(t, types.ZeroTimestamp, banTS)
+
+	// decay
+	ts.Ts += 10*ScoreDecaySeconds + 1
+	score, _, _ = s.Get(id)
+	assert.Equal(t, 50, score)
+
+	// the decay keeps the remaining seconds.
+	ts.Ts += ScoreDecaySeconds - 1
+	score, _, _ = s.Get(id)
+	assert.Equal(t, 49, score)
+
+	// ban
+	isBanned, _ = s.Add(id, 50)
+	assert.False(t, isBanned)
+	isBanned, _ = s.Add(id, ScoreInvalidOplog)
+	assert.True(t, isBanned)
+	assert.True(t, s.IsBanned(id))
+
+	expectedBanTS := ts
+	expectedBanTS.Ts += BanSeconds
+	score, banTS, _ = s.Get(id)
+	assert.Equal(t, 0, score)
+	assert.Equal(t, expectedBanTS, banTS)
+
+	bannedNodeIDs, _ := s.BannedNodes()
+	assert.Equal(t, []*discover.NodeID{id}, bannedNodeIDs)
+
+	// no more score when banned.
+	isBanned, _ = s.Add(id, BanScore)
+	assert.False(t, isBanned)
+
+	// removed peer keeps the ban.
+	s.Remove(id)
+	s.Expire()
+	assert.True(t, s.IsBanned(id))
+
+	// ban expired.
+	ts.Ts += BanSeconds
+	assert.False(t, s.IsBanned(id))
+	bannedNodeIDs, _ = s.BannedNodes()
+	assert.Equal(t, 0, len(bannedNodeIDs))
+
+	s.Expire()
+	assert.Equal(t, 0, len(s.scores))
+}
+
+func TestRouter_Misbehave(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	ts := tDefaultTimestamp
+	types.GetTimestamp = func() (types.Timestamp, error) {
+		return ts, nil
+	}
+
+	r := &BaseRouter{
+		config: &Config{},
+
+		myNodeID: tDefaultNodeID,
+
+		myPeers:        make(map[discover.NodeID]*PttPeer),
+		hubPeers:       make(map[discover.NodeID]*PttPeer),
+		importantPeers: make(map[discover.NodeID]*PttPeer),
+		memberPeers:    make(map[discover.NodeID]*PttPeer),
+		pendingPeers:   make(map[discover.NodeID]*PttPeer),
+		randomPeers:    make(map[discover.NodeID]*PttPeer),
+
+		dialHist: NewDialHistory(),
+
+		peerScores: NewPeerScores(),
+	}
+
+	peerID := discover.NodeID{1}
+	peer, _ := testRelayPeer(r, peerID, Ptt5)
+
+	data, err := r.MarshalData(CodeTypeIdentifyPeerWithMyID, &tDefaultHash, []byte("identify"))
+	assert.NoError(t, err)
+	data.Node = tDefaultNodeID[:]
+
+	// rate-limited
+	limit := CodeRateLimits[CodeTypeIdentifyPeerWithMyID]
+	for i := 0; i < int(limit.Burst); i++ {
+		assert.True(t, r.AllowPeerMsg(peer, &RateKey{Code: CodeTypeIdentifyPeerWithMyID}, limit))
+	}
+	err = r.HandleMessage(CodeTypeIdentifyPeerWithMyID, data, peer)
+	assert.NoError(t, err)
+
+	score, _, _ := r.peerScores.Get(&peerID)
+	assert.Equal(t, ScoreRateLimited, score)
+
+	backendPeers, err := r.BEGetPeers()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(backendPeers))
+	assert.Equal(t, ScoreRateLimited, backendPeers[0].Score)
+
+	// banned
+	r.dialHist.Add(&peerID, &tDefaultHash)
+	for i := 0; i < BanScore/ScoreFailChallenge; i++ {
+		r.Misbehave(peer, ScoreFailChallenge)
+	}
+	assert.True(t, r.IsBanned(&peerID))
+	assert.Nil(t, r.dialHist.Get(&peerID))
+
+	err = r.HandleMessage(CodeTypeIdentifyPeerWithMyID, data, peer)
+	assert.Equal(t, ErrBanned, err)
+
+	err = r.AddDial(&peerID, &tDefaultHash, PeerTypeImportant, true)
+	assert.Equal(t, ErrBanned, err)
+
+	// disconnected banned peer is still in the peer-list.
+	delete(r.randomPeers, peerID)
+	r.peerScores.Remove(&peerID)
+
+	expectedBanTS := ts
+	expectedBanTS.Ts += BanSeconds
+	backendPeers, err = r.BEGetPeers()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(backendPeers))
+	assert.Equal(t, &peerID, backendPeers[0].NodeID)
+	assert.Equal(t, PeerTypeRemoved, backendPeers[0].PeerType)
+	assert.Equal(t, expectedBanTS, backendPeers[0].BanTS)
+}
@@ -72,7 +72,7 @@ func (p *BaseRouter) ApproveJoin(confirmKey []byte) error {
 		return err
 	}
 
-	encData, err := p.EncryptData(ApproveJoinMsg, data, keyInfo)
+	encData, err := p.EncryptDataWithPeer(CodeTypeJoinAck, keyInfo.Hash, ApproveJoinMsg, data, keyInfo, peer)
 	if err != nil {
 		return err
 	}
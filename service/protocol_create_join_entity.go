@@ -182,6 +182,8 @@ func (spm *BaseServiceProtocolManager) CreateJoinEntity(
 	entity.SetStatus(types.StatusAlive)
 	entity.Save(true)
 
+	spm.EventMux().Post(&EntityStatusEvent{EntityID: entity.GetID(), Status: types.StatusAlive})
+
 	// 13. entity start
 	if isStart {
 		log.Debug("CreateJoinEntity: to PrestartAndStart", "entity", entity.GetID(), "Service", entity.Service().Name())
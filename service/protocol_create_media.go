@@ -38,3 +38,146 @@ func (pm *BaseProtocolManager) NewMedia(theData CreateData) (Object, OpData, err
 
 	return theMedia, opData, nil
 }
+
+/*
+CreateFileMedia creates the media from the completed upload.
+
+The meta of the file (filename, size and the hashs of the chunks) is in the signed create-media oplog,
+and the chunks are synced with SyncMediaChunk.
+*/
+func (pm *BaseProtocolManager) CreateFileMedia(
+	uploadID *types.PttID,
+	createOp OpType,
+
+	merkle *Merkle,
+
+	newOplogWithTS func(objID *types.PttID, ts types.Timestamp, op OpType, opData OpData) (Oplog, error),
+
+	setLogDB func(oplog *BaseOplog),
+	broadcastLogs func(oplogs []*BaseOplog) error,
+	broadcastLog func(oplog *BaseOplog) error,
+) (*Media, error) {
+
+	dbLock := pm.DBObjLock()
+	err := dbLock.Lock(uploadID)
+	if err != nil {
+		return nil, err
+	}
+	defer dbLock.Unlock(uploadID)
+
+	uploadFile, err := pm.GetUploadFile(uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !uploadFile.IsComplete() {
+		return nil, ErrIncompleteUpload
+	}
+	if len(missingMediaChunks(pm.DB().DB(), uploadFile.Chunks, 1)) != 0 {
+		return nil, ErrIncompleteUpload
+	}
+
+	theMedia, err := pm.CreateObject(
+		uploadFile,
+		createOp,
+
+		merkle,
+
+		pm.NewMedia,
+		newOplogWithTS,
+		pm.increateFileMedia,
+
+		setLogDB,
+		broadcastLogs,
+		broadcastLog,
+
+		pm.postcreateMedia,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	media, ok := theMedia.(*Media)
+	if !ok {
+		return nil, ErrInvalidData
+	}
+
+	// the chunks are referenced by the media now.
+	err = refMediaChunks(pm.DB().DB(), media.GetFileData().Chunks, media.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = pm.removeUploadFile(uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	return media, nil
+}
+
+func (pm *BaseProtocolManager) increateFileMedia(theObj Object, theData CreateData, oplog *BaseOplog, theOpData OpData) error {
+
+	obj, ok := theObj.(*Media)
+	if !ok {
+		return ErrInvalidData
+	}
+
+	data, ok := theData.(*UploadFile)
+	if !ok {
+		return ErrInvalidData
+	}
+
+	opData, ok := theOpData.(*OpCreateMedia)
+	if !ok {
+		return ErrInvalidData
+	}
+
+	fileData := &MediaDataFile{
+		Filename: data.Filename,
+		Size:     data.Size,
+		Chunks:   data.Chunks,
+	}
+
+	obj.MediaType = MediaTypeFile
+	obj.MediaData = fileData
+
+	opData.MediaType = MediaTypeFile
+	opData.File = fileData
+
+	return nil
+}
+
+/*
+postcreateMedia refers the chunks of the chunked file, and starts syncing the missing chunks.
+*/
+func (pm *BaseProtocolManager) postcreateMedia(theObj Object, oplog *BaseOplog) error {
+	media, ok := theObj.(*Media)
+	if !ok {
+		return ErrInvalidData
+	}
+
+	if !media.IsChunkedFile() {
+		return nil
+	}
+
+	db := pm.DB().DB()
+
+	err := refMediaChunks(db, media.GetFileData().Chunks, media.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(missingMediaChunks(db, media.GetFileData().Chunks, 1)) == 0 {
+		return nil
+	}
+
+	err = saveMediaChunkPending(db, media.EntityID, media.ID)
+	if err != nil {
+		return err
+	}
+
+	pm.SyncMediaChunk(media, nil)
+
+	return nil
+}
@@ -41,7 +41,7 @@ func (pm *BaseProtocolManager) HandleCreateMediaLogs(
 
 		existsInInfo,
 		pm.newMediaWithOplog,
-		nil,
+		pm.postcreateMedia,
 		updateCreateInfo,
 	)
 }
@@ -67,7 +67,7 @@ func (pm *BaseProtocolManager) HandlePendingCreateMediaLogs(
 
 		existsInInfo,
 		pm.newMediaWithOplog,
-		nil,
+		pm.postcreateMedia,
 		updateCreateInfo,
 	)
 }
@@ -106,6 +106,13 @@ func (pm *BaseProtocolManager) newMediaWithOplog(oplog *BaseOplog, theOpData OpD
 	pm.SetMediaDB(obj)
 	NewObjectWithOplog(obj, oplog)
 
+	// chunked file: the meta is in the oplog, no blocks.
+	if opData.File != nil {
+		obj.MediaType = MediaTypeFile
+		obj.MediaData = opData.File
+		return obj
+	}
+
 	blockInfo, err := NewBlockInfo(opData.BlockInfoID, opData.Hashs, nil, oplog.CreatorID)
 	if err != nil {
 		return nil
@@ -119,6 +119,8 @@ func (pm *BaseProtocolManager) DeleteEntity(
 		return nil
 	}
 
+	pm.PostEntityStatusEvent()
+
 	if postdelete != nil {
 		postdelete(opData, false)
 	}
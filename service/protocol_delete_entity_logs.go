@@ -110,6 +110,8 @@ func (pm *BaseProtocolManager) HandleDeleteEntityLog(
 		return nil, err
 	}
 
+	pm.PostEntityStatusEvent()
+
 	// 7.1
 	if postdelete != nil {
 		postdelete(opData, false)
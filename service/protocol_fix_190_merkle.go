@@ -49,7 +49,7 @@ func (pm *BaseProtocolManager) isFix190Merkle() (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	_, err = dbMeta.Get(key)
+	_, err = pm.Router().DBMeta().Get(key)
 	if err == leveldb.ErrNotFound {
 		return false, nil
 	}
@@ -70,7 +70,7 @@ func (pm *BaseProtocolManager) setFix190Merkle() error {
 		return err
 	}
 
-	dbMeta.Put(key, pttdb.ValueTrue)
+	pm.Router().DBMeta().Put(key, pttdb.ValueTrue)
 
 	return nil
 }
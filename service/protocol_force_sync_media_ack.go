@@ -67,6 +67,9 @@ func (pm *BaseProtocolManager) HandleForceSyncMediaAck(
 		}
 
 		blockInfo = obj.GetBlockInfo()
+		if blockInfo == nil {
+			continue
+		}
 
 		logID = obj.LogID
 		if obj.GetUpdateLogID() != nil {
@@ -30,6 +30,11 @@ func (pm *BaseProtocolManager) GetMedia(mediaID *types.PttID) (*Media, error) {
 		return nil, err
 	}
 
+	// the chunked file is read with GetMediaChunk / NewMediaReader.
+	if media.IsChunkedFile() {
+		return media, nil
+	}
+
 	err = media.GetBuf()
 	if err != nil {
 		return nil, err
@@ -37,3 +42,27 @@ func (pm *BaseProtocolManager) GetMedia(mediaID *types.PttID) (*Media, error) {
 
 	return media, nil
 }
+
+/*
+GetMediaChunk gets the idx-th chunk of the chunked file.
+*/
+func (pm *BaseProtocolManager) GetMediaChunk(mediaID *types.PttID, idx int) ([]byte, error) {
+	media, err := pm.getChunkedMedia(mediaID)
+	if err != nil {
+		return nil, err
+	}
+
+	return media.GetChunk(idx)
+}
+
+/*
+NewMediaReader returns the reader streaming the chunks of the chunked file.
+*/
+func (pm *BaseProtocolManager) NewMediaReader(mediaID *types.PttID) (*MediaReader, error) {
+	media, err := pm.getChunkedMedia(mediaID)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMediaReader(media), nil
+}
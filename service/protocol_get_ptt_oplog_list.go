@@ -28,7 +28,7 @@ func (r *BaseRouter) GetPttOplogList(logID *types.PttID, limit int, listOrder pt
 
 	oplog := &BaseOplog{}
 	myID := r.myEntity.GetID()
-	SetPttDB(myID, oplog)
+	r.SetPttDB(myID, oplog)
 
 	oplogs, err := GetOplogList(oplog, logID, limit, listOrder, status, false)
 	if err != nil {
@@ -173,6 +173,8 @@ func (pm *BaseProtocolManager) HandleSyncNewMasterOplogAck(dataBytes []byte, pee
 		pm.SetMasterDB,
 		pm.HandleMasterOplogs,
 		pm.postsyncMasterOplogs,
+
+		pm.MasterMerkle(),
 	)
 }
 
@@ -169,6 +169,8 @@ func (pm *BaseProtocolManager) HandleSyncNewMemberOplogAck(dataBytes []byte, pee
 		pm.HandleMemberOplogs,
 
 		pm.postsyncMemberOplogs,
+
+		pm.MemberMerkle(),
 	)
 }
 
@@ -24,7 +24,7 @@ import (
 
 /*
 HandleOplogs handles a list of Oplog.
-    1. verify all the oplogs, return if any of the log is invalid.
+    1. verify all the oplogs, return if any of the log is invalid. drop the oplogs created by the blocked users.
     2. preset oplog (isSync as false and setDB)
     3. check pre-log-id
     4. integrate existing oplog. skip if already synced.
@@ -576,10 +576,19 @@ func preprocessOplogs(
 		err = oplog.Verify()
 		if err != nil {
 			log.Warn("preprocessOplogs: unable to verify oplog", "op", oplog.Op, "e", err)
+			if peer != nil {
+				pm.Router().Misbehave(peer, ScoreInvalidOplog)
+			}
 			return nil, err
 		}
 	}
 
+	// blocked creators (the creator-id is verified with the sign)
+	oplogs = filterBlockedOplogs(oplogs, pm.Router())
+	if len(oplogs) == 0 {
+		return oplogs, nil
+	}
+
 	// check pre-log-id
 	// XXX prelog as shared tmp-variable.
 	prelog := &BaseOplog{}
@@ -625,3 +634,31 @@ func checkPreOplog(oplog *BaseOplog, prelog *BaseOplog, existIDs map[types.PttID
 	existIDs[*oplog.ID] = oplog
 	return nil
 }
+
+/*
+filterBlockedOplogs drops the oplogs created by the blocked users,
+regardless of the peers that the oplogs are from (ex: relayed by the other members).
+*/
+func filterBlockedOplogs(oplogs []*BaseOplog, ptt Router) []*BaseOplog {
+	filtered := oplogs[:0]
+	for _, oplog := range oplogs {
+		if isBlockedCreator(ptt, oplog.CreatorID) {
+			log.Debug("filterBlockedOplogs: blocked", "op", oplog.Op, "id", oplog.ID, "creator", oplog.CreatorID)
+			continue
+		}
+		filtered = append(filtered, oplog)
+	}
+
+	return filtered
+}
+
+/*
+isBlockedCreator checks whether the creator of the oplog / object is in the block-list of me.
+*/
+func isBlockedCreator(ptt Router, creatorID *types.PttID) bool {
+	if ptt == nil {
+		return false
+	}
+
+	return ptt.IsBlockedUser(creatorID)
+}
@@ -0,0 +1,62 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"github.com/ailabstw/go-pttai-core/log"
+)
+
+type HubAnnounce struct{}
+
+/*
+HubAnnounce announces that we are the hub to the peer (hub)
+*/
+func (r *BaseRouter) HubAnnounce(peer *PttPeer) error {
+	return r.SendDataToPeer(CodeTypeHubAnnounce, &HubAnnounce{}, peer)
+}
+
+/*
+HandleHubAnnounce handles HubAnnounce (requester)
+	1. learn the hub.
+	2. set the peer as hub-peer.
+	3. identify with my-id, for the hub to deliver the data relayed to me.
+	4. push the data that are not delivered yet to the hub.
+*/
+func (r *BaseRouter) HandleHubAnnounce(dataBytes []byte, peer *PttPeer) error {
+	// 1. learn the hub.
+	err := r.AddHubNode(peer.GetID())
+	if err != nil {
+		return err
+	}
+
+	// 2. set the peer as hub-peer.
+	err = r.setHubPeer(peer)
+	if err != nil {
+		return err
+	}
+
+	// 3. identify with my-id.
+	if peer.UserID == nil {
+		err = r.IdentifyPeerWithMyID(peer)
+		if err != nil {
+			log.Warn("HandleHubAnnounce: unable to IdentifyPeerWithMyID", "peer", peer, "e", err)
+		}
+	}
+
+	// 4. push to hub.
+	return r.PushToHub(peer)
+}
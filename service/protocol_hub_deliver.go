@@ -0,0 +1,128 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/ailabstw/go-pttai-core/log"
+)
+
+type HubDeliver struct {
+	Data *RouterData `json:"D"`
+}
+
+/*
+DeliverHubRelays delivers the data relayed to the identified peer, and removes the delivered data (hub)
+*/
+func (r *BaseRouter) DeliverHubRelays(peer *PttPeer) error {
+	if !r.config.IsHub || peer.UserID == nil {
+		return nil
+	}
+
+	for {
+		relays, keys, err := r.hubRelays.Get(peer.UserID, MaxDeliverHubRelay)
+		if err != nil {
+			return err
+		}
+		if len(relays) == 0 {
+			break
+		}
+
+		log.Debug("DeliverHubRelays: to deliver", "userID", peer.UserID, "peer", peer, "relays", len(relays))
+
+		for i, relay := range relays {
+			err = r.SendDataToPeer(CodeTypeHubDeliver, &HubDeliver{Data: relay.Data}, peer)
+			if err != nil {
+				return err
+			}
+
+			err = r.hubRelays.Remove(keys[i])
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+/*
+HandleHubDeliver handles HubDeliver (recipient)
+	1. unmarshal the relayed data and verify the original sender.
+	2. get the entity from the op-key, and check the sender with the block-list.
+	3. decrypt the relayed data.
+	4. have the pm handle the relayed message.
+*/
+func (r *BaseRouter) HandleHubDeliver(dataBytes []byte, peer *PttPeer) error {
+	if !r.IsHubPeer(peer) {
+		return ErrNotHub
+	}
+
+	data := &HubDeliver{}
+	err := json.Unmarshal(dataBytes, data)
+	if err != nil {
+		return err
+	}
+
+	if data.Data == nil {
+		return ErrInvalidData
+	}
+
+	// 1. unmarshal
+	code, hash, encData, fromID, err := r.UnmarshalRelayData(data.Data)
+	if err != nil {
+		return err
+	}
+
+	if code != CodeTypeOp || hash == nil || !reflect.DeepEqual(hash[:], data.Data.Hash[:]) {
+		return ErrInvalidData
+	}
+
+	// 2. entity
+	entity, err := r.getEntityFromHash(hash, &r.lockOps, r.ops)
+	if err != nil {
+		log.Warn("HandleHubDeliver: invalid entity", "hash", hash, "e", err)
+		return err
+	}
+
+	// the hub is not the sender, checking the sender verified in the relayed data.
+	if r.isBlockedUserOp(entity, fromID) {
+		log.Debug("HandleHubDeliver: blocked", "entity", entity.IDString(), "from", fromID, "peer", peer)
+		return nil
+	}
+
+	pm := entity.PM()
+
+	// 3. decrypt
+	opKeyInfo, err := pm.GetOpKeyFromHash(hash, false)
+	if err != nil {
+		return err
+	}
+
+	// relayed data is always in the aead envelope (see RelayDataToHub)
+	op, relayedBytes, err := r.DecryptDataAEAD(CodeTypeOp, hash, encData, opKeyInfo)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("HandleHubDeliver: to HandleRelayedMessage", "entity", entity.IDString(), "op", op, "peer", peer)
+
+	// 4. handle
+	return pm.HandleRelayedMessage(op, relayedBytes)
+}
@@ -0,0 +1,77 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"encoding/json"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+)
+
+type HubRelay struct {
+	UserID *types.PttID `json:"U"`
+	Data   *RouterData  `json:"D"`
+}
+
+/*
+HubRelay relays the data to the user through the hub (requester)
+*/
+func (r *BaseRouter) HubRelay(userID *types.PttID, data *RouterData, peer *PttPeer) error {
+	relay := &HubRelay{
+		UserID: userID,
+		Data:   data,
+	}
+
+	return r.SendDataToPeer(CodeTypeHubRelay, relay, peer)
+}
+
+/*
+HandleHubRelay handles HubRelay (hub)
+	1. save the data within the quotas of the user, the sending node and the hub.
+	2. deliver the data if the user is connected.
+*/
+func (r *BaseRouter) HandleHubRelay(dataBytes []byte, peer *PttPeer) error {
+	if !r.config.IsHub {
+		return ErrNotHub
+	}
+
+	data := &HubRelay{}
+	err := json.Unmarshal(dataBytes, data)
+	if err != nil {
+		return err
+	}
+
+	if data.UserID == nil || data.Data == nil {
+		return ErrInvalidData
+	}
+
+	// 1. save
+	err = r.hubRelays.Save(data.UserID, peer.GetID(), data.Data)
+	log.Debug("HandleHubRelay: after Save", "userID", data.UserID, "peer", peer, "e", err)
+	if err != nil {
+		return err
+	}
+
+	// 2. deliver
+	userPeer, err := r.GetPeerByUserID(data.UserID, false)
+	if err != nil {
+		return nil
+	}
+
+	return r.DeliverHubRelays(userPeer)
+}
@@ -123,15 +123,22 @@ func (p *BaseRouter) HandleIdentifyPeerAck(entityID *types.PttID, data *Identify
 
 	if peer.IDChallenge == nil || data.AckChallenge == nil || !reflect.DeepEqual(peer.IDChallenge[:], data.AckChallenge[:types.SizeSalt]) {
 		log.Warn("HandleIdentifyPeerAck: unable to match challenge", "peer", peer.IDChallenge, "data", data.AckChallenge, "peer", peer)
+		p.Misbehave(peer, ScoreFailChallenge)
 		return ErrInvalidData
 	}
 
 	err := VerifyData(data.AckChallenge, data.Hash, data.Sig, data.PubBytes, data.MyID, data.Extra)
 	if err != nil {
 		log.Warn("HandleIdentifyPeerAck: unable to verify data", "peer", peer)
+		p.Misbehave(peer, ScoreFailChallenge)
 		return err
 	}
 
+	if p.IsBlockedUser(data.MyID) {
+		log.Warn("HandleIdentifyPeerAck: blocked user", "userID", data.MyID, "peer", peer)
+		return ErrBlockedUser
+	}
+
 	if peer.UserID != nil {
 		log.Debug("HandleIdentifyPeerAck: already known user-id", "peer", peer)
 
@@ -144,5 +151,10 @@ func (p *BaseRouter) HandleIdentifyPeerAck(entityID *types.PttID, data *Identify
 
 	log.Debug("HandleIdentifyPeerAck: to FinishIdentifyPeer", "peer", peer, "userID", peer.UserID)
 
-	return p.FinishIdentifyPeer(peer, false, false)
+	err = p.FinishIdentifyPeer(peer, false, false)
+	if err != nil {
+		return err
+	}
+
+	return p.DeliverHubRelays(peer)
 }
@@ -123,7 +123,7 @@ func (p *BaseRouter) join(challenge []byte, hash *common.Address, joinKey *ecdsa
 
 	keyInfo := joinKeyToKeyInfo(joinKey)
 
-	encData, err := p.EncryptData(JoinMsg, data, keyInfo)
+	encData, err := p.EncryptDataWithPeer(CodeTypeJoin, hash, JoinMsg, data, keyInfo, peer)
 	if err != nil {
 		return err
 	}
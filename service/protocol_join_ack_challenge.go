@@ -47,7 +47,7 @@ func (p *BaseRouter) JoinAckChallenge(keyInfo *KeyInfo, join *Join, peer *PttPee
 		return err
 	}
 
-	encData, err := p.EncryptData(JoinAckChallengeMsg, data, keyInfo)
+	encData, err := p.EncryptDataWithPeer(CodeTypeJoinAck, keyInfo.Hash, JoinAckChallengeMsg, data, keyInfo, peer)
 	if err != nil {
 		return err
 	}
@@ -84,6 +84,7 @@ func (p *BaseRouter) HandleJoinAckChallenge(dataBytes []byte, hash *common.Addre
 
 	if !reflect.DeepEqual(joinRequest.Challenge, joinAckChallenge.Challenge) {
 		log.Error("HandleJoinAckChallenge: challenge not the same", "peer", peer, "send", joinRequest.Challenge, "recv", joinAckChallenge.Challenge)
+		p.Misbehave(peer, ScoreFailChallenge)
 		return ErrInvalidData
 	}
 
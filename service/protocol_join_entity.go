@@ -51,7 +51,7 @@ func (r *BaseRouter) JoinEntity(joinRequest *JoinRequest, joinAckChallenge *Join
 
 	keyInfo := joinKeyToKeyInfo(joinRequest.Key)
 
-	encData, err := r.EncryptData(JoinEntityMsg, data, keyInfo)
+	encData, err := r.EncryptDataWithPeer(CodeTypeJoin, joinRequest.Hash, JoinEntityMsg, data, keyInfo, peer)
 	if err != nil {
 		return err
 	}
@@ -93,6 +93,11 @@ func (r *BaseRouter) HandleJoinEntity(dataBytes []byte, hash *common.Address, en
 
 	id := joinEntity.ID
 	nodeID := peer.GetID()
+	if r.IsBlockedUser(id) {
+		log.Warn("HandleJoinEntity: blocked user", "id", id, "peer", peer)
+		return ErrBlockedUser
+	}
+
 	if entity.PM().IsSuspiciousID(id, nodeID) {
 		return ErrInvalidData
 	}
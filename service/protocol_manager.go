@@ -38,6 +38,7 @@ type ProtocolManager interface {
 
 	HandleNonRegisteredMessage(op OpType, dataBytes []byte, peer *PttPeer) error
 	HandleMessage(op OpType, dataBytes []byte, peer *PttPeer) error
+	HandleRelayedMessage(op OpType, dataBytes []byte) error
 
 	Sync(peer *PttPeer) error
 
@@ -174,6 +175,8 @@ type ProtocolManager interface {
 	HandleMemberOplogs(oplogs []*BaseOplog, peer *PttPeer, isUpdateSyncTime bool) error
 	SetMemberSyncTime(ts types.Timestamp) error
 
+	SetMemberDB(oplog *BaseOplog)
+
 	GetMemberOplogList(logID *types.PttID, limit int, listOrder pttdb.ListOrder, status types.Status) ([]*MemberOplog, error)
 	GetMemberOplogMerkleNodeList(level MerkleTreeLevel, startKey []byte, limit int, listOrder pttdb.ListOrder) ([]*MerkleNode, error)
 
@@ -186,6 +189,7 @@ type ProtocolManager interface {
 	// log0
 	SetLog0DB(oplog *BaseOplog)
 	Log0Merkle() *Merkle
+	CountPendingLog0s() (int, error)
 
 	HandleLog0s(logs []*BaseOplog, peer *PttPeer, isUpdateSyncTime bool) error
 
@@ -217,7 +221,7 @@ type ProtocolManager interface {
 	ToRenewOpKeyTS() (types.Timestamp, error)
 
 	DBOpKeyLock() *types.LockMap
-	DBOpKey() *pttdb.LDBBatch
+	DBOpKey() pttdb.IndexBatch
 	DBOpKeyPrefix() []byte
 	DBOpKeyIdxPrefix() []byte
 
@@ -256,6 +260,26 @@ type ProtocolManager interface {
 
 	GetOpKeyOplogList(logID *types.PttID, limit int, listOrder pttdb.ListOrder, status types.Status) ([]*OpKeyOplog, error)
 
+	// media-chunk
+	SyncMediaChunk(media *Media, peer *PttPeer) error
+	HandleSyncMediaChunk(dataBytes []byte, peer *PttPeer) error
+	HandleSyncMediaChunkAck(dataBytes []byte, peer *PttPeer) error
+	ForceSyncMediaChunk(peer *PttPeer) error
+
+	// hub
+	PushToHub(peer *PttPeer) error
+	RelayDataToHub(op OpType, data interface{}, userID *types.PttID, peer *PttPeer) error
+
+	GetHubRelayTS(nodeID *discover.NodeID) (types.Timestamp, error)
+	SetHubRelayTS(nodeID *discover.NodeID, ts types.Timestamp) error
+
+	// relay
+	RelayDataToNode(op OpType, data interface{}, nodeID *discover.NodeID) error
+
+	// rate-limit
+	SetOpRateLimit(op OpType, limit *RateLimit)
+	OpRateLimit(op OpType) *RateLimit
+
 	// peers
 	Peers() *PttPeerSet
 
@@ -309,7 +333,7 @@ type ProtocolManager interface {
 	Router() Router
 
 	// db
-	DB() *pttdb.LDBBatch
+	DB() pttdb.IndexBatch
 	DBObjLock() *types.LockMap
 }
 
@@ -391,6 +415,8 @@ type BaseProtocolManager struct {
 
 	forceOpKey chan struct{}
 
+	opRateLimits map[OpType]*RateLimit
+
 	// op-key-oplog
 	dbOpKeyLock *types.LockMap
 
@@ -433,7 +459,7 @@ type BaseProtocolManager struct {
 	ptt Router
 
 	// db
-	db     *pttdb.LDBBatch
+	db     pttdb.IndexBatch
 	dbLock *types.LockMap
 
 	// block
@@ -485,7 +511,7 @@ func NewBaseProtocolManager(
 	svc Service,
 
 	// db
-	db *pttdb.LDBBatch,
+	db pttdb.IndexBatch,
 
 ) (*BaseProtocolManager, error) {
 
@@ -580,6 +606,8 @@ func NewBaseProtocolManager(
 
 		forceOpKey: make(chan struct{}),
 
+		opRateLimits: newOpRateLimits(),
+
 		// op-key-oplog
 		dbOpKeyLock: dbOpKeyLock,
 
@@ -684,6 +712,10 @@ func (pm *BaseProtocolManager) HandleMessage(op OpType, dataBytes []byte, peer *
 	return types.ErrNotImplemented
 }
 
+func (pm *BaseProtocolManager) HandleRelayedMessage(op OpType, dataBytes []byte) error {
+	return types.ErrNotImplemented
+}
+
 func (pm *BaseProtocolManager) Prestart() error {
 	if pm.isPrestart {
 		log.Warn("Prestart: already prestarted", "entity", pm.Entity().IDString())
@@ -874,7 +906,7 @@ func (pm *BaseProtocolManager) Router() Router {
 	return pm.ptt
 }
 
-func (pm *BaseProtocolManager) DB() *pttdb.LDBBatch {
+func (pm *BaseProtocolManager) DB() pttdb.IndexBatch {
 	return pm.db
 }
 
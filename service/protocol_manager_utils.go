@@ -164,23 +164,35 @@ func (pm *BaseProtocolManager) sendDataToPeers(op OpType, data interface{}, peer
 		return err
 	}
 
+	// encrypt once for each enc-version negotiated with the peers.
 	ptt := pm.Router()
-	encData, err := ptt.EncryptData(op, dataBytes, opKeyInfo)
-	if err != nil {
-		return err
-	}
-
-	pttData, err := ptt.MarshalData(CodeTypeOp, opKeyInfo.Hash, encData)
-	if err != nil {
-		return err
-	}
+	encDataByVersion := make(map[uint8][]byte)
+	pttDataByVersion := make(map[uint8]*RouterData)
 
 	okCount := 0
 	for _, peer := range peerList {
+		encVersion := peer.EncVersion()
+		pttData, ok := pttDataByVersion[encVersion]
+		if !ok {
+			encData, err := ptt.EncryptDataWithPeer(CodeTypeOp, opKeyInfo.Hash, op, dataBytes, opKeyInfo, peer)
+			if err != nil {
+				return err
+			}
+
+			pttData, err = ptt.MarshalData(CodeTypeOp, opKeyInfo.Hash, encData)
+			if err != nil {
+				return err
+			}
+
+			encDataByVersion[encVersion] = encData
+			pttDataByVersion[encVersion] = pttData
+		}
+
 		pttData.Node = peer.GetID()[:]
 		err := peer.SendData(pttData)
 		if err == nil {
 			okCount++
+			markOpMsg(MetricDirOut, pm, op, len(encDataByVersion[encVersion]))
 		} else {
 			log.Warn("sendDataToPeers: unable to SendData", "peer", peer, "entity", pm.Entity().IDString(), "e", err)
 		}
@@ -240,7 +252,7 @@ func (pm *BaseProtocolManager) sendDataToPeerWithCode(code CodeType, op OpType,
 	}
 
 	ptt := pm.Router()
-	encData, err := ptt.EncryptData(op, dataBytes, opKeyInfo)
+	encData, err := ptt.EncryptDataWithPeer(code, opKeyInfo.Hash, op, dataBytes, opKeyInfo, peer)
 	if err != nil {
 		return err
 	}
@@ -259,5 +271,7 @@ func (pm *BaseProtocolManager) sendDataToPeerWithCode(code CodeType, op OpType,
 		return err
 	}
 
+	markOpMsg(MetricDirOut, pm, op, len(encData))
+
 	return nil
 }
@@ -21,19 +21,27 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
-func PMHandleMessageWrapper(pm ProtocolManager, hash *common.Address, encData []byte, peer *PttPeer) error {
+func PMHandleMessageWrapper(pm ProtocolManager, code CodeType, hash *common.Address, encData []byte, peer *PttPeer) error {
 	opKeyInfo, err := pm.GetOpKeyFromHash(hash, false)
 	if err != nil {
 		log.Error("PMHandleMessageWrapper: unable to GetOpKeyFromHash", "e", err, "entity", pm.Entity().IDString(), "peer", peer)
 		return err
 	}
 
-	op, dataBytes, err := pm.Router().DecryptData(encData, opKeyInfo)
+	op, dataBytes, err := pm.Router().DecryptDataWithPeer(code, hash, encData, opKeyInfo, peer)
 	if err != nil {
 		log.Error("PMHandleMessageWrapper: unable to DecryptData", "e", err, "entity", pm.Entity().IDString(), "peer", peer)
+		pm.Router().Misbehave(peer, ScoreDecryptFail)
 		return err
 	}
 
+	rateKey := &RateKey{Code: code, EntityID: *pm.Entity().GetID(), Op: op}
+	if !pm.Router().AllowPeerMsg(peer, rateKey, pm.OpRateLimit(op)) {
+		return ErrBusy
+	}
+
+	markOpMsg(MetricDirIn, pm, op, len(encData))
+
 	// handle identify-peer message
 
 	switch op {
@@ -264,6 +272,18 @@ func PMHandleMessageWrapper(pm ProtocolManager, hash *common.Address, encData []
 			log.Error("PMHandleMessageWrapper: unable to HandleSyncCreateOpKeyAck", "e", err, "entity", pm.Entity().IDString(), "peer", peer)
 		}
 
+	// media-chunk
+	case SyncMediaChunkMsg:
+		err = pm.HandleSyncMediaChunk(dataBytes, peer)
+		if err != nil {
+			log.Error("PMHandleMessageWrapper: unable to HandleSyncMediaChunk", "e", err, "entity", pm.Entity().IDString(), "peer", peer)
+		}
+	case SyncMediaChunkAckMsg:
+		err = pm.HandleSyncMediaChunkAck(dataBytes, peer)
+		if err != nil {
+			log.Error("PMHandleMessageWrapper: unable to HandleSyncMediaChunkAck", "e", err, "entity", pm.Entity().IDString(), "peer", peer)
+		}
+
 	// default
 	default:
 		err = pm.HandleMessage(op, dataBytes, peer)
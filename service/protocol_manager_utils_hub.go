@@ -0,0 +1,110 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"encoding/json"
+
+	"github.com/ailabstw/go-pttai-core/common"
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+)
+
+/*
+PushToHub pushes the data that are not delivered yet to the hub. Implemented in the entities supporting the hubs.
+*/
+func (pm *BaseProtocolManager) PushToHub(peer *PttPeer) error {
+	return nil
+}
+
+/*
+RelayDataToHub relays the data to the user through the hub.
+
+The data is encrypted with the newest op-key for the user to be able to decrypt the data as late as possible.
+The data is always in the aead envelope as the data relayed to the nodes (RelayDataToNode),
+because the data is stored in the hub and the protocol version of the user is unknown when relaying.
+*/
+func (pm *BaseProtocolManager) RelayDataToHub(op OpType, data interface{}, userID *types.PttID, peer *PttPeer) error {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	opKeyInfo, err := pm.GetNewestOpKey(false)
+	if err != nil {
+		return err
+	}
+
+	ptt := pm.Router()
+	encData, err := ptt.EncryptDataAEAD(CodeTypeOp, opKeyInfo.Hash, op, dataBytes, opKeyInfo)
+	if err != nil {
+		return err
+	}
+
+	pttData, err := ptt.MarshalRelayData(CodeTypeOp, opKeyInfo.Hash, encData)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("RelayDataToHub: to HubRelay", "entity", pm.Entity().IDString(), "op", op, "userID", userID, "peer", peer)
+
+	return ptt.HubRelay(userID, pttData, peer)
+}
+
+/**********
+ * Relay-TS
+ **********/
+
+func (pm *BaseProtocolManager) marshalHubRelayTSKey(nodeID *discover.NodeID) ([]byte, error) {
+	return common.Concat([][]byte{DBHubRelayTSPrefix, pm.Entity().GetID()[:], nodeID[:]})
+}
+
+/*
+GetHubRelayTS gets the update-ts of the newest data relayed to the hub.
+*/
+func (pm *BaseProtocolManager) GetHubRelayTS(nodeID *discover.NodeID) (types.Timestamp, error) {
+	key, err := pm.marshalHubRelayTSKey(nodeID)
+	if err != nil {
+		return types.ZeroTimestamp, err
+	}
+
+	val, err := pm.Router().DBMeta().Get(key)
+	if err == pttdb.ErrNotFound {
+		return types.ZeroTimestamp, nil
+	}
+	if err != nil {
+		return types.ZeroTimestamp, err
+	}
+
+	return types.UnmarshalTimestamp(val)
+}
+
+func (pm *BaseProtocolManager) SetHubRelayTS(nodeID *discover.NodeID, ts types.Timestamp) error {
+	key, err := pm.marshalHubRelayTSKey(nodeID)
+	if err != nil {
+		return err
+	}
+
+	val, err := ts.Marshal()
+	if err != nil {
+		return err
+	}
+
+	return pm.Router().DBMeta().Put(key, val)
+}
@@ -16,6 +16,11 @@
 
 package service
 
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+)
+
 func (pm *BaseProtocolManager) GetOplog0() *BaseOplog {
 	return pm.oplog0
 }
@@ -32,6 +37,33 @@ func (pm *BaseProtocolManager) Log0Merkle() *Merkle {
 	return pm.log0Merkle
 }
 
+/*
+CountPendingLog0s counts the pending and internal-pending log0s.
+*/
+func (pm *BaseProtocolManager) CountPendingLog0s() (int, error) {
+	if pm.setLog0DB == nil {
+		return 0, nil
+	}
+
+	oplog := &BaseOplog{}
+	pm.setLog0DB(oplog)
+
+	count := 0
+	for _, status := range []types.Status{types.StatusPending, types.StatusInternalPending} {
+		iter, err := GetOplogIterWithOplog(oplog, nil, pttdb.ListOrderNext, status, false)
+		if err != nil {
+			return 0, err
+		}
+
+		for iter.Next() {
+			count++
+		}
+		iter.Release()
+	}
+
+	return count, nil
+}
+
 func (pm *BaseProtocolManager) HandleLog0s(logs []*BaseOplog, peer *PttPeer, isUpdateSyncTime bool) error {
 	return pm.handleLog0s(logs, peer, isUpdateSyncTime)
 }
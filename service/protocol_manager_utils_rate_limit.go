@@ -0,0 +1,43 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+func newOpRateLimits() map[OpType]*RateLimit {
+	limits := make(map[OpType]*RateLimit)
+	for op, limit := range OpRateLimits {
+		limits[op] = limit
+	}
+
+	return limits
+}
+
+/*
+SetOpRateLimit sets the rate-limit of the op from each peer.
+Expected to be called only when constructing the pm (ex: the entity-specific ops.)
+*/
+func (pm *BaseProtocolManager) SetOpRateLimit(op OpType, limit *RateLimit) {
+	pm.opRateLimits[op] = limit
+}
+
+func (pm *BaseProtocolManager) OpRateLimit(op OpType) *RateLimit {
+	limit, ok := pm.opRateLimits[op]
+	if !ok {
+		return DefaultOpRateLimit
+	}
+
+	return limit
+}
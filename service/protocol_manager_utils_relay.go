@@ -0,0 +1,61 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"encoding/json"
+
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+)
+
+/*
+RelayDataToNode relays the data to the node through the connected peers,
+so that the node is still able to receive the data even if we are not able to dial the node.
+
+The data is encrypted with the newest op-key in the aead-envelope,
+the relays forward the still-encrypted data and are not able to decrypt the data.
+
+The recipient handles the data without the peer (HandleRelayedMessage).
+Currently only the friend relays the data (the pending friend-oplogs and the objects / blocks of the oplogs).
+*/
+func (pm *BaseProtocolManager) RelayDataToNode(op OpType, data interface{}, nodeID *discover.NodeID) error {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	opKeyInfo, err := pm.GetNewestOpKey(false)
+	if err != nil {
+		return err
+	}
+
+	ptt := pm.Router()
+	encData, err := ptt.EncryptDataAEAD(CodeTypeOp, opKeyInfo.Hash, op, dataBytes, opKeyInfo)
+	if err != nil {
+		return err
+	}
+
+	pttData, err := ptt.MarshalRelayData(CodeTypeOp, opKeyInfo.Hash, encData)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("RelayDataToNode: to RelayData", "entity", pm.Entity().IDString(), "op", op, "nodeID", nodeID)
+
+	return ptt.RelayData(nodeID, pttData)
+}
@@ -75,6 +75,14 @@ func (p *BaseRouter) HandleOpCheckMember(dataBytes []byte, peer *PttPeer) error
 	}
 	pm := entity.PM()
 
+	if data.MyLog == nil || data.TheirLog == nil {
+		return ErrInvalidData
+	}
+
+	// the db-prefix is required for the op-data in verifying.
+	pm.SetMemberDB(data.MyLog)
+	pm.SetMemberDB(data.TheirLog)
+
 	err = data.MyLog.Verify()
 	if err != nil {
 		return err
@@ -57,6 +57,13 @@ func (p *BaseRouter) HandleOpCheckMemberAck(dataBytes []byte, peer *PttPeer) err
 	}
 	pm := entity.PM()
 
+	if data.Log == nil {
+		return ErrInvalidData
+	}
+
+	// the db-prefix is required for the op-data in verifying.
+	pm.SetMemberDB(data.Log)
+
 	err = data.Log.Verify()
 	log.Debug("HandleOpCheckMemberAck: after Verify", "e", err)
 	if err != nil {
@@ -73,17 +73,25 @@ func (pm *BaseProtocolManager) HandleSyncBlock(
 		return err
 	}
 
-	lenObjs := len(data.IDs)
-	if lenObjs == 0 {
+	if len(data.IDs) == 0 {
 		return nil
 	}
 
-	blocks := make([]*Block, 0, lenObjs*NSubBlock)
+	blocks := pm.GetSyncBlocks(data.IDs, obj)
+
+	return pm.SyncBlockAck(syncAckMsg, blocks, peer)
+}
+
+/*
+GetSyncBlocks gets the blocks in syncBlockIDs, as in SyncBlockAck.
+*/
+func (pm *BaseProtocolManager) GetSyncBlocks(syncBlockIDs []*SyncBlockID, obj Object) []*Block {
+	blocks := make([]*Block, 0, len(syncBlockIDs)*NSubBlock)
 
 	var blockInfo *BlockInfo
 	var newBlocks []*Block
 	var syncInfo SyncInfo
-	for _, syncBlockID := range data.IDs {
+	for _, syncBlockID := range syncBlockIDs {
 		newObj, err := obj.GetNewObjByID(syncBlockID.ObjID, false)
 		if err != nil {
 			continue
@@ -116,7 +124,7 @@ func (pm *BaseProtocolManager) HandleSyncBlock(
 		blocks = append(blocks, newBlocks...)
 	}
 
-	return pm.SyncBlockAck(syncAckMsg, blocks, peer)
+	return blocks
 }
 
 func blocksToBlocksByIDsByObjs(blocks []*Block) map[types.PttID]map[types.PttID][]*Block {
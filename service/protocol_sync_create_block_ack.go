@@ -28,6 +28,15 @@ type SyncBlockAck struct {
 }
 
 func (pm *BaseProtocolManager) SyncBlockAck(ackMsg OpType, blocks []*Block, peer *PttPeer) error {
+	return SendSyncBlockAck(ackMsg, blocks, func(op OpType, data interface{}) error {
+		return pm.SendDataToPeer(op, data, peer)
+	})
+}
+
+/*
+SendSyncBlockAck sends the blocks as SyncBlockAck with send, in the chunks of MaxSyncBlock blocks.
+*/
+func SendSyncBlockAck(ackMsg OpType, blocks []*Block, send func(op OpType, data interface{}) error) error {
 	if len(blocks) == 0 {
 		return nil
 	}
@@ -49,7 +58,7 @@ func (pm *BaseProtocolManager) SyncBlockAck(ackMsg OpType, blocks []*Block, peer
 		data = &SyncBlockAck{
 			Blocks: eachBlocks,
 		}
-		err = pm.SendDataToPeer(ackMsg, data, peer)
+		err = send(ackMsg, data)
 		if err != nil {
 			return err
 		}
@@ -70,7 +70,7 @@ func (pm *BaseProtocolManager) HandleSyncCreateMediaBlockAck(
 		merkle,
 
 		setLogDB,
-		nil,
+		pm.postcreateMedia,
 		broadcastLog,
 	)
 }
@@ -54,7 +54,7 @@ func (pm *BaseProtocolManager) HandleSyncCreateMediaAck(
 
 			setLogDB,
 			pm.updateSyncCreateMedia,
-			nil,
+			pm.postcreateMedia,
 			broadcastLog,
 		)
 	}
@@ -73,6 +73,11 @@ func (pm *BaseProtocolManager) updateSyncCreateMedia(theToObj Object, theFromObj
 		return ErrInvalidData
 	}
 
+	// the meta of the chunked file is from the signed oplog.
+	if toObj.IsChunkedFile() {
+		return nil
+	}
+
 	toObj.BlockInfo = fromObj.BlockInfo
 	toObj.MediaType = fromObj.MediaType
 	toObj.MediaData = fromObj.MediaData
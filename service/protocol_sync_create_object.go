@@ -71,16 +71,26 @@ func (pm *BaseProtocolManager) HandleSyncCreateObject(
 		return err
 	}
 
-	lenObjs := len(data.IDs)
-	if lenObjs == 0 {
+	if len(data.IDs) == 0 {
 		return nil
 	}
 
-	objs := make([]Object, 0, lenObjs)
+	objs := pm.GetSyncCreateObjects(data.IDs, obj)
 
-	log.Debug("HandleSyncCreateObject: start")
+	log.Debug("HandleSyncCreateObject: to SyncObjectAck", "objs", objs)
+
+	return pm.SyncObjectAck(objs, syncAckMsg, peer)
+}
+
+/*
+GetSyncCreateObjects gets the objects created with the oplogs in syncIDs, as in SyncObjectAck.
+*/
+func (pm *BaseProtocolManager) GetSyncCreateObjects(syncIDs []*SyncID, obj Object) []Object {
+	objs := make([]Object, 0, len(syncIDs))
+
+	log.Debug("GetSyncCreateObjects: start")
 	var blockInfo *BlockInfo
-	for _, syncID := range data.IDs {
+	for _, syncID := range syncIDs {
 		newObj, err := obj.GetNewObjByID(syncID.ID, false)
 		if err != nil {
 			continue
@@ -102,14 +112,12 @@ func (pm *BaseProtocolManager) HandleSyncCreateObject(
 		if blockInfo != nil {
 			blockInfo.ResetIsGood()
 		}
-		log.Debug("HandleSyncCreateObject: (in for-loop)", "blockInfo", blockInfo)
+		log.Debug("GetSyncCreateObjects: (in for-loop)", "blockInfo", blockInfo)
 
 		newObj.SetSyncInfo(nil)
 
 		objs = append(objs, newObj)
 	}
 
-	log.Debug("HandleSyncCreateObject: to SyncObjectAck", "objs", objs)
-
-	return pm.SyncObjectAck(objs, syncAckMsg, peer)
+	return objs
 }
@@ -20,6 +20,7 @@ import (
 	"reflect"
 
 	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
 )
 
 type SyncObjectAck struct {
@@ -27,6 +28,15 @@ type SyncObjectAck struct {
 }
 
 func (pm *BaseProtocolManager) SyncObjectAck(objs []Object, syncAckMsg OpType, peer *PttPeer) error {
+	return SendSyncObjectAck(objs, syncAckMsg, func(op OpType, data interface{}) error {
+		return pm.SendDataToPeer(op, data, peer)
+	})
+}
+
+/*
+SendSyncObjectAck sends the objects as SyncObjectAck with send, in the chunks of MaxSyncObjectAck objects.
+*/
+func SendSyncObjectAck(objs []Object, syncAckMsg OpType, send func(op OpType, data interface{}) error) error {
 	if len(objs) == 0 {
 		return nil
 	}
@@ -47,7 +57,7 @@ func (pm *BaseProtocolManager) SyncObjectAck(objs []Object, syncAckMsg OpType, p
 			Objs: eachObjs,
 		}
 
-		err := pm.SendDataToPeer(syncAckMsg, data, peer)
+		err := send(syncAckMsg, data)
 		if err != nil {
 			return err
 		}
@@ -75,6 +85,12 @@ func (pm *BaseProtocolManager) HandleSyncCreateObjectAck(
 	broadcastLog func(oplog *BaseOplog) error,
 ) error {
 
+	// blocked creator
+	if isBlockedCreator(pm.Router(), obj.GetCreatorID()) {
+		log.Debug("HandleSyncCreateObjectAck: blocked", "objID", obj.GetID(), "creator", obj.GetCreatorID())
+		return nil
+	}
+
 	// oplog
 	objID := obj.GetID()
 	logID := obj.GetLogID()
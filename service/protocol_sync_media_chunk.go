@@ -0,0 +1,212 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"encoding/json"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+)
+
+type SyncMediaChunk struct {
+	MediaID *types.PttID `json:"ID"`
+	Hashs   [][]byte     `json:"H"`
+}
+
+type SyncMediaChunkAck struct {
+	MediaID *types.PttID `json:"ID"`
+	Chunks  [][]byte     `json:"C"`
+}
+
+/*
+SyncMediaChunk requests the missing chunks of the chunked file from the peer (random peer if peer is nil),
+MaxSyncMediaChunk chunks at a time. The next chunks are requested when receiving the ack.
+*/
+func (pm *BaseProtocolManager) SyncMediaChunk(media *Media, peer *PttPeer) error {
+	fileData := media.GetFileData()
+	if fileData == nil {
+		return ErrInvalidData
+	}
+
+	db := pm.DB().DB()
+
+	hashs := missingMediaChunks(db, fileData.Chunks, MaxSyncMediaChunk)
+	if len(hashs) == 0 {
+		return removeMediaChunkPending(db, media.EntityID, media.ID)
+	}
+
+	if peer == nil {
+		peer = RandomPeer(pm.Peers().PeerList(false))
+	}
+	if peer == nil {
+		return ErrNoPeer
+	}
+
+	data := &SyncMediaChunk{
+		MediaID: media.ID,
+		Hashs:   hashs,
+	}
+
+	return pm.SendDataToPeer(SyncMediaChunkMsg, data, peer)
+}
+
+func (pm *BaseProtocolManager) HandleSyncMediaChunk(dataBytes []byte, peer *PttPeer) error {
+	data := &SyncMediaChunk{}
+	err := json.Unmarshal(dataBytes, data)
+	if err != nil {
+		return err
+	}
+
+	media, err := pm.getChunkedMedia(data.MediaID)
+	if err != nil {
+		return err
+	}
+
+	hashs := data.Hashs
+	if len(hashs) > MaxSyncMediaChunk {
+		hashs = hashs[:MaxSyncMediaChunk]
+	}
+
+	db := pm.DB().DB()
+	fileData := media.GetFileData()
+
+	chunks := make([][]byte, 0, len(hashs))
+	for _, hash := range hashs {
+		// only the chunks of the media.
+		if !isHashInList(fileData.Chunks, hash) {
+			continue
+		}
+
+		chunk, err := getMediaChunk(db, hash)
+		if err != nil {
+			continue
+		}
+
+		chunks = append(chunks, chunk)
+	}
+
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	ackData := &SyncMediaChunkAck{
+		MediaID: media.ID,
+		Chunks:  chunks,
+	}
+
+	return pm.SendDataToPeer(SyncMediaChunkAckMsg, ackData, peer)
+}
+
+func (pm *BaseProtocolManager) HandleSyncMediaChunkAck(dataBytes []byte, peer *PttPeer) error {
+	data := &SyncMediaChunkAck{}
+	err := json.Unmarshal(dataBytes, data)
+	if err != nil {
+		return err
+	}
+
+	media, err := pm.getChunkedMedia(data.MediaID)
+	if err != nil {
+		return err
+	}
+
+	db := pm.DB().DB()
+	fileData := media.GetFileData()
+
+	for _, chunk := range data.Chunks {
+		hash := MediaChunkHash(chunk)
+		if !isHashInList(fileData.Chunks, hash) {
+			log.Warn("HandleSyncMediaChunkAck: invalid chunk", "media", media.ID, "peer", peer)
+			continue
+		}
+
+		_, err = saveMediaChunk(db, chunk, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	// next chunks
+	return pm.SyncMediaChunk(media, peer)
+}
+
+/*
+ForceSyncMediaChunk resumes syncing the chunks of the pending chunked files with the peer.
+*/
+func (pm *BaseProtocolManager) ForceSyncMediaChunk(peer *PttPeer) error {
+	db := pm.DB().DB()
+	entityID := pm.Entity().GetID()
+
+	prefix, err := marshalMediaChunkPendingPrefix(entityID)
+	if err != nil {
+		return err
+	}
+
+	iter, err := db.NewIteratorWithPrefix(nil, prefix, pttdb.ListOrderNext)
+	if err != nil {
+		return err
+	}
+
+	mediaIDs := make([]*types.PttID, 0)
+	for iter.Next() {
+		mediaID := &types.PttID{}
+		copy(mediaID[:], iter.Value())
+		mediaIDs = append(mediaIDs, mediaID)
+	}
+	iter.Release()
+
+	for _, mediaID := range mediaIDs {
+		media, err := pm.getChunkedMedia(mediaID)
+		if err != nil {
+			removeMediaChunkPending(db, entityID, mediaID)
+			continue
+		}
+
+		err = pm.SyncMediaChunk(media, peer)
+		if err != nil {
+			log.Warn("ForceSyncMediaChunk: unable to SyncMediaChunk", "media", mediaID, "e", err)
+		}
+	}
+
+	return nil
+}
+
+func (pm *BaseProtocolManager) getChunkedMedia(mediaID *types.PttID) (*Media, error) {
+	if mediaID == nil {
+		return nil, ErrInvalidData
+	}
+
+	media := NewEmptyMedia()
+	pm.SetMediaDB(media)
+	media.SetID(mediaID)
+
+	err := media.GetByID(false)
+	if err != nil {
+		return nil, err
+	}
+
+	if media.Status != types.StatusAlive {
+		return nil, ErrInvalidStatus
+	}
+
+	if !media.IsChunkedFile() {
+		return nil, ErrInvalidObject
+	}
+
+	return media, nil
+}
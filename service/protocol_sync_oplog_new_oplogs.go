@@ -54,6 +54,8 @@ func (pm *BaseProtocolManager) SyncOplogNewOplogs(
 			merkle.SaveSyncTime(myLastNode.UpdateTS)
 		}
 
+		pm.postMerkleSyncEvent(merkle, peer, 0)
+
 		if postsync != nil {
 			return postsync(peer)
 		}
@@ -68,6 +70,8 @@ func (pm *BaseProtocolManager) SyncOplogNewOplogs(
 	}
 
 	if len(theirNewLogs) == 0 && len(myNewKeys) == 0 {
+		pm.postMerkleSyncEvent(merkle, peer, 0)
+
 		if postsync != nil {
 			return postsync(peer)
 		}
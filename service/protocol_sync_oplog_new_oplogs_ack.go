@@ -70,6 +70,8 @@ func (pm *BaseProtocolManager) HandleSyncOplogNewOplogsAck(
 	setDB func(oplog *BaseOplog),
 	handleOplogs func(oplogs []*BaseOplog, peer *PttPeer, isUpdateSyncTime bool) error,
 	postsync func(peer *PttPeer) error,
+
+	merkle *Merkle,
 ) error {
 
 	ptt := pm.Router()
@@ -94,6 +96,8 @@ func (pm *BaseProtocolManager) HandleSyncOplogNewOplogsAck(
 		return err
 	}
 
+	pm.postMerkleSyncEvent(merkle, peer, len(data.Oplogs))
+
 	if postsync != nil {
 		return postsync(peer)
 	}
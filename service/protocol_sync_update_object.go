@@ -37,17 +37,27 @@ func (pm *BaseProtocolManager) HandleSyncUpdateObject(
 		return err
 	}
 
-	lenObjs := len(data.IDs)
-	if lenObjs == 0 {
+	if len(data.IDs) == 0 {
 		return nil
 	}
 
-	objs := make([]Object, 0, lenObjs)
+	objs := pm.GetSyncUpdateObjects(data.IDs, obj)
+
+	log.Debug("HandleSyncUpdateObject: to SyncObjectAck", "objs", objs)
+
+	return pm.SyncObjectAck(objs, syncAckMsg, peer)
+}
+
+/*
+GetSyncUpdateObjects gets the objects updated with the oplogs in syncIDs, as in SyncObjectAck.
+*/
+func (pm *BaseProtocolManager) GetSyncUpdateObjects(syncIDs []*SyncID, obj Object) []Object {
+	objs := make([]Object, 0, len(syncIDs))
 	var syncInfo SyncInfo
 	var blockInfo *BlockInfo
 
-	log.Debug("HandleSyncUpdateObject: start")
-	for _, syncID := range data.IDs {
+	log.Debug("GetSyncUpdateObjects: start")
+	for _, syncID := range syncIDs {
 		newObj, err := obj.GetNewObjByID(syncID.ID, false)
 		if err != nil {
 			continue
@@ -94,7 +104,5 @@ func (pm *BaseProtocolManager) HandleSyncUpdateObject(
 		objs = append(objs, newObj)
 	}
 
-	log.Debug("HandleSyncUpdateObject: to SyncObjectAck", "objs", objs)
-
-	return pm.SyncObjectAck(objs, syncAckMsg, peer)
+	return objs
 }
@@ -39,6 +39,12 @@ func (pm *BaseProtocolManager) HandleSyncUpdateObjectAck(
 
 ) error {
 
+	// blocked creator
+	if isBlockedCreator(pm.Router(), obj.GetCreatorID()) {
+		log.Debug("HandleSyncUpdateObjectAck: blocked", "objID", obj.GetID(), "creator", obj.GetCreatorID())
+		return nil
+	}
+
 	// oplog
 	objID := obj.GetID()
 	logID := obj.GetUpdateLogID()
@@ -27,6 +27,11 @@ type PersonOpTransferPerson struct {
 	ToID *types.PttID `json:"t"`
 }
 
+func (o *PersonOpTransferPerson) EncodeCanonical(enc *CanonicalEncoder) {
+	enc.Version(1)
+	enc.PttID(o.ToID)
+}
+
 /**********
  * Handle CreateObjectLog
  **********/
@@ -0,0 +1,120 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"github.com/ailabstw/go-pttai-core/common/types"
+)
+
+/*
+BeginUploadFile starts the chunked upload of the file with the filename and the size.
+The chunks are uploaded with UploadFileChunk, and the media is created with CreateFileMedia.
+*/
+func (pm *BaseProtocolManager) BeginUploadFile(filename []byte, size int64) (*UploadFile, error) {
+	uploadFile, err := NewUploadFile(pm.Entity().GetID(), filename, size)
+	if err != nil {
+		return nil, err
+	}
+
+	err = uploadFile.Save(pm.DB().DB())
+	if err != nil {
+		return nil, err
+	}
+
+	return uploadFile, nil
+}
+
+func (pm *BaseProtocolManager) GetUploadFile(uploadID *types.PttID) (*UploadFile, error) {
+	uploadFile := &UploadFile{ID: uploadID, EntityID: pm.Entity().GetID()}
+	err := uploadFile.Get(pm.DB().DB())
+	if err != nil {
+		return nil, err
+	}
+
+	return uploadFile, nil
+}
+
+/*
+UploadFileChunk uploads the idx-th chunk of the file.
+Uploading the same chunk again is no-op, so the client can resume the upload with the missing chunks.
+*/
+func (pm *BaseProtocolManager) UploadFileChunk(uploadID *types.PttID, idx int, buf []byte) (*UploadFile, error) {
+	dbLock := pm.DBObjLock()
+	err := dbLock.Lock(uploadID)
+	if err != nil {
+		return nil, err
+	}
+	defer dbLock.Unlock(uploadID)
+
+	uploadFile, err := pm.GetUploadFile(uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	if idx < 0 || idx >= len(uploadFile.Chunks) {
+		return nil, ErrInvalidChunk
+	}
+	if len(buf) != MediaChunkSize(uploadFile.Size, idx) {
+		return nil, ErrInvalidChunk
+	}
+
+	db := pm.DB().DB()
+
+	hash, err := saveMediaChunk(db, buf, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	uploadFile.Chunks[idx] = hash
+
+	err = uploadFile.Save(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return uploadFile, nil
+}
+
+/*
+CancelUploadFile removes the upload and the uploaded chunks not referenced by the others.
+*/
+func (pm *BaseProtocolManager) CancelUploadFile(uploadID *types.PttID) error {
+	dbLock := pm.DBObjLock()
+	err := dbLock.Lock(uploadID)
+	if err != nil {
+		return err
+	}
+	defer dbLock.Unlock(uploadID)
+
+	return pm.removeUploadFile(uploadID)
+}
+
+func (pm *BaseProtocolManager) removeUploadFile(uploadID *types.PttID) error {
+	uploadFile, err := pm.GetUploadFile(uploadID)
+	if err != nil {
+		return err
+	}
+
+	db := pm.DB().DB()
+
+	err = unrefMediaChunks(db, uploadFile.Chunks, uploadID)
+	if err != nil {
+		return err
+	}
+
+	return uploadFile.Delete(db)
+}
@@ -22,9 +22,9 @@ type PttOplog struct {
 	*BaseOplog `json:"O"`
 }
 
-func NewPttOplog(objID *types.PttID, ts types.Timestamp, doerID *types.PttID, op OpType, data OpData, myID *types.PttID) (*PttOplog, error) {
+func (r *BaseRouter) NewPttOplog(objID *types.PttID, ts types.Timestamp, doerID *types.PttID, op OpType, data OpData, myID *types.PttID) (*PttOplog, error) {
 
-	oplog, err := NewOplog(objID, ts, doerID, op, data, dbOplog, myID, DBPttOplogPrefix, DBPttIdxOplogPrefix, nil, DBPttLockMap)
+	oplog, err := NewOplog(objID, ts, doerID, op, data, r.dbOplog, myID, DBPttOplogPrefix, DBPttIdxOplogPrefix, nil, r.dbPttLockMap)
 	if err != nil {
 		return nil, err
 	}
@@ -36,8 +36,8 @@ func NewPttOplog(objID *types.PttID, ts types.Timestamp, doerID *types.PttID, op
 	}, nil
 }
 
-func SetPttDB(myID *types.PttID, oplog *BaseOplog) {
-	oplog.SetDB(dbOplog, myID, DBPttOplogPrefix, DBPttIdxOplogPrefix, nil, DBPttLockMap)
+func (r *BaseRouter) SetPttDB(myID *types.PttID, oplog *BaseOplog) {
+	oplog.SetDB(r.dbOplog, myID, DBPttOplogPrefix, DBPttIdxOplogPrefix, nil, r.dbPttLockMap)
 }
 
 func OplogsToPttOplogs(logs []*BaseOplog) []*PttOplog {
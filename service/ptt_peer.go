@@ -35,7 +35,8 @@ type PttPeer struct {
 
 	rw p2p.MsgReadWriter
 
-	version uint
+	version    uint
+	encVersion uint8
 
 	term chan struct{} // Termination channel to stop the broadcaster
 
@@ -154,6 +155,13 @@ func (p *PttPeer) Version() uint {
 	return p.version
 }
 
+/*
+EncVersion returns the envelope of the encrypted data negotiated in RWInit.
+*/
+func (p *PttPeer) EncVersion() uint8 {
+	return p.encVersion
+}
+
 func (p *PttPeer) RW() p2p.MsgReadWriter {
 	return p.rw
 }
@@ -0,0 +1,96 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"sync"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+/*
+RelayHistory keeps the relayed data seen and the bytes relayed for each peer in the relay-window.
+
+The seen data are kept for 2 windows (the current and the previous one)
+to prevent the data looping among the relays.
+*/
+type RelayHistory struct {
+	lock sync.Mutex
+
+	windowTS int64
+
+	seen     map[common.Hash]bool
+	prevSeen map[common.Hash]bool
+
+	bytes map[discover.NodeID]int
+}
+
+func NewRelayHistory() *RelayHistory {
+	return &RelayHistory{
+		seen:     make(map[common.Hash]bool),
+		prevSeen: make(map[common.Hash]bool),
+		bytes:    make(map[discover.NodeID]int),
+	}
+}
+
+/*
+Add adds the checksum of the relayed data from the peer.
+
+Return: isSeen, isOverQuota, error
+*/
+func (h *RelayHistory) Add(checksum []byte, id *discover.NodeID, size int, quota int) (bool, bool, error) {
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return false, false, err
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.rotate(ts.Ts)
+
+	hash := common.BytesToHash(checksum)
+	if h.seen[hash] || h.prevSeen[hash] {
+		return true, false, nil
+	}
+	h.seen[hash] = true
+
+	if h.bytes[*id]+size > quota {
+		return false, true, nil
+	}
+	h.bytes[*id] += size
+
+	return false, false, nil
+}
+
+func (h *RelayHistory) rotate(ts int64) {
+	if ts < h.windowTS+RelayWindowSeconds {
+		return
+	}
+
+	// more than 1 window passed, the seen data are all expired.
+	if ts < h.windowTS+2*RelayWindowSeconds {
+		h.prevSeen = h.seen
+	} else {
+		h.prevSeen = make(map[common.Hash]bool)
+	}
+	h.seen = make(map[common.Hash]bool)
+	h.bytes = make(map[discover.NodeID]int)
+	h.windowTS = ts
+}
@@ -0,0 +1,275 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/p2p"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelayHistory(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	ts := tDefaultTimestamp
+	types.GetTimestamp = func() (types.Timestamp, error) {
+		return ts, nil
+	}
+
+	h := NewRelayHistory()
+	id := &discover.NodeID{1}
+	id2 := &discover.NodeID{2}
+
+	// seen
+	isSeen, isOverQuota, err := h.Add([]byte("checksum-1"), id, 10, 20)
+	assert.NoError(t, err)
+	assert.False(t, isSeen)
+	assert.False(t, isOverQuota)
+
+	isSeen, _, _ = h.Add([]byte("checksum-1"), id2, 10, 20)
+	assert.True(t, isSeen)
+
+	// quota is for each peer.
+	_, isOverQuota, _ = h.Add([]byte("checksum-2"), id, 10, 20)
+	assert.False(t, isOverQuota)
+	_, isOverQuota, _ = h.Add([]byte("checksum-3"), id, 10, 20)
+	assert.True(t, isOverQuota)
+	_, isOverQuota, _ = h.Add([]byte("checksum-4"), id2, 10, 20)
+	assert.False(t, isOverQuota)
+
+	// next window: quota reset, still seen.
+	ts.Ts += RelayWindowSeconds
+	isSeen, isOverQuota, _ = h.Add([]byte("checksum-5"), id, 10, 20)
+	assert.False(t, isSeen)
+	assert.False(t, isOverQuota)
+	isSeen, _, _ = h.Add([]byte("checksum-1"), id, 10, 20)
+	assert.True(t, isSeen)
+
+	// 2 windows later: expired.
+	ts.Ts += 2 * RelayWindowSeconds
+	isSeen, _, _ = h.Add([]byte("checksum-1"), id, 10, 20)
+	assert.False(t, isSeen)
+}
+
+func testRelayPeer(r *BaseRouter, id discover.NodeID, version uint) (*PttPeer, *p2p.MsgPipeRW) {
+	rw1, rw2 := p2p.MsgPipe()
+	peer, _ := NewPttPeer(version, p2p.NewPeer(id, "test", nil), rw1, r)
+	r.RWInit(peer, version)
+	r.randomPeers[id] = peer
+
+	return peer, rw2
+}
+
+func testReadRelayData(t *testing.T, rw *p2p.MsgPipeRW) *RouterData {
+	dataChan := make(chan *RouterData, 1)
+	go func() {
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			dataChan <- nil
+			return
+		}
+		defer msg.Discard()
+
+		data := &RouterData{}
+		if msg.Decode(data) != nil {
+			dataChan <- nil
+			return
+		}
+		dataChan <- data
+	}()
+
+	select {
+	case data := <-dataChan:
+		return data
+	case <-time.After(100 * time.Millisecond):
+		return nil
+	}
+}
+
+func TestRouter_ForwardRelayData(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	r := &BaseRouter{
+		config: &Config{IsRelay: true, RelayQuotaBytes: 4096},
+
+		myNodeID: tDefaultNodeID,
+
+		myPeers:        make(map[discover.NodeID]*PttPeer),
+		hubPeers:       make(map[discover.NodeID]*PttPeer),
+		importantPeers: make(map[discover.NodeID]*PttPeer),
+		memberPeers:    make(map[discover.NodeID]*PttPeer),
+		pendingPeers:   make(map[discover.NodeID]*PttPeer),
+		randomPeers:    make(map[discover.NodeID]*PttPeer),
+
+		relayHist: NewRelayHistory(),
+	}
+
+	fromPeer, _ := testRelayPeer(r, discover.NodeID{1}, Ptt5)
+	oldPeer, _ := testRelayPeer(r, discover.NodeID{2}, Ptt4)
+	targetID := discover.NodeID{3}
+	_, targetRW := testRelayPeer(r, targetID, Ptt5)
+
+	newData := func(encData string, relay uint8) *RouterData {
+		data, err := r.MarshalData(CodeTypeOp, &tDefaultHash, []byte(encData))
+		assert.NoError(t, err)
+		data.Node = targetID[:]
+		data.Relay = relay
+		return data
+	}
+
+	// forwarded to the target with one more hop.
+	data := newData("relay-1", 1)
+	go r.ForwardRelayData(CodeTypeOp, data, fromPeer)
+	got := testReadRelayData(t, targetRW)
+	assert.NotNil(t, got)
+	if got != nil {
+		assert.Equal(t, uint8(2), got.Relay)
+		assert.Equal(t, data.Checksum, got.Checksum)
+	}
+
+	// seen
+	err := r.ForwardRelayData(CodeTypeOp, data, fromPeer)
+	assert.NoError(t, err)
+	assert.Nil(t, testReadRelayData(t, targetRW))
+
+	// exceeding hops
+	err = r.ForwardRelayData(CodeTypeOp, newData("relay-2", MaxRelayHops), fromPeer)
+	assert.NoError(t, err)
+	assert.Nil(t, testReadRelayData(t, targetRW))
+
+	// ptt4-peer, or not op, is not for relay.
+	err = r.ForwardRelayData(CodeTypeOp, newData("relay-3", 1), oldPeer)
+	assert.Equal(t, ErrInvalidData, err)
+	err = r.ForwardRelayData(CodeTypeJoin, newData("relay-4", 1), fromPeer)
+	assert.Equal(t, ErrInvalidData, err)
+
+	// invalid checksum
+	data = newData("relay-5", 1)
+	data.Checksum = data.Checksum[1:]
+	err = r.ForwardRelayData(CodeTypeOp, data, fromPeer)
+	assert.Equal(t, ErrInvalidData, err)
+
+	// exceeding quota
+	r.config.RelayQuotaBytes = 0
+	err = r.ForwardRelayData(CodeTypeOp, newData("relay-6", 1), fromPeer)
+	assert.NoError(t, err)
+	assert.Nil(t, testReadRelayData(t, targetRW))
+	r.config.RelayQuotaBytes = 4096
+
+	// not relay
+	r.config.IsRelay = false
+	err = r.ForwardRelayData(CodeTypeOp, newData("relay-7", 1), fromPeer)
+	assert.Equal(t, ErrInvalidData, err)
+}
+
+func TestRouter_MarshalRelayData(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	r := &BaseRouter{
+		myEntity: &testBlockMyEntity{myID: tUserIDMe, signKey: tKeyInfoMe},
+	}
+
+	data, err := r.MarshalRelayData(CodeTypeOp, &tDefaultHash, []byte("relay-1"))
+	assert.NoError(t, err)
+
+	// the original sender is verified.
+	code, hash, encData, fromID, err := r.UnmarshalRelayData(data)
+	assert.NoError(t, err)
+	assert.Equal(t, CodeTypeOp, code)
+	assert.Equal(t, tDefaultHash[:], hash[:])
+	assert.Equal(t, []byte("relay-1"), encData)
+	assert.Equal(t, tUserIDMe, fromID)
+
+	// still able to be unmarshaled as the not-relayed data.
+	_, _, encData, err = r.UnmarshalData(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("relay-1"), encData)
+
+	remarshal := func(ev *RouterEventData) *RouterData {
+		evWithSalt, checksum, err := r.checksumPttEventData(ev)
+		assert.NoError(t, err)
+		return &RouterData{Code: ev.Code, Hash: ev.Hash, EvWithSalt: evWithSalt, Checksum: checksum}
+	}
+
+	// modified by the relay
+	ev, err := r.verifyChecksumEventData(data)
+	assert.NoError(t, err)
+	ev.EncData = []byte("relay-2")
+	_, _, _, _, err = r.UnmarshalRelayData(remarshal(ev))
+	assert.Equal(t, ErrInvalidData, err)
+
+	// claiming to be another user
+	ev, _ = r.verifyChecksumEventData(data)
+	ev.From.ID = &types.PttID{1}
+	_, _, _, _, err = r.UnmarshalRelayData(remarshal(ev))
+	assert.Equal(t, ErrInvalidKey, err)
+
+	// without the sender
+	ev, _ = r.verifyChecksumEventData(data)
+	ev.From = nil
+	_, _, _, _, err = r.UnmarshalRelayData(remarshal(ev))
+	assert.Equal(t, ErrInvalidData, err)
+}
+
+func TestRouter_RelayPeers(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	r := &BaseRouter{
+		myPeers:        make(map[discover.NodeID]*PttPeer),
+		hubPeers:       make(map[discover.NodeID]*PttPeer),
+		importantPeers: make(map[discover.NodeID]*PttPeer),
+		memberPeers:    make(map[discover.NodeID]*PttPeer),
+		pendingPeers:   make(map[discover.NodeID]*PttPeer),
+		randomPeers:    make(map[discover.NodeID]*PttPeer),
+	}
+
+	fromPeer, _ := testRelayPeer(r, discover.NodeID{1}, Ptt5)
+	testRelayPeer(r, discover.NodeID{2}, Ptt4)
+	for i := 0; i < MaxRelayPeers+1; i++ {
+		testRelayPeer(r, discover.NodeID{10, uint8(i)}, Ptt5)
+	}
+
+	// not connected: at most MaxRelayPeers ptt5-peers except the from-peer.
+	peers, isTarget := r.relayPeers(&discover.NodeID{3}, fromPeer)
+	assert.False(t, isTarget)
+	assert.Equal(t, MaxRelayPeers, len(peers))
+	for _, peer := range peers {
+		assert.NotEqual(t, *fromPeer.GetID(), *peer.GetID())
+		assert.Equal(t, Ptt5, peer.Version())
+	}
+
+	// connected
+	peers, isTarget = r.relayPeers(&discover.NodeID{10, 0}, fromPeer)
+	assert.True(t, isTarget)
+	assert.Equal(t, 1, len(peers))
+
+	// connected ptt4-node is not able to handle the relayed data.
+	peers, isTarget = r.relayPeers(&discover.NodeID{2}, fromPeer)
+	assert.False(t, isTarget)
+}
@@ -22,8 +22,10 @@ import (
 
 	"github.com/ailabstw/go-pttai-core/common/types"
 	"github.com/ailabstw/go-pttai-core/log"
+	pttmetrics "github.com/ailabstw/go-pttai-core/metrics"
 	"github.com/ailabstw/go-pttai-core/p2p"
 	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	"github.com/ailabstw/go-pttai-core/pttdb"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -50,6 +52,20 @@ type Router interface {
 
 	AddDial(nodeID *discover.NodeID, opKey *common.Address, peerType PeerType, isAddPeer bool) error
 
+	// hub
+
+	HubPeerList() []*PttPeer
+	HubRelay(userID *types.PttID, data *RouterData, peer *PttPeer) error
+
+	// relay
+
+	RelayData(nodeID *discover.NodeID, data *RouterData) error
+
+	// peer-score
+
+	AllowPeerMsg(peer *PttPeer, key *RateKey, limit *RateLimit) bool
+	Misbehave(peer *PttPeer, score int) error
+
 	// entities
 
 	RegisterEntity(e Entity, isLocked bool, isPeerLock bool) error
@@ -81,12 +97,26 @@ type Router interface {
 	GetMyEntity() MyEntity
 	GetMyService() Service
 
+	IsBlockedUser(id *types.PttID) bool
+
+	// db
+
+	DBMeta() pttdb.Storage
+
+	NewPttOplog(objID *types.PttID, ts types.Timestamp, doerID *types.PttID, op OpType, data OpData, myID *types.PttID) (*PttOplog, error)
+
 	// data
 
 	EncryptData(op OpType, data []byte, keyInfo *KeyInfo) ([]byte, error)
 	DecryptData(ciphertext []byte, keyInfo *KeyInfo) (OpType, []byte, error)
 
+	EncryptDataWithPeer(code CodeType, hash *common.Address, op OpType, data []byte, keyInfo *KeyInfo, peer *PttPeer) ([]byte, error)
+	DecryptDataWithPeer(code CodeType, hash *common.Address, ciphertext []byte, keyInfo *KeyInfo, peer *PttPeer) (OpType, []byte, error)
+
+	EncryptDataAEAD(code CodeType, hash *common.Address, op OpType, data []byte, keyInfo *KeyInfo) ([]byte, error)
+
 	MarshalData(code CodeType, hash *common.Address, encData []byte) (*RouterData, error)
+	MarshalRelayData(code CodeType, hash *common.Address, encData []byte) (*RouterData, error)
 	UnmarshalData(pttData *RouterData) (CodeType, *common.Address, []byte, error)
 }
 
@@ -163,6 +193,16 @@ type BaseRouter struct {
 
 	dialHist *DialHistory
 
+	relayHist *RelayHistory
+
+	peerScores *PeerScores
+
+	// hubs
+	lockHubNodes sync.RWMutex
+	hubNodes     map[discover.NodeID]bool
+
+	hubRelays *HubRelays
+
 	// entities
 	entityLock sync.RWMutex
 
@@ -198,6 +238,18 @@ type BaseRouter struct {
 	// network-id
 	networkID uint32
 
+	// db
+	dbOplog     pttdb.IndexBatch
+	dbOplogCore pttdb.Storage
+
+	dbMeta pttdb.Storage
+
+	dbPttLockMap *types.LockMap
+
+	// locale
+	localeLock sync.RWMutex
+	locale     Locale
+
 	// me
 	myEntity   RouterMyEntity
 	myNodeID   *discover.NodeID // ptt knows only my-node-id
@@ -208,9 +260,6 @@ type BaseRouter struct {
 }
 
 func NewRouter(ctx *RouterContext, cfg *Config, myNodeID *discover.NodeID, myNodeKey *ecdsa.PrivateKey) (*BaseRouter, error) {
-	// init-service
-	InitService(cfg.DataDir)
-
 	myRaftID, err := myNodeID.ToRaftID()
 	if err != nil {
 		return nil, err
@@ -244,6 +293,13 @@ func NewRouter(ctx *RouterContext, cfg *Config, myNodeID *discover.NodeID, myNod
 
 		dialHist: NewDialHistory(),
 
+		relayHist: NewRelayHistory(),
+
+		peerScores: NewPeerScores(),
+
+		// hubs
+		hubNodes: make(map[discover.NodeID]bool),
+
 		// entities
 		entities: make(map[types.PttID]Entity),
 
@@ -263,6 +319,24 @@ func NewRouter(ctx *RouterContext, cfg *Config, myNodeID *discover.NodeID, myNod
 		errChan: types.NewChan(1),
 	}
 
+	// init-service
+	err = r.initService(cfg.DataDir, cfg.DBEngine)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.loadHubNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.IsHub {
+		r.hubRelays, err = NewHubRelays(r.dbMeta, cfg.HubQuotaBytes, cfg.HubNodeQuotaBytes, cfg.HubTotalQuotaBytes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	r.apis = r.routerAPIs()
 
 	r.protocols = r.generateProtocols()
@@ -270,6 +344,58 @@ func NewRouter(ctx *RouterContext, cfg *Config, myNodeID *discover.NodeID, myNod
 	return r, nil
 }
 
+func (r *BaseRouter) initService(dataDir string, engine pttdb.Engine) error {
+	var err error
+
+	r.dbOplogCore, err = pttdb.NewStorageWithEngine(engine, "oplog", dataDir)
+	if err != nil {
+		return err
+	}
+
+	r.dbOplog, err = pttdb.NewStorageBatch(r.dbOplogCore)
+	if err != nil {
+		return err
+	}
+
+	r.dbMeta, err = pttdb.NewStorageWithEngine(engine, "meta", dataDir)
+	if err != nil {
+		return err
+	}
+
+	r.dbPttLockMap, err = types.NewLockMap(SleepTimePttLock)
+	if err != nil {
+		return err
+	}
+
+	r.locale = r.LoadLocale()
+
+	return nil
+}
+
+func (r *BaseRouter) teardownService() {
+	if r.dbOplog != nil {
+		r.dbOplog = nil
+	}
+
+	if r.dbOplogCore != nil {
+		r.dbOplogCore.Close()
+		r.dbOplogCore = nil
+	}
+
+	if r.dbMeta != nil {
+		r.dbMeta.Close()
+		r.dbMeta = nil
+	}
+
+	if r.dbPttLockMap != nil {
+		r.dbPttLockMap = nil
+	}
+}
+
+func (r *BaseRouter) DBMeta() pttdb.Storage {
+	return r.dbMeta
+}
+
 /**********
  * NodeRouter
  **********/
@@ -345,13 +471,39 @@ func (r *BaseRouter) Start(server *p2p.Server) error {
 		return errMapToErr(errMap)
 	}
 
+	// hubs
+	r.syncWG.Add(1)
+	go func() {
+		defer r.syncWG.Done()
+		r.HubLoop()
+	}()
+
+	// peer-scores
+	r.syncWG.Add(1)
+	go func() {
+		defer r.syncWG.Done()
+		r.PeerScoreLoop()
+	}()
+
+	// metrics
+	pttmetrics.RegisterCollector(MetricsCollectorRouter, r)
+
 	return nil
 }
 
 func (r *BaseRouter) Stop() error {
+	pttmetrics.UnregisterCollector(MetricsCollectorRouter)
+
 	close(r.quitSync)
 	close(r.noMorePeers)
 
+	// close peers before the services, the messages from the peers are not handled with the closed dbs.
+	r.ClosePeers()
+
+	log.Debug("Stop: to wait peerWG")
+
+	r.peerWG.Wait()
+
 	// close all service-loop
 	errMap := make(map[string]error)
 	for name, service := range r.services {
@@ -365,17 +517,12 @@ func (r *BaseRouter) Stop() error {
 
 	r.syncWG.Wait()
 
-	// close peers
-	r.ClosePeers()
-
-	log.Debug("Stop: to wait peerWG")
-
-	r.peerWG.Wait()
-
 	// remove ptt-level chan
 
 	r.eventMux.Stop()
 
+	r.teardownService()
+
 	log.Debug("Stop: done")
 
 	if len(errMap) != 0 {
@@ -389,12 +536,26 @@ func (r *BaseRouter) Stop() error {
  * RW
  **********/
 
+/*
+RWInit inits the read-write of the peer with the negotiated protocol version,
+including the envelope of the encrypted data.
+*/
 func (r *BaseRouter) RWInit(peer *PttPeer, version uint) {
+	peer.encVersion = encVersionFromProtocol(version)
+
 	if rw, ok := peer.RW().(MeteredMsgReadWriter); ok {
 		rw.Init(version)
 	}
 }
 
+func encVersionFromProtocol(version uint) uint8 {
+	if version >= Ptt5 {
+		return EncVersionAEAD
+	}
+
+	return EncVersionLegacy
+}
+
 /**********
  * Service
  **********/
@@ -108,12 +108,12 @@ func (api *PrivateAPI) GetPttOplogSeen() (types.Timestamp, error) {
  **********/
 
 func (api *PrivateAPI) SetLocale(locale Locale) (Locale, error) {
-	err := SetLocale(locale)
-	return CurrentLocale, err
+	err := api.r.SetLocale(locale)
+	return api.r.Locale(), err
 }
 
 func (api *PrivateAPI) GetLocale() (Locale, error) {
-	return CurrentLocale, nil
+	return api.r.Locale(), nil
 }
 
 /**********
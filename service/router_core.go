@@ -86,6 +86,25 @@ func (r *BaseRouter) BEGetPeers() ([]*BackendPeer, error) {
 		peerList = append(peerList, backendPeer)
 	}
 
+	for _, backendPeer = range peerList {
+		backendPeer.Score, backendPeer.BanTS, _ = r.peerScores.Get(backendPeer.NodeID)
+	}
+
+	// banned (disconnected) peers
+	bannedNodeIDs, err := r.peerScores.BannedNodes()
+	if err != nil {
+		return nil, err
+	}
+	for _, nodeID := range bannedNodeIDs {
+		if r.GetPeer(nodeID, true) != nil {
+			continue
+		}
+
+		backendPeer = &BackendPeer{NodeID: nodeID, PeerType: PeerTypeRemoved}
+		backendPeer.Score, backendPeer.BanTS, _ = r.peerScores.Get(nodeID)
+		peerList = append(peerList, backendPeer)
+	}
+
 	return peerList, nil
 }
 
@@ -163,7 +182,7 @@ func (r *BaseRouter) MarkPttOplogSeen() (types.Timestamp, error) {
 		return types.ZeroTimestamp, err
 	}
 
-	err = dbMeta.Put(DBPttLogSeenPrefix, tsBytes)
+	err = r.dbMeta.Put(DBPttLogSeenPrefix, tsBytes)
 	if err != nil {
 		return types.ZeroTimestamp, err
 	}
@@ -172,7 +191,7 @@ func (r *BaseRouter) MarkPttOplogSeen() (types.Timestamp, error) {
 }
 
 func (r *BaseRouter) GetPttOplogSeen() (types.Timestamp, error) {
-	tsBytes, err := dbMeta.Get(DBPttLogSeenPrefix)
+	tsBytes, err := r.dbMeta.Get(DBPttLogSeenPrefix)
 	if err != nil {
 		return types.ZeroTimestamp, nil
 	}
@@ -26,6 +26,21 @@ type RouterEventData struct {
 	Code    CodeType `json:"C"`
 	Hash    []byte   `json:"H,omitempty"`
 	EncData []byte   `json:"D,omitempty"`
+
+	From *RelayFrom `json:"F,omitempty"`
+}
+
+/*
+RelayFrom is the original sender of the relayed data, signed by the sign-key of the sender,
+so that the recipient is able to verify the sender regardless of the relays (see MarshalRelayData).
+*/
+type RelayFrom struct {
+	ID            *types.PttID  `json:"ID"`
+	BytesWithSalt []byte        `json:"B"`
+	Hash          []byte        `json:"H"`
+	Sig           []byte        `json:"S"`
+	Pub           []byte        `json:"P"`
+	Extra         *KeyExtraInfo `json:"E,omitempty"`
 }
 
 // RouterData
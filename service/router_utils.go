@@ -108,6 +108,113 @@ func (r *BaseRouter) DecryptData(ciphertext []byte, keyInfo *KeyInfo) (OpType, [
 	return op, data, nil
 }
 
+/*
+EncryptDataAEAD encrypts data in ptt-layer with the versioned aead-envelope (aes-gcm):
+
+	version (1 byte) | nonce (12 bytes) | sealed(op (4 bytes) | data)
+
+The version, the code and the entity-hash are bound as the associated data,
+so the tampered or the re-targeted ciphertext is rejected in DecryptDataAEAD.
+*/
+func (r *BaseRouter) EncryptDataAEAD(code CodeType, hash *common.Address, op OpType, data []byte, keyInfo *KeyInfo) ([]byte, error) {
+	aead, err := newAEAD(keyInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	marshaled := make([]byte, SizeOpType+len(data))
+	binary.BigEndian.PutUint32(marshaled[:SizeOpType], uint32(op))
+	copy(marshaled[SizeOpType:], data)
+
+	sizeHeader := SizeEncVersion + aead.NonceSize()
+	ciphertext := make([]byte, sizeHeader, sizeHeader+len(marshaled)+aead.Overhead())
+	ciphertext[0] = EncVersionAEAD
+
+	nonce := ciphertext[SizeEncVersion:sizeHeader]
+	err = genIV(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	ad := aeadAssociatedData(EncVersionAEAD, code, hash)
+
+	return aead.Seal(ciphertext, nonce, marshaled, ad), nil
+}
+
+/*
+DecryptDataAEAD decrypts data encrypted by EncryptDataAEAD.
+*/
+func (r *BaseRouter) DecryptDataAEAD(code CodeType, hash *common.Address, ciphertext []byte, keyInfo *KeyInfo) (OpType, []byte, error) {
+	aead, err := newAEAD(keyInfo)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	sizeHeader := SizeEncVersion + aead.NonceSize()
+	if len(ciphertext) < sizeHeader+aead.Overhead()+SizeOpType {
+		return 0, nil, ErrInvalidData
+	}
+
+	if ciphertext[0] != EncVersionAEAD {
+		return 0, nil, ErrInvalidEncVersion
+	}
+
+	nonce := ciphertext[SizeEncVersion:sizeHeader]
+	ad := aeadAssociatedData(EncVersionAEAD, code, hash)
+
+	marshaled, err := aead.Open(nil, nonce, ciphertext[sizeHeader:], ad)
+	if err != nil {
+		return 0, nil, ErrInvalidData
+	}
+
+	op := OpType(binary.BigEndian.Uint32(marshaled[:SizeOpType]))
+	data := marshaled[SizeOpType:]
+
+	return op, data, nil
+}
+
+/*
+EncryptDataWithPeer encrypts data with the envelope negotiated with the peer.
+*/
+func (r *BaseRouter) EncryptDataWithPeer(code CodeType, hash *common.Address, op OpType, data []byte, keyInfo *KeyInfo, peer *PttPeer) ([]byte, error) {
+	if peer.EncVersion() == EncVersionAEAD {
+		return r.EncryptDataAEAD(code, hash, op, data, keyInfo)
+	}
+
+	return r.EncryptData(op, data, keyInfo)
+}
+
+/*
+DecryptDataWithPeer decrypts data with the envelope negotiated with the peer.
+*/
+func (r *BaseRouter) DecryptDataWithPeer(code CodeType, hash *common.Address, ciphertext []byte, keyInfo *KeyInfo, peer *PttPeer) (OpType, []byte, error) {
+	if peer.EncVersion() == EncVersionAEAD {
+		return r.DecryptDataAEAD(code, hash, ciphertext, keyInfo)
+	}
+
+	return r.DecryptData(ciphertext, keyInfo)
+}
+
+func newAEAD(keyInfo *KeyInfo) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(keyInfo.KeyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func aeadAssociatedData(version uint8, code CodeType, hash *common.Address) []byte {
+	ad := make([]byte, SizeEncVersion+SizeCodeType, SizeEncVersion+SizeCodeType+common.AddressLength)
+	ad[0] = version
+	binary.BigEndian.PutUint64(ad[SizeEncVersion:], uint64(code))
+	if hash != nil {
+		ad = append(ad, hash[:]...)
+	}
+
+	return ad
+}
+
 func addBase64Padding(value string) string {
 	m := len(value) % 4
 	if m != 0 {
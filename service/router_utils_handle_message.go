@@ -66,9 +66,29 @@ func (r *BaseRouter) HandleMessage(code CodeType, data *RouterData, peer *PttPee
 
 	log.Debug("HandleMessage: start", "code", code, "peer", peer, "peerType", peer.PeerType)
 
+	if r.IsBanned(peer.GetID()) {
+		return ErrBanned
+	}
+
+	if !r.AllowPeerMsg(peer, &RateKey{Code: code}, r.codeRateLimit(code)) {
+		return nil
+	}
+
 	if !reflect.DeepEqual(data.Node, discover.EmptyNodeID) && !reflect.DeepEqual(data.Node, r.myNodeID[:]) {
-		log.Error("HandleMessage: the msg is not for me or not for broadcast", "code", code, "data.Node", data.Node, "peer", peer)
-		return ErrInvalidData
+		return r.ForwardRelayData(code, data, peer)
+	}
+
+	// relayed to me
+	if data.Relay > 0 {
+		if code != CodeTypeOp {
+			return ErrInvalidData
+		}
+
+		err = r.HandleRelayedOp(data, peer)
+		if err != nil {
+			log.Error("Ptt.HandleMessage: unable to handle relayed op", "e", err)
+		}
+		return nil
 	}
 
 	evCode, evHash, encData, err := r.UnmarshalData(data)
@@ -120,6 +140,13 @@ func (r *BaseRouter) HandleMessage(code CodeType, data *RouterData, peer *PttPee
 		err = r.HandleCodeIdentifyPeerWithMyIDChallengeAck(evHash, encData, peer)
 	case CodeTypeIdentifyPeerWithMyIDAck:
 		err = r.HandleCodeIdentifyPeerWithMyIDAck(evHash, encData, peer)
+
+	case CodeTypeHubAnnounce:
+		err = r.HandleCodeHubAnnounce(evHash, encData, peer)
+	case CodeTypeHubRelay:
+		err = r.HandleCodeHubRelay(evHash, encData, peer)
+	case CodeTypeHubDeliver:
+		err = r.HandleCodeHubDeliver(evHash, encData, peer)
 	default:
 		err = ErrInvalidMsgCode
 	}
@@ -146,7 +173,7 @@ func (r *BaseRouter) HandleCodeJoin(hash *common.Address, encData []byte, peer *
 		return err
 	}
 
-	op, dataBytes, err := r.DecryptData(encData, keyInfo)
+	op, dataBytes, err := r.DecryptDataWithPeer(CodeTypeJoin, hash, encData, keyInfo, peer)
 	if err != nil {
 		log.Error("HandleCodeJoin: unable to DecryptData", "e", err)
 		return err
@@ -176,7 +203,7 @@ func (r *BaseRouter) HandleCodeJoinAck(hash *common.Address, encData []byte, pee
 
 	keyInfo := joinKeyToKeyInfo(joinRequest.Key)
 
-	op, dataBytes, err := r.DecryptData(encData, keyInfo)
+	op, dataBytes, err := r.DecryptDataWithPeer(CodeTypeJoinAck, hash, encData, keyInfo, peer)
 	if err != nil {
 		return err
 	}
@@ -204,9 +231,14 @@ func (r *BaseRouter) HandleCodeOp(hash *common.Address, encData []byte, peer *Pt
 		return r.OpFail(hash, peer)
 	}
 
+	if r.isBlockedOp(entity, peer) {
+		log.Debug("HandleCodeOp: blocked", "entity", entity.IDString(), "peer", peer, "userID", peer.UserID)
+		return nil
+	}
+
 	pm := entity.PM()
 
-	err = PMHandleMessageWrapper(pm, hash, encData, peer)
+	err = PMHandleMessageWrapper(pm, CodeTypeOp, hash, encData, peer)
 
 	return err
 }
@@ -252,7 +284,7 @@ func (r *BaseRouter) HandleCodeIdentifyPeer(hash *common.Address, encData []byte
 
 	pm := entity.PM()
 
-	err = PMHandleMessageWrapper(pm, hash, encData, peer)
+	err = PMHandleMessageWrapper(pm, CodeTypeIdentifyPeer, hash, encData, peer)
 	if err != nil {
 		r.IdentifyPeerFail(hash, peer)
 	}
@@ -284,3 +316,15 @@ func (r *BaseRouter) HandleCodeIdentifyPeerWithMyIDAck(hash *common.Address, enc
 
 	return r.HandleIdentifyPeerWithMyIDAck(encData, peer)
 }
+
+func (r *BaseRouter) HandleCodeHubAnnounce(hash *common.Address, encData []byte, peer *PttPeer) error {
+	return r.HandleHubAnnounce(encData, peer)
+}
+
+func (r *BaseRouter) HandleCodeHubRelay(hash *common.Address, encData []byte, peer *PttPeer) error {
+	return r.HandleHubRelay(encData, peer)
+}
+
+func (r *BaseRouter) HandleCodeHubDeliver(hash *common.Address, encData []byte, peer *PttPeer) error {
+	return r.HandleHubDeliver(encData, peer)
+}
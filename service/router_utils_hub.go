@@ -0,0 +1,213 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"time"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+)
+
+/**********
+ * Hub Node
+ **********/
+
+func (r *BaseRouter) IsHub() bool {
+	return r.config.IsHub
+}
+
+/*
+loadHubNodes loads the hubs from the config and the hubs that we've learned.
+*/
+func (r *BaseRouter) loadHubNodes() error {
+	r.lockHubNodes.Lock()
+	defer r.lockHubNodes.Unlock()
+
+	for _, hubNode := range r.config.HubNodes {
+		nodeID, err := discover.HexID(hubNode)
+		if err != nil {
+			return err
+		}
+		r.hubNodes[nodeID] = true
+	}
+
+	nodeIDs, err := loadHubNodes(r.dbMeta)
+	if err != nil {
+		return err
+	}
+
+	for _, nodeID := range nodeIDs {
+		r.hubNodes[*nodeID] = true
+	}
+
+	return nil
+}
+
+/*
+AddHubNode learns the hub.
+*/
+func (r *BaseRouter) AddHubNode(nodeID *discover.NodeID) error {
+	r.lockHubNodes.Lock()
+	defer r.lockHubNodes.Unlock()
+
+	if r.hubNodes[*nodeID] {
+		return nil
+	}
+
+	err := saveHubNode(r.dbMeta, nodeID)
+	if err != nil {
+		return err
+	}
+
+	r.hubNodes[*nodeID] = true
+
+	return nil
+}
+
+func (r *BaseRouter) HubNodeList() []*discover.NodeID {
+	r.lockHubNodes.RLock()
+	defer r.lockHubNodes.RUnlock()
+
+	nodeIDs := make([]*discover.NodeID, 0, len(r.hubNodes))
+	for nodeID := range r.hubNodes {
+		eachNodeID := nodeID
+		nodeIDs = append(nodeIDs, &eachNodeID)
+	}
+
+	return nodeIDs
+}
+
+/**********
+ * Hub Peer
+ **********/
+
+func (r *BaseRouter) HubPeerList() []*PttPeer {
+	r.peerLock.RLock()
+	defer r.peerLock.RUnlock()
+
+	peerList := make([]*PttPeer, 0, len(r.hubPeers))
+	for _, peer := range r.hubPeers {
+		peerList = append(peerList, peer)
+	}
+
+	return peerList
+}
+
+func (r *BaseRouter) setHubPeer(peer *PttPeer) error {
+	r.peerLock.Lock()
+	defer r.peerLock.Unlock()
+
+	err := r.ValidatePeer(peer.GetID(), peer.UserID, PeerTypeHub, true)
+	if err != nil {
+		return err
+	}
+
+	return r.SetPeerType(peer, PeerTypeHub, false, true)
+}
+
+/*
+PushToHub pushes the data that are not delivered yet in all the entities to the hub.
+*/
+func (r *BaseRouter) PushToHub(peer *PttPeer) error {
+	r.entityLock.RLock()
+	pms := make([]ProtocolManager, 0, len(r.entities))
+	for _, entity := range r.entities {
+		pms = append(pms, entity.PM())
+	}
+	r.entityLock.RUnlock()
+
+	var err error
+	for _, pm := range pms {
+		err = pm.PushToHub(peer)
+		if err != nil {
+			log.Warn("PushToHub: unable to push to hub", "entity", pm.Entity().IDString(), "peer", peer, "e", err)
+		}
+	}
+
+	return nil
+}
+
+/**********
+ * Hub Loop
+ **********/
+
+/*
+HubLoop dials the hubs that are not connected,
+and removes the expired relayed data if we are the hub.
+*/
+func (r *BaseRouter) HubLoop() error {
+	ticker := time.NewTicker(HubLoopInterval)
+	defer ticker.Stop()
+
+	r.dialHubs()
+
+loop:
+	for {
+		select {
+		case <-ticker.C:
+			r.dialHubs()
+			r.ExpireHubRelays()
+		case <-r.quitSync:
+			log.Debug("HubLoop: QuitSync")
+			break loop
+		}
+	}
+
+	return nil
+}
+
+func (r *BaseRouter) dialHubs() {
+	if r.server == nil {
+		return
+	}
+
+	nodeIDs := r.HubNodeList()
+	for _, nodeID := range nodeIDs {
+		if *nodeID == *r.myNodeID {
+			continue
+		}
+
+		if r.GetPeer(nodeID, false) != nil {
+			continue
+		}
+
+		if r.IsBanned(nodeID) {
+			continue
+		}
+
+		log.Debug("dialHubs: to dial hub", "nodeID", nodeID)
+
+		node := discover.NewWebrtcNode(*nodeID)
+		r.server.AddPeer(node)
+	}
+}
+
+func (r *BaseRouter) ExpireHubRelays() error {
+	if !r.config.IsHub {
+		return nil
+	}
+
+	expireTS, err := types.GetTimestamp()
+	if err != nil {
+		return err
+	}
+	expireTS.Ts -= int64(r.config.HubExpireSeconds)
+
+	return r.hubRelays.Expire(expireTS)
+}
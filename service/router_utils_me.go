@@ -18,6 +18,7 @@ package service
 
 import (
 	"crypto/ecdsa"
+	"reflect"
 
 	"github.com/ailabstw/go-pttai-core/common/types"
 	"github.com/ailabstw/go-pttai-core/p2p/discover"
@@ -54,6 +55,54 @@ func (r *BaseRouter) GetMyEntity() MyEntity {
 	return r.myEntity
 }
 
+/*
+IsBlockedUser checks whether the user is in the block-list of me.
+*/
+func (r *BaseRouter) IsBlockedUser(id *types.PttID) bool {
+	if id == nil || r.myEntity == nil {
+		return false
+	}
+
+	return r.myEntity.IsBlockedUser(id)
+}
+
+/*
+isBlockedOp checks whether the op-msg of the entity from the peer is to be dropped,
+either the peer is a blocked user, or the entity is with a blocked user (ex: friend).
+The op-msgs from my devices are not dropped.
+
+The oplogs / the synced objects created by the blocked users are dropped after decrypted and verified
+(see filterBlockedOplogs and HandleSyncCreateObjectAck).
+*/
+func (r *BaseRouter) isBlockedOp(entity Entity, peer *PttPeer) bool {
+	if peer.PeerType == PeerTypeMe {
+		return false
+	}
+
+	return r.isBlockedUserOp(entity, peer.UserID)
+}
+
+/*
+isBlockedUserOp checks whether the op-msg of the entity from the user is to be dropped,
+the user is the original sender instead of the peer for the relayed op-msg.
+*/
+func (r *BaseRouter) isBlockedUserOp(entity Entity, userID *types.PttID) bool {
+	if userID != nil && r.myEntity != nil && reflect.DeepEqual(userID, r.myEntity.GetID()) {
+		return false
+	}
+
+	if r.IsBlockedUser(userID) {
+		return true
+	}
+
+	friendEntity, ok := entity.(FriendEntity)
+	if !ok {
+		return false
+	}
+
+	return r.IsBlockedUser(friendEntity.GetFriendID())
+}
+
 func (r *BaseRouter) GetMyEntityFromMe(myID *types.PttID) Entity {
 	return nil
 }
@@ -0,0 +1,156 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type testBlockMyEntity struct {
+	RouterMyEntity
+
+	myID       *types.PttID
+	signKey    *KeyInfo
+	blockedIDs map[types.PttID]bool
+}
+
+func (m *testBlockMyEntity) GetID() *types.PttID {
+	return m.myID
+}
+
+func (m *testBlockMyEntity) SignKey() *KeyInfo {
+	return m.signKey
+}
+
+func (m *testBlockMyEntity) IsBlockedUser(id *types.PttID) bool {
+	return m.blockedIDs[*id]
+}
+
+type testFriendEntity struct {
+	Entity
+
+	friendID *types.PttID
+}
+
+func (e *testFriendEntity) GetFriendID() *types.PttID {
+	return e.friendID
+}
+
+func TestRouter_IsBlockedOp(t *testing.T) {
+	setupTest(t)
+	defer teardownTest(t)
+
+	blockedID := &types.PttID{1}
+	goodID := &types.PttID{2}
+	myID := &types.PttID{3}
+
+	r := &BaseRouter{}
+
+	// no my-entity
+	assert.False(t, r.IsBlockedUser(blockedID))
+
+	r.myEntity = &testBlockMyEntity{myID: myID, blockedIDs: map[types.PttID]bool{*blockedID: true}}
+
+	assert.True(t, r.IsBlockedUser(blockedID))
+	assert.False(t, r.IsBlockedUser(goodID))
+	assert.False(t, r.IsBlockedUser(nil))
+
+	var entity Entity
+	friendWithBlocked := &testFriendEntity{friendID: blockedID}
+	friendWithGood := &testFriendEntity{friendID: goodID}
+
+	tests := []struct {
+		name   string
+		entity Entity
+		peer   *PttPeer
+		want   bool
+	}{
+		{"blocked peer", entity, &PttPeer{UserID: blockedID, PeerType: PeerTypeRandom}, true},
+		{"good peer", entity, &PttPeer{UserID: goodID, PeerType: PeerTypeRandom}, false},
+		{"unidentified peer", entity, &PttPeer{PeerType: PeerTypeRandom}, false},
+		{"friend with blocked user from hub", friendWithBlocked, &PttPeer{UserID: goodID, PeerType: PeerTypeHub}, true},
+		{"friend with blocked user from my device", friendWithBlocked, &PttPeer{UserID: blockedID, PeerType: PeerTypeMe}, false},
+		{"friend with good user", friendWithGood, &PttPeer{UserID: goodID, PeerType: PeerTypeImportant}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, r.isBlockedOp(tt.entity, tt.peer))
+		})
+	}
+
+	// the original sender of the relayed op-msgs.
+	userTests := []struct {
+		name   string
+		entity Entity
+		userID *types.PttID
+		want   bool
+	}{
+		{"blocked sender", entity, blockedID, true},
+		{"good sender", entity, goodID, false},
+		{"friend with blocked user from good sender", friendWithBlocked, goodID, true},
+		{"friend with blocked user from me", friendWithBlocked, myID, false},
+		{"friend with good user from good sender", friendWithGood, goodID, false},
+	}
+
+	for _, tt := range userTests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, r.isBlockedUserOp(tt.entity, tt.userID))
+		})
+	}
+}
+
+func TestFilterBlockedOplogs(t *testing.T) {
+	setupTest(t)
+	defer teardownTest(t)
+
+	blockedID := &types.PttID{1}
+	goodID := &types.PttID{2}
+
+	r := &BaseRouter{myEntity: &testBlockMyEntity{myID: &types.PttID{3}, blockedIDs: map[types.PttID]bool{*blockedID: true}}}
+
+	// without the router
+	assert.False(t, isBlockedCreator(nil, blockedID))
+
+	oplogs := []*BaseOplog{
+		{ID: &types.PttID{4}, CreatorID: goodID},
+		{ID: &types.PttID{5}, CreatorID: blockedID},
+		{ID: &types.PttID{6}, CreatorID: goodID},
+	}
+
+	// relayed by the good peer, still dropped with the creator.
+	got := filterBlockedOplogs(oplogs, r)
+	assert.Equal(t, 2, len(got))
+	for _, oplog := range got {
+		assert.Equal(t, goodID, oplog.CreatorID)
+	}
+
+	// the synced object created by the blocked user.
+	pm := &BaseProtocolManager{ptt: r}
+	obj := &Media{BaseObject: &BaseObject{ID: &types.PttID{7}, CreatorID: blockedID}}
+	setLogDB := func(oplog *BaseOplog) {
+		t.Errorf("setLogDB: the object of the blocked user is handled")
+	}
+
+	err := pm.HandleSyncCreateObjectAck(obj, nil, nil, nil, setLogDB, nil, nil, nil)
+	assert.NoError(t, err)
+	err = pm.HandleSyncUpdateObjectAck(obj, nil, nil, nil, setLogDB, nil, nil, nil)
+	assert.NoError(t, err)
+}
@@ -45,31 +45,46 @@ func (r *BaseRouter) NewPeer(version uint, peer *p2p.Peer, rw p2p.MsgReadWriter)
 /*
 HandlePeer handles peer
 	1. Basic handshake
-	2. AddNewPeer (defer RemovePeer)
-	3. init read/write
-	4. for-loop handle-message
+	2. init read/write
+	3. AddNewPeer (defer RemovePeer), and sign the oplogs in json while the peer is legacy
+	4. announce as hub
+	5. for-loop handle-message
 */
 func (r *BaseRouter) HandlePeer(peer *PttPeer) error {
 	log.Debug("HandlePeer: start", "peer", peer)
 	defer log.Debug("HandlePeer: done", "peer", peer)
 
+	// 0. banned peer
+	if r.IsBanned(peer.GetID()) {
+		log.Warn("HandlePeer: banned peer", "peer", peer)
+		return ErrBanned
+	}
+
 	// 1. basic handshake
 	err := peer.Handshake(r.networkID)
 	if err != nil {
 		return err
 	}
 
-	// 2. add new peer (defer remove-peer)
+	// 2. init read-write (before the peer is available for sending data)
+	r.RWInit(peer, peer.Version())
+
+	// 3. add new peer (defer remove-peer)
 	err = r.AddNewPeer(peer)
 	if err != nil {
 		return err
 	}
 	defer r.RemovePeer(peer, false)
 
-	// 3. init read-write
-	r.RWInit(peer, peer.Version())
+	// 4. announce as hub
+	if r.config.IsHub {
+		err = r.HubAnnounce(peer)
+		if err != nil {
+			log.Warn("HandlePeer: unable to HubAnnounce", "peer", peer, "e", err)
+		}
+	}
 
-	// 4. for-loop handle-message
+	// 5. for-loop handle-message
 	log.Info("HandlePeer: to for-loop", "peer", peer)
 
 looping:
@@ -230,7 +245,10 @@ func (r *BaseRouter) determinePeerTypeFromAllEntities(peer *PttPeer) (PeerType,
 }
 
 func (r *BaseRouter) IsHubPeer(peer *PttPeer) bool {
-	return false
+	r.lockHubNodes.RLock()
+	defer r.lockHubNodes.RUnlock()
+
+	return r.hubNodes[peer.ID()]
 }
 
 /*
@@ -320,6 +338,11 @@ func (r *BaseRouter) RemovePeer(peer *PttPeer, isLocked bool) error {
 		log.Error("unable to remove peer", "peer", peer, "e", err)
 	}
 
+	err = r.peerScores.Remove(peer.GetID())
+	if err != nil {
+		log.Error("unable to remove peer-score", "peer", peer, "e", err)
+	}
+
 	node := &discover.Node{ID: peer.ID()}
 	r.server.RemovePeer(node)
 
@@ -769,6 +792,11 @@ looping:
  **********/
 
 func (r *BaseRouter) AddDial(nodeID *discover.NodeID, opKey *common.Address, peerType PeerType, isAddPeer bool) error {
+	if r.IsBanned(nodeID) {
+		log.Debug("router.AddDial: banned node", "nodeID", nodeID)
+		return ErrBanned
+	}
+
 	peer := r.GetPeer(nodeID, false)
 
 	if peer != nil && peer.UserID != nil {
@@ -832,6 +860,10 @@ func (r *BaseRouter) checkDialEntity(peer *PttPeer) (Entity, error) {
 		return nil, nil
 	}
 
+	if r.IsBanned(peer.GetID()) {
+		return nil, nil
+	}
+
 	r.lockOps.RLock()
 	defer r.lockOps.RUnlock()
 
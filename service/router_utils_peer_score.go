@@ -0,0 +1,107 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"time"
+
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+)
+
+/*
+AllowPeerMsg checks the rate-limit of the message from the peer.
+The peer is misbehaving if the message exceeds the rate-limit.
+*/
+func (r *BaseRouter) AllowPeerMsg(peer *PttPeer, key *RateKey, limit *RateLimit) bool {
+	isAllowed, err := r.peerScores.Allow(peer.GetID(), key, limit)
+	if err != nil {
+		log.Warn("AllowPeerMsg: unable to check rate-limit", "e", err, "peer", peer)
+		return true
+	}
+	if isAllowed {
+		return true
+	}
+
+	log.Warn("AllowPeerMsg: rate-limited", "code", key.Code, "op", key.Op, "entity", key.EntityID, "peer", peer)
+	r.Misbehave(peer, ScoreRateLimited)
+
+	return false
+}
+
+/*
+Misbehave adds the misbehaviour score to the peer.
+The peer is banned and disconnected if the score reaches BanScore.
+*/
+func (r *BaseRouter) Misbehave(peer *PttPeer, score int) error {
+	nodeID := peer.GetID()
+	isBanned, err := r.peerScores.Add(nodeID, score)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Misbehave: added score", "score", score, "isBanned", isBanned, "peer", peer)
+
+	if !isBanned {
+		return nil
+	}
+
+	log.Warn("Misbehave: banned peer", "peer", peer, "seconds", BanSeconds)
+
+	r.dialHist.Remove(nodeID)
+
+	if r.server != nil {
+		node := &discover.Node{ID: peer.ID()}
+		r.server.RemovePeer(node)
+	}
+
+	return nil
+}
+
+func (r *BaseRouter) IsBanned(nodeID *discover.NodeID) bool {
+	return r.peerScores.IsBanned(nodeID)
+}
+
+/*
+PeerScoreLoop expires the scores and the bans of the disconnected peers.
+*/
+func (r *BaseRouter) PeerScoreLoop() error {
+	ticker := time.NewTicker(PeerScoreLoopInterval)
+	defer ticker.Stop()
+
+loop:
+	for {
+		select {
+		case <-ticker.C:
+			r.peerScores.Expire()
+		case <-r.quitSync:
+			log.Debug("PeerScoreLoop: QuitSync")
+			break loop
+		}
+	}
+
+	return nil
+}
+
+func (r *BaseRouter) codeRateLimit(code CodeType) *RateLimit {
+	limit, ok := CodeRateLimits[code]
+	if !ok {
+		return DefaultCodeRateLimit
+	}
+
+	return limit
+}
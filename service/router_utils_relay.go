@@ -0,0 +1,309 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"reflect"
+
+	pttcommon "github.com/ailabstw/go-pttai-core/common"
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/log"
+	"github.com/ailabstw/go-pttai-core/p2p/discover"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+/*
+RelayData sends the data to the node through the connected peers (requester).
+
+Relay is the number of the hops that the data goes through, and is always positive for the relayed data,
+so that the node handles the data as relayed even if the node is connected.
+
+The data is sent to the node directly if the node is connected,
+or sent to at most MaxRelayPeers peers forwarding the still-encrypted data toward the node.
+Only the ptt5-peers are able to forward the data.
+
+Only the data explicitly relayed by the pm (RelayDataToNode) goes through the relays,
+the other op-data (SendDataToPeer / SendDataToPeers) is still sent to the connected peers only.
+*/
+func (r *BaseRouter) RelayData(nodeID *discover.NodeID, data *RouterData) error {
+	data.Node = nodeID[:]
+	data.Relay = 1
+
+	peers, _ := r.relayPeers(nodeID, nil)
+	if len(peers) == 0 {
+		return ErrNoPeer
+	}
+
+	log.Debug("RelayData: to send", "nodeID", nodeID, "peers", len(peers))
+
+	var err error
+	sent := 0
+	for _, peer := range peers {
+		err = peer.SendData(data)
+		if err != nil {
+			log.Warn("RelayData: unable to send", "nodeID", nodeID, "peer", peer, "e", err)
+			continue
+		}
+		sent++
+	}
+	if sent == 0 {
+		return ErrNotSent
+	}
+
+	return nil
+}
+
+/*
+ForwardRelayData forwards the data not for me toward the target node (relay).
+
+The data is dropped if we are not the relay, the data exceeds the hops,
+the data is seen already (looping among the relays), or the peer exceeds the relay-quota.
+*/
+func (r *BaseRouter) ForwardRelayData(code CodeType, data *RouterData, peer *PttPeer) error {
+	if !r.config.IsRelay || peer.Version() < Ptt5 || code != CodeTypeOp {
+		log.Error("ForwardRelayData: the msg is not for me or not for broadcast", "code", code, "data.Node", data.Node, "peer", peer)
+		return ErrInvalidData
+	}
+
+	nodeID, err := discover.BytesID(data.Node)
+	if err != nil {
+		return ErrInvalidData
+	}
+	if nodeID == *peer.GetID() {
+		return ErrInvalidData
+	}
+
+	if data.Relay >= MaxRelayHops {
+		log.Debug("ForwardRelayData: exceeding hops", "nodeID", nodeID, "relay", data.Relay, "peer", peer)
+		return nil
+	}
+
+	err = r.verifyChecksumData(data.EvWithSalt, data.Checksum)
+	if err != nil {
+		return err
+	}
+
+	isSeen, isOverQuota, err := r.relayHist.Add(data.Checksum, peer.GetID(), len(data.EvWithSalt), r.config.RelayQuotaBytes)
+	if err != nil {
+		return err
+	}
+	if isSeen {
+		log.Debug("ForwardRelayData: seen", "nodeID", nodeID, "peer", peer)
+		return nil
+	}
+	if isOverQuota {
+		log.Warn("ForwardRelayData: exceeding quota", "nodeID", nodeID, "peer", peer)
+		return nil
+	}
+
+	forwarded := data.Clone()
+	forwarded.Relay++
+
+	peers, isTarget := r.relayPeers(&nodeID, peer)
+	if !isTarget && forwarded.Relay >= MaxRelayHops {
+		peers = nil
+	}
+
+	log.Debug("ForwardRelayData: to forward", "nodeID", nodeID, "relay", forwarded.Relay, "isTarget", isTarget, "peers", len(peers), "from", peer)
+
+	for _, eachPeer := range peers {
+		err = eachPeer.SendData(forwarded)
+		if err != nil {
+			log.Warn("ForwardRelayData: unable to send", "nodeID", nodeID, "peer", eachPeer, "e", err)
+		}
+	}
+
+	return nil
+}
+
+/*
+relayPeers gets the target peer if the node is connected,
+or at most MaxRelayPeers ptt5-peers except the peer that the data is from.
+
+Return: peers, isTarget
+*/
+func (r *BaseRouter) relayPeers(nodeID *discover.NodeID, fromPeer *PttPeer) ([]*PttPeer, bool) {
+	r.peerLock.RLock()
+	defer r.peerLock.RUnlock()
+
+	peer := r.GetPeer(nodeID, true)
+	if peer != nil && peer.Version() >= Ptt5 {
+		return []*PttPeer{peer}, true
+	}
+
+	peers := make([]*PttPeer, 0, MaxRelayPeers)
+	peerMaps := []map[discover.NodeID]*PttPeer{r.myPeers, r.hubPeers, r.importantPeers, r.memberPeers, r.pendingPeers, r.randomPeers}
+	for _, peerMap := range peerMaps {
+		for id, peer := range peerMap {
+			if len(peers) == MaxRelayPeers {
+				return peers, false
+			}
+			if fromPeer != nil && id == *fromPeer.GetID() {
+				continue
+			}
+			if peer.Version() < Ptt5 {
+				continue
+			}
+			peers = append(peers, peer)
+		}
+	}
+
+	return peers, false
+}
+
+/*
+MarshalRelayData marshals the encrypted data to be relayed (RelayDataToNode / RelayDataToHub),
+with the original sender (me) signing the hash and the encrypted data by my sign-key,
+because the peer that the recipient receives the data from is the last relay instead of me.
+*/
+func (r *BaseRouter) MarshalRelayData(code CodeType, hash *common.Address, encData []byte) (*RouterData, error) {
+	if r.myEntity == nil {
+		return nil, ErrInvalidEntity
+	}
+
+	signKey := r.myEntity.SignKey()
+	if signKey == nil {
+		return nil, ErrInvalidKey
+	}
+
+	relayHash, err := relayDataHash(hash, encData)
+	if err != nil {
+		return nil, err
+	}
+
+	bytesWithSalt, sigHash, sig, pubBytes, err := SignData(relayHash, signKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ev := &RouterEventData{
+		Code:    code,
+		Hash:    hash[:],
+		EncData: encData,
+		From: &RelayFrom{
+			ID:            r.myEntity.GetID(),
+			BytesWithSalt: bytesWithSalt,
+			Hash:          sigHash,
+			Sig:           sig,
+			Pub:           pubBytes,
+			Extra:         signKey.Extra,
+		},
+	}
+
+	evWithSalt, checksum, err := r.checksumPttEventData(ev)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RouterData{
+		Code:       code,
+		Hash:       hash[:],
+		EvWithSalt: evWithSalt,
+		Checksum:   checksum,
+		Relay:      0,
+	}, nil
+}
+
+/*
+UnmarshalRelayData unmarshals the relayed data and verifies the original sender of the data.
+
+Return: code, hash, encData, fromID, error
+*/
+func (r *BaseRouter) UnmarshalRelayData(routerData *RouterData) (CodeType, *common.Address, []byte, *types.PttID, error) {
+	ev, err := r.verifyChecksumEventData(routerData)
+	if err != nil {
+		return CodeTypeInvalid, nil, nil, nil, err
+	}
+
+	from := ev.From
+	if from == nil || from.ID == nil || len(from.BytesWithSalt) != common.HashLength+types.SizeSalt {
+		return CodeTypeInvalid, nil, nil, nil, ErrInvalidData
+	}
+
+	hashAddr := &common.Address{}
+	copy(hashAddr[:], ev.Hash[:])
+
+	relayHash, err := relayDataHash(hashAddr, ev.EncData)
+	if err != nil {
+		return CodeTypeInvalid, nil, nil, nil, err
+	}
+	if !reflect.DeepEqual(from.BytesWithSalt[:common.HashLength], relayHash) {
+		return CodeTypeInvalid, nil, nil, nil, ErrInvalidData
+	}
+
+	err = VerifyData(from.BytesWithSalt, from.Hash, from.Sig, from.Pub, from.ID, from.Extra)
+	if err != nil {
+		return CodeTypeInvalid, nil, nil, nil, err
+	}
+
+	return ev.Code, hashAddr, ev.EncData, from.ID, nil
+}
+
+func relayDataHash(hash *common.Address, encData []byte) ([]byte, error) {
+	hashEncData, err := pttcommon.Concat([][]byte{hash[:], encData})
+	if err != nil {
+		return nil, err
+	}
+
+	return crypto.Keccak256(hashEncData), nil
+}
+
+/*
+HandleRelayedOp handles the op-data relayed to me (target).
+The relayed data is always in the aead-envelope (see RelayDataToNode).
+The peer is the last relay instead of the sender,
+the block-list is checked with the original sender verified by the signature in the data (see MarshalRelayData).
+*/
+func (r *BaseRouter) HandleRelayedOp(data *RouterData, peer *PttPeer) error {
+	code, hash, encData, fromID, err := r.UnmarshalRelayData(data)
+	if err != nil {
+		log.Warn("HandleRelayedOp: unable to unmarshal", "peer", peer, "e", err)
+		return err
+	}
+
+	if code != CodeTypeOp || !reflect.DeepEqual(hash[:], data.Hash[:]) {
+		return ErrInvalidData
+	}
+
+	entity, err := r.getEntityFromHash(hash, &r.lockOps, r.ops)
+	if err != nil {
+		log.Warn("HandleRelayedOp: invalid entity", "hash", hash, "e", err)
+		return err
+	}
+
+	if r.isBlockedUserOp(entity, fromID) {
+		log.Debug("HandleRelayedOp: blocked", "entity", entity.IDString(), "from", fromID, "peer", peer)
+		return nil
+	}
+
+	pm := entity.PM()
+
+	opKeyInfo, err := pm.GetOpKeyFromHash(hash, false)
+	if err != nil {
+		return err
+	}
+
+	op, relayedBytes, err := r.DecryptDataAEAD(CodeTypeOp, hash, encData, opKeyInfo)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("HandleRelayedOp: to HandleRelayedMessage", "entity", entity.IDString(), "op", op, "from", fromID, "peer", peer)
+
+	return pm.HandleRelayedMessage(op, relayedBytes)
+}
@@ -325,3 +325,144 @@ func Test_aesPadUnPad(t *testing.T) {
 		})
 	}
 }
+
+func TestRouter_EncryptDataAEAD(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	r := tDefaultPtt
+
+	encData, err := r.EncryptDataAEAD(CodeTypeOp, &tDefaultHash, tDefaultOp, tDefaultDataBytes, tDefaultKeyInfo)
+	if err != nil {
+		t.Errorf("Ptt.EncryptDataAEAD() error = %v", err)
+		return
+	}
+	if encData[0] != EncVersionAEAD {
+		t.Errorf("Ptt.EncryptDataAEAD() version = %v, want %v", encData[0], EncVersionAEAD)
+	}
+
+	tampered := append([]byte{}, encData...)
+	tampered[len(tampered)-1] ^= 0x01
+
+	legacy := append([]byte{}, encData...)
+	legacy[0] = EncVersionLegacy
+
+	otherHash := common.Address{}
+
+	// define test-structure
+	type args struct {
+		code    CodeType
+		hash    *common.Address
+		encData []byte
+	}
+
+	// prepare test-cases
+	tests := []struct {
+		name    string
+		args    args
+		want    OpType
+		want1   []byte
+		wantErr bool
+	}{
+		{
+			name:  "ok",
+			args:  args{code: CodeTypeOp, hash: &tDefaultHash, encData: encData},
+			want:  tDefaultOp,
+			want1: tDefaultDataBytes,
+		},
+		{
+			name:    "tampered",
+			args:    args{code: CodeTypeOp, hash: &tDefaultHash, encData: tampered},
+			wantErr: true,
+		},
+		{
+			name:    "invalid version",
+			args:    args{code: CodeTypeOp, hash: &tDefaultHash, encData: legacy},
+			wantErr: true,
+		},
+		{
+			name:    "other code",
+			args:    args{code: CodeTypeIdentifyPeer, hash: &tDefaultHash, encData: encData},
+			wantErr: true,
+		},
+		{
+			name:    "other hash",
+			args:    args{code: CodeTypeOp, hash: &otherHash, encData: encData},
+			wantErr: true,
+		},
+		{
+			name:    "too short",
+			args:    args{code: CodeTypeOp, hash: &tDefaultHash, encData: encData[:SizeEncVersion+12]},
+			wantErr: true,
+		},
+	}
+
+	// run test
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1, err := r.DecryptDataAEAD(tt.args.code, tt.args.hash, tt.args.encData, tDefaultKeyInfo)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Ptt.DecryptDataAEAD() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Ptt.DecryptDataAEAD() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("Ptt.DecryptDataAEAD() got1 = %v, want1 %v", got1, tt.want1)
+			}
+		})
+	}
+
+	// teardown test
+}
+
+func TestRouter_EncryptDataWithPeer(t *testing.T) {
+	// setup test
+	setupTest(t)
+	defer teardownTest(t)
+
+	r := tDefaultPtt
+
+	// prepare test-cases
+	tests := []struct {
+		name    string
+		version uint
+		want    uint8
+	}{
+		{name: "ptt4", version: Ptt4, want: EncVersionLegacy},
+		{name: "ptt5", version: Ptt5, want: EncVersionAEAD},
+	}
+
+	// run test
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			peer := &PttPeer{}
+			r.RWInit(peer, tt.version)
+			if peer.EncVersion() != tt.want {
+				t.Errorf("Ptt.RWInit() encVersion = %v, want %v", peer.EncVersion(), tt.want)
+			}
+
+			encData, err := r.EncryptDataWithPeer(CodeTypeOp, &tDefaultHash, tDefaultOp, tDefaultDataBytes, tDefaultKeyInfo, peer)
+			if err != nil {
+				t.Errorf("Ptt.EncryptDataWithPeer() error = %v", err)
+				return
+			}
+			if tt.want == EncVersionAEAD && encData[0] != EncVersionAEAD {
+				t.Errorf("Ptt.EncryptDataWithPeer() version = %v, want %v", encData[0], EncVersionAEAD)
+			}
+
+			op, data, err := r.DecryptDataWithPeer(CodeTypeOp, &tDefaultHash, encData, tDefaultKeyInfo, peer)
+			if err != nil {
+				t.Errorf("Ptt.DecryptDataWithPeer() error = %v", err)
+				return
+			}
+			if op != tDefaultOp || !reflect.DeepEqual(data, tDefaultDataBytes) {
+				t.Errorf("Ptt.DecryptDataWithPeer() = (%v, %v), want (%v, %v)", op, data, tDefaultOp, tDefaultDataBytes)
+			}
+		})
+	}
+
+	// teardown test
+}
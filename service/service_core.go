@@ -17,10 +17,12 @@
 package service
 
 import (
+	"context"
 	"reflect"
 
 	"github.com/ailabstw/go-pttai-core/common/types"
 	"github.com/ailabstw/go-pttai-core/pttdb"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 /**********
@@ -442,3 +444,35 @@ func (b *BaseService) GetMyMemberLog(entityIDBytes []byte) (*BaseOplog, error) {
 
 	return myMemberLog.BaseOplog, nil
 }
+
+/**********
+ * Subscribe
+ **********/
+
+/*
+SubscribeEntityStatus subscribes the status-changes of the entity (all the entities of the service if entityID is empty).
+*/
+func (svc *BaseService) SubscribeEntityStatus(ctx context.Context, entityIDBytes []byte) (*rpc.Subscription, error) {
+	entityID, err := types.UnmarshalTextPttID(entityIDBytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return SubscribeEvent(ctx, svc.SPM().EventMux(), &EntityStatusEvent{}, func(ev interface{}) bool {
+		return entityID == nil || reflect.DeepEqual(ev.(*EntityStatusEvent).EntityID, entityID)
+	})
+}
+
+/*
+SubscribeMerkleSync subscribes the merkle-syncs of the entity (all the entities of the service if entityID is empty).
+*/
+func (svc *BaseService) SubscribeMerkleSync(ctx context.Context, entityIDBytes []byte) (*rpc.Subscription, error) {
+	entityID, err := types.UnmarshalTextPttID(entityIDBytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return SubscribeEvent(ctx, svc.SPM().EventMux(), &MerkleSyncEvent{}, func(ev interface{}) bool {
+		return entityID == nil || reflect.DeepEqual(ev.(*MerkleSyncEvent).EntityID, entityID)
+	})
+}
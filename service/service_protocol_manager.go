@@ -21,6 +21,7 @@ import (
 
 	"github.com/ailabstw/go-pttai-core/common/types"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
 )
 
 /*
@@ -59,6 +60,9 @@ type ServiceProtocolManager interface {
 	RUnlock(id *types.PttID) error
 
 	NewEmptyEntity() Entity
+
+	// event-mux for the rpc-subscriptions
+	EventMux() *event.TypeMux
 }
 
 type BaseServiceProtocolManager struct {
@@ -79,6 +83,9 @@ type BaseServiceProtocolManager struct {
 
 	lockJoinRequest sync.RWMutex
 	joinRequests    map[common.Address]*JoinRequest
+
+	// eventMux is the event-mux for the events posted from the entities of the service.
+	eventMux *event.TypeMux
 }
 
 func NewBaseServiceProtocolManager(ptt Router, service Service) (*BaseServiceProtocolManager, error) {
@@ -108,6 +115,8 @@ func NewBaseServiceProtocolManager(ptt Router, service Service) (*BaseServicePro
 		dbLock:    dbLock,
 		dbObjLock: dbObjLock,
 		dbLogLock: dbLogLock,
+
+		eventMux: new(event.TypeMux),
 	}
 
 	return spm, nil
@@ -134,7 +143,11 @@ func (spm *BaseServiceProtocolManager) Start() error {
 }
 
 func (spm *BaseServiceProtocolManager) Stop() error {
-	return spm.StopEntities()
+	err := spm.StopEntities()
+
+	spm.eventMux.Stop()
+
+	return err
 }
 
 func (spm *BaseServiceProtocolManager) Router() Router {
@@ -149,6 +162,10 @@ func (spm *BaseServiceProtocolManager) NewEmptyEntity() Entity {
 	return nil
 }
 
+func (spm *BaseServiceProtocolManager) EventMux() *event.TypeMux {
+	return spm.eventMux
+}
+
 /*
 Lock locks the entity-level lock.
 */
@@ -0,0 +1,128 @@
+// Copyright 2019 The go-pttai Authors
+// This file is part of the go-pttai library.
+//
+// The go-pttai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-pttai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-pttai library. If not, see <http://www.gnu.org/licenses/>.
+
+package service
+
+import (
+	"encoding/json"
+
+	"github.com/ailabstw/go-pttai-core/common"
+	"github.com/ailabstw/go-pttai-core/common/types"
+	"github.com/ailabstw/go-pttai-core/pttdb"
+)
+
+/*
+UploadFile is the session of the chunked upload of a large file.
+
+The session is saved in the db, so the upload can be resumed chunk by chunk.
+Chunks[idx] is nil if the idx-th chunk is not uploaded yet.
+*/
+type UploadFile struct {
+	V        types.Version
+	ID       *types.PttID    `json:"ID"`
+	EntityID *types.PttID    `json:"EID"`
+	Filename []byte          `json:"f"`
+	Size     int64           `json:"s"`
+	Chunks   [][]byte        `json:"c"`
+	UpdateTS types.Timestamp `json:"UT"`
+}
+
+func NewUploadFile(entityID *types.PttID, filename []byte, size int64) (*UploadFile, error) {
+	if size < 0 {
+		return nil, ErrNegativeSize
+	}
+	if size > MaxUploadFileSize {
+		return nil, ErrFileTooLarge
+	}
+
+	id, err := types.NewPttID()
+	if err != nil {
+		return nil, err
+	}
+
+	ts, err := types.GetTimestamp()
+	if err != nil {
+		return nil, err
+	}
+
+	return &UploadFile{
+		V:        types.CurrentVersion,
+		ID:       id,
+		EntityID: entityID,
+		Filename: filename,
+		Size:     size,
+		Chunks:   make([][]byte, NMediaChunk(size)),
+		UpdateTS: ts,
+	}, nil
+}
+
+/*
+MissingChunks returns the indexes of the chunks that are not uploaded yet.
+*/
+func (u *UploadFile) MissingChunks() []int {
+	missings := make([]int, 0)
+	for i, hash := range u.Chunks {
+		if hash == nil {
+			missings = append(missings, i)
+		}
+	}
+	return missings
+}
+
+func (u *UploadFile) IsComplete() bool {
+	return len(u.MissingChunks()) == 0
+}
+
+func marshalUploadFileKey(entityID *types.PttID, id *types.PttID) ([]byte, error) {
+	return common.Concat([][]byte{DBUploadFilePrefix, entityID[:], id[:]})
+}
+
+func (u *UploadFile) Save(db pttdb.Storage) error {
+	key, err := marshalUploadFileKey(u.EntityID, u.ID)
+	if err != nil {
+		return err
+	}
+
+	marshaled, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+
+	return db.Put(key, marshaled)
+}
+
+func (u *UploadFile) Get(db pttdb.Storage) error {
+	key, err := marshalUploadFileKey(u.EntityID, u.ID)
+	if err != nil {
+		return err
+	}
+
+	marshaled, err := db.Get(key)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(marshaled, u)
+}
+
+func (u *UploadFile) Delete(db pttdb.Storage) error {
+	key, err := marshalUploadFileKey(u.EntityID, u.ID)
+	if err != nil {
+		return err
+	}
+
+	return db.Delete(key)
+}